@@ -0,0 +1,222 @@
+// ABOUTME: Turns Alertmanager and Grafana webhook payloads into notifications.
+// ABOUTME: Groups alerts by name and status and maps severity labels to priorities.
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Alert states as the webhooks report them.
+const (
+	statusFiring   = "firing"
+	statusResolved = "resolved"
+)
+
+// resolvedPriority keeps resolved notifications quiet whatever their severity.
+const resolvedPriority = -1
+
+// maxListed caps the alerts listed in one notification.
+const maxListed = 10
+
+// Notification is one message to send for a webhook call.
+type Notification struct {
+	Title    string
+	Message  string
+	Priority int
+	URL      string
+	URLTitle string
+}
+
+// payload is the Alertmanager webhook body, which Grafana's unified alerting
+// also sends with a few fields of its own.
+type payload struct {
+	Status      string  `json:"status"`
+	ExternalURL string  `json:"externalURL"`
+	Alerts      []alert `json:"alerts"`
+}
+
+type alert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	GeneratorURL string            `json:"generatorURL"`
+	// Set by Grafana.
+	DashboardURL string `json:"dashboardURL"`
+	PanelURL     string `json:"panelURL"`
+}
+
+// legacyPayload is the body of Grafana's legacy dashboard alerts.
+type legacyPayload struct {
+	Title       string            `json:"title"`
+	RuleName    string            `json:"ruleName"`
+	RuleURL     string            `json:"ruleUrl"`
+	State       string            `json:"state"`
+	Message     string            `json:"message"`
+	Tags        map[string]string `json:"tags"`
+	EvalMatches []struct {
+		Metric string  `json:"metric"`
+		Value  float64 `json:"value"`
+	} `json:"evalMatches"`
+}
+
+// Alertmanager returns the notifications for an Alertmanager webhook body,
+// one per alert name and status. priorities maps severity labels to
+// priorities; unknown severities get 0.
+func Alertmanager(body []byte, priorities map[string]int) ([]Notification, error) {
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("parse alertmanager payload: %w", err)
+	}
+	return group(p, priorities, func(a alert) string {
+		if a.GeneratorURL != "" {
+			return a.GeneratorURL
+		}
+		return p.ExternalURL
+	}), nil
+}
+
+// Grafana returns the notifications for a Grafana webhook body, from unified
+// alerting or from legacy dashboard alerts.
+func Grafana(body []byte, priorities map[string]int) ([]Notification, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return nil, fmt.Errorf("parse grafana payload: %w", err)
+	}
+	if _, unified := probe["alerts"]; unified {
+		var p payload
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, fmt.Errorf("parse grafana payload: %w", err)
+		}
+		return group(p, priorities, func(a alert) string {
+			for _, link := range []string{a.PanelURL, a.DashboardURL, a.GeneratorURL} {
+				if link != "" {
+					return link
+				}
+			}
+			return p.ExternalURL
+		}), nil
+	}
+
+	var p legacyPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("parse grafana payload: %w", err)
+	}
+	return legacy(p, priorities), nil
+}
+
+// group sends one notification per alert name and status, in the order the
+// alerts arrived.
+func group(p payload, priorities map[string]int, link func(alert) string) []Notification {
+	type key struct{ name, status string }
+	var order []key
+	groups := map[key][]alert{}
+	for _, a := range p.Alerts {
+		status := a.Status
+		if status == "" {
+			status = p.Status
+		}
+		k := key{name: a.Labels["alertname"], status: status}
+		if k.name == "" {
+			k.name = "alert"
+		}
+		if _, seen := groups[k]; !seen {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], a)
+	}
+
+	notifications := make([]Notification, 0, len(order))
+	for _, k := range order {
+		alerts := groups[k]
+		n := Notification{URL: link(alerts[0]), URLTitle: "View alert"}
+		if k.status == statusResolved {
+			n.Title = "[RESOLVED] " + k.name
+			n.Priority = resolvedPriority
+		} else {
+			n.Title = fmt.Sprintf("[%s:%d] %s", strings.ToUpper(orDefault(k.status, statusFiring)), len(alerts), k.name)
+			n.Priority = severityPriority(alerts, priorities)
+		}
+		var lines []string
+		for i, a := range alerts {
+			if i == maxListed {
+				lines = append(lines, fmt.Sprintf("…and %d more", len(alerts)-maxListed))
+				break
+			}
+			lines = append(lines, describe(a))
+		}
+		n.Message = strings.Join(lines, "\n")
+		notifications = append(notifications, n)
+	}
+	return notifications
+}
+
+// severityPriority returns the highest priority among alerts' severities.
+func severityPriority(alerts []alert, priorities map[string]int) int {
+	best := -2
+	for _, a := range alerts {
+		priority := priorities[strings.ToLower(a.Labels["severity"])]
+		best = max(best, priority)
+	}
+	return best
+}
+
+// describe condenses one alert to a line: its summary, or description, and
+// the instance it fired for.
+func describe(a alert) string {
+	text := orDefault(a.Annotations["summary"], a.Annotations["description"])
+	if text == "" {
+		text = orDefault(a.Labels["alertname"], "alert")
+	}
+	if instance := a.Labels["instance"]; instance != "" && !strings.Contains(text, instance) {
+		text += " (" + instance + ")"
+	}
+	return "• " + text
+}
+
+// legacy converts a legacy Grafana alert. Pending and paused alerts are not
+// worth a notification.
+func legacy(p legacyPayload, priorities map[string]int) []Notification {
+	n := Notification{
+		Title:    orDefault(p.Title, p.RuleName),
+		Message:  p.Message,
+		URL:      p.RuleURL,
+		URLTitle: "View alert",
+	}
+	switch p.State {
+	case "alerting":
+		n.Priority = priorities[strings.ToLower(p.Tags["severity"])]
+	case "no_data":
+		n.Priority = 0
+	case "ok":
+		n.Priority = resolvedPriority
+	default:
+		return nil
+	}
+	var lines []string
+	for i, match := range p.EvalMatches {
+		if i == maxListed {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(p.EvalMatches)-maxListed))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("• %s: %g", match.Metric, match.Value))
+	}
+	if len(lines) > 0 {
+		n.Message = strings.TrimSpace(n.Message + "\n" + strings.Join(lines, "\n"))
+	}
+	if n.Message == "" {
+		n.Message = orDefault(p.RuleName, n.Title)
+	}
+	if n.Title == "" {
+		n.Title = "Grafana alert"
+	}
+	return []Notification{n}
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,132 @@
+// ABOUTME: Tests for converting alert webhooks into notifications.
+// ABOUTME: Covers Alertmanager grouping, Grafana payloads, and webhook authentication.
+package alerts
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/harper/push/internal/config"
+)
+
+const alertmanagerBody = `{
+  "version": "4",
+  "status": "firing",
+  "externalURL": "http://alertmanager:9093",
+  "alerts": [
+    {"status": "firing", "labels": {"alertname": "HighCPU", "severity": "warning", "instance": "web-01"},
+     "annotations": {"summary": "CPU above 90%"}, "generatorURL": "http://prometheus/graph?g0"},
+    {"status": "firing", "labels": {"alertname": "HighCPU", "severity": "critical", "instance": "web-02"},
+     "annotations": {"summary": "CPU above 90%"}},
+    {"status": "resolved", "labels": {"alertname": "DiskFull", "severity": "critical", "instance": "db-01"},
+     "annotations": {"description": "Disk usage back under 80% on db-01"}}
+  ]
+}`
+
+func TestAlertmanager(t *testing.T) {
+	notifications, err := Alertmanager([]byte(alertmanagerBody), config.DefaultSeverityPriorities)
+	if err != nil {
+		t.Fatalf("Alertmanager() error: %v", err)
+	}
+	if len(notifications) != 2 {
+		t.Fatalf("got %d notifications, want one per alert name and status: %+v", len(notifications), notifications)
+	}
+
+	firing := notifications[0]
+	if firing.Title != "[FIRING:2] HighCPU" || firing.Priority != 1 || firing.URL != "http://prometheus/graph?g0" {
+		t.Errorf("firing = %+v, want both HighCPU alerts at the critical priority", firing)
+	}
+	if firing.Message != "• CPU above 90% (web-01)\n• CPU above 90% (web-02)" {
+		t.Errorf("firing message = %q", firing.Message)
+	}
+
+	resolved := notifications[1]
+	if resolved.Title != "[RESOLVED] DiskFull" || resolved.Priority != resolvedPriority || resolved.URL != "http://alertmanager:9093" {
+		t.Errorf("resolved = %+v", resolved)
+	}
+	if resolved.Message != "• Disk usage back under 80% on db-01" {
+		t.Errorf("resolved message = %q, want the instance left out when the text names it", resolved.Message)
+	}
+}
+
+func TestGrafana(t *testing.T) {
+	unified := `{"status": "firing", "alerts": [{"status": "firing", "labels": {"alertname": "Latency"},
+	  "annotations": {"summary": "p99 over 2s"}, "generatorURL": "http://grafana/alerting/1", "panelURL": "http://grafana/d/abc?viewPanel=2"}]}`
+	notifications, err := Grafana([]byte(unified), config.DefaultSeverityPriorities)
+	if err != nil {
+		t.Fatalf("Grafana() unified error: %v", err)
+	}
+	if len(notifications) != 1 || notifications[0].Title != "[FIRING:1] Latency" || notifications[0].Priority != 0 || notifications[0].URL != "http://grafana/d/abc?viewPanel=2" {
+		t.Errorf("unified = %+v", notifications)
+	}
+
+	legacyBody := `{"title": "[Alerting] Queue depth", "ruleName": "Queue depth", "ruleUrl": "http://grafana/d/q",
+	  "state": "alerting", "message": "Queue is backing up", "tags": {"severity": "critical"},
+	  "evalMatches": [{"metric": "jobs", "value": 1200}]}`
+	notifications, err = Grafana([]byte(legacyBody), map[string]int{"critical": 2})
+	if err != nil {
+		t.Fatalf("Grafana() legacy error: %v", err)
+	}
+	if len(notifications) != 1 || notifications[0].Priority != 2 || notifications[0].Message != "Queue is backing up\n• jobs: 1200" {
+		t.Errorf("legacy = %+v", notifications)
+	}
+
+	notifications, err = Grafana([]byte(`{"title": "x", "state": "pending"}`), nil)
+	if err != nil || len(notifications) != 0 {
+		t.Errorf("pending legacy alert = %+v, %v; want nothing sent", notifications, err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var sent []Notification
+	send := func(_ context.Context, n Notification) error {
+		sent = append(sent, n)
+		return nil
+	}
+	srv := httptest.NewServer(Handler("s3cret", config.DefaultSeverityPriorities, send, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	defer srv.Close()
+
+	post := func(path, auth, body string) int {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, srv.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post("/hooks/alertmanager", "", alertmanagerBody); code != http.StatusUnauthorized {
+		t.Errorf("no token = %d, want 401", code)
+	}
+	if code := post("/hooks/alertmanager?token=wrong", "", alertmanagerBody); code != http.StatusUnauthorized {
+		t.Errorf("wrong token = %d, want 401", code)
+	}
+	if code := post("/hooks/alertmanager", "Bearer s3cret", "not json"); code != http.StatusBadRequest {
+		t.Errorf("bad payload = %d, want 400", code)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("sent %d notifications for rejected requests", len(sent))
+	}
+	if code := post("/hooks/alertmanager", "Bearer s3cret", alertmanagerBody); code != http.StatusOK {
+		t.Errorf("alertmanager = %d, want 200", code)
+	}
+	if code := post("/hooks/grafana?token=s3cret", "", `{"alerts": [{"status": "resolved", "labels": {"alertname": "Latency"}}]}`); code != http.StatusOK {
+		t.Errorf("grafana = %d, want 200", code)
+	}
+	if len(sent) != 3 || sent[2].Title != "[RESOLVED] Latency" {
+		t.Errorf("sent = %+v", sent)
+	}
+}
@@ -0,0 +1,75 @@
+// ABOUTME: HTTP endpoints that receive Alertmanager and Grafana webhooks.
+// ABOUTME: Checks the shared token, parses the payload, and sends its notifications.
+package alerts
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// maxBody caps a webhook payload.
+const maxBody = 1 << 20
+
+// SendFunc delivers one notification.
+type SendFunc func(ctx context.Context, n Notification) error
+
+// Handler serves POST /hooks/alertmanager and POST /hooks/grafana. Requests
+// must carry token as a bearer token or a token query parameter.
+func Handler(token string, priorities map[string]int, send SendFunc, log *slog.Logger) http.Handler {
+	mux := http.NewServeMux()
+	for path, parse := range map[string]func([]byte, map[string]int) ([]Notification, error){
+		"/hooks/alertmanager": Alertmanager,
+		"/hooks/grafana":      Grafana,
+	} {
+		source := strings.TrimPrefix(path, "/hooks/")
+		mux.HandleFunc("POST "+path, func(w http.ResponseWriter, r *http.Request) {
+			if !authorized(r, token) {
+				log.Warn("rejected alert webhook with a bad token", "source", source, "remote", r.RemoteAddr)
+				http.Error(w, "missing or wrong token", http.StatusUnauthorized)
+				return
+			}
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+			if err != nil {
+				http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			notifications, err := parse(body, priorities)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			var failed []error
+			for _, n := range notifications {
+				if err := send(r.Context(), n); err != nil {
+					log.Error("alert not sent", "source", source, "title", n.Title, "error", err)
+					failed = append(failed, err)
+					continue
+				}
+				log.Info("alert sent", "source", source, "title", n.Title, "priority", n.Priority)
+			}
+			if len(failed) > 0 {
+				// Alertmanager and Grafana retry on 5xx.
+				http.Error(w, fmt.Sprintf("%d of %d notifications failed: %v", len(failed), len(notifications), errors.Join(failed...)), http.StatusBadGateway)
+				return
+			}
+			_, _ = fmt.Fprintf(w, "sent %d notification(s)\n", len(notifications))
+		})
+	}
+	return mux
+}
+
+// authorized reports whether r carries token.
+func authorized(r *http.Request, token string) bool {
+	given := r.URL.Query().Get("token")
+	if auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		given = strings.TrimSpace(auth)
+	}
+	return token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
@@ -0,0 +1,56 @@
+// ABOUTME: Opens web links in the default browser by shelling out to the platform's opener.
+// ABOUTME: Honours $BROWSER, then uses open, xdg-open, or rundll32.
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnavailable means no way to open a browser was found.
+var ErrUnavailable = errors.New("no browser opener found (set $BROWSER or install xdg-utils)")
+
+// Open shows link in the default browser. Only http and https links are
+// opened, so a message can't launch arbitrary handlers.
+func Open(link string) error {
+	parsed, err := url.Parse(link)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("refusing to open %q: only http and https links are opened", link)
+	}
+	for _, args := range commands() {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		// Not tied to a context: a browser started directly from $BROWSER
+		// should outlive this command.
+		cmd := exec.Command(path, append(args[1:], link)...) //nolint:gosec // fixed tool list or the user's $BROWSER
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("open browser with %s: %w", args[0], err)
+		}
+		// Openers hand the link over and exit; don't leave a zombie behind.
+		go func() { _ = cmd.Wait() }()
+		return nil
+	}
+	return ErrUnavailable
+}
+
+// commands lists openers for this platform in order of preference.
+func commands() [][]string {
+	var cmds [][]string
+	if custom := strings.Fields(os.Getenv("BROWSER")); len(custom) > 0 {
+		cmds = append(cmds, custom)
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return append(cmds, []string{"open"})
+	case "windows":
+		return append(cmds, []string{"rundll32", "url.dll,FileProtocolHandler"})
+	}
+	return append(cmds, []string{"xdg-open"}, []string{"wslview"})
+}
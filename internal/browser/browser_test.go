@@ -1,6 +1,6 @@
-// ABOUTME: Placeholder test for pushover package.
+// ABOUTME: Placeholder test for browser package.
 // ABOUTME: Ensures coverage tools work correctly.
-package pushover
+package browser
 
 import "testing"
 
@@ -0,0 +1,119 @@
+// ABOUTME: Fetches iCalendar feeds and picks the events push calendar alerts before.
+// ABOUTME: Supports conditional requests so unchanged feeds aren't downloaded again.
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/feed"
+)
+
+// maxFeedSize bounds how much of a feed is read.
+const maxFeedSize = 16 << 20
+
+// Feed is what a fetch returned. NotModified is set when the server
+// answered a conditional request with 304, in which case Events is nil.
+type Feed struct {
+	Events       []Event
+	ETag         string
+	LastModified string
+	NotModified  bool
+}
+
+// NormalizeURL turns webcal:// links, which calendar apps hand out for
+// subscriptions, into https:// ones. Anything else is returned as is.
+func NormalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if rest, ok := strings.CutPrefix(raw, "webcal://"); ok {
+		return "https://" + rest
+	}
+	if rest, ok := strings.CutPrefix(raw, "webcals://"); ok {
+		return "https://" + rest
+	}
+	return raw
+}
+
+// Fetch reads the feed at source, an http(s) URL or a local file path.
+// etag and lastModified come from the previous fetch and make the request
+// conditional.
+func Fetch(ctx context.Context, client *http.Client, source, etag, lastModified string) (Feed, error) {
+	source = NormalizeURL(source)
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		f, err := os.Open(strings.TrimPrefix(source, "file://"))
+		if err != nil {
+			return Feed{}, fmt.Errorf("open calendar: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		events, err := Parse(io.LimitReader(f, maxFeedSize))
+		return Feed{Events: events}, err
+	}
+
+	resp, err := feed.ConditionalGet(ctx, client, source, "push-calendar", "text/calendar, */*;q=0.5", etag, lastModified, maxFeedSize)
+	if err != nil {
+		return Feed{}, fmt.Errorf("fetch calendar: %w", err)
+	}
+	fetched := Feed{ETag: resp.ETag, LastModified: resp.LastModified, NotModified: resp.NotModified}
+	if resp.NotModified {
+		return fetched, nil
+	}
+	if fetched.Events, err = Parse(bytes.NewReader(resp.Body)); err != nil {
+		return Feed{}, err
+	}
+	return fetched, nil
+}
+
+// Alerts returns the timed occurrences starting after now and no more than
+// lead away: the ones to alert about at now. All-day events are skipped,
+// since an alert shortly before midnight helps nobody.
+func Alerts(events []Event, now time.Time, lead time.Duration) []Occurrence {
+	var out []Occurrence
+	for _, o := range Occurrences(events, now.Add(time.Nanosecond), now.Add(lead+time.Nanosecond)) {
+		if !o.AllDay {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// Notification returns the title and message for an alert about o sent at
+// now.
+func Notification(o Occurrence, now time.Time) (string, string) {
+	title := o.Summary
+	if title == "" {
+		title = "(untitled event)"
+	}
+
+	start := o.Start.In(now.Location())
+	when := "at " + start.Format("15:04")
+	if y, m, d := start.Date(); y != now.Year() || m != now.Month() || d != now.Day() {
+		when = start.Format("Mon 2 Jan") + " " + when
+	}
+	message := fmt.Sprintf("Starts %s, in %s.", when, until(o.Start.Sub(now)))
+	if o.End.After(o.Start) {
+		message = fmt.Sprintf("Starts %s, in %s; ends at %s.", when, until(o.Start.Sub(now)), o.End.In(now.Location()).Format("15:04"))
+	}
+	if o.Location != "" {
+		message += "\n" + o.Location
+	}
+	return title, message
+}
+
+// until renders d to the minute, e.g. "15m" or "1h30m".
+func until(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "under a minute"
+	}
+	s := strings.TrimSuffix(d.String(), "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,225 @@
+// ABOUTME: Tests for reading iCalendar feeds and choosing which events to alert on.
+// ABOUTME: Covers folding, time zones, recurrence rules, overrides, and conditional fetches.
+package calendar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"SUMMARY:Stand-up\r\n" +
+	"DTSTART:20261012T090000Z\r\n" +
+	"DTEND:20261012T091500Z\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR\r\n" +
+	"EXDATE:20261016T090000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"RECURRENCE-ID:20261019T090000Z\r\n" +
+	"SUMMARY:Stand-up (moved)\r\n" +
+	"DTSTART:20261019T100000Z\r\n" +
+	"DTEND:20261019T101500Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:review\r\n" +
+	"SUMMARY:Design review\\, round two\r\n" +
+	"LOCATION:Room 4\r\n" +
+	"DESCRIPTION:Bring the long\r\n" +
+	"  notes\r\n" +
+	"DTSTART;TZID=America/New_York:20261014T110000\r\n" +
+	"DTEND;TZID=America/New_York:20261014T120000\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"TRIGGER:-PT10M\r\n" +
+	"DESCRIPTION:alarm\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:holiday\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"DTSTART;VALUE=DATE:20261015\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled\r\n" +
+	"SUMMARY:Cancelled\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART:20261014T160000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(testICS))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(events) != 5 {
+		t.Fatalf("got %d events, want 5", len(events))
+	}
+
+	review := events[2]
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	if review.Summary != "Design review, round two" || review.Location != "Room 4" || review.Description != "Bring the long notes" {
+		t.Errorf("review = %+v", review)
+	}
+	if want := time.Date(2026, time.October, 14, 11, 0, 0, 0, ny); !review.Start.Equal(want) {
+		t.Errorf("review starts %s, want %s", review.Start, want)
+	}
+	if !events[3].AllDay || !events[4].Cancelled {
+		t.Errorf("holiday = %+v, cancelled = %+v", events[3], events[4])
+	}
+	if events[0].Rule == nil || len(events[0].Rule.ByDay) != 3 || len(events[0].ExDates) != 1 {
+		t.Errorf("stand-up = %+v", events[0])
+	}
+
+	if _, err := Parse(strings.NewReader("<html>not a calendar</html>")); err == nil {
+		t.Error("Parse of HTML succeeded, want an error")
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	events, err := Parse(strings.NewReader(testICS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, time.October, 22, 0, 0, 0, 0, time.UTC)
+
+	var got []string
+	for _, o := range Occurrences(events, from, to) {
+		if o.UID == "standup" {
+			got = append(got, o.Summary+" "+o.Start.UTC().Format("Mon 2 15:04"))
+		}
+	}
+	want := []string{
+		"Stand-up Mon 12 09:00",
+		"Stand-up Wed 14 09:00",
+		// Friday the 16th is an EXDATE; Monday the 19th was moved.
+		"Stand-up (moved) Mon 19 10:00",
+		"Stand-up Wed 21 09:00",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("stand-ups:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRules(t *testing.T) {
+	utc := func(y int, m time.Month, d, h int) time.Time { return time.Date(y, m, d, h, 0, 0, 0, time.UTC) }
+	cases := []struct {
+		rule  string
+		start time.Time
+		want  []time.Time
+	}{
+		{"FREQ=DAILY;INTERVAL=2;COUNT=3", utc(2026, 1, 30, 8), []time.Time{utc(2026, 1, 30, 8), utc(2026, 2, 1, 8), utc(2026, 2, 3, 8)}},
+		{"FREQ=WEEKLY;UNTIL=20260115T000000Z", utc(2026, 1, 1, 8), []time.Time{utc(2026, 1, 1, 8), utc(2026, 1, 8, 8)}},
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", utc(2026, 1, 30, 8), []time.Time{utc(2026, 1, 30, 8), utc(2026, 2, 27, 8), utc(2026, 3, 27, 8)}},
+		{"FREQ=MONTHLY;BYDAY=2TU;COUNT=2", utc(2026, 1, 13, 8), []time.Time{utc(2026, 1, 13, 8), utc(2026, 2, 10, 8)}},
+		// BYDAY limits BYMONTHDAY: only Friday the 13ths, not every Friday.
+		{"FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13;COUNT=3", utc(2026, 2, 13, 8), []time.Time{utc(2026, 2, 13, 8), utc(2026, 3, 13, 8), utc(2026, 11, 13, 8)}},
+		{"FREQ=MONTHLY;BYDAY=2FR;BYMONTHDAY=13;COUNT=2", utc(2026, 2, 13, 8), []time.Time{utc(2026, 2, 13, 8), utc(2026, 3, 13, 8)}},
+		// The 31st is skipped in months without one.
+		{"FREQ=MONTHLY;COUNT=3", utc(2026, 1, 31, 8), []time.Time{utc(2026, 1, 31, 8), utc(2026, 3, 31, 8), utc(2026, 5, 31, 8)}},
+		{"FREQ=YEARLY;COUNT=2", utc(2028, 2, 29, 8), []time.Time{utc(2028, 2, 29, 8), utc(2032, 2, 29, 8)}},
+	}
+	for _, c := range cases {
+		rule, err := parseRule(c.rule, nil)
+		if err != nil || rule == nil {
+			t.Fatalf("parseRule(%q) = %v, %v", c.rule, rule, err)
+		}
+		got := rule.expand(c.start, utc(2040, 1, 1, 0))
+		if len(got) != len(c.want) {
+			t.Errorf("%s: got %v, want %v", c.rule, got, c.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(c.want[i]) {
+				t.Errorf("%s: got %v, want %v", c.rule, got, c.want)
+				break
+			}
+		}
+	}
+
+	if rule, err := parseRule("FREQ=MONTHLY;BYSETPOS=-1;BYDAY=MO,TU", nil); rule != nil || err != nil {
+		t.Errorf("unsupported rule = %v, %v; want nil so the event happens once", rule, err)
+	}
+	if _, err := parseRule("FREQ=DAILY;COUNT=x", nil); err == nil {
+		t.Error("bad COUNT parsed, want an error")
+	}
+}
+
+func TestAlertsAndNotification(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 8, 50, 0, 0, time.UTC)
+	events, err := Parse(strings.NewReader(testICS))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alerts := Alerts(events, now, 15*time.Minute)
+	if len(alerts) != 1 || alerts[0].Summary != "Stand-up" {
+		t.Fatalf("alerts = %+v, want the 09:00 stand-up", alerts)
+	}
+	if key := alerts[0].Key(); key != "standup@2026-10-14T09:00:00Z" {
+		t.Errorf("Key = %q", key)
+	}
+	title, message := Notification(alerts[0], now)
+	if title != "Stand-up" || message != "Starts at 09:00, in 10m; ends at 09:15." {
+		t.Errorf("Notification = %q, %q", title, message)
+	}
+
+	// Nothing once it has started, and all-day events never alert.
+	if got := Alerts(events, now.Add(10*time.Minute), 15*time.Minute); len(got) != 0 {
+		t.Errorf("alerts at the start = %+v, want none", got)
+	}
+	if got := Alerts(events, time.Date(2026, time.October, 14, 23, 50, 0, 0, time.Local), 15*time.Minute); len(got) != 0 {
+		t.Errorf("alerts before the holiday = %+v, want none", got)
+	}
+
+	_, message = Notification(Occurrence{Summary: "Flight", Location: "Gate 12", Start: now.Add(26 * time.Hour)}, now)
+	if message != "Starts Thu 15 Oct at 10:50, in 26h.\nGate 12" {
+		t.Errorf("Notification for tomorrow = %q", message)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(testICS))
+	}))
+	defer srv.Close()
+
+	got, err := Fetch(t.Context(), srv.Client(), srv.URL, "", "")
+	if err != nil || len(got.Events) != 5 || got.ETag != `"v1"` {
+		t.Fatalf("Fetch = %+v, %v", got, err)
+	}
+	got, err = Fetch(t.Context(), srv.Client(), srv.URL, `"v1"`, "")
+	if err != nil || !got.NotModified || got.ETag != `"v1"` {
+		t.Errorf("conditional Fetch = %+v, %v", got, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "cal.ics")
+	if err := os.WriteFile(path, []byte(testICS), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Fetch(t.Context(), nil, path, "", ""); err != nil || len(got.Events) != 5 {
+		t.Errorf("Fetch(file) = %+v, %v", got, err)
+	}
+	if NormalizeURL("webcal://example.com/cal.ics") != "https://example.com/cal.ics" {
+		t.Error("webcal:// was not turned into https://")
+	}
+}
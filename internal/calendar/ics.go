@@ -0,0 +1,238 @@
+// ABOUTME: Minimal iCalendar (RFC 5545) reader for the events push calendar alerts on.
+// ABOUTME: Handles folded lines, time zones, all-day events, and cancelled events.
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxLine bounds one unfolded content line.
+const maxLine = 1 << 20
+
+// Event is one VEVENT. A recurring event carries its rule and is expanded
+// by Occurrences.
+type Event struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Cancelled   bool
+	// RecurrenceID is set on an event that replaces one occurrence of a
+	// recurring event with the same UID.
+	RecurrenceID time.Time
+	Rule         *Rule
+	ExDates      []time.Time
+}
+
+// property is one content line: NAME;PARAM=VALUE:value.
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the events in an iCalendar stream. Times are resolved with
+// their TZID when it names a known zone and read as local time otherwise.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var p icsParser
+	for _, line := range lines {
+		prop, ok := parseProperty(line)
+		if !ok {
+			continue
+		}
+		if err := p.add(prop); err != nil {
+			return nil, err
+		}
+	}
+	if !p.sawCal {
+		return nil, fmt.Errorf("not an iCalendar feed (no BEGIN:VCALENDAR)")
+	}
+	return p.events, nil
+}
+
+// icsParser collects events from a stream's properties in order.
+type icsParser struct {
+	events []Event
+	// cur is the event being read, and depth how far inside components
+	// nested in it, such as VALARM, the current property is.
+	cur    *Event
+	depth  int
+	sawCal bool
+}
+
+func (p *icsParser) add(prop property) error {
+	switch prop.name {
+	case "BEGIN":
+		p.begin(prop.value)
+	case "END":
+		p.end(prop.value)
+	default:
+		if p.cur == nil || p.depth > 0 {
+			return nil
+		}
+		if err := p.cur.set(prop); err != nil {
+			return fmt.Errorf("event %q: %w", p.cur.UID, err)
+		}
+	}
+	return nil
+}
+
+func (p *icsParser) begin(component string) {
+	if strings.EqualFold(component, "VCALENDAR") {
+		p.sawCal = true
+	}
+	switch {
+	case strings.EqualFold(component, "VEVENT") && p.cur == nil:
+		p.cur = &Event{}
+		p.depth = 0
+	case p.cur != nil:
+		// VALARM and other components nested in the event.
+		p.depth++
+	}
+}
+
+func (p *icsParser) end(component string) {
+	switch {
+	case p.cur == nil:
+	case p.depth > 0:
+		p.depth--
+	case strings.EqualFold(component, "VEVENT"):
+		if !p.cur.Start.IsZero() {
+			p.events = append(p.events, *p.cur)
+		}
+		p.cur = nil
+	}
+}
+
+func (e *Event) set(prop property) error {
+	switch prop.name {
+	case "UID":
+		e.UID = prop.value
+	case "SUMMARY":
+		e.Summary = unescape(prop.value)
+	case "LOCATION":
+		e.Location = unescape(prop.value)
+	case "DESCRIPTION":
+		e.Description = unescape(prop.value)
+	case "URL":
+		e.URL = prop.value
+	case "STATUS":
+		e.Cancelled = strings.EqualFold(prop.value, "CANCELLED")
+	case "DTSTART":
+		t, allDay, err := parseTime(prop)
+		if err != nil {
+			return fmt.Errorf("DTSTART: %w", err)
+		}
+		e.Start, e.AllDay = t, allDay
+	case "DTEND":
+		t, _, err := parseTime(prop)
+		if err != nil {
+			return fmt.Errorf("DTEND: %w", err)
+		}
+		e.End = t
+	case "RECURRENCE-ID":
+		t, _, err := parseTime(prop)
+		if err != nil {
+			return fmt.Errorf("RECURRENCE-ID: %w", err)
+		}
+		e.RecurrenceID = t
+	case "RRULE":
+		rule, err := parseRule(prop.value, prop.params)
+		if err != nil {
+			return err
+		}
+		e.Rule = rule
+	case "EXDATE":
+		for _, value := range strings.Split(prop.value, ",") {
+			t, _, err := parseTime(property{params: prop.params, value: value})
+			if err != nil {
+				return fmt.Errorf("EXDATE: %w", err)
+			}
+			e.ExDates = append(e.ExDates, t)
+		}
+	}
+	return nil
+}
+
+// unfold joins continuation lines, which start with a space or tab.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLine)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseProperty splits a content line, respecting quoted parameter values.
+func parseProperty(line string) (property, bool) {
+	inQuote := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuote = !inQuote
+		}
+		if r == ':' && !inQuote {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return property{}, false
+	}
+	head := strings.Split(line[:colon], ";")
+	prop := property{name: strings.ToUpper(head[0]), value: line[colon+1:], params: map[string]string{}}
+	for _, param := range head[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return prop, true
+}
+
+// parseTime reads a DATE or DATE-TIME value.
+func parseTime(prop property) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.value)
+	if prop.params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	loc := time.Local
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if zone, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = zone
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// unescape undoes TEXT escaping.
+func unescape(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
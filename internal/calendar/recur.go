@@ -0,0 +1,361 @@
+// ABOUTME: Expands recurring events into the occurrences that fall in a window.
+// ABOUTME: Covers the RRULE shapes calendar apps write for everyday repeating events.
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods caps how many periods a rule is walked through, so a daily
+// event from decades ago can't stall a sync.
+const maxPeriods = 20000
+
+// Rule is the supported subset of an RRULE: DAILY, WEEKLY, MONTHLY and
+// YEARLY with INTERVAL, COUNT, UNTIL, BYDAY and BYMONTHDAY. Events with a
+// rule outside it are treated as happening once.
+type Rule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+}
+
+// WeekdayNum is a BYDAY entry such as MO, 2TU, or -1FR. N is zero when the
+// entry has no ordinal.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// Occurrence is one instance of an event.
+type Occurrence struct {
+	UID      string
+	Summary  string
+	Location string
+	URL      string
+	Start    time.Time
+	End      time.Time
+	AllDay   bool
+}
+
+// Key identifies the occurrence across syncs, for remembering which ones
+// have already been alerted.
+func (o Occurrence) Key() string {
+	uid := o.UID
+	if uid == "" {
+		uid = o.Summary
+	}
+	return uid + "@" + o.Start.UTC().Format(time.RFC3339)
+}
+
+func parseRule(value string, params map[string]string) (*Rule, error) {
+	rule := &Rule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("RRULE: bad INTERVAL %q", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("RRULE: bad COUNT %q", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			t, _, err := parseTime(property{params: params, value: val})
+			if err != nil {
+				return nil, fmt.Errorf("RRULE: bad UNTIL %q", val)
+			}
+			rule.Until = t
+		case "BYDAY":
+			days, err := parseByDay(val)
+			if err != nil {
+				return nil, err
+			}
+			rule.ByDay = append(rule.ByDay, days...)
+		case "BYMONTHDAY":
+			days, err := parseByMonthDay(val)
+			if err != nil {
+				return nil, err
+			}
+			rule.ByMonthDay = append(rule.ByMonthDay, days...)
+		case "WKST", "":
+		default:
+			// BYSETPOS, BYHOUR and friends: fall back to a single occurrence.
+			rule.Freq = ""
+		}
+	}
+	switch rule.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+		return rule, nil
+	}
+	return nil, nil
+}
+
+// parseByDay reads a BYDAY list such as "MO,WE" or "-1FR".
+func parseByDay(val string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, day := range strings.Split(val, ",") {
+		day = strings.ToUpper(strings.TrimSpace(day))
+		if len(day) < 2 {
+			return nil, fmt.Errorf("RRULE: bad BYDAY %q", val)
+		}
+		wd, ok := weekdays[day[len(day)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("RRULE: bad BYDAY %q", val)
+		}
+		n := 0
+		if prefix := day[:len(day)-2]; prefix != "" {
+			var err error
+			if n, err = strconv.Atoi(prefix); err != nil {
+				return nil, fmt.Errorf("RRULE: bad BYDAY %q", val)
+			}
+		}
+		days = append(days, WeekdayNum{N: n, Day: wd})
+	}
+	return days, nil
+}
+
+// parseByMonthDay reads a BYMONTHDAY list such as "1,15" or "-1".
+func parseByMonthDay(val string) ([]int, error) {
+	var days []int
+	for _, day := range strings.Split(val, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(day))
+		if err != nil || n == 0 || n < -31 || n > 31 {
+			return nil, fmt.Errorf("RRULE: bad BYMONTHDAY %q", val)
+		}
+		days = append(days, n)
+	}
+	return days, nil
+}
+
+// Occurrences returns the instances of events starting in [from, to),
+// sorted by start. Cancelled events, EXDATEs, and occurrences replaced by a
+// RECURRENCE-ID override are left out.
+func Occurrences(events []Event, from, to time.Time) []Occurrence {
+	overridden := map[string]bool{}
+	for _, e := range events {
+		if !e.RecurrenceID.IsZero() {
+			overridden[e.UID+"@"+e.RecurrenceID.UTC().Format(time.RFC3339)] = true
+		}
+	}
+
+	var out []Occurrence
+	for _, e := range events {
+		if e.Cancelled {
+			continue
+		}
+		length := e.End.Sub(e.Start)
+		if length < 0 {
+			length = 0
+		}
+		add := func(start time.Time) {
+			if start.Before(from) || !start.Before(to) {
+				return
+			}
+			out = append(out, Occurrence{
+				UID: e.UID, Summary: e.Summary, Location: e.Location, URL: e.URL,
+				Start: start, End: start.Add(length), AllDay: e.AllDay,
+			})
+		}
+		if e.Rule == nil || !e.RecurrenceID.IsZero() {
+			add(e.Start)
+			continue
+		}
+		for _, start := range e.Rule.expand(e.Start, to) {
+			key := e.UID + "@" + start.UTC().Format(time.RFC3339)
+			if overridden[key] || excluded(e.ExDates, start) {
+				continue
+			}
+			add(start)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+func excluded(exdates []time.Time, start time.Time) bool {
+	for _, ex := range exdates {
+		if ex.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// expand lists the rule's occurrences from dtstart up to (not including) to.
+// dtstart is always the first occurrence, as RFC 5545 requires.
+func (r *Rule) expand(dtstart, to time.Time) []time.Time {
+	var out []time.Time
+	seen := 0
+	emit := func(t time.Time) bool {
+		if t.Before(dtstart) {
+			return true
+		}
+		if !r.Until.IsZero() && t.After(r.Until) {
+			return false
+		}
+		if r.Count > 0 && seen >= r.Count {
+			return false
+		}
+		if !t.Before(to) {
+			return false
+		}
+		seen++
+		out = append(out, t)
+		return true
+	}
+
+	if !emit(dtstart) {
+		return out
+	}
+	for period := 0; period < maxPeriods; period++ {
+		for _, t := range r.period(dtstart, period) {
+			if t.Equal(dtstart) {
+				continue
+			}
+			if !emit(t) {
+				return out
+			}
+		}
+	}
+	return out
+}
+
+// period returns the candidate times in the n-th period of the rule, in
+// order, keeping dtstart's wall-clock time and zone.
+func (r *Rule) period(dtstart time.Time, n int) []time.Time {
+	y, m, d := dtstart.Date()
+	hh, mm, ss := dtstart.Clock()
+	loc := dtstart.Location()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hh, mm, ss, 0, loc)
+	}
+	step := n * r.Interval
+
+	switch r.Freq {
+	case "DAILY":
+		return []time.Time{at(y, m, d+step)}
+	case "WEEKLY":
+		if len(r.ByDay) == 0 {
+			return []time.Time{at(y, m, d+7*step)}
+		}
+		// Weeks start on Monday.
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		monday := d - offset + 7*step
+		var out []time.Time
+		for i := 0; i < 7; i++ {
+			t := at(y, m, monday+i)
+			for _, wd := range r.ByDay {
+				if wd.Day == t.Weekday() {
+					out = append(out, t)
+				}
+			}
+		}
+		return out
+	case "MONTHLY":
+		first := time.Date(y, m+time.Month(step), 1, 0, 0, 0, 0, loc)
+		return r.daysInMonth(first.Year(), first.Month(), d, at)
+	case "YEARLY":
+		if len(r.ByDay) > 0 || len(r.ByMonthDay) > 0 {
+			return r.daysInMonth(y+step, m, d, at)
+		}
+		t := at(y+step, m, d)
+		if t.Day() != d {
+			// 29 February in a year without one.
+			return nil
+		}
+		return []time.Time{t}
+	}
+	return nil
+}
+
+// daysInMonth expands BYMONTHDAY and BYDAY within one month, defaulting to
+// dtstart's day of the month. When both are set, BYDAY limits BYMONTHDAY:
+// only month days falling on a listed weekday are kept.
+func (r *Rule) daysInMonth(year int, month time.Month, defaultDay int, at func(int, time.Month, int) time.Time) []time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	var days []int
+	switch {
+	case len(r.ByMonthDay) > 0 && len(r.ByDay) > 0:
+		onWeekday := map[int]bool{}
+		for _, day := range r.byDayIn(year, month, last) {
+			onWeekday[day] = true
+		}
+		for _, day := range r.byMonthDayIn(last) {
+			if onWeekday[day] {
+				days = append(days, day)
+			}
+		}
+	case len(r.ByMonthDay) > 0:
+		days = r.byMonthDayIn(last)
+	case len(r.ByDay) > 0:
+		days = r.byDayIn(year, month, last)
+	case defaultDay <= last:
+		days = []int{defaultDay}
+	}
+	sort.Ints(days)
+
+	var out []time.Time
+	for i, day := range days {
+		if i > 0 && day == days[i-1] {
+			continue
+		}
+		out = append(out, at(year, month, day))
+	}
+	return out
+}
+
+// byMonthDayIn resolves BYMONTHDAY to days of a month with last days.
+func (r *Rule) byMonthDayIn(last int) []int {
+	var days []int
+	for _, md := range r.ByMonthDay {
+		if md < 0 {
+			md = last + md + 1
+		}
+		if md >= 1 && md <= last {
+			days = append(days, md)
+		}
+	}
+	return days
+}
+
+// byDayIn resolves BYDAY to days of the month, honouring ordinals such as
+// 2TU and -1FR.
+func (r *Rule) byDayIn(year int, month time.Month, last int) []int {
+	var days []int
+	for _, wd := range r.ByDay {
+		var matches []int
+		for day := 1; day <= last; day++ {
+			if time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() == wd.Day {
+				matches = append(matches, day)
+			}
+		}
+		switch {
+		case wd.N == 0:
+			days = append(days, matches...)
+		case wd.N > 0 && wd.N <= len(matches):
+			days = append(days, matches[wd.N-1])
+		case wd.N < 0 && -wd.N <= len(matches):
+			days = append(days, matches[len(matches)+wd.N])
+		}
+	}
+	return days
+}
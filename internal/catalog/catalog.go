@@ -0,0 +1,73 @@
+// ABOUTME: Cached sound and device lists shared by the CLI and MCP server.
+// ABOUTME: Refreshes from the Pushover API when the local copy is missing or stale.
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// MaxAge is how long cached sound and device lists are trusted.
+const MaxAge = 24 * time.Hour
+
+// Source fetches fresh lists; *pushover.Client satisfies it.
+type Source interface {
+	Sounds(ctx context.Context) (map[string]string, error)
+	ValidateUser(ctx context.Context) (*pushover.UserValidation, error)
+}
+
+// Load returns cached entries of a kind, refreshing them from src when stale.
+// A failed refresh falls back to whatever is cached.
+func Load(ctx context.Context, store *db.Store, src Source, kind string) ([]db.CatalogEntry, error) {
+	entries, fetchedAt, err := store.Catalog(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	if !fetchedAt.IsZero() && time.Since(fetchedAt) < MaxAge {
+		return entries, nil
+	}
+
+	fresh, err := Fetch(ctx, src, kind)
+	if err != nil {
+		if len(entries) > 0 {
+			return entries, nil
+		}
+		return nil, err
+	}
+	if err := store.ReplaceCatalog(ctx, kind, fresh, time.Now()); err != nil {
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// Fetch asks src for the current entries of a kind, sorted by name.
+func Fetch(ctx context.Context, src Source, kind string) ([]db.CatalogEntry, error) {
+	var entries []db.CatalogEntry
+	switch kind {
+	case db.CatalogSounds:
+		sounds, err := src.Sounds(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for name, label := range sounds {
+			entries = append(entries, db.CatalogEntry{Name: name, Label: label})
+		}
+	case db.CatalogDevices:
+		validation, err := src.ValidateUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range validation.Devices {
+			entries = append(entries, db.CatalogEntry{Name: name})
+		}
+	default:
+		return nil, fmt.Errorf("unknown catalog %q", kind)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
@@ -0,0 +1,76 @@
+// ABOUTME: Tests for the cached sound and device lists.
+// ABOUTME: Covers refresh on a cold cache, cache hits, and fallback on API errors.
+package catalog
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+)
+
+type fakeSource struct {
+	sounds  map[string]string
+	devices []string
+	err     error
+	calls   int
+}
+
+func (f *fakeSource) Sounds(context.Context) (map[string]string, error) {
+	f.calls++
+	return f.sounds, f.err
+}
+
+func (f *fakeSource) ValidateUser(context.Context) (*pushover.UserValidation, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &pushover.UserValidation{Devices: f.devices}, nil
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	store, err := db.Open(filepath.Join(t.TempDir(), "push.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	src := &fakeSource{
+		sounds:  map[string]string{"pushover": "Pushover (default)", "bike": "Bike"},
+		devices: []string{"phone", "laptop"},
+	}
+	sounds, err := Load(ctx, store, src, db.CatalogSounds)
+	if err != nil {
+		t.Fatalf("Load(sounds) error: %v", err)
+	}
+	if len(sounds) != 2 || sounds[0].Name != "bike" || sounds[1].Label != "Pushover (default)" {
+		t.Errorf("sounds = %+v, want bike then pushover", sounds)
+	}
+
+	// A fresh cache is served without asking the source.
+	if _, err := Load(ctx, store, src, db.CatalogSounds); err != nil || src.calls != 1 {
+		t.Errorf("cached Load() err = %v, calls = %d; want 1 call", err, src.calls)
+	}
+
+	// A stale cache falls back to the old entries when the refresh fails.
+	if err := store.ReplaceCatalog(ctx, db.CatalogDevices, []db.CatalogEntry{{Name: "tablet"}}, time.Now().Add(-2*MaxAge)); err != nil {
+		t.Fatalf("ReplaceCatalog() error: %v", err)
+	}
+	src.err = errors.New("offline")
+	devices, err := Load(ctx, store, src, db.CatalogDevices)
+	if err != nil || len(devices) != 1 || devices[0].Name != "tablet" {
+		t.Errorf("stale Load() = %+v, %v; want cached tablet", devices, err)
+	}
+
+	src.err = nil
+	devices, err = Load(ctx, store, src, db.CatalogDevices)
+	if err != nil || len(devices) != 2 || devices[0].Name != "laptop" {
+		t.Errorf("refreshed Load() = %+v, %v; want laptop and phone", devices, err)
+	}
+}
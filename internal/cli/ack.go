@@ -0,0 +1,62 @@
+// ABOUTME: Ack command for acknowledging emergency-priority messages.
+// ABOUTME: Calls the receipt acknowledgement endpoint and records it in history.
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newAckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ack <receipt>...",
+		Short: "Acknowledge emergency messages by receipt",
+		Long:  "Acknowledge received emergency-priority messages so Pushover stops retrying them. Receipts are shown by 'push messages' next to messages awaiting acknowledgement.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runAck,
+	}
+
+	return cmd
+}
+
+func runAck(cmd *cobra.Command, args []string) error {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := cmd.Context()
+	for _, receipt := range args {
+		// Acknowledge with the device the message arrived on, falling back
+		// to the login device for receipts that aren't in history.
+		deviceCfg := cfg
+		if rec, ok, err := store.GetMessageByReceipt(ctx, receipt); err == nil && ok && rec.Device != "" {
+			if forDevice, err := cfg.ForDevice(rec.Device); err == nil {
+				deviceCfg = forDevice
+			}
+		}
+		if err := deviceCfg.ValidateReceive(); err != nil {
+			return err
+		}
+
+		client, err := newClientFromConfig(deviceCfg)
+		if err != nil {
+			return err
+		}
+		if err := client.AcknowledgeReceipt(ctx, receipt); err != nil {
+			return fmt.Errorf("acknowledge %s: %w", receipt, err)
+		}
+		if err := store.MarkReceiptAcked(ctx, receipt); err != nil {
+			logger.Warn("unable to update history", "receipt", receipt, "error", err)
+		}
+		cmd.Printf("✓ Acknowledged %s.\n", receipt)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// ABOUTME: Alias command for sending canned notifications from config.
+// ABOUTME: Expands an [aliases] entry, appends any extra text, and sends it.
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+func newAliasCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "a [alias] [extra text]",
+		Short:             "Send a canned notification defined in [aliases]",
+		Long:              "Send a notification defined under [aliases] in the config file. Extra arguments are appended to the alias message. Without arguments, lists the configured aliases.",
+		RunE:              runAlias,
+		ValidArgsFunction: completeAliases,
+	}
+
+	return cmd
+}
+
+func runAlias(cmd *cobra.Command, args []string) error {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		if len(cfg.Aliases) == 0 {
+			cmd.Println("No aliases configured. Add an [aliases.<name>] table to the config file.")
+			return nil
+		}
+		for _, name := range aliasNames(cfg.Aliases) {
+			cmd.Printf("%s: %s\n", name, cfg.Aliases[name].Message)
+		}
+		return nil
+	}
+
+	alias, ok := cfg.Aliases[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown alias %q (run 'push a' to list aliases)", args[0])
+	}
+	message := strings.TrimSpace(strings.Join(append([]string{alias.Message}, args[1:]...), " "))
+	if message == "" {
+		return fmt.Errorf("alias %q has no message", args[0])
+	}
+	if alias.Priority < -2 || alias.Priority > 2 {
+		return fmt.Errorf("alias %q: priority must be between -2 and 2", args[0])
+	}
+	if cfg, err = cfg.ForApp(alias.App); err != nil {
+		return fmt.Errorf("alias %q: %w", args[0], err)
+	}
+
+	window, err := cfg.DedupeWindowDuration()
+	if err != nil {
+		return err
+	}
+	longMessages, err := cfg.LongMessagePolicy()
+	if err != nil {
+		return err
+	}
+
+	params := pushover.SendParams{
+		Message:  message,
+		Title:    alias.Title,
+		Device:   alias.Device,
+		Priority: alias.Priority,
+		URL:      alias.URL,
+		Sound:    alias.Sound,
+	}
+	return dispatchSend(cmd, cfg, params, sendOptions{via: alias.Via, window: window, longMessages: longMessages})
+}
+
+func completeAliases(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, name := range aliasNames(cfg.Aliases) {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name+"\t"+cfg.Aliases[name].Message)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func aliasNames(aliases map[string]config.AliasConfig) []string {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,75 @@
+// ABOUTME: Archive and unarchive commands for tucking triaged messages away.
+// ABOUTME: Archived messages are kept but only listed by 'push history --archived'.
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newArchiveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         "archive <message-id>...",
+		Short:       "Hide received messages from history without deleting them",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Archive received messages once they are dealt with. Archived messages are kept in the database but left out of 'push history' unless --archived is given. Message IDs are the [N] shown by 'push messages' and 'push history'.",
+		Args:        cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runArchive(cmd, args, true)
+		},
+	}
+}
+
+func newUnarchiveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         "unarchive <message-id>...",
+		Short:       "Return archived messages to history",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Unarchive messages so 'push history' lists them again. Find archived messages with 'push history --archived'.",
+		Args:        cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runArchive(cmd, args, false)
+		},
+	}
+}
+
+func runArchive(cmd *cobra.Command, args []string, archived bool) error {
+	ids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(strings.Trim(arg, "[]"), 10, 64)
+		if err != nil || id <= 0 {
+			return fmt.Errorf("invalid message id %q", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	verb := "Archived"
+	if !archived {
+		verb = "Unarchived"
+	}
+	var missing []string
+	for _, id := range ids {
+		found, err := store.SetArchived(cmd.Context(), id, archived)
+		if err != nil {
+			return err
+		}
+		if !found {
+			missing = append(missing, fmt.Sprintf("[%d]", id))
+			continue
+		}
+		cmd.Printf("%s message [%d]\n", verb, id)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no message %s; see 'push history'", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+// ABOUTME: Backup and restore commands for moving history between machines.
+// ABOUTME: Archives an online database copy plus a secret-free config snapshot.
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/replicate"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/cobra"
+)
+
+const (
+	backupDBName     = replicate.ArchiveDB
+	backupConfigName = replicate.ArchiveConfig
+	// maxBackupEntry guards restore against decompression bombs.
+	maxBackupEntry = 4 << 30
+	// maxBackupConfig caps the config snapshot.
+	maxBackupConfig = 1 << 20
+)
+
+func newBackupCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         "backup <file>",
+		Short:       "Write the history database and settings (without secrets) to an archive",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Args:        cobra.ExactArgs(1),
+		RunE:        runBackup,
+	}
+}
+
+func newRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "restore <file>",
+		Short:       "Replace the history database with one from a backup archive",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Args:        cobra.ExactArgs(1),
+		RunE:        runRestore,
+	}
+	cmd.Flags().Bool("with-config", false, "also restore settings, keeping this machine's credentials")
+	return cmd
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	dest := args[0]
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("%s already exists", dest)
+	}
+
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	snapshot, err := toml.Marshal(cfg.WithoutSecrets())
+	if err != nil {
+		return fmt.Errorf("encoding config snapshot: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "push-backup-*")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	dbCopy := filepath.Join(tmpDir, backupDBName)
+	if err := store.BackupTo(cmd.Context(), dbCopy); err != nil {
+		return err
+	}
+
+	if err := writeBackupArchive(dest, dbCopy, snapshot); err != nil {
+		_ = os.Remove(dest)
+		return err
+	}
+
+	cmd.Printf("✓ Backup written to %s\n", dest)
+	return nil
+}
+
+func writeBackupArchive(dest, dbPath string, configSnapshot []byte) error {
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("create backup: %w", err)
+	}
+	defer func() { _ = out.Close() }()
+
+	if err := replicate.WriteArchive(out, dbPath, configSnapshot, time.Now()); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	tmpDir, err := os.MkdirTemp("", "push-restore-*")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	snapshot, err := extractBackupArchive(args[0], tmpDir)
+	if err != nil {
+		return err
+	}
+
+	store, dbPath, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	safety := fmt.Sprintf("%s.pre-restore-%s", dbPath, time.Now().Format("20060102-150405"))
+	if err := store.BackupTo(cmd.Context(), safety); err != nil {
+		return fmt.Errorf("saving current database: %w", err)
+	}
+	if err := store.RestoreFrom(cmd.Context(), filepath.Join(tmpDir, backupDBName)); err != nil {
+		return err
+	}
+	cmd.Printf("✓ History restored. Previous database saved to %s\n", safety)
+
+	withConfig, _ := cmd.Flags().GetBool("with-config")
+	if !withConfig {
+		return nil
+	}
+	if snapshot == nil {
+		return errors.New("backup does not contain a config snapshot")
+	}
+	return restoreConfigSnapshot(cmd, snapshot)
+}
+
+// extractBackupArchive unpacks the database into dir and returns the config snapshot, if any.
+func extractBackupArchive(path, dir string) ([]byte, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open backup: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("read backup: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	var snapshot []byte
+	foundDB := false
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read backup: %w", err)
+		}
+		switch hdr.Name {
+		case backupDBName:
+			if err := extractBackupEntry(tr, filepath.Join(dir, backupDBName)); err != nil {
+				return nil, err
+			}
+			foundDB = true
+		case backupConfigName:
+			if snapshot, err = io.ReadAll(io.LimitReader(tr, maxBackupConfig+1)); err != nil {
+				return nil, fmt.Errorf("read backup: %w", err)
+			}
+			if len(snapshot) > maxBackupConfig {
+				return nil, fmt.Errorf("read backup: %s is larger than %d bytes", backupConfigName, maxBackupConfig)
+			}
+		}
+	}
+	if !foundDB {
+		return nil, fmt.Errorf("%s is not a push backup: no %s inside", path, backupDBName)
+	}
+	return snapshot, nil
+}
+
+func extractBackupEntry(r io.Reader, dest string) error {
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("extract backup: %w", err)
+	}
+	defer func() { _ = out.Close() }()
+	n, err := io.Copy(out, io.LimitReader(r, maxBackupEntry+1))
+	if err != nil {
+		return fmt.Errorf("extract backup: %w", err)
+	}
+	if n > maxBackupEntry {
+		return fmt.Errorf("extract backup: %s is larger than %d bytes", filepath.Base(dest), int64(maxBackupEntry))
+	}
+	return out.Close()
+}
+
+func restoreConfigSnapshot(cmd *cobra.Command, snapshot []byte) error {
+	var restored config.Config
+	if err := toml.Unmarshal(snapshot, &restored); err != nil {
+		return fmt.Errorf("parsing config snapshot: %w", err)
+	}
+
+	current, cfgPath, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := config.Save(cfgPath, restored.WithSecretsFrom(current)); err != nil {
+		return err
+	}
+	cmd.Printf("✓ Settings restored to %s (credentials unchanged)\n", cfgPath)
+	return nil
+}
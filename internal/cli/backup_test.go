@@ -0,0 +1,76 @@
+// ABOUTME: Tests for reading push backup archives.
+// ABOUTME: Checks an oversized entry fails the restore instead of being cut short.
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func archiveEntries(t *testing.T, entries map[string][]byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range entries {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "backup.tar.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtractBackupArchive(t *testing.T) {
+	snapshot := []byte("default_priority = 1\n")
+	path := archiveEntries(t, map[string][]byte{
+		backupDBName:     []byte("database"),
+		backupConfigName: snapshot,
+	})
+	dir := t.TempDir()
+	got, err := extractBackupArchive(path, dir)
+	if err != nil {
+		t.Fatalf("extractBackupArchive: %v", err)
+	}
+	if !bytes.Equal(got, snapshot) {
+		t.Errorf("snapshot = %q, want %q", got, snapshot)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, backupDBName)); err != nil || string(data) != "database" {
+		t.Errorf("extracted database = %q, %v", data, err)
+	}
+}
+
+func TestExtractBackupArchiveOversizedConfig(t *testing.T) {
+	path := archiveEntries(t, map[string][]byte{
+		backupDBName:     []byte("database"),
+		backupConfigName: bytes.Repeat([]byte("#"), maxBackupConfig+1),
+	})
+	_, err := extractBackupArchive(path, t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Fatalf("extractBackupArchive = %v, want a size error", err)
+	}
+}
+
+func TestExtractBackupArchiveWithoutDatabase(t *testing.T) {
+	path := archiveEntries(t, map[string][]byte{backupConfigName: []byte("")})
+	if _, err := extractBackupArchive(path, t.TempDir()); err == nil {
+		t.Fatal("archive without a database was accepted")
+	}
+}
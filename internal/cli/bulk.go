@@ -0,0 +1,155 @@
+// ABOUTME: Bulk delete and update of the messages push history matches.
+// ABOUTME: Previews the matches, asks for confirmation, and changes them in batches.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// bulkPreview is how many matching messages are shown before confirming.
+const bulkPreview = 5
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]*$`)
+
+// bulkFlags are the history flags that change the matching messages.
+var bulkFlags = []string{"delete", "apply", "dry-run", "yes"}
+
+// parseApply turns --apply key=value changes into one update.
+func parseApply(changes []string) (db.MessageUpdate, []string, error) {
+	var update db.MessageUpdate
+	var described []string
+	for _, change := range changes {
+		key, value, ok := strings.Cut(change, "=")
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return db.MessageUpdate{}, nil, fmt.Errorf("--apply %q: want key=value, e.g. tag=sensitive", change)
+		}
+		switch key {
+		case "tag", "untag":
+			tag := strings.ToLower(value)
+			if !tagPattern.MatchString(tag) {
+				return db.MessageUpdate{}, nil, fmt.Errorf("--apply %q: tags are letters, digits, and . _ : -", change)
+			}
+			if key == "tag" {
+				update.AddTag = tag
+			} else {
+				update.RemoveTag = tag
+			}
+			described = append(described, key+"="+tag)
+		case "archived":
+			archived, err := strconv.ParseBool(value)
+			if err != nil {
+				return db.MessageUpdate{}, nil, fmt.Errorf("--apply %q: archived must be true or false", change)
+			}
+			update.Archived = &archived
+			described = append(described, key+"="+strconv.FormatBool(archived))
+		default:
+			return db.MessageUpdate{}, nil, fmt.Errorf("--apply %q: unknown key %q; use tag, untag, or archived", change, key)
+		}
+	}
+	if update.AddTag != "" && update.RemoveTag != "" {
+		return db.MessageUpdate{}, nil, fmt.Errorf("--apply cannot add and remove a tag at once")
+	}
+	return update, described, nil
+}
+
+// runHistoryBulk deletes or updates every message matching filter, or only
+// the first --limit of them when that is given.
+func runHistoryBulk(cmd *cobra.Command, store *db.Store, filter db.MessageFilter) error {
+	del, _ := cmd.Flags().GetBool("delete")
+	changes, _ := cmd.Flags().GetStringArray("apply")
+	var update db.MessageUpdate
+	action := "delete"
+	if !del {
+		var described []string
+		var err error
+		if update, described, err = parseApply(changes); err != nil {
+			return err
+		}
+		action = "apply " + strings.Join(described, ", ") + " to"
+	}
+	if !cmd.Flags().Changed("limit") {
+		filter.Limit = 0
+	}
+
+	ctx := cmd.Context()
+	ids, err := store.MessageIDs(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		cmd.Println("No matching messages.")
+		return nil
+	}
+	if err := previewMatches(cmd, store, filter, len(ids)); err != nil {
+		return err
+	}
+	if ok, err := confirmBulk(cmd, fmt.Sprintf("%s %d message(s)", action, len(ids))); !ok || err != nil {
+		return err
+	}
+
+	if del {
+		deleted, err := store.DeleteMessages(ctx, ids)
+		if err != nil {
+			return err
+		}
+		cmd.Printf("Deleted %d message(s).\n", deleted)
+		return nil
+	}
+	changed, err := store.UpdateMessages(ctx, ids, update)
+	if err != nil {
+		return err
+	}
+	cmd.Printf("Updated %d message(s); %d already matched.\n", changed, int64(len(ids))-changed)
+	return nil
+}
+
+// previewMatches lists the first few of the total messages matching filter.
+func previewMatches(cmd *cobra.Command, store *db.Store, filter db.MessageFilter, total int) error {
+	preview := filter
+	preview.Limit = min(total, bulkPreview)
+	records, err := store.FindMessages(cmd.Context(), preview)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		cmd.Printf("  %s [%d] %s\n", rec.ReceivedAt.Local().Format(time.RFC3339), rec.PushoverID, digestLine(rec))
+	}
+	if more := total - len(records); more > 0 {
+		cmd.Printf("  …and %d more\n", more)
+	}
+	return nil
+}
+
+// confirmBulk reports whether to go ahead with the change summary describes:
+// not under --dry-run, and with --yes or a yes at the prompt. Without a
+// terminal to ask on, it refuses.
+func confirmBulk(cmd *cobra.Command, summary string) (bool, error) {
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		cmd.Printf("Dry run: would %s.\n", summary)
+		return false, nil
+	}
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true, nil
+	}
+	if !isTerminal(os.Stdin) {
+		return false, fmt.Errorf("refusing to %s without confirmation; pass --yes", summary)
+	}
+	answer, err := newPrompter(cmd.OutOrStderr()).Ask(strings.ToUpper(summary[:1])+summary[1:]+"? (y/n)", "n")
+	if err != nil {
+		return false, err
+	}
+	if !strings.HasPrefix(strings.ToLower(answer), "y") {
+		cmd.Println("Nothing changed.")
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,140 @@
+// ABOUTME: Tests for push history --delete and --apply confirmation.
+// ABOUTME: Checks bulk changes need --yes without a terminal and --dry-run changes nothing.
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// pipeStdin replaces os.Stdin with a pipe, so prompts see no terminal.
+func pipeStdin(t *testing.T) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		_ = r.Close()
+		_ = w.Close()
+	})
+}
+
+func bulkHistoryCmd(t *testing.T, args ...string) (*cobra.Command, *bytes.Buffer) {
+	t.Helper()
+	cmd := newHistoryCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetContext(context.Background())
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+	return cmd, &out
+}
+
+func bulkTestStore(t *testing.T, n int) *db.Store {
+	t.Helper()
+	store, err := db.Open(db.Memory)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	msgs := make([]db.MessageRecord, n)
+	for i := range msgs {
+		msgs[i] = db.MessageRecord{
+			PushoverID: int64(i + 1),
+			Title:      "Job",
+			Message:    fmt.Sprintf("run %d", i),
+			App:        "ci",
+			ReceivedAt: time.Now().Add(-time.Duration(i) * time.Minute),
+		}
+	}
+	if _, err := store.PersistMessages(context.Background(), msgs); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func storedCount(t *testing.T, store *db.Store) int {
+	t.Helper()
+	ids, err := store.MessageIDs(context.Background(), db.MessageFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(ids)
+}
+
+func TestConfirmBulkRefusesWithoutYes(t *testing.T) {
+	pipeStdin(t)
+
+	cmd, _ := bulkHistoryCmd(t, "--delete")
+	ok, err := confirmBulk(cmd, "delete 3 message(s)")
+	if ok || err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Fatalf("confirmBulk without --yes = %v, %v; want refusal naming --yes", ok, err)
+	}
+
+	cmd, _ = bulkHistoryCmd(t, "--delete", "--yes")
+	if ok, err := confirmBulk(cmd, "delete 3 message(s)"); !ok || err != nil {
+		t.Fatalf("confirmBulk with --yes = %v, %v", ok, err)
+	}
+
+	cmd, out := bulkHistoryCmd(t, "--delete", "--yes", "--dry-run")
+	if ok, err := confirmBulk(cmd, "delete 3 message(s)"); ok || err != nil {
+		t.Fatalf("confirmBulk with --dry-run = %v, %v", ok, err)
+	}
+	if !strings.Contains(out.String(), "Dry run: would delete 3 message(s).") {
+		t.Errorf("dry run output = %q", out.String())
+	}
+}
+
+func TestHistoryBulkNeedsConfirmation(t *testing.T) {
+	pipeStdin(t)
+	store := bulkTestStore(t, 3)
+
+	cmd, _ := bulkHistoryCmd(t, "--delete")
+	if err := runHistoryBulk(cmd, store, db.MessageFilter{App: "ci"}); err == nil {
+		t.Fatal("delete without --yes succeeded")
+	}
+	cmd, _ = bulkHistoryCmd(t, "--apply", "tag=x", "--dry-run")
+	if err := runHistoryBulk(cmd, store, db.MessageFilter{App: "ci"}); err != nil {
+		t.Fatal(err)
+	}
+	tagged, err := store.MessageIDs(context.Background(), db.MessageFilter{Tag: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := storedCount(t, store); got != 3 || len(tagged) != 0 {
+		t.Fatalf("after refused changes: %d stored, %d tagged; want 3 and 0", got, len(tagged))
+	}
+
+	cmd, out := bulkHistoryCmd(t, "--delete", "--yes")
+	if err := runHistoryBulk(cmd, store, db.MessageFilter{App: "ci"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedCount(t, store); got != 0 {
+		t.Errorf("%d messages left after --delete --yes", got)
+	}
+	if !strings.Contains(out.String(), "Deleted 3 message(s).") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	cmd, out = bulkHistoryCmd(t, "--delete", "--yes")
+	if err := runHistoryBulk(cmd, store, db.MessageFilter{App: "ci"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No matching messages.") {
+		t.Errorf("empty match output = %q", out.String())
+	}
+}
@@ -0,0 +1,182 @@
+// ABOUTME: Calendar command for alerts shortly before events in an iCalendar feed.
+// ABOUTME: Subscribes to feeds the daemon syncs, and lists and removes them.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/calendar"
+	"github.com/harper/push/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// maxCalendarLead bounds --lead; anything longer is a digest, not an alert.
+const maxCalendarLead = 7 * 24 * time.Hour
+
+func newCalendarCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "calendar",
+		Short:       "Get notified shortly before events in an iCalendar feed, synced by 'push daemon'",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Subscribe to an iCalendar (.ics) feed, such as the secret address Google Calendar or Fastmail gives out, or a local .ics file. 'push daemon' fetches each feed every 15 minutes and sends a notification the --lead time before each timed event starts, once per event. All-day events don't alert. Without a subcommand, lists the feeds and how their last sync went.",
+		Args:        cobra.NoArgs,
+		RunE:        runCalendarList,
+	}
+	cmd.Flags().Bool("json", false, "output JSON")
+
+	cmd.AddCommand(newCalendarSyncCmd(), newCalendarRemoveCmd())
+
+	return cmd
+}
+
+func newCalendarSyncCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync <ics-url>",
+		Short: "Subscribe to a calendar feed, or change the settings of one",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runCalendarSync,
+	}
+	cmd.Flags().String("name", "", "name for the calendar (default: the feed's host or file name)")
+	cmd.Flags().Duration("lead", 15*time.Minute, "how long before an event starts to alert")
+	cmd.Flags().IntP("priority", "p", 0, "priority of the alerts (-2 to 2)")
+	return cmd
+}
+
+func runCalendarSync(cmd *cobra.Command, args []string) error {
+	lead, _ := cmd.Flags().GetDuration("lead")
+	if lead < time.Minute || lead > maxCalendarLead {
+		return fmt.Errorf("--lead must be between 1m and 168h")
+	}
+	priority, _ := cmd.Flags().GetInt("priority")
+	if priority < -2 || priority > 2 {
+		return fmt.Errorf("priority must be between -2 and 2")
+	}
+
+	source := calendar.NormalizeURL(args[0])
+	if !strings.Contains(source, "://") {
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return fmt.Errorf("resolve path: %w", err)
+		}
+		source = abs
+	}
+	name, _ := cmd.Flags().GetString("name")
+	if name == "" {
+		name = calendarName(source)
+	}
+
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	client, err := newMonitorClient(cfg)
+	if err != nil {
+		return err
+	}
+	// Fetch once now so a wrong address fails here rather than in the daemon.
+	feed, err := calendar.Fetch(cmd.Context(), client, source, "", "")
+	if err != nil {
+		// Not wrapped: the network hints are about reaching Pushover.
+		return fmt.Errorf("calendar unavailable: %v", err)
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now()
+	if err := store.SaveCalendar(cmd.Context(), db.CalendarRecord{
+		Name:         name,
+		URL:          source,
+		Lead:         lead,
+		Priority:     priority,
+		ETag:         feed.ETag,
+		LastModified: feed.LastModified,
+		LastSynced:   now,
+		Events:       len(feed.Events),
+	}); err != nil {
+		return err
+	}
+	cmd.Printf("✓ Calendar %q: %d events, alerting %s before each.\n", name, len(feed.Events), roughDuration(lead))
+	if next := calendar.Alerts(feed.Events, now, 30*24*time.Hour); len(next) > 0 {
+		cmd.Printf("  Next: %s, %s.\n", next[0].Summary, next[0].Start.Local().Format("Mon 2 Jan 15:04"))
+	}
+	noteDaemonStopped(cmd, "first alert")
+	return nil
+}
+
+// calendarName picks a default name for a feed: its host, or its file name
+// without the extension.
+func calendarName(source string) string {
+	if u, err := url.Parse(source); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	base := filepath.Base(source)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func runCalendarList(cmd *cobra.Command, args []string) error {
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	calendars, err := store.ListCalendars(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(calendars)
+	}
+	if len(calendars) == 0 {
+		cmd.Println("No calendars. Add one with 'push calendar sync <ics-url>'.")
+		return nil
+	}
+	for _, c := range calendars {
+		cmd.Printf("%s: %s\n", c.Name, c.URL)
+		synced := "never synced"
+		if !c.LastSynced.IsZero() {
+			synced = "synced " + c.LastSynced.Local().Format(time.RFC3339)
+		}
+		cmd.Printf("  %d events, alerts %s before, %s\n", c.Events, roughDuration(c.Lead), synced)
+		if c.LastError != "" {
+			cmd.Printf("  last sync failed: %s\n", c.LastError)
+		}
+	}
+	return nil
+}
+
+func newCalendarRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Unsubscribe from a calendar feed",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			removed, err := store.DeleteCalendar(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("no calendar named %q", args[0])
+			}
+			cmd.Printf("✓ Calendar %q removed.\n", args[0])
+			return nil
+		},
+	}
+}
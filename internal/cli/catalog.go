@@ -0,0 +1,55 @@
+// ABOUTME: Cached sound and device lists for prompts and shell completion.
+// ABOUTME: Loads them through internal/catalog with the configured API client.
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/harper/push/internal/catalog"
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// loadCatalog returns cached entries of a kind, refreshing them from the API
+// when stale.
+func loadCatalog(ctx context.Context, cfg *config.Config, store *db.Store, kind string) ([]db.CatalogEntry, error) {
+	client, err := newClientFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.Load(ctx, store, client, kind)
+}
+
+// completeCatalog offers cached entries of a kind as flag completions.
+func completeCatalog(kind string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		cfg, _, err := loadConfig()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		store, _, err := openStore()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		defer func() { _ = store.Close() }()
+
+		entries, err := loadCatalog(cmd.Context(), cfg, store, kind)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var completions []string
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name, toComplete) {
+				continue
+			}
+			if entry.Label != "" {
+				completions = append(completions, entry.Name+"\t"+entry.Label)
+			} else {
+				completions = append(completions, entry.Name)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
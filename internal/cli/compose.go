@@ -0,0 +1,203 @@
+// ABOUTME: Compose command for building a notification interactively.
+// ABOUTME: Prompts for each field with cached sound and device choices, previews, then sends.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+func newComposeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compose",
+		Short: "Interactively write and send a notification",
+		Args:  cobra.NoArgs,
+		RunE:  runCompose,
+	}
+
+	return cmd
+}
+
+func runCompose(cmd *cobra.Command, args []string) error {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.ValidateSend(); err != nil {
+		return err
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	prom := newPrompter(cmd.OutOrStdout())
+	defaults := pushover.SendParams{Priority: cfg.DefaultPriority, Device: cfg.DefaultDevice}
+	params, err := askComposeFields(cmd, prom, cfg, store, defaults)
+	if err != nil {
+		return err
+	}
+	window, err := cfg.DedupeWindowDuration()
+	if err != nil {
+		return err
+	}
+	return confirmComposeSend(cmd, prom, cfg, params, sendOptions{window: window})
+}
+
+// askComposeFields prompts for each field of a notification, offering the
+// values in defaults.
+func askComposeFields(cmd *cobra.Command, prom *prompter, cfg *config.Config, store *db.Store, defaults pushover.SendParams) (pushover.SendParams, error) {
+	ctx := cmd.Context()
+	out := cmd.OutOrStdout()
+
+	var params pushover.SendParams
+	var err error
+	for params.Message == "" {
+		if params.Message, err = prom.Ask("Message", defaults.Message); err != nil {
+			return params, err
+		}
+	}
+	label := "Title"
+	if defaults.Title != "" {
+		label += ", - to clear"
+	}
+	if params.Title, err = prom.Ask(label, defaults.Title); err != nil {
+		return params, err
+	}
+	if params.Title == "-" {
+		params.Title = ""
+	}
+	if params.Priority, err = askPriority(prom, out, defaults.Priority); err != nil {
+		return params, err
+	}
+
+	choices := make(map[string][]db.CatalogEntry)
+	for _, kind := range []string{db.CatalogDevices, db.CatalogSounds} {
+		entries, err := loadCatalog(ctx, cfg, store, kind)
+		if err != nil {
+			logger.Warn("unable to load choices", "kind", kind, "error", err)
+		}
+		choices[kind] = entries
+	}
+	if params.Device, err = askChoice(prom, out, "Device (blank for all)", defaults.Device, choices[db.CatalogDevices]); err != nil {
+		return params, err
+	}
+	if params.Sound, err = askChoice(prom, out, "Sound (blank for default)", defaults.Sound, choices[db.CatalogSounds]); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// confirmComposeSend previews params, and sends them with sendOpts once
+// confirmed.
+func confirmComposeSend(cmd *cobra.Command, prom *prompter, cfg *config.Config, params pushover.SendParams, sendOpts sendOptions) error {
+	writeComposePreview(cmd.OutOrStdout(), params)
+	answer, err := prom.Ask("Send? (y/n)", "y")
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(strings.ToLower(answer), "y") {
+		cmd.Println("Discarded.")
+		return nil
+	}
+
+	if sendOpts.longMessages, err = cfg.LongMessagePolicy(); err != nil {
+		return err
+	}
+	return dispatchSend(cmd, cfg, params, sendOpts)
+}
+
+func askPriority(prom *prompter, out io.Writer, fallback int) (int, error) {
+	for {
+		answer, err := prom.Ask("Priority (-2 to 2)", strconv.Itoa(fallback))
+		if err != nil {
+			return 0, err
+		}
+		priority, err := strconv.Atoi(answer)
+		if err == nil && priority >= -2 && priority <= 2 {
+			return priority, nil
+		}
+		_, _ = fmt.Fprintln(out, "Priority must be a number between -2 and 2.")
+	}
+}
+
+// askChoice prompts until the answer names a known entry. Unique prefixes are
+// expanded, "?" lists the options, and "-" clears a default. Without cached
+// options any answer is accepted.
+func askChoice(prom *prompter, out io.Writer, label, fallback string, options []db.CatalogEntry) (string, error) {
+	if len(options) > 0 {
+		label += ", ? to list"
+	}
+	for {
+		answer, err := prom.Ask(label, fallback)
+		if err != nil {
+			return "", err
+		}
+		switch answer {
+		case "", "-":
+			return "", nil
+		case "?":
+			for _, option := range options {
+				if option.Label != "" {
+					_, _ = fmt.Fprintf(out, "  %-12s %s\n", option.Name, option.Label)
+				} else {
+					_, _ = fmt.Fprintf(out, "  %s\n", option.Name)
+				}
+			}
+			continue
+		}
+		if len(options) == 0 {
+			return answer, nil
+		}
+
+		matches := matchChoices(answer, options)
+		switch len(matches) {
+		case 1:
+			return matches[0], nil
+		case 0:
+			_, _ = fmt.Fprintf(out, "Unknown choice %q; type ? to list options.\n", answer)
+		default:
+			_, _ = fmt.Fprintf(out, "%q matches %s.\n", answer, strings.Join(matches, ", "))
+		}
+	}
+}
+
+// matchChoices returns the exact match for answer, or every option it prefixes.
+func matchChoices(answer string, options []db.CatalogEntry) []string {
+	var matches []string
+	for _, option := range options {
+		if strings.EqualFold(option.Name, answer) {
+			return []string{option.Name}
+		}
+		if strings.HasPrefix(strings.ToLower(option.Name), strings.ToLower(answer)) {
+			matches = append(matches, option.Name)
+		}
+	}
+	return matches
+}
+
+func writeComposePreview(out io.Writer, params pushover.SendParams) {
+	orDefault := func(value, fallback string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	}
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, "Preview:")
+	_, _ = fmt.Fprintf(out, "  Title:    %s\n", orDefault(params.Title, "(app name)"))
+	_, _ = fmt.Fprintf(out, "  Message:  %s\n", params.Message)
+	_, _ = fmt.Fprintf(out, "  Priority: %d\n", params.Priority)
+	_, _ = fmt.Fprintf(out, "  Device:   %s\n", orDefault(params.Device, "(all devices)"))
+	_, _ = fmt.Fprintf(out, "  Sound:    %s\n", orDefault(params.Sound, "(default)"))
+	_, _ = fmt.Fprintln(out)
+}
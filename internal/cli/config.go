@@ -1,5 +1,5 @@
 // ABOUTME: Config command for displaying current configuration.
-// ABOUTME: Shows config file path and contents in TOML format.
+// ABOUTME: Shows the config file paths and merged contents in TOML format.
 package cli
 
 import (
@@ -38,7 +38,13 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("encode config: %w", err)
 	}
 
-	cmd.Printf("# %s\n%s", cfgPath, string(data))
+	cmd.Printf("# %s\n", cfgPath)
+	if files := cfg.Files(); len(files) > 1 {
+		for _, file := range files[1:] {
+			cmd.Printf("# + %s\n", file)
+		}
+	}
+	cmd.Print(string(data))
 	if len(data) == 0 || data[len(data)-1] != '\n' {
 		cmd.Println()
 	}
@@ -0,0 +1,411 @@
+// ABOUTME: Daemon command for running background monitoring jobs.
+// ABOUTME: Watches heartbeats and answers control commands on a local socket.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/daemon"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/notify"
+	"github.com/harper/push/internal/replicate"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/cobra"
+)
+
+func newDaemonCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "daemon",
+		Annotations: map[string]string{serverAnnotation: "true"},
+		Short:       "Run background jobs such as heartbeats, URL, file, and system monitors, calendar alerts, digests, and database replication and maintenance",
+		Args:        cobra.NoArgs,
+		RunE:        runDaemon,
+	}
+
+	cmd.Flags().Duration("interval", time.Minute, "how often to run each job")
+
+	cmd.AddCommand(newDaemonStatusCmd(), newDaemonPollCmd(), newDaemonReloadCmd(), newDaemonStopCmd())
+
+	return cmd
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	p := &daemonProcess{interval: interval, store: store, started: time.Now()}
+	runner, err := p.build()
+	if err != nil {
+		return err
+	}
+	p.runner = runner
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	p.stop = stop
+
+	socket, err := daemonSocketPath()
+	if err != nil {
+		return err
+	}
+	control, err := daemon.ListenControl(socket, p.handle)
+	if err != nil {
+		return err
+	}
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		if err := control.Serve(ctx); err != nil {
+			logger.Warn("control socket stopped", "error", err)
+		}
+	}()
+	cfgPath := p.configPath
+	go func() {
+		err := config.Watch(ctx, cfgPath, func() {
+			if err := p.reload(); err != nil {
+				logger.Warn("config changed but could not be reloaded; keeping the running jobs", "error", err)
+			}
+		})
+		if err != nil {
+			logger.Warn("not watching config for changes", "error", err)
+		}
+	}()
+	// Let a pending stop request get its answer before exiting.
+	defer func() {
+		stop()
+		<-served
+	}()
+
+	logger.Info("starting daemon", "jobs", len(runner.Jobs()), "interval", interval, "socket", socket)
+	for {
+		p.mu.Lock()
+		if p.next != nil {
+			p.runner, p.next = p.next, nil
+			logger.Info("reloaded configuration", "jobs", len(p.runner.Jobs()))
+		}
+		runner := p.runner
+		runCtx, cancel := context.WithCancel(ctx)
+		p.cancel = cancel
+		p.mu.Unlock()
+
+		err := runner.Run(runCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		p.mu.Lock()
+		reloading := p.next != nil
+		p.mu.Unlock()
+		if !reloading {
+			return err
+		}
+	}
+}
+
+// daemonSocketPath returns where the daemon listens for control commands.
+func daemonSocketPath() (string, error) {
+	dir, err := resolveDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "daemon.sock"), nil
+}
+
+// daemonProcess is the running daemon as seen from its control socket.
+type daemonProcess struct {
+	interval time.Duration
+	store    *db.Store
+	started  time.Time
+	stop     context.CancelFunc
+
+	mu         sync.Mutex
+	configPath string
+	reloaded   time.Time
+	runner     *daemon.Runner
+	cancel     context.CancelFunc
+	// next replaces runner once the current one stops for a reload.
+	next *daemon.Runner
+}
+
+// build reads the config and returns a runner for its jobs.
+func (p *daemonProcess) build() (*daemon.Runner, error) {
+	cfg, path, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := newClientFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	notifier, err := notify.New(cfg, "", client)
+	if err != nil {
+		return nil, err
+	}
+
+	monitorClient, err := newMonitorClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	runner := daemon.NewRunner(logger)
+	runner.Add(daemon.HeartbeatJob(p.store, notifier, p.interval))
+	runner.Add(daemon.ReceiptsJob(p.store, receiptClients(cfg), p.interval, logger))
+	runner.Add(daemon.MonitorsJob(p.store, notifier, monitorClient, p.interval, logger))
+	runner.Add(daemon.FSWatchJob(p.store, notifier, p.interval, logger))
+	runner.Add(daemon.SysmonJob(p.store, notifier, p.interval, logger))
+	runner.Add(daemon.CalendarJob(p.store, notifier, monitorClient, p.interval, logger))
+	runner.Add(daemon.FeedsJob(p.store, notifier, monitorClient, p.interval, logger))
+	runner.Add(daemon.ScheduledJob(p.store, notifier, min(p.interval, daemon.ScheduledTick), logger))
+	schedule, scheduled, err := cfg.DigestSchedule()
+	if err != nil {
+		return nil, err
+	}
+	if scheduled {
+		runner.Add(daemon.DigestJob(p.store, notifier, daemon.DigestOptions{
+			Previous:    schedule.Previous,
+			Priority:    cfg.Digest.Priority,
+			MinPriority: cfg.Digest.MinPriority,
+		}, p.interval, logger))
+	}
+	maintenance, maintained, err := cfg.MaintenanceSchedule()
+	if err != nil {
+		return nil, err
+	}
+	if maintained {
+		runner.Add(daemon.MaintenanceJob(p.store, daemon.MaintenanceOptions{
+			Interval:  maintenance.Interval,
+			Idle:      maintenance.Idle,
+			Retention: maintenance.Retention,
+		}, p.interval, logger))
+	}
+	if err := p.addReplication(runner, cfg); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.configPath = path
+	p.mu.Unlock()
+	return runner, nil
+}
+
+// addReplication adds the job copying the database to the [replicate] target,
+// if one is set.
+func (p *daemonProcess) addReplication(runner *daemon.Runner, cfg *config.Config) error {
+	r, ok, err := cfg.Replication()
+	if err != nil || !ok {
+		return err
+	}
+	if p.store.InMemory() {
+		logger.Warn("not replicating an in-memory database", "target", r.String())
+		return nil
+	}
+	target, err := replicate.New(r)
+	if err != nil {
+		return err
+	}
+	statusPath, err := replicateStatusPath()
+	if err != nil {
+		return err
+	}
+	snapshot, err := toml.Marshal(cfg.WithoutSecrets())
+	if err != nil {
+		return fmt.Errorf("encoding config snapshot: %w", err)
+	}
+	runner.Add(daemon.ReplicateJob(p.store, target, replicate.Options{
+		Retain:     r.Retain,
+		StatusPath: statusPath,
+		Config:     snapshot,
+	}, r.Interval, logger))
+	return nil
+}
+
+// replicateStatusPath returns where the daemon records how replication went.
+func replicateStatusPath() (string, error) {
+	dir, err := resolveDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "replicate.json"), nil
+}
+
+// reload rebuilds the jobs from the config file and restarts them. It builds
+// first so a broken config leaves the current jobs running.
+func (p *daemonProcess) reload() error {
+	runner, err := p.build()
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.next = runner
+	p.reloaded = time.Now()
+	if p.cancel != nil {
+		p.cancel()
+	}
+	return nil
+}
+
+func (p *daemonProcess) handle(ctx context.Context, req daemon.Request) daemon.Response {
+	logger.Debug("control request", "command", req.Command)
+	switch req.Command {
+	case daemon.CommandStatus:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return daemon.Response{OK: true, Status: &daemon.Status{
+			PID:       os.Getpid(),
+			StartedAt: p.started,
+			Config:    p.configPath,
+			Reloaded:  p.reloaded,
+			Jobs:      p.runner.Status(),
+		}}
+	case daemon.CommandPoll:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.runner.RunNow()
+		return daemon.Response{OK: true}
+	case daemon.CommandReload:
+		if err := p.reload(); err != nil {
+			return daemon.Response{Error: err.Error()}
+		}
+		return daemon.Response{OK: true}
+	case daemon.CommandStop:
+		logger.Info("stopping daemon on request")
+		p.stop()
+		return daemon.Response{OK: true}
+	default:
+		return daemon.Response{Error: fmt.Sprintf("unknown command %q", req.Command)}
+	}
+}
+
+func newDaemonStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the daemon is running and how its jobs are doing",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := callDaemon(cmd, daemon.CommandStatus)
+			if err != nil {
+				return err
+			}
+			st := resp.Status
+
+			if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(st)
+			}
+
+			out := cmd.OutOrStdout()
+			_, _ = fmt.Fprintf(out, "Running:  pid %d, up %s\n", st.PID, time.Since(st.StartedAt).Round(time.Second))
+			if st.Config != "" {
+				_, _ = fmt.Fprintf(out, "Config:   %s\n", st.Config)
+			}
+			if !st.Reloaded.IsZero() {
+				_, _ = fmt.Fprintf(out, "Reloaded: %s\n", st.Reloaded.Local().Format(time.RFC3339))
+			}
+			_, _ = fmt.Fprintln(out)
+
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(tw, "JOB\tEVERY\tRUNS\tLAST RUN\tRESULT")
+			for _, job := range st.Jobs {
+				last, result := "-", "-"
+				if !job.LastRun.IsZero() {
+					last = job.LastRun.Local().Format(time.RFC3339)
+					result = "ok"
+				}
+				if job.LastError != "" {
+					result = job.LastError
+				}
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", job.Name, job.Every, job.Runs, last, result)
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().Bool("json", false, "output JSON")
+	return cmd
+}
+
+func newDaemonPollCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "poll",
+		Short: "Run the daemon's jobs now instead of waiting for the next interval",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := callDaemon(cmd, daemon.CommandPoll); err != nil {
+				return err
+			}
+			cmd.Println("✓ Jobs queued to run now.")
+			return nil
+		},
+	}
+}
+
+func newDaemonReloadCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reload",
+		Short: "Re-read the config file and restart the daemon's jobs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := callDaemon(cmd, daemon.CommandReload); err != nil {
+				return err
+			}
+			cmd.Println("✓ Configuration reloaded.")
+			return nil
+		},
+	}
+}
+
+func newDaemonStopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Shut the daemon down cleanly",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := callDaemon(cmd, daemon.CommandStop); err != nil {
+				return err
+			}
+			cmd.Println("✓ Daemon stopping.")
+			return nil
+		},
+	}
+}
+
+// callDaemon sends command to the running daemon, explaining a missing one.
+func callDaemon(cmd *cobra.Command, command string) (*daemon.Response, error) {
+	socket, err := daemonSocketPath()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := daemon.CallControl(cmd.Context(), socket, command)
+	if err != nil && resp == nil {
+		return nil, fmt.Errorf("daemon not running (no answer on %s): %w", socket, err)
+	}
+	return resp, err
+}
+
+// noteDaemonStopped tells the user when no daemon is running to send what
+// they just scheduled.
+func noteDaemonStopped(cmd *cobra.Command, what string) {
+	if _, err := callDaemon(cmd, daemon.CommandStatus); err != nil {
+		cmd.Printf("push daemon isn't running; the %s goes out once it starts.\n", what)
+	}
+}
@@ -0,0 +1,270 @@
+// ABOUTME: Database maintenance commands for the local SQLite store.
+// ABOUTME: Provides vacuum, integrity-check, stats, path, and replicate status subcommands.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/replicate"
+	"github.com/spf13/cobra"
+)
+
+func newDBCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Inspect and maintain the local history database",
+	}
+
+	cmd.AddCommand(
+		newDBPathCmd(),
+		newDBStatsCmd(),
+		newDBVacuumCmd(),
+		newDBIntegrityCheckCmd(),
+		newDBReplicateCmd(),
+	)
+
+	return cmd
+}
+
+func newDBPathCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "path",
+		Short: "Print the database file path",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := databasePath()
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), path)
+			return err
+		},
+	}
+}
+
+func newDBStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "stats",
+		Short:       "Show file size, row counts, table/index sizes, and the last maintenance",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Args:        cobra.NoArgs,
+		RunE:        runDBStats,
+	}
+	cmd.Flags().Bool("json", false, "output JSON")
+	return cmd
+}
+
+func runDBStats(cmd *cobra.Command, args []string) error {
+	store, path, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	stats, err := store.Stats(cmd.Context())
+	if err != nil {
+		return err
+	}
+	maintenance, err := store.MaintenanceStatus(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	dbSize, walSize := fileSize(path), fileSize(path+"-wal")
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]any{
+			"path":        path,
+			"file_size":   dbSize,
+			"wal_size":    walSize,
+			"database":    stats,
+			"maintenance": maintenance,
+		})
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Path:       %s\n", path)
+	_, _ = fmt.Fprintf(out, "File size:  %s (WAL %s)\n", formatBytes(dbSize), formatBytes(walSize))
+	_, _ = fmt.Fprintf(out, "Pages:      %d × %d bytes, %d free\n", stats.PageCount, stats.PageSize, stats.FreePages)
+	_, _ = fmt.Fprintf(out, "Maintained: %s\n\n", describeMaintenance(maintenance))
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tTYPE\tTABLE\tROWS\tSIZE")
+	for _, obj := range stats.Objects {
+		rows := "-"
+		if obj.Type == "table" {
+			rows = fmt.Sprintf("%d", obj.Rows)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", obj.Name, obj.Type, obj.Table, rows, formatBytes(obj.Bytes))
+	}
+	return tw.Flush()
+}
+
+// describeMaintenance summarizes push daemon's last maintenance run.
+func describeMaintenance(m db.MaintenanceState) string {
+	if m.LastRun().IsZero() {
+		return "never (push daemon runs maintenance when idle)"
+	}
+	stamp := func(at time.Time) string { return at.Local().Format("2006-01-02 15:04") }
+	parts := []string{"optimized " + stamp(m.OptimizedAt), fmt.Sprintf("vacuumed %s (freed %s)", stamp(m.VacuumedAt), formatBytes(m.FreedBytes))}
+	if !m.PrunedAt.IsZero() {
+		parts = append(parts, fmt.Sprintf("pruned %s (%d messages, %d sent)", stamp(m.PrunedAt), m.PrunedMessages, m.PrunedSent))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func newDBVacuumCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         "vacuum",
+		Short:       "Rebuild the database to reclaim unused space",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, path, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			before := fileSize(path)
+			if err := store.Vacuum(cmd.Context()); err != nil {
+				return err
+			}
+			cmd.Printf("✓ Vacuumed %s: %s → %s\n", path, formatBytes(before), formatBytes(fileSize(path)))
+			return nil
+		},
+	}
+}
+
+func newDBIntegrityCheckCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         "integrity-check",
+		Short:       "Run SQLite's integrity check",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			problems, err := store.IntegrityCheck(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if len(problems) == 0 {
+				cmd.Println("✓ Integrity check passed.")
+				return nil
+			}
+			for _, problem := range problems {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), problem)
+			}
+			return fmt.Errorf("integrity check found %d problem(s)", len(problems))
+		},
+	}
+}
+
+func newDBReplicateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replicate",
+		Short: "Inspect replication of the database by push daemon",
+	}
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "Show the replication target and how the last replication went",
+		Args:  cobra.NoArgs,
+		RunE:  runDBReplicateStatus,
+	}
+	status.Flags().Bool("json", false, "output JSON")
+	cmd.AddCommand(status)
+	return cmd
+}
+
+func runDBReplicateStatus(cmd *cobra.Command, args []string) error {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	r, configured, err := cfg.Replication()
+	if err != nil {
+		return err
+	}
+	statusPath, err := replicateStatusPath()
+	if err != nil {
+		return err
+	}
+	status, err := replicate.LoadStatus(statusPath)
+	if err != nil {
+		return err
+	}
+	// A status left from an earlier target doesn't describe this one.
+	if configured && status.Target != r.String() {
+		status = replicate.Status{}
+	}
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		out := map[string]any{"configured": configured}
+		if configured {
+			out["target"] = r.String()
+			out["interval"] = r.Interval.String()
+			out["retain"] = r.Retain
+			out["status"] = status
+		}
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	if !configured {
+		cmd.Println("Replication is off. Set [replicate] target in the config and run 'push daemon'.")
+		return nil
+	}
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Target:        %s\n", r)
+	_, _ = fmt.Fprintf(out, "Every:         %s, keeping %d replicas\n", r.Interval, r.Retain)
+	if status.LastRun.IsZero() {
+		_, _ = fmt.Fprintln(out, "Last check:    never; is 'push daemon' running?")
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "Last check:    %s\n", status.LastRun.Local().Format(time.RFC3339))
+	if status.LastReplicated.IsZero() {
+		_, _ = fmt.Fprintln(out, "Last replica:  none yet")
+	} else {
+		_, _ = fmt.Fprintf(out, "Last replica:  %s (%s) at %s\n", status.LastReplica, formatBytes(status.LastSize), status.LastReplicated.Local().Format(time.RFC3339))
+		_, _ = fmt.Fprintf(out, "Replicas:      %d\n", status.Replicas)
+	}
+	if status.LastError != "" {
+		_, _ = fmt.Fprintf(out, "Last error:    %s\n", status.LastError)
+	}
+	return nil
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
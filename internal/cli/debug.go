@@ -0,0 +1,44 @@
+// ABOUTME: Debug logging setup for Pushover API traffic.
+// ABOUTME: Routes client request logs to the structured logger or a --debug-file.
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+var debugLog struct {
+	logger pushover.Logger
+	file   *os.File
+}
+
+// setupDebugLog installs the request logger: text lines in --debug-file, or
+// debug records on the structured logger when --debug or --log-level debug
+// lets them through.
+func setupDebugLog(cmd *cobra.Command, args []string) error {
+	if opts.debugFile == "" {
+		if logger.Enabled(cmd.Context(), slog.LevelDebug) {
+			debugLog.logger = pushover.NewSlogLogger(logger)
+		}
+		return nil
+	}
+	file, err := os.OpenFile(opts.debugFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open debug log: %w", err)
+	}
+	debugLog.file = file
+	debugLog.logger = pushover.NewWriterLogger(file)
+	return nil
+}
+
+func closeDebugLog() {
+	if debugLog.file != nil {
+		_ = debugLog.file.Close()
+	}
+	debugLog.logger = nil
+	debugLog.file = nil
+}
@@ -0,0 +1,97 @@
+// ABOUTME: Digest command summarizing received messages by app and priority.
+// ABOUTME: Prints the summary as Markdown or JSON, or sends it as one notification.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/digest"
+	"github.com/spf13/cobra"
+)
+
+func newDigestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "digest",
+		Short:       "Summarize received messages by app and priority",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Summarize the messages received in a window, one line per app with its message count by priority and its latest message. The digest is printed as Markdown, or sent as a single notification with --send. To get one every day or week, set [digest] at in the config and run 'push daemon'.",
+		Args:        cobra.NoArgs,
+		RunE:        runDigest,
+	}
+	cmd.Flags().String("since", "24h", "start of the window (e.g. yesterday, 7d, 2025-01-02)")
+	cmd.Flags().String("until", "", "end of the window (default: now)")
+	cmd.Flags().Int("min-priority", 0, "only count messages at or above this priority (-2 to 2)")
+	cmd.Flags().Bool("send", false, "send the digest as a notification instead of printing it")
+	cmd.Flags().IntP("priority", "p", 0, "with --send, priority of the notification (-2 to 2)")
+	cmd.Flags().Bool("json", false, "output JSON")
+	cmd.MarkFlagsMutuallyExclusive("send", "json")
+	return cmd
+}
+
+func runDigest(cmd *cobra.Command, args []string) error {
+	sinceStr, _ := cmd.Flags().GetString("since")
+	since, err := parseSince(sinceStr)
+	if err != nil {
+		return fmt.Errorf("parse --since: %w", err)
+	}
+	until := time.Now()
+	if value, _ := cmd.Flags().GetString("until"); value != "" {
+		if until, err = parseSince(value); err != nil {
+			return fmt.Errorf("parse --until: %w", err)
+		}
+	}
+	if !since.Before(until) {
+		return fmt.Errorf("--since must be before --until")
+	}
+	filter := db.MessageFilter{Since: &since, Until: &until}
+	if cmd.Flags().Changed("min-priority") {
+		priority, _ := cmd.Flags().GetInt("min-priority")
+		if priority < -2 || priority > 2 {
+			return fmt.Errorf("--min-priority must be between -2 and 2")
+		}
+		filter.MinPriority = &priority
+	}
+	priority, _ := cmd.Flags().GetInt("priority")
+	if priority < -2 || priority > 2 {
+		return fmt.Errorf("priority must be between -2 and 2")
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	d, err := digest.Load(cmd.Context(), store, filter)
+	if err != nil {
+		return err
+	}
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+	if send, _ := cmd.Flags().GetBool("send"); !send {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), d.Markdown())
+		return err
+	}
+	if d.Total == 0 {
+		cmd.Printf("No messages received since %s; nothing sent.\n", since.Local().Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.ValidateSend(); err != nil {
+		return err
+	}
+	params := d.Notification()
+	params.Priority = priority
+	return dispatchSend(cmd, cfg, params, sendOptions{})
+}
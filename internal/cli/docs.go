@@ -0,0 +1,50 @@
+// ABOUTME: Docs command for generating reference documentation.
+// ABOUTME: Writes man pages or Markdown for the whole command tree via cobra/doc.
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+func newDocsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:       "docs man|markdown",
+		Short:     "Generate man pages or Markdown reference docs",
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"man", "markdown"},
+		RunE:      runDocs,
+	}
+
+	cmd.Flags().String("out", ".", "directory to write the generated files to")
+
+	return cmd
+}
+
+func runDocs(cmd *cobra.Command, args []string) error {
+	outDir, _ := cmd.Flags().GetString("out")
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	root := cmd.Root()
+	root.DisableAutoGenTag = true
+
+	switch args[0] {
+	case "man":
+		header := &doc.GenManHeader{Title: "PUSH", Section: "1", Source: "push"}
+		if err := doc.GenManTree(root, header, outDir); err != nil {
+			return fmt.Errorf("generate man pages: %w", err)
+		}
+	case "markdown":
+		if err := doc.GenMarkdownTree(root, outDir); err != nil {
+			return fmt.Errorf("generate markdown: %w", err)
+		}
+	}
+
+	cmd.Printf("Wrote %s docs to %s\n", args[0], outDir)
+	return nil
+}
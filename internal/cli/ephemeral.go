@@ -0,0 +1,35 @@
+// ABOUTME: Ephemeral mode, where history lives in memory and nothing is written to disk.
+// ABOUTME: Turns away commands that only work with what earlier runs stored.
+package cli
+
+import (
+	"fmt"
+
+	"github.com/harper/push/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// storedAnnotation marks commands that read history or state kept between
+// runs, or schedule work for 'push daemon'. An in-memory database can't give
+// them anything to work with.
+const storedAnnotation = "push/stored"
+
+// checkEphemeral fails commands marked with storedAnnotation, or under one,
+// when the database is in memory.
+func checkEphemeral(cmd *cobra.Command) error {
+	stored := false
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[storedAnnotation] != "" {
+			stored = true
+			break
+		}
+	}
+	if !stored {
+		return nil
+	}
+	// A bad config is left for the command itself to report.
+	if path, err := databasePath(); err != nil || path != db.Memory {
+		return nil
+	}
+	return fmt.Errorf("'%s' needs stored history, and nothing is stored in ephemeral mode (--ephemeral or database_path = %q)", cmd.CommandPath(), db.Memory)
+}
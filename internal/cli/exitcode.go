@@ -0,0 +1,55 @@
+// ABOUTME: Exit status contract for scripts wrapping push.
+// ABOUTME: Maps failures to distinct codes: config, auth, rate limit, network.
+package cli
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/messages"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// Exit codes returned by the push binary. Anything not covered by a more
+// specific code, including usage errors, exits with ExitFailure.
+const (
+	ExitOK          = 0
+	ExitFailure     = 1
+	ExitConfig      = 2
+	ExitAuth        = 3
+	ExitRateLimited = 4
+	ExitNetwork     = 5
+)
+
+// ExitCode returns the process exit status for an error from Execute.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var apiErr *pushover.APIError
+	switch {
+	case errors.Is(err, pushover.ErrInvalidToken),
+		errors.Is(err, pushover.ErrInvalidUser),
+		errors.Is(err, pushover.ErrDeviceInvalid),
+		errors.Is(err, pushover.ErrTwoFactorRequired),
+		errors.Is(err, errTwoFactorPending):
+		return ExitAuth
+	case errors.As(err, &apiErr) && (apiErr.Status == http.StatusUnauthorized || apiErr.Status == http.StatusForbidden):
+		return ExitAuth
+	case errors.Is(err, pushover.ErrRateLimited), errors.Is(err, messages.ErrSendBudgetExceeded):
+		return ExitRateLimited
+	case errors.Is(err, config.ErrInvalid):
+		return ExitConfig
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var netErr net.Error
+	if errors.As(err, &certErr) || errors.As(err, &netErr) {
+		return ExitNetwork
+	}
+	return ExitFailure
+}
@@ -0,0 +1,37 @@
+// ABOUTME: Tests for the exit status contract.
+// ABOUTME: Pins one wrapped error per exit code so a lost %w can't collapse them to 1.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/messages"
+	"github.com/harper/push/pkg/pushover"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"invalid config", fmt.Errorf("load config: %w", config.ErrInvalid), ExitConfig},
+		{"invalid token", fmt.Errorf("send: %w", pushover.ErrInvalidToken), ExitAuth},
+		{"401 response", fmt.Errorf("fetch: %w", &pushover.APIError{Status: http.StatusUnauthorized}), ExitAuth},
+		{"rate limited", fmt.Errorf("send: %w", pushover.ErrRateLimited), ExitRateLimited},
+		{"send budget", fmt.Errorf("send: %w", messages.ErrSendBudgetExceeded), ExitRateLimited},
+		{"network", fmt.Errorf("send: %w", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}), ExitNetwork},
+		{"other", errors.New("something broke"), ExitFailure},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%s: %v) = %d, want %d", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
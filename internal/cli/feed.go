@@ -0,0 +1,209 @@
+// ABOUTME: Feed command for notifications about new items in RSS and Atom feeds.
+// ABOUTME: Adds the feeds the daemon polls, and lists and removes them.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/feed"
+	"github.com/spf13/cobra"
+)
+
+func newFeedCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "feed",
+		Short:       "Get notified about new items in RSS and Atom feeds, polled by 'push daemon'",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Follow RSS and Atom feeds, such as a GitHub project's releases.atom, a blog, or a status page. 'push daemon' polls each feed and sends a notification for every new item, with its link attached; items already in the feed when it's added are not sent.",
+	}
+
+	cmd.AddCommand(newFeedAddCmd(), newFeedListCmd(), newFeedRemoveCmd())
+
+	return cmd
+}
+
+func newFeedAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <url>",
+		Short: "Follow a feed, or change the settings of one",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runFeedAdd,
+	}
+	cmd.Flags().String("name", "", "name for the feed, used as the notification title (default: the feed's title)")
+	cmd.Flags().String("filter", "", "only notify about items whose title matches this regular expression, e.g. '^v[0-9.]+$'")
+	cmd.Flags().Duration("interval", 15*time.Minute, "how often to poll")
+	cmd.Flags().IntP("priority", "p", 0, "priority of the notifications (-2 to 2)")
+	return cmd
+}
+
+func runFeedAdd(cmd *cobra.Command, args []string) error {
+	rec, filter, err := feedFromFlags(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	client, err := newMonitorClient(cfg)
+	if err != nil {
+		return err
+	}
+	// Fetch once now so a wrong address fails here rather than in the daemon,
+	// and so the items already there aren't sent as new.
+	res, err := feed.Fetch(cmd.Context(), client, rec.URL, "", "")
+	if err != nil {
+		// Not wrapped: the network hints are about reaching Pushover.
+		return fmt.Errorf("feed unavailable: %v", err)
+	}
+
+	if rec.Name == "" {
+		rec.Name = res.Feed.Title
+	}
+	if rec.Name == "" {
+		u, _ := url.Parse(rec.URL)
+		rec.Name = u.Hostname()
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now()
+	rec.ETag, rec.LastModified, rec.LastChecked = res.ETag, res.LastModified, now
+	if err := store.SaveFeed(cmd.Context(), rec); err != nil {
+		return err
+	}
+	guids, latest, matching := seenItems(res.Feed.Items, filter)
+	if err := store.MarkFeedItemsSeen(cmd.Context(), rec.Name, guids, now); err != nil {
+		return err
+	}
+
+	counted := fmt.Sprintf("%d items", len(res.Feed.Items))
+	if filter != nil {
+		counted = fmt.Sprintf("%d of %d items match the filter", matching, len(res.Feed.Items))
+	}
+	cmd.Printf("✓ Following %q every %s (%s).\n", rec.Name, roughDuration(rec.Every), counted)
+	if latest != "" {
+		cmd.Printf("  Latest: %s\n", latest)
+	}
+	cmd.Println("New items are sent while 'push daemon' is running.")
+	return nil
+}
+
+// feedFromFlags checks the feed add flags and URL, returning the feed's
+// settings and its compiled filter. Name is empty unless --name was given.
+func feedFromFlags(cmd *cobra.Command, rawURL string) (db.FeedRecord, *regexp.Regexp, error) {
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if interval < time.Minute {
+		return db.FeedRecord{}, nil, fmt.Errorf("--interval must be at least 1m")
+	}
+	priority, _ := cmd.Flags().GetInt("priority")
+	if priority < -2 || priority > 2 {
+		return db.FeedRecord{}, nil, fmt.Errorf("priority must be between -2 and 2")
+	}
+	expr, _ := cmd.Flags().GetString("filter")
+	filter, err := feed.CompileFilter(expr)
+	if err != nil {
+		return db.FeedRecord{}, nil, err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return db.FeedRecord{}, nil, fmt.Errorf("feed url must be an http or https URL")
+	}
+	name, _ := cmd.Flags().GetString("name")
+	return db.FeedRecord{Name: name, URL: u.String(), Filter: expr, Priority: priority, Every: interval}, filter, nil
+}
+
+// seenItems returns the GUIDs of a feed's current items, and the title of the
+// newest one matching filter along with how many match.
+func seenItems(items []feed.Item, filter *regexp.Regexp) (guids []string, latest string, matching int) {
+	guids = make([]string, 0, len(items))
+	for _, item := range items {
+		guids = append(guids, item.GUID)
+		if filter == nil || filter.MatchString(item.Title) {
+			if matching == 0 {
+				latest = item.Title
+			}
+			matching++
+		}
+	}
+	return guids, latest, matching
+}
+
+func newFeedListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List followed feeds and how their last poll went",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			feeds, err := store.ListFeeds(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(feeds)
+			}
+			if len(feeds) == 0 {
+				cmd.Println("No feeds. Follow one with 'push feed add <url>'.")
+				return nil
+			}
+			for _, f := range feeds {
+				cmd.Printf("%s: %s every %s\n", f.Name, f.URL, roughDuration(f.Every))
+				if f.Filter != "" {
+					cmd.Printf("  filter %s\n", f.Filter)
+				}
+				if f.LastItem != "" {
+					cmd.Printf("  last sent: %s\n", f.LastItem)
+				}
+				if f.LastError != "" {
+					cmd.Printf("  last poll failed: %s\n", f.LastError)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("json", false, "output JSON")
+	return cmd
+}
+
+func newFeedRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Stop following a feed",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			removed, err := store.DeleteFeed(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("no feed named %q", args[0])
+			}
+			cmd.Printf("✓ Feed %q removed.\n", args[0])
+			return nil
+		},
+	}
+}
@@ -0,0 +1,74 @@
+// ABOUTME: --format Go-template output for listing commands.
+// ABOUTME: Renders one line per record so scripts can pick fields without jq.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+// formatEscapes turns the escapes people type inside shell single quotes
+// into the characters they mean.
+var formatEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\\`, `\`)
+
+// formatFuncs are available to --format templates in addition to the
+// text/template builtins.
+var formatFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		var b strings.Builder
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(b.String(), "\n"), nil
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"oneline": func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	},
+}
+
+// addFormatFlag registers --format on cmd; fields names the type whose
+// fields the template can use.
+func addFormatFlag(cmd *cobra.Command, fields string) {
+	cmd.Flags().String("format", "", fmt.Sprintf("print each %s through this Go template (e.g. '{{.PushoverID}}\\t{{.Title}}')", fields))
+}
+
+// formatTemplate parses --format, returning nil when it is not set.
+func formatTemplate(cmd *cobra.Command) (*template.Template, error) {
+	text, _ := cmd.Flags().GetString("format")
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("format").Funcs(formatFuncs).Option("missingkey=error").Parse(formatEscapes.Replace(text))
+	if err != nil {
+		return nil, fmt.Errorf("parse --format: %w", err)
+	}
+	return tmpl, nil
+}
+
+// writeFormatted renders each record through tmpl on its own line.
+func writeFormatted[T any](w io.Writer, tmpl *template.Template, records []T) error {
+	var b strings.Builder
+	for _, rec := range records {
+		b.Reset()
+		if err := tmpl.Execute(&b, rec); err != nil {
+			return fmt.Errorf("render --format: %w", err)
+		}
+		line := b.String()
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// ABOUTME: Fswatch command for notifications about files appearing or changing.
+// ABOUTME: Adds, lists, and removes the filesystem watches the daemon runs.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/fswatch"
+	"github.com/spf13/cobra"
+)
+
+func newFSWatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "fswatch <path>",
+		Short:       "Get notified when files appear or change in a directory, watched by 'push daemon'",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Register a directory, or a single file such as a backup completion marker, for 'push daemon' to watch. Events that match --pattern and --event are collected until the path has been quiet for a couple of seconds and then sent as one notification, so a large copy or a burst of files doesn't flood you. Directories are watched without their subdirectories. The daemon picks up new watches on its next tick.",
+		Args:        cobra.ExactArgs(1),
+		RunE:        runFSWatch,
+	}
+	cmd.Flags().String("name", "", "name used in notifications and to remove it (default: the path's last element)")
+	cmd.Flags().String("pattern", "", "only files whose names match this glob, e.g. '*.log'")
+	cmd.Flags().String("event", fswatch.DefaultEvents, "events to notify about: create, modify, remove, rename")
+	cmd.Flags().IntP("priority", "p", 0, "priority of the notifications (-2 to 2)")
+
+	cmd.AddCommand(newFSWatchListCmd(), newFSWatchRemoveCmd())
+
+	return cmd
+}
+
+func runFSWatch(cmd *cobra.Command, args []string) error {
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+	w := db.FSWatchRecord{Path: path}
+	w.Name, _ = cmd.Flags().GetString("name")
+	if w.Name == "" {
+		w.Name = filepath.Base(path)
+	}
+	w.Pattern, _ = cmd.Flags().GetString("pattern")
+	if _, err := filepath.Match(w.Pattern, ""); err != nil {
+		return fmt.Errorf("--pattern %q: %w", w.Pattern, err)
+	}
+	events, _ := cmd.Flags().GetString("event")
+	if w.Events, err = fswatch.ParseEvents(events); err != nil {
+		return fmt.Errorf("--event: %w", err)
+	}
+	w.Priority, _ = cmd.Flags().GetInt("priority")
+	if w.Priority < -2 || w.Priority > 2 {
+		return fmt.Errorf("priority must be between -2 and 2")
+	}
+	watch, err := fswatch.Resolve(w)
+	if err != nil {
+		return err
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.SaveFSWatch(cmd.Context(), w); err != nil {
+		return err
+	}
+	target := "files in " + watch.Dir
+	if watch.File != "" {
+		target = path
+	} else if w.Pattern != "" {
+		target = fmt.Sprintf("%s files in %s", w.Pattern, watch.Dir)
+	}
+	cmd.Printf("✓ Watching %s as %q for %s events.\n", target, w.Name, w.Events)
+	cmd.Println("Events are sent while 'push daemon' is running.")
+	return nil
+}
+
+func newFSWatchListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List filesystem watches and when they last fired",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			watches, err := store.ListFSWatches(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(watches)
+			}
+			if len(watches) == 0 {
+				cmd.Println("No filesystem watches. Add one with 'push fswatch <path>'.")
+				return nil
+			}
+			for _, w := range watches {
+				filter := ""
+				if w.Pattern != "" {
+					filter = " matching " + w.Pattern
+				}
+				cmd.Printf("%s: %s%s on %s\n", w.Name, w.Path, filter, w.Events)
+				if !w.LastFired.IsZero() {
+					cmd.Printf("  last fired %s: %s\n", w.LastFired.Local().Format(time.RFC3339), w.LastFile)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("json", false, "output JSON")
+	return cmd
+}
+
+func newFSWatchRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Stop watching a path",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			removed, err := store.DeleteFSWatch(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("no filesystem watch named %q", args[0])
+			}
+			cmd.Printf("✓ Watch %q removed.\n", args[0])
+			return nil
+		},
+	}
+}
@@ -0,0 +1,109 @@
+// ABOUTME: Heartbeat command for dead man's switch monitoring of cron jobs.
+// ABOUTME: Records check-ins that the daemon watches for missed windows.
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newHeartbeatCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "heartbeat",
+		Short:       "Record a heartbeat check-in monitored by 'push daemon'",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Args:        cobra.NoArgs,
+		RunE:        runHeartbeat,
+	}
+
+	cmd.Flags().String("name", "", "heartbeat name (e.g. backup)")
+	cmd.Flags().Duration("every", 24*time.Hour, "expected interval between check-ins")
+	_ = cmd.MarkFlagRequired("name")
+
+	cmd.AddCommand(newHeartbeatListCmd(), newHeartbeatRemoveCmd())
+
+	return cmd
+}
+
+func runHeartbeat(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")
+	every, _ := cmd.Flags().GetDuration("every")
+	if every < time.Minute {
+		return fmt.Errorf("--every must be at least 1m")
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now()
+	if err := store.RecordHeartbeat(cmd.Context(), name, every, now); err != nil {
+		return err
+	}
+
+	cmd.Printf("✓ Heartbeat %q recorded. Next check-in due by %s.\n", name, now.Add(every).Format(time.RFC3339))
+	return nil
+}
+
+func newHeartbeatListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List registered heartbeats and their status",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			beats, err := store.ListHeartbeats(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if len(beats) == 0 {
+				cmd.Println("No heartbeats registered.")
+				return nil
+			}
+
+			now := time.Now()
+			for _, beat := range beats {
+				status := "ok"
+				if beat.Overdue(now) {
+					status = "MISSED"
+				}
+				cmd.Printf("%s [%s] every %s, last seen %s\n", beat.Name, status, beat.Every, beat.LastSeen.Local().Format(time.RFC3339))
+			}
+			return nil
+		},
+	}
+}
+
+func newHeartbeatRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Stop monitoring a heartbeat",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			removed, err := store.DeleteHeartbeat(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("no heartbeat named %q", args[0])
+			}
+			cmd.Printf("✓ Heartbeat %q removed.\n", args[0])
+			return nil
+		},
+	}
+}
@@ -4,11 +4,20 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/araddon/dateparse"
 	"github.com/harper/push/internal/config"
 	"github.com/harper/push/internal/db"
-	"github.com/harper/push/internal/pushover"
+	"github.com/harper/push/internal/media"
+	"github.com/harper/push/internal/render"
+	"github.com/harper/push/pkg/pushover"
+	"golang.org/x/term"
 )
 
 func loadConfig() (*config.Config, string, error) {
@@ -23,7 +32,26 @@ func loadConfig() (*config.Config, string, error) {
 	return cfg, cfgPath, nil
 }
 
+// databasePath is the database file: db.Memory with --ephemeral,
+// database_path from the config when set, unless --data picks a data
+// directory, and push.db in the data directory otherwise.
 func databasePath() (string, error) {
+	if opts.ephemeral {
+		return db.Memory, nil
+	}
+	if opts.dataDir == "" {
+		cfg, _, err := loadConfig()
+		if err != nil {
+			return "", err
+		}
+		path, err := cfg.DatabaseLocation()
+		if err != nil {
+			return "", err
+		}
+		if path != "" {
+			return path, nil
+		}
+	}
 	dataDir, err := resolveDataDir()
 	if err != nil {
 		return "", err
@@ -43,9 +71,131 @@ func openStore() (*db.Store, string, error) {
 	return store, path, nil
 }
 
-func newClientFromConfig(cfg *config.Config) *pushover.Client {
+func newClientFromConfig(cfg *config.Config) (*pushover.Client, error) {
 	if cfg == nil {
-		return pushover.NewClient("", "", "", "")
+		cfg = &config.Config{}
+	}
+	httpClient, err := pushover.NewHTTPClient(pushover.HTTPClientOptions{
+		ProxyURL:   cfg.ProxyURL,
+		CACertPath: cfg.CACertPath,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client := pushover.NewClient(cfg.AppToken, cfg.UserKey, cfg.DeviceID, cfg.DeviceSecret)
+	client.SetBaseURL(cfg.EffectiveAPIURL())
+	client.SetHTTPClient(httpClient)
+	client.SetLogger(debugLog.logger)
+	return client, nil
+}
+
+// mediaCacheDir is where downloaded icons are stored.
+func mediaCacheDir() (string, error) {
+	dataDir, err := resolveDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "cache"), nil
+}
+
+// newMediaCache returns the icon cache, or false when disable_media_cache is
+// set or the database is in memory.
+func newMediaCache(cfg *config.Config, store *db.Store) (*media.Cache, bool, error) {
+	if cfg.NoMediaCache || store.InMemory() {
+		return nil, false, nil
+	}
+	dir, err := mediaCacheDir()
+	if err != nil {
+		return nil, false, err
+	}
+	httpClient, err := pushover.NewHTTPClient(pushover.HTTPClientOptions{
+		ProxyURL:   cfg.ProxyURL,
+		CACertPath: cfg.CACertPath,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return media.New(dir, store, httpClient, cfg.EffectiveAPIURL()), true, nil
+}
+
+// parseSince accepts relative spans such as "30d", "12h", or "2w", "today" and
+// "yesterday" for the start of those days, and any date dateparse understands,
+// returning the resulting point in time.
+func parseSince(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+	if span, ok := parseSpan(value); ok {
+		return time.Now().Add(-span), nil
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(value) {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	return dateparse.ParseLocal(value)
+}
+
+// parseSpan parses Go durations extended with day ("d") and week ("w") units.
+func parseSpan(value string) (time.Duration, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+	unit := value[len(value)-1]
+	if unit == 'd' || unit == 'w' {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		day := 24 * time.Hour
+		if unit == 'w' {
+			return time.Duration(n) * 7 * day, true
+		}
+		return time.Duration(n) * day, true
+	}
+	span, err := time.ParseDuration(value)
+	if err != nil || span < 0 {
+		return 0, false
+	}
+	return span, true
+}
+
+// displayBody renders HTML message bodies for the terminal, keeping the markup
+// as-is when raw is set. color allows ANSI styling.
+func displayBody(body string, isHTML, raw, color bool) string {
+	if !isHTML || raw {
+		return body
+	}
+	return render.HTML(body, color)
+}
+
+// colorEnabled reports whether w is a terminal and colour wasn't disabled.
+func colorEnabled(w io.Writer) bool {
+	if opts.noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// listingTheme returns the configured colour theme for message listings
+// written to w, or the unstyled theme when colour is disabled.
+func listingTheme(cfg *config.Config, w io.Writer) (render.Theme, error) {
+	theme, err := render.ThemeByName(cfg.Theme)
+	if err != nil {
+		return render.Theme{}, err
+	}
+	if !colorEnabled(w) {
+		return render.Theme{}, nil
 	}
-	return pushover.NewClient(cfg.AppToken, cfg.UserKey, cfg.DeviceID, cfg.DeviceSecret)
+	return theme, nil
 }
@@ -3,92 +3,804 @@
 package cli
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/araddon/dateparse"
+	"github.com/harper/push/internal/config"
 	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/render"
 	"github.com/spf13/cobra"
 )
 
 func newHistoryCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "history",
-		Short: "Show persisted message history",
-		RunE:  runHistory,
+		Use:         "history",
+		Short:       "Show persisted message history",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		RunE:        runHistory,
 	}
 
 	cmd.Flags().IntP("limit", "n", 20, "limit number of rows")
 	cmd.Flags().String("since", "", "filter by natural language date (e.g. yesterday)")
+	cmd.Flags().String("until", "", "only messages received before this date")
 	cmd.Flags().String("search", "", "search text")
-	cmd.Flags().Bool("json", false, "output JSON")
+	cmd.Flags().String("regex", "", "only messages whose body or title matches this Go regular expression")
+	cmd.Flags().Int("context", 0, "with --search or --regex, also show this many messages received before and after each match")
+	cmd.Flags().String("app", "", "only messages from this application")
+	cmd.Flags().Int("min-priority", 0, "only messages at or above this priority (-2 to 2)")
+	cmd.Flags().String("device", "", "only messages received on this device")
+	cmd.Flags().Bool("has-url", false, "only messages with a supplementary URL")
+	cmd.Flags().Bool("archived", false, "include messages hidden with 'push archive'")
+	cmd.Flags().String("tag", "", "only messages tagged with --apply tag=NAME")
+	cmd.Flags().Int("offset", 0, "skip this many matching rows")
+	cmd.Flags().String("cursor", "", "continue after the page that printed this cursor")
+	cmd.Flags().String("group-by", "", "summarize instead of listing; only \"app\" is supported")
+	cmd.Flags().Bool("json", false, "output JSON (default from [history] default_format)")
+	cmd.Flags().Bool("show-icons", false, "show the cached icon file for each message")
+	cmd.Flags().Bool("qr", false, "render message URLs as terminal QR codes")
+	cmd.Flags().Bool("raw-html", false, "show HTML messages with their markup instead of rendering them")
+	cmd.Flags().Int64("raw", 0, "print the original API payload for this Pushover message ID")
+	cmd.Flags().Bool("sent", false, "show messages sent from this machine instead of received ones")
+	cmd.Flags().String("thread", "", "show the sent and received notifications in this thread together")
+	cmd.Flags().Bool("all", false, "show sent and received messages together, with each message you sent yourself shown once")
+	cmd.Flags().Bool("delete", false, "delete the matching messages (all of them unless --limit is given)")
+	cmd.Flags().StringArray("apply", nil, "change the matching messages: tag=NAME, untag=NAME, or archived=true|false (repeatable)")
+	cmd.Flags().Bool("dry-run", false, "with --delete or --apply, show what would change without changing it")
+	cmd.Flags().BoolP("yes", "y", false, "with --delete or --apply, don't ask for confirmation")
+	cmd.Flags().String("db", "", "read this database file instead of the configured one, e.g. a backup")
+	cmd.Flags().Bool("read-only", false, "open the database read-only, without migrating or locking it for writes")
+	addFormatFlag(cmd, "message")
+	cmd.MarkFlagsMutuallyExclusive("format", "json")
+	cmd.MarkFlagsMutuallyExclusive("format", "group-by")
+	cmd.MarkFlagsMutuallyExclusive("format", "raw")
+	cmd.MarkFlagsMutuallyExclusive("delete", "apply")
+	cmd.MarkFlagsMutuallyExclusive("all", "sent")
+	cmd.MarkFlagsMutuallyExclusive("all", "thread")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "yes")
+	cmd.MarkFlagsMutuallyExclusive("read-only", "delete")
+	cmd.MarkFlagsMutuallyExclusive("read-only", "apply")
+	_ = cmd.RegisterFlagCompletionFunc("device", completeReceivingDevices)
 
 	return cmd
 }
 
 func runHistory(cmd *cobra.Command, args []string) error {
-	limit, _ := cmd.Flags().GetInt("limit")
-	if limit <= 0 {
-		limit = 20
+	switch {
+	case cmd.Flags().Changed("raw"):
+		rawID, _ := cmd.Flags().GetInt64("raw")
+		return runHistoryRaw(cmd, rawID)
+	case cmd.Flags().Changed("thread"):
+		return runHistoryThread(cmd)
+	case flagSet(cmd, "sent"):
+		return runHistorySent(cmd)
+	case flagSet(cmd, "all"):
+		return runHistoryAll(cmd)
+	case cmd.Flags().Changed("delete") || cmd.Flags().Changed("apply"):
+		return runHistoryChange(cmd)
 	}
+	return runHistoryList(cmd)
+}
 
-	sinceStr, _ := cmd.Flags().GetString("since")
-	search, _ := cmd.Flags().GetString("search")
-	asJSON, _ := cmd.Flags().GetBool("json")
+// flagSet reports whether the boolean flag name is on.
+func flagSet(cmd *cobra.Command, name string) bool {
+	on, _ := cmd.Flags().GetBool(name)
+	return on
+}
 
-	var since *time.Time
-	if sinceStr != "" {
-		parsed, err := dateparse.ParseLocal(sinceStr)
-		if err != nil {
-			return fmt.Errorf("parse --since: %w", err)
+// runHistoryChange handles --delete and --apply.
+func runHistoryChange(cmd *cobra.Command) error {
+	filter, err := historyFilter(cmd)
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{"json", "format", "group-by", "context", "cursor", "offset"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be used with --delete or --apply", name)
+		}
+	}
+	store, err := openHistoryStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+	return runHistoryBulk(cmd, store, filter)
+}
+
+// historyOutput is how runHistoryList shows what it finds.
+type historyOutput struct {
+	cfg         *config.Config
+	tmpl        *template.Template
+	asJSON      bool
+	groupBy     string
+	contextRows int
+}
+
+// historyOutputFlags reads and checks the output flags for a listing of
+// filter's matches.
+func historyOutputFlags(cmd *cobra.Command, filter db.MessageFilter) (historyOutput, error) {
+	for _, name := range []string{"dry-run", "yes"} {
+		if cmd.Flags().Changed(name) {
+			return historyOutput{}, fmt.Errorf("--%s needs --delete or --apply", name)
 		}
-		since = &parsed
+	}
+	var out historyOutput
+	out.contextRows, _ = cmd.Flags().GetInt("context")
+	if out.contextRows < 0 {
+		return historyOutput{}, fmt.Errorf("--context cannot be negative")
+	}
+	if out.contextRows > 0 && filter.Search == "" && filter.Regex == "" {
+		return historyOutput{}, fmt.Errorf("--context needs --search or --regex")
+	}
+	var err error
+	if out.tmpl, err = formatTemplate(cmd); err != nil {
+		return historyOutput{}, err
+	}
+	if out.cfg, _, err = loadConfig(); err != nil {
+		return historyOutput{}, err
+	}
+	format, err := out.cfg.HistoryFormat()
+	if err != nil {
+		return historyOutput{}, err
+	}
+	out.asJSON = format == config.HistoryFormatJSON
+	if cmd.Flags().Changed("json") {
+		out.asJSON, _ = cmd.Flags().GetBool("json")
+	}
+	out.groupBy, _ = cmd.Flags().GetString("group-by")
+	if out.contextRows > 0 && (out.asJSON || out.tmpl != nil || out.groupBy != "") {
+		return historyOutput{}, fmt.Errorf("--context only applies to the history table, not to JSON, --format, or --group-by")
+	}
+	if out.groupBy != "" && out.groupBy != "app" {
+		return historyOutput{}, fmt.Errorf("--group-by supports only \"app\"")
+	}
+	return out, nil
+}
+
+// runHistoryList lists received messages as a table, JSON, a --format
+// template, or grouped by app.
+func runHistoryList(cmd *cobra.Command) error {
+	filter, err := historyFilter(cmd)
+	if err != nil {
+		return err
+	}
+	out, err := historyOutputFlags(cmd, filter)
+	if err != nil {
+		return err
 	}
 
-	store, _, err := openStore()
+	store, err := openHistoryStore(cmd)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = store.Close() }()
+	if out.groupBy != "" {
+		return runHistoryDigest(cmd, store, filter, out.asJSON)
+	}
 
-	records, err := store.QueryMessages(cmd.Context(), limit, since, search)
+	records, err := store.FindMessages(cmd.Context(), filter)
 	if err != nil {
 		return err
 	}
+	if len(records) == filter.Limit {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "next-cursor: %s\n", db.NextCursor(records))
+	}
 
-	if asJSON {
+	switch {
+	case out.tmpl != nil:
+		return writeFormatted(cmd.OutOrStdout(), out.tmpl, records)
+	case out.asJSON:
 		return writeHistoryJSON(cmd, records)
 	}
-	writeHistoryTable(cmd, records)
+	return showHistoryTable(cmd, out.cfg, store, filter, records, out.contextRows)
+}
+
+// showHistoryTable pages the history table, with contextRows messages around
+// each match when it's positive.
+func showHistoryTable(cmd *cobra.Command, cfg *config.Config, store *db.Store, filter db.MessageFilter, records []db.MessageRecord, contextRows int) error {
+	var display historyDisplay
+	var err error
+	if display.theme, err = listingTheme(cfg, cmd.OutOrStderr()); err != nil {
+		return err
+	}
+	display.color = colorEnabled(cmd.OutOrStderr())
+	if showIcons, _ := cmd.Flags().GetBool("show-icons"); showIcons {
+		display.icons = cachedIconPaths(cmd.Context(), store, records)
+	}
+	display.qr, _ = cmd.Flags().GetBool("qr")
+	display.rawHTML, _ = cmd.Flags().GetBool("raw-html")
+	if display.match, err = historyMatch(filter); err != nil {
+		return err
+	}
+	if contextRows > 0 {
+		groups, err := historyContext(cmd.Context(), store, records, contextRows)
+		if err != nil {
+			return err
+		}
+		return withPager(cmd, func() error {
+			writeHistoryContext(cmd, groups, display)
+			return nil
+		})
+	}
+	return withPager(cmd, func() error {
+		writeHistoryTable(cmd, records, display)
+		return nil
+	})
+}
+
+// historyMatch is the pattern that --search or --regex matched, for
+// highlighting: --search as case-insensitive literal text, the way SQLite's
+// LIKE compares it. It is nil when neither was given.
+func historyMatch(filter db.MessageFilter) (*regexp.Regexp, error) {
+	switch {
+	case filter.Regex != "":
+		re, err := regexp.Compile(filter.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return re, nil
+	case filter.Search != "":
+		return regexp.Compile("(?i)" + regexp.QuoteMeta(filter.Search))
+	}
+	return nil, nil
+}
+
+// historyRow is a message in a --context listing, either a match or one of
+// the messages received around it.
+type historyRow struct {
+	rec     db.MessageRecord
+	isMatch bool
+}
+
+// historyContext surrounds each match with the n messages received before and
+// after it, newest first like the rest of history. Windows that overlap are
+// merged into one group.
+func historyContext(ctx context.Context, store *db.Store, matches []db.MessageRecord, n int) ([][]historyRow, error) {
+	matched := make(map[int64]bool, len(matches))
+	for _, rec := range matches {
+		matched[rec.ID] = true
+	}
+	var groups [][]historyRow
+	shown := map[int64]int{}
+	for _, rec := range matches {
+		before, after, err := store.MessagesAround(ctx, rec, n)
+		if err != nil {
+			return nil, err
+		}
+		window := make([]db.MessageRecord, 0, len(before)+len(after)+1)
+		for i := len(after) - 1; i >= 0; i-- {
+			window = append(window, after[i])
+		}
+		window = append(window, rec)
+		window = append(window, before...)
+
+		// Matches arrive newest first, so a window can only overlap the group
+		// before it.
+		group := len(groups)
+		for _, w := range window {
+			if g, ok := shown[w.ID]; ok {
+				group = g
+				break
+			}
+		}
+		if group == len(groups) {
+			groups = append(groups, nil)
+		}
+		for _, w := range window {
+			if _, ok := shown[w.ID]; ok {
+				continue
+			}
+			shown[w.ID] = group
+			groups[group] = append(groups[group], historyRow{rec: w, isMatch: matched[w.ID]})
+		}
+	}
+	return groups, nil
+}
+
+func runHistoryDigest(cmd *cobra.Command, store *db.Store, filter db.MessageFilter, asJSON bool) error {
+	digests, err := store.DigestByApp(cmd.Context(), filter)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(digests)
+	}
+	if len(digests) == 0 {
+		cmd.Println("No history found.")
+		return nil
+	}
+
+	for _, digest := range digests {
+		app := digest.App
+		if app == "" {
+			app = "(unknown app)"
+		}
+		latest := digest.Latest
+		cmd.Printf("%s: %d message(s)\n", app, digest.Count)
+		cmd.Printf("  Latest %s [%d] %s\n", latest.ReceivedAt.Local().Format(time.RFC3339), latest.PushoverID, digestLine(latest))
+	}
 	return nil
 }
 
+// digestLine condenses a message to a single line for summaries.
+func digestLine(rec db.MessageRecord) string {
+	body := rec.Message
+	if rec.HTML {
+		body = render.HTML(body, false)
+	}
+	line := strings.Join(strings.Fields(body), " ")
+	if rec.Title != "" {
+		line = rec.Title + ": " + line
+	}
+	if runes := []rune(line); len(runes) > 80 {
+		line = string(runes[:79]) + "…"
+	}
+	return line
+}
+
+// openHistoryStore opens the database history reads: the one --db names, or
+// the configured one, read-only with --read-only.
+func openHistoryStore(cmd *cobra.Command) (*db.Store, error) {
+	path, _ := cmd.Flags().GetString("db")
+	readOnly, _ := cmd.Flags().GetBool("read-only")
+	if path != "" {
+		// Don't leave an empty database behind for a mistyped path.
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("no database at --db %s", path)
+		}
+	}
+	if path == "" {
+		if !readOnly {
+			store, _, err := openStore()
+			return store, err
+		}
+		var err error
+		if path, err = databasePath(); err != nil {
+			return nil, err
+		}
+	}
+	if readOnly {
+		return db.OpenReadOnly(path)
+	}
+	store, err := db.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	return store, nil
+}
+
+// historyFilter builds the query filter from the history flags.
+func historyFilter(cmd *cobra.Command) (db.MessageFilter, error) {
+	filter := db.MessageFilter{}
+	filter.Limit, _ = cmd.Flags().GetInt("limit")
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	}
+	filter.Search, _ = cmd.Flags().GetString("search")
+	filter.Regex, _ = cmd.Flags().GetString("regex")
+	filter.App, _ = cmd.Flags().GetString("app")
+	filter.Device, _ = cmd.Flags().GetString("device")
+	filter.HasURL, _ = cmd.Flags().GetBool("has-url")
+	filter.Tag, _ = cmd.Flags().GetString("tag")
+	filter.Tag = strings.ToLower(strings.TrimSpace(filter.Tag))
+	if archived, _ := cmd.Flags().GetBool("archived"); !archived {
+		filter.HideArchived = true
+	}
+	filter.Cursor, _ = cmd.Flags().GetString("cursor")
+	filter.Offset, _ = cmd.Flags().GetInt("offset")
+	if filter.Offset < 0 {
+		return db.MessageFilter{}, fmt.Errorf("--offset cannot be negative")
+	}
+
+	for _, bound := range []struct {
+		flag string
+		dest **time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		value, _ := cmd.Flags().GetString(bound.flag)
+		if value == "" {
+			continue
+		}
+		parsed, err := dateparse.ParseLocal(value)
+		if err != nil {
+			return db.MessageFilter{}, fmt.Errorf("parse --%s: %w", bound.flag, err)
+		}
+		*bound.dest = &parsed
+	}
+
+	if cmd.Flags().Changed("min-priority") {
+		priority, _ := cmd.Flags().GetInt("min-priority")
+		if priority < -2 || priority > 2 {
+			return db.MessageFilter{}, fmt.Errorf("--min-priority must be between -2 and 2")
+		}
+		filter.MinPriority = &priority
+	}
+	return filter, nil
+}
+
+// receivedOnlyFlags are history flags that filter or render received
+// messages and have no meaning with --sent.
+var receivedOnlyFlags = append([]string{"search", "regex", "context", "app", "min-priority", "device", "has-url", "archived", "tag", "offset", "cursor", "group-by", "show-icons", "qr", "raw-html", "raw"}, bulkFlags...)
+
+// runHistorySent lists the sent log, including failed sends and the state
+// of emergency receipts.
+func runHistorySent(cmd *cobra.Command) error {
+	for _, name := range receivedOnlyFlags {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be used with --sent", name)
+		}
+	}
+	received, err := historyFilter(cmd)
+	if err != nil {
+		return err
+	}
+	filter := db.SentFilter{Limit: received.Limit, Since: received.Since, Until: received.Until}
+	tmpl, err := formatTemplate(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	format, err := cfg.HistoryFormat()
+	if err != nil {
+		return err
+	}
+	asJSON := format == config.HistoryFormatJSON
+	if cmd.Flags().Changed("json") {
+		asJSON, _ = cmd.Flags().GetBool("json")
+	}
+
+	store, err := openHistoryStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	records, err := store.ListSent(cmd.Context(), filter)
+	if err != nil {
+		return err
+	}
+	if tmpl != nil {
+		return writeFormatted(cmd.OutOrStdout(), tmpl, records)
+	}
+	if asJSON {
+		return writeSentJSON(cmd, records)
+	}
+	theme, err := listingTheme(cfg, cmd.OutOrStderr())
+	if err != nil {
+		return err
+	}
+	return withPager(cmd, func() error {
+		writeSentTable(cmd, records, theme)
+		return nil
+	})
+}
+
+// runHistoryAll lists sent and received messages together, newest first. A
+// message sent to your own user and received back is one entry, marked
+// delivered.
+func runHistoryAll(cmd *cobra.Command) error {
+	for _, name := range append([]string{"format"}, receivedOnlyFlags...) {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be used with --all", name)
+		}
+	}
+	received, err := historyFilter(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	format, err := cfg.HistoryFormat()
+	if err != nil {
+		return err
+	}
+	asJSON := format == config.HistoryFormatJSON
+	if cmd.Flags().Changed("json") {
+		asJSON, _ = cmd.Flags().GetBool("json")
+	}
+
+	store, err := openHistoryStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	entries, err := store.Timeline(cmd.Context(), received.Since, received.Until, received.Limit)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(append([]db.TimelineEntry{}, entries...))
+	}
+	theme, err := listingTheme(cfg, cmd.OutOrStderr())
+	if err != nil {
+		return err
+	}
+	return withPager(cmd, func() error {
+		writeTimelineTable(cmd, entries, theme)
+		return nil
+	})
+}
+
+func writeTimelineTable(cmd *cobra.Command, entries []db.TimelineEntry, theme render.Theme) {
+	if len(entries) == 0 {
+		cmd.Println("No messages found.")
+		return
+	}
+	for _, e := range entries {
+		timestamp := e.At.Local().Format(time.RFC3339)
+		ref := fmt.Sprintf("sent #%d", e.ID)
+		if e.Kind == db.ThreadReceived {
+			ref = fmt.Sprintf("received [%d]", e.ID)
+		}
+		line := e.Message
+		if e.Title != "" {
+			line = e.Title + ": " + line
+		}
+		line = digestLine(db.MessageRecord{Message: line})
+		cmd.Printf("%s %s %s\n", theme.Dimmed(timestamp), theme.Dimmed(ref), theme.ForPriority(e.Priority, line))
+		if e.App != "" {
+			cmd.Printf("  %s %s\n", theme.Dimmed("App:"), e.App)
+		}
+		if !e.DeliveredAt.IsZero() {
+			delivered := fmt.Sprintf("✓ Delivered %s as [%d]", e.DeliveredAt.Local().Format(time.DateTime), e.DeliveredID)
+			if e.DeliveredTo != "" {
+				delivered += " on " + e.DeliveredTo
+			}
+			cmd.Printf("  %s\n", theme.Dimmed(delivered))
+		}
+	}
+}
+
+// runHistoryThread lists a thread's sent and received notifications in the
+// order they happened.
+func runHistoryThread(cmd *cobra.Command) error {
+	for _, name := range append([]string{"sent", "since", "until", "format"}, receivedOnlyFlags...) {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be used with --thread", name)
+		}
+	}
+	key, _ := cmd.Flags().GetString("thread")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return fmt.Errorf("--thread needs a thread key")
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	format, err := cfg.HistoryFormat()
+	if err != nil {
+		return err
+	}
+	asJSON := format == config.HistoryFormatJSON
+	if cmd.Flags().Changed("json") {
+		asJSON, _ = cmd.Flags().GetBool("json")
+	}
+
+	store, err := openHistoryStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	entries, err := store.Thread(cmd.Context(), key, limit)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	theme, err := listingTheme(cfg, cmd.OutOrStderr())
+	if err != nil {
+		return err
+	}
+	return withPager(cmd, func() error {
+		writeThreadTable(cmd, key, entries, theme)
+		return nil
+	})
+}
+
+func writeThreadTable(cmd *cobra.Command, key string, entries []db.ThreadEntry, theme render.Theme) {
+	if len(entries) == 0 {
+		cmd.Printf("No notifications in thread %q.\n", key)
+		return
+	}
+	for _, e := range entries {
+		timestamp := e.At.Local().Format(time.RFC3339)
+		ref := fmt.Sprintf("sent #%d", e.ID)
+		if e.Kind == db.ThreadReceived {
+			ref = fmt.Sprintf("received [%d]", e.ID)
+		}
+		line := e.Message
+		if e.Title != "" {
+			line = e.Title + ": " + line
+		}
+		line = digestLine(db.MessageRecord{Message: line})
+		cmd.Printf("%s %s %s\n", theme.Dimmed(timestamp), theme.Dimmed(ref), theme.ForPriority(e.Priority, line))
+		if e.App != "" {
+			cmd.Printf("  %s %s\n", theme.Dimmed("App:"), e.App)
+		}
+	}
+}
+
+func writeSentTable(cmd *cobra.Command, records []db.SentRecord, theme render.Theme) {
+	if len(records) == 0 {
+		cmd.Println("No sent messages found.")
+		return
+	}
+	for _, rec := range records {
+		timestamp := rec.SentAt.Local().Format(time.RFC3339)
+		cmd.Printf("%s %s %s\n", theme.Dimmed(timestamp), theme.Dimmed(fmt.Sprintf("#%d", rec.ID)), theme.ForPriority(rec.Priority, sentLine(rec)))
+		switch {
+		case rec.Error != "":
+			cmd.Printf("  %s %s\n", theme.Dimmed("Failed:"), rec.Error)
+		case rec.Suppressed:
+			cmd.Printf("  %s\n", theme.Dimmed("Suppressed as a duplicate"))
+		}
+		if rec.Device != "" {
+			cmd.Printf("  %s %s\n", theme.Dimmed("Device:"), rec.Device)
+		}
+		if rec.Priority != 0 {
+			cmd.Printf("  %s %s\n", theme.Dimmed("Priority:"), theme.ForPriority(rec.Priority, strconv.Itoa(rec.Priority)))
+		}
+		if rec.Receipt != "" {
+			cmd.Printf("  %s %s (%s)\n", theme.Dimmed("Receipt:"), rec.Receipt, receiptState(rec))
+		}
+	}
+}
+
+// cachedIconPaths maps icon hashes in records to their cached file paths.
+func cachedIconPaths(ctx context.Context, store *db.Store, records []db.MessageRecord) map[string]string {
+	paths := make(map[string]string)
+	for _, rec := range records {
+		if rec.IconHash == "" {
+			continue
+		}
+		if _, seen := paths[rec.IconHash]; seen {
+			continue
+		}
+		if media, found, err := store.MediaByHash(ctx, rec.IconHash); err == nil && found {
+			paths[rec.IconHash] = media.Path
+		}
+	}
+	return paths
+}
+
+func runHistoryRaw(cmd *cobra.Command, pushoverID int64) error {
+	store, err := openHistoryStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	rec, found, err := store.GetMessage(cmd.Context(), pushoverID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("message %d not found in history", pushoverID)
+	}
+	if rec.RawJSON == "" {
+		return fmt.Errorf("no raw payload stored for message %d (received before raw capture was added)", pushoverID)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(rec.RawJSON), "", "  "); err != nil {
+		return fmt.Errorf("format raw payload: %w", err)
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), out.String())
+	return err
+}
+
 func writeHistoryJSON(cmd *cobra.Command, records []db.MessageRecord) error {
 	enc := json.NewEncoder(cmd.OutOrStdout())
 	enc.SetIndent("", "  ")
 	return enc.Encode(records)
 }
 
-func writeHistoryTable(cmd *cobra.Command, records []db.MessageRecord) {
+// historyDisplay holds the presentation options for the history table.
+type historyDisplay struct {
+	theme   render.Theme
+	icons   map[string]string
+	qr      bool
+	rawHTML bool
+	color   bool
+	// match highlights what --search or --regex matched.
+	match *regexp.Regexp
+}
+
+func writeHistoryTable(cmd *cobra.Command, records []db.MessageRecord, display historyDisplay) {
 	if len(records) == 0 {
 		cmd.Println("No history found.")
 		return
 	}
 	for _, rec := range records {
-		timestamp := rec.ReceivedAt.Local().Format(time.RFC3339)
-		cmd.Printf("%s [%d] %s\n", timestamp, rec.PushoverID, rec.Message)
-		if rec.Title != "" {
-			cmd.Printf("  Title: %s\n", rec.Title)
-		}
-		if rec.URL != "" {
-			cmd.Printf("  URL: %s\n", rec.URL)
+		writeHistoryRecord(cmd, rec, display)
+	}
+}
+
+// writeHistoryContext prints --context groups, separated by "--". The
+// messages around each match get one dimmed line each.
+func writeHistoryContext(cmd *cobra.Command, groups [][]historyRow, display historyDisplay) {
+	if len(groups) == 0 {
+		cmd.Println("No history found.")
+		return
+	}
+	theme := display.theme
+	for i, group := range groups {
+		if i > 0 {
+			cmd.Println(theme.Dimmed("--"))
 		}
-		if rec.Priority != 0 {
-			cmd.Printf("  Priority: %d\n", rec.Priority)
+		for _, row := range group {
+			if row.isMatch {
+				writeHistoryRecord(cmd, row.rec, display)
+				continue
+			}
+			timestamp := row.rec.ReceivedAt.Local().Format(time.RFC3339)
+			cmd.Println(theme.Dimmed(fmt.Sprintf("%s [%d] %s", timestamp, row.rec.PushoverID, digestLine(row.rec))))
 		}
-		if rec.App != "" {
-			cmd.Printf("  App: %s\n", rec.App)
+	}
+}
+
+// writeHistoryRecord prints one received message with its details.
+func writeHistoryRecord(cmd *cobra.Command, rec db.MessageRecord, display historyDisplay) {
+	theme := display.theme
+	timestamp := rec.ReceivedAt.Local().Format(time.RFC3339)
+	body := displayBody(rec.Message, rec.HTML, display.rawHTML, display.color)
+	match := display.match
+	if rec.HTML && !display.rawHTML {
+		// The rendered body no longer lines up with the stored markup the
+		// pattern matched, and may carry its own escape sequences.
+		match = nil
+	}
+	cmd.Printf("%s [%d] %s\n", theme.Dimmed(timestamp), rec.PushoverID, theme.Highlighted(rec.Priority, body, match))
+	if rec.Title != "" {
+		cmd.Printf("  %s %s\n", theme.Dimmed("Title:"), theme.Highlighted(0, rec.Title, display.match))
+	}
+	if rec.URL != "" {
+		cmd.Printf("  %s %s\n", theme.Dimmed("URL:"), rec.URL)
+		if display.qr {
+			if err := writeQR(cmd.OutOrStderr(), rec.URL, "  "); err != nil {
+				logger.Warn("unable to render QR code", "error", err)
+			}
 		}
 	}
+	if rec.Priority != 0 {
+		cmd.Printf("  %s %s\n", theme.Dimmed("Priority:"), theme.ForPriority(rec.Priority, strconv.Itoa(rec.Priority)))
+	}
+	if rec.App != "" {
+		cmd.Printf("  %s %s\n", theme.Dimmed("App:"), rec.App)
+	}
+	if len(rec.Tags) > 0 {
+		cmd.Printf("  %s %s\n", theme.Dimmed("Tags:"), strings.Join(rec.Tags, ", "))
+	}
+	if rec.Archived {
+		cmd.Printf("  %s yes\n", theme.Dimmed("Archived:"))
+	}
+	if path := display.icons[rec.IconHash]; path != "" {
+		cmd.Printf("  %s %s\n", theme.Dimmed("Icon:"), path)
+	}
 }
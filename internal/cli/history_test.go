@@ -0,0 +1,41 @@
+// ABOUTME: Tests for where push history reads from.
+// ABOUTME: Checks --db with --read-only opens the named file without write access.
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+func TestOpenHistoryStoreReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "copy.db")
+	store, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, _ := bulkHistoryCmd(t, "--db", path, "--read-only")
+	ro, err := openHistoryStore(cmd)
+	if err != nil {
+		t.Fatalf("openHistoryStore: %v", err)
+	}
+	defer func() { _ = ro.Close() }()
+	if !ro.ReadOnly() {
+		t.Error("--read-only opened a writable store")
+	}
+	if _, err := ro.PersistMessages(context.Background(), []db.MessageRecord{{PushoverID: 1, Message: "x", ReceivedAt: time.Now()}}); err == nil {
+		t.Error("write through --read-only succeeded, want an error")
+	}
+
+	cmd, _ = bulkHistoryCmd(t, "--db", filepath.Join(t.TempDir(), "missing.db"), "--read-only")
+	if _, err := openHistoryStore(cmd); err == nil {
+		t.Error("openHistoryStore() of a missing --db succeeded, want an error")
+	}
+}
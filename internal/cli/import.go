@@ -0,0 +1,88 @@
+// ABOUTME: Import command that adds messages from Pushover JSON or CSV exports to history.
+// ABOUTME: Skips messages already stored, so the same export can be imported again safely.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/harper/push/internal/messages"
+	"github.com/spf13/cobra"
+)
+
+func newImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "import <file>",
+		Short:       "Add messages from a Pushover JSON or CSV export to history",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Add received messages from an export to the history database, dated when they were sent, so history from before push is kept in one place. --format pushover-json reads the Open Client API's messages.json response or an array of its message objects; --format csv reads a header row naming date and message columns, and optionally id, app, title, priority, url, acked, and html. Messages already stored, by ID or by the same date, app, title, and body, are skipped. Use - to read from stdin.",
+		Args:        cobra.ExactArgs(1),
+		RunE:        runImport,
+	}
+	cmd.Flags().String("format", "", "export format: pushover-json or csv (default: from the .json or .csv extension)")
+	cmd.Flags().Bool("dry-run", false, "parse the export and report what it holds without importing")
+	return cmd
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	format, _ := cmd.Flags().GetString("format")
+	if format == "" {
+		switch lower := strings.ToLower(path); {
+		case strings.HasSuffix(lower, ".json"):
+			format = messages.ImportPushoverJSON
+		case strings.HasSuffix(lower, ".csv"):
+			format = messages.ImportCSV
+		default:
+			return fmt.Errorf("can't tell the format of %s; pass --format %s or --format %s", path, messages.ImportPushoverJSON, messages.ImportCSV)
+		}
+	}
+
+	var r io.Reader = cmd.InOrStdin()
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("open export: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		r = file
+	}
+	records, err := messages.ParseImport(r, format)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	oldest, newest := *records[0].SentAt, *records[0].SentAt
+	for _, rec := range records[1:] {
+		if rec.SentAt.Before(oldest) {
+			oldest = *rec.SentAt
+		}
+		if rec.SentAt.After(newest) {
+			newest = *rec.SentAt
+		}
+	}
+	span := fmt.Sprintf("%s to %s", oldest.Local().Format("2006-01-02"), newest.Local().Format("2006-01-02"))
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		cmd.Printf("%s holds %d messages from %s. Nothing imported (--dry-run).\n", path, len(records), span)
+		return nil
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	imported, err := store.ImportMessages(cmd.Context(), records)
+	if err != nil {
+		return err
+	}
+	cmd.Printf("✓ Imported %d of %d messages from %s", imported, len(records), span)
+	if skipped := len(records) - imported; skipped > 0 {
+		cmd.Printf("; %d already in history", skipped)
+	}
+	cmd.Println(".")
+	return nil
+}
@@ -0,0 +1,56 @@
+// ABOUTME: Structured logging for CLI commands, the daemon, and the MCP server.
+// ABOUTME: Applies --log-level, --log-format, and --log-file to the slog default.
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/harper/push/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var logFile *os.File
+
+// logger is where commands report warnings and progress. Until setupLogging
+// runs (e.g. during shell completion) it writes text to stderr.
+var logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+// setupLogging builds the logger from the --log-* flags and installs it as the
+// slog default so the daemon and MCP server share it. --debug lowers the
+// level to debug unless --log-level was given.
+func setupLogging(cmd *cobra.Command) error {
+	level, err := logging.ParseLevel(opts.logLevel)
+	if err != nil {
+		return err
+	}
+	if opts.debug && !cmd.Flags().Changed("log-level") {
+		level = slog.LevelDebug
+	}
+
+	out := cmd.ErrOrStderr()
+	if opts.logFile != "" {
+		file, err := os.OpenFile(opts.logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("open log file: %w", err)
+		}
+		logFile = file
+		out = file
+	}
+
+	configured, err := logging.New(out, level, opts.logFormat)
+	if err != nil {
+		return err
+	}
+	logger = configured
+	slog.SetDefault(configured)
+	return nil
+}
+
+func closeLogging() {
+	if logFile != nil {
+		_ = logFile.Close()
+	}
+	logFile = nil
+}
@@ -6,10 +6,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/harper/push/internal/config"
-	"github.com/harper/push/internal/pushover"
+	"github.com/harper/push/pkg/pushover"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func newLoginCmd() *cobra.Command {
@@ -21,12 +24,30 @@ func newLoginCmd() *cobra.Command {
 		},
 	}
 	cmd.Flags().String("device-name", "push-cli", "device name to register")
+	cmd.Flags().String("app-token", "", "Pushover app token (skips the prompt)")
+	cmd.Flags().String("user-key", "", "Pushover user key (skips the prompt)")
+	cmd.Flags().String("email", "", "Pushover account email (skips the prompt)")
+	cmd.Flags().Bool("password-stdin", false, "read the account password from stdin")
+	cmd.Flags().String("two-factor", "", "two-factor authentication code (also accepted as --code)")
+	cmd.Flags().Bool("non-interactive", false, "never prompt; fail if a required value is missing")
+	cmd.Flags().Bool("refresh", false, "re-authenticate and rotate the device secret, keeping the registered device")
+	cmd.Flags().String("secret-file", "", "key=value file with app_token, user_key, email, password, and/or two_factor")
+	cmd.Flags().Bool("add", false, "register an additional receiving device under [devices], keeping the current one")
+	cmd.Flags().Bool("continue", false, "finish a --non-interactive login that stopped for a two-factor code (pass --code)")
+	cmd.MarkFlagsMutuallyExclusive("add", "refresh")
+	cmd.MarkFlagsMutuallyExclusive("continue", "add", "refresh")
+	// --code reads better than --two-factor when resuming with --continue.
+	cmd.Flags().SetNormalizeFunc(func(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "code" {
+			name = "two-factor"
+		}
+		return pflag.NormalizedName(name)
+	})
 
 	return cmd
 }
 
 func runLogin(cmd *cobra.Command) error {
-	ctx := cmd.Context()
 	prom := newPrompter(cmd.OutOrStdout())
 
 	cfg, cfgPath, err := loadConfig()
@@ -39,45 +60,131 @@ func runLogin(cmd *cobra.Command) error {
 	}
 
 	deviceName, _ := cmd.Flags().GetString("device-name")
+	addDevice, _ := cmd.Flags().GetBool("add")
+	if addDevice && cfg.DeviceConfigured() && deviceName == cfg.ReceivingDevice() {
+		return fmt.Errorf("device %q is already the login device; pick another --device-name", deviceName)
+	}
+	nonInteractive, _ := cmd.Flags().GetBool("non-interactive")
+	src := loginSource{prom: prom, nonInteractive: nonInteractive}
+	if path := flagString(cmd, "secret-file"); path != "" {
+		if src.secrets, err = readSecretFile(path); err != nil {
+			return err
+		}
+	}
+	src.twoFactor = flagString(cmd, "two-factor")
+	if src.twoFactor == "" {
+		src.twoFactor = src.secrets["two_factor"]
+	}
+
+	if resume, _ := cmd.Flags().GetBool("continue"); resume {
+		return runLoginContinue(cmd, src, cfg, cfgPath)
+	}
+	discardPendingLogin()
+	if refresh, _ := cmd.Flags().GetBool("refresh"); refresh {
+		return runLoginRefresh(cmd, src, cfg, cfgPath)
+	}
 
-	appToken, err := prom.Ask("Pushover app token", cfg.AppToken)
+	appToken, err := src.text("Pushover app token", "app_token", flagString(cmd, "app-token"), cfg.AppToken)
 	if err != nil {
 		return fmt.Errorf("reading app token: %w", err)
 	}
-	userKey, err := prom.Ask("Pushover user key", cfg.UserKey)
+	userKey, err := src.text("Pushover user key", "user_key", flagString(cmd, "user-key"), cfg.UserKey)
 	if err != nil {
 		return fmt.Errorf("reading user key: %w", err)
 	}
-	email, err := prom.Ask("Email", "")
+	email, err := src.text("Email", "email", flagString(cmd, "email"), cfg.Email)
 	if err != nil {
 		return fmt.Errorf("reading email: %w", err)
 	}
-	password, err := prom.AskSecret("Password")
+	passwordStdin, _ := cmd.Flags().GetBool("password-stdin")
+	password, err := src.password(cmd.InOrStdin(), passwordStdin)
 	if err != nil {
 		return fmt.Errorf("reading password: %w", err)
 	}
 
-	client := pushover.NewClient(appToken, userKey, "", "")
-	loginResp, err := performLogin(ctx, prom, client, email, password)
+	mode := loginModeNew
+	if addDevice {
+		mode = loginModeAdd
+	}
+	return completeLogin(cmd, src, cfg, cfgPath, loginRequest{
+		Mode:       mode,
+		AppToken:   appToken,
+		UserKey:    userKey,
+		Email:      email,
+		Password:   password,
+		DeviceName: deviceName,
+	})
+}
+
+// Login modes, recorded in a pending login so --continue finishes the same way.
+const (
+	loginModeNew     = "login"
+	loginModeAdd     = "add"
+	loginModeRefresh = "refresh"
+)
+
+// loginRequest is everything needed to authenticate and finish a login.
+type loginRequest struct {
+	Mode       string
+	AppToken   string
+	UserKey    string
+	Email      string
+	Password   string
+	DeviceName string
+}
+
+// completeLogin authenticates req and stores the result. Non-interactive
+// logins that need a two-factor code are saved for 'push login --continue'.
+func completeLogin(cmd *cobra.Command, src loginSource, cfg *config.Config, cfgPath string, req loginRequest) error {
+	ctx := cmd.Context()
+
+	loginCfg := cfg.Clone()
+	loginCfg.AppToken = req.AppToken
+	loginCfg.UserKey = req.UserKey
+	if req.Mode != loginModeRefresh {
+		loginCfg.DeviceID = ""
+		loginCfg.DeviceSecret = ""
+	}
+	client, err := newClientFromConfig(loginCfg)
 	if err != nil {
 		return err
 	}
-
-	deviceResp, err := client.RegisterDevice(ctx, loginResp.Secret, deviceName)
+	loginResp, err := performLogin(ctx, src, client, req.Email, req.Password)
+	if errors.Is(err, errTwoFactorPending) {
+		return deferLogin(cmd, req)
+	}
 	if err != nil {
 		return err
 	}
 
-	cfg.AppToken = appToken
-	cfg.UserKey = userKey
+	cfg.AppToken = req.AppToken
+	cfg.UserKey = req.UserKey
+	cfg.Email = req.Email
+	if req.Mode == loginModeRefresh {
+		cfg.DeviceSecret = loginResp.Secret
+		if err := config.Save(cfgPath, cfg); err != nil {
+			return err
+		}
+		cmd.Printf("✓ Credentials refreshed for device %q.\n", cfg.DeviceID)
+		return nil
+	}
+
+	deviceResp, err := client.RegisterDevice(ctx, loginResp.Secret, req.DeviceName)
+	if err != nil {
+		return err
+	}
+	if req.Mode == loginModeAdd {
+		return saveAddedDevice(cmd, cfg, cfgPath, req.DeviceName, loginResp.Secret, deviceResp)
+	}
 	cfg.DeviceSecret = loginResp.Secret
+	cfg.DeviceName = req.DeviceName
 	if deviceResp.ID != "" {
 		cfg.DeviceID = deviceResp.ID
 	} else if deviceResp.Name != "" {
 		cfg.DeviceID = deviceResp.Name
 	}
-	if cfg.DefaultDevice == "" && deviceName != "" {
-		cfg.DefaultDevice = deviceName
+	if cfg.DefaultDevice == "" && req.DeviceName != "" {
+		cfg.DefaultDevice = req.DeviceName
 	}
 
 	if err := config.Save(cfgPath, cfg); err != nil {
@@ -88,14 +195,69 @@ func runLogin(cmd *cobra.Command) error {
 	return nil
 }
 
-func performLogin(ctx context.Context, prom *prompter, client *pushover.Client, email, password string) (*pushover.LoginResponse, error) {
-	loginResp, err := client.Login(ctx, email, password, "")
+// saveAddedDevice stores a newly registered device under [devices] so
+// messages polls it alongside the login device.
+func saveAddedDevice(cmd *cobra.Command, cfg *config.Config, cfgPath, name, secret string, resp *pushover.DeviceRegistration) error {
+	id := resp.ID
+	if id == "" {
+		id = resp.Name
+	}
+	devices := make(map[string]config.DeviceConfig, len(cfg.Devices)+1)
+	for existing, device := range cfg.Devices {
+		devices[existing] = device
+	}
+	devices[name] = config.DeviceConfig{DeviceID: id, DeviceSecret: secret}
+	cfg.Devices = devices
+
+	if err := config.Save(cfgPath, cfg); err != nil {
+		return err
+	}
+	cmd.Printf("✓ Logged in. Additional device %q registered.\n", name)
+	return nil
+}
+
+// runLoginRefresh re-authenticates with the stored credentials and swaps in a
+// fresh device secret without registering a new device, so the device ID and
+// local history stay attached.
+func runLoginRefresh(cmd *cobra.Command, src loginSource, cfg *config.Config, cfgPath string) error {
+	if !cfg.DeviceConfigured() {
+		return fmt.Errorf("no registered device to refresh: run 'push login' first")
+	}
+
+	email, err := src.text("Email", "email", flagString(cmd, "email"), cfg.Email)
+	if err != nil {
+		return fmt.Errorf("reading email: %w", err)
+	}
+	passwordStdin, _ := cmd.Flags().GetBool("password-stdin")
+	password, err := src.password(cmd.InOrStdin(), passwordStdin)
+	if err != nil {
+		return fmt.Errorf("reading password: %w", err)
+	}
+
+	return completeLogin(cmd, src, cfg, cfgPath, loginRequest{
+		Mode:     loginModeRefresh,
+		AppToken: cfg.AppToken,
+		UserKey:  cfg.UserKey,
+		Email:    email,
+		Password: password,
+	})
+}
+
+// errTwoFactorPending reports a non-interactive login that stopped for a
+// two-factor code.
+var errTwoFactorPending = errors.New("two-factor code required")
+
+func performLogin(ctx context.Context, src loginSource, client *pushover.Client, email, password string) (*pushover.LoginResponse, error) {
+	loginResp, err := client.Login(ctx, email, password, src.twoFactor)
 	if err == nil {
 		return loginResp, nil
 	}
 
-	if errors.Is(err, pushover.ErrTwoFactorRequired) {
-		code, promptErr := prom.Ask("2FA code", "")
+	if errors.Is(err, pushover.ErrTwoFactorRequired) && src.twoFactor == "" {
+		if src.nonInteractive {
+			return nil, errTwoFactorPending
+		}
+		code, promptErr := src.prom.Ask("2FA code", "")
 		if promptErr != nil {
 			return nil, promptErr
 		}
@@ -104,3 +266,57 @@ func performLogin(ctx context.Context, prom *prompter, client *pushover.Client,
 
 	return nil, err
 }
+
+// loginSource resolves login inputs from flags first, then the secret file,
+// then prompts unless running non-interactively.
+type loginSource struct {
+	prom           *prompter
+	nonInteractive bool
+	secrets        map[string]string
+	twoFactor      string
+}
+
+func (s loginSource) text(label, key, flagValue, fallback string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	if value := s.secrets[key]; value != "" {
+		return value, nil
+	}
+	if s.nonInteractive {
+		if fallback != "" {
+			return fallback, nil
+		}
+		return "", fmt.Errorf("%s is required with --non-interactive", strings.ToLower(label))
+	}
+	return s.prom.Ask(label, fallback)
+}
+
+func (s loginSource) password(stdin io.Reader, fromStdin bool) (string, error) {
+	if fromStdin {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", err
+		}
+		password := strings.TrimRight(string(data), "\r\n")
+		if password == "" {
+			return "", errors.New("no password on stdin")
+		}
+		return password, nil
+	}
+	if value := s.secrets["password"]; value != "" {
+		return value, nil
+	}
+	if password, ok, err := readPasswordFile(); err != nil || ok {
+		return password, err
+	}
+	if s.nonInteractive {
+		return "", errors.New("password is required with --non-interactive: use --password-stdin, --secret-file, or " + passwordFileEnv)
+	}
+	return s.prom.AskSecret("Password")
+}
+
+func flagString(cmd *cobra.Command, name string) string {
+	value, _ := cmd.Flags().GetString(name)
+	return strings.TrimSpace(value)
+}
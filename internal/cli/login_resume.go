@@ -0,0 +1,173 @@
+// ABOUTME: Two-step non-interactive login for accounts with two-factor auth.
+// ABOUTME: Saves the pending login, minus secrets, and finishes it with 'push login --continue --code'.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// pendingLoginFile holds a login waiting for its two-factor code.
+	pendingLoginFile = "login-pending.json"
+	// pendingLoginTTL is how long a pending login can be resumed.
+	pendingLoginTTL = 10 * time.Minute
+	// resumeLoginCommand is how a provisioning tool finishes the login.
+	resumeLoginCommand = "push login --continue --code <code>"
+)
+
+// pendingLogin is what --continue needs to finish a login besides the
+// secrets, which it reads again from the same sources as the first attempt.
+type pendingLogin struct {
+	Mode       string    `json:"mode"`
+	Email      string    `json:"email"`
+	DeviceName string    `json:"device_name,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// twoFactorRequired is printed on stdout so tools can detect the second step.
+type twoFactorRequired struct {
+	Error     string    `json:"error"`
+	Message   string    `json:"message"`
+	Resume    string    `json:"resume"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func pendingLoginPath() (string, error) {
+	dir, err := resolveDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, pendingLoginFile), nil
+}
+
+// deferLogin saves req for --continue, reports the next step as JSON, and
+// fails the command so scripts see a non-zero exit.
+func deferLogin(cmd *cobra.Command, req loginRequest) error {
+	path, err := pendingLoginPath()
+	if err != nil {
+		return err
+	}
+	pending := pendingLogin{
+		Mode:       req.Mode,
+		Email:      req.Email,
+		DeviceName: req.DeviceName,
+		ExpiresAt:  time.Now().Add(pendingLoginTTL).UTC(),
+	}
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return fmt.Errorf("encode pending login: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("save pending login: %w", err)
+	}
+
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(twoFactorRequired{
+		Error:     "two_factor_required",
+		Message:   "Pushover sent a two-factor challenge; finish the login with the code, supplying the password the same way again.",
+		Resume:    resumeLoginCommand,
+		ExpiresAt: pending.ExpiresAt,
+	}); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: run '%s' within %d minutes", errTwoFactorPending, resumeLoginCommand, int(pendingLoginTTL.Minutes()))
+}
+
+// discardPendingLogin removes a pending login left by an earlier attempt, so
+// a new login starts over.
+func discardPendingLogin() {
+	path, err := pendingLoginPath()
+	if err != nil {
+		return
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warn("unable to remove pending login", "path", path, "error", err)
+	}
+}
+
+// runLoginContinue finishes the saved login with the code from --code and
+// the password and app credentials read again from flags, the secret file,
+// the config, or a prompt.
+func runLoginContinue(cmd *cobra.Command, src loginSource, cfg *config.Config, cfgPath string) error {
+	if src.twoFactor == "" {
+		return errors.New("--continue needs the two-factor code: pass --code")
+	}
+	path, err := pendingLoginPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return errors.New("no login waiting for a two-factor code: run 'push login --non-interactive' first")
+	}
+	if err != nil {
+		return fmt.Errorf("read pending login: %w", err)
+	}
+
+	var pending pendingLogin
+	if err := json.Unmarshal(data, &pending); err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("decode pending login: %w", err)
+	}
+	if time.Now().After(pending.ExpiresAt) {
+		_ = os.Remove(path)
+		return fmt.Errorf("pending login expired at %s: start over with 'push login'", pending.ExpiresAt.Local().Format(time.Kitchen))
+	}
+	if pending.Mode == loginModeRefresh && !cfg.DeviceConfigured() {
+		return fmt.Errorf("no registered device to refresh: run 'push login' first")
+	}
+
+	req, err := resumeRequest(cmd, src, cfg, pending)
+	if err != nil {
+		return err
+	}
+	err = completeLogin(cmd, src, cfg, cfgPath, req)
+	// A rejected code can be retried until the login expires; anything else
+	// ends it.
+	if !errors.Is(err, pushover.ErrTwoFactorRequired) {
+		if removeErr := os.Remove(path); removeErr != nil && err == nil {
+			logger.Warn("unable to remove pending login", "path", path, "error", removeErr)
+		}
+	}
+	return err
+}
+
+// resumeRequest rebuilds the login request for pending from the same sources
+// the first attempt read.
+func resumeRequest(cmd *cobra.Command, src loginSource, cfg *config.Config, pending pendingLogin) (loginRequest, error) {
+	req := loginRequest{
+		Mode:       pending.Mode,
+		AppToken:   cfg.AppToken,
+		UserKey:    cfg.UserKey,
+		Email:      pending.Email,
+		DeviceName: pending.DeviceName,
+	}
+	var err error
+	if req.Mode != loginModeRefresh {
+		if req.AppToken, err = src.text("Pushover app token", "app_token", flagString(cmd, "app-token"), cfg.AppToken); err != nil {
+			return req, fmt.Errorf("reading app token: %w", err)
+		}
+		if req.UserKey, err = src.text("Pushover user key", "user_key", flagString(cmd, "user-key"), cfg.UserKey); err != nil {
+			return req, fmt.Errorf("reading user key: %w", err)
+		}
+	}
+	passwordStdin, _ := cmd.Flags().GetBool("password-stdin")
+	if req.Password, err = src.password(cmd.InOrStdin(), passwordStdin); err != nil {
+		return req, fmt.Errorf("reading password: %w", err)
+	}
+	return req, nil
+}
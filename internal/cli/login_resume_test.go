@@ -0,0 +1,126 @@
+// ABOUTME: Tests for the two-step non-interactive login.
+// ABOUTME: Checks the pending login holds no password, resumes with the code, expires, and is cleared by a new login.
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+)
+
+// loginEnv points the config and data directory at a temporary directory
+// and the API at a mock server requiring a two-factor code.
+func loginEnv(t *testing.T) (*pushovertest.Server, string) {
+	t.Helper()
+	srv := pushovertest.NewServer()
+	srv.TwoFactorCode = "123456"
+	t.Cleanup(srv.Close)
+	t.Setenv(config.APIURLEnv, srv.URL())
+	t.Setenv(passwordFileEnv, "")
+
+	dir := t.TempDir()
+	saved := opts
+	opts.configPath = filepath.Join(dir, "config.toml")
+	opts.dataDir = dir
+	t.Cleanup(func() { opts = saved })
+	return srv, dir
+}
+
+func runLoginCmd(t *testing.T, password string, args ...string) error {
+	t.Helper()
+	cmd := newLoginCmd()
+	cmd.SetArgs(append([]string{
+		"--non-interactive", "--password-stdin",
+		"--app-token", pushovertest.AppToken, "--user-key", pushovertest.UserKey,
+	}, args...))
+	cmd.SetIn(strings.NewReader(password))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetContext(context.Background())
+	return cmd.Execute()
+}
+
+func writePending(t *testing.T, dir string, pending pendingLogin) string {
+	t.Helper()
+	data, err := json.Marshal(pending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, pendingLoginFile)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoginContinue(t *testing.T) {
+	_, dir := loginEnv(t)
+
+	err := runLoginCmd(t, pushovertest.Password, "--email", pushovertest.Email, "--device-name", "rack")
+	if !errors.Is(err, errTwoFactorPending) {
+		t.Fatalf("login without a code = %v, want a pending two-factor login", err)
+	}
+	path := filepath.Join(dir, pendingLoginFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read pending login: %v", err)
+	}
+	if strings.Contains(string(data), pushovertest.Password) {
+		t.Errorf("pending login %s holds the password", data)
+	}
+
+	if err := runLoginCmd(t, pushovertest.Password, "--continue", "--code", "000000"); err == nil {
+		t.Fatal("continue with a wrong code succeeded")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("pending login gone after a wrong code: %v", err)
+	}
+	if err := runLoginCmd(t, pushovertest.Password, "--continue", "--code", "123456"); err != nil {
+		t.Fatalf("continue: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("pending login left after success: %v", err)
+	}
+	cfg, err := config.Load(opts.configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DeviceID != pushovertest.DeviceID || cfg.DeviceName != "rack" || cfg.Email != pushovertest.Email {
+		t.Errorf("saved config = device %q named %q for %q", cfg.DeviceID, cfg.DeviceName, cfg.Email)
+	}
+}
+
+func TestLoginContinueExpired(t *testing.T) {
+	_, dir := loginEnv(t)
+	path := writePending(t, dir, pendingLogin{Mode: loginModeNew, Email: pushovertest.Email, ExpiresAt: time.Now().Add(-time.Minute)})
+
+	err := runLoginCmd(t, pushovertest.Password, "--continue", "--code", "123456")
+	if err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("continue after expiry = %v, want an expiry error", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expired pending login left on disk: %v", err)
+	}
+}
+
+func TestLoginDiscardsPending(t *testing.T) {
+	_, dir := loginEnv(t)
+	path := writePending(t, dir, pendingLogin{Mode: loginModeNew, Email: pushovertest.Email, ExpiresAt: time.Now().Add(time.Hour)})
+
+	// Even a login that fails starts by dropping the old pending one.
+	if err := runLoginCmd(t, "wrong", "--email", pushovertest.Email, "--code", "123456"); err == nil {
+		t.Fatal("login with a wrong password succeeded")
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("pending login left after a new login: %v", err)
+	}
+}
@@ -17,6 +17,8 @@ func newLogoutCmd() *cobra.Command {
 			return runLogout(cmd)
 		},
 	}
+	cmd.Flags().String("device", "", "remove only this additional [devices] entry")
+	_ = cmd.RegisterFlagCompletionFunc("device", completeReceivingDevices)
 	return cmd
 }
 
@@ -25,6 +27,9 @@ func runLogout(cmd *cobra.Command) error {
 	if err != nil {
 		return err
 	}
+	if name, _ := cmd.Flags().GetString("device"); name != "" && name != cfg.ReceivingDevice() {
+		return removeDevice(cmd, cfg, cfgPath, name)
+	}
 	if cfg.DeviceID == "" && cfg.DeviceSecret == "" {
 		cmd.Println("No device credentials were stored.")
 		return nil
@@ -32,6 +37,7 @@ func runLogout(cmd *cobra.Command) error {
 
 	cfg.DeviceID = ""
 	cfg.DeviceSecret = ""
+	cfg.DeviceName = ""
 
 	if err := config.Save(cfgPath, cfg); err != nil {
 		return fmt.Errorf("saving config: %w", err)
@@ -40,3 +46,23 @@ func runLogout(cmd *cobra.Command) error {
 	cmd.Println("✓ Device credentials removed.")
 	return nil
 }
+
+// removeDevice drops an additional receiving device from [devices].
+func removeDevice(cmd *cobra.Command, cfg *config.Config, cfgPath, name string) error {
+	if _, ok := cfg.Devices[name]; !ok {
+		return fmt.Errorf("unknown device %q", name)
+	}
+	devices := make(map[string]config.DeviceConfig, len(cfg.Devices))
+	for existing, device := range cfg.Devices {
+		if existing != name {
+			devices[existing] = device
+		}
+	}
+	cfg.Devices = devices
+
+	if err := config.Save(cfgPath, cfg); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+	cmd.Printf("✓ Device %q removed.\n", name)
+	return nil
+}
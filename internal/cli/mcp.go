@@ -3,18 +3,23 @@
 package cli
 
 import (
-	"fmt"
+	"net"
 
+	"github.com/harper/push/internal/config"
 	pushmcp "github.com/harper/push/internal/mcp"
 	"github.com/spf13/cobra"
 )
 
 func newMCPCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "mcp",
-		Short: "Start the MCP server",
-		RunE:  runMCP,
+		Use:         "mcp",
+		Annotations: map[string]string{serverAnnotation: "true"},
+		Short:       "Start the MCP server",
+		Long:        "Start the MCP server over stdio. --read-only (or read_only under [mcp]) hides send_notification and mark_read and stops check_messages from acknowledging; enabled_tools under [mcp] exposes only the listed tools.",
+		RunE:        runMCP,
 	}
+	cmd.Flags().Bool("read-only", false, "Expose only tools that read messages and history")
+	cmd.Flags().String("http", "", "Serve concurrent sessions over streamable HTTP at this address (e.g. 127.0.0.1:8765) instead of stdio")
 	return cmd
 }
 
@@ -24,11 +29,15 @@ func runMCP(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if readOnly, _ := cmd.Flags().GetBool("read-only"); readOnly {
+		cfg.MCP.ReadOnly = true
+	}
+
 	if err := cfg.ValidateSend(); err != nil {
-		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", err)
+		logger.Warn("sending is not configured", "error", err)
 	}
 	if !cfg.DeviceConfigured() {
-		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: device not configured, check_messages and mark_read will fail until you run 'push login'\n")
+		logger.Warn("device not configured; check_messages and mark_read will fail until you run 'push login'")
 	}
 
 	store, dbPath, err := openStore()
@@ -41,7 +50,59 @@ func runMCP(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	cache, _, err := newMediaCache(cfg, store)
+	if err != nil {
+		return err
+	}
+	server.SetMediaCache(cache)
+	server.SetRequestLogger(debugLog.logger)
+	go watchMCPConfig(cmd, server, cfgPath)
+
+	if addr, _ := cmd.Flags().GetString("http"); addr != "" {
+		if !isLoopback(addr) {
+			logger.Warn("MCP HTTP transport has no authentication; anyone who can reach it can use these tools", "addr", addr)
+		}
+		logger.Info("starting MCP server", "transport", "http", "url", "http://"+addr+"/mcp", "tools", server.Tools(), "read_only", cfg.MCP.ReadOnly)
+		return server.ServeHTTP(cmd.Context(), addr)
+	}
 
-	_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Starting MCP server (stdio)...")
+	logger.Info("starting MCP server", "transport", "stdio", "tools", server.Tools(), "read_only", cfg.MCP.ReadOnly)
 	return server.Serve(cmd.Context())
 }
+
+// watchMCPConfig hands the server each saved version of the config file, so
+// new credentials and defaults apply without restarting the client.
+func watchMCPConfig(cmd *cobra.Command, server *pushmcp.Server, cfgPath string) {
+	readOnly, _ := cmd.Flags().GetBool("read-only")
+	err := config.Watch(cmd.Context(), cfgPath, func() {
+		cfg, _, err := loadConfig()
+		if err != nil {
+			logger.Warn("config changed but could not be reloaded; keeping the running config", "error", err)
+			return
+		}
+		if readOnly {
+			cfg.MCP.ReadOnly = true
+		}
+		restart := server.SetConfig(cfg)
+		logger.Info("reloaded configuration")
+		if len(restart) > 0 {
+			logger.Warn("some config changes need a restart of push mcp", "settings", restart)
+		}
+	})
+	if err != nil {
+		logger.Warn("not watching config for changes", "error", err)
+	}
+}
+
+// isLoopback reports whether addr binds only to the local machine.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
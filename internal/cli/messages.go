@@ -3,10 +3,17 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
 
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
 	"github.com/harper/push/internal/messages"
-	"github.com/harper/push/internal/pushover"
+	"github.com/harper/push/internal/render"
+	"github.com/harper/push/pkg/pushover"
 	"github.com/spf13/cobra"
 )
 
@@ -17,28 +24,66 @@ func newMessagesCmd() *cobra.Command {
 		RunE:  runMessages,
 	}
 
-	cmd.Flags().IntP("limit", "n", 10, "maximum messages to return")
+	cmd.Flags().IntP("limit", "n", 10, "maximum messages to return (default from [messages] default_limit)")
+	cmd.Flags().Bool("no-ack", false, "leave fetched messages on the Pushover server")
+	cmd.Flags().Int64("ack-up-to", 0, "only acknowledge messages up to and including this Pushover ID")
+	cmd.MarkFlagsMutuallyExclusive("no-ack", "ack-up-to")
+	cmd.Flags().Bool("qr", false, "render message URLs as terminal QR codes")
+	cmd.Flags().Bool("raw", false, "show HTML messages with their markup instead of rendering them")
+	cmd.Flags().String("device", "", "only poll this receiving device (default: all configured devices)")
+	addFormatFlag(cmd, "message")
+	cmd.MarkFlagsMutuallyExclusive("format", "qr")
+	_ = cmd.RegisterFlagCompletionFunc("device", completeReceivingDevices)
 
 	return cmd
 }
 
+// messagesOptions are the push messages flags, resolved against the config.
+type messagesOptions struct {
+	limit   int
+	showQR  bool
+	raw     bool
+	noAck   bool
+	ackUpTo int64
+	tmpl    *template.Template
+}
+
+func messagesFlags(cmd *cobra.Command, cfg *config.Config, devices int) (messagesOptions, error) {
+	var opts messagesOptions
+	opts.limit, _ = cmd.Flags().GetInt("limit")
+	if !cmd.Flags().Changed("limit") && cfg.Messages.DefaultLimit > 0 {
+		opts.limit = cfg.Messages.DefaultLimit
+	}
+	if opts.limit <= 0 {
+		opts.limit = 10
+	}
+	opts.showQR, _ = cmd.Flags().GetBool("qr")
+	opts.raw, _ = cmd.Flags().GetBool("raw")
+	opts.noAck, _ = cmd.Flags().GetBool("no-ack")
+	opts.ackUpTo, _ = cmd.Flags().GetInt64("ack-up-to")
+	if cmd.Flags().Changed("ack-up-to") && opts.ackUpTo <= 0 {
+		return messagesOptions{}, fmt.Errorf("--ack-up-to must be a positive message ID")
+	}
+	if opts.ackUpTo > 0 && devices > 1 {
+		return messagesOptions{}, fmt.Errorf("--ack-up-to needs --device when several receiving devices are configured")
+	}
+	var err error
+	if opts.tmpl, err = formatTemplate(cmd); err != nil {
+		return messagesOptions{}, err
+	}
+	return opts, nil
+}
+
 func runMessages(cmd *cobra.Command, args []string) error {
 	cfg, _, err := loadConfig()
 	if err != nil {
 		return err
 	}
-	if err := cfg.ValidateReceive(); err != nil {
+	devices, err := messageDevices(cmd, cfg)
+	if err != nil {
 		return err
 	}
-
-	limit, _ := cmd.Flags().GetInt("limit")
-	if limit <= 0 {
-		limit = 10
-	}
-
-	client := newClientFromConfig(cfg)
-	ctx := cmd.Context()
-	result, err := client.FetchMessages(ctx)
+	opts, err := messagesFlags(cmd, cfg, len(devices))
 	if err != nil {
 		return err
 	}
@@ -49,54 +94,250 @@ func runMessages(cmd *cobra.Command, args []string) error {
 	}
 	defer func() { _ = store.Close() }()
 
-	if _, err := messages.PersistReceived(ctx, store, result.Messages); err != nil {
-		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to persist messages: %v\n", err)
+	polls, err := pollDevices(cmd.Context(), devices, store, opts)
+	if err != nil {
+		return err
 	}
-
-	if last := highestMessageID(result, result.Messages); last > 0 {
-		if err := client.DeleteMessages(ctx, last); err != nil {
-			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: unable to ack messages: %v\n", err)
+	var shown []polledMessage
+	for _, poll := range polls {
+		for _, msg := range poll.messages {
+			shown = append(shown, polledMessage{device: poll.device, ReceivedMessage: msg})
 		}
 	}
-
-	messages := result.Messages
-	if len(messages) > limit {
-		messages = messages[:limit]
+	if len(shown) > opts.limit {
+		shown = shown[:opts.limit]
 	}
 
-	if len(messages) == 0 {
+	multiDevice := len(devices) > 1
+	switch {
+	case len(shown) == 0:
 		cmd.Println("No new messages.")
+		printMuted(cmd, polls)
+		return nil
+	case opts.tmpl != nil:
+		records := make([]db.MessageRecord, 0, len(shown))
+		for _, msg := range shown {
+			rec := messages.RecordsFromReceived([]pushover.ReceivedMessage{msg.ReceivedMessage})[0]
+			rec.Device = msg.device
+			records = append(records, rec)
+		}
+		if err := writeFormatted(cmd.OutOrStdout(), opts.tmpl, records); err != nil {
+			return err
+		}
+		printMuted(cmd, polls)
+		printLeftOnServer(cmd, polls, opts.noAck, multiDevice)
 		return nil
 	}
 
-	for _, msg := range messages {
-		cmd.Printf("[%d] %s\n", msg.PushoverID, msg.Message)
+	theme, err := listingTheme(cfg, cmd.OutOrStderr())
+	if err != nil {
+		return err
+	}
+	return withPager(cmd, func() error {
+		printPolledMessages(cmd, shown, theme, opts, multiDevice)
+		printMuted(cmd, polls)
+		printLeftOnServer(cmd, polls, opts.noAck, multiDevice)
+		return nil
+	})
+}
+
+// pollDevices polls each receiving device in turn. With several devices, one
+// that fails is logged and skipped rather than failing the rest.
+func pollDevices(ctx context.Context, devices []*config.Config, store *db.Store, opts messagesOptions) ([]devicePoll, error) {
+	var polls []devicePoll
+	for _, device := range devices {
+		var fetched []pushover.ReceivedMessage
+		poll, err := pollDevice(ctx, device, store, opts.noAck, opts.ackUpTo, func(batch []pushover.ReceivedMessage) {
+			fetched = append(fetched, batch...)
+		})
+		poll.messages = fetched
+		if err != nil {
+			if len(devices) == 1 {
+				return nil, err
+			}
+			logger.Warn("unable to poll device", "device", device.ReceivingDevice(), "error", err)
+			// Batches handled before the failure were stored and
+			// acknowledged, so show them.
+			if len(fetched) == 0 {
+				continue
+			}
+		}
+		polls = append(polls, poll)
+	}
+	return polls, nil
+}
+
+// printPolledMessages writes the fetched messages in the listing format.
+func printPolledMessages(cmd *cobra.Command, shown []polledMessage, theme render.Theme, opts messagesOptions, multiDevice bool) {
+	color := colorEnabled(cmd.OutOrStderr())
+	for _, msg := range shown {
+		body := displayBody(msg.Message, msg.HTML != 0, opts.raw, color)
+		cmd.Printf("[%d] %s\n", msg.PushoverID, theme.ForPriority(msg.Priority, body))
 		if msg.Title != "" {
-			cmd.Printf("  Title: %s\n", msg.Title)
+			cmd.Printf("  %s %s\n", theme.Dimmed("Title:"), msg.Title)
 		}
 		if msg.App != "" {
-			cmd.Printf("  App: %s\n", msg.App)
+			cmd.Printf("  %s %s\n", theme.Dimmed("App:"), msg.App)
+		}
+		if multiDevice {
+			cmd.Printf("  %s %s\n", theme.Dimmed("Device:"), msg.device)
 		}
 		if msg.URL != "" {
-			cmd.Printf("  URL: %s\n", msg.URL)
+			cmd.Printf("  %s %s\n", theme.Dimmed("URL:"), msg.URL)
+			if opts.showQR {
+				if err := writeQR(cmd.OutOrStderr(), msg.URL, "  "); err != nil {
+					logger.Warn("unable to render QR code", "error", err)
+				}
+			}
 		}
 		if msg.Priority != 0 {
-			cmd.Printf("  Priority: %d\n", msg.Priority)
+			cmd.Printf("  %s %s\n", theme.Dimmed("Priority:"), theme.ForPriority(msg.Priority, strconv.Itoa(msg.Priority)))
+		}
+		if msg.NeedsAck() {
+			cmd.Printf("  %s\n", theme.ForPriority(2, "Awaiting acknowledgement: push ack "+msg.Receipt))
 		}
 	}
+}
+
+// printLeftOnServer tells how to acknowledge messages fetched with --no-ack.
+func printLeftOnServer(cmd *cobra.Command, polls []devicePoll, noAck, multiDevice bool) {
+	for _, poll := range polls {
+		if !noAck || poll.last == 0 {
+			continue
+		}
+		if multiDevice {
+			cmd.Printf("Messages left on server. Acknowledge with: push messages --device %s --ack-up-to %d\n", poll.device, poll.last)
+		} else {
+			cmd.Printf("Messages left on server. Acknowledge with: push messages --ack-up-to %d\n", poll.last)
+		}
+	}
+}
 
-	return nil
+// devicePoll is what one receiving device returned.
+type devicePoll struct {
+	device   string
+	messages []pushover.ReceivedMessage
+	last     int64
+	// muted counts the messages left out of messages because 'push mute'
+	// covers their app.
+	muted int
 }
 
-func highestMessageID(result *pushover.FetchResult, msgs []pushover.ReceivedMessage) int64 {
-	if result != nil && result.LastMessageID > 0 {
-		return result.LastMessageID
+type polledMessage struct {
+	device string
+	pushover.ReceivedMessage
+}
+
+// messageDevices resolves the receiving devices to poll: the --device one, or
+// every configured device.
+func messageDevices(cmd *cobra.Command, cfg *config.Config) ([]*config.Config, error) {
+	if err := cfg.ValidateSend(); err != nil {
+		return nil, err
+	}
+	names := cfg.ReceivingDevices()
+	if name, _ := cmd.Flags().GetString("device"); name != "" {
+		names = []string{name}
+	}
+	if len(names) == 0 {
+		return nil, cfg.ValidateReceive()
+	}
+
+	devices := make([]*config.Config, 0, len(names))
+	for _, name := range names {
+		device, err := cfg.ForDevice(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := device.ValidateReceive(); err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
 	}
+	return devices, nil
+}
+
+// pollDevice streams the messages waiting for one receiving device, at their
+// local priorities under priority_rules, storing and (unless noAck)
+// acknowledging them messages.StreamBatch at a time, up to ackUpTo when it's
+// set. Each batch, less messages from muted apps, goes to handle before it's
+// acknowledged. If the fetch fails partway, the batches already handled stay
+// acknowledged so they aren't handled again.
+func pollDevice(ctx context.Context, cfg *config.Config, store *db.Store, noAck bool, ackUpTo int64, handle func([]pushover.ReceivedMessage)) (devicePoll, error) {
+	rules, err := cfg.LocalPriorities()
+	if err != nil {
+		return devicePoll{}, err
+	}
+	client, err := newClientFromConfig(cfg)
+	if err != nil {
+		return devicePoll{}, err
+	}
+	cache, cacheOK, err := newMediaCache(cfg, store)
+	if err != nil {
+		logger.Warn("media cache unavailable", "error", err)
+	}
+	stream, err := client.FetchMessagesStream(ctx)
+	if err != nil {
+		return devicePoll{}, err
+	}
+	defer func() { _ = stream.Close() }()
+
+	poll := devicePoll{device: cfg.ReceivingDevice()}
+	var acked int64
+	ack := func(upTo int64) {
+		if ackUpTo > 0 {
+			upTo = min(upTo, ackUpTo)
+		}
+		poll.last = max(poll.last, upTo)
+		if noAck || upTo <= acked {
+			return
+		}
+		if err := client.DeleteMessages(ctx, upTo); err != nil {
+			logger.Warn("unable to ack messages", "device", poll.device, "up_to", upTo, "error", err)
+			return
+		}
+		acked = upTo
+	}
+	for batch, err := range stream.Batches(messages.StreamBatch) {
+		if err != nil {
+			return poll, err
+		}
+		messages.ApplyLocalPriorities(rules, batch)
+		if _, err := messages.PersistReceived(ctx, store, poll.device, batch); err != nil {
+			logger.Warn("failed to persist messages", "device", poll.device, "error", err)
+		}
+		if cacheOK {
+			if err := cache.CacheIcons(ctx, batch); err != nil {
+				logger.Warn("unable to cache icons", "error", err)
+			}
+		}
+		shown, muted := withoutMuted(ctx, store, batch)
+		poll.muted += muted
+		handle(shown)
+		ack(highestMessageID(batch))
+	}
+	ack(stream.LastMessageID)
+	return poll, nil
+}
+
+func highestMessageID(msgs []pushover.ReceivedMessage) int64 {
 	var highest int64
 	for _, msg := range msgs {
-		if msg.PushoverID > highest {
-			highest = msg.PushoverID
-		}
+		highest = max(highest, msg.PushoverID)
 	}
 	return highest
 }
+
+// completeReceivingDevices offers the configured receiving device names.
+func completeReceivingDevices(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, name := range cfg.ReceivingDevices() {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,216 @@
+// ABOUTME: Monitor command for uptime checks of URLs run by the daemon.
+// ABOUTME: Adds, lists, removes, and test-runs monitors that alert when a URL goes down.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/monitor"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+// maxMonitorTimeout bounds --timeout; the monitor HTTP client gives up then.
+const maxMonitorTimeout = time.Minute
+
+func newMonitorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "monitor",
+		Short:       "Watch URLs and get notified when they go down or come back",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Register URLs for 'push daemon' to check. A notification goes out when a URL goes down and when it comes back up; the state only changes after --confirm checks in a row agree, so a single slow or failed request doesn't page you. Checks run on the daemon's ticks, so a monitor is checked at most once per daemon --interval.",
+		Args:        cobra.NoArgs,
+		RunE:        runMonitorList,
+	}
+	cmd.Flags().Bool("json", false, "output JSON")
+
+	cmd.AddCommand(newMonitorAddCmd(), newMonitorRemoveCmd(), newMonitorCheckCmd())
+
+	return cmd
+}
+
+func newMonitorAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <url>",
+		Short: "Start monitoring a URL, or change an existing monitor",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runMonitorAdd,
+	}
+	cmd.Flags().String("name", "", "name used in notifications and to remove it (default: the URL's host and path)")
+	cmd.Flags().Duration("every", time.Minute, "how often to check")
+	cmd.Flags().String("expect", "200", "accepted status codes, e.g. 200, 2xx, or 200,301")
+	cmd.Flags().Duration("timeout", 10*time.Second, "count the check as down after this long without a response")
+	cmd.Flags().Int("confirm", 2, "checks in a row that must agree before the state changes")
+	return cmd
+}
+
+func runMonitorAdd(cmd *cobra.Command, args []string) error {
+	target, err := url.Parse(args[0])
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("monitor URL must be an http or https URL, got %q", args[0])
+	}
+	m := db.MonitorRecord{URL: target.String()}
+	m.Name, _ = cmd.Flags().GetString("name")
+	if m.Name == "" {
+		m.Name = target.Host + strings.TrimSuffix(target.EscapedPath(), "/")
+	}
+	m.Every, _ = cmd.Flags().GetDuration("every")
+	if m.Every < 10*time.Second {
+		return fmt.Errorf("--every must be at least 10s")
+	}
+	m.Timeout, _ = cmd.Flags().GetDuration("timeout")
+	if m.Timeout < time.Second || m.Timeout > maxMonitorTimeout {
+		return fmt.Errorf("--timeout must be between 1s and %s", maxMonitorTimeout)
+	}
+	m.Expect, _ = cmd.Flags().GetString("expect")
+	if _, err := monitor.ParseExpect(m.Expect); err != nil {
+		return fmt.Errorf("--expect: %w", err)
+	}
+	m.Confirm, _ = cmd.Flags().GetInt("confirm")
+	if m.Confirm < 1 {
+		return fmt.Errorf("--confirm must be at least 1")
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.SaveMonitor(cmd.Context(), m); err != nil {
+		return err
+	}
+	cmd.Printf("✓ Monitoring %s as %q every %s, expecting %s.\n", m.URL, m.Name, m.Every, m.Expect)
+	cmd.Println("Checks run while 'push daemon' is running.")
+	return nil
+}
+
+func runMonitorList(cmd *cobra.Command, args []string) error {
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	monitors, err := store.ListMonitors(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(monitors)
+	}
+	if len(monitors) == 0 {
+		cmd.Println("No monitors. Add one with 'push monitor add <url>'.")
+		return nil
+	}
+	for _, m := range monitors {
+		cmd.Printf("%s [%s] %s every %s, expecting %s\n", m.Name, monitorStateLabel(m), m.URL, m.Every, m.Expect)
+		if !m.LastChecked.IsZero() {
+			cmd.Printf("  last checked %s: %s\n", m.LastChecked.Local().Format(time.RFC3339), m.LastResult)
+		}
+	}
+	return nil
+}
+
+// monitorStateLabel shows the state, flagging one that is about to change.
+func monitorStateLabel(m db.MonitorRecord) string {
+	switch m.State {
+	case db.MonitorDown:
+		if m.Streak > 0 {
+			return fmt.Sprintf("DOWN, %d ok", m.Streak)
+		}
+		return "DOWN"
+	case db.MonitorUp:
+		if m.Streak > 0 {
+			return fmt.Sprintf("up, %d failed", m.Streak)
+		}
+		return "up"
+	}
+	return "unknown"
+}
+
+func newMonitorRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Stop monitoring a URL",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			removed, err := store.DeleteMonitor(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("no monitor named %q", args[0])
+			}
+			cmd.Printf("✓ Monitor %q removed.\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newMonitorCheckCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check",
+		Short: "Check every monitor once now and print the results, without notifying",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, _, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			client, err := newMonitorClient(cfg)
+			if err != nil {
+				return err
+			}
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			monitors, err := store.ListMonitors(cmd.Context())
+			if err != nil {
+				return err
+			}
+			down := 0
+			for _, m := range monitors {
+				result := monitor.Check(cmd.Context(), client, m)
+				mark := "✓"
+				if !result.Up {
+					mark = "✗"
+					down++
+				}
+				cmd.Printf("%s %s: %s\n", mark, m.Name, result)
+			}
+			if down > 0 {
+				return fmt.Errorf("%d of %d monitors failed", down, len(monitors))
+			}
+			return nil
+		},
+	}
+}
+
+// newMonitorClient returns the HTTP client monitors check with, honouring
+// proxy_url and ca_cert_path. Each check applies its own timeout.
+func newMonitorClient(cfg *config.Config) (*http.Client, error) {
+	return pushover.NewHTTPClient(pushover.HTTPClientOptions{
+		ProxyURL:   cfg.ProxyURL,
+		CACertPath: cfg.CACertPath,
+		Timeout:    maxMonitorTimeout,
+	})
+}
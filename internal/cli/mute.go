@@ -0,0 +1,176 @@
+// ABOUTME: Mute command that quiets received messages from chatty apps.
+// ABOUTME: Muted apps' messages are still saved but skip hooks and listings.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+func newMuteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "mute [app-name]",
+		Short:       "Hide received messages from an app, e.g. during its maintenance window",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Stop showing messages from an app in 'push messages' and 'push watch', and stop running --exec hooks for them, until the mute expires or is removed. The messages are still saved to history and acknowledged. The app name is the one shown as App: by 'push messages', matched ignoring case. Without an app, list the current mutes.",
+		Args:        cobra.MaximumNArgs(1),
+		RunE:        runMute,
+	}
+	cmd.Flags().String("for", "", "end the mute after this long (e.g. 2h, 3d; default: until removed)")
+
+	cmd.AddCommand(newMuteListCmd(), newMuteRemoveCmd())
+
+	return cmd
+}
+
+func runMute(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return listMutes(cmd, false)
+	}
+	app := strings.TrimSpace(args[0])
+	if app == "" {
+		return fmt.Errorf("app name is required")
+	}
+	now := time.Now()
+	var until time.Time
+	if value, _ := cmd.Flags().GetString("for"); value != "" {
+		span, ok := parseSpan(value)
+		if !ok || span <= 0 {
+			return fmt.Errorf("invalid --for duration %q (use e.g. 30m, 2h, 3d)", value)
+		}
+		until = now.Add(span)
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.MuteApp(cmd.Context(), app, until, now); err != nil {
+		return err
+	}
+	if until.IsZero() {
+		cmd.Printf("✓ %s muted until 'push mute remove %s'.\n", app, app)
+	} else {
+		cmd.Printf("✓ %s muted until %s (%s).\n", app, until.Local().Format(time.RFC3339), roughDuration(until.Sub(now)))
+	}
+	return nil
+}
+
+func newMuteListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List muted apps and when their mutes end",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return listMutes(cmd, asJSON)
+		},
+	}
+	cmd.Flags().Bool("json", false, "output JSON")
+	return cmd
+}
+
+func listMutes(cmd *cobra.Command, asJSON bool) error {
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now()
+	mutes, err := store.ListMutes(cmd.Context(), now)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		if mutes == nil {
+			mutes = []db.MuteRecord{}
+		}
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(mutes)
+	}
+	if len(mutes) == 0 {
+		cmd.Println("No apps muted.")
+		return nil
+	}
+	for _, m := range mutes {
+		if m.Until.IsZero() {
+			cmd.Printf("%s: until removed\n", m.App)
+			continue
+		}
+		cmd.Printf("%s: until %s (%s left)\n", m.App, m.Until.Local().Format(time.RFC3339), roughDuration(m.Until.Sub(now)))
+	}
+	return nil
+}
+
+func newMuteRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <app-name>",
+		Short: "Unmute an app",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			active, err := store.Unmute(cmd.Context(), args[0], time.Now())
+			if err != nil {
+				return err
+			}
+			if !active {
+				return fmt.Errorf("%s is not muted", args[0])
+			}
+			cmd.Printf("✓ %s unmuted.\n", args[0])
+			return nil
+		},
+	}
+}
+
+// withoutMuted drops the messages from muted apps, returning how many it
+// dropped. A failed lookup keeps them all rather than hiding any.
+func withoutMuted(ctx context.Context, store *db.Store, msgs []pushover.ReceivedMessage) ([]pushover.ReceivedMessage, int) {
+	if len(msgs) == 0 {
+		return msgs, 0
+	}
+	muted, err := store.MutedApps(ctx, time.Now())
+	if err != nil {
+		logger.Warn("unable to check muted apps", "error", err)
+		return msgs, 0
+	}
+	if len(muted) == 0 {
+		return msgs, 0
+	}
+	kept := make([]pushover.ReceivedMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		if muted[strings.ToLower(msg.App)] {
+			continue
+		}
+		kept = append(kept, msg)
+	}
+	return kept, len(msgs) - len(kept)
+}
+
+// printMuted tells how many polled messages were hidden by 'push mute'.
+func printMuted(cmd *cobra.Command, polls []devicePoll) {
+	muted := 0
+	for _, poll := range polls {
+		muted += poll.muted
+	}
+	if muted == 1 {
+		cmd.Println("1 message from a muted app saved to history but not shown.")
+	} else if muted > 1 {
+		cmd.Printf("%d messages from muted apps saved to history but not shown.\n", muted)
+	}
+}
@@ -0,0 +1,76 @@
+// ABOUTME: Open command that shows a received message's supplementary URL in the browser.
+// ABOUTME: Looks the message up by Pushover ID, or takes the latest one with a URL.
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/harper/push/internal/browser"
+	"github.com/harper/push/internal/db"
+	"github.com/spf13/cobra"
+)
+
+func newOpenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "open [message-id]",
+		Short:       "Open a received message's URL in the browser",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Open the supplementary URL of a received message in the default browser. Message IDs are the [N] shown by 'push messages' and 'push history'; without one, the latest message with a URL is opened. Use --print on headless machines to print the URL instead.",
+		Args:        cobra.MaximumNArgs(1),
+		RunE:        runOpen,
+	}
+	cmd.Flags().Bool("print", false, "print the URL instead of opening it")
+	return cmd
+}
+
+func runOpen(cmd *cobra.Command, args []string) error {
+	var id int64
+	if len(args) == 1 {
+		var err error
+		id, err = strconv.ParseInt(strings.Trim(args[0], "[]"), 10, 64)
+		if err != nil || id <= 0 {
+			return fmt.Errorf("invalid message id %q", args[0])
+		}
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	var rec db.MessageRecord
+	if id != 0 {
+		var found bool
+		if rec, found, err = store.GetMessage(cmd.Context(), id); err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("no message [%d]; see 'push history'", id)
+		}
+		if rec.URL == "" {
+			return fmt.Errorf("message [%d] has no URL", id)
+		}
+	} else {
+		records, err := store.FindMessages(cmd.Context(), db.MessageFilter{HasURL: true, Limit: 1})
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return fmt.Errorf("no received message has a URL")
+		}
+		rec = records[0]
+	}
+
+	if printOnly, _ := cmd.Flags().GetBool("print"); printOnly {
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), rec.URL)
+		return err
+	}
+	if err := browser.Open(rec.URL); err != nil {
+		return err
+	}
+	cmd.Printf("Opened %s from message [%d]\n", rec.URL, rec.PushoverID)
+	return nil
+}
@@ -0,0 +1,73 @@
+// ABOUTME: Pages long listings through $PAGER when writing to a terminal.
+// ABOUTME: Buffers command output and only starts the pager if it overflows the screen.
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// withPager runs write with the command's output buffered. Output taller than
+// the terminal goes through $PAGER (default "less"); anything else, or any
+// output when --no-pager is set or stdout is not a terminal, is written directly.
+func withPager(cmd *cobra.Command, write func() error) error {
+	out := cmd.OutOrStderr()
+	height, ok := pagerHeight(out)
+	if !ok {
+		return write()
+	}
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	err := write()
+	cmd.SetOut(nil)
+
+	if bytes.Count(buf.Bytes(), []byte("\n")) < height || !runPager(&buf) {
+		_, _ = io.Copy(out, &buf)
+	}
+	return err
+}
+
+// pagerHeight returns the terminal height when paging applies to out.
+func pagerHeight(out io.Writer) (int, bool) {
+	if opts.noPager {
+		return 0, false
+	}
+	f, ok := out.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return 0, false
+	}
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || height <= 0 {
+		return 0, false
+	}
+	return height, true
+}
+
+// runPager feeds content to the pager, reporting false if it could not start.
+func runPager(content io.Reader) bool {
+	args := strings.Fields(os.Getenv("PAGER"))
+	if len(args) == 0 {
+		args = []string{"less"}
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return false
+	}
+
+	pager := exec.Command(path, args[1:]...) //nolint:gosec // the user's chosen pager
+	pager.Stdin = content
+	pager.Stdout = os.Stdout
+	pager.Stderr = os.Stderr
+	if os.Getenv("LESS") == "" {
+		// Like git: quit if one screen, keep colours, don't clear the screen.
+		pager.Env = append(os.Environ(), "LESS=FRX")
+	}
+	return pager.Run() == nil
+}
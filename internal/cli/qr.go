@@ -0,0 +1,51 @@
+// ABOUTME: Terminal QR code rendering for message URLs.
+// ABOUTME: Draws two modules per character cell using Unicode half blocks.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"rsc.io/qr"
+)
+
+// qrQuietZone is the light border, in modules, scanners need around the code.
+const qrQuietZone = 2
+
+// writeQR renders text as a QR code. Light modules are drawn as blocks so the
+// code scans on the usual dark terminal background.
+func writeQR(w io.Writer, text, indent string) error {
+	code, err := qr.Encode(text, qr.M)
+	if err != nil {
+		return fmt.Errorf("encode qr code: %w", err)
+	}
+
+	light := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= code.Size || y >= code.Size {
+			return true
+		}
+		return !code.Black(x, y)
+	}
+
+	var b strings.Builder
+	for y := -qrQuietZone; y < code.Size+qrQuietZone; y += 2 {
+		b.WriteString(indent)
+		for x := -qrQuietZone; x < code.Size+qrQuietZone; x++ {
+			top, bottom := light(x, y), light(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
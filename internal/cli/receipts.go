@@ -0,0 +1,154 @@
+// ABOUTME: Receipts command for following emergency (priority 2) sends.
+// ABOUTME: Lists their acknowledgement state and syncs it from Pushover.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/messages"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+func newReceiptsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "receipts",
+		Short:       "Show whether emergency sends were acknowledged",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "List emergency (priority 2) sends with the state of their receipts: pending while Pushover keeps repeating them, acknowledged, or expired unacknowledged. Run 'push receipts sync' or 'push daemon' to refresh the state.",
+		Args:        cobra.NoArgs,
+		RunE:        runReceipts,
+	}
+
+	cmd.Flags().IntP("limit", "n", 20, "limit number of rows")
+	cmd.Flags().String("status", "", "only receipts in this state: pending, acknowledged, or expired")
+	cmd.Flags().Bool("json", false, "output JSON")
+	_ = cmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions([]string{db.ReceiptPending, db.ReceiptAcknowledged, db.ReceiptExpired}, cobra.ShellCompDirectiveNoFileComp))
+
+	cmd.AddCommand(newReceiptsSyncCmd())
+
+	return cmd
+}
+
+func runReceipts(cmd *cobra.Command, args []string) error {
+	filter := db.SentFilter{ReceiptsOnly: true}
+	filter.Limit, _ = cmd.Flags().GetInt("limit")
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	}
+	filter.ReceiptStatus, _ = cmd.Flags().GetString("status")
+	switch filter.ReceiptStatus {
+	case "", db.ReceiptPending, db.ReceiptAcknowledged, db.ReceiptExpired:
+	default:
+		return fmt.Errorf("--status must be pending, acknowledged, or expired")
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	records, err := store.ListSent(cmd.Context(), filter)
+	if err != nil {
+		return err
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		return writeSentJSON(cmd, records)
+	}
+	if len(records) == 0 {
+		cmd.Println("No emergency sends found.")
+		return nil
+	}
+	for _, rec := range records {
+		cmd.Printf("%s %s %s\n", rec.SentAt.Local().Format(time.RFC3339), rec.Receipt, sentLine(rec))
+		cmd.Printf("  %s\n", receiptState(rec))
+	}
+	return nil
+}
+
+func newReceiptsSyncCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync",
+		Short: "Ask Pushover about pending receipts and record any that settled",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, _, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			settled, err := messages.SyncReceipts(cmd.Context(), store, receiptClients(cfg))
+			for _, rec := range settled {
+				cmd.Printf("%s %s: %s\n", rec.Receipt, sentLine(rec), receiptState(rec))
+			}
+			if err != nil {
+				return fmt.Errorf("sync receipts: %w", err)
+			}
+			pending, err := store.ListSent(cmd.Context(), db.SentFilter{ReceiptStatus: db.ReceiptPending})
+			if err != nil {
+				return err
+			}
+			cmd.Printf("✓ %d receipt(s) settled, %d still pending.\n", len(settled), len(pending))
+			return nil
+		},
+	}
+}
+
+// receiptClients returns clients that read receipts with the token each
+// send used.
+func receiptClients(cfg *config.Config) messages.ReceiptClient {
+	return func(app string) (*pushover.Client, error) {
+		appCfg, err := cfg.ForApp(app)
+		if err != nil {
+			return nil, err
+		}
+		return newClientFromConfig(appCfg)
+	}
+}
+
+// receiptState describes where an emergency send's receipt stands.
+func receiptState(rec db.SentRecord) string {
+	switch rec.ReceiptStatus {
+	case db.ReceiptAcknowledged:
+		state := "acknowledged " + rec.ReceiptAckedAt.Local().Format(time.RFC3339)
+		if rec.ReceiptAckedBy != "" {
+			state += " on " + rec.ReceiptAckedBy
+		}
+		return state
+	case db.ReceiptExpired:
+		return "expired unacknowledged"
+	}
+	if rec.ReceiptExpiresAt.IsZero() {
+		return "pending"
+	}
+	return "pending until " + rec.ReceiptExpiresAt.Local().Format(time.RFC3339)
+}
+
+// sentLine condenses a send to a single line for listings.
+func sentLine(rec db.SentRecord) string {
+	line := rec.Message
+	if rec.Title != "" {
+		line = rec.Title + ": " + line
+	}
+	line = digestLine(db.MessageRecord{Message: line})
+	if rec.Recipient != "" {
+		line += " (to " + rec.Recipient + ")"
+	}
+	return line
+}
+
+func writeSentJSON(cmd *cobra.Command, records []db.SentRecord) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
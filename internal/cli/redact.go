@@ -0,0 +1,176 @@
+// ABOUTME: Redact command that blanks sensitive message bodies in history, the sent log, schedules, and reply links.
+// ABOUTME: Keeps each message's title, app, priority, and dates, e.g. before sharing a backup.
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/spf13/cobra"
+)
+
+func newRedactCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "redact",
+		Short:       "Replace message bodies matching a pattern with " + db.RedactedMarker,
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Replace the bodies of received messages, logged sends, finished scheduled sends, and reply links matching --search, a case-insensitive regular expression, with " + db.RedactedMarker + ", keeping their title, app, priority, and dates. A matching scheduled send that hasn't gone out yet stops the redaction until it's sent or cancelled. The copy of the API response kept for --raw goes too, and so does a URL the pattern matches. Useful before sharing a backup or attaching history to a bug report. Matches are previewed and confirmed first, as with 'push history --delete'. Redaction can't be undone.",
+		Args:        cobra.NoArgs,
+		RunE:        runRedact,
+	}
+	cmd.Flags().String("search", "", "regular expression matched against message bodies, ignoring case (required)")
+	cmd.Flags().String("older-than", "", "only messages older than this (e.g. 7d, 12h)")
+	cmd.Flags().String("app", "", "only messages from or sent with this app")
+	cmd.Flags().Bool("dry-run", false, "show what would be redacted without changing anything")
+	cmd.Flags().BoolP("yes", "y", false, "redact without asking for confirmation")
+	_ = cmd.MarkFlagRequired("search")
+	return cmd
+}
+
+// redactMatches are the rows push redact would change, by table.
+type redactMatches struct {
+	messages  []int64
+	sent      []int64
+	scheduled []int64
+	responses []int64
+}
+
+func (m redactMatches) total() int {
+	return len(m.messages) + len(m.sent) + len(m.scheduled) + len(m.responses)
+}
+
+func runRedact(cmd *cobra.Command, args []string) error {
+	pattern, until, err := redactFlags(cmd)
+	if err != nil {
+		return err
+	}
+	app, _ := cmd.Flags().GetString("app")
+	filter := db.MessageFilter{BodyRegex: pattern, App: app, Until: until}
+	sentFilter := db.SentFilter{BodyRegex: pattern, App: app, Until: until}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := cmd.Context()
+	var matches redactMatches
+	if matches.messages, err = store.MessageIDs(ctx, filter); err != nil {
+		return err
+	}
+	sent, err := store.ListSent(ctx, sentFilter)
+	if err != nil {
+		return err
+	}
+	scheduled, responses, err := matchScheduledAndResponses(cmd, store, pattern, until)
+	if err != nil {
+		return err
+	}
+	if len(matches.messages) == 0 && len(sent) == 0 && len(scheduled) == 0 && len(responses) == 0 {
+		cmd.Println("No matching messages.")
+		return nil
+	}
+	if len(matches.messages) > 0 {
+		if err := previewMatches(cmd, store, filter, len(matches.messages)); err != nil {
+			return err
+		}
+	}
+	matches.previewOthers(cmd, sent, scheduled, responses)
+	summary := fmt.Sprintf("redact %d message(s), %d sent notification(s), %d scheduled send(s), and %d reply link(s)",
+		len(matches.messages), len(matches.sent), len(matches.scheduled), len(matches.responses))
+	if ok, err := confirmBulk(cmd, summary); !ok || err != nil {
+		return err
+	}
+	return applyRedaction(cmd, store, pattern, matches)
+}
+
+// redactFlags returns the case-insensitive body pattern from --search and the
+// cutoff from --older-than, nil when it isn't set.
+func redactFlags(cmd *cobra.Command) (string, *time.Time, error) {
+	search, _ := cmd.Flags().GetString("search")
+	if _, err := regexp.Compile(search); err != nil {
+		return "", nil, fmt.Errorf("parse --search: %w", err)
+	}
+	value, _ := cmd.Flags().GetString("older-than")
+	if value == "" {
+		return "(?i)" + search, nil, nil
+	}
+	span, ok := parseSpan(value)
+	if !ok {
+		return "", nil, fmt.Errorf("--older-than must be a duration like 7d or 12h, got %q", value)
+	}
+	cutoff := time.Now().Add(-span)
+	return "(?i)" + search, &cutoff, nil
+}
+
+// previewOthers lists the matching sends, scheduled sends, and reply links,
+// collecting their IDs. Long lists of sends are cut short like messages.
+func (m *redactMatches) previewOthers(cmd *cobra.Command, sent []db.SentRecord, scheduled []db.ScheduledRecord, responses []db.ResponseRecord) {
+	m.sent = make([]int64, len(sent))
+	for i, rec := range sent {
+		m.sent[i] = rec.ID
+		if i < bulkPreview {
+			cmd.Printf("  %s #%d (sent) %s\n", rec.SentAt.Local().Format(time.RFC3339), rec.ID, digestLine(db.MessageRecord{Title: rec.Title, Message: rec.Message}))
+		}
+	}
+	if more := len(sent) - bulkPreview; more > 0 {
+		cmd.Printf("  …and %d more sent\n", more)
+	}
+	for _, rec := range scheduled {
+		m.scheduled = append(m.scheduled, rec.ID)
+		cmd.Printf("  %s #%d (scheduled) %s\n", rec.SendAt.Local().Format(time.RFC3339), rec.ID, digestLine(db.MessageRecord{Title: rec.Title, Message: rec.Message}))
+	}
+	for _, rec := range responses {
+		m.responses = append(m.responses, rec.ID)
+		cmd.Printf("  %s #%d (reply link) %s\n", rec.CreatedAt.Local().Format(time.RFC3339), rec.ID, digestLine(db.MessageRecord{Title: rec.Title, Message: rec.Message}))
+	}
+}
+
+// matchScheduledAndResponses returns the scheduled sends and reply links
+// whose bodies match pattern. Neither records an app, so --app doesn't narrow
+// them. A matching send that's still pending is an error, since blanking it
+// would change what goes out.
+func matchScheduledAndResponses(cmd *cobra.Command, store *db.Store, pattern string, until *time.Time) ([]db.ScheduledRecord, []db.ResponseRecord, error) {
+	ctx := cmd.Context()
+	scheduled, err := store.MatchScheduled(ctx, pattern, until)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, rec := range scheduled {
+		if rec.Status == db.ScheduledPending {
+			return nil, nil, fmt.Errorf("scheduled send #%d matches and hasn't gone out yet; cancel it with 'push scheduled cancel %d' or redact after it's sent", rec.ID, rec.ID)
+		}
+	}
+	responses, err := store.ListResponses(ctx, db.ResponseFilter{BodyRegex: pattern, Until: until})
+	if err != nil {
+		return nil, nil, err
+	}
+	return scheduled, responses, nil
+}
+
+func applyRedaction(cmd *cobra.Command, store *db.Store, pattern string, matches redactMatches) error {
+	ctx := cmd.Context()
+	redacted, err := store.RedactMessages(ctx, matches.messages, pattern)
+	if err != nil {
+		return err
+	}
+	redactedSent, err := store.RedactSent(ctx, matches.sent)
+	if err != nil {
+		return err
+	}
+	redactedScheduled, err := store.RedactScheduled(ctx, matches.scheduled)
+	if err != nil {
+		return err
+	}
+	redactedResponses, err := store.RedactResponses(ctx, matches.responses)
+	if err != nil {
+		return err
+	}
+	already := int64(matches.total()) - redacted - redactedSent - redactedScheduled - redactedResponses
+	cmd.Printf("Redacted %d message(s), %d sent notification(s), %d scheduled send(s), and %d reply link(s); %d already were.\n",
+		redacted, redactedSent, redactedScheduled, redactedResponses, already)
+	return nil
+}
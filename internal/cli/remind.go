@@ -0,0 +1,98 @@
+// ABOUTME: Remind command that schedules a notification from a natural sentence.
+// ABOUTME: Pulls the time phrase out of the text and leaves the daemon to send it.
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/remind"
+	"github.com/spf13/cobra"
+)
+
+func newRemindCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "remind <text>",
+		Short:       "Schedule a reminder from plain words, e.g. push remind \"call dentist tomorrow at 3pm\"",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Schedule a notification by saying what and when in one sentence. The time phrase (\"in 20 minutes\", \"tomorrow at 3pm\", \"friday morning\", \"nov 2 at 9:30\") is taken out of the text, the rest becomes the message, and the time it was read as is printed so you can check it. 'push daemon' sends the reminder; list and cancel pending ones with 'push scheduled'.",
+		Args:        cobra.MinimumNArgs(1),
+		RunE:        runRemind,
+	}
+	cmd.Flags().String("at", "", "when to send, instead of finding it in the text (e.g. \"tomorrow 9am\" or \"2026-11-02 15:00\")")
+	cmd.Flags().IntP("priority", "p", 0, "priority of the notification (-2 to 2)")
+	cmd.Flags().StringP("device", "d", "", "target device name")
+	cmd.Flags().Bool("dry-run", false, "show how the text was read without scheduling it")
+	_ = cmd.RegisterFlagCompletionFunc("device", completeCatalog(db.CatalogDevices))
+	return cmd
+}
+
+func runRemind(cmd *cobra.Command, args []string) error {
+	priority, _ := cmd.Flags().GetInt("priority")
+	if priority < -2 || priority > 2 {
+		return fmt.Errorf("priority must be between -2 and 2")
+	}
+	device, _ := cmd.Flags().GetString("device")
+
+	now := time.Now()
+	text := strings.Join(args, " ")
+	var r remind.Reminder
+	if when, _ := cmd.Flags().GetString("at"); when != "" {
+		at, err := remind.ParseTime(when, now)
+		if err != nil {
+			return fmt.Errorf("--at: %w", err)
+		}
+		r = remind.Reminder{Message: strings.TrimSpace(text), At: at, Phrase: when}
+	} else {
+		var err error
+		if r, err = remind.Parse(text, now); err != nil {
+			return err
+		}
+	}
+
+	when := fmt.Sprintf("%s (in %s)", remind.Describe(r.At, now), roughDuration(r.At.Sub(now)))
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		cmd.Printf("Would remind %q %s, reading %q as the time.\n", r.Message, when, r.Phrase)
+		return nil
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	id, err := store.ScheduleSend(cmd.Context(), db.ScheduledRecord{
+		Title:    "Reminder",
+		Message:  r.Message,
+		Priority: priority,
+		Device:   device,
+		SendAt:   r.At,
+		Kind:     db.ScheduledReminder,
+	})
+	if err != nil {
+		return err
+	}
+	cmd.Printf("✓ Reminder #%d: %q %s.\n", id, r.Message, when)
+	noteDaemonStopped(cmd, "reminder")
+	return nil
+}
+
+// roughDuration renders d to the minute, or to the hour past a day, e.g.
+// "2h", "23h43m", or "2d17h".
+func roughDuration(d time.Duration) string {
+	if d >= 24*time.Hour {
+		d = d.Round(time.Hour)
+		return fmt.Sprintf("%dd%dh", d/(24*time.Hour), d%(24*time.Hour)/time.Hour)
+	}
+	s := strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	if s == "" {
+		return "under a minute"
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+// ABOUTME: Resend command that sends a notification from the sent log again.
+// ABOUTME: Reuses its message, title, priority, device, and target, optionally edited first.
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+func newResendCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "resend <sent-id>",
+		Short:       "Send a notification from 'push history --sent' again",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Send a logged notification again, to the same device, recipient, app, backend, and thread, with the same title, message, and priority. IDs are shown as #N by 'push history --sent'. With --edit, the compose prompts open filled in with the old values so you can change any of them before sending.",
+		Args:        cobra.ExactArgs(1),
+		RunE:        runResend,
+	}
+	cmd.Flags().Bool("edit", false, "change the notification in the compose prompts before sending")
+	return cmd
+}
+
+func runResend(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "#"), 10, 64)
+	if err != nil || id <= 0 {
+		return fmt.Errorf("invalid sent id %q", args[0])
+	}
+
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	rec, found, err := store.SentByID(cmd.Context(), id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no sent notification #%d; see 'push history --sent'", id)
+	}
+	if cfg, err = cfg.ForApp(rec.App); err != nil {
+		return err
+	}
+	if err := cfg.ValidateSend(); err != nil {
+		return err
+	}
+
+	// The logged title already went through title_template, so it isn't
+	// applied again, and without a dedupe window a deliberate resend isn't
+	// suppressed as a duplicate.
+	sendOpts := sendOptions{via: rec.Via, noPrefix: true, app: rec.App, thread: rec.Thread}
+	if sendOpts.recipients, err = cfg.ResolveRecipients(rec.Recipient); err != nil {
+		return err
+	}
+	params := pushover.SendParams{
+		Message:  rec.Message,
+		Title:    rec.Title,
+		Device:   rec.Device,
+		Priority: rec.Priority,
+		Sound:    cfg.Send.DefaultSound,
+	}
+
+	if edit, _ := cmd.Flags().GetBool("edit"); edit {
+		prom := newPrompter(cmd.OutOrStdout())
+		if params, err = askComposeFields(cmd, prom, cfg, store, params); err != nil {
+			return err
+		}
+		return confirmComposeSend(cmd, prom, cfg, params, sendOpts)
+	}
+	if sendOpts.longMessages, err = cfg.LongMessagePolicy(); err != nil {
+		return err
+	}
+	return dispatchSend(cmd, cfg, params, sendOpts)
+}
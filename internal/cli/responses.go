@@ -0,0 +1,95 @@
+// ABOUTME: Responses command for reply links sent with push send --reply or --choices.
+// ABOUTME: Lists whether each link was opened, acknowledged, or answered.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var responseStatuses = []string{db.ResponseWaiting, db.ResponseOpened, db.ResponseAcknowledged, db.ResponseAnswered}
+
+func newResponsesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "responses",
+		Short:       "Show what recipients did with reply links",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "List notifications sent with --reply or --choices and what happened to their reply links: waiting, opened, acknowledged, or answered with a choice. 'push serve' records these as links are tapped.",
+		Args:        cobra.NoArgs,
+		RunE:        runResponses,
+	}
+
+	cmd.Flags().IntP("limit", "n", 20, "limit number of rows")
+	cmd.Flags().String("status", "", "only links in this state: waiting, opened, acknowledged, or answered")
+	cmd.Flags().String("since", "", "only links sent since this time (e.g. 2h, yesterday)")
+	cmd.Flags().Bool("json", false, "output JSON")
+	_ = cmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions(responseStatuses, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+func runResponses(cmd *cobra.Command, args []string) error {
+	var filter db.ResponseFilter
+	filter.Limit, _ = cmd.Flags().GetInt("limit")
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	}
+	filter.Status, _ = cmd.Flags().GetString("status")
+	if filter.Status != "" && !slices.Contains(responseStatuses, filter.Status) {
+		return fmt.Errorf("--status must be %s", strings.Join(responseStatuses, ", "))
+	}
+	if value, _ := cmd.Flags().GetString("since"); value != "" {
+		since, err := parseSince(value)
+		if err != nil {
+			return fmt.Errorf("parse --since: %w", err)
+		}
+		filter.Since = &since
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	records, err := store.ListResponses(cmd.Context(), filter)
+	if err != nil {
+		return err
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+	if len(records) == 0 {
+		cmd.Println("No reply links found.")
+		return nil
+	}
+	for _, rec := range records {
+		cmd.Printf("%s %s\n", rec.CreatedAt.Local().Format(time.RFC3339), sentLine(db.SentRecord{Message: rec.Message, Title: rec.Title}))
+		cmd.Printf("  %s\n", responseState(rec))
+	}
+	return nil
+}
+
+// responseState describes where a reply link stands.
+func responseState(rec db.ResponseRecord) string {
+	switch rec.Status {
+	case db.ResponseAnswered:
+		return fmt.Sprintf("answered %q %s", rec.Choice, rec.RespondedAt.Local().Format(time.RFC3339))
+	case db.ResponseAcknowledged:
+		return "acknowledged " + rec.RespondedAt.Local().Format(time.RFC3339)
+	case db.ResponseOpened:
+		return "opened " + rec.OpenedAt.Local().Format(time.RFC3339)
+	}
+	if len(rec.Choices) > 0 {
+		return "waiting for one of " + strings.Join(rec.Choices, ", ")
+	}
+	return "waiting"
+}
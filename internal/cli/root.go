@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/harper/push/internal/hints"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +15,14 @@ import (
 type appOptions struct {
 	configPath string
 	dataDir    string
+	debug      bool
+	debugFile  string
+	noColor    bool
+	noPager    bool
+	logLevel   string
+	logFormat  string
+	logFile    string
+	ephemeral  bool
 }
 
 var opts = appOptions{}
@@ -21,7 +30,15 @@ var opts = appOptions{}
 // Execute runs the Cobra root command.
 func Execute() error {
 	cmd := newRootCmd()
-	return cmd.Execute()
+	defer closeDebugLog()
+	defer closeLogging()
+	defer setupTracing()()
+	err := cmd.Execute()
+	if hint := hints.For(err); hint != "" {
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Hint: "+hint)
+	}
+	endCommandSpan(err)
+	return err
 }
 
 func newRootCmd() *cobra.Command {
@@ -31,18 +48,69 @@ func newRootCmd() *cobra.Command {
 		Long:  "Push sends, receives, and persists Pushover messages for both human and AI assistant workflows.",
 	}
 	cmd.SilenceUsage = true
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		startCommandSpan(cmd)
+		if err := setupLogging(cmd); err != nil {
+			return err
+		}
+		if err := checkEphemeral(cmd); err != nil {
+			return err
+		}
+		return setupDebugLog(cmd, args)
+	}
 
 	cmd.PersistentFlags().StringVar(&opts.configPath, "config", "", "config file (default ~/.config/push/config.toml)")
 	cmd.PersistentFlags().StringVar(&opts.dataDir, "data", "", "data directory (default ~/.local/share/push)")
+	cmd.PersistentFlags().BoolVar(&opts.debug, "debug", false, "log Pushover API requests and responses (credentials redacted) to stderr")
+	cmd.PersistentFlags().StringVar(&opts.debugFile, "debug-file", "", "write debug request logs to this file instead of stderr")
+	cmd.PersistentFlags().BoolVar(&opts.ephemeral, "ephemeral", false, "keep history in memory only and write nothing to the data directory")
+	cmd.PersistentFlags().BoolVar(&opts.noColor, "no-color", false, "disable colored output (also honours NO_COLOR)")
+	cmd.PersistentFlags().BoolVar(&opts.noPager, "no-pager", false, "never pipe long output through $PAGER")
+	cmd.PersistentFlags().StringVar(&opts.logLevel, "log-level", "info", "lowest log level shown: debug, info, warn, or error")
+	cmd.PersistentFlags().StringVar(&opts.logFormat, "log-format", "text", "log output format: text or json")
+	cmd.PersistentFlags().StringVar(&opts.logFile, "log-file", "", "append logs to this file instead of stderr")
 
 	cmd.AddCommand(
 		newLoginCmd(),
 		newLogoutCmd(),
 		newSendCmd(),
+		newComposeCmd(),
+		newResendCmd(),
+		newAliasCmd(),
+		newScheduledCmd(),
 		newMessagesCmd(),
+		newAckCmd(),
+		newWatchCmd(),
+		newSnoozeCmd(),
+		newMuteCmd(),
 		newHistoryCmd(),
+		newOpenCmd(),
+		newArchiveCmd(),
+		newUnarchiveCmd(),
+		newReceiptsCmd(),
+		newResponsesCmd(),
+		newStatsCmd(),
+		newDigestCmd(),
+		newBackupCmd(),
+		newRestoreCmd(),
+		newImportCmd(),
+		newRedactCmd(),
+		newDBCmd(),
+		newHeartbeatCmd(),
+		newMonitorCmd(),
+		newFSWatchCmd(),
+		newSysmonCmd(),
+		newTimerCmd(),
+		newRemindCmd(),
+		newCalendarCmd(),
+		newFeedCmd(),
+		newDaemonCmd(),
+		newSelfTestCmd(),
 		newConfigCmd(),
 		newMCPCmd(),
+		newServeCmd(),
+		newSMTPCmd(),
+		newDocsCmd(),
 	)
 
 	return cmd
@@ -0,0 +1,206 @@
+// ABOUTME: Delayed sends with a cancellation window, and the scheduled command.
+// ABOUTME: Counts down before sending; Ctrl-C or `push scheduled cancel` aborts.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+// errSendCancelled reports a delayed send aborted before its countdown ended.
+var errSendCancelled = errors.New("send cancelled")
+
+// runDelayedSend waits out delay, showing a countdown, then sends unless the
+// user interrupts or the send is cancelled from another process.
+func runDelayedSend(cmd *cobra.Command, cfg *config.Config, params pushover.SendParams, sendOpts sendOptions, delay time.Duration) error {
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	deadline := time.Now().Add(delay)
+	id, err := store.ScheduleSend(cmd.Context(), db.ScheduledRecord{
+		Message:  params.Message,
+		Title:    params.Title,
+		Priority: params.Priority,
+		Device:   params.Device,
+		Via:      sendOpts.via,
+		SendAt:   deadline,
+		PID:      os.Getpid(),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := countdown(ctx, cmd, store, id, deadline); err != nil {
+		if errors.Is(err, context.Canceled) {
+			_, _ = store.FinishScheduled(cmd.Context(), id, db.ScheduledCancelled)
+			return errSendCancelled
+		}
+		return err
+	}
+
+	claimed, err := store.FinishScheduled(cmd.Context(), id, db.ScheduledSent)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return fmt.Errorf("%w by push scheduled cancel", errSendCancelled)
+	}
+	return dispatchSend(cmd, cfg, params, sendOpts)
+}
+
+// countdown ticks until deadline, returning early if ctx ends or the send
+// is cancelled elsewhere.
+func countdown(ctx context.Context, cmd *cobra.Command, store *db.Store, id int64, deadline time.Time) error {
+	out := cmd.ErrOrStderr()
+	live := isTerminal(out)
+	if !live {
+		_, _ = fmt.Fprintf(out, "Sending in %s (scheduled #%d; Ctrl-C or 'push scheduled cancel %d' to abort)\n",
+			time.Until(deadline).Round(time.Second), id, id)
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		remaining := time.Until(deadline)
+		if live {
+			_, _ = fmt.Fprintf(out, "\rSending in %s... Ctrl-C to cancel (scheduled #%d) ", remaining.Round(time.Second), id)
+		}
+		if remaining <= 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			if live {
+				_, _ = fmt.Fprintln(out)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		case <-time.After(remaining):
+		}
+
+		status, err := store.ScheduledStatus(ctx, id)
+		if err == nil && status != db.ScheduledPending {
+			break
+		}
+	}
+	if live {
+		_, _ = fmt.Fprintln(out)
+	}
+	return nil
+}
+
+func newScheduledCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "scheduled",
+		Short:       "List delayed sends and timers that haven't gone out yet",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		RunE:        runScheduledList,
+	}
+
+	cancelCmd := &cobra.Command{
+		Use:   "cancel [id...]",
+		Short: "Cancel pending delayed sends",
+		RunE:  runScheduledCancel,
+	}
+	cancelCmd.Flags().Bool("all", false, "cancel every pending send")
+	cmd.AddCommand(cancelCmd)
+
+	return cmd
+}
+
+func runScheduledList(cmd *cobra.Command, args []string) error {
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	pending, err := store.PendingScheduled(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		cmd.Println("No pending sends.")
+		return nil
+	}
+
+	now := time.Now()
+	for _, rec := range pending {
+		when := fmt.Sprintf("in %s", rec.SendAt.Sub(now).Round(time.Second))
+		switch {
+		case rec.Daemon() && rec.SendAt.Before(now):
+			when = "due now, waiting for push daemon"
+		case rec.Daemon():
+			when += " (" + rec.Kind + ")"
+		case rec.SendAt.Before(now):
+			// The countdown process would have sent or cancelled it by now.
+			when = fmt.Sprintf("overdue since %s, sender (pid %d) gone", rec.SendAt.Local().Format(time.RFC3339), rec.PID)
+		}
+		cmd.Printf("#%d %s [%d] %s\n", rec.ID, when, rec.Priority, rec.Message)
+	}
+	return nil
+}
+
+func runScheduledCancel(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+	if all == (len(args) > 0) {
+		return fmt.Errorf("give scheduled send IDs or --all")
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := cmd.Context()
+	var ids []int64
+	if all {
+		pending, err := store.PendingScheduled(ctx)
+		if err != nil {
+			return err
+		}
+		for _, rec := range pending {
+			ids = append(ids, rec.ID)
+		}
+	}
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid scheduled send ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	cancelled := 0
+	for _, id := range ids {
+		ok, err := store.FinishScheduled(ctx, id, db.ScheduledCancelled)
+		if err != nil {
+			return err
+		}
+		if ok {
+			cancelled++
+		} else {
+			logger.Warn("scheduled send is not pending", "id", id)
+		}
+	}
+	cmd.Printf("Cancelled %d send(s).\n", cancelled)
+	return nil
+}
@@ -0,0 +1,72 @@
+// ABOUTME: File-based secret inputs for unattended login.
+// ABOUTME: Reads key=value secret files and PUSH_LOGIN_PASSWORD_FILE.
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// passwordFileEnv names a file holding only the Pushover account password.
+const passwordFileEnv = "PUSH_LOGIN_PASSWORD_FILE"
+
+// secretFileKeys are the keys accepted in a --secret-file.
+var secretFileKeys = map[string]bool{
+	"app_token":  true,
+	"user_key":   true,
+	"email":      true,
+	"password":   true,
+	"two_factor": true,
+}
+
+// readSecretFile parses a key=value file. Blank lines and # comments are
+// skipped; values may be wrapped in single or double quotes.
+func readSecretFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read secret file: %w", err)
+	}
+
+	secrets := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !ok || !secretFileKeys[key] {
+			return nil, fmt.Errorf("secret file %s line %d: expected one of app_token, user_key, email, password, two_factor", path, line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		secrets[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read secret file: %w", err)
+	}
+	return secrets, nil
+}
+
+// readPasswordFile returns the contents of PUSH_LOGIN_PASSWORD_FILE, if set.
+func readPasswordFile() (string, bool, error) {
+	path := strings.TrimSpace(os.Getenv(passwordFileEnv))
+	if path == "" {
+		return "", false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("read %s: %w", passwordFileEnv, err)
+	}
+	password := strings.TrimRight(string(data), "\r\n")
+	if password == "" {
+		return "", false, fmt.Errorf("%s points to an empty file", passwordFileEnv)
+	}
+	return password, true, nil
+}
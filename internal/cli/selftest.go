@@ -0,0 +1,133 @@
+// ABOUTME: Self-test command that checks the whole send and receive loop.
+// ABOUTME: Sends a tagged message to this device and polls until it arrives.
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+func newSelfTestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Send a tagged test message and wait for it to arrive on this device",
+		Long:  "Send a quiet, uniquely tagged message to this device and, when the device is logged in, poll until it comes back, reporting send and round-trip latency. Other unread messages are left untouched; the test message is acknowledged only when it is the sole message waiting.",
+		Args:  cobra.NoArgs,
+		RunE:  runSelfTest,
+	}
+
+	cmd.Flags().Duration("timeout", time.Minute, "give up waiting for the test message after this long")
+	cmd.Flags().Duration("interval", 2*time.Second, "how often to poll for the test message")
+
+	return cmd
+}
+
+func runSelfTest(cmd *cobra.Command, args []string) error {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.ValidateSend(); err != nil {
+		return err
+	}
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if timeout <= 0 || interval <= 0 {
+		return fmt.Errorf("--timeout and --interval must be positive")
+	}
+
+	client, err := newClientFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	tag, err := selfTestTag()
+	if err != nil {
+		return err
+	}
+
+	params := pushover.SendParams{
+		Message:  "push self-test " + tag,
+		Title:    "push test",
+		Priority: -1,
+	}
+	if cfg.DeviceConfigured() {
+		params.Device = cfg.DeviceName
+	}
+	if err := acquireSendBudget(cmd, cfg); err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	start := time.Now()
+	resp, err := client.Send(ctx, params)
+	if err != nil {
+		return fmt.Errorf("send test message: %w", err)
+	}
+	sent := time.Since(start)
+	cmd.Printf("✓ Sent %s in %s (request %s)\n", tag, sent.Round(time.Millisecond), resp.Request)
+
+	if err := cfg.ValidateReceive(); err != nil {
+		cmd.Printf("Skipped the round trip: %v\n", err)
+		return nil
+	}
+
+	msg, others, err := waitForSelfTest(ctx, client, tag, timeout, interval)
+	if err != nil {
+		return err
+	}
+	cmd.Printf("✓ Received message %d after %s round trip\n", msg.PushoverID, time.Since(start).Round(time.Millisecond))
+
+	if others > 0 {
+		cmd.Printf("Left it unread alongside %d other message(s); 'push messages' will show them.\n", others)
+		return nil
+	}
+	if err := client.DeleteMessages(ctx, msg.PushoverID); err != nil {
+		logger.Warn("unable to acknowledge test message", "error", err)
+	}
+	return nil
+}
+
+// selfTestTag returns a random marker that identifies one test message.
+func selfTestTag() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate test tag: %w", err)
+	}
+	return "push-test-" + hex.EncodeToString(buf), nil
+}
+
+// waitForSelfTest polls until a message containing tag arrives, returning it
+// and how many other messages are waiting.
+func waitForSelfTest(ctx context.Context, client *pushover.Client, tag string, timeout, interval time.Duration) (pushover.ReceivedMessage, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := client.FetchMessages(ctx)
+		if err != nil && ctx.Err() == nil {
+			return pushover.ReceivedMessage{}, 0, fmt.Errorf("poll for test message: %w", err)
+		}
+		if result != nil {
+			for _, msg := range result.Messages {
+				if strings.Contains(msg.Message, tag) {
+					return msg, len(result.Messages) - 1, nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return pushover.ReceivedMessage{}, 0, fmt.Errorf("test message not received within %s; check that this device is enabled in your Pushover account", timeout)
+		case <-ticker.C:
+		}
+	}
+}
@@ -5,11 +5,17 @@ package cli
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/harper/push/internal/clipboard"
+	"github.com/harper/push/internal/config"
 	"github.com/harper/push/internal/db"
-	"github.com/harper/push/internal/pushover"
+	"github.com/harper/push/internal/messages"
+	"github.com/harper/push/internal/notify"
+	"github.com/harper/push/internal/reply"
+	"github.com/harper/push/pkg/pushover"
 	"github.com/spf13/cobra"
 )
 
@@ -17,7 +23,7 @@ func newSendCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "send [message]",
 		Short: "Send a Pushover notification",
-		Args:  cobra.MinimumNArgs(1),
+		Args:  sendArgs,
 		RunE:  runSend,
 	}
 
@@ -25,8 +31,34 @@ func newSendCmd() *cobra.Command {
 	cmd.Flags().IntP("priority", "p", 0, "priority (-2 to 2)")
 	cmd.Flags().StringP("url", "u", "", "supplementary URL")
 	cmd.Flags().String("url-title", "", "supplementary URL title")
-	cmd.Flags().StringP("sound", "s", "", "notification sound")
+	cmd.Flags().StringP("sound", "s", "", "notification sound (default from [send] default_sound)")
 	cmd.Flags().StringP("device", "d", "", "target device name")
+	cmd.Flags().String("user", "", "send to these comma-separated user or group keys or [recipients] names instead of user_key")
+	cmd.Flags().Duration("dedupe", 0, "suppress identical message+title sent within this window (e.g. 5m)")
+	cmd.Flags().String("via", "", "send backend: pushover, ntfy, gotify, or webhook (default from config)")
+	cmd.Flags().String("app", "", "send with the token of this [apps] entry instead of app_token")
+	cmd.Flags().Bool("clipboard", false, "send the clipboard contents, using the first link as the URL")
+	cmd.Flags().String("timestamp", "", "show this as the event time on devices (e.g. \"2h ago\", \"2025-01-02 15:04\")")
+	cmd.Flags().Duration("delay", 0, "count down this long before sending; Ctrl-C cancels (e.g. 30s)")
+	cmd.Flags().Duration("ttl", 0, "expire the notification from devices after this long (e.g. 1h)")
+	cmd.Flags().Bool("split", false, "send messages over 1024 characters as numbered parts")
+	cmd.Flags().Bool("truncate", false, "cut messages over 1024 characters short with an ellipsis")
+	cmd.Flags().Bool("no-prefix", false, "send the title as given, without title_template or [send] default_title_prefix")
+	cmd.Flags().String("batch", "", "send one notification per row of a CSV or JSONL file (- for stdin); row fields override flags")
+	cmd.Flags().Int("concurrency", 4, "with --batch, how many rows to send at once")
+	cmd.Flags().Bool("reply", false, "link to a page that records when the notification is opened and acknowledged (needs push serve)")
+	cmd.Flags().String("choices", "", "link to a page offering these comma-separated answers and record the one picked (needs push serve)")
+	cmd.Flags().String("thread", "", "group the notification with related ones under this key, shown by 'push history --thread'")
+	cmd.MarkFlagsMutuallyExclusive("split", "truncate")
+	cmd.MarkFlagsMutuallyExclusive("batch", "clipboard")
+	cmd.MarkFlagsMutuallyExclusive("batch", "delay")
+	cmd.MarkFlagsMutuallyExclusive("reply", "choices", "url")
+	cmd.MarkFlagsMutuallyExclusive("reply", "batch")
+	cmd.MarkFlagsMutuallyExclusive("choices", "batch")
+	_ = cmd.RegisterFlagCompletionFunc("sound", completeCatalog(db.CatalogSounds))
+	_ = cmd.RegisterFlagCompletionFunc("device", completeCatalog(db.CatalogDevices))
+	_ = cmd.RegisterFlagCompletionFunc("app", completeApps)
+	_ = cmd.RegisterFlagCompletionFunc("user", completeRecipients)
 
 	return cmd
 }
@@ -36,67 +68,481 @@ func runSend(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	if err := cfg.ValidateSend(); err != nil {
+	app, _ := cmd.Flags().GetString("app")
+	if cfg, err = cfg.ForApp(app); err != nil {
 		return err
 	}
 
+	params, err := sendParamsFromFlags(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	sendOpts, err := sendOptionsFromFlags(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	if batch, _ := cmd.Flags().GetString("batch"); batch != "" {
+		return runSendBatch(cmd, cfg, batch, params, sendOpts)
+	}
+
 	message := strings.TrimSpace(strings.Join(args, " "))
+	if useClipboard, _ := cmd.Flags().GetBool("clipboard"); useClipboard {
+		text, err := clipboard.Read(cmd.Context())
+		if err != nil {
+			return err
+		}
+		message = strings.TrimSpace(text)
+		if link, ok := messages.ExtractURL(message); ok && params.URL == "" && sendOpts.replyBase == "" {
+			params.URL = link
+		}
+	}
 	if message == "" {
 		return fmt.Errorf("message cannot be empty")
 	}
+	params.Message = message
 
+	if delay, _ := cmd.Flags().GetDuration("delay"); delay > 0 {
+		return runDelayedSend(cmd, cfg, params, sendOpts, delay)
+	}
+	return dispatchSend(cmd, cfg, params, sendOpts)
+}
+
+// sendParamsFromFlags reads everything about the notification but its
+// message from the send flags and [send] defaults.
+func sendParamsFromFlags(cmd *cobra.Command, cfg *config.Config) (pushover.SendParams, error) {
 	title, _ := cmd.Flags().GetString("title")
+	if noPrefix, _ := cmd.Flags().GetBool("no-prefix"); !noPrefix {
+		title = prefixTitle(cfg.Send.DefaultTitlePrefix, title)
+	}
 	priority, _ := cmd.Flags().GetInt("priority")
 	if priority < -2 || priority > 2 {
-		return fmt.Errorf("priority must be between -2 and 2")
+		return pushover.SendParams{}, fmt.Errorf("priority must be between -2 and 2")
 	}
-	urlVal, _ := cmd.Flags().GetString("url")
 	urlTitle, _ := cmd.Flags().GetString("url-title")
 	sound, _ := cmd.Flags().GetString("sound")
+	if !cmd.Flags().Changed("sound") {
+		sound = cfg.Send.DefaultSound
+	}
 	device, _ := cmd.Flags().GetString("device")
+	ttl, _ := cmd.Flags().GetDuration("ttl")
+	if ttl < 0 {
+		return pushover.SendParams{}, fmt.Errorf("--ttl must be positive")
+	}
+	var timestamp time.Time
+	if value, _ := cmd.Flags().GetString("timestamp"); value != "" {
+		var err error
+		if timestamp, err = parseSince(strings.TrimSuffix(strings.TrimSpace(value), " ago")); err != nil {
+			return pushover.SendParams{}, fmt.Errorf("parse --timestamp: %w", err)
+		}
+		if timestamp.After(time.Now()) {
+			return pushover.SendParams{}, fmt.Errorf("--timestamp cannot be in the future")
+		}
+	}
+	urlVal, _ := cmd.Flags().GetString("url")
 
-	client := newClientFromConfig(cfg)
-	ctx := cmd.Context()
-	params := pushover.SendParams{
-		Message:  message,
-		Title:    title,
-		Device:   device,
-		Priority: priority,
-		URL:      urlVal,
-		URLTitle: urlTitle,
-		Sound:    sound,
+	return pushover.SendParams{
+		Title:     title,
+		Device:    device,
+		Priority:  priority,
+		URL:       urlVal,
+		URLTitle:  urlTitle,
+		Sound:     sound,
+		TTL:       ttl,
+		Timestamp: timestamp,
+	}, nil
+}
+
+// sendOptionsFromFlags reads how to send from the send flags: the backend,
+// dedupe window, long message policy, recipients, and reply link.
+func sendOptionsFromFlags(cmd *cobra.Command, cfg *config.Config) (sendOptions, error) {
+	via, _ := cmd.Flags().GetString("via")
+	noPrefix, _ := cmd.Flags().GetBool("no-prefix")
+	app, _ := cmd.Flags().GetString("app")
+	thread, _ := cmd.Flags().GetString("thread")
+	opts := sendOptions{via: via, noPrefix: noPrefix, app: app, thread: strings.TrimSpace(thread)}
+
+	var err error
+	if opts.window, err = cfg.DedupeWindowDuration(); err != nil {
+		return sendOptions{}, err
+	}
+	if cmd.Flags().Changed("dedupe") {
+		opts.window, _ = cmd.Flags().GetDuration("dedupe")
 	}
+	if opts.longMessages, err = cfg.LongMessagePolicy(); err != nil {
+		return sendOptions{}, err
+	}
+	if split, _ := cmd.Flags().GetBool("split"); split {
+		opts.longMessages = config.LongMessageSplit
+	}
+	if truncate, _ := cmd.Flags().GetBool("truncate"); truncate {
+		opts.longMessages = config.LongMessageTruncate
+	}
+
+	if users, _ := cmd.Flags().GetString("user"); users != "" {
+		if opts.recipients, err = cfg.ResolveRecipients(users); err != nil {
+			return sendOptions{}, err
+		}
+		if backend := notify.Resolve(cfg, via); backend != notify.Pushover {
+			return sendOptions{}, fmt.Errorf("--user needs the pushover backend, not %s", backend)
+		}
+	}
+	if err := replyFromFlags(cmd, cfg, &opts); err != nil {
+		return sendOptions{}, err
+	}
+	return opts, nil
+}
 
-	resp, err := client.Send(ctx, params)
+// replyFromFlags sets up the reply link for --reply or --choices: the
+// choices offered and the push serve URL the link points at.
+func replyFromFlags(cmd *cobra.Command, cfg *config.Config, opts *sendOptions) error {
+	replyLink, _ := cmd.Flags().GetBool("reply")
+	if choices, _ := cmd.Flags().GetString("choices"); choices != "" {
+		var err error
+		if opts.choices, err = reply.ParseChoices(choices); err != nil {
+			return fmt.Errorf("--choices: %w", err)
+		}
+		replyLink = true
+	}
+	if !replyLink {
+		return nil
+	}
+	base, err := cfg.ReplyBaseURL()
 	if err != nil {
 		return err
 	}
+	opts.replyBase = base
+	return nil
+}
 
-	if err := logSentMessage(ctx, message, title, device, priority, resp.Request); err != nil {
-		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: unable to log sent message: %v\n", err)
+// prefixTitle applies [send] default_title_prefix, using the prefix alone as
+// the title when there is none.
+func prefixTitle(prefix, title string) string {
+	if title == "" {
+		return strings.TrimSpace(prefix)
+	}
+	return prefix + title
+}
+
+// sendOptions control how dispatchSend delivers a notification.
+type sendOptions struct {
+	via          string
+	window       time.Duration
+	longMessages string
+	// noPrefix skips title_template.
+	noPrefix bool
+	// recipients are the --user targets; none means the configured user_key.
+	recipients []config.Recipient
+	// recipient is the one target deliverSend sends to.
+	recipient config.Recipient
+	// app is the --app entry, recorded so receipts are checked with its token.
+	app string
+	// replyBase is [serve] public_url when the notification carries a reply
+	// link, offering choices when there are any.
+	replyBase string
+	choices   []string
+	// thread is the --thread key recorded with the send.
+	thread string
+}
+
+// targets returns one copy of sendOpts per recipient to send to.
+func (o sendOptions) targets() []sendOptions {
+	if len(o.recipients) == 0 {
+		return []sendOptions{o}
+	}
+	targets := make([]sendOptions, len(o.recipients))
+	for i, recipient := range o.recipients {
+		targets[i] = o
+		targets[i].recipient = recipient
+	}
+	return targets
+}
+
+// label names the recipient in progress output.
+func (o sendOptions) label() string {
+	if o.recipient.Name == "" {
+		return ""
+	}
+	return " to " + o.recipient.Name
+}
+
+// dispatchSend applies deduplication, the long message policy, and the send
+// budget, sends through the selected backend, and logs the result.
+func dispatchSend(cmd *cobra.Command, cfg *config.Config, params pushover.SendParams, sendOpts sendOptions) error {
+	client, err := newClientFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	notifier, err := notify.New(cfg, sendOpts.via, client)
+	if err != nil {
+		return err
 	}
 
-	cmd.Printf("✓ Notification sent. Request ID: %s\n", resp.Request)
-	if resp.Receipt != "" {
-		cmd.Printf("Receipt: %s\n", resp.Receipt)
+	targets := sendOpts.targets()
+	var failed []string
+	for _, target := range targets {
+		label := target.label()
+		lastSent, err := deliverSend(cmd, cfg, notifier, params, target, func(part, parts int, resp *pushover.SendResponse) {
+			if parts > 1 {
+				cmd.Printf("✓ Part %d/%d sent%s. Request ID: %s\n", part, parts, label, resp.Request)
+			} else {
+				cmd.Printf("✓ Notification sent%s. Request ID: %s\n", label, resp.Request)
+			}
+			if resp.Receipt != "" {
+				cmd.Printf("Receipt: %s\n", resp.Receipt)
+			}
+		})
+		if err != nil {
+			if len(targets) == 1 {
+				return err
+			}
+			cmd.Printf("✗ Not sent%s: %v\n", label, err)
+			failed = append(failed, target.recipient.Name)
+			continue
+		}
+		if !lastSent.IsZero() {
+			cmd.Printf("Duplicate%s suppressed (identical notification sent %s ago).\n", label, time.Since(lastSent).Round(time.Second))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("send failed for %d of %d recipients: %s", len(failed), len(targets), strings.Join(failed, ", "))
 	}
 	return nil
 }
 
-func logSentMessage(ctx context.Context, message, title, device string, priority int, requestID string) error {
+// deliverSend does the work of dispatchSend for one recipient through
+// notifier, calling onPart after each part is sent. When the send is suppressed as a duplicate it
+// returns when the identical notification was last sent.
+func deliverSend(cmd *cobra.Command, cfg *config.Config, notifier notify.Notifier, params pushover.SendParams, sendOpts sendOptions, onPart func(part, parts int, resp *pushover.SendResponse)) (time.Time, error) {
+	var err error
+	if !sendOpts.noPrefix {
+		if params.Title, err = cfg.ApplyTitleTemplate(params.Title); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	ctx := cmd.Context()
+	message := params.Message
+	hash := messages.RecipientContentHash(message, params.Title, sendOpts.recipient.Name)
+	params.User = sendOpts.recipient.Key
+
+	record := db.SentRecord{
+		Message:     message,
+		Title:       params.Title,
+		Device:      params.Device,
+		Priority:    params.Priority,
+		ContentHash: hash,
+		Via:         notify.Resolve(cfg, sendOpts.via),
+		Recipient:   sendOpts.recipient.Name,
+		App:         sendOpts.app,
+		Thread:      sendOpts.thread,
+	}
+
+	if sendOpts.window > 0 {
+		if lastSent, suppressed := suppressDuplicateSend(ctx, &record, &params, sendOpts.window); suppressed {
+			return lastSent, nil
+		}
+	}
+
+	parts, err := messages.FitMessage(params.Message, sendOpts.longMessages)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w; send with --split or --truncate, or set long_message_mode", err)
+	}
+
+	replyToken, err := addReplyLink(&params, sendOpts)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for i, part := range parts {
+		if err := acquireSendBudget(cmd, cfg); err != nil {
+			return time.Time{}, err
+		}
+
+		params.Message = part
+		resp, err := notifier.Send(ctx, params)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		record.Message = part
+		record.RequestID = resp.Request
+		record.Receipt = resp.Receipt
+		if resp.Receipt != "" {
+			record.ReceiptExpiresAt = emergencyExpiry(params)
+		}
+		if err := logSentMessage(ctx, record); err != nil {
+			logger.Warn("unable to log sent message", "error", err)
+		}
+		if replyToken != "" && i == 0 {
+			err := createReplyLink(ctx, db.ResponseRecord{
+				Token:     replyToken,
+				Message:   message,
+				Title:     params.Title,
+				Choices:   sendOpts.choices,
+				RequestID: resp.Request,
+			})
+			if err != nil {
+				logger.Warn("unable to record reply link; tapping it will show not found", "error", err)
+			}
+		}
+		onPart(i+1, len(parts), resp)
+	}
+	return time.Time{}, nil
+}
+
+// suppressDuplicateSend logs record as suppressed when an identical
+// notification went out within window, returning when it did. Otherwise it
+// notes in params how many repeats were suppressed since. A failed check
+// lets the send go ahead.
+func suppressDuplicateSend(ctx context.Context, record *db.SentRecord, params *pushover.SendParams, window time.Duration) (time.Time, bool) {
+	dup, err := checkDuplicateSend(ctx, record.ContentHash, window)
+	switch {
+	case err != nil:
+		logger.Warn("unable to check for duplicates", "error", err)
+	case dup.Duplicate:
+		record.Suppressed = true
+		if err := logSentMessage(ctx, *record); err != nil {
+			logger.Warn("unable to log sent message", "error", err)
+		}
+		return dup.LastSent, true
+	default:
+		params.Message = messages.AnnotateRepeats(record.Message, dup.Repeats)
+	}
+	return time.Time{}, false
+}
+
+// addReplyLink points the notification's URL at a new reply link when
+// --reply or --choices asked for one, using [serve] public_url as the base,
+// and returns its token; otherwise it returns "".
+func addReplyLink(params *pushover.SendParams, sendOpts sendOptions) (string, error) {
+	if sendOpts.replyBase == "" {
+		return "", nil
+	}
+	token, err := reply.NewToken()
+	if err != nil {
+		return "", err
+	}
+	params.URL = reply.Link(sendOpts.replyBase, token)
+	if params.URLTitle == "" {
+		params.URLTitle = "Acknowledge"
+		if len(sendOpts.choices) > 0 {
+			params.URLTitle = "Reply"
+		}
+	}
+	return token, nil
+}
+
+// emergencyExpiry is when an emergency message sent now stops repeating.
+func emergencyExpiry(params pushover.SendParams) time.Time {
+	expire := params.Expire
+	if expire <= 0 {
+		expire = pushover.DefaultEmergencyExpire
+	}
+	return time.Now().Add(expire)
+}
+
+// completeApps offers the configured [apps] names for --app.
+func completeApps(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for name := range cfg.Apps {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRecipients offers [recipients] names for the last entry of --user.
+func completeRecipients(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	done, last := "", toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		done, last = toComplete[:i+1], toComplete[i+1:]
+	}
+	var names []string
+	for _, name := range cfg.RecipientNames() {
+		if strings.HasPrefix(name, last) {
+			names = append(names, done+name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// sendArgs requires a message argument unless it comes from the clipboard
+// or a batch file.
+func sendArgs(cmd *cobra.Command, args []string) error {
+	if batch, _ := cmd.Flags().GetString("batch"); batch != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("--batch cannot be combined with a message argument")
+		}
+		return nil
+	}
+	if useClipboard, _ := cmd.Flags().GetBool("clipboard"); useClipboard {
+		if len(args) > 0 {
+			return fmt.Errorf("--clipboard cannot be combined with a message argument")
+		}
+		return nil
+	}
+	return cobra.MinimumNArgs(1)(cmd, args)
+}
+
+func logSentMessage(ctx context.Context, rec db.SentRecord) error {
 	store, _, err := openStore()
 	if err != nil {
 		return err
 	}
 	defer func() { _ = store.Close() }()
 
-	rec := db.SentRecord{
-		Message:   message,
-		Title:     title,
-		Device:    device,
-		Priority:  priority,
-		RequestID: requestID,
-		SentAt:    time.Now(),
-	}
+	rec.SentAt = time.Now()
 	return store.LogSent(ctx, rec)
 }
+
+// createReplyLink records a reply link so push serve can answer it.
+func createReplyLink(ctx context.Context, rec db.ResponseRecord) error {
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	return store.CreateResponse(ctx, rec)
+}
+
+// acquireSendBudget enforces rate_limit_per_minute across concurrent push processes.
+func acquireSendBudget(cmd *cobra.Command, cfg *config.Config) error {
+	if cfg.RateLimit <= 0 {
+		return nil
+	}
+	wait, err := cfg.RateLimitWaits()
+	if err != nil {
+		return err
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	return messages.AcquireSendSlot(cmd.Context(), store, cfg.RateLimit, wait, func(retry time.Duration) {
+		logger.Info("send budget reached, waiting", "limit_per_minute", cfg.RateLimit, "retry_in", retry.Round(time.Second))
+	})
+}
+
+func checkDuplicateSend(ctx context.Context, hash string, window time.Duration) (messages.DedupeResult, error) {
+	store, _, err := openStore()
+	if err != nil {
+		return messages.DedupeResult{}, err
+	}
+	defer func() { _ = store.Close() }()
+
+	return messages.CheckDuplicate(ctx, store, hash, window, time.Now())
+}
@@ -0,0 +1,181 @@
+// ABOUTME: Bulk sends from a CSV or JSONL file via push send --batch.
+// ABOUTME: Sends rows concurrently, reports each row, and logs every row to the sent table.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/messages"
+	"github.com/harper/push/internal/notify"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+// runSendBatch sends one notification per row of the file at path, and per
+// --user recipient, filling fields a row leaves empty from defaults. Every
+// row is attempted; the command fails when any send did.
+func runSendBatch(cmd *cobra.Command, cfg *config.Config, path string, defaults pushover.SendParams, sendOpts sendOptions) error {
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	if concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	rows, err := readBatch(cmd, path)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClientFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	notifier, err := notify.New(cfg, sendOpts.via, client)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	var (
+		mu                       sync.Mutex // guards the counts and output
+		sent, suppressed, failed int
+		wg                       sync.WaitGroup
+		slots                    = make(chan struct{}, concurrency)
+	)
+
+	targets := sendOpts.targets()
+	total := len(rows) * len(targets)
+jobs:
+	for _, row := range rows {
+		for _, target := range targets {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				break jobs
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+
+				params := batchParams(cfg, row, defaults, target.noPrefix)
+				var requests []string
+				lastSent, err := deliverSend(cmd, cfg, notifier, params, target, func(_, _ int, resp *pushover.SendResponse) {
+					requests = append(requests, resp.Request)
+				})
+				if err != nil {
+					logBatchFailure(cmd, cfg, params, target, err)
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				label := target.label()
+				switch {
+				case err != nil:
+					failed++
+					cmd.Printf("✗ Line %d%s: %v\n", row.Line, label, err)
+				case !lastSent.IsZero():
+					suppressed++
+					cmd.Printf("- Line %d%s: duplicate suppressed\n", row.Line, label)
+				default:
+					sent++
+					cmd.Printf("✓ Line %d%s: sent. Request ID: %s\n", row.Line, label, strings.Join(requests, ", "))
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	noun := "rows"
+	if len(targets) > 1 {
+		noun = "notifications"
+	}
+	skipped := total - sent - suppressed - failed
+	cmd.Printf("Sent %d of %d %s", sent, total, noun)
+	if suppressed > 0 {
+		cmd.Printf(", %d suppressed as duplicates", suppressed)
+	}
+	if failed > 0 {
+		cmd.Printf(", %d failed", failed)
+	}
+	if skipped > 0 {
+		cmd.Printf(", %d not attempted", skipped)
+	}
+	cmd.Println(".")
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d batch %s failed", failed, total, noun)
+	}
+	return nil
+}
+
+// readBatch parses the batch file at path, or stdin for "-".
+func readBatch(cmd *cobra.Command, path string) ([]messages.BatchRow, error) {
+	var r io.Reader = cmd.InOrStdin()
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open batch file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		r = file
+	}
+	rows, err := messages.ParseBatch(r, messages.BatchFormatFor(path))
+	if err != nil {
+		return nil, fmt.Errorf("parse batch file: %w", err)
+	}
+	return rows, nil
+}
+
+// batchParams overlays a row's fields on the flag defaults.
+func batchParams(cfg *config.Config, row messages.BatchRow, defaults pushover.SendParams, noPrefix bool) pushover.SendParams {
+	params := defaults
+	params.Message = row.Message
+	if row.Title != "" {
+		params.Title = row.Title
+		if !noPrefix {
+			params.Title = prefixTitle(cfg.Send.DefaultTitlePrefix, row.Title)
+		}
+	}
+	if row.Priority != nil {
+		params.Priority = *row.Priority
+	}
+	if row.Device != "" {
+		params.Device = row.Device
+	}
+	if row.URL != "" {
+		params.URL = row.URL
+	}
+	if row.URLTitle != "" {
+		params.URLTitle = row.URLTitle
+	}
+	if row.Sound != "" {
+		params.Sound = row.Sound
+	}
+	return params
+}
+
+// logBatchFailure records a row that couldn't be sent so the sent table
+// accounts for the whole batch.
+func logBatchFailure(cmd *cobra.Command, cfg *config.Config, params pushover.SendParams, sendOpts sendOptions, sendErr error) {
+	record := db.SentRecord{
+		Message:     params.Message,
+		Title:       params.Title,
+		Device:      params.Device,
+		Priority:    params.Priority,
+		ContentHash: messages.RecipientContentHash(params.Message, params.Title, sendOpts.recipient.Name),
+		Via:         notify.Resolve(cfg, sendOpts.via),
+		Recipient:   sendOpts.recipient.Name,
+		Error:       sendErr.Error(),
+	}
+	if err := logSentMessage(cmd.Context(), record); err != nil {
+		logger.Warn("unable to log sent message", "error", err)
+	}
+}
@@ -0,0 +1,147 @@
+// ABOUTME: Serve command for reply links and incoming alert webhooks.
+// ABOUTME: Records reply link taps and forwards Alertmanager and Grafana alerts as notifications.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/harper/push/internal/alerts"
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/notify"
+	"github.com/harper/push/internal/reply"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "serve",
+		Annotations: map[string]string{serverAnnotation: "true"},
+		Short:       "Serve reply links and alert webhooks",
+		Long:        "Serve the page that reply links open. Tapping a notification sent with --reply or --choices opens it, and opening, acknowledging, or picking a choice is recorded for 'push responses'. Phones must reach this server at [serve] public_url, e.g. through a reverse proxy or tunnel; the page has no authentication beyond each link's unguessable token.\n\nWith [serve] hook_token set, Alertmanager and Grafana can also POST alerts to /hooks/alertmanager and /hooks/grafana, passing the token as a bearer token, and each alert group is sent as a notification.",
+		Args:        cobra.NoArgs,
+		RunE:        runServe,
+	}
+	cmd.Flags().String("listen", "", "address to listen on (default from [serve] listen, or 127.0.0.1:8766)")
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	addr := cfg.ServeListen()
+	if listen, _ := cmd.Flags().GetString("listen"); listen != "" {
+		addr = listen
+	}
+	publicURL, err := cfg.ReplyBaseURL()
+	switch {
+	case cfg.Serve.PublicURL == "":
+		logger.Info("reply links are off; set [serve] public_url to use push send --reply and --choices")
+	case err != nil:
+		logger.Warn("push send --reply and --choices will fail until this is fixed", "error", err)
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	mux := http.NewServeMux()
+	mux.Handle("/", reply.Handler(store, logger))
+	if cfg.Serve.HookToken == "" {
+		logger.Info("alert webhooks are off; set [serve] hook_token to enable /hooks/alertmanager and /hooks/grafana")
+	} else {
+		hooks, err := alertHooks(cmd, cfg)
+		if err != nil {
+			return err
+		}
+		mux.Handle("/hooks/", hooks)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("serving reply links", "listen", "http://"+addr, "public_url", publicURL, "alert_webhooks", cfg.Serve.HookToken != "")
+	return serveHTTP(ctx, addr, mux)
+}
+
+// alertHooks returns the alert webhook endpoints, sending through the
+// configured backend.
+func alertHooks(cmd *cobra.Command, cfg *config.Config) (http.Handler, error) {
+	if notify.Resolve(cfg, "") == notify.Pushover {
+		if err := cfg.ValidateSend(); err != nil {
+			return nil, err
+		}
+	}
+	priorities, err := cfg.AlertPriorities()
+	if err != nil {
+		return nil, err
+	}
+	window, err := cfg.DedupeWindowDuration()
+	if err != nil {
+		return nil, err
+	}
+	// Long alert groups are cut short rather than refused.
+	longMessages, err := cfg.LongMessagePolicy()
+	if err != nil {
+		return nil, err
+	}
+	if longMessages == config.LongMessageError {
+		longMessages = config.LongMessageTruncate
+	}
+	sendOpts := sendOptions{window: window, longMessages: longMessages}
+
+	client, err := newClientFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	notifier, err := notify.New(cfg, "", client)
+	if err != nil {
+		return nil, err
+	}
+
+	send := func(_ context.Context, n alerts.Notification) error {
+		params := pushover.SendParams{
+			Title:    n.Title,
+			Message:  n.Message,
+			Priority: n.Priority,
+			URL:      n.URL,
+			URLTitle: n.URLTitle,
+		}
+		_, err := deliverSend(cmd, cfg, notifier, params, sendOpts, func(int, int, *pushover.SendResponse) {})
+		return err
+	}
+	return alerts.Handler(cfg.Serve.HookToken, priorities, send, logger), nil
+}
+
+// serveHTTP serves handler at addr until ctx is cancelled.
+func serveHTTP(ctx context.Context, addr string, handler http.Handler) error {
+	httpServer := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", addr, err)
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve http: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// ABOUTME: SMTP command that turns mail into push notifications.
+// ABOUTME: Lets appliances that can only send email alert through Pushover.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/notify"
+	"github.com/harper/push/internal/smtpd"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+// maxMailTitle is Pushover's limit on titles; longer subjects are cut.
+const maxMailTitle = 250
+
+func newSMTPCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "smtp",
+		Annotations: map[string]string{serverAnnotation: "true"},
+		Short:       "Accept mail over SMTP and send each message as a notification",
+		Long:        "Run a minimal SMTP server for devices that can only alert by email. Each mail becomes a notification with the subject as its title and the text body as its message. Mail from senders outside [smtp] allowed_senders (or --allow) and mail over the size limit is refused. There is no TLS or authentication, so keep it on a trusted network.",
+		Args:        cobra.NoArgs,
+		RunE:        runSMTP,
+	}
+	cmd.Flags().String("listen", "", "address to listen on (default from [smtp] listen, or 127.0.0.1:2525)")
+	cmd.Flags().StringSlice("allow", nil, "accept mail only from these senders, as addresses or @domain (default from [smtp] allowed_senders)")
+	cmd.Flags().Int64("max-size", 0, "refuse mail over this many bytes (default from [smtp] max_message_bytes, or 1 MiB)")
+	cmd.Flags().IntP("priority", "p", 0, "priority of the notifications (-2 to 2)")
+	cmd.Flags().StringP("device", "d", "", "target device name")
+	cmd.Flags().String("via", "", "send backend: pushover, ntfy, gotify, or webhook (default from config)")
+	_ = cmd.RegisterFlagCompletionFunc("device", completeCatalog(db.CatalogDevices))
+	return cmd
+}
+
+func runSMTP(cmd *cobra.Command, args []string) error {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	via, _ := cmd.Flags().GetString("via")
+	if notify.Resolve(cfg, via) == notify.Pushover {
+		if err := cfg.ValidateSend(); err != nil {
+			return err
+		}
+	}
+	priority, _ := cmd.Flags().GetInt("priority")
+	if priority < -2 || priority > 2 {
+		return fmt.Errorf("priority must be between -2 and 2")
+	}
+	device, _ := cmd.Flags().GetString("device")
+
+	addr := cfg.SMTPListen()
+	if listen, _ := cmd.Flags().GetString("listen"); listen != "" {
+		addr = listen
+	}
+	allowed := cfg.SMTP.AllowedSenders
+	if cmd.Flags().Changed("allow") {
+		allowed, _ = cmd.Flags().GetStringSlice("allow")
+	}
+	maxBytes := cfg.SMTP.MaxMessageBytes
+	if cmd.Flags().Changed("max-size") {
+		maxBytes, _ = cmd.Flags().GetInt64("max-size")
+	}
+	if maxBytes < 0 {
+		return fmt.Errorf("--max-size must be positive")
+	}
+	if maxBytes == 0 {
+		maxBytes = smtpd.DefaultMaxBytes
+	}
+
+	window, err := cfg.DedupeWindowDuration()
+	if err != nil {
+		return err
+	}
+	// Mail bodies are often long; cut them short rather than refusing them
+	// unless splitting was asked for.
+	longMessages, err := cfg.LongMessagePolicy()
+	if err != nil {
+		return err
+	}
+	if longMessages == config.LongMessageError {
+		longMessages = config.LongMessageTruncate
+	}
+	sendOpts := sendOptions{via: via, window: window, longMessages: longMessages}
+
+	client, err := newClientFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	notifier, err := notify.New(cfg, via, client)
+	if err != nil {
+		return err
+	}
+
+	server := &smtpd.Server{
+		MaxBytes: maxBytes,
+		Allowed:  allowed,
+		Log:      logger,
+		Deliver: func(_ context.Context, env smtpd.Envelope) error {
+			mail, err := smtpd.Parse(env.Data)
+			if err != nil {
+				// Mail that doesn't parse never will; don't have it retried.
+				return &smtpd.PermanentError{Err: err}
+			}
+			params := pushover.SendParams{
+				Title:    truncateRunes(mail.Subject, maxMailTitle),
+				Message:  mail.Body,
+				Priority: priority,
+				Device:   device,
+			}
+			if params.Message == "" {
+				params.Title, params.Message = "", mail.Subject
+			}
+			if params.Message == "" {
+				params.Message = "Empty mail from " + env.From
+			}
+			_, err = deliverSend(cmd, cfg, notifier, params, sendOpts, func(_, _ int, resp *pushover.SendResponse) {
+				logger.Info("sent mail as a notification", "from", env.From, "subject", mail.Subject, "request", resp.Request)
+			})
+			return err
+		},
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		server.Hostname = hostname
+	}
+	if len(allowed) == 0 {
+		logger.Warn("no allowed senders configured; accepting mail from anyone who can connect", "listen", addr)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("accepting mail", "listen", addr, "allowed_senders", allowed, "max_bytes", server.MaxBytes)
+	return server.ListenAndServe(ctx, addr)
+}
+
+// truncateRunes cuts s to at most n characters.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
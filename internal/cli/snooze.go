@@ -0,0 +1,105 @@
+// ABOUTME: Snooze command that pauses message hooks for a while.
+// ABOUTME: Running watchers read the flag from the database on every poll.
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newSnoozeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "snooze [duration]",
+		Short:       "Pause 'push watch' hooks for a while (e.g. 1h, 2d)",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Suppress --exec hooks in running 'push watch' processes until the duration passes. Messages are still polled, saved, and acknowledged, and the device stays logged in. Without a duration, show the current snooze.",
+		Args:        cobra.MaximumNArgs(1),
+		RunE:        runSnooze,
+	}
+
+	cmd.AddCommand(newSnoozeStatusCmd(), newSnoozeCancelCmd())
+
+	return cmd
+}
+
+func runSnooze(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return showSnooze(cmd)
+	}
+	span, ok := parseSpan(args[0])
+	if !ok || span <= 0 {
+		return fmt.Errorf("invalid duration %q (use e.g. 30m, 1h, 2d)", args[0])
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	until := time.Now().Add(span)
+	if err := store.SetSnooze(cmd.Context(), until); err != nil {
+		return err
+	}
+	cmd.Printf("✓ Hooks snoozed until %s.\n", until.Local().Format(time.RFC3339))
+	return nil
+}
+
+func newSnoozeStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show whether hooks are snoozed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showSnooze(cmd)
+		},
+	}
+}
+
+func showSnooze(cmd *cobra.Command) error {
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now()
+	until, err := store.SnoozedUntil(cmd.Context(), now)
+	if err != nil {
+		return err
+	}
+	if until.IsZero() {
+		cmd.Println("Not snoozed.")
+		return nil
+	}
+	cmd.Printf("Snoozed until %s (%s left).\n", until.Local().Format(time.RFC3339), until.Sub(now).Round(time.Second))
+	return nil
+}
+
+func newSnoozeCancelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel",
+		Short: "End the current snooze early",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			active, err := store.ClearSnooze(cmd.Context(), time.Now())
+			if err != nil {
+				return err
+			}
+			if !active {
+				cmd.Println("Not snoozed.")
+				return nil
+			}
+			cmd.Println("✓ Snooze cancelled.")
+			return nil
+		},
+	}
+}
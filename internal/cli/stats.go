@@ -0,0 +1,184 @@
+// ABOUTME: Stats command for summarizing persisted message history.
+// ABOUTME: Renders per-day, per-app, per-priority, and hourly counts as bar charts.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/spf13/cobra"
+)
+
+const statsBarWidth = 30
+
+// statsReport bundles every aggregate shown by the stats command.
+type statsReport struct {
+	Since         time.Time        `json:"since"`
+	Total         int              `json:"total"`
+	PerDay        []db.CountBucket `json:"per_day"`
+	PerApp        []db.CountBucket `json:"per_app"`
+	PerPriority   []db.CountBucket `json:"per_priority"`
+	Hourly        []db.CountBucket `json:"hourly"`
+	TopSenders    []db.SenderStats `json:"top_senders"`
+	AvgFetchDelay string           `json:"avg_fetch_delay,omitempty"`
+}
+
+func newStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "stats",
+		Short:       "Show message statistics from persisted history",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		RunE:        runStats,
+	}
+
+	cmd.Flags().String("since", "30d", "window to summarize (e.g. 7d, 2w, yesterday)")
+	cmd.Flags().Int("top", 10, "number of top senders to list")
+	cmd.Flags().Bool("json", false, "output JSON")
+
+	return cmd
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	sinceStr, _ := cmd.Flags().GetString("since")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	top, _ := cmd.Flags().GetInt("top")
+
+	since, err := parseSince(sinceStr)
+	if err != nil {
+		return fmt.Errorf("parse --since: %w", err)
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := cmd.Context()
+	report := statsReport{Since: since}
+	if report.PerDay, err = store.CountByDay(ctx, &since); err != nil {
+		return err
+	}
+	if report.PerApp, err = store.CountByApp(ctx, &since); err != nil {
+		return err
+	}
+	if report.PerPriority, err = store.CountByPriority(ctx, &since); err != nil {
+		return err
+	}
+	if report.Hourly, err = store.HourlyHistogram(ctx, &since); err != nil {
+		return err
+	}
+	if report.TopSenders, err = store.TopSenders(ctx, &since, top); err != nil {
+		return err
+	}
+	delay, err := store.AverageFetchDelay(ctx, &since)
+	if err != nil {
+		return err
+	}
+	if delay > 0 {
+		report.AvgFetchDelay = delay.String()
+	}
+	for _, bucket := range report.PerDay {
+		report.Total += bucket.Count
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	writeStatsText(cmd, report)
+	return nil
+}
+
+func writeStatsText(cmd *cobra.Command, report statsReport) {
+	cmd.Printf("Messages since %s: %d\n", report.Since.Local().Format("2006-01-02 15:04"), report.Total)
+	if report.Total == 0 {
+		return
+	}
+
+	writeStatsSection(cmd, "Per day", report.PerDay, nil)
+	writeTopSenders(cmd, report.TopSenders)
+	writeStatsSection(cmd, "Per priority", report.PerPriority, priorityLabel)
+	writeStatsSection(cmd, "Busiest hours", report.Hourly, func(key string) string { return key + ":00" })
+
+	if report.AvgFetchDelay != "" {
+		cmd.Printf("\nAverage fetch delay: %s\n", report.AvgFetchDelay)
+	}
+}
+
+func writeStatsSection(cmd *cobra.Command, heading string, buckets []db.CountBucket, label func(string) string) {
+	cmd.Printf("\n%s\n", heading)
+
+	labels := make([]string, len(buckets))
+	width, highest := 0, 0
+	for i, bucket := range buckets {
+		labels[i] = bucket.Key
+		if label != nil {
+			labels[i] = label(bucket.Key)
+		}
+		width = max(width, len([]rune(labels[i])))
+		highest = max(highest, bucket.Count)
+	}
+
+	for i, bucket := range buckets {
+		pad := strings.Repeat(" ", width-len([]rune(labels[i])))
+		cmd.Printf("  %s%s  %s %d\n", labels[i], pad, renderBar(bucket.Count, highest, statsBarWidth), bucket.Count)
+	}
+}
+
+// writeTopSenders lists the busiest apps with how many of their messages were
+// high priority or above and when the latest arrived.
+func writeTopSenders(cmd *cobra.Command, senders []db.SenderStats) {
+	if len(senders) == 0 {
+		return
+	}
+	cmd.Printf("\nTop senders\n")
+
+	width, highest := 0, senders[0].Count
+	for _, sender := range senders {
+		width = max(width, len([]rune(sender.App)))
+	}
+	for _, sender := range senders {
+		pad := strings.Repeat(" ", width-len([]rune(sender.App)))
+		cmd.Printf("  %s%s  %s %d", sender.App, pad, renderBar(sender.Count, highest, statsBarWidth), sender.Count)
+		if sender.Urgent > 0 {
+			cmd.Printf(" (%d urgent)", sender.Urgent)
+		}
+		cmd.Printf(", last %s\n", sender.LastAt.Local().Format("2006-01-02 15:04"))
+	}
+}
+
+// renderBar draws a horizontal bar scaled against highest using eighth-block glyphs.
+func renderBar(count, highest, width int) string {
+	if count <= 0 || highest <= 0 || width <= 0 {
+		return ""
+	}
+	partials := []rune(" ▏▎▍▌▋▊▉")
+	eighths := count * width * 8 / highest
+	if eighths == 0 {
+		eighths = 1
+	}
+	bar := strings.Repeat("█", eighths/8)
+	if rem := eighths % 8; rem > 0 {
+		bar += string(partials[rem])
+	}
+	return bar
+}
+
+func priorityLabel(key string) string {
+	names := map[string]string{
+		"-2": "lowest",
+		"-1": "low",
+		"0":  "normal",
+		"1":  "high",
+		"2":  "emergency",
+	}
+	if name, ok := names[key]; ok {
+		return fmt.Sprintf("%s (%s)", key, name)
+	}
+	return key
+}
@@ -0,0 +1,149 @@
+// ABOUTME: Sysmon command for disk and load alerts on the local machine.
+// ABOUTME: Sets the thresholds the daemon checks and shows current readings.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/sysmon"
+	"github.com/spf13/cobra"
+)
+
+func newSysmonCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "sysmon",
+		Short:       "Alert when this machine's disk or load crosses a threshold, checked by 'push daemon'",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Set thresholds for 'push daemon' to check on this machine: how full a filesystem is and the five-minute load average. A notification goes out when a value reaches its threshold and another once it falls 5% below it, so a value hovering at the limit doesn't alert on every check. Without --disk or --load, shows each check's threshold and current reading.",
+		Args:        cobra.NoArgs,
+		RunE:        runSysmon,
+	}
+	cmd.Flags().String("disk", "", "alert when the filesystem at --path is this full, e.g. 90%")
+	cmd.Flags().String("path", "/", "filesystem the --disk threshold applies to")
+	cmd.Flags().Float64("load", 0, "alert when the five-minute load average reaches this")
+	cmd.Flags().Duration("interval", 5*time.Minute, "how often to check")
+	cmd.Flags().Bool("json", false, "output JSON")
+
+	cmd.AddCommand(newSysmonRemoveCmd())
+
+	return cmd
+}
+
+func runSysmon(cmd *cobra.Command, args []string) error {
+	var checks []db.SysmonRecord
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if disk, _ := cmd.Flags().GetString("disk"); disk != "" {
+		pct, err := sysmon.ParsePercent(disk)
+		if err != nil {
+			return fmt.Errorf("--disk: %w", err)
+		}
+		path, _ := cmd.Flags().GetString("path")
+		if path, err = filepath.Abs(path); err != nil {
+			return fmt.Errorf("resolve path: %w", err)
+		}
+		checks = append(checks, db.SysmonRecord{Name: sysmon.Name(sysmon.Disk, path), Metric: sysmon.Disk, Path: path, Threshold: pct})
+	}
+	if cmd.Flags().Changed("load") {
+		load, _ := cmd.Flags().GetFloat64("load")
+		if load <= 0 {
+			return fmt.Errorf("--load must be positive")
+		}
+		checks = append(checks, db.SysmonRecord{Name: sysmon.Name(sysmon.Load, ""), Metric: sysmon.Load, Threshold: load})
+	}
+	if len(checks) == 0 {
+		return runSysmonList(cmd)
+	}
+	if interval < 10*time.Second {
+		return fmt.Errorf("--interval must be at least 10s")
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	for _, check := range checks {
+		// Read once now so a path that can't be measured fails here rather
+		// than in the daemon.
+		value, err := sysmon.Read(cmd.Context(), check)
+		if err != nil {
+			return err
+		}
+		check.Every = interval
+		if err := store.SaveSysmon(cmd.Context(), check); err != nil {
+			return err
+		}
+		cmd.Printf("✓ Checking %s every %s: alert at %s (now %s).\n", check.Name, interval,
+			sysmon.Format(check.Metric, check.Threshold), sysmon.Format(check.Metric, value))
+	}
+	cmd.Println("Checks run while 'push daemon' is running.")
+	return nil
+}
+
+func runSysmonList(cmd *cobra.Command) error {
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	checks, err := store.ListSysmon(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(checks)
+	}
+	if len(checks) == 0 {
+		cmd.Println("No system checks. Add one with 'push sysmon --disk 90%' or 'push sysmon --load 8'.")
+		return nil
+	}
+	for _, check := range checks {
+		now := "unavailable"
+		if value, err := sysmon.Read(cmd.Context(), check); err == nil {
+			now = sysmon.Format(check.Metric, value)
+		}
+		state := "ok"
+		if check.State == db.SysmonHigh {
+			state = "HIGH"
+		}
+		cmd.Printf("%s [%s] alert at %s, now %s, every %s\n", check.Name, state,
+			sysmon.Format(check.Metric, check.Threshold), now, check.Every)
+		if !check.LastChange.IsZero() {
+			cmd.Printf("  last changed %s\n", check.LastChange.Local().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+func newSysmonRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Stop a system check, e.g. disk:/ or load",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			removed, err := store.DeleteSysmon(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("no system check named %q", args[0])
+			}
+			cmd.Printf("✓ System check %q removed.\n", args[0])
+			return nil
+		},
+	}
+}
@@ -0,0 +1,181 @@
+// ABOUTME: Timer command that pushes a notification once a duration has passed.
+// ABOUTME: Timers live in the scheduled table, so the daemon fires them even after a reboot.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/spf13/cobra"
+)
+
+func newTimerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "timer <duration> [label]",
+		Short:       "Get a notification when a timer runs out, e.g. push timer 25m \"Stand up\"",
+		Annotations: map[string]string{storedAnnotation: "true"},
+		Long:        "Start a timer that 'push daemon' fires when it runs out. The timer is saved in the database rather than kept by this process, so it survives closing the terminal, and a timer that ran out while the daemon was stopped fires as soon as it starts again.",
+		Args:        cobra.RangeArgs(1, 2),
+		RunE:        runTimer,
+	}
+	cmd.Flags().IntP("priority", "p", 0, "priority of the notification (-2 to 2)")
+	cmd.Flags().StringP("device", "d", "", "target device name")
+	_ = cmd.RegisterFlagCompletionFunc("device", completeCatalog(db.CatalogDevices))
+
+	cmd.AddCommand(newTimerListCmd(), newTimerCancelCmd())
+
+	return cmd
+}
+
+func runTimer(cmd *cobra.Command, args []string) error {
+	d, err := time.ParseDuration(args[0])
+	if err != nil || d < time.Second {
+		return fmt.Errorf("timer duration must be like 90s, 25m, or 1h30m, got %q", args[0])
+	}
+	priority, _ := cmd.Flags().GetInt("priority")
+	if priority < -2 || priority > 2 {
+		return fmt.Errorf("priority must be between -2 and 2")
+	}
+	device, _ := cmd.Flags().GetString("device")
+
+	rec := db.ScheduledRecord{
+		Title:    "Timer",
+		Message:  fmt.Sprintf("Your %s timer is up.", args[0]),
+		Priority: priority,
+		Device:   device,
+		SendAt:   time.Now().Add(d),
+		Kind:     db.ScheduledTimer,
+	}
+	if len(args) == 2 && args[1] != "" {
+		rec.Title = args[1]
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	id, err := store.ScheduleSend(cmd.Context(), rec)
+	if err != nil {
+		return err
+	}
+	cmd.Printf("✓ Timer #%d set for %s, going off at %s.\n", id, args[0], rec.SendAt.Local().Format("15:04:05"))
+	noteDaemonStopped(cmd, "timer")
+	return nil
+}
+
+// pendingTimers returns the timers that haven't gone off yet.
+func pendingTimers(cmd *cobra.Command, store *db.Store) ([]db.ScheduledRecord, error) {
+	pending, err := store.PendingScheduled(cmd.Context())
+	if err != nil {
+		return nil, err
+	}
+	var timers []db.ScheduledRecord
+	for _, rec := range pending {
+		if rec.Kind == db.ScheduledTimer {
+			timers = append(timers, rec)
+		}
+	}
+	return timers, nil
+}
+
+func newTimerListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List timers that haven't gone off yet",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			timers, err := pendingTimers(cmd, store)
+			if err != nil {
+				return err
+			}
+			if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(timers)
+			}
+			if len(timers) == 0 {
+				cmd.Println("No timers running.")
+				return nil
+			}
+			now := time.Now()
+			for _, rec := range timers {
+				when := fmt.Sprintf("in %s", rec.SendAt.Sub(now).Round(time.Second))
+				if rec.SendAt.Before(now) {
+					when = "due now, waiting for push daemon"
+				}
+				cmd.Printf("#%d %s %s (at %s)\n", rec.ID, rec.Title, when, rec.SendAt.Local().Format("15:04:05"))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("json", false, "output JSON")
+	return cmd
+}
+
+func newTimerCancelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel [id...]",
+		Short: "Cancel running timers",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+			if all == (len(args) > 0) {
+				return fmt.Errorf("give timer IDs or --all")
+			}
+
+			store, _, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = store.Close() }()
+
+			timers, err := pendingTimers(cmd, store)
+			if err != nil {
+				return err
+			}
+			running := map[int64]bool{}
+			var ids []int64
+			for _, rec := range timers {
+				running[rec.ID] = true
+				if all {
+					ids = append(ids, rec.ID)
+				}
+			}
+			for _, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid timer ID %q", arg)
+				}
+				if !running[id] {
+					return fmt.Errorf("no running timer #%d", id)
+				}
+				ids = append(ids, id)
+			}
+
+			cancelled := 0
+			for _, id := range ids {
+				ok, err := store.FinishScheduled(cmd.Context(), id, db.ScheduledCancelled)
+				if err != nil {
+					return err
+				}
+				if ok {
+					cancelled++
+				}
+			}
+			cmd.Printf("Cancelled %d timer(s).\n", cancelled)
+			return nil
+		},
+	}
+	cmd.Flags().Bool("all", false, "cancel every running timer")
+	return cmd
+}
@@ -0,0 +1,55 @@
+// ABOUTME: OpenTelemetry setup for CLI invocations.
+// ABOUTME: Wraps each command in a span so API and database spans share a trace.
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/harper/push/internal/telemetry"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var commandSpan trace.Span
+
+// serverAnnotation marks long-running commands. They get no command span, so
+// each MCP request or daemon job starts its own trace instead of nesting
+// under one that lasts as long as the process.
+const serverAnnotation = "push/server"
+
+// setupTracing installs the exporter when OTEL_* variables enable it and
+// returns a function that flushes spans before exit. Telemetry problems are
+// reported but never stop the command.
+func setupTracing() func() {
+	shutdown, err := telemetry.Setup(context.Background())
+	if err != nil {
+		logger.Warn("tracing disabled", "error", err)
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			logger.Warn("unable to flush traces", "error", err)
+		}
+	}
+}
+
+// startCommandSpan opens the span for the running command, e.g. "push send".
+func startCommandSpan(cmd *cobra.Command) {
+	if cmd.Annotations[serverAnnotation] != "" {
+		return
+	}
+	ctx, span := telemetry.Start(cmd.Context(), cmd.CommandPath())
+	cmd.SetContext(ctx)
+	commandSpan = span
+}
+
+// endCommandSpan closes the command span, recording err.
+func endCommandSpan(err error) {
+	if commandSpan == nil {
+		return
+	}
+	telemetry.End(commandSpan, err)
+	commandSpan = nil
+}
@@ -0,0 +1,146 @@
+// ABOUTME: Watch command that polls for new messages until interrupted.
+// ABOUTME: Prints each message and can run a hook command for it.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/daemon"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/hooks"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/spf13/cobra"
+)
+
+func newWatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "watch",
+		Annotations: map[string]string{serverAnnotation: "true"},
+		Short:       "Poll for new messages and print or act on each one",
+		Long:        "Poll every receiving device until interrupted, persisting and acknowledging new messages. With --exec, run a shell command for each message with PUSH_ID, PUSH_MESSAGE, PUSH_TITLE, PUSH_APP, PUSH_PRIORITY, PUSH_URL, PUSH_DEVICE, and PUSH_DATE set. Hooks are skipped while 'push snooze' is active, and messages from apps muted with 'push mute' are saved without being printed or passed to the hook.",
+		Args:        cobra.NoArgs,
+		RunE:        runWatch,
+	}
+
+	cmd.Flags().Duration("interval", 30*time.Second, "how often to poll")
+	cmd.Flags().String("exec", "", "shell command to run for each new message")
+	cmd.Flags().Duration("exec-timeout", hooks.DefaultTimeout, "stop a hook command, and anything it started, that runs longer than this")
+	cmd.Flags().String("device", "", "only poll this receiving device (default: all configured devices)")
+	_ = cmd.RegisterFlagCompletionFunc("device", completeReceivingDevices)
+
+	return cmd
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	devices, err := messageDevices(cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	hook, _ := cmd.Flags().GetString("exec")
+	timeout, _ := cmd.Flags().GetDuration("exec-timeout")
+	if timeout <= 0 {
+		return fmt.Errorf("--exec-timeout must be positive")
+	}
+
+	store, _, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := &watcher{
+		devices: devices,
+		store:   store,
+		hook:    hook,
+		timeout: timeout,
+		out:     cmd.OutOrStdout(),
+		errOut:  cmd.ErrOrStderr(),
+	}
+	runner := daemon.NewRunner(logger)
+	runner.Add(daemon.Job{Name: "watch", Every: interval, Run: w.poll})
+
+	logger.Info("watching for messages", "devices", len(devices), "interval", interval, "hook", hook != "")
+	return runner.Run(ctx)
+}
+
+// watcher polls the receiving devices and hands each new message to the hook.
+type watcher struct {
+	devices []*config.Config
+	store   *db.Store
+	hook    string
+	timeout time.Duration
+	out     io.Writer
+	errOut  io.Writer
+	// snoozed is the end of the snooze last reported, so it's logged once.
+	snoozed time.Time
+}
+
+func (w *watcher) poll(ctx context.Context) error {
+	for _, device := range w.devices {
+		name := device.ReceivingDevice()
+		_, err := pollDevice(ctx, device, w.store, false, 0, func(batch []pushover.ReceivedMessage) {
+			for _, msg := range batch {
+				w.handle(ctx, name, msg)
+			}
+		})
+		if err != nil {
+			if len(w.devices) == 1 {
+				return err
+			}
+			logger.Warn("unable to poll device", "device", name, "error", err)
+		}
+	}
+	return nil
+}
+
+func (w *watcher) handle(ctx context.Context, device string, msg pushover.ReceivedMessage) {
+	line := msg.Message
+	if msg.Title != "" {
+		line = msg.Title + ": " + line
+	}
+	if msg.App != "" {
+		line = msg.App + " | " + line
+	}
+	_, _ = fmt.Fprintf(w.out, "[%d] %s\n", msg.PushoverID, line)
+
+	if w.hook == "" || w.snoozing(ctx) {
+		return
+	}
+	if err := hooks.Run(ctx, w.hook, device, msg, w.timeout, w.out, w.errOut); err != nil {
+		logger.Warn("hook failed", "message_id", msg.PushoverID, "error", err)
+	}
+}
+
+// snoozing reports whether 'push snooze' has paused hooks. A failed lookup
+// runs the hook anyway rather than silently dropping it.
+func (w *watcher) snoozing(ctx context.Context) bool {
+	until, err := w.store.SnoozedUntil(ctx, time.Now())
+	if err != nil {
+		logger.Warn("unable to check snooze", "error", err)
+		return false
+	}
+	if !until.IsZero() && !until.Equal(w.snoozed) {
+		logger.Info("hooks snoozed", "until", until.Local().Format(time.RFC3339))
+	}
+	w.snoozed = until
+	return !until.IsZero()
+}
@@ -0,0 +1,44 @@
+// ABOUTME: Tests for push watch's handling of new messages.
+// ABOUTME: Checks the hook is skipped while 'push snooze' is active and runs again after.
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+)
+
+func TestWatcherSkipsHookWhileSnoozed(t *testing.T) {
+	ctx := context.Background()
+	store, err := db.Open(db.Memory)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	var out bytes.Buffer
+	w := &watcher{store: store, hook: `echo "hook ran for $PUSH_ID"`, timeout: time.Minute, out: &out, errOut: &out}
+	msg := pushover.ReceivedMessage{PushoverID: 7, Message: "disk full"}
+
+	if err := store.SetSnooze(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	w.handle(ctx, "phone", msg)
+	if got := out.String(); !strings.Contains(got, "[7] disk full") || strings.Contains(got, "hook ran") {
+		t.Errorf("output while snoozed = %q, want the message printed and no hook", got)
+	}
+
+	if _, err := store.ClearSnooze(ctx, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	w.handle(ctx, "phone", msg)
+	if got := out.String(); !strings.Contains(got, "hook ran for 7") {
+		t.Errorf("output after the snooze = %q, want the hook's output", got)
+	}
+}
@@ -0,0 +1,51 @@
+// ABOUTME: Reads the system clipboard by shelling out to the platform's paste tool.
+// ABOUTME: Supports pbpaste, wl-paste, xclip, xsel, and PowerShell.
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnavailable means no supported clipboard tool is installed.
+var ErrUnavailable = errors.New("no clipboard tool found (install wl-clipboard, xclip, or xsel)")
+
+// Read returns the current clipboard text.
+func Read(ctx context.Context) (string, error) {
+	for _, args := range commands() {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		out, err := exec.CommandContext(ctx, path, args[1:]...).Output() //nolint:gosec // fixed tool list
+		if err != nil {
+			return "", fmt.Errorf("read clipboard with %s: %w", args[0], err)
+		}
+		return strings.TrimRight(string(out), "\r\n"), nil
+	}
+	return "", ErrUnavailable
+}
+
+// commands lists paste tools for this platform in order of preference.
+func commands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbpaste"}}
+	case "windows":
+		return [][]string{{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"}}
+	}
+
+	var cmds [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, []string{"wl-paste", "--no-newline"})
+	}
+	return append(cmds,
+		[]string{"xclip", "-selection", "clipboard", "-o"},
+		[]string{"xsel", "--clipboard", "--output"},
+	)
+}
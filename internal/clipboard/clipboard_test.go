@@ -0,0 +1,9 @@
+// ABOUTME: Placeholder test for clipboard package.
+// ABOUTME: Ensures coverage tools work correctly.
+package clipboard
+
+import "testing"
+
+func TestPlaceholder(t *testing.T) {
+	// Placeholder to satisfy Go 1.23 coverage requirements
+}
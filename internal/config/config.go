@@ -5,55 +5,341 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/pelletier/go-toml/v2"
 )
 
+// ErrInvalid matches errors caused by a missing or invalid setting or an
+// unreadable config file, so callers can tell them from API failures.
+var ErrInvalid = errors.New("invalid config")
+
+// invalidError marks err as a config problem without changing its message.
+type invalidError struct {
+	err error
+}
+
+func (e *invalidError) Error() string {
+	return e.err.Error()
+}
+
+func (e *invalidError) Unwrap() []error {
+	return []error{e.err, ErrInvalid}
+}
+
+// invalid marks err as matching ErrInvalid.
+func invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &invalidError{err: err}
+}
+
 // Config describes the persisted Push settings.
 type Config struct {
 	AppToken        string `toml:"app_token"`
 	UserKey         string `toml:"user_key"`
 	DeviceID        string `toml:"device_id"`
 	DeviceSecret    string `toml:"device_secret"`
+	DeviceName      string `toml:"device_name,omitempty"`
 	DefaultDevice   string `toml:"default_device"`
+	Email           string `toml:"email,omitempty"`
 	DefaultPriority int    `toml:"default_priority"`
+	DedupeWindow    string `toml:"dedupe_window,omitempty"`
+	DefaultVia      string `toml:"default_via,omitempty"`
+	APIURL          string `toml:"api_url,omitempty"`
+	ProxyURL        string `toml:"proxy_url,omitempty"`
+	CACertPath      string `toml:"ca_cert_path,omitempty"`
+	RateLimit       int    `toml:"rate_limit_per_minute,omitempty"`
+	RateLimitMode   string `toml:"rate_limit_mode,omitempty"`
+	NoMediaCache    bool   `toml:"disable_media_cache,omitempty"`
+	Theme           string `toml:"theme,omitempty"`
+	LongMessageMode string `toml:"long_message_mode,omitempty"`
+	TitleTemplate   string `toml:"title_template,omitempty"`
+	DatabasePath    string `toml:"database_path,omitempty"`
+	// Include lists overlay files, relative to this file, merged over it.
+	Include []string `toml:"include,omitempty"`
+
+	Send        SendDefaults            `toml:"send,omitempty"`
+	Messages    MessagesDefaults        `toml:"messages,omitempty"`
+	History     HistoryDefaults         `toml:"history,omitempty"`
+	MCP         MCPConfig               `toml:"mcp,omitempty"`
+	Serve       ServeConfig             `toml:"serve,omitempty"`
+	SMTP        SMTPConfig              `toml:"smtp,omitempty"`
+	Digest      DigestConfig            `toml:"digest,omitempty"`
+	Replicate   ReplicateConfig         `toml:"replicate,omitempty"`
+	Maintenance MaintenanceConfig       `toml:"maintenance,omitempty"`
+	Aliases     map[string]AliasConfig  `toml:"aliases,omitempty"`
+	Apps        map[string]AppConfig    `toml:"apps,omitempty"`
+	Devices     map[string]DeviceConfig `toml:"devices,omitempty"`
+	// Recipients names other user or group keys for push send --user.
+	Recipients map[string]string `toml:"recipients,omitempty"`
+	Ntfy       NtfyConfig        `toml:"ntfy,omitempty"`
+	Gotify     GotifyConfig      `toml:"gotify,omitempty"`
+	Webhook    WebhookConfig     `toml:"webhook,omitempty"`
+	// PriorityRules change the local priority of received messages; see
+	// LocalPriorities.
+	PriorityRules []PriorityRule `toml:"priority_rules,omitempty"`
+
+	// layers are the files merged into this config, main file first; Save
+	// writes each setting back to the file it came from.
+	layers []layer
+}
+
+// SendDefaults are defaults for push send flags.
+type SendDefaults struct {
+	// DefaultSound is used when --sound isn't given.
+	DefaultSound string `toml:"default_sound,omitempty"`
+	// DefaultTitlePrefix is prepended to every title, or becomes the title
+	// when none is given.
+	DefaultTitlePrefix string `toml:"default_title_prefix,omitempty"`
+}
+
+// MessagesDefaults are defaults for push messages flags.
+type MessagesDefaults struct {
+	// DefaultLimit is used when --limit isn't given. Unset means 10.
+	DefaultLimit int `toml:"default_limit,omitempty"`
+}
+
+// HistoryDefaults are defaults for push history flags.
+type HistoryDefaults struct {
+	// DefaultFormat is "table" or "json"; --json overrides it either way.
+	DefaultFormat string `toml:"default_format,omitempty"`
+}
+
+// History output formats.
+const (
+	HistoryFormatTable = "table"
+	HistoryFormatJSON  = "json"
+)
+
+// MCPConfig holds settings specific to the MCP server.
+type MCPConfig struct {
+	// RequireConfirmationPriority is the lowest priority that needs explicit
+	// human confirmation before sending. Unset means 2 (emergency); 3 disables it.
+	RequireConfirmationPriority *int `toml:"require_confirmation_priority,omitempty"`
+	// AutoAck controls whether check_messages deletes fetched messages from
+	// Pushover when the caller doesn't say. Unset means true.
+	AutoAck *bool `toml:"auto_ack,omitempty"`
+	// EnabledTools limits the tools the server exposes. Empty means all.
+	EnabledTools []string `toml:"enabled_tools,omitempty"`
+	// ReadOnly hides tools that send or delete notifications and stops
+	// check_messages from acknowledging what it fetches.
+	ReadOnly bool `toml:"read_only,omitempty"`
+	// SessionSendLimit caps send_notification calls per minute from one client
+	// session. Unset means 10; 0 disables it.
+	SessionSendLimit *int `toml:"session_send_limit_per_minute,omitempty"`
+	// SendLimit caps send_notification calls per minute across all sessions of
+	// one server. Unset means 30; 0 disables it.
+	SendLimit *int `toml:"send_limit_per_minute,omitempty"`
+	// MaxBodyChars cuts message bodies in list_history and check_messages
+	// replies to this many characters unless the call says otherwise. Unset
+	// or 0 means no limit.
+	MaxBodyChars int `toml:"max_body_chars,omitempty"`
+}
+
+// ServeConfig holds settings for push serve, which records taps on reply
+// links.
+type ServeConfig struct {
+	// Listen is the address push serve binds. Unset means 127.0.0.1:8766.
+	Listen string `toml:"listen,omitempty"`
+	// PublicURL is where phones reach push serve, e.g.
+	// https://push.example.com; reply links are built from it.
+	PublicURL string `toml:"public_url,omitempty"`
+	// HookToken must accompany requests to the alert webhooks; they are off
+	// without it.
+	HookToken string `toml:"hook_token,omitempty"`
+	// SeverityPriorities maps alert severity labels to notification
+	// priorities, on top of DefaultSeverityPriorities.
+	SeverityPriorities map[string]int `toml:"severity_priorities,omitempty"`
+}
+
+// SMTPConfig holds settings for push smtp, which turns mail into
+// notifications.
+type SMTPConfig struct {
+	// Listen is the address push smtp binds. Unset means 127.0.0.1:2525.
+	Listen string `toml:"listen,omitempty"`
+	// AllowedSenders lists envelope senders to accept, as full addresses or
+	// "@domain". Empty accepts mail from anyone who can connect.
+	AllowedSenders []string `toml:"allowed_senders,omitempty"`
+	// MaxMessageBytes rejects larger mail. Unset means 1 MiB.
+	MaxMessageBytes int64 `toml:"max_message_bytes,omitempty"`
+}
+
+// DefaultSMTPListen is where push smtp listens without [smtp] listen.
+const DefaultSMTPListen = "127.0.0.1:2525"
+
+// SMTPListen returns the address push smtp binds.
+func (c *Config) SMTPListen() string {
+	if c == nil || c.SMTP.Listen == "" {
+		return DefaultSMTPListen
+	}
+	return c.SMTP.Listen
+}
+
+// DefaultSeverityPriorities maps common alert severity labels to priorities.
+var DefaultSeverityPriorities = map[string]int{
+	"critical": 1,
+	"error":    1,
+	"high":     1,
+	"warning":  0,
+	"info":     -1,
+	"none":     -1,
+}
+
+// AlertPriorities returns the severity to priority map for alert webhooks.
+func (c *Config) AlertPriorities() (map[string]int, error) {
+	priorities := make(map[string]int, len(DefaultSeverityPriorities))
+	for severity, priority := range DefaultSeverityPriorities {
+		priorities[severity] = priority
+	}
+	if c == nil {
+		return priorities, nil
+	}
+	for severity, priority := range c.Serve.SeverityPriorities {
+		if priority < -2 || priority > 2 {
+			return nil, invalid(fmt.Errorf("[serve] severity_priorities.%s must be between -2 and 2", severity))
+		}
+		priorities[strings.ToLower(severity)] = priority
+	}
+	return priorities, nil
+}
+
+// DefaultServeListen is where push serve listens without [serve] listen.
+const DefaultServeListen = "127.0.0.1:8766"
+
+// ServeListen returns the address push serve binds.
+func (c *Config) ServeListen() string {
+	if c == nil || c.Serve.Listen == "" {
+		return DefaultServeListen
+	}
+	return c.Serve.Listen
 }
 
-// Load reads the config from disk. If the file does not exist it returns a default config.
+// ReplyBaseURL returns [serve] public_url, which reply links are built on.
+func (c *Config) ReplyBaseURL() (string, error) {
+	if c == nil || c.Serve.PublicURL == "" {
+		return "", invalid(errors.New("reply links need [serve] public_url, the address where phones reach push serve"))
+	}
+	base, err := url.Parse(c.Serve.PublicURL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return "", invalid(fmt.Errorf("[serve] public_url must be an http or https URL, got %q", c.Serve.PublicURL))
+	}
+	return strings.TrimRight(c.Serve.PublicURL, "/"), nil
+}
+
+// AliasConfig is a canned notification sent with `push a <name>`.
+type AliasConfig struct {
+	Message  string `toml:"message"`
+	Title    string `toml:"title,omitempty"`
+	Priority int    `toml:"priority,omitempty"`
+	Sound    string `toml:"sound,omitempty"`
+	Device   string `toml:"device,omitempty"`
+	URL      string `toml:"url,omitempty"`
+	Via      string `toml:"via,omitempty"`
+	App      string `toml:"app,omitempty"`
+}
+
+// AppConfig is an additional Pushover application token, selected with --app
+// so notifications show under that application's name and icon.
+type AppConfig struct {
+	Token string `toml:"token"`
+}
+
+// DeviceConfig is an additional registered receiving device, keyed by its
+// name. Messages polled for it are stored with that name in history.
+type DeviceConfig struct {
+	DeviceID     string `toml:"device_id"`
+	DeviceSecret string `toml:"device_secret"`
+}
+
+// NtfyConfig configures the ntfy.sh (or self-hosted ntfy) send backend.
+type NtfyConfig struct {
+	Server string `toml:"server,omitempty"`
+	Topic  string `toml:"topic,omitempty"`
+	Token  string `toml:"token,omitempty"`
+}
+
+// GotifyConfig configures the Gotify send backend.
+type GotifyConfig struct {
+	Server string `toml:"server,omitempty"`
+	Token  string `toml:"token,omitempty"`
+}
+
+// WebhookConfig configures the generic JSON webhook send backend.
+type WebhookConfig struct {
+	URL     string            `toml:"url,omitempty"`
+	Headers map[string]string `toml:"headers,omitempty"`
+}
+
+// Load reads the config from disk, merging in the files named by include and
+// any config.d/*.toml next to it. If no file exists it returns a default config.
 func Load(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
-	if errors.Is(err, os.ErrNotExist) {
-		return &Config{}, nil
+	layers, err := loadLayers(path)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	merged := map[string]any{}
+	for _, l := range layers {
+		mergeValues(merged, l.values)
 	}
+	data, err := toml.Marshal(merged)
 	if err != nil {
-		return nil, fmt.Errorf("reading config: %w", err)
+		return nil, invalid(fmt.Errorf("merging config: %w", err))
 	}
 
 	var cfg Config
 	if err := toml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("parsing config: %w", err)
+		return nil, invalid(fmt.Errorf("parsing config: %w", err))
+	}
+	if len(layers) > 1 {
+		cfg.layers = layers
 	}
 
 	return &cfg, nil
 }
 
-// Save writes the config atomically to disk.
+// Save writes the config atomically to disk. A config loaded with overlays is
+// written back across its files so overlay settings stay in the overlays.
 func Save(path string, cfg *Config) error {
 	if cfg == nil {
 		return errors.New("config is nil")
 	}
-
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
+	if len(cfg.layers) > 1 && cfg.layers[0].path == path {
+		return saveLayers(cfg)
 	}
 
 	data, err := toml.Marshal(cfg)
 	if err != nil {
 		return fmt.Errorf("encoding config: %w", err)
 	}
+	return writeFile(path, data)
+}
+
+// Files returns the config files merged into c, main file first. It is empty
+// for a config that wasn't loaded with overlays.
+func (c *Config) Files() []string {
+	if c == nil {
+		return nil
+	}
+	files := make([]string, 0, len(c.layers))
+	for _, l := range c.layers {
+		files = append(files, l.path)
+	}
+	return files
+}
+
+// writeFile replaces path with data atomically, readable only by the owner.
+func writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
 
 	tmpFile, err := os.CreateTemp(dir, "config-*.tmp")
 	if err != nil {
@@ -86,13 +372,13 @@ func Save(path string, cfg *Config) error {
 // ValidateSend ensures the config contains the minimum fields required to send.
 func (c *Config) ValidateSend() error {
 	if c == nil {
-		return errors.New("config is nil")
+		return invalid(errors.New("config is nil"))
 	}
 	if c.AppToken == "" {
-		return errors.New("app token is missing")
+		return invalid(errors.New("app token is missing"))
 	}
 	if c.UserKey == "" {
-		return errors.New("user key is missing")
+		return invalid(errors.New("user key is missing"))
 	}
 	return nil
 }
@@ -103,12 +389,13 @@ func (c *Config) ValidateReceive() error {
 		return err
 	}
 	if c.DeviceID == "" || c.DeviceSecret == "" {
-		return errors.New("device credentials missing, run 'push login'")
+		return invalid(errors.New("device credentials missing, run 'push login'"))
 	}
 	return nil
 }
 
 // Clone returns a shallow copy of the config to avoid accidental mutation.
+// Map fields such as webhook headers remain shared with the original.
 func (c *Config) Clone() *Config {
 	if c == nil {
 		return nil
@@ -117,6 +404,79 @@ func (c *Config) Clone() *Config {
 	return &copied
 }
 
+// WithoutSecrets returns a copy with credentials and machine-specific device
+// registration removed, suitable for backups. The proxy URL goes too, since
+// it may carry a username and password.
+func (c *Config) WithoutSecrets() *Config {
+	if c == nil {
+		return nil
+	}
+	copied := *c
+	copied.AppToken = ""
+	copied.UserKey = ""
+	copied.DeviceID = ""
+	copied.DeviceSecret = ""
+	copied.DeviceName = ""
+	copied.Ntfy.Token = ""
+	copied.Gotify.Token = ""
+	copied.Webhook.Headers = nil
+	copied.Serve.HookToken = ""
+	copied.ProxyURL = ""
+	copied.Apps = nil
+	copied.Devices = nil
+	copied.Recipients = nil
+	return &copied
+}
+
+// WithSecretsFrom returns a copy carrying the credentials of src, the inverse
+// of WithoutSecrets.
+func (c *Config) WithSecretsFrom(src *Config) *Config {
+	if c == nil {
+		return nil
+	}
+	copied := *c
+	if src == nil {
+		return &copied
+	}
+	copied.AppToken = src.AppToken
+	copied.UserKey = src.UserKey
+	copied.DeviceID = src.DeviceID
+	copied.DeviceSecret = src.DeviceSecret
+	copied.DeviceName = src.DeviceName
+	copied.Ntfy.Token = src.Ntfy.Token
+	copied.Gotify.Token = src.Gotify.Token
+	copied.Webhook.Headers = src.Webhook.Headers
+	copied.Serve.HookToken = src.Serve.HookToken
+	copied.ProxyURL = src.ProxyURL
+	copied.Apps = src.Apps
+	copied.Devices = src.Devices
+	copied.Recipients = src.Recipients
+	copied.layers = src.layers
+	return &copied
+}
+
+// ForApp returns a copy that sends with the token of the named [apps] entry.
+// An empty name returns the config unchanged.
+func (c *Config) ForApp(name string) (*Config, error) {
+	if name == "" {
+		return c, nil
+	}
+	var app AppConfig
+	ok := false
+	if c != nil {
+		app, ok = c.Apps[name]
+	}
+	if !ok {
+		return nil, invalid(fmt.Errorf("unknown app %q (add an [apps.%s] table with a token)", name, name))
+	}
+	if app.Token == "" {
+		return nil, invalid(fmt.Errorf("app %q has no token", name))
+	}
+	copied := *c
+	copied.AppToken = app.Token
+	return &copied, nil
+}
+
 // DeviceConfigured indicates whether receiving credentials exist.
 func (c *Config) DeviceConfigured() bool {
 	if c == nil {
@@ -124,3 +484,197 @@ func (c *Config) DeviceConfigured() bool {
 	}
 	return c.DeviceID != "" && c.DeviceSecret != ""
 }
+
+// ReceivingDevice names the registered device in message history: the name
+// chosen at login, or the device ID for configs that predate it.
+func (c *Config) ReceivingDevice() string {
+	if c == nil {
+		return ""
+	}
+	if c.DeviceName != "" {
+		return c.DeviceName
+	}
+	return c.DeviceID
+}
+
+// ReceivingDevices lists the names of every configured receiving device: the
+// one registered by login first, then any [devices] entries sorted by name.
+func (c *Config) ReceivingDevices() []string {
+	if c == nil {
+		return nil
+	}
+	var names []string
+	if c.DeviceConfigured() {
+		names = append(names, c.ReceivingDevice())
+	}
+	extra := make([]string, 0, len(c.Devices))
+	for name := range c.Devices {
+		if name != c.ReceivingDevice() {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	return append(names, extra...)
+}
+
+// ForDevice returns a copy that receives as the named device. An empty name
+// or the login device returns the config unchanged.
+func (c *Config) ForDevice(name string) (*Config, error) {
+	if name == "" || (c != nil && c.DeviceConfigured() && name == c.ReceivingDevice()) {
+		return c, nil
+	}
+	var device DeviceConfig
+	ok := false
+	if c != nil {
+		device, ok = c.Devices[name]
+	}
+	if !ok {
+		return nil, invalid(fmt.Errorf("unknown device %q (register it with 'push login --add --device-name %s')", name, name))
+	}
+	if device.DeviceID == "" || device.DeviceSecret == "" {
+		return nil, invalid(fmt.Errorf("device %q is missing device_id or device_secret", name))
+	}
+	copied := *c
+	copied.DeviceID = device.DeviceID
+	copied.DeviceSecret = device.DeviceSecret
+	copied.DeviceName = name
+	return &copied, nil
+}
+
+// APIURLEnv overrides api_url when set, e.g. to point at a mock server.
+const APIURLEnv = "PUSH_API_URL"
+
+// EffectiveAPIURL returns the Pushover API base URL from the environment or
+// config. An empty result means the client default.
+func (c *Config) EffectiveAPIURL() string {
+	if env := strings.TrimSpace(os.Getenv(APIURLEnv)); env != "" {
+		return env
+	}
+	if c == nil {
+		return ""
+	}
+	return c.APIURL
+}
+
+// ConfirmationPriority returns the lowest MCP send priority requiring confirmation.
+func (c *Config) ConfirmationPriority() int {
+	if c == nil || c.MCP.RequireConfirmationPriority == nil {
+		return 2
+	}
+	return *c.MCP.RequireConfirmationPriority
+}
+
+// MCPAutoAck reports whether check_messages acknowledges by default.
+func (c *Config) MCPAutoAck() bool {
+	if c == nil || c.MCP.AutoAck == nil {
+		return true
+	}
+	return *c.MCP.AutoAck
+}
+
+// MCPMaxBodyChars returns the default body length for list_history and
+// check_messages replies; zero means no limit.
+func (c *Config) MCPMaxBodyChars() int {
+	if c == nil {
+		return 0
+	}
+	return max(c.MCP.MaxBodyChars, 0)
+}
+
+// MCPSendLimits returns the per-session and server-wide MCP sends allowed per
+// minute; zero means unlimited.
+func (c *Config) MCPSendLimits() (session, global int) {
+	session, global = 10, 30
+	if c == nil {
+		return session, global
+	}
+	if c.MCP.SessionSendLimit != nil {
+		session = *c.MCP.SessionSendLimit
+	}
+	if c.MCP.SendLimit != nil {
+		global = *c.MCP.SendLimit
+	}
+	return session, global
+}
+
+// Rate limit modes for rate_limit_mode.
+const (
+	RateLimitFail = "fail"
+	RateLimitWait = "wait"
+)
+
+// RateLimitWaits reports whether sends over budget should queue rather than fail.
+func (c *Config) RateLimitWaits() (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+	switch strings.ToLower(c.RateLimitMode) {
+	case "", RateLimitFail:
+		return false, nil
+	case RateLimitWait:
+		return true, nil
+	default:
+		return false, invalid(fmt.Errorf("rate_limit_mode must be %q or %q", RateLimitFail, RateLimitWait))
+	}
+}
+
+// Long message modes for long_message_mode.
+const (
+	LongMessageError    = "error"
+	LongMessageTruncate = "truncate"
+	LongMessageSplit    = "split"
+)
+
+// LongMessagePolicy returns how messages over the length limit are handled.
+func (c *Config) LongMessagePolicy() (string, error) {
+	if c == nil {
+		return LongMessageError, nil
+	}
+	policy, err := ParseLongMessageMode(c.LongMessageMode)
+	return policy, invalid(err)
+}
+
+// ParseLongMessageMode validates a long message mode, defaulting to error.
+func ParseLongMessageMode(mode string) (string, error) {
+	switch strings.ToLower(mode) {
+	case "", LongMessageError:
+		return LongMessageError, nil
+	case LongMessageTruncate:
+		return LongMessageTruncate, nil
+	case LongMessageSplit:
+		return LongMessageSplit, nil
+	default:
+		return "", fmt.Errorf("long_message_mode must be %q, %q, or %q", LongMessageError, LongMessageTruncate, LongMessageSplit)
+	}
+}
+
+// HistoryFormat returns the validated [history] default_format, defaulting to
+// table.
+func (c *Config) HistoryFormat() (string, error) {
+	if c == nil {
+		return HistoryFormatTable, nil
+	}
+	switch strings.ToLower(c.History.DefaultFormat) {
+	case "", HistoryFormatTable:
+		return HistoryFormatTable, nil
+	case HistoryFormatJSON:
+		return HistoryFormatJSON, nil
+	default:
+		return "", invalid(fmt.Errorf("history.default_format must be %q or %q", HistoryFormatTable, HistoryFormatJSON))
+	}
+}
+
+// DedupeWindowDuration parses dedupe_window, returning zero when deduplication is off.
+func (c *Config) DedupeWindowDuration() (time.Duration, error) {
+	if c == nil || c.DedupeWindow == "" {
+		return 0, nil
+	}
+	window, err := time.ParseDuration(c.DedupeWindow)
+	if err != nil {
+		return 0, invalid(fmt.Errorf("parsing dedupe_window: %w", err))
+	}
+	if window < 0 {
+		return 0, invalid(errors.New("dedupe_window must not be negative"))
+	}
+	return window, nil
+}
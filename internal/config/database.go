@@ -0,0 +1,41 @@
+// ABOUTME: Location of the SQLite database when database_path moves it out of the data directory.
+// ABOUTME: Lets the store live on an encrypted volume or shared storage.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MemoryDatabase is the database_path that keeps history in memory only.
+const MemoryDatabase = ":memory:"
+
+// DatabaseLocation returns database_path with a leading ~ expanded, or "" to
+// keep the database in the data directory. MemoryDatabase is returned as is.
+// Relative paths are rejected because they would move with the working
+// directory.
+func (c *Config) DatabaseLocation() (string, error) {
+	if c == nil || strings.TrimSpace(c.DatabasePath) == "" {
+		return "", nil
+	}
+	path := strings.TrimSpace(c.DatabasePath)
+	if path == MemoryDatabase {
+		return path, nil
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("locating home directory: %w", err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	if !filepath.IsAbs(path) {
+		return "", invalid(fmt.Errorf("database_path %q must be absolute or start with ~/", c.DatabasePath))
+	}
+	if strings.HasSuffix(c.DatabasePath, "/") || path == filepath.Dir(path) {
+		return "", invalid(fmt.Errorf("database_path %q names a directory; give the database file, e.g. %s", c.DatabasePath, filepath.Join(path, "push.db")))
+	}
+	return filepath.Clean(path), nil
+}
@@ -0,0 +1,46 @@
+// ABOUTME: Tests for database_path.
+// ABOUTME: Checks home expansion, in-memory mode, and rejection of relative and directory paths.
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDatabaseLocation(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home directory: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "unset", path: "", want: ""},
+		{name: "absolute", path: "/secure/push/history.db", want: "/secure/push/history.db"},
+		{name: "cleaned", path: "/secure//push/./history.db", want: "/secure/push/history.db"},
+		{name: "home", path: "~/vault/push.db", want: filepath.Join(home, "vault", "push.db")},
+		{name: "memory", path: ":memory:", want: ":memory:"},
+		{name: "relative", path: "push.db", wantErr: true},
+		{name: "directory", path: "/secure/push/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&Config{DatabasePath: tt.path}).DatabaseLocation()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DatabaseLocation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalid) {
+				t.Errorf("DatabaseLocation() error = %v, want it to match ErrInvalid", err)
+			}
+			if got != tt.want {
+				t.Errorf("DatabaseLocation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// ABOUTME: Schedule for the digest of received messages that push daemon sends.
+// ABOUTME: Parses [digest] into a daily or weekly send time.
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DigestConfig schedules the digest push daemon sends.
+type DigestConfig struct {
+	// At is the local time of day to send the digest, "HH:MM"; unset sends none.
+	At string `toml:"at,omitempty"`
+	// Weekday makes the digest weekly, sent on this day.
+	Weekday  string `toml:"weekday,omitempty"`
+	Priority int    `toml:"priority,omitempty"`
+	// MinPriority leaves messages below this priority out of the digest.
+	MinPriority *int `toml:"min_priority,omitempty"`
+}
+
+// DigestSchedule is when the scheduled digest goes out.
+type DigestSchedule struct {
+	Hour   int
+	Minute int
+	// Weekly sends on Weekday only, rather than every day.
+	Weekly  bool
+	Weekday time.Weekday
+}
+
+// DigestSchedule parses [digest]. It reports false when at is unset, so no
+// digest is scheduled.
+func (c *Config) DigestSchedule() (DigestSchedule, bool, error) {
+	if c == nil || c.Digest.At == "" {
+		return DigestSchedule{}, false, nil
+	}
+	at, err := time.Parse("15:04", c.Digest.At)
+	if err != nil {
+		return DigestSchedule{}, false, invalid(fmt.Errorf("[digest] at must be a time like 08:00, got %q", c.Digest.At))
+	}
+	if p := c.Digest.Priority; p < -2 || p > 2 {
+		return DigestSchedule{}, false, invalid(fmt.Errorf("[digest] priority must be between -2 and 2"))
+	}
+	if p := c.Digest.MinPriority; p != nil && (*p < -2 || *p > 2) {
+		return DigestSchedule{}, false, invalid(fmt.Errorf("[digest] min_priority must be between -2 and 2"))
+	}
+	schedule := DigestSchedule{Hour: at.Hour(), Minute: at.Minute()}
+	if c.Digest.Weekday != "" {
+		day, ok := parseWeekday(c.Digest.Weekday)
+		if !ok {
+			return DigestSchedule{}, false, invalid(fmt.Errorf("[digest] weekday must be a day such as monday, got %q", c.Digest.Weekday))
+		}
+		schedule.Weekly, schedule.Weekday = true, day
+	}
+	return schedule, true, nil
+}
+
+// Previous returns the latest time the digest was due at or before now, in
+// now's time zone.
+func (s DigestSchedule) Previous(now time.Time) time.Time {
+	due := time.Date(now.Year(), now.Month(), now.Day(), s.Hour, s.Minute, 0, 0, now.Location())
+	if due.After(now) {
+		due = due.AddDate(0, 0, -1)
+	}
+	for s.Weekly && due.Weekday() != s.Weekday {
+		due = due.AddDate(0, 0, -1)
+	}
+	return due
+}
+
+// String describes the schedule, e.g. "daily at 08:00".
+func (s DigestSchedule) String() string {
+	if s.Weekly {
+		return fmt.Sprintf("%ss at %02d:%02d", s.Weekday, s.Hour, s.Minute)
+	}
+	return fmt.Sprintf("daily at %02d:%02d", s.Hour, s.Minute)
+}
+
+// parseWeekday accepts a day's English name or its first three letters.
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) < 3 {
+		return 0, false
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,52 @@
+// ABOUTME: Tests for the digest schedule.
+// ABOUTME: Checks parsing [digest] and finding the latest due time, daily and weekly.
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDigestSchedule(t *testing.T) {
+	if _, ok, err := (&Config{}).DigestSchedule(); ok || err != nil {
+		t.Errorf("no [digest] at = %v, %v; want none scheduled", ok, err)
+	}
+	tooLow := -3
+	for name, digest := range map[string]DigestConfig{
+		"bad time":     {At: "8am"},
+		"bad weekday":  {At: "08:00", Weekday: "someday"},
+		"priority":     {At: "08:00", Priority: 3},
+		"min priority": {At: "08:00", MinPriority: &tooLow},
+	} {
+		if _, _, err := (&Config{Digest: digest}).DigestSchedule(); err == nil {
+			t.Errorf("%s: DigestSchedule() succeeded, want an error", name)
+		}
+	}
+
+	// Wednesday.
+	now := time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		digest DigestConfig
+		want   time.Time
+		desc   string
+	}{
+		{DigestConfig{At: "08:00"}, time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC), "daily at 08:00"},
+		{DigestConfig{At: "09:30"}, time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC), "daily at 09:30"},
+		{DigestConfig{At: "18:15"}, time.Date(2026, 3, 3, 18, 15, 0, 0, time.UTC), "daily at 18:15"},
+		{DigestConfig{At: "08:00", Weekday: "Monday"}, time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC), "Mondays at 08:00"},
+		{DigestConfig{At: "08:00", Weekday: "wed"}, time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC), "Wednesdays at 08:00"},
+		{DigestConfig{At: "10:00", Weekday: "wednesday"}, time.Date(2026, 2, 25, 10, 0, 0, 0, time.UTC), "Wednesdays at 10:00"},
+	}
+	for _, tt := range tests {
+		schedule, ok, err := (&Config{Digest: tt.digest}).DigestSchedule()
+		if err != nil || !ok {
+			t.Fatalf("DigestSchedule(%+v) = %v, %v", tt.digest, ok, err)
+		}
+		if got := schedule.Previous(now); !got.Equal(tt.want) {
+			t.Errorf("%+v: Previous() = %v, want %v", tt.digest, got, tt.want)
+		}
+		if got := schedule.String(); got != tt.desc {
+			t.Errorf("%+v: String() = %q, want %q", tt.digest, got, tt.desc)
+		}
+	}
+}
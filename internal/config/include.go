@@ -0,0 +1,213 @@
+// ABOUTME: Layered config files: include = [...] overlays and config.d/*.toml.
+// ABOUTME: Merges them on load and writes each setting back to the file that owns it.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// includeDir holds overlays merged automatically after the include list.
+const includeDir = "config.d"
+
+// layer is one file merged into a loaded config.
+type layer struct {
+	path   string
+	values map[string]any
+}
+
+// loadLayers reads the main config at path and every overlay it pulls in, in
+// merge order: the main file, its include list, then config.d/*.toml sorted
+// by name. Later layers win. Missing overlays load as empty so a synced base
+// config works on a machine that has no overlay yet.
+func loadLayers(path string) ([]layer, error) {
+	base, err := readLayer(path)
+	if err != nil {
+		return nil, err
+	}
+	layers := []layer{base}
+
+	dir := filepath.Dir(path)
+	var overlays []string
+	if raw, ok := base.values["include"]; ok {
+		list, ok := raw.([]any)
+		if !ok {
+			return nil, errors.New("include must be a list of file names")
+		}
+		for _, item := range list {
+			name, ok := item.(string)
+			if !ok || name == "" {
+				return nil, errors.New("include must be a list of file names")
+			}
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(dir, name)
+			}
+			overlays = append(overlays, name)
+		}
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, includeDir, "*.toml"))
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", includeDir, err)
+	}
+	sort.Strings(matches)
+	overlays = append(overlays, matches...)
+
+	for _, name := range overlays {
+		overlay, err := readLayer(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := overlay.values["include"]; ok {
+			return nil, fmt.Errorf("%s: include is only allowed in the main config", name)
+		}
+		layers = append(layers, overlay)
+	}
+	return layers, nil
+}
+
+// readLayer parses one file, treating a missing file as empty.
+func readLayer(path string) (layer, error) {
+	l := layer{path: path, values: map[string]any{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return layer{}, fmt.Errorf("reading config: %w", err)
+	}
+	if err := toml.Unmarshal(data, &l.values); err != nil {
+		return layer{}, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// mergeValues copies src into dst, merging tables key by key.
+func mergeValues(dst, src map[string]any) {
+	for key, value := range src {
+		if table, ok := value.(map[string]any); ok {
+			if existing, ok := dst[key].(map[string]any); ok {
+				mergeValues(existing, table)
+				continue
+			}
+			copied := map[string]any{}
+			mergeValues(copied, table)
+			dst[key] = copied
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// leaves flattens values into non-table settings keyed by their dotted path.
+func leaves(values map[string]any) map[string]any {
+	out := map[string]any{}
+	var walk func(prefix []string, values map[string]any)
+	walk = func(prefix []string, values map[string]any) {
+		for key, value := range values {
+			path := append(append([]string(nil), prefix...), key)
+			if table, ok := value.(map[string]any); ok {
+				walk(path, table)
+				continue
+			}
+			out[strings.Join(path, "\x00")] = value
+		}
+	}
+	walk(nil, values)
+	return out
+}
+
+// setLeaf stores value at a path produced by leaves.
+func setLeaf(values map[string]any, key string, value any) {
+	path := strings.Split(key, "\x00")
+	for _, part := range path[:len(path)-1] {
+		table, ok := values[part].(map[string]any)
+		if !ok {
+			table = map[string]any{}
+			values[part] = table
+		}
+		values = table
+	}
+	values[path[len(path)-1]] = value
+}
+
+// toValues converts cfg to the generic form files are parsed into.
+func toValues(cfg *Config) (map[string]any, error) {
+	data, err := toml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	values := map[string]any{}
+	if err := toml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	return values, nil
+}
+
+// saveLayers writes cfg back across its layers. A setting goes to the last
+// layer that defines it; new credentials and device registrations go to the
+// last overlay so they stay off the shared base; anything else new goes to
+// the main file. Files whose contents wouldn't change are left alone.
+func saveLayers(cfg *Config) error {
+	full, err := toValues(cfg)
+	if err != nil {
+		return err
+	}
+	public, err := toValues(cfg.WithoutSecrets())
+	if err != nil {
+		return err
+	}
+	publicLeaves := leaves(public)
+
+	owner := map[string]int{}
+	for i, l := range cfg.layers {
+		for key := range leaves(l.values) {
+			owner[key] = i
+		}
+	}
+	baseLeaves := leaves(cfg.layers[0].values)
+
+	outputs := make([]map[string]any, len(cfg.layers))
+	for i := range outputs {
+		outputs[i] = map[string]any{}
+	}
+	for key, value := range leaves(full) {
+		i, ok := owner[key]
+		if !ok {
+			i = 0
+			if public, ok := publicLeaves[key]; !ok || !reflect.DeepEqual(public, value) {
+				i = len(cfg.layers) - 1
+			}
+		}
+		setLeaf(outputs[i], key, value)
+		// An overlay's value wins; keep the base file's own value as it was.
+		if baseValue, ok := baseLeaves[key]; ok && i != 0 {
+			setLeaf(outputs[0], key, baseValue)
+		}
+	}
+
+	for i, l := range cfg.layers {
+		before, err := toml.Marshal(l.values)
+		if err != nil {
+			return fmt.Errorf("encoding config: %w", err)
+		}
+		after, err := toml.Marshal(outputs[i])
+		if err != nil {
+			return fmt.Errorf("encoding config: %w", err)
+		}
+		if bytes.Equal(before, after) {
+			continue
+		}
+		if err := writeFile(l.path, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
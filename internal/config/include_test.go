@@ -0,0 +1,110 @@
+// ABOUTME: Tests for layered config files.
+// ABOUTME: Covers include lists, config.d overlays, and writing settings back to their files.
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readConfigFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestLoadIncludes(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.toml")
+	local := filepath.Join(dir, "local.toml")
+	overlay := filepath.Join(dir, "config.d", "10-theme.toml")
+	writeConfigFile(t, base, "include = [\"local.toml\", \"missing.toml\"]\napp_token = \"shared-token\"\ndefault_priority = 0\n\n[mcp]\nread_only = true\n")
+	writeConfigFile(t, local, "user_key = \"local-user\"\ndevice_id = \"local-device\"\ndefault_priority = 1\n\n[mcp]\nauto_ack = false\n")
+	writeConfigFile(t, overlay, "theme = \"dark\"\ndefault_priority = 2\n")
+
+	cfg, err := Load(base)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.AppToken != "shared-token" || cfg.UserKey != "local-user" || cfg.DeviceID != "local-device" || cfg.Theme != "dark" {
+		t.Errorf("merged config = %+v", cfg)
+	}
+	if cfg.DefaultPriority != 2 {
+		t.Errorf("DefaultPriority = %d, want 2 from config.d (last layer wins)", cfg.DefaultPriority)
+	}
+	if !cfg.MCP.ReadOnly || cfg.MCPAutoAck() {
+		t.Errorf("[mcp] tables not merged key by key: %+v", cfg.MCP)
+	}
+	want := []string{base, local, filepath.Join(dir, "missing.toml"), overlay}
+	if !slices.Equal(cfg.Files(), want) {
+		t.Errorf("Files() = %v, want %v", cfg.Files(), want)
+	}
+}
+
+func TestIncludeOnlyInMainConfig(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.toml")
+	writeConfigFile(t, base, "include = [\"local.toml\"]\n")
+	writeConfigFile(t, filepath.Join(dir, "local.toml"), "include = [\"other.toml\"]\n")
+
+	if _, err := Load(base); err == nil || !strings.Contains(err.Error(), "only allowed in the main config") {
+		t.Errorf("Load() error = %v, want nested include rejected", err)
+	}
+}
+
+func TestSaveIncludes(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.toml")
+	local := filepath.Join(dir, "local.toml")
+	writeConfigFile(t, base, "include = [\"local.toml\"]\napp_token = \"shared-token\"\ndefault_priority = 0\n")
+	writeConfigFile(t, local, "device_id = \"old-device\"\ndefault_priority = 1\n")
+
+	cfg, err := Load(base)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	cfg.DeviceID = "new-device" // owned by local.toml
+	cfg.DeviceSecret = "secret" // new credential: goes to the overlay
+	cfg.DefaultPriority = -1    // owned by local.toml
+	cfg.DedupeWindow = "5m"     // new shared setting: goes to the base
+	if err := Save(base, cfg); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	baseData := readConfigFile(t, base)
+	for _, leaked := range []string{"new-device", "secret", "-1"} {
+		if strings.Contains(baseData, leaked) {
+			t.Errorf("base config gained %q:\n%s", leaked, baseData)
+		}
+	}
+	if !strings.Contains(baseData, "dedupe_window = '5m'") || !strings.Contains(baseData, "default_priority = 0") {
+		t.Errorf("base config missing its settings:\n%s", baseData)
+	}
+
+	reloaded, err := Load(base)
+	if err != nil {
+		t.Fatalf("Load() after save: %v", err)
+	}
+	if reloaded.DeviceID != "new-device" || reloaded.DeviceSecret != "secret" || reloaded.DefaultPriority != -1 || reloaded.DedupeWindow != "5m" || reloaded.AppToken != "shared-token" {
+		t.Errorf("reloaded config = %+v", reloaded)
+	}
+	if localData := readConfigFile(t, local); !strings.Contains(localData, "secret") {
+		t.Errorf("overlay missing the new credential:\n%s", localData)
+	}
+}
@@ -0,0 +1,89 @@
+// ABOUTME: Background database maintenance run by push daemon.
+// ABOUTME: Parses [maintenance] into an interval, an idle wait, and an optional retention period.
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaintenanceInterval = 24 * time.Hour
+	minMaintenanceInterval     = time.Hour
+	defaultMaintenanceIdle     = 10 * time.Minute
+)
+
+// MaintenanceConfig tunes how push daemon optimizes, vacuums, and prunes the
+// database.
+type MaintenanceConfig struct {
+	// Disabled turns background maintenance off.
+	Disabled bool `toml:"disabled,omitempty"`
+	// Interval is how often maintenance runs, e.g. "24h".
+	Interval string `toml:"interval,omitempty"`
+	// Idle is how long nothing must have been received or sent before
+	// maintenance starts, e.g. "10m".
+	Idle string `toml:"idle,omitempty"`
+	// Retention deletes received messages and logged sends older than this,
+	// e.g. "365d"; unset keeps everything.
+	Retention string `toml:"retention,omitempty"`
+}
+
+// MaintenanceSchedule is a parsed [maintenance] section.
+type MaintenanceSchedule struct {
+	Interval time.Duration
+	Idle     time.Duration
+	// Retention is zero when history is kept forever.
+	Retention time.Duration
+}
+
+// MaintenanceSchedule parses [maintenance]. It reports false when maintenance
+// is disabled.
+func (c *Config) MaintenanceSchedule() (MaintenanceSchedule, bool, error) {
+	m := MaintenanceSchedule{Interval: defaultMaintenanceInterval, Idle: defaultMaintenanceIdle}
+	if c == nil {
+		return m, true, nil
+	}
+	mc := c.Maintenance
+	if mc.Disabled {
+		return MaintenanceSchedule{}, false, nil
+	}
+	if mc.Interval != "" {
+		interval, err := time.ParseDuration(mc.Interval)
+		if err != nil || interval < minMaintenanceInterval {
+			return MaintenanceSchedule{}, false, invalid(fmt.Errorf("[maintenance] interval must be a duration of at least 1h, got %q", mc.Interval))
+		}
+		m.Interval = interval
+	}
+	if mc.Idle != "" {
+		idle, err := time.ParseDuration(mc.Idle)
+		if err != nil || idle < 0 {
+			return MaintenanceSchedule{}, false, invalid(fmt.Errorf("[maintenance] idle must be a duration like 10m, got %q", mc.Idle))
+		}
+		m.Idle = idle
+	}
+	if mc.Retention != "" {
+		retention, ok := parseDays(mc.Retention)
+		if !ok || retention < 24*time.Hour {
+			return MaintenanceSchedule{}, false, invalid(fmt.Errorf("[maintenance] retention must be at least a day, like 90d or 52w, got %q", mc.Retention))
+		}
+		m.Retention = retention
+	}
+	return m, true, nil
+}
+
+// parseDays parses a Go duration or a whole number of days ("90d") or weeks ("52w").
+func parseDays(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if n, ok := strings.CutSuffix(value, "d"); ok {
+		days, err := strconv.Atoi(n)
+		return time.Duration(days) * 24 * time.Hour, err == nil
+	}
+	if n, ok := strings.CutSuffix(value, "w"); ok {
+		weeks, err := strconv.Atoi(n)
+		return time.Duration(weeks) * 7 * 24 * time.Hour, err == nil
+	}
+	d, err := time.ParseDuration(value)
+	return d, err == nil
+}
@@ -0,0 +1,48 @@
+// ABOUTME: Tests for the background maintenance schedule.
+// ABOUTME: Checks defaults, day and week retention periods, and rejecting bad settings.
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceSchedule(t *testing.T) {
+	defaults := MaintenanceSchedule{Interval: 24 * time.Hour, Idle: 10 * time.Minute}
+	if got, ok, err := (&Config{}).MaintenanceSchedule(); !ok || err != nil || got != defaults {
+		t.Errorf("no [maintenance] = %+v, %v, %v; want %+v", got, ok, err, defaults)
+	}
+	if _, ok, err := (&Config{Maintenance: MaintenanceConfig{Disabled: true, Interval: "bad"}}).MaintenanceSchedule(); ok || err != nil {
+		t.Errorf("disabled = %v, %v; want none", ok, err)
+	}
+
+	for name, mc := range map[string]MaintenanceConfig{
+		"short interval":     {Interval: "30m"},
+		"bad idle":           {Idle: "soon"},
+		"negative idle":      {Idle: "-1m"},
+		"bad retention":      {Retention: "forever"},
+		"short retention":    {Retention: "12h"},
+		"partial days":       {Retention: "1.5d"},
+		"negative retention": {Retention: "-30d"},
+	} {
+		if _, _, err := (&Config{Maintenance: mc}).MaintenanceSchedule(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: MaintenanceSchedule() error = %v, want ErrInvalid", name, err)
+		}
+	}
+
+	tests := []struct {
+		mc   MaintenanceConfig
+		want MaintenanceSchedule
+	}{
+		{MaintenanceConfig{Retention: "90d"}, MaintenanceSchedule{Interval: 24 * time.Hour, Idle: 10 * time.Minute, Retention: 90 * 24 * time.Hour}},
+		{MaintenanceConfig{Interval: "6h", Idle: "0s", Retention: "2w"}, MaintenanceSchedule{Interval: 6 * time.Hour, Retention: 14 * 24 * time.Hour}},
+		{MaintenanceConfig{Retention: "720h"}, MaintenanceSchedule{Interval: 24 * time.Hour, Idle: 10 * time.Minute, Retention: 30 * 24 * time.Hour}},
+	}
+	for _, tt := range tests {
+		got, ok, err := (&Config{Maintenance: tt.mc}).MaintenanceSchedule()
+		if err != nil || !ok || got != tt.want {
+			t.Errorf("MaintenanceSchedule(%+v) = %+v, %v, %v; want %+v", tt.mc, got, ok, err, tt.want)
+		}
+	}
+}
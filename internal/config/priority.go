@@ -0,0 +1,69 @@
+// ABOUTME: Priority rules that raise or lower received messages by app or title.
+// ABOUTME: Gives chatty sources, such as cron mail, a local priority of their own.
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PriorityRule sets the local priority of received messages from App, with
+// a title matching the Title regular expression, or both.
+type PriorityRule struct {
+	App      string `toml:"app,omitempty"`
+	Title    string `toml:"title,omitempty"`
+	Priority int    `toml:"priority"`
+}
+
+// LocalPriorities are the compiled priority_rules.
+type LocalPriorities []localPriority
+
+type localPriority struct {
+	app      string
+	title    *regexp.Regexp
+	priority int
+}
+
+// LocalPriorities compiles priority_rules, checking each has something to
+// match and a priority between -2 and 2.
+func (c *Config) LocalPriorities() (LocalPriorities, error) {
+	if c == nil {
+		return nil, nil
+	}
+	rules := make(LocalPriorities, 0, len(c.PriorityRules))
+	for i, rule := range c.PriorityRules {
+		if rule.App == "" && rule.Title == "" {
+			return nil, invalid(fmt.Errorf("priority_rules[%d]: set app, title, or both", i))
+		}
+		if rule.Priority < -2 || rule.Priority > 2 {
+			return nil, invalid(fmt.Errorf("priority_rules[%d]: priority must be between -2 and 2", i))
+		}
+		compiled := localPriority{app: rule.App, priority: rule.Priority}
+		if rule.Title != "" {
+			re, err := regexp.Compile(rule.Title)
+			if err != nil {
+				return nil, invalid(fmt.Errorf("priority_rules[%d]: title: %w", i, err))
+			}
+			compiled.title = re
+		}
+		rules = append(rules, compiled)
+	}
+	return rules, nil
+}
+
+// For returns the local priority of a message from app with title, which
+// arrived with priority: that of the first matching rule, or priority itself
+// when none match. App names match ignoring case.
+func (r LocalPriorities) For(app, title string, priority int) int {
+	for _, rule := range r {
+		if rule.app != "" && !strings.EqualFold(rule.app, app) {
+			continue
+		}
+		if rule.title != nil && !rule.title.MatchString(title) {
+			continue
+		}
+		return rule.priority
+	}
+	return priority
+}
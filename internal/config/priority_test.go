@@ -0,0 +1,76 @@
+// ABOUTME: Tests for priority rules.
+// ABOUTME: Checks app and title matching, rule order, and invalid rules.
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+func TestLocalPriorities(t *testing.T) {
+	var cfg Config
+	if err := toml.Unmarshal([]byte(`
+[[priority_rules]]
+app = "cron"
+priority = -2
+
+[[priority_rules]]
+title = "(?i)disk (full|failing)"
+priority = 2
+
+[[priority_rules]]
+app = "backups"
+title = "^OK"
+priority = -1
+`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := cfg.LocalPriorities()
+	if err != nil {
+		t.Fatalf("LocalPriorities() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		app      string
+		title    string
+		priority int
+		want     int
+	}{
+		{name: "app ignoring case", app: "Cron", title: "daily job", priority: 0, want: -2},
+		{name: "first rule wins", app: "cron", title: "Disk full", priority: 0, want: -2},
+		{name: "title", app: "nas", title: "DISK FULL on /data", priority: 0, want: 2},
+		{name: "app and title", app: "backups", title: "OK: 3 files", priority: 1, want: -1},
+		{name: "app without title", app: "backups", title: "Failed", priority: 1, want: 1},
+		{name: "no match", app: "nas", title: "Scrub finished", priority: 1, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.For(tt.app, tt.title, tt.priority); got != tt.want {
+				t.Errorf("For(%q, %q, %d) = %d, want %d", tt.app, tt.title, tt.priority, got, tt.want)
+			}
+		})
+	}
+
+	if got := LocalPriorities(nil).For("cron", "x", 1); got != 1 {
+		t.Errorf("no rules: For() = %d, want the priority unchanged", got)
+	}
+}
+
+func TestLocalPrioritiesInvalid(t *testing.T) {
+	for name, rule := range map[string]PriorityRule{
+		"nothing to match": {Priority: -1},
+		"priority range":   {App: "cron", Priority: 3},
+		"bad title":        {Title: "(unclosed", Priority: -1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := (&Config{PriorityRules: []PriorityRule{rule}}).LocalPriorities()
+			var invalid *invalidError
+			if !errors.As(err, &invalid) {
+				t.Errorf("LocalPriorities() error = %v, want an invalid config error", err)
+			}
+		})
+	}
+}
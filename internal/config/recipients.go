@@ -0,0 +1,65 @@
+// ABOUTME: Recipients for sends to more than the configured user key.
+// ABOUTME: Resolves --user lists of [recipients] names and raw user or group keys.
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// userKeyPattern matches Pushover user and group keys.
+var userKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]{30}$`)
+
+// Recipient is one user or group a notification is sent to. Name is the
+// [recipients] entry, or the key itself when given directly.
+type Recipient struct {
+	Name string
+	Key  string
+}
+
+// ResolveRecipients turns a comma-separated list of [recipients] names and
+// raw keys into recipients, dropping repeats. An empty list returns none,
+// meaning the configured user_key.
+func (c *Config) ResolveRecipients(list string) ([]Recipient, error) {
+	var recipients []Recipient
+	seen := map[string]bool{}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		recipient := Recipient{Name: item, Key: item}
+		if c != nil {
+			if key, ok := c.Recipients[item]; ok {
+				recipient.Key = key
+			}
+		}
+		if !userKeyPattern.MatchString(recipient.Key) {
+			if recipient.Key != item {
+				return nil, fmt.Errorf("recipient %q has an invalid user key", item)
+			}
+			return nil, fmt.Errorf("unknown recipient %q (add it under [recipients] or pass a 30-character user key)", item)
+		}
+		if seen[recipient.Key] {
+			continue
+		}
+		seen[recipient.Key] = true
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
+
+// RecipientNames lists the [recipients] names in order.
+func (c *Config) RecipientNames() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.Recipients))
+	for name := range c.Recipients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
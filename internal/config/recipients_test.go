@@ -0,0 +1,39 @@
+// ABOUTME: Tests for resolving send recipients.
+// ABOUTME: Covers named recipients, raw keys, repeats, and unknown names.
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResolveRecipients(t *testing.T) {
+	const (
+		aliceKey = "uQiRzpo4DXghDmr9QzzfQu27cmVRsG"
+		teamKey  = "gznej3rKEVAvPUxu9vvNnqpmZpokzF"
+	)
+	cfg := &Config{Recipients: map[string]string{"alice": aliceKey, "broken": "short"}}
+
+	got, err := cfg.ResolveRecipients(" alice, " + teamKey + ",," + aliceKey)
+	if err != nil {
+		t.Fatalf("ResolveRecipients() error: %v", err)
+	}
+	want := []Recipient{{Name: "alice", Key: aliceKey}, {Name: teamKey, Key: teamKey}}
+	if !slices.Equal(got, want) {
+		t.Errorf("ResolveRecipients() = %+v, want %+v", got, want)
+	}
+
+	if got, err := cfg.ResolveRecipients(""); err != nil || got != nil {
+		t.Errorf("ResolveRecipients(\"\") = %v, %v; want none", got, err)
+	}
+	if _, err := cfg.ResolveRecipients("bob"); err == nil || !strings.Contains(err.Error(), "unknown recipient") {
+		t.Errorf("ResolveRecipients(bob) error = %v, want unknown recipient", err)
+	}
+	if _, err := cfg.ResolveRecipients("broken"); err == nil || !strings.Contains(err.Error(), "invalid user key") {
+		t.Errorf("ResolveRecipients(broken) error = %v, want invalid key", err)
+	}
+	if names := cfg.RecipientNames(); !slices.Equal(names, []string{"alice", "broken"}) {
+		t.Errorf("RecipientNames() = %v", names)
+	}
+}
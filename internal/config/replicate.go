@@ -0,0 +1,120 @@
+// ABOUTME: Replication target for the history database, copied by push daemon.
+// ABOUTME: Parses [replicate] into a directory or S3 location with an interval and retention.
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Replication kinds.
+const (
+	ReplicateFile = "file"
+	ReplicateS3   = "s3"
+)
+
+const (
+	defaultReplicateInterval = 15 * time.Minute
+	minReplicateInterval     = time.Minute
+	defaultReplicateRetain   = 24
+)
+
+// ReplicateConfig has push daemon copy the database to a directory or S3.
+type ReplicateConfig struct {
+	// Target is a directory ("file:///mnt/nas/push" or an absolute path) or
+	// "s3://bucket/prefix"; unset replicates nothing.
+	Target string `toml:"target,omitempty"`
+	// Interval is how often to check for changes to copy, e.g. "15m".
+	Interval string `toml:"interval,omitempty"`
+	// Retain is how many replicas to keep; older ones are deleted.
+	Retain int `toml:"retain,omitempty"`
+	// Region and Endpoint locate the S3 bucket; Endpoint is for
+	// S3-compatible stores such as MinIO or R2.
+	Region   string `toml:"region,omitempty"`
+	Endpoint string `toml:"endpoint,omitempty"`
+}
+
+// Replication is a parsed [replicate] section.
+type Replication struct {
+	Kind string
+	// Dir is the directory for file replication.
+	Dir string
+	// Bucket and Prefix are the S3 location; Prefix has no trailing slash.
+	Bucket   string
+	Prefix   string
+	Region   string
+	Endpoint string
+	Interval time.Duration
+	Retain   int
+}
+
+// Replication parses [replicate]. It reports false when target is unset.
+func (c *Config) Replication() (Replication, bool, error) {
+	if c == nil || strings.TrimSpace(c.Replicate.Target) == "" {
+		return Replication{}, false, nil
+	}
+	rc := c.Replicate
+	r := Replication{Interval: defaultReplicateInterval, Retain: defaultReplicateRetain, Region: rc.Region, Endpoint: strings.TrimRight(rc.Endpoint, "/")}
+	if rc.Interval != "" {
+		interval, err := time.ParseDuration(rc.Interval)
+		if err != nil || interval < minReplicateInterval {
+			return Replication{}, false, invalid(fmt.Errorf("[replicate] interval must be a duration of at least 1m, got %q", rc.Interval))
+		}
+		r.Interval = interval
+	}
+	if rc.Retain < 0 {
+		return Replication{}, false, invalid(fmt.Errorf("[replicate] retain cannot be negative"))
+	}
+	if rc.Retain > 0 {
+		r.Retain = rc.Retain
+	}
+
+	if err := r.setTarget(rc.Target); err != nil {
+		return Replication{}, false, invalid(err)
+	}
+	return r, true, nil
+}
+
+// setTarget fills in the kind and location from a [replicate] target,
+// checking the endpoint too for S3.
+func (r *Replication) setTarget(raw string) error {
+	target := strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(target, "s3://"):
+		u, err := url.Parse(target)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("[replicate] target %q must look like s3://bucket/prefix", raw)
+		}
+		r.Kind, r.Bucket, r.Prefix = ReplicateS3, u.Host, strings.Trim(u.Path, "/")
+		if r.Endpoint != "" {
+			if e, err := url.Parse(r.Endpoint); err != nil || (e.Scheme != "http" && e.Scheme != "https") || e.Host == "" {
+				return fmt.Errorf("[replicate] endpoint %q must be an http or https URL", r.Endpoint)
+			}
+		}
+	case strings.HasPrefix(target, "file://"):
+		u, err := url.Parse(target)
+		if err != nil || u.Host != "" || !filepath.IsAbs(u.Path) {
+			return fmt.Errorf("[replicate] target %q must look like file:///absolute/dir", raw)
+		}
+		r.Kind, r.Dir = ReplicateFile, filepath.Clean(u.Path)
+	case filepath.IsAbs(target):
+		r.Kind, r.Dir = ReplicateFile, filepath.Clean(target)
+	default:
+		return fmt.Errorf("[replicate] target must be an absolute directory, file:///dir, or s3://bucket/prefix, got %q", raw)
+	}
+	return nil
+}
+
+// String names the target, e.g. "s3://bucket/prefix" or "/mnt/nas/push".
+func (r Replication) String() string {
+	if r.Kind == ReplicateS3 {
+		if r.Prefix == "" {
+			return "s3://" + r.Bucket
+		}
+		return "s3://" + r.Bucket + "/" + r.Prefix
+	}
+	return r.Dir
+}
@@ -0,0 +1,67 @@
+// ABOUTME: Tests for the replication target.
+// ABOUTME: Checks parsing [replicate] into file and S3 locations and rejecting bad settings.
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReplication(t *testing.T) {
+	if _, ok, err := (&Config{}).Replication(); ok || err != nil {
+		t.Errorf("no [replicate] target = %v, %v; want none", ok, err)
+	}
+	for name, rc := range map[string]ReplicateConfig{
+		"relative dir":    {Target: "backups/push"},
+		"file host":       {Target: "file://nas/push"},
+		"no bucket":       {Target: "s3:///push"},
+		"short interval":  {Target: "/mnt/push", Interval: "10s"},
+		"bad interval":    {Target: "/mnt/push", Interval: "often"},
+		"negative retain": {Target: "/mnt/push", Retain: -1},
+		"bad endpoint":    {Target: "s3://bucket", Endpoint: "minio:9000"},
+	} {
+		if _, _, err := (&Config{Replicate: rc}).Replication(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: Replication() error = %v, want ErrInvalid", name, err)
+		}
+	}
+
+	tests := []struct {
+		rc   ReplicateConfig
+		want Replication
+		name string
+	}{
+		{
+			ReplicateConfig{Target: "/mnt/nas/push/"},
+			Replication{Kind: ReplicateFile, Dir: "/mnt/nas/push", Interval: 15 * time.Minute, Retain: 24},
+			"/mnt/nas/push",
+		},
+		{
+			ReplicateConfig{Target: "file:///mnt/nas/push", Interval: "1h", Retain: 3},
+			Replication{Kind: ReplicateFile, Dir: "/mnt/nas/push", Interval: time.Hour, Retain: 3},
+			"/mnt/nas/push",
+		},
+		{
+			ReplicateConfig{Target: "s3://backups/push/laptop/", Region: "eu-west-1"},
+			Replication{Kind: ReplicateS3, Bucket: "backups", Prefix: "push/laptop", Region: "eu-west-1", Interval: 15 * time.Minute, Retain: 24},
+			"s3://backups/push/laptop",
+		},
+		{
+			ReplicateConfig{Target: "s3://backups", Endpoint: "http://localhost:9000/"},
+			Replication{Kind: ReplicateS3, Bucket: "backups", Endpoint: "http://localhost:9000", Interval: 15 * time.Minute, Retain: 24},
+			"s3://backups",
+		},
+	}
+	for _, tt := range tests {
+		got, ok, err := (&Config{Replicate: tt.rc}).Replication()
+		if err != nil || !ok {
+			t.Fatalf("Replication(%+v) = %v, %v", tt.rc, ok, err)
+		}
+		if got != tt.want {
+			t.Errorf("Replication(%+v) = %+v, want %+v", tt.rc, got, tt.want)
+		}
+		if got.String() != tt.name {
+			t.Errorf("%+v: String() = %q, want %q", tt.rc, got.String(), tt.name)
+		}
+	}
+}
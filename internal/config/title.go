@@ -0,0 +1,42 @@
+// ABOUTME: Title templates that tag every sent notification, e.g. with the host.
+// ABOUTME: Renders title_template with text/template for fleet-wide prefixes.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// TitleData is what title_template can refer to.
+type TitleData struct {
+	// Title is the title given for the notification, possibly empty.
+	Title string
+	// Hostname is this machine's name as reported by the OS.
+	Hostname string
+}
+
+// ApplyTitleTemplate renders title_template around title, for example
+// "[{{.Hostname}}] {{.Title}}". Without a template the title is unchanged.
+// Surrounding whitespace is trimmed so an empty title doesn't leave a
+// dangling separator.
+func (c *Config) ApplyTitleTemplate(title string) (string, error) {
+	if c == nil || c.TitleTemplate == "" {
+		return title, nil
+	}
+	tmpl, err := template.New("title_template").Option("missingkey=error").Parse(c.TitleTemplate)
+	if err != nil {
+		return "", invalid(fmt.Errorf("parse title_template: %w", err))
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, TitleData{Title: title, Hostname: host}); err != nil {
+		return "", invalid(fmt.Errorf("render title_template: %w", err))
+	}
+	return strings.TrimSpace(b.String()), nil
+}
@@ -0,0 +1,41 @@
+// ABOUTME: Tests for title templates.
+// ABOUTME: Checks hostname tagging, empty titles, and template errors.
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestApplyTitleTemplate(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skipf("no hostname: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		title    string
+		want     string
+		wantErr  bool
+	}{
+		{name: "no template", title: "Backup", want: "Backup"},
+		{name: "hostname prefix", template: "[{{.Hostname}}] {{.Title}}", title: "Backup", want: "[" + host + "] Backup"},
+		{name: "suffix", template: "{{.Title}} ({{.Hostname}})", title: "Backup", want: "Backup (" + host + ")"},
+		{name: "empty title", template: "[{{.Hostname}}] {{.Title}}", want: "[" + host + "]"},
+		{name: "unknown field", template: "{{.Region}} {{.Title}}", title: "Backup", wantErr: true},
+		{name: "bad syntax", template: "{{.Title", title: "Backup", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&Config{TitleTemplate: tt.template}).ApplyTitleTemplate(tt.title)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyTitleTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyTitleTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
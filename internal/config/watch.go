@@ -0,0 +1,108 @@
+// ABOUTME: Watches the config file so long-running commands can reload it.
+// ABOUTME: Follows editors that replace files, include overlays, and config.d.
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce merges the burst of events one save produces.
+const watchDebounce = 200 * time.Millisecond
+
+// Watch calls onChange after the file at path, one of its include files, or
+// a config.d/*.toml overlay is written, created, or replaced, until ctx is
+// done. It watches the parent directories, so files may be missing at first
+// or renamed over by an editor.
+func Watch(ctx context.Context, path string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create config watcher: %w", err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	path = filepath.Clean(path)
+	w := &configWatch{
+		watcher:    watcher,
+		path:       path,
+		overlayDir: filepath.Join(filepath.Dir(path), includeDir),
+		files:      map[string]bool{},
+	}
+	if err := w.track(); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if w.relevant(event) {
+				timer.Reset(watchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("watch config: %w", err)
+		case <-timer.C:
+			if err := w.track(); err != nil {
+				return err
+			}
+			onChange()
+		}
+	}
+}
+
+// configWatch is the set of files a Watch follows.
+type configWatch struct {
+	watcher    *fsnotify.Watcher
+	path       string
+	overlayDir string
+	files      map[string]bool
+}
+
+// track (re)reads the include list so newly added overlays are watched too.
+func (w *configWatch) track() error {
+	names := []string{w.path}
+	if cfg, err := Load(w.path); err == nil {
+		names = append(names, cfg.Files()...)
+	}
+	for _, name := range names {
+		name = filepath.Clean(name)
+		if w.files[name] {
+			continue
+		}
+		if err := w.watcher.Add(filepath.Dir(name)); err != nil {
+			return fmt.Errorf("watch config directory: %w", err)
+		}
+		w.files[name] = true
+	}
+	if info, err := os.Stat(w.overlayDir); err == nil && info.IsDir() {
+		_ = w.watcher.Add(w.overlayDir)
+	}
+	return nil
+}
+
+// relevant reports whether event writes or creates one of the watched files,
+// an overlay, or the overlay directory itself.
+func (w *configWatch) relevant(event fsnotify.Event) bool {
+	name := filepath.Clean(event.Name)
+	overlay := filepath.Dir(name) == w.overlayDir && filepath.Ext(name) == ".toml"
+	if !w.files[name] && !overlay && name != w.overlayDir {
+		return false
+	}
+	return event.Has(fsnotify.Write | fsnotify.Create)
+}
@@ -0,0 +1,101 @@
+// ABOUTME: Tests for config file watching.
+// ABOUTME: Covers in-place writes and editors that rename a new file over the old one.
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte("default_priority = 0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{}, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, path, func() { changes <- struct{}{} })
+	}()
+	// Give the watcher a moment to register the directory.
+	time.Sleep(100 * time.Millisecond)
+
+	wait := func(what string) {
+		t.Helper()
+		select {
+		case <-changes:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no change reported after %s", what)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("default_priority = 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	wait("writing in place")
+
+	tmp := filepath.Join(dir, "config.toml.tmp")
+	if err := os.WriteFile(tmp, []byte("default_priority = 2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	wait("renaming over it")
+
+	if err := os.WriteFile(filepath.Join(dir, "other.toml"), []byte("x = 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+		t.Error("change reported for an unrelated file")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch() error: %v", err)
+	}
+}
+
+func TestWatchIncludes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	local := filepath.Join(t.TempDir(), "local.toml")
+	if err := os.WriteFile(path, []byte("include = [\""+filepath.ToSlash(local)+"\"]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{}, 10)
+	go func() { _ = Watch(ctx, path, func() { changes <- struct{}{} }) }()
+	time.Sleep(100 * time.Millisecond)
+
+	for _, file := range []string{local, filepath.Join(dir, "config.d", "extra.toml")} {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		// Let the watcher pick up a newly created config.d, which itself
+		// counts as a change.
+		time.Sleep(400 * time.Millisecond)
+		for len(changes) > 0 {
+			<-changes
+		}
+		if err := os.WriteFile(file, []byte("theme = \"dark\"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-changes:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no change reported after writing %s", file)
+		}
+	}
+}
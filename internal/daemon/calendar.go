@@ -0,0 +1,142 @@
+// ABOUTME: Job that syncs calendar feeds and alerts shortly before their events.
+// ABOUTME: Refetches each feed every few minutes and remembers alerted events in the store.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/harper/push/internal/calendar"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/notify"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// CalendarRefresh is how often a calendar feed is fetched again. Alerts are
+// checked on every run against the last copy.
+const CalendarRefresh = 15 * time.Minute
+
+// cachedFeed is the last copy of a feed the job fetched.
+type cachedFeed struct {
+	url     string
+	events  []calendar.Event
+	fetched time.Time
+}
+
+// CalendarJob returns a job that syncs calendar feeds and sends their alerts.
+func CalendarJob(store *db.Store, notifier notify.Notifier, client *http.Client, every time.Duration, log *slog.Logger) Job {
+	feeds := map[string]*cachedFeed{}
+	return Job{
+		Name:  "calendars",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			return checkCalendars(ctx, store, notifier, client, feeds, time.Now(), log)
+		},
+	}
+}
+
+func checkCalendars(ctx context.Context, store *db.Store, notifier notify.Notifier, client *http.Client, feeds map[string]*cachedFeed, now time.Time, log *slog.Logger) error {
+	calendars, err := store.ListCalendars(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	current := map[string]bool{}
+	for _, cal := range calendars {
+		current[cal.Name] = true
+		feed := feeds[cal.Name]
+		if feed == nil || feed.url != cal.URL || now.Sub(feed.fetched) >= CalendarRefresh-monitorSlack {
+			if feed == nil || feed.url != cal.URL {
+				// Without a copy to fall back on, fetch in full.
+				feed = &cachedFeed{url: cal.URL}
+				cal.ETag, cal.LastModified = "", ""
+			}
+			if err := syncCalendar(ctx, store, client, &cal, feed, now); err != nil {
+				errs = append(errs, fmt.Errorf("calendar %q: %w", cal.Name, err))
+			}
+			feeds[cal.Name] = feed
+			log.Debug("calendar synced", "calendar", cal.Name, "events", cal.Events, "error", cal.LastError)
+		}
+		if err := sendCalendarAlerts(ctx, store, notifier, cal, feed.events, now, log); err != nil {
+			errs = append(errs, fmt.Errorf("calendar %q: %w", cal.Name, err))
+		}
+	}
+	for name := range feeds {
+		if !current[name] {
+			delete(feeds, name)
+		}
+	}
+
+	if err := store.PruneCalendarAlerts(ctx, now.Add(-24*time.Hour)); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// syncCalendar fetches cal's feed into feed and records the outcome. On a
+// failed fetch the previous copy stays in use.
+func syncCalendar(ctx context.Context, store *db.Store, client *http.Client, cal *db.CalendarRecord, feed *cachedFeed, now time.Time) error {
+	feed.fetched = now
+	fetched, fetchErr := calendar.Fetch(ctx, client, cal.URL, cal.ETag, cal.LastModified)
+	cal.LastSynced = now
+	if fetchErr != nil {
+		cal.LastError = fetchErr.Error()
+	} else {
+		cal.LastError = ""
+		cal.ETag, cal.LastModified = fetched.ETag, fetched.LastModified
+		if !fetched.NotModified {
+			feed.events = fetched.Events
+			cal.Events = len(fetched.Events)
+		}
+	}
+	if err := store.UpdateCalendarSync(ctx, *cal); err != nil {
+		return err
+	}
+	return fetchErr
+}
+
+func sendCalendarAlerts(ctx context.Context, store *db.Store, notifier notify.Notifier, cal db.CalendarRecord, events []calendar.Event, now time.Time, log *slog.Logger) error {
+	var errs []error
+	for _, o := range calendar.Alerts(events, now, cal.Lead) {
+		key := o.Key()
+		done, err := store.CalendarAlerted(ctx, cal.Name, key)
+		if err != nil {
+			return err
+		}
+		if done {
+			continue
+		}
+
+		params := pushover.SendParams{Priority: cal.Priority}
+		params.Title, params.Message = calendar.Notification(o, now)
+		if o.URL != "" {
+			params.URL, params.URLTitle = o.URL, "Open event"
+		}
+		resp, err := notifier.Send(ctx, params)
+		if err != nil {
+			// Left unmarked, so the next run tries again while it's still ahead.
+			errs = append(errs, fmt.Errorf("alert %q: %w", o.Summary, err))
+			continue
+		}
+		log.Info("calendar alert sent", "calendar", cal.Name, "event", o.Summary, "starts", o.Start, "request_id", resp.Request)
+
+		if err := store.MarkCalendarAlerted(ctx, cal.Name, key, o.Start, now); err != nil {
+			return err
+		}
+		if err := store.LogSent(ctx, db.SentRecord{
+			Message:   params.Message,
+			Title:     params.Title,
+			Priority:  params.Priority,
+			SentAt:    now,
+			RequestID: resp.Request,
+		}); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
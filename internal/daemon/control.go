@@ -0,0 +1,159 @@
+// ABOUTME: Local control socket for a running daemon.
+// ABOUTME: Other push invocations use it to query status, poll, reload, or stop.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Commands accepted on the control socket.
+const (
+	CommandStatus = "status"
+	CommandPoll   = "poll"
+	CommandReload = "reload"
+	CommandStop   = "stop"
+)
+
+// controlTimeout bounds one request/response exchange on the socket.
+const controlTimeout = 10 * time.Second
+
+// Request is one control command.
+type Request struct {
+	Command string `json:"command"`
+}
+
+// Response answers a Request. Error is set when OK is false.
+type Response struct {
+	OK     bool    `json:"ok"`
+	Error  string  `json:"error,omitempty"`
+	Status *Status `json:"status,omitempty"`
+}
+
+// Status describes a running daemon.
+type Status struct {
+	PID       int         `json:"pid"`
+	StartedAt time.Time   `json:"started_at"`
+	Config    string      `json:"config,omitempty"`
+	Reloaded  time.Time   `json:"reloaded_at,omitzero"`
+	Jobs      []JobStatus `json:"jobs"`
+}
+
+// ControlHandler answers control requests.
+type ControlHandler func(ctx context.Context, req Request) Response
+
+// ControlServer serves control requests on a Unix-domain socket. Windows 10
+// and later support these too, so the same transport is used everywhere.
+type ControlServer struct {
+	path   string
+	ln     net.Listener
+	handle ControlHandler
+}
+
+// ErrDaemonRunning is returned by ListenControl when another daemon already
+// answers on the socket.
+var ErrDaemonRunning = errors.New("daemon already running")
+
+// ListenControl creates the socket at path, replacing a stale one left by a
+// daemon that didn't shut down cleanly. Only the current user can connect.
+func ListenControl(path string, handle ControlHandler) (*ControlServer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create socket directory: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		conn, err := net.DialTimeout("unix", path, time.Second)
+		if err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%w (socket %s)", ErrDaemonRunning, path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	ln, err := listenSocket(path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on control socket: %w", err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("restrict control socket: %w", err)
+	}
+	return &ControlServer{path: path, ln: ln, handle: handle}, nil
+}
+
+// Path returns the socket path.
+func (s *ControlServer) Path() string {
+	return s.path
+}
+
+// Serve answers requests until ctx is done, then removes the socket and waits
+// for in-flight requests to finish.
+func (s *ControlServer) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		_ = s.ln.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("accept control connection: %w", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(ctx, conn)
+		}()
+	}
+}
+
+func (s *ControlServer) serveConn(ctx context.Context, conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(controlTimeout))
+
+	var req Request
+	resp := Response{}
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		resp.Error = fmt.Sprintf("decode request: %v", err)
+	} else {
+		resp = s.handle(ctx, req)
+	}
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+// CallControl sends command to the daemon listening at path. A refused
+// command is returned as an error alongside the response.
+func CallControl(ctx context.Context, path, command string) (*Response, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("connect to daemon: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(controlTimeout))
+
+	if err := json.NewEncoder(conn).Encode(Request{Command: command}); err != nil {
+		return nil, fmt.Errorf("send %s request: %w", command, err)
+	}
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("read %s response: %w", command, err)
+	}
+	if !resp.OK {
+		return &resp, fmt.Errorf("daemon %s: %s", command, resp.Error)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,127 @@
+// ABOUTME: Tests for the daemon control socket.
+// ABOUTME: Covers round trips, socket permissions, stale sockets, and a second daemon.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// socketPath returns a short socket path; Unix sockets have a length limit
+// that t.TempDir paths can exceed.
+func socketPath(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "pushd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return filepath.Join(dir, "run", "daemon.sock")
+}
+
+// serveControl starts a control server at path answering every request with
+// the command it received.
+func serveControl(t *testing.T, path string) *ControlServer {
+	t.Helper()
+	server, err := ListenControl(path, func(_ context.Context, req Request) Response {
+		if req.Command == "fail" {
+			return Response{Error: "no such command"}
+		}
+		return Response{OK: true, Status: &Status{Config: req.Command}}
+	})
+	if err != nil {
+		t.Fatalf("ListenControl: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return server
+}
+
+func TestControlRoundTrip(t *testing.T) {
+	path := socketPath(t)
+	serveControl(t, path)
+
+	resp, err := CallControl(context.Background(), path, CommandStatus)
+	if err != nil {
+		t.Fatalf("CallControl: %v", err)
+	}
+	if !resp.OK || resp.Status == nil || resp.Status.Config != CommandStatus {
+		t.Errorf("response = %+v, want the status command echoed", resp)
+	}
+
+	resp, err = CallControl(context.Background(), path, "fail")
+	if err == nil || resp == nil || resp.Error != "no such command" {
+		t.Errorf("refused command = %+v, %v; want the error in both", resp, err)
+	}
+}
+
+func TestControlSocketIsPrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions don't apply")
+	}
+	path := socketPath(t)
+	serveControl(t, path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket mode = %o, want 600", perm)
+	}
+	dir, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := dir.Mode().Perm(); perm != 0o700 {
+		t.Errorf("socket directory mode = %o, want 700", perm)
+	}
+}
+
+func TestControlReplacesStaleSocket(t *testing.T) {
+	path := socketPath(t)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	// A daemon that died leaves its socket file behind with nobody listening.
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = ln.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("stale socket missing: %v", err)
+	}
+
+	serveControl(t, path)
+	if _, err := CallControl(context.Background(), path, CommandStatus); err != nil {
+		t.Errorf("CallControl after takeover: %v", err)
+	}
+}
+
+func TestControlRefusesSecondDaemon(t *testing.T) {
+	path := socketPath(t)
+	serveControl(t, path)
+
+	_, err := ListenControl(path, func(context.Context, Request) Response { return Response{OK: true} })
+	if !errors.Is(err, ErrDaemonRunning) {
+		t.Fatalf("second ListenControl = %v, want ErrDaemonRunning", err)
+	}
+	// The running daemon keeps its socket.
+	if _, err := CallControl(context.Background(), path, CommandStatus); err != nil {
+		t.Errorf("CallControl after refused second daemon: %v", err)
+	}
+}
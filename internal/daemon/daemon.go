@@ -0,0 +1,144 @@
+// ABOUTME: Long-running scheduler for periodic background jobs.
+// ABOUTME: Runs registered jobs on their own intervals until cancelled.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a named unit of periodic work.
+type Job struct {
+	Name  string
+	Every time.Duration
+	Run   func(ctx context.Context) error
+}
+
+// JobStatus describes how a job has been doing since the runner started.
+type JobStatus struct {
+	Name      string        `json:"name"`
+	Every     time.Duration `json:"every"`
+	Runs      int           `json:"runs"`
+	LastRun   time.Time     `json:"last_run,omitzero"`
+	LastError string        `json:"last_error,omitempty"`
+}
+
+// Runner schedules jobs and reports their failures to a logger.
+type Runner struct {
+	jobs []Job
+	log  *slog.Logger
+	// wake holds one channel per job; a send runs the job ahead of its tick.
+	wake []chan struct{}
+
+	mu     sync.Mutex
+	status []JobStatus
+}
+
+// NewRunner returns a runner that logs job errors to log (nil discards them).
+func NewRunner(log *slog.Logger) *Runner {
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
+	}
+	return &Runner{log: log}
+}
+
+// Add registers a job. Jobs added after Run starts are ignored.
+func (r *Runner) Add(job Job) {
+	r.jobs = append(r.jobs, job)
+	r.wake = append(r.wake, make(chan struct{}, 1))
+	r.mu.Lock()
+	r.status = append(r.status, JobStatus{Name: job.Name, Every: job.Every})
+	r.mu.Unlock()
+}
+
+// Jobs returns the registered jobs.
+func (r *Runner) Jobs() []Job {
+	return r.jobs
+}
+
+// Run executes every job immediately and then on its interval until ctx is done.
+func (r *Runner) Run(ctx context.Context) error {
+	if len(r.jobs) == 0 {
+		return errors.New("daemon: no jobs registered")
+	}
+	for _, job := range r.jobs {
+		if job.Every <= 0 || job.Run == nil {
+			return fmt.Errorf("daemon: job %q needs a positive interval and a run function", job.Name)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range r.jobs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r.loop(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// RunNow asks every job to run as soon as it is idle instead of waiting for
+// its next tick. Requests made while a job is already queued are merged.
+func (r *Runner) RunNow() {
+	for _, wake := range r.wake {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Status returns a snapshot of every job's run history.
+func (r *Runner) Status() []JobStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]JobStatus(nil), r.status...)
+}
+
+func (r *Runner) loop(ctx context.Context, i int) {
+	job := r.jobs[i]
+	ticker := time.NewTicker(job.Every)
+	defer ticker.Stop()
+
+	for {
+		r.runOnce(ctx, i)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake[i]:
+			ticker.Reset(job.Every)
+		}
+	}
+}
+
+func (r *Runner) runOnce(ctx context.Context, i int) {
+	job := r.jobs[i]
+	err := job.Run(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		r.log.Warn("job failed", "job", job.Name, "error", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := &r.status[i]
+	st.Runs++
+	st.LastRun = time.Now()
+	st.LastError = ""
+	if err != nil {
+		st.LastError = err.Error()
+	}
+}
@@ -0,0 +1,76 @@
+// ABOUTME: Job that sends the scheduled digest of received messages.
+// ABOUTME: Covers everything received since the last digest, which the store remembers.
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/digest"
+	"github.com/harper/push/internal/notify"
+)
+
+// DigestOptions configure the scheduled digest.
+type DigestOptions struct {
+	// Previous returns the latest time the digest was due at or before now.
+	Previous    func(now time.Time) time.Time
+	Priority    int
+	MinPriority *int
+}
+
+// DigestJob returns a job that sends the digest once it's due.
+func DigestJob(store *db.Store, notifier notify.Notifier, opts DigestOptions, every time.Duration, log *slog.Logger) Job {
+	return Job{
+		Name:  "digest",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			return SendDigest(ctx, store, notifier, opts, time.Now(), log)
+		},
+	}
+}
+
+// SendDigest sends the digest of the messages received since the last one if
+// it has come due since. The first run only starts the count, and a digest
+// with no messages isn't sent.
+func SendDigest(ctx context.Context, store *db.Store, notifier notify.Notifier, opts DigestOptions, now time.Time, log *slog.Logger) error {
+	last, err := store.DigestSentAt(ctx)
+	if err != nil {
+		return err
+	}
+	if last.IsZero() {
+		return store.SetDigestSentAt(ctx, now)
+	}
+	if !last.Before(opts.Previous(now)) {
+		return nil
+	}
+
+	d, err := digest.Load(ctx, store, db.MessageFilter{Since: &last, Until: &now, MinPriority: opts.MinPriority})
+	if err != nil {
+		return err
+	}
+	if d.Total == 0 {
+		log.Debug("digest skipped; no messages", "since", last)
+		return store.SetDigestSentAt(ctx, now)
+	}
+
+	params := d.Notification()
+	params.Priority = opts.Priority
+	resp, err := notifier.Send(ctx, params)
+	if err != nil {
+		// Left unrecorded, so the next tick tries again.
+		return err
+	}
+	log.Info("digest sent", "messages", d.Total, "apps", len(d.Apps), "request_id", resp.Request)
+	if err := store.SetDigestSentAt(ctx, now); err != nil {
+		return err
+	}
+	return store.LogSent(ctx, db.SentRecord{
+		Message:   params.Message,
+		Title:     params.Title,
+		Priority:  params.Priority,
+		SentAt:    now,
+		RequestID: resp.Request,
+	})
+}
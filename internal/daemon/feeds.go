@@ -0,0 +1,144 @@
+// ABOUTME: Job that polls RSS and Atom feeds and notifies about new items.
+// ABOUTME: Remembers seen item GUIDs in the store so each item is sent once.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/feed"
+	"github.com/harper/push/internal/notify"
+)
+
+// feedItemMemory is how long an item that dropped out of its feed is
+// remembered, in case it comes back.
+const feedItemMemory = 30 * 24 * time.Hour
+
+// FeedsJob returns a job that polls every due feed.
+func FeedsJob(store *db.Store, notifier notify.Notifier, client *http.Client, every time.Duration, log *slog.Logger) Job {
+	return Job{
+		Name:  "feeds",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			return PollFeeds(ctx, store, notifier, client, time.Now(), log)
+		},
+	}
+}
+
+// PollFeeds polls the feeds due at now and sends their new items.
+func PollFeeds(ctx context.Context, store *db.Store, notifier notify.Notifier, client *http.Client, now time.Time, log *slog.Logger) error {
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, f := range feeds {
+		if !f.Due(now, monitorSlack) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pollFeed(ctx, store, notifier, client, f, now, log); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("feed %q: %w", f.Name, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := store.PruneFeedItems(ctx, now.Add(-feedItemMemory)); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func pollFeed(ctx context.Context, store *db.Store, notifier notify.Notifier, client *http.Client, f db.FeedRecord, now time.Time, log *slog.Logger) error {
+	// A feed that has never been read only learns what's already there.
+	seed := f.LastChecked.IsZero()
+	f.LastChecked = now
+
+	filter, err := feed.CompileFilter(f.Filter)
+	if err != nil {
+		return recordFeedError(ctx, store, f, err)
+	}
+	res, err := feed.Fetch(ctx, client, f.URL, f.ETag, f.LastModified)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return recordFeedError(ctx, store, f, err)
+	}
+	f.ETag, f.LastModified, f.LastError = res.ETag, res.LastModified, ""
+	if res.NotModified {
+		if err := store.TouchFeedItems(ctx, f.Name, now); err != nil {
+			return err
+		}
+		return store.UpdateFeedState(ctx, f)
+	}
+
+	seen, err := store.SeenFeedItems(ctx, f.Name)
+	if err != nil {
+		return err
+	}
+	guids := make([]string, 0, len(res.Feed.Items))
+	var fresh []feed.Item
+	for _, item := range res.Feed.Items {
+		guids = append(guids, item.GUID)
+		if seen[item.GUID] || (filter != nil && !filter.MatchString(item.Title)) {
+			continue
+		}
+		fresh = append(fresh, item)
+	}
+	log.Debug("feed polled", "feed", f.Name, "items", len(res.Feed.Items), "new", len(fresh), "seed", seed)
+
+	if !seed {
+		for _, params := range feed.Notifications(f.Name, res.Feed, fresh) {
+			params.Priority = f.Priority
+			resp, err := notifier.Send(ctx, params)
+			if err != nil {
+				// Left unseen, so the next poll tries again.
+				return err
+			}
+			log.Info("feed item sent", "feed", f.Name, "title", params.Message, "request_id", resp.Request)
+			if err := store.LogSent(ctx, db.SentRecord{
+				Message:   params.Message,
+				Title:     params.Title,
+				Priority:  params.Priority,
+				SentAt:    now,
+				RequestID: resp.Request,
+			}); err != nil {
+				return err
+			}
+		}
+		if len(fresh) > 0 {
+			f.LastItem = fresh[0].Title
+		}
+	}
+
+	if err := store.MarkFeedItemsSeen(ctx, f.Name, guids, now); err != nil {
+		return err
+	}
+	return store.UpdateFeedState(ctx, f)
+}
+
+// recordFeedError saves a failed poll on the feed and returns the failure.
+func recordFeedError(ctx context.Context, store *db.Store, f db.FeedRecord, pollErr error) error {
+	f.LastError = pollErr.Error()
+	if err := store.UpdateFeedState(ctx, f); err != nil {
+		return err
+	}
+	return pollErr
+}
@@ -0,0 +1,202 @@
+// ABOUTME: Job that watches directories with fsnotify and notifies about matching files.
+// ABOUTME: Re-reads the saved watches on each tick and batches events until they settle.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/fswatch"
+	"github.com/harper/push/internal/notify"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// fsSettle is how long a watch must be quiet before its events are sent, so
+// a file being copied in or a burst of files makes one notification.
+const fsSettle = 2 * time.Second
+
+// FSWatchJob returns a job that keeps a filesystem watcher in step with the
+// saved watches. The watcher runs between ticks and stops with the job's
+// context; each tick picks up watches added or removed since the last one.
+func FSWatchJob(store *db.Store, notifier notify.Notifier, every time.Duration, log *slog.Logger) Job {
+	w := &fsWatcher{store: store, notifier: notifier, log: log}
+	return Job{
+		Name:  "fswatch",
+		Every: every,
+		Run:   w.sync,
+	}
+}
+
+type fsWatcher struct {
+	store    *db.Store
+	notifier notify.Notifier
+	log      *slog.Logger
+
+	mu       sync.Mutex
+	watcher  *fsnotify.Watcher
+	watches  []fswatch.Watch
+	dirs     map[string]bool
+	pending  map[string]*fswatch.Batch
+	starting sync.Once
+	startErr error
+}
+
+// sync starts the watcher on the first run and then matches its directories
+// to the saved watches.
+func (w *fsWatcher) sync(ctx context.Context) error {
+	w.starting.Do(func() {
+		w.watcher, w.startErr = fsnotify.NewWatcher()
+		if w.startErr != nil {
+			w.startErr = fmt.Errorf("create file watcher: %w", w.startErr)
+			return
+		}
+		w.dirs = map[string]bool{}
+		w.pending = map[string]*fswatch.Batch{}
+		go w.loop(ctx)
+	})
+	if w.startErr != nil {
+		return w.startErr
+	}
+
+	recs, err := w.store.ListFSWatches(ctx)
+	if err != nil {
+		return err
+	}
+	var (
+		errs    []error
+		watches []fswatch.Watch
+	)
+	names := map[string]bool{}
+	dirs := map[string]bool{}
+	for _, rec := range recs {
+		watch, err := fswatch.Resolve(rec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("watch %q: %w", rec.Name, err))
+			continue
+		}
+		watches = append(watches, watch)
+		names[rec.Name] = true
+		dirs[watch.Dir] = true
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for dir := range dirs {
+		if w.dirs[dir] {
+			continue
+		}
+		if err := w.watcher.Add(dir); err != nil {
+			errs = append(errs, fmt.Errorf("watch %s: %w", dir, err))
+			continue
+		}
+		w.dirs[dir] = true
+	}
+	for dir := range w.dirs {
+		if !dirs[dir] {
+			// The directory may be gone already, which removes the watch.
+			_ = w.watcher.Remove(dir)
+			delete(w.dirs, dir)
+		}
+	}
+	for name := range w.pending {
+		if !names[name] {
+			delete(w.pending, name)
+		}
+	}
+	w.watches = watches
+	return errors.Join(errs...)
+}
+
+// loop collects events and sends each watch's batch once it settles.
+func (w *fsWatcher) loop(ctx context.Context) {
+	defer func() { _ = w.watcher.Close() }()
+	ticker := time.NewTicker(fsSettle / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.add(ev, time.Now())
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.log.Warn("file watcher error", "error", err)
+		case now := <-ticker.C:
+			for _, send := range w.settled(now) {
+				if err := w.send(ctx, send.watch, send.batch, now); err != nil && ctx.Err() == nil {
+					w.log.Warn("fswatch notification failed", "watch", send.watch.Name, "error", err)
+				}
+			}
+		}
+	}
+}
+
+func (w *fsWatcher) add(ev fsnotify.Event, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, watch := range w.watches {
+		if !watch.Matches(ev) {
+			continue
+		}
+		batch := w.pending[watch.Name]
+		if batch == nil {
+			batch = &fswatch.Batch{}
+			w.pending[watch.Name] = batch
+		}
+		batch.Add(ev, watch.Ops, now)
+	}
+}
+
+type settledBatch struct {
+	watch fswatch.Watch
+	batch *fswatch.Batch
+}
+
+// settled removes and returns the batches that have been quiet for fsSettle.
+func (w *fsWatcher) settled(now time.Time) []settledBatch {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var ready []settledBatch
+	for _, watch := range w.watches {
+		batch := w.pending[watch.Name]
+		if batch == nil || now.Sub(batch.Last) < fsSettle {
+			continue
+		}
+		delete(w.pending, watch.Name)
+		ready = append(ready, settledBatch{watch: watch, batch: batch})
+	}
+	return ready
+}
+
+func (w *fsWatcher) send(ctx context.Context, watch fswatch.Watch, batch *fswatch.Batch, now time.Time) error {
+	params := pushover.SendParams{Priority: watch.Priority}
+	params.Title, params.Message = batch.Notification(watch)
+	resp, err := w.notifier.Send(ctx, params)
+	if err != nil {
+		return err
+	}
+	w.log.Info("fswatch notification sent", "watch", watch.Name, "files", batch.Len(), "request_id", resp.Request)
+
+	if err := w.store.MarkFSWatchFired(ctx, watch.Name, batch.LastFile(), now); err != nil {
+		return err
+	}
+	return w.store.LogSent(ctx, db.SentRecord{
+		Message:   params.Message,
+		Title:     params.Title,
+		Priority:  params.Priority,
+		SentAt:    now,
+		RequestID: resp.Request,
+	})
+}
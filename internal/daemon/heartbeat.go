@@ -0,0 +1,70 @@
+// ABOUTME: Dead man's switch job that alerts on missed heartbeats.
+// ABOUTME: Sends one notification per missed window and logs it as sent.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/notify"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// HeartbeatJob returns a job that alerts once for every overdue heartbeat.
+func HeartbeatJob(store *db.Store, notifier notify.Notifier, every time.Duration) Job {
+	return Job{
+		Name:  "heartbeats",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			return CheckHeartbeats(ctx, store, notifier, time.Now())
+		},
+	}
+}
+
+// CheckHeartbeats sends alerts for heartbeats that are overdue and not yet reported.
+func CheckHeartbeats(ctx context.Context, store *db.Store, notifier notify.Notifier, now time.Time) error {
+	beats, err := store.ListHeartbeats(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, beat := range beats {
+		if beat.AlertedAt != nil || !beat.Overdue(now) {
+			continue
+		}
+		if err := alertMissedHeartbeat(ctx, store, notifier, beat, now); err != nil {
+			errs = append(errs, fmt.Errorf("heartbeat %q: %w", beat.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func alertMissedHeartbeat(ctx context.Context, store *db.Store, notifier notify.Notifier, beat db.HeartbeatRecord, now time.Time) error {
+	params := pushover.SendParams{
+		Title:    fmt.Sprintf("Missed heartbeat: %s", beat.Name),
+		Message:  fmt.Sprintf("No check-in from %q since %s (expected every %s).", beat.Name, beat.LastSeen.Local().Format(time.RFC1123), beat.Every),
+		Priority: 1,
+	}
+
+	resp, err := notifier.Send(ctx, params)
+	if err != nil {
+		return err
+	}
+	if err := store.MarkHeartbeatAlerted(ctx, beat.Name, now); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "missed heartbeat alert sent", "heartbeat", beat.Name, "last_seen", beat.LastSeen, "request_id", resp.Request)
+
+	return store.LogSent(ctx, db.SentRecord{
+		Message:   params.Message,
+		Title:     params.Title,
+		Priority:  params.Priority,
+		SentAt:    now,
+		RequestID: resp.Request,
+	})
+}
@@ -0,0 +1,12 @@
+// ABOUTME: Creates the control socket on systems without a umask.
+// ABOUTME: There the socket directory's permissions keep other users out.
+//go:build !unix
+
+package daemon
+
+import "net"
+
+// listenSocket listens on a Unix-domain socket at path.
+func listenSocket(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
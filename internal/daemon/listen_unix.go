@@ -0,0 +1,19 @@
+// ABOUTME: Creates the control socket on Unix with a umask that keeps other users out.
+// ABOUTME: The socket is never reachable with looser permissions, not even briefly.
+//go:build unix
+
+package daemon
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenSocket listens on a Unix-domain socket at path that only its owner
+// can connect to. The umask is process-wide, so this runs before the daemon
+// starts any other work that creates files.
+func listenSocket(path string) (net.Listener, error) {
+	old := syscall.Umask(0o177)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,75 @@
+// ABOUTME: Job that keeps the history database tidy while nothing else is happening.
+// ABOUTME: Prunes history past the retention period, frees unused pages, and refreshes planner statistics.
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+// MaintenanceOptions configure background maintenance.
+type MaintenanceOptions struct {
+	// Interval is how long to wait between maintenance runs.
+	Interval time.Duration
+	// Idle is how long nothing must have been received or sent first.
+	Idle time.Duration
+	// Retention, when set, prunes history older than this.
+	Retention time.Duration
+}
+
+// MaintenanceJob returns a job that maintains the database once it's due and
+// history has been idle.
+func MaintenanceJob(store *db.Store, opts MaintenanceOptions, every time.Duration, log *slog.Logger) Job {
+	return Job{
+		Name:  "maintenance",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			return Maintain(ctx, store, opts, time.Now(), log)
+		},
+	}
+}
+
+// Maintain prunes, vacuums, and optimizes the database if Interval has passed
+// since it last did and nothing was received or sent in the last Idle.
+func Maintain(ctx context.Context, store *db.Store, opts MaintenanceOptions, now time.Time, log *slog.Logger) error {
+	state, err := store.MaintenanceStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if last := state.LastRun(); !last.IsZero() && now.Sub(last) < opts.Interval {
+		return nil
+	}
+	active, err := store.LastActivity(ctx)
+	if err != nil {
+		return err
+	}
+	if now.Sub(active) < opts.Idle {
+		log.Debug("maintenance postponed; history is busy", "last_activity", active)
+		return nil
+	}
+
+	if opts.Retention > 0 {
+		messages, sent, err := store.PruneHistory(ctx, now.Add(-opts.Retention))
+		if err != nil {
+			return err
+		}
+		state.PrunedAt, state.PrunedMessages, state.PrunedSent = now, messages, sent
+		if messages+sent > 0 {
+			log.Info("pruned old history", "messages", messages, "sent", sent, "retention", opts.Retention)
+		}
+	}
+	freed, err := store.IncrementalVacuum(ctx)
+	if err != nil {
+		return err
+	}
+	state.VacuumedAt, state.FreedBytes = now, freed
+	if err := store.Optimize(ctx); err != nil {
+		return err
+	}
+	state.OptimizedAt = now
+	log.Debug("database maintained", "freed_bytes", freed)
+	return store.SetMaintenanceStatus(ctx, state)
+}
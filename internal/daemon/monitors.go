@@ -0,0 +1,105 @@
+// ABOUTME: Job that checks URL monitors and alerts when they go down or come back.
+// ABOUTME: Runs due checks concurrently and logs each transition notification as sent.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/monitor"
+	"github.com/harper/push/internal/notify"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// monitorSlack lets a monitor run on a tick that lands just short of its
+// interval.
+const monitorSlack = time.Second
+
+// MonitorsJob returns a job that checks every due URL monitor.
+func MonitorsJob(store *db.Store, notifier notify.Notifier, client *http.Client, every time.Duration, log *slog.Logger) Job {
+	return Job{
+		Name:  "monitors",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			return CheckMonitors(ctx, store, notifier, client, time.Now(), log)
+		},
+	}
+}
+
+// CheckMonitors checks the monitors due at now and sends a notification for
+// each one that changed state.
+func CheckMonitors(ctx context.Context, store *db.Store, notifier notify.Notifier, client *http.Client, now time.Time, log *slog.Logger) error {
+	monitors, err := store.ListMonitors(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, m := range monitors {
+		if !m.Due(now, monitorSlack) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := checkMonitor(ctx, store, notifier, client, m, now, log); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("monitor %q: %w", m.Name, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func checkMonitor(ctx context.Context, store *db.Store, notifier notify.Notifier, client *http.Client, m db.MonitorRecord, now time.Time, log *slog.Logger) error {
+	previous := m
+	result := monitor.Check(ctx, client, m)
+	if ctx.Err() != nil {
+		return nil
+	}
+	changed := monitor.Apply(&m, result, now)
+	if err := store.UpdateMonitorState(ctx, m); err != nil {
+		return err
+	}
+	log.Debug("monitor checked", "monitor", m.Name, "state", m.State, "result", m.LastResult)
+	// The first result only settles an unknown monitor; being up from the
+	// start isn't news.
+	if !changed || (previous.State == db.MonitorUnknown && m.State == db.MonitorUp) {
+		return nil
+	}
+
+	params := pushover.SendParams{URL: m.URL, URLTitle: "Open " + m.Name}
+	if m.State == db.MonitorDown {
+		params.Title = "Down: " + m.Name
+		params.Message = fmt.Sprintf("%s is down: %s.", m.URL, result)
+		params.Priority = 1
+	} else {
+		params.Title = "Up: " + m.Name
+		params.Message = fmt.Sprintf("%s is back up after %s: %s.", m.URL, now.Sub(previous.LastChange).Round(time.Second), result)
+	}
+	resp, err := notifier.Send(ctx, params)
+	if err != nil {
+		return err
+	}
+	log.Info("monitor changed state", "monitor", m.Name, "state", m.State, "result", m.LastResult, "request_id", resp.Request)
+
+	return store.LogSent(ctx, db.SentRecord{
+		Message:   params.Message,
+		Title:     params.Title,
+		Priority:  params.Priority,
+		SentAt:    now,
+		RequestID: resp.Request,
+	})
+}
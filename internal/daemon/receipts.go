@@ -0,0 +1,27 @@
+// ABOUTME: Job that follows emergency sends until they are acknowledged or expire.
+// ABOUTME: Syncs pending receipts into the sent log on every tick.
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/messages"
+)
+
+// ReceiptsJob returns a job that syncs pending emergency receipts.
+func ReceiptsJob(store *db.Store, clientFor messages.ReceiptClient, every time.Duration, log *slog.Logger) Job {
+	return Job{
+		Name:  "receipts",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			settled, err := messages.SyncReceipts(ctx, store, clientFor)
+			for _, rec := range settled {
+				log.Info("emergency receipt settled", "receipt", rec.Receipt, "status", rec.ReceiptStatus, "acknowledged_by", rec.ReceiptAckedBy)
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,32 @@
+// ABOUTME: Job that copies the history database to the [replicate] target.
+// ABOUTME: Uploads a snapshot when the database has changed and prunes old replicas.
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/replicate"
+)
+
+// ReplicateJob returns a job that replicates the database to target.
+func ReplicateJob(store *db.Store, target replicate.Target, opts replicate.Options, every time.Duration, log *slog.Logger) Job {
+	return Job{
+		Name:  "replicate",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			status, err := replicate.Run(ctx, store, target, opts, time.Now())
+			if err != nil {
+				return err
+			}
+			if status.Uploaded() {
+				log.Info("database replicated", "target", status.Target, "replica", status.LastReplica, "bytes", status.LastSize, "replicas", status.Replicas)
+			} else {
+				log.Debug("replication skipped; database unchanged", "target", status.Target)
+			}
+			return nil
+		},
+	}
+}
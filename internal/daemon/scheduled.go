@@ -0,0 +1,90 @@
+// ABOUTME: Job that sends timers and other scheduled sends the daemon owns.
+// ABOUTME: Claims each due send before sending so a cancel can't race it.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/notify"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// ScheduledTick is how often scheduled sends are looked for, so a timer
+// fires within a few seconds whatever the daemon's interval.
+const ScheduledTick = 5 * time.Second
+
+// lateAfter is how overdue a send can be before its message says when it
+// was due, as when the daemon wasn't running at the time.
+const lateAfter = time.Minute
+
+// ScheduledJob returns a job that sends every due scheduled send.
+func ScheduledJob(store *db.Store, notifier notify.Notifier, every time.Duration, log *slog.Logger) Job {
+	return Job{
+		Name:  "scheduled",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			return SendDueScheduled(ctx, store, notifier, time.Now(), log)
+		},
+	}
+}
+
+// SendDueScheduled sends the scheduled sends due at now. Each is marked sent
+// first; one that fails is logged as a failed send rather than retried.
+func SendDueScheduled(ctx context.Context, store *db.Store, notifier notify.Notifier, now time.Time, log *slog.Logger) error {
+	due, err := store.DueScheduled(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, rec := range due {
+		claimed, err := store.FinishScheduled(ctx, rec.ID, db.ScheduledSent)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if err := sendScheduled(ctx, store, notifier, rec, now, log); err != nil {
+			errs = append(errs, fmt.Errorf("scheduled #%d: %w", rec.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sendScheduled(ctx context.Context, store *db.Store, notifier notify.Notifier, rec db.ScheduledRecord, now time.Time, log *slog.Logger) error {
+	params := pushover.SendParams{
+		Message:  rec.Message,
+		Title:    rec.Title,
+		Priority: rec.Priority,
+		Device:   rec.Device,
+	}
+	if now.Sub(rec.SendAt) > lateAfter {
+		params.Message += fmt.Sprintf("\n(Due at %s.)", rec.SendAt.Local().Format("Mon 15:04"))
+	}
+
+	sent := db.SentRecord{
+		Message:  params.Message,
+		Title:    params.Title,
+		Device:   params.Device,
+		Priority: params.Priority,
+		SentAt:   now,
+	}
+	resp, sendErr := notifier.Send(ctx, params)
+	if sendErr != nil {
+		sent.Error = sendErr.Error()
+	} else {
+		sent.RequestID = resp.Request
+		log.Info("scheduled send sent", "id", rec.ID, "kind", rec.Kind, "due", rec.SendAt, "request_id", resp.Request)
+	}
+	if err := store.LogSent(ctx, sent); err != nil {
+		return errors.Join(sendErr, err)
+	}
+	return sendErr
+}
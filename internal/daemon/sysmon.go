@@ -0,0 +1,93 @@
+// ABOUTME: Job that checks local disk and load thresholds for push sysmon.
+// ABOUTME: Alerts when a threshold is crossed and again when it clears, logging both as sent.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/notify"
+	"github.com/harper/push/internal/sysmon"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// SysmonJob returns a job that runs every due system check.
+func SysmonJob(store *db.Store, notifier notify.Notifier, every time.Duration, log *slog.Logger) Job {
+	return Job{
+		Name:  "sysmon",
+		Every: every,
+		Run: func(ctx context.Context) error {
+			return CheckSysmon(ctx, store, notifier, time.Now(), log)
+		},
+	}
+}
+
+// CheckSysmon runs the system checks due at now and sends a notification for
+// each one that crossed or cleared its threshold.
+func CheckSysmon(ctx context.Context, store *db.Store, notifier notify.Notifier, now time.Time, log *slog.Logger) error {
+	checks, err := store.ListSysmon(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, check := range checks {
+		if !check.Due(now, monitorSlack) {
+			continue
+		}
+		if err := runSysmonCheck(ctx, store, notifier, check, now, log); err != nil {
+			errs = append(errs, fmt.Errorf("system check %q: %w", check.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func runSysmonCheck(ctx context.Context, store *db.Store, notifier notify.Notifier, check db.SysmonRecord, now time.Time, log *slog.Logger) error {
+	value, err := sysmon.Read(ctx, check)
+	if err != nil {
+		return err
+	}
+	changed := sysmon.Apply(&check, value, now)
+	if err := store.UpdateSysmonState(ctx, check); err != nil {
+		return err
+	}
+	log.Debug("system check ran", "check", check.Name, "value", value, "state", check.State)
+	if !changed {
+		return nil
+	}
+
+	host, _ := os.Hostname()
+	subject, sentence := "load", "The load average"
+	if check.Metric == sysmon.Disk {
+		subject, sentence = "disk "+check.Path, "Disk use of "+check.Path
+	}
+	current := sysmon.Format(check.Metric, value)
+	threshold := sysmon.Format(check.Metric, check.Threshold)
+	params := pushover.SendParams{}
+	if check.State == db.SysmonHigh {
+		params.Title = fmt.Sprintf("%s: %s at %s", host, subject, current)
+		params.Message = fmt.Sprintf("%s on %s is %s, over the %s threshold.", sentence, host, current, threshold)
+		params.Priority = 1
+	} else {
+		params.Title = fmt.Sprintf("%s: %s back to %s", host, subject, current)
+		params.Message = fmt.Sprintf("%s on %s is %s, back under the %s threshold.", sentence, host, current, threshold)
+	}
+	resp, err := notifier.Send(ctx, params)
+	if err != nil {
+		return err
+	}
+	log.Info("system check changed state", "check", check.Name, "state", check.State, "value", value, "request_id", resp.Request)
+
+	return store.LogSent(ctx, db.SentRecord{
+		Message:   params.Message,
+		Title:     params.Title,
+		Priority:  params.Priority,
+		SentAt:    now,
+		RequestID: resp.Request,
+	})
+}
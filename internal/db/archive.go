@@ -0,0 +1,30 @@
+// ABOUTME: Archiving of received messages, for push archive and push unarchive.
+// ABOUTME: Archived messages stay in the database but drop out of default history listings.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// SetArchived archives or unarchives the message with the given Pushover ID.
+// It reports whether such a message exists.
+func (s *Store) SetArchived(ctx context.Context, pushoverID int64, archived bool) (bool, error) {
+	if s == nil || s.write == nil {
+		return false, errors.New("database not initialized")
+	}
+	value := 0
+	if archived {
+		value = 1
+	}
+	res, err := s.write.ExecContext(ctx, `UPDATE messages SET archived = ? WHERE pushover_id = ?;`, value, pushoverID)
+	if err != nil {
+		return false, fmt.Errorf("archive message: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("archive message: %w", err)
+	}
+	return affected > 0, nil
+}
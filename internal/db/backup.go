@@ -0,0 +1,89 @@
+// ABOUTME: Online backup and restore using SQLite's backup API.
+// ABOUTME: Copies the live database page by page without stopping writers.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"modernc.org/sqlite"
+)
+
+// backupStepPages is how many pages each backup step copies before yielding.
+const backupStepPages = 256
+
+type backuper interface {
+	NewBackup(dstURI string) (*sqlite.Backup, error)
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
+// BackupTo writes a consistent copy of the database to path, which must not exist.
+func (s *Store) BackupTo(ctx context.Context, path string) error {
+	if s == nil || s.sql == nil {
+		return errors.New("database not initialized")
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup destination %s already exists", path)
+	}
+
+	return s.withBackup(ctx, func(b backuper) (*sqlite.Backup, error) {
+		return b.NewBackup(path)
+	})
+}
+
+// RestoreFrom replaces the database contents with the database at path and
+// brings its schema up to date.
+func (s *Store) RestoreFrom(ctx context.Context, path string) error {
+	if s == nil || s.sql == nil {
+		return errors.New("database not initialized")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("restore source: %w", err)
+	}
+
+	if err := s.withBackup(ctx, func(b backuper) (*sqlite.Backup, error) {
+		return b.NewRestore(path)
+	}); err != nil {
+		return err
+	}
+	return s.migrate()
+}
+
+func (s *Store) withBackup(ctx context.Context, start func(backuper) (*sqlite.Backup, error)) error {
+	conn, err := s.write.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	return conn.Raw(func(driverConn any) error {
+		b, ok := driverConn.(backuper)
+		if !ok {
+			return errors.New("backup: sqlite driver does not support online backup")
+		}
+		backup, err := start(b)
+		if err != nil {
+			return fmt.Errorf("backup: %w", err)
+		}
+		for {
+			if err := ctx.Err(); err != nil {
+				_ = backup.Finish()
+				return err
+			}
+			more, err := backup.Step(backupStepPages)
+			if err != nil {
+				_ = backup.Finish()
+				return fmt.Errorf("backup: %w", err)
+			}
+			if !more {
+				break
+			}
+		}
+		if err := backup.Finish(); err != nil {
+			return fmt.Errorf("backup: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,163 @@
+// ABOUTME: Batched updates and deletes over many received messages at once.
+// ABOUTME: Backs push history --delete and --apply, and stores message tags.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// bulkBatch caps the messages changed per transaction, so a large bulk
+// operation doesn't hold the write lock for long.
+const bulkBatch = 500
+
+// MessageUpdate is a change UpdateMessages applies to each message. Zero
+// fields leave messages as they are.
+type MessageUpdate struct {
+	AddTag    string
+	RemoveTag string
+	Archived  *bool
+}
+
+// Tags are stored as ",a,b," so one can be found with instr.
+func tagToken(tag string) string {
+	return "," + tag + ","
+}
+
+func splitTags(stored string) []string {
+	var tags []string
+	for _, tag := range strings.Split(stored, ",") {
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// MessageIDs returns the row IDs of the messages matching filter, newest
+// first. Unlike FindMessages, a zero Limit means every match.
+func (s *Store) MessageIDs(ctx context.Context, filter MessageFilter) ([]int64, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+	where, args, err := filter.where()
+	if err != nil {
+		return nil, err
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.sql.QueryContext(ctx, fmt.Sprintf(`SELECT id FROM messages
+        WHERE %s
+        ORDER BY received_at DESC, id DESC
+        LIMIT ? OFFSET ?;`, where), append(args, limit, max(filter.Offset, 0))...)
+	if err != nil {
+		return nil, fmt.Errorf("query message ids: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan message id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate message ids: %w", err)
+	}
+	return ids, nil
+}
+
+// DeleteMessages deletes the messages with the given row IDs and returns how
+// many were deleted.
+func (s *Store) DeleteMessages(ctx context.Context, ids []int64) (int64, error) {
+	return s.inBatches(ctx, ids, func(tx *sql.Tx, in string, args []any) (sql.Result, error) {
+		return tx.ExecContext(ctx, `DELETE FROM messages WHERE id IN (`+in+`);`, args...)
+	})
+}
+
+// UpdateMessages applies update to the messages with the given row IDs and
+// returns how many changed.
+func (s *Store) UpdateMessages(ctx context.Context, ids []int64, update MessageUpdate) (int64, error) {
+	var sets, conds []string
+	var setArgs, condArgs []any
+	if update.AddTag != "" {
+		sets = append(sets, "tags = CASE WHEN instr(COALESCE(tags, ''), ?) = 0 THEN COALESCE(NULLIF(tags, ''), ',') || ? || ',' ELSE tags END")
+		setArgs = append(setArgs, tagToken(update.AddTag), update.AddTag)
+		conds = append(conds, "instr(COALESCE(tags, ''), ?) = 0")
+		condArgs = append(condArgs, tagToken(update.AddTag))
+	}
+	if update.RemoveTag != "" {
+		sets = append(sets, "tags = NULLIF(replace(tags, ?, ','), ',')")
+		setArgs = append(setArgs, tagToken(update.RemoveTag))
+		conds = append(conds, "instr(COALESCE(tags, ''), ?) > 0")
+		condArgs = append(condArgs, tagToken(update.RemoveTag))
+	}
+	if update.Archived != nil {
+		value := 0
+		if *update.Archived {
+			value = 1
+		}
+		sets = append(sets, "archived = ?")
+		setArgs = append(setArgs, value)
+		conds = append(conds, "COALESCE(archived, 0) <> ?")
+		condArgs = append(condArgs, value)
+	}
+	if len(sets) == 0 {
+		return 0, errors.New("message update changes nothing")
+	}
+	if update.AddTag != "" && update.RemoveTag != "" {
+		return 0, errors.New("message update can't add and remove a tag at once")
+	}
+
+	// Only rows the update would change are counted.
+	return s.inBatches(ctx, ids, func(tx *sql.Tx, in string, args []any) (sql.Result, error) {
+		query := fmt.Sprintf(`UPDATE messages SET %s WHERE id IN (%s) AND (%s);`,
+			strings.Join(sets, ", "), in, strings.Join(conds, " OR "))
+		all := append(append(append([]any{}, setArgs...), args...), condArgs...)
+		return tx.ExecContext(ctx, query, all...)
+	})
+}
+
+// inBatches runs exec over ids bulkBatch at a time, each batch in its own
+// transaction, and totals the rows affected.
+func (s *Store) inBatches(ctx context.Context, ids []int64, exec func(tx *sql.Tx, in string, args []any) (sql.Result, error)) (int64, error) {
+	if s == nil || s.write == nil {
+		return 0, errors.New("database not initialized")
+	}
+	var total int64
+	for start := 0; start < len(ids); start += bulkBatch {
+		batch := ids[start:min(start+bulkBatch, len(ids))]
+		args := make([]any, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+		in := strings.TrimSuffix(strings.Repeat("?, ", len(batch)), ", ")
+
+		tx, err := s.write.BeginTx(ctx, nil)
+		if err != nil {
+			return total, fmt.Errorf("begin bulk update: %w", err)
+		}
+		res, err := exec(tx, in, args)
+		if err != nil {
+			_ = tx.Rollback()
+			return total, fmt.Errorf("bulk update messages: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			_ = tx.Rollback()
+			return total, fmt.Errorf("bulk update messages: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return total, fmt.Errorf("commit bulk update: %w", err)
+		}
+		total += affected
+	}
+	return total, nil
+}
@@ -0,0 +1,138 @@
+// ABOUTME: Tests for batched deletes and updates over many messages.
+// ABOUTME: Covers matches spanning several batches, repeated updates, and empty matches.
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// bulkStore returns a store holding 2400 messages, 600 from each of four apps.
+func bulkStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(Memory)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	if _, err := store.PersistMessages(context.Background(), backlog(2400, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func countMessages(t *testing.T, store *Store, filter MessageFilter) int {
+	t.Helper()
+	ids, err := store.MessageIDs(context.Background(), filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(ids)
+}
+
+func TestDeleteMessagesInBatches(t *testing.T) {
+	ctx := context.Background()
+	store := bulkStore(t)
+
+	ids, err := store.MessageIDs(ctx, MessageFilter{App: "ci"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 600 || len(ids) <= bulkBatch {
+		t.Fatalf("matched %d messages, want 600 (more than one batch of %d)", len(ids), bulkBatch)
+	}
+	deleted, err := store.DeleteMessages(ctx, ids)
+	if err != nil {
+		t.Fatalf("DeleteMessages: %v", err)
+	}
+	if deleted != 600 {
+		t.Errorf("deleted %d, want 600", deleted)
+	}
+	if got := countMessages(t, store, MessageFilter{App: "ci"}); got != 0 {
+		t.Errorf("%d ci messages left", got)
+	}
+	if got := countMessages(t, store, MessageFilter{}); got != 1800 {
+		t.Errorf("%d messages left, want 1800", got)
+	}
+
+	// Deleting the same IDs again finds nothing.
+	if deleted, err := store.DeleteMessages(ctx, ids); err != nil || deleted != 0 {
+		t.Errorf("repeat delete = %d, %v; want 0", deleted, err)
+	}
+}
+
+func TestUpdateMessagesInBatches(t *testing.T) {
+	ctx := context.Background()
+	store := bulkStore(t)
+
+	ids, err := store.MessageIDs(ctx, MessageFilter{App: "backup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := store.UpdateMessages(ctx, ids, MessageUpdate{AddTag: "nightly"})
+	if err != nil {
+		t.Fatalf("UpdateMessages: %v", err)
+	}
+	if changed != 600 {
+		t.Errorf("tagged %d, want 600", changed)
+	}
+	if got := countMessages(t, store, MessageFilter{Tag: "nightly"}); got != 600 {
+		t.Errorf("%d messages tagged, want 600", got)
+	}
+	// Only rows the update changes are counted.
+	if changed, err := store.UpdateMessages(ctx, ids, MessageUpdate{AddTag: "nightly"}); err != nil || changed != 0 {
+		t.Errorf("repeat tag = %d, %v; want 0", changed, err)
+	}
+
+	archived := true
+	if changed, err := store.UpdateMessages(ctx, ids[:550], MessageUpdate{Archived: &archived}); err != nil || changed != 550 {
+		t.Errorf("archive = %d, %v; want 550", changed, err)
+	}
+	if got := countMessages(t, store, MessageFilter{App: "backup", HideArchived: true}); got != 50 {
+		t.Errorf("%d unarchived backup messages, want 50", got)
+	}
+
+	if changed, err := store.UpdateMessages(ctx, ids, MessageUpdate{RemoveTag: "nightly"}); err != nil || changed != 600 {
+		t.Errorf("untag = %d, %v; want 600", changed, err)
+	}
+	if got := countMessages(t, store, MessageFilter{Tag: "nightly"}); got != 0 {
+		t.Errorf("%d messages still tagged", got)
+	}
+	msg, _, err := store.GetMessage(ctx, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Tags) != 0 {
+		t.Errorf("message 4 tags = %v, want none", msg.Tags)
+	}
+
+	if _, err := store.UpdateMessages(ctx, ids, MessageUpdate{}); err == nil {
+		t.Error("empty update succeeded")
+	}
+	if _, err := store.UpdateMessages(ctx, ids, MessageUpdate{AddTag: "a", RemoveTag: "b"}); err == nil {
+		t.Error("adding and removing a tag at once succeeded")
+	}
+}
+
+func TestBulkEmptyMatch(t *testing.T) {
+	ctx := context.Background()
+	store := bulkStore(t)
+
+	ids, err := store.MessageIDs(ctx, MessageFilter{App: "nobody"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 0 {
+		t.Fatalf("matched %d messages, want none", len(ids))
+	}
+	if deleted, err := store.DeleteMessages(ctx, ids); err != nil || deleted != 0 {
+		t.Errorf("DeleteMessages(none) = %d, %v", deleted, err)
+	}
+	if changed, err := store.UpdateMessages(ctx, ids, MessageUpdate{AddTag: "x"}); err != nil || changed != 0 {
+		t.Errorf("UpdateMessages(none) = %d, %v", changed, err)
+	}
+	if got := countMessages(t, store, MessageFilter{}); got != 2400 {
+		t.Errorf("%d messages left, want 2400", got)
+	}
+}
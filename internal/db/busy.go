@@ -0,0 +1,57 @@
+// ABOUTME: Retries statements that find the database locked by another connection or process.
+// ABOUTME: Covers the SQLITE_BUSY cases busy_timeout doesn't, such as bursts while push daemon writes.
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// SQLite result codes for a locked database; extended codes share the low byte.
+const (
+	sqliteBusy   = 5
+	sqliteLocked = 6
+)
+
+// busyRetries is how many more times a statement is tried after finding the
+// database locked. Each try already waits out busy_timeout where SQLite
+// allows it, so this mostly covers the cases it doesn't, such as a snapshot
+// that went stale or a WAL checkpoint in another process.
+const busyRetries = 4
+
+// busyBackoff is the wait before the first retry; it doubles for each one after.
+const busyBackoff = 100 * time.Millisecond
+
+// IsBusy reports whether err is SQLite saying the database is locked.
+func IsBusy(err error) bool {
+	var coded interface{ Code() int }
+	if !errors.As(err, &coded) {
+		return false
+	}
+	code := coded.Code() & 0xff
+	return code == sqliteBusy || code == sqliteLocked
+}
+
+// retryBusy runs op, running it again after a backoff while it fails because
+// the database is locked, until busyRetries run out or ctx is done. op must be
+// safe to repeat: a single statement or the start of a transaction.
+func retryBusy(ctx context.Context, op func() error) error {
+	wait := busyBackoff
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || !IsBusy(err) || attempt == busyRetries {
+			return err
+		}
+		slog.Debug("database busy; retrying", "attempt", attempt+1, "wait", wait, "error", err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		wait *= 2
+	}
+}
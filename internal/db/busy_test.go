@@ -0,0 +1,98 @@
+// ABOUTME: Tests for retrying statements while the database is locked.
+// ABOUTME: Checks busy detection by result code and when retrying stops.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type codedError int
+
+func (e codedError) Error() string { return fmt.Sprintf("sqlite error %d", int(e)) }
+func (e codedError) Code() int     { return int(e) }
+
+func TestRetryBusy(t *testing.T) {
+	ctx := context.Background()
+	busySnapshot := codedError(sqliteBusy | 2<<8)
+	for err, want := range map[error]bool{
+		codedError(sqliteBusy):   true,
+		codedError(sqliteLocked): true,
+		busySnapshot:             true,
+		fmt.Errorf("insert message: %w", busySnapshot): true,
+		codedError(19):                   false,
+		errors.New("database is locked"): false,
+	} {
+		if got := IsBusy(err); got != want {
+			t.Errorf("IsBusy(%v) = %v, want %v", err, got, want)
+		}
+	}
+
+	calls := 0
+	err := retryBusy(ctx, func() error {
+		if calls++; calls < 3 {
+			return codedError(sqliteBusy)
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("retryBusy() = %v after %d calls, want success on the third", err, calls)
+	}
+
+	calls = 0
+	if err := retryBusy(ctx, func() error { calls++; return codedError(19) }); err == nil || calls != 1 {
+		t.Errorf("retryBusy() on another error = %v after %d calls, want it returned at once", err, calls)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	calls = 0
+	if err := retryBusy(cancelled, func() error { calls++; return codedError(sqliteBusy) }); !IsBusy(err) || calls != 1 {
+		t.Errorf("retryBusy() with a done context = %v after %d calls, want the busy error at once", err, calls)
+	}
+}
+
+func TestConcurrentStores(t *testing.T) {
+	// Two stores on one file stand in for push daemon and push mcp.
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "push.db")
+	first, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = first.Close() }()
+	second, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = second.Close() }()
+
+	errs := make(chan error, 2)
+	for _, store := range []*Store{first, second} {
+		go func() {
+			for i := range 50 {
+				if err := store.LogSent(ctx, SentRecord{Message: fmt.Sprintf("burst %d", i), SentAt: time.Now()}); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := store.ListSent(ctx, SentFilter{Limit: 5}); err != nil {
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatalf("concurrent writes failed: %v", err)
+		}
+	}
+	if sent, err := first.ListSent(ctx, SentFilter{}); err != nil || len(sent) != 100 {
+		t.Errorf("ListSent() = %d records, %v; want 100", len(sent), err)
+	}
+}
@@ -0,0 +1,173 @@
+// ABOUTME: Persistence for the calendar feeds push calendar alerts on.
+// ABOUTME: Stores each feed's sync state and which events have already been alerted.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CalendarRecord mirrors the calendars table.
+type CalendarRecord struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Lead is how long before an event starts its alert goes out.
+	Lead     time.Duration `json:"lead"`
+	Priority int           `json:"priority"`
+	// ETag and LastModified make the next fetch conditional.
+	ETag         string    `json:"-"`
+	LastModified string    `json:"-"`
+	LastSynced   time.Time `json:"last_synced,omitzero"`
+	LastError    string    `json:"last_error,omitempty"`
+	// Events counts the events in the feed at the last successful sync.
+	Events    int       `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SaveCalendar adds a calendar feed, or updates the one with the same name
+// and forgets its sync state so the next sync fetches it in full.
+func (s *Store) SaveCalendar(ctx context.Context, c CalendarRecord) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	if c.Name == "" || c.URL == "" {
+		return errors.New("calendar name and url are required")
+	}
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	_, err := s.write.ExecContext(ctx,
+		`INSERT INTO calendars (name, url, lead_seconds, priority, etag, last_modified, last_synced, last_error, events, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(name) DO UPDATE SET
+            url=excluded.url,
+            lead_seconds=excluded.lead_seconds,
+            priority=excluded.priority,
+            etag=excluded.etag,
+            last_modified=excluded.last_modified,
+            last_synced=excluded.last_synced,
+            last_error=excluded.last_error,
+            events=excluded.events;`,
+		c.Name, c.URL, int64(c.Lead/time.Second), c.Priority, nullIfEmpty(c.ETag), nullIfEmpty(c.LastModified),
+		nullTime(c.LastSynced), nullIfEmpty(c.LastError), c.Events, c.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("save calendar: %w", err)
+	}
+	return nil
+}
+
+// ListCalendars returns every calendar feed ordered by name.
+func (s *Store) ListCalendars(ctx context.Context) ([]CalendarRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	rows, err := s.sql.QueryContext(ctx,
+		`SELECT name, url, lead_seconds, priority, etag, last_modified, last_synced, last_error, events, created_at
+        FROM calendars ORDER BY name ASC;`)
+	if err != nil {
+		return nil, fmt.Errorf("query calendars: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []CalendarRecord
+	for rows.Next() {
+		var rec CalendarRecord
+		var leadSeconds int64
+		var etag, lastModified, lastError sql.NullString
+		var lastSynced sql.NullTime
+		if err := rows.Scan(&rec.Name, &rec.URL, &leadSeconds, &rec.Priority, &etag, &lastModified,
+			&lastSynced, &lastError, &rec.Events, &rec.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan calendar: %w", err)
+		}
+		rec.Lead = time.Duration(leadSeconds) * time.Second
+		rec.ETag = etag.String
+		rec.LastModified = lastModified.String
+		rec.LastSynced = lastSynced.Time
+		rec.LastError = lastError.String
+		results = append(results, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate calendars: %w", err)
+	}
+	return results, nil
+}
+
+// UpdateCalendarSync records the outcome of a sync.
+func (s *Store) UpdateCalendarSync(ctx context.Context, c CalendarRecord) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := s.write.ExecContext(ctx,
+		`UPDATE calendars SET etag = ?, last_modified = ?, last_synced = ?, last_error = ?, events = ? WHERE name = ?;`,
+		nullIfEmpty(c.ETag), nullIfEmpty(c.LastModified), nullTime(c.LastSynced), nullIfEmpty(c.LastError), c.Events, c.Name)
+	if err != nil {
+		return fmt.Errorf("update calendar: %w", err)
+	}
+	return nil
+}
+
+// DeleteCalendar removes a calendar feed and its alert history, reporting
+// whether it existed.
+func (s *Store) DeleteCalendar(ctx context.Context, name string) (bool, error) {
+	if s == nil || s.write == nil {
+		return false, errors.New("database not initialized")
+	}
+	if _, err := s.write.ExecContext(ctx, `DELETE FROM calendar_alerts WHERE calendar = ?;`, name); err != nil {
+		return false, fmt.Errorf("delete calendar alerts: %w", err)
+	}
+	res, err := s.write.ExecContext(ctx, `DELETE FROM calendars WHERE name = ?;`, name)
+	if err != nil {
+		return false, fmt.Errorf("delete calendar: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete calendar: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// CalendarAlerted reports whether the event occurrence identified by key has
+// already been alerted for calendar.
+func (s *Store) CalendarAlerted(ctx context.Context, calendar, key string) (bool, error) {
+	if s == nil || s.sql == nil {
+		return false, errors.New("database not initialized")
+	}
+	var n int
+	err := s.sql.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM calendar_alerts WHERE calendar = ? AND key = ?;`, calendar, key).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("query calendar alerts: %w", err)
+	}
+	return n > 0, nil
+}
+
+// MarkCalendarAlerted records that the occurrence identified by key, starting
+// at startsAt, was alerted at alertedAt.
+func (s *Store) MarkCalendarAlerted(ctx context.Context, calendar, key string, startsAt, alertedAt time.Time) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := s.write.ExecContext(ctx,
+		`INSERT OR IGNORE INTO calendar_alerts (calendar, key, starts_at, alerted_at) VALUES (?, ?, ?, ?);`,
+		calendar, key, startsAt.UTC(), alertedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("record calendar alert: %w", err)
+	}
+	return nil
+}
+
+// PruneCalendarAlerts forgets alerts for events that started before cutoff;
+// they can't come round again.
+func (s *Store) PruneCalendarAlerts(ctx context.Context, cutoff time.Time) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	if _, err := s.write.ExecContext(ctx, `DELETE FROM calendar_alerts WHERE starts_at < ?;`, cutoff.UTC()); err != nil {
+		return fmt.Errorf("prune calendar alerts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// ABOUTME: Cached lookup lists fetched from the Pushover API.
+// ABOUTME: Stores sound and device names so prompts can offer valid choices offline.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Catalog kinds.
+const (
+	CatalogSounds  = "sounds"
+	CatalogDevices = "devices"
+)
+
+// CatalogEntry is one cached choice, such as a sound name and its description.
+type CatalogEntry struct {
+	Name  string `json:"name"`
+	Label string `json:"label,omitempty"`
+}
+
+// ReplaceCatalog swaps the cached entries of a kind for a freshly fetched list.
+func (s *Store) ReplaceCatalog(ctx context.Context, kind string, entries []CatalogEntry, fetchedAt time.Time) error {
+	if s == nil || s.sql == nil {
+		return errors.New("database not initialized")
+	}
+
+	tx, err := s.write.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin catalog tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM catalog WHERE kind = ?;`, kind); err != nil {
+		return fmt.Errorf("clear catalog: %w", err)
+	}
+	for _, entry := range entries {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR REPLACE INTO catalog (kind, name, label, fetched_at) VALUES (?, ?, ?, ?);`,
+			kind, entry.Name, entry.Label, fetchedAt.UTC(),
+		); err != nil {
+			return fmt.Errorf("save catalog entry: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit catalog: %w", err)
+	}
+	return nil
+}
+
+// Catalog returns the cached entries of a kind sorted by name, along with when
+// they were fetched. A zero time means nothing is cached.
+func (s *Store) Catalog(ctx context.Context, kind string) ([]CatalogEntry, time.Time, error) {
+	if s == nil || s.sql == nil {
+		return nil, time.Time{}, errors.New("database not initialized")
+	}
+
+	rows, err := s.sql.QueryContext(ctx,
+		`SELECT name, COALESCE(label, ''), fetched_at FROM catalog WHERE kind = ? ORDER BY name ASC;`, kind)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("query catalog: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var (
+		entries   []CatalogEntry
+		fetchedAt time.Time
+	)
+	for rows.Next() {
+		var entry CatalogEntry
+		var at time.Time
+		if err := rows.Scan(&entry.Name, &entry.Label, &at); err != nil {
+			return nil, time.Time{}, fmt.Errorf("scan catalog: %w", err)
+		}
+		if fetchedAt.IsZero() || at.Before(fetchedAt) {
+			fetchedAt = at
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("iterate catalog: %w", err)
+	}
+	return entries, fetchedAt, nil
+}
@@ -5,19 +5,45 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
 )
 
-// Store wraps the SQLite handle and exposes helpers for persistence operations.
+// Store wraps the SQLite handles and exposes helpers for persistence operations.
+// Reads share a small pool; writes go through a single-connection handle so
+// concurrent writers in one process queue instead of racing for the lock.
 type Store struct {
-	sql *sql.DB
+	sql   *tracedDB
+	write *tracedDB
+	// memory is set for stores opened at Memory.
+	memory bool
+	// readOnly is set for stores opened with OpenReadOnly.
+	readOnly bool
+}
+
+// Memory opens a store that lives only as long as the process, for
+// ephemeral runs that must not leave history on disk.
+const Memory = ":memory:"
+
+const readPoolSize = 4
+
+// sqlitePragmas apply to every pooled connection. WAL lets the CLI, daemon,
+// and MCP server read while another process writes; busy_timeout covers
+// cross-process write contention.
+var sqlitePragmas = []string{
+	"busy_timeout(5000)",
+	"journal_mode(WAL)",
+	"synchronous(NORMAL)",
 }
 
 // MessageRecord mirrors the messages table schema.
@@ -36,17 +62,54 @@ type MessageRecord struct {
 	URL        string
 	Acked      bool
 	HTML       bool
+	// Device is the registered device that received the message.
+	Device string
+	// Receipt is set on emergency messages that need acknowledging.
+	Receipt string
+	// IconHash identifies the cached icon in the media table, if downloaded.
+	IconHash string
+	// RawJSON is the message object exactly as the API returned it.
+	RawJSON string `json:"-"`
+	// Thread groups the message with related sent and received ones; see
+	// PersistMessages.
+	Thread string
+	// Archived messages are left out of history listings unless asked for.
+	Archived bool
+	// Tags label the message for filtering; see UpdateMessages. Left out of
+	// JSON when empty, since MCP output schemas don't accept null arrays.
+	Tags []string `json:"Tags,omitempty"`
+	// SentID is the sent log ID of the notification this message is a copy
+	// of, when it was sent from here to your own user; see linkSent.
+	SentID int64 `json:"SentID,omitempty"`
 }
 
 // SentRecord mirrors the sent table.
 type SentRecord struct {
-	ID        int64
-	Message   string
-	Title     string
-	Device    string
-	Priority  int
-	SentAt    time.Time
-	RequestID string
+	ID          int64
+	Message     string
+	Title       string
+	Device      string
+	Priority    int
+	SentAt      time.Time
+	RequestID   string
+	ContentHash string
+	Suppressed  bool
+	Via         string
+	// Recipient names who a send --user went to; empty for the configured user_key.
+	Recipient string
+	// App is the [apps] entry the send used; empty for app_token.
+	App string
+	// Error is set when the send failed, as for rows of a batch send.
+	Error string
+	// Receipt is set for emergency sends. The Receipt* fields below track
+	// it, starting from ReceiptPending, as UpdateReceipt records changes.
+	Receipt          string
+	ReceiptStatus    string
+	ReceiptAckedAt   time.Time
+	ReceiptAckedBy   string
+	ReceiptExpiresAt time.Time
+	// Thread is the --thread key the notification was sent with.
+	Thread string
 }
 
 // Open creates (if necessary) and opens the SQLite database.
@@ -54,42 +117,174 @@ func Open(path string) (*Store, error) {
 	if path == "" {
 		return nil, errors.New("database path is empty")
 	}
+	if path == Memory {
+		return openMemory()
+	}
 
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return nil, fmt.Errorf("creating database directory: %w", err)
 	}
 
-	conn, err := sql.Open("sqlite", path)
+	write, err := openPool(path, "immediate")
 	if err != nil {
-		return nil, fmt.Errorf("opening database: %w", err)
+		return nil, err
+	}
+	write.SetMaxOpenConns(1)
+	write.SetMaxIdleConns(1)
+
+	traced := &tracedDB{write}
+	store := &Store{sql: traced, write: traced}
+	if err := store.migrate(); err != nil {
+		_ = write.Close()
+		return nil, err
+	}
+
+	read, err := openPool(path, "")
+	if err != nil {
+		_ = write.Close()
+		return nil, err
+	}
+	read.SetMaxOpenConns(readPoolSize)
+	read.SetMaxIdleConns(readPoolSize)
+	store.sql = &tracedDB{read}
+
+	return store, nil
+}
+
+// OpenReadOnly opens an existing database without writing to it, for
+// inspecting a backup or a copy from another machine. Nothing is migrated, so
+// a database from an older push that lacks columns history reads is refused;
+// writes fail with SQLite's read-only error.
+func OpenReadOnly(path string) (*Store, error) {
+	if path == "" {
+		return nil, errors.New("database path is empty")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("no database at %s", path)
 	}
 
-	if _, err := conn.Exec(`PRAGMA busy_timeout = 5000;`); err != nil {
+	// No journal_mode pragma: switching to WAL is itself a write.
+	query := url.Values{"mode": {"ro"}, "_pragma": {"busy_timeout(5000)"}}
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?" + query.Encode()
+	conn, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	if err := conn.Ping(); err != nil {
 		_ = conn.Close()
-		return nil, fmt.Errorf("configuring sqlite: %w", err)
+		return nil, fmt.Errorf("opening database read-only: %w", err)
+	}
+	conn.SetMaxOpenConns(readPoolSize)
+	conn.SetMaxIdleConns(readPoolSize)
+
+	traced := &tracedDB{conn}
+	for _, col := range addedColumns {
+		if col.table != "messages" && col.table != "sent" {
+			continue
+		}
+		found, err := hasColumn(traced, col.table, col.name)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		if !found {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%s is from an older push (no %s.%s column); open a copy without read-only mode to upgrade it", path, col.table, col.name)
+		}
+	}
+	return &Store{sql: traced, write: traced, readOnly: true}, nil
+}
+
+// ReadOnly reports whether the store was opened with OpenReadOnly.
+func (s *Store) ReadOnly() bool {
+	return s != nil && s.readOnly
+}
+
+// openMemory opens a store at Memory. Every SQLite connection to ":memory:"
+// gets its own empty database, so reads and writes share one connection that
+// is never closed while the store is open.
+func openMemory() (*Store, error) {
+	conn, err := openPool(Memory, "immediate")
+	if err != nil {
+		return nil, err
 	}
+	conn.SetMaxOpenConns(1)
+	conn.SetMaxIdleConns(1)
+	conn.SetConnMaxLifetime(0)
+	conn.SetConnMaxIdleTime(0)
 
-	store := &Store{sql: conn}
+	traced := &tracedDB{conn}
+	store := &Store{sql: traced, write: traced, memory: true}
 	if err := store.migrate(); err != nil {
 		_ = conn.Close()
 		return nil, err
 	}
-
 	return store, nil
 }
 
-// Close releases the underlying SQL handle.
+// InMemory reports whether the store was opened at Memory, so nothing it
+// holds outlives the process.
+func (s *Store) InMemory() bool {
+	return s != nil && s.memory
+}
+
+func openPool(path, txlock string) (*sql.DB, error) {
+	query := url.Values{"_pragma": sqlitePragmas}
+	if txlock != "" {
+		query.Set("_txlock", txlock)
+	}
+
+	conn, err := sql.Open("sqlite", path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	if err := conn.Ping(); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("configuring sqlite: %w", err)
+	}
+	return conn, nil
+}
+
+// Close releases the underlying SQL handles.
 func (s *Store) Close() error {
 	if s == nil || s.sql == nil {
 		return nil
 	}
-	return s.sql.Close()
+	if s.write == nil || s.write == s.sql {
+		return s.sql.Close()
+	}
+	return errors.Join(s.sql.Close(), s.write.Close())
 }
 
 func (s *Store) migrate() error {
-	stmts := []string{
-		`CREATE TABLE IF NOT EXISTS messages (
+	for _, stmt := range schema {
+		if _, err := s.write.Exec(stmt); err != nil {
+			return fmt.Errorf("running migration: %w", err)
+		}
+	}
+
+	for _, col := range addedColumns {
+		if err := s.addColumnIfMissing(col.table, col.name, col.ddl); err != nil {
+			return err
+		}
+	}
+
+	for _, stmt := range addedIndexes {
+		if _, err := s.write.Exec(stmt); err != nil {
+			return fmt.Errorf("running migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// schema creates the tables and the indexes on their original columns.
+var schema = []string{
+	// Takes effect on new databases, and on older ones at their next
+	// VACUUM, so background maintenance can free pages incrementally.
+	`PRAGMA auto_vacuum = INCREMENTAL;`,
+	`CREATE TABLE IF NOT EXISTS messages (
             id INTEGER PRIMARY KEY,
             pushover_id INTEGER UNIQUE,
             umid TEXT,
@@ -105,7 +300,7 @@ func (s *Store) migrate() error {
             acked INTEGER DEFAULT 0,
             html INTEGER DEFAULT 0
         );`,
-		`CREATE TABLE IF NOT EXISTS sent (
+	`CREATE TABLE IF NOT EXISTS sent (
             id INTEGER PRIMARY KEY,
             message TEXT NOT NULL,
             title TEXT,
@@ -114,94 +309,228 @@ func (s *Store) migrate() error {
             sent_at DATETIME DEFAULT CURRENT_TIMESTAMP,
             request_id TEXT
         );`,
-		`CREATE INDEX IF NOT EXISTS idx_messages_received_at ON messages(received_at);`,
-		`CREATE INDEX IF NOT EXISTS idx_sent_sent_at ON sent(sent_at);`,
-	}
-
-	for _, stmt := range stmts {
-		if _, err := s.sql.Exec(stmt); err != nil {
-			return fmt.Errorf("running migration: %w", err)
-		}
-	}
+	`CREATE TABLE IF NOT EXISTS heartbeats (
+            name TEXT PRIMARY KEY,
+            every_seconds INTEGER NOT NULL,
+            last_seen DATETIME NOT NULL,
+            alerted_at DATETIME
+        );`,
+	`CREATE TABLE IF NOT EXISTS send_slots (
+            id INTEGER PRIMARY KEY,
+            reserved_at DATETIME NOT NULL
+        );`,
+	`CREATE TABLE IF NOT EXISTS media (
+            hash TEXT NOT NULL,
+            url TEXT PRIMARY KEY,
+            path TEXT NOT NULL,
+            content_type TEXT,
+            size INTEGER DEFAULT 0,
+            fetched_at DATETIME NOT NULL
+        );`,
+	`CREATE INDEX IF NOT EXISTS idx_media_hash ON media(hash);`,
+	`CREATE TABLE IF NOT EXISTS scheduled (
+            id INTEGER PRIMARY KEY,
+            message TEXT NOT NULL,
+            title TEXT,
+            priority INTEGER DEFAULT 0,
+            device TEXT,
+            via TEXT,
+            send_at DATETIME NOT NULL,
+            pid INTEGER,
+            status TEXT NOT NULL DEFAULT 'pending',
+            created_at DATETIME NOT NULL
+        );`,
+	`CREATE TABLE IF NOT EXISTS catalog (
+            kind TEXT NOT NULL,
+            name TEXT NOT NULL,
+            label TEXT,
+            fetched_at DATETIME NOT NULL,
+            PRIMARY KEY (kind, name)
+        );`,
+	`CREATE TABLE IF NOT EXISTS state (
+            key TEXT PRIMARY KEY,
+            value TEXT NOT NULL,
+            updated_at DATETIME NOT NULL
+        );`,
+	`CREATE TABLE IF NOT EXISTS responses (
+            id INTEGER PRIMARY KEY,
+            token TEXT NOT NULL UNIQUE,
+            message TEXT NOT NULL,
+            title TEXT,
+            choices TEXT,
+            request_id TEXT,
+            status TEXT NOT NULL DEFAULT 'waiting',
+            choice TEXT,
+            created_at DATETIME NOT NULL,
+            opened_at DATETIME,
+            responded_at DATETIME
+        );`,
+	`CREATE TABLE IF NOT EXISTS monitors (
+            name TEXT PRIMARY KEY,
+            url TEXT NOT NULL,
+            every_seconds INTEGER NOT NULL,
+            timeout_seconds INTEGER NOT NULL,
+            expect TEXT NOT NULL,
+            confirm INTEGER NOT NULL DEFAULT 2,
+            state TEXT NOT NULL DEFAULT 'unknown',
+            streak INTEGER NOT NULL DEFAULT 0,
+            last_checked DATETIME,
+            last_result TEXT,
+            last_change DATETIME,
+            created_at DATETIME NOT NULL
+        );`,
+	`CREATE TABLE IF NOT EXISTS fswatches (
+            name TEXT PRIMARY KEY,
+            path TEXT NOT NULL,
+            pattern TEXT NOT NULL DEFAULT '',
+            events TEXT NOT NULL,
+            priority INTEGER NOT NULL DEFAULT 0,
+            last_fired DATETIME,
+            last_file TEXT,
+            created_at DATETIME NOT NULL
+        );`,
+	`CREATE TABLE IF NOT EXISTS sysmon (
+            name TEXT PRIMARY KEY,
+            metric TEXT NOT NULL,
+            path TEXT NOT NULL DEFAULT '',
+            threshold REAL NOT NULL,
+            every_seconds INTEGER NOT NULL,
+            state TEXT NOT NULL DEFAULT 'ok',
+            last_checked DATETIME,
+            last_value REAL,
+            last_change DATETIME,
+            created_at DATETIME NOT NULL
+        );`,
+	`CREATE TABLE IF NOT EXISTS calendars (
+            name TEXT PRIMARY KEY,
+            url TEXT NOT NULL,
+            lead_seconds INTEGER NOT NULL,
+            priority INTEGER NOT NULL DEFAULT 0,
+            etag TEXT,
+            last_modified TEXT,
+            last_synced DATETIME,
+            last_error TEXT,
+            events INTEGER NOT NULL DEFAULT 0,
+            created_at DATETIME NOT NULL
+        );`,
+	`CREATE TABLE IF NOT EXISTS calendar_alerts (
+            calendar TEXT NOT NULL,
+            key TEXT NOT NULL,
+            starts_at DATETIME NOT NULL,
+            alerted_at DATETIME NOT NULL,
+            PRIMARY KEY (calendar, key)
+        );`,
+	`CREATE TABLE IF NOT EXISTS feeds (
+            name TEXT PRIMARY KEY,
+            url TEXT NOT NULL,
+            filter TEXT NOT NULL DEFAULT '',
+            priority INTEGER NOT NULL DEFAULT 0,
+            every_seconds INTEGER NOT NULL,
+            etag TEXT,
+            last_modified TEXT,
+            last_checked DATETIME,
+            last_error TEXT,
+            last_item TEXT,
+            created_at DATETIME NOT NULL
+        );`,
+	`CREATE TABLE IF NOT EXISTS feed_items (
+            feed TEXT NOT NULL,
+            guid TEXT NOT NULL,
+            seen_at DATETIME NOT NULL,
+            PRIMARY KEY (feed, guid)
+        );`,
+	`CREATE TABLE IF NOT EXISTS mutes (
+            app TEXT PRIMARY KEY COLLATE NOCASE,
+            until DATETIME,
+            created_at DATETIME NOT NULL
+        );`,
+	`CREATE INDEX IF NOT EXISTS idx_messages_received_at ON messages(received_at);`,
+	`CREATE INDEX IF NOT EXISTS idx_sent_sent_at ON sent(sent_at);`,
+}
 
-	return nil
+// addedIndexes cover addedColumns, so they run after those exist.
+var addedIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS idx_sent_content_hash ON sent(content_hash, sent_at);`,
+	`CREATE INDEX IF NOT EXISTS idx_sent_thread ON sent(thread);`,
+	`CREATE INDEX IF NOT EXISTS idx_messages_thread ON messages(thread);`,
+	`CREATE INDEX IF NOT EXISTS idx_messages_sent_id ON messages(sent_id);`,
+	// matchThread looks up threaded notifications by title for every
+	// message stored; most have no thread, so these stay small.
+	`CREATE INDEX IF NOT EXISTS idx_messages_thread_title ON messages(app, title COLLATE NOCASE, received_at) WHERE thread IS NOT NULL;`,
+	`CREATE INDEX IF NOT EXISTS idx_sent_thread_title ON sent(title COLLATE NOCASE, sent_at) WHERE thread IS NOT NULL;`,
 }
 
-// PersistMessages inserts the provided message records, ignoring duplicates.
-func (s *Store) PersistMessages(ctx context.Context, msgs []MessageRecord) (int, error) {
-	if s == nil || s.sql == nil {
-		return 0, errors.New("database not initialized")
-	}
-	if len(msgs) == 0 {
-		return 0, nil
-	}
+// addedColumns are the columns added since the tables were first created,
+// which migrate adds to older databases.
+var addedColumns = []struct{ table, name, ddl string }{
+	{"sent", "content_hash", "TEXT"},
+	{"sent", "suppressed", "INTEGER DEFAULT 0"},
+	{"sent", "via", "TEXT"},
+	{"sent", "error", "TEXT"},
+	{"sent", "recipient", "TEXT"},
+	{"sent", "app", "TEXT"},
+	{"sent", "receipt", "TEXT"},
+	{"sent", "receipt_status", "TEXT"},
+	{"sent", "receipt_acked_at", "DATETIME"},
+	{"sent", "receipt_acked_by", "TEXT"},
+	{"sent", "receipt_expires_at", "DATETIME"},
+	{"messages", "raw_json", "TEXT"},
+	{"messages", "icon_hash", "TEXT"},
+	{"messages", "device", "TEXT"},
+	{"messages", "receipt", "TEXT"},
+	{"scheduled", "kind", "TEXT"},
+	{"sent", "thread", "TEXT"},
+	{"messages", "thread", "TEXT"},
+	{"messages", "archived", "INTEGER DEFAULT 0"},
+	{"messages", "tags", "TEXT"},
+	{"messages", "sent_id", "INTEGER"},
+}
 
-	tx, err := s.sql.BeginTx(ctx, nil)
-	if err != nil {
-		return 0, fmt.Errorf("begin tx: %w", err)
-	}
-
-	inserted := 0
-	stmt, err := tx.PrepareContext(ctx, `INSERT INTO messages (
-            pushover_id, umid, title, message, app, aid, icon,
-            received_at, sent_at, priority, url, acked, html
-        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-        ON CONFLICT(pushover_id) DO UPDATE SET
-            umid=excluded.umid,
-            title=excluded.title,
-            message=excluded.message,
-            app=excluded.app,
-            aid=excluded.aid,
-            icon=excluded.icon,
-            received_at=excluded.received_at,
-            sent_at=excluded.sent_at,
-            priority=excluded.priority,
-            url=excluded.url,
-            acked=excluded.acked,
-            html=excluded.html;`)
+// hasColumn reports whether table has column.
+func hasColumn(conn *tracedDB, table, column string) (bool, error) {
+	rows, err := conn.Query(fmt.Sprintf(`PRAGMA table_info(%s);`, table))
 	if err != nil {
-		_ = tx.Rollback()
-		return 0, fmt.Errorf("prepare insert: %w", err)
+		return false, fmt.Errorf("inspect %s: %w", table, err)
 	}
-	defer func() { _ = stmt.Close() }()
+	defer func() { _ = rows.Close() }()
 
-	for _, msg := range msgs {
-		received := msg.ReceivedAt
-		if received.IsZero() {
-			received = time.Now()
-		}
-		var sent interface{}
-		if msg.SentAt != nil {
-			sent = msg.SentAt.UTC()
-		} else {
-			sent = nil
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, fmt.Errorf("inspect %s: %w", table, err)
 		}
-		if _, err := stmt.ExecContext(ctx,
-			msg.PushoverID,
-			msg.UMID,
-			msg.Title,
-			msg.Message,
-			msg.App,
-			msg.AID,
-			msg.Icon,
-			received.UTC(),
-			sent,
-			msg.Priority,
-			msg.URL,
-			boolToInt(msg.Acked),
-			boolToInt(msg.HTML),
-		); err != nil {
-			_ = tx.Rollback()
-			return inserted, fmt.Errorf("insert message: %w", err)
+		if name == column {
+			return true, nil
 		}
-		inserted++
 	}
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("inspect %s: %w", table, err)
+	}
+	return false, nil
+}
 
-	if err := tx.Commit(); err != nil {
-		return inserted, fmt.Errorf("commit messages: %w", err)
+// addColumnIfMissing extends an existing table so older databases pick up new columns.
+func (s *Store) addColumnIfMissing(table, column, ddl string) error {
+	if found, err := hasColumn(s.write, table, column); err != nil || found {
+		return err
 	}
 
-	return inserted, nil
+	if _, err := s.write.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s;`, table, column, ddl)); err != nil {
+		// Another process opening the database may have added it first.
+		if strings.Contains(err.Error(), "duplicate column name") {
+			return nil
+		}
+		return fmt.Errorf("add column %s.%s: %w", table, column, err)
+	}
+	slog.Debug("migrated database", "table", table, "added_column", column)
+	return nil
 }
 
 // LogSent persists a sent notification entry.
@@ -214,15 +543,28 @@ func (s *Store) LogSent(ctx context.Context, rec SentRecord) error {
 	if sentAt.IsZero() {
 		sentAt = time.Now()
 	}
+	if rec.Receipt != "" && rec.ReceiptStatus == "" {
+		rec.ReceiptStatus = ReceiptPending
+	}
 
-	_, err := s.sql.ExecContext(ctx,
-		`INSERT INTO sent (message, title, device, priority, sent_at, request_id) VALUES (?, ?, ?, ?, ?, ?);`,
+	_, err := s.write.ExecContext(ctx,
+		`INSERT INTO sent (message, title, device, priority, sent_at, request_id, content_hash, suppressed, via, error, recipient, app, receipt, receipt_status, receipt_expires_at, thread) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
 		rec.Message,
 		rec.Title,
 		rec.Device,
 		rec.Priority,
 		sentAt.UTC(),
 		rec.RequestID,
+		rec.ContentHash,
+		boolToInt(rec.Suppressed),
+		rec.Via,
+		nullIfEmpty(rec.Error),
+		nullIfEmpty(rec.Recipient),
+		nullIfEmpty(rec.App),
+		nullIfEmpty(rec.Receipt),
+		nullIfEmpty(rec.ReceiptStatus),
+		nullTime(rec.ReceiptExpiresAt),
+		nullIfEmpty(rec.Thread),
 	)
 	if err != nil {
 		return fmt.Errorf("insert sent record: %w", err)
@@ -230,36 +572,148 @@ func (s *Store) LogSent(ctx context.Context, rec SentRecord) error {
 	return nil
 }
 
+// MessageFilter narrows message queries. Zero values disable each filter.
+type MessageFilter struct {
+	Limit  int
+	Since  *time.Time
+	Until  *time.Time
+	Search string
+	// Regex matches message or title against a Go regular expression.
+	Regex string
+	// BodyRegex matches the message body alone against a Go regular expression.
+	BodyRegex string
+	App       string
+	Device    string
+	// MinPriority keeps messages at or above this priority when set.
+	MinPriority *int
+	// HasURL keeps only messages with a supplementary URL.
+	HasURL bool
+	// Thread keeps messages in this thread.
+	Thread string
+	// HideArchived leaves out messages archived with SetArchived.
+	HideArchived bool
+	// Tag keeps messages carrying this tag.
+	Tag string
+	// Unlinked leaves out copies of notifications sent from here.
+	Unlinked bool
+	// Cursor resumes after the last row of a previous page (see NextCursor).
+	Cursor string
+	// Offset skips this many matching rows. Prefer Cursor for walking large tables.
+	Offset int
+}
+
 // QueryMessages returns persisted messages applying the optional filters.
 func (s *Store) QueryMessages(ctx context.Context, limit int, since *time.Time, search string) ([]MessageRecord, error) {
-	if s == nil || s.sql == nil {
-		return nil, errors.New("database not initialized")
-	}
-	if limit <= 0 {
-		limit = 20
-	}
+	return s.FindMessages(ctx, MessageFilter{Limit: limit, Since: since, Search: search})
+}
 
+// where renders the filter as a SQL condition over the messages table.
+func (filter MessageFilter) where() (string, []interface{}, error) {
 	clauses := []string{"1=1"}
 	args := []interface{}{}
 
-	if since != nil && !since.IsZero() {
+	if filter.Since != nil && !filter.Since.IsZero() {
 		clauses = append(clauses, "received_at >= ?")
-		args = append(args, since.UTC())
+		args = append(args, filter.Since.UTC())
+	}
+
+	if filter.Until != nil && !filter.Until.IsZero() {
+		clauses = append(clauses, "received_at < ?")
+		args = append(args, filter.Until.UTC())
 	}
 
-	if search != "" {
-		like := fmt.Sprintf("%%%s%%", search)
+	if filter.Search != "" {
+		like := fmt.Sprintf("%%%s%%", filter.Search)
 		clauses = append(clauses, "(message LIKE ? OR title LIKE ?)")
 		args = append(args, like, like)
 	}
 
-	query := fmt.Sprintf(`SELECT id, pushover_id, umid, title, message, app, aid, icon,
-            received_at, sent_at, priority, url, acked, html
+	if filter.Regex != "" {
+		if _, err := compilePattern(filter.Regex); err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, "(message REGEXP ? OR title REGEXP ?)")
+		args = append(args, filter.Regex, filter.Regex)
+	}
+
+	if filter.BodyRegex != "" {
+		if _, err := compilePattern(filter.BodyRegex); err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, "message REGEXP ?")
+		args = append(args, filter.BodyRegex)
+	}
+
+	if filter.App != "" {
+		clauses = append(clauses, "app = ?")
+		args = append(args, filter.App)
+	}
+
+	if filter.Device != "" {
+		clauses = append(clauses, "device = ?")
+		args = append(args, filter.Device)
+	}
+
+	if filter.MinPriority != nil {
+		clauses = append(clauses, "priority >= ?")
+		args = append(args, *filter.MinPriority)
+	}
+
+	if filter.HasURL {
+		clauses = append(clauses, "COALESCE(url, '') <> ''")
+	}
+
+	if filter.Thread != "" {
+		clauses = append(clauses, "thread = ?")
+		args = append(args, filter.Thread)
+	}
+
+	if filter.Tag != "" {
+		clauses = append(clauses, "instr(COALESCE(tags, ''), ?) > 0")
+		args = append(args, tagToken(filter.Tag))
+	}
+
+	if filter.HideArchived {
+		clauses = append(clauses, "COALESCE(archived, 0) = 0")
+	}
+
+	if filter.Unlinked {
+		clauses = append(clauses, "sent_id IS NULL")
+	}
+
+	if filter.Cursor != "" {
+		at, id, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, "(received_at < ? OR (received_at = ? AND id < ?))")
+		args = append(args, at, at, id)
+	}
+
+	return strings.Join(clauses, " AND "), args, nil
+}
+
+// FindMessages returns persisted messages matching the filter, newest first.
+func (s *Store) FindMessages(ctx context.Context, filter MessageFilter) ([]MessageRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	where, args, err := filter.where()
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s
         FROM messages
         WHERE %s
-        ORDER BY received_at DESC
-        LIMIT ?;`, strings.Join(clauses, " AND "))
-	args = append(args, limit)
+        ORDER BY received_at DESC, id DESC
+        LIMIT ? OFFSET ?;`, messageColumns, where)
+	args = append(args, limit, max(filter.Offset, 0))
 
 	rows, err := s.sql.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -269,35 +723,10 @@ func (s *Store) QueryMessages(ctx context.Context, limit int, since *time.Time,
 
 	var results []MessageRecord
 	for rows.Next() {
-		var rec MessageRecord
-		var sent sql.NullTime
-		var received time.Time
-		var acked, html int
-		if err := rows.Scan(
-			&rec.ID,
-			&rec.PushoverID,
-			&rec.UMID,
-			&rec.Title,
-			&rec.Message,
-			&rec.App,
-			&rec.AID,
-			&rec.Icon,
-			&received,
-			&sent,
-			&rec.Priority,
-			&rec.URL,
-			&acked,
-			&html,
-		); err != nil {
+		rec, err := scanMessage(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan history: %w", err)
 		}
-		rec.ReceivedAt = received
-		if sent.Valid {
-			val := sent.Time
-			rec.SentAt = &val
-		}
-		rec.Acked = acked == 1
-		rec.HTML = html == 1
 		results = append(results, rec)
 	}
 
@@ -308,9 +737,289 @@ func (s *Store) QueryMessages(ctx context.Context, limit int, since *time.Time,
 	return results, nil
 }
 
+// MessagesAround returns up to n messages received just before rec and up to
+// n received just after it, both nearest first, whatever their filters.
+func (s *Store) MessagesAround(ctx context.Context, rec MessageRecord, n int) (before, after []MessageRecord, err error) {
+	if s == nil || s.sql == nil {
+		return nil, nil, errors.New("database not initialized")
+	}
+	if n <= 0 {
+		return nil, nil, nil
+	}
+	at := rec.ReceivedAt.UTC()
+	if before, err = s.selectMessages(ctx, fmt.Sprintf(`SELECT %s FROM messages
+        WHERE received_at < ? OR (received_at = ? AND id < ?)
+        ORDER BY received_at DESC, id DESC LIMIT ?;`, messageColumns), at, at, rec.ID, n); err != nil {
+		return nil, nil, err
+	}
+	if after, err = s.selectMessages(ctx, fmt.Sprintf(`SELECT %s FROM messages
+        WHERE received_at > ? OR (received_at = ? AND id > ?)
+        ORDER BY received_at ASC, id ASC LIMIT ?;`, messageColumns), at, at, rec.ID, n); err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+// selectMessages runs a query selecting messageColumns.
+func (s *Store) selectMessages(ctx context.Context, query string, args ...any) ([]MessageRecord, error) {
+	rows, err := s.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query messages: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []MessageRecord
+	for rows.Next() {
+		rec, err := scanMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan message: %w", err)
+		}
+		results = append(results, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate messages: %w", err)
+	}
+	return results, nil
+}
+
+// GetMessage returns the persisted message with the given Pushover ID.
+func (s *Store) GetMessage(ctx context.Context, pushoverID int64) (MessageRecord, bool, error) {
+	if s == nil || s.sql == nil {
+		return MessageRecord{}, false, errors.New("database not initialized")
+	}
+
+	row := s.sql.QueryRowContext(ctx,
+		fmt.Sprintf(`SELECT %s FROM messages WHERE pushover_id = ?;`, messageColumns), pushoverID)
+	rec, err := scanMessage(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return MessageRecord{}, false, nil
+	}
+	if err != nil {
+		return MessageRecord{}, false, fmt.Errorf("query message: %w", err)
+	}
+	return rec, true, nil
+}
+
+// MessagesAfter returns up to limit stored messages with Pushover IDs above
+// after and at most upTo, in ID order, received on device when it isn't
+// empty, and how many such messages there are in all. It reads back a fetch
+// already stored, a page at a time.
+func (s *Store) MessagesAfter(ctx context.Context, device string, after, upTo int64, limit int) ([]MessageRecord, int, error) {
+	if s == nil || s.sql == nil {
+		return nil, 0, errors.New("database not initialized")
+	}
+
+	where := "pushover_id > ? AND pushover_id <= ? AND (? = '' OR COALESCE(device, '') = ?)"
+	args := []any{after, upTo, device, device}
+	var total int
+	if err := s.sql.QueryRowContext(ctx, `SELECT COUNT(*) FROM messages WHERE `+where+`;`, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count messages: %w", err)
+	}
+
+	rows, err := s.sql.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM messages
+        WHERE %s
+        ORDER BY pushover_id ASC
+        LIMIT ?;`, messageColumns, where), append(args, limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query messages: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []MessageRecord
+	for rows.Next() {
+		rec, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan messages: %w", err)
+		}
+		results = append(results, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate messages: %w", err)
+	}
+	return results, total, nil
+}
+
+// GetMessageByReceipt returns the persisted emergency message with the given
+// receipt.
+func (s *Store) GetMessageByReceipt(ctx context.Context, receipt string) (MessageRecord, bool, error) {
+	if s == nil || s.sql == nil {
+		return MessageRecord{}, false, errors.New("database not initialized")
+	}
+
+	row := s.sql.QueryRowContext(ctx,
+		fmt.Sprintf(`SELECT %s FROM messages WHERE receipt = ? ORDER BY id DESC LIMIT 1;`, messageColumns), receipt)
+	rec, err := scanMessage(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return MessageRecord{}, false, nil
+	}
+	if err != nil {
+		return MessageRecord{}, false, fmt.Errorf("query message: %w", err)
+	}
+	return rec, true, nil
+}
+
+// MarkReceiptAcked records that the messages carrying receipt were acknowledged.
+func (s *Store) MarkReceiptAcked(ctx context.Context, receipt string) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	if _, err := s.write.ExecContext(ctx, `UPDATE messages SET acked = 1 WHERE receipt = ?;`, receipt); err != nil {
+		return fmt.Errorf("mark receipt acked: %w", err)
+	}
+	return nil
+}
+
+// messageColumns lists the messages columns in the order scanMessage expects.
+const messageColumns = `id, pushover_id, umid, title, message, app, aid, icon,
+            received_at, sent_at, priority, url, acked, html, raw_json, icon_hash, device, receipt, thread, archived, tags, sent_id`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMessage(row rowScanner) (MessageRecord, error) {
+	var rec MessageRecord
+	var sent sql.NullTime
+	var received time.Time
+	var acked, html int
+	var archived, sentID sql.NullInt64
+	var raw, iconHash, device, receipt, thread, tags sql.NullString
+	if err := row.Scan(
+		&rec.ID,
+		&rec.PushoverID,
+		&rec.UMID,
+		&rec.Title,
+		&rec.Message,
+		&rec.App,
+		&rec.AID,
+		&rec.Icon,
+		&received,
+		&sent,
+		&rec.Priority,
+		&rec.URL,
+		&acked,
+		&html,
+		&raw,
+		&iconHash,
+		&device,
+		&receipt,
+		&thread,
+		&archived,
+		&tags,
+		&sentID,
+	); err != nil {
+		return MessageRecord{}, err
+	}
+	rec.ReceivedAt = received
+	if sent.Valid {
+		val := sent.Time
+		rec.SentAt = &val
+	}
+	rec.Acked = acked == 1
+	rec.HTML = html == 1
+	rec.RawJSON = raw.String
+	rec.IconHash = iconHash.String
+	rec.Device = device.String
+	rec.Receipt = receipt.String
+	rec.Thread = thread.String
+	rec.Archived = archived.Int64 == 1
+	rec.Tags = splitTags(tags.String)
+	rec.SentID = sentID.Int64
+	return rec, nil
+}
+
+// NextCursor returns the cursor that continues after the last record, or an
+// empty string when records is empty.
+func NextCursor(records []MessageRecord) string {
+	if len(records) == 0 {
+		return ""
+	}
+	last := records[len(records)-1]
+	raw := fmt.Sprintf("%d:%d", last.ReceivedAt.UTC().UnixNano(), last.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor: %w", err)
+	}
+	nanosStr, idStr, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, 0, errors.New("invalid cursor")
+	}
+	nanos, err := strconv.ParseInt(nanosStr, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor: %w", err)
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return time.Unix(0, nanos).UTC(), id, nil
+}
+
 func boolToInt(v bool) int {
 	if v {
 		return 1
 	}
 	return 0
 }
+
+func nullIfEmpty(v string) any {
+	if v == "" {
+		return nil
+	}
+	return v
+}
+
+func nullIfZero(v int64) any {
+	if v == 0 {
+		return nil
+	}
+	return v
+}
+
+// LastDeliveredByHash returns the most recent successful, non-suppressed send
+// with the given content hash.
+func (s *Store) LastDeliveredByHash(ctx context.Context, hash string) (SentRecord, bool, error) {
+	if s == nil || s.sql == nil {
+		return SentRecord{}, false, errors.New("database not initialized")
+	}
+
+	var rec SentRecord
+	var title, device, requestID sql.NullString
+	err := s.sql.QueryRowContext(ctx,
+		`SELECT id, message, title, device, priority, sent_at, request_id
+        FROM sent
+        WHERE content_hash = ? AND COALESCE(suppressed, 0) = 0 AND error IS NULL
+        ORDER BY sent_at DESC
+        LIMIT 1;`, hash).Scan(&rec.ID, &rec.Message, &title, &device, &rec.Priority, &rec.SentAt, &requestID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return SentRecord{}, false, nil
+	}
+	if err != nil {
+		return SentRecord{}, false, fmt.Errorf("query sent by hash: %w", err)
+	}
+	rec.Title = title.String
+	rec.Device = device.String
+	rec.RequestID = requestID.String
+	rec.ContentHash = hash
+	return rec, true, nil
+}
+
+// CountSuppressedSince counts duplicate sends skipped after the given time.
+func (s *Store) CountSuppressedSince(ctx context.Context, hash string, since time.Time) (int, error) {
+	if s == nil || s.sql == nil {
+		return 0, errors.New("database not initialized")
+	}
+
+	var count int
+	err := s.sql.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sent WHERE content_hash = ? AND suppressed = 1 AND sent_at > ?;`,
+		hash, since.UTC()).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count suppressed sends: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,154 @@
+// ABOUTME: Per-app digest of message history.
+// ABOUTME: Summarizes each app's message counts and most recent message, and when the last digest went out.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const digestKey = "digest_sent_at"
+
+// AppDigest summarizes one application's messages within a filter.
+type AppDigest struct {
+	App    string        `json:"app"`
+	Count  int           `json:"count"`
+	Latest MessageRecord `json:"latest"`
+}
+
+// DigestByApp groups messages matching filter by app, busiest first. Limit,
+// Offset, and Cursor are ignored.
+func (s *Store) DigestByApp(ctx context.Context, filter MessageFilter) ([]AppDigest, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+	filter.Cursor = ""
+	where, args, err := filter.where()
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`WITH matched AS (
+            SELECT %s,
+                ROW_NUMBER() OVER (PARTITION BY COALESCE(app, '') ORDER BY received_at DESC, id DESC) AS rn,
+                COUNT(*) OVER (PARTITION BY COALESCE(app, '')) AS total
+            FROM messages
+            WHERE %s
+        )
+        SELECT %s, total FROM matched WHERE rn = 1
+        ORDER BY total DESC, received_at DESC;`, messageColumns, where, messageColumns)
+
+	rows, err := s.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query digest: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var digests []AppDigest
+	for rows.Next() {
+		var digest AppDigest
+		rec, err := scanMessage(extraScanner{rows, []any{&digest.Count}})
+		if err != nil {
+			return nil, fmt.Errorf("scan digest: %w", err)
+		}
+		digest.App = rec.App
+		digest.Latest = rec
+		digests = append(digests, digest)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate digest: %w", err)
+	}
+	return digests, nil
+}
+
+// extraScanner scans messageColumns followed by additional trailing columns.
+type extraScanner struct {
+	row   rowScanner
+	extra []any
+}
+
+func (e extraScanner) Scan(dest ...any) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
+
+// AppPriorityCount is how many messages one app sent at one priority.
+type AppPriorityCount struct {
+	App      string `json:"app"`
+	Priority int    `json:"priority"`
+	Count    int    `json:"count"`
+}
+
+// CountByAppPriority counts messages matching filter per app and priority.
+// Limit, Offset, and Cursor are ignored.
+func (s *Store) CountByAppPriority(ctx context.Context, filter MessageFilter) ([]AppPriorityCount, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+	filter.Cursor = ""
+	where, args, err := filter.where()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.sql.QueryContext(ctx, fmt.Sprintf(`SELECT COALESCE(app, ''), COALESCE(priority, 0), COUNT(*)
+        FROM messages WHERE %s
+        GROUP BY COALESCE(app, ''), COALESCE(priority, 0)
+        ORDER BY 1 ASC, 2 DESC;`, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query app priorities: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var counts []AppPriorityCount
+	for rows.Next() {
+		var c AppPriorityCount
+		if err := rows.Scan(&c.App, &c.Priority, &c.Count); err != nil {
+			return nil, fmt.Errorf("scan app priority: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate app priorities: %w", err)
+	}
+	return counts, nil
+}
+
+// DigestSentAt returns when push daemon last sent the scheduled digest, or
+// the zero time if it never has.
+func (s *Store) DigestSentAt(ctx context.Context) (time.Time, error) {
+	if s == nil || s.sql == nil {
+		return time.Time{}, errors.New("database not initialized")
+	}
+	var value string
+	err := s.sql.QueryRowContext(ctx, `SELECT value FROM state WHERE key = ?;`, digestKey).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query digest state: %w", err)
+	}
+	at, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse digest state: %w", err)
+	}
+	return at, nil
+}
+
+// SetDigestSentAt records when the scheduled digest was sent.
+func (s *Store) SetDigestSentAt(ctx context.Context, at time.Time) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := s.write.ExecContext(ctx,
+		`INSERT INTO state (key, value, updated_at) VALUES (?, ?, ?)
+        ON CONFLICT(key) DO UPDATE SET value=excluded.value, updated_at=excluded.updated_at;`,
+		digestKey, at.UTC().Format(time.RFC3339Nano), time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("set digest state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+// ABOUTME: Persistence for the RSS and Atom feeds push feed polls.
+// ABOUTME: Stores each feed's settings and poll state, and the item GUIDs already seen.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FeedRecord mirrors the feeds table.
+type FeedRecord struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Filter is a regular expression item titles must match to notify;
+	// empty matches all.
+	Filter   string        `json:"filter,omitempty"`
+	Priority int           `json:"priority"`
+	Every    time.Duration `json:"every"`
+	// ETag and LastModified make the next fetch conditional.
+	ETag         string    `json:"-"`
+	LastModified string    `json:"-"`
+	LastChecked  time.Time `json:"last_checked,omitzero"`
+	LastError    string    `json:"last_error,omitempty"`
+	// LastItem is the title of the last item notified.
+	LastItem  string    `json:"last_item,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Due reports whether the feed should be polled at now; slack lets it run a
+// little early, as for monitors.
+func (f FeedRecord) Due(now time.Time, slack time.Duration) bool {
+	return f.LastChecked.IsZero() || !now.Add(slack).Before(f.LastChecked.Add(f.Every))
+}
+
+// SaveFeed adds a feed, or replaces the settings and poll state of the one
+// with the same name. Seen items are kept.
+func (s *Store) SaveFeed(ctx context.Context, f FeedRecord) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	if f.Name == "" || f.URL == "" {
+		return errors.New("feed name and url are required")
+	}
+	if f.CreatedAt.IsZero() {
+		f.CreatedAt = time.Now()
+	}
+	_, err := s.write.ExecContext(ctx,
+		`INSERT INTO feeds (name, url, filter, priority, every_seconds, etag, last_modified, last_checked, last_error, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(name) DO UPDATE SET
+            url=excluded.url,
+            filter=excluded.filter,
+            priority=excluded.priority,
+            every_seconds=excluded.every_seconds,
+            etag=excluded.etag,
+            last_modified=excluded.last_modified,
+            last_checked=excluded.last_checked,
+            last_error=excluded.last_error;`,
+		f.Name, f.URL, f.Filter, f.Priority, int64(f.Every/time.Second), nullIfEmpty(f.ETag), nullIfEmpty(f.LastModified),
+		nullTime(f.LastChecked), nullIfEmpty(f.LastError), f.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("save feed: %w", err)
+	}
+	return nil
+}
+
+// ListFeeds returns every feed ordered by name.
+func (s *Store) ListFeeds(ctx context.Context) ([]FeedRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	rows, err := s.sql.QueryContext(ctx,
+		`SELECT name, url, filter, priority, every_seconds, etag, last_modified, last_checked, last_error, last_item, created_at
+        FROM feeds ORDER BY name ASC;`)
+	if err != nil {
+		return nil, fmt.Errorf("query feeds: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []FeedRecord
+	for rows.Next() {
+		var rec FeedRecord
+		var everySeconds int64
+		var etag, lastModified, lastError, lastItem sql.NullString
+		var lastChecked sql.NullTime
+		if err := rows.Scan(&rec.Name, &rec.URL, &rec.Filter, &rec.Priority, &everySeconds, &etag, &lastModified,
+			&lastChecked, &lastError, &lastItem, &rec.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan feed: %w", err)
+		}
+		rec.Every = time.Duration(everySeconds) * time.Second
+		rec.ETag = etag.String
+		rec.LastModified = lastModified.String
+		rec.LastChecked = lastChecked.Time
+		rec.LastError = lastError.String
+		rec.LastItem = lastItem.String
+		results = append(results, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate feeds: %w", err)
+	}
+	return results, nil
+}
+
+// UpdateFeedState records the outcome of a poll.
+func (s *Store) UpdateFeedState(ctx context.Context, f FeedRecord) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := s.write.ExecContext(ctx,
+		`UPDATE feeds SET etag = ?, last_modified = ?, last_checked = ?, last_error = ?, last_item = ? WHERE name = ?;`,
+		nullIfEmpty(f.ETag), nullIfEmpty(f.LastModified), nullTime(f.LastChecked), nullIfEmpty(f.LastError),
+		nullIfEmpty(f.LastItem), f.Name)
+	if err != nil {
+		return fmt.Errorf("update feed: %w", err)
+	}
+	return nil
+}
+
+// DeleteFeed removes a feed and its seen items, reporting whether it
+// existed.
+func (s *Store) DeleteFeed(ctx context.Context, name string) (bool, error) {
+	if s == nil || s.write == nil {
+		return false, errors.New("database not initialized")
+	}
+	if _, err := s.write.ExecContext(ctx, `DELETE FROM feed_items WHERE feed = ?;`, name); err != nil {
+		return false, fmt.Errorf("delete feed items: %w", err)
+	}
+	res, err := s.write.ExecContext(ctx, `DELETE FROM feeds WHERE name = ?;`, name)
+	if err != nil {
+		return false, fmt.Errorf("delete feed: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete feed: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// SeenFeedItems returns the GUIDs already seen for feed.
+func (s *Store) SeenFeedItems(ctx context.Context, feed string) (map[string]bool, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows, err := s.sql.QueryContext(ctx, `SELECT guid FROM feed_items WHERE feed = ?;`, feed)
+	if err != nil {
+		return nil, fmt.Errorf("query feed items: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	seen := map[string]bool{}
+	for rows.Next() {
+		var guid string
+		if err := rows.Scan(&guid); err != nil {
+			return nil, fmt.Errorf("scan feed item: %w", err)
+		}
+		seen[guid] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate feed items: %w", err)
+	}
+	return seen, nil
+}
+
+// MarkFeedItemsSeen records guids as still in feed at seenAt. Items that drop
+// out of the feed are forgotten by PruneFeedItems once they're old enough
+// not to come back.
+func (s *Store) MarkFeedItemsSeen(ctx context.Context, feed string, guids []string, seenAt time.Time) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+
+	tx, err := s.write.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin feed items tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, guid := range guids {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO feed_items (feed, guid, seen_at) VALUES (?, ?, ?)
+            ON CONFLICT(feed, guid) DO UPDATE SET seen_at=excluded.seen_at;`,
+			feed, guid, seenAt.UTC(),
+		); err != nil {
+			return fmt.Errorf("save feed item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit feed items: %w", err)
+	}
+	return nil
+}
+
+// TouchFeedItems marks every item of feed as seen at seenAt, for a poll the
+// server answered with "not modified".
+func (s *Store) TouchFeedItems(ctx context.Context, feed string, seenAt time.Time) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	if _, err := s.write.ExecContext(ctx, `UPDATE feed_items SET seen_at = ? WHERE feed = ?;`, seenAt.UTC(), feed); err != nil {
+		return fmt.Errorf("update feed items: %w", err)
+	}
+	return nil
+}
+
+// PruneFeedItems forgets items last seen in their feed before cutoff.
+func (s *Store) PruneFeedItems(ctx context.Context, cutoff time.Time) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	if _, err := s.write.ExecContext(ctx, `DELETE FROM feed_items WHERE seen_at < ?;`, cutoff.UTC()); err != nil {
+		return fmt.Errorf("prune feed items: %w", err)
+	}
+	return nil
+}
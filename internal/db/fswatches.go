@@ -0,0 +1,115 @@
+// ABOUTME: Persistence for filesystem watches the daemon turns into notifications.
+// ABOUTME: Stores each watch's path, filters, and when it last fired.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FSWatchRecord mirrors the fswatches table.
+type FSWatchRecord struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Pattern is a glob matched against file names; empty matches all.
+	Pattern string `json:"pattern,omitempty"`
+	// Events lists the events watched, e.g. "create,modify".
+	Events    string    `json:"events"`
+	Priority  int       `json:"priority"`
+	LastFired time.Time `json:"last_fired,omitzero"`
+	LastFile  string    `json:"last_file,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SaveFSWatch adds a watch, or replaces the settings of the one with the same
+// name.
+func (s *Store) SaveFSWatch(ctx context.Context, w FSWatchRecord) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	if w.Name == "" || w.Path == "" || w.Events == "" {
+		return errors.New("watch name, path, and events are required")
+	}
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now()
+	}
+	_, err := s.write.ExecContext(ctx,
+		`INSERT INTO fswatches (name, path, pattern, events, priority, created_at)
+        VALUES (?, ?, ?, ?, ?, ?)
+        ON CONFLICT(name) DO UPDATE SET
+            path=excluded.path,
+            pattern=excluded.pattern,
+            events=excluded.events,
+            priority=excluded.priority;`,
+		w.Name, w.Path, w.Pattern, w.Events, w.Priority, w.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("save watch: %w", err)
+	}
+	return nil
+}
+
+// ListFSWatches returns every watch ordered by name.
+func (s *Store) ListFSWatches(ctx context.Context) ([]FSWatchRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	rows, err := s.sql.QueryContext(ctx,
+		`SELECT name, path, pattern, events, priority, last_fired, last_file, created_at
+        FROM fswatches ORDER BY name ASC;`)
+	if err != nil {
+		return nil, fmt.Errorf("query watches: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []FSWatchRecord
+	for rows.Next() {
+		var rec FSWatchRecord
+		var lastFired sql.NullTime
+		var lastFile sql.NullString
+		if err := rows.Scan(&rec.Name, &rec.Path, &rec.Pattern, &rec.Events, &rec.Priority, &lastFired, &lastFile, &rec.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan watch: %w", err)
+		}
+		rec.LastFired = lastFired.Time
+		rec.LastFile = lastFile.String
+		results = append(results, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate watches: %w", err)
+	}
+	return results, nil
+}
+
+// MarkFSWatchFired records that a watch sent a notification at firedAt,
+// naming the last file it reported.
+func (s *Store) MarkFSWatchFired(ctx context.Context, name, file string, firedAt time.Time) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := s.write.ExecContext(ctx,
+		`UPDATE fswatches SET last_fired = ?, last_file = ? WHERE name = ?;`,
+		firedAt.UTC(), nullIfEmpty(file), name)
+	if err != nil {
+		return fmt.Errorf("update watch: %w", err)
+	}
+	return nil
+}
+
+// DeleteFSWatch removes a watch, reporting whether it existed.
+func (s *Store) DeleteFSWatch(ctx context.Context, name string) (bool, error) {
+	if s == nil || s.write == nil {
+		return false, errors.New("database not initialized")
+	}
+	res, err := s.write.ExecContext(ctx, `DELETE FROM fswatches WHERE name = ?;`, name)
+	if err != nil {
+		return false, fmt.Errorf("delete watch: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete watch: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,121 @@
+// ABOUTME: Heartbeat persistence for dead man's switch monitoring.
+// ABOUTME: Records expected check-ins and tracks which have already alerted.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// HeartbeatRecord mirrors the heartbeats table.
+type HeartbeatRecord struct {
+	Name      string        `json:"name"`
+	Every     time.Duration `json:"every"`
+	LastSeen  time.Time     `json:"last_seen"`
+	AlertedAt *time.Time    `json:"alerted_at,omitempty"`
+}
+
+// DueAt reports when the next check-in is expected.
+func (h HeartbeatRecord) DueAt() time.Time {
+	return h.LastSeen.Add(h.Every)
+}
+
+// Overdue reports whether the heartbeat missed its window at the given time.
+func (h HeartbeatRecord) Overdue(now time.Time) bool {
+	return now.After(h.DueAt())
+}
+
+// RecordHeartbeat upserts a check-in and clears any previous alert.
+func (s *Store) RecordHeartbeat(ctx context.Context, name string, every time.Duration, at time.Time) error {
+	if s == nil || s.sql == nil {
+		return errors.New("database not initialized")
+	}
+	if name == "" {
+		return errors.New("heartbeat name is required")
+	}
+	if every <= 0 {
+		return errors.New("heartbeat interval must be positive")
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	_, err := s.write.ExecContext(ctx,
+		`INSERT INTO heartbeats (name, every_seconds, last_seen, alerted_at) VALUES (?, ?, ?, NULL)
+        ON CONFLICT(name) DO UPDATE SET
+            every_seconds=excluded.every_seconds,
+            last_seen=excluded.last_seen,
+            alerted_at=NULL;`,
+		name,
+		int64(every/time.Second),
+		at.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("record heartbeat: %w", err)
+	}
+	return nil
+}
+
+// ListHeartbeats returns every registered heartbeat ordered by name.
+func (s *Store) ListHeartbeats(ctx context.Context) ([]HeartbeatRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	rows, err := s.sql.QueryContext(ctx,
+		`SELECT name, every_seconds, last_seen, alerted_at FROM heartbeats ORDER BY name ASC;`)
+	if err != nil {
+		return nil, fmt.Errorf("query heartbeats: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []HeartbeatRecord
+	for rows.Next() {
+		var rec HeartbeatRecord
+		var everySeconds int64
+		var alerted sql.NullTime
+		if err := rows.Scan(&rec.Name, &everySeconds, &rec.LastSeen, &alerted); err != nil {
+			return nil, fmt.Errorf("scan heartbeat: %w", err)
+		}
+		rec.Every = time.Duration(everySeconds) * time.Second
+		if alerted.Valid {
+			val := alerted.Time
+			rec.AlertedAt = &val
+		}
+		results = append(results, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate heartbeats: %w", err)
+	}
+	return results, nil
+}
+
+// MarkHeartbeatAlerted records that a missed heartbeat has been reported.
+func (s *Store) MarkHeartbeatAlerted(ctx context.Context, name string, at time.Time) error {
+	if s == nil || s.sql == nil {
+		return errors.New("database not initialized")
+	}
+	if _, err := s.write.ExecContext(ctx, `UPDATE heartbeats SET alerted_at = ? WHERE name = ?;`, at.UTC(), name); err != nil {
+		return fmt.Errorf("mark heartbeat alerted: %w", err)
+	}
+	return nil
+}
+
+// DeleteHeartbeat removes a heartbeat, reporting whether it existed.
+func (s *Store) DeleteHeartbeat(ctx context.Context, name string) (bool, error) {
+	if s == nil || s.sql == nil {
+		return false, errors.New("database not initialized")
+	}
+	res, err := s.write.ExecContext(ctx, `DELETE FROM heartbeats WHERE name = ?;`, name)
+	if err != nil {
+		return false, fmt.Errorf("delete heartbeat: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete heartbeat: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,101 @@
+// ABOUTME: Imports received messages from outside exports into the messages table.
+// ABOUTME: Skips messages already stored, by Pushover ID or by identical content.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ImportMessages inserts the messages that aren't stored yet and returns how
+// many were inserted. A message is already stored when one has its Pushover
+// ID, or the same sent time, app, title, and body. Unlike PersistMessages,
+// stored messages are left as they are. Messages are inserted bulkBatch to
+// a transaction.
+func (s *Store) ImportMessages(ctx context.Context, msgs []MessageRecord) (int, error) {
+	if s == nil || s.write == nil {
+		return 0, errors.New("database not initialized")
+	}
+
+	inserted := 0
+	for start := 0; start < len(msgs); start += bulkBatch {
+		n, err := s.importBatch(ctx, msgs[start:min(start+bulkBatch, len(msgs))])
+		inserted += n
+		if err != nil {
+			return inserted, err
+		}
+	}
+	return inserted, nil
+}
+
+func (s *Store) importBatch(ctx context.Context, msgs []MessageRecord) (int, error) {
+	tx, err := s.write.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO messages (
+            pushover_id, umid, title, message, app, aid, icon,
+            received_at, sent_at, priority, url, acked, html, raw_json, device, receipt, thread
+        )
+        SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+        WHERE NOT EXISTS (
+            SELECT 1 FROM messages
+            WHERE pushover_id = ?
+               OR (sent_at = ? AND COALESCE(app, '') = ? AND COALESCE(title, '') = ? AND message = ?)
+        );`)
+	if err != nil {
+		return 0, fmt.Errorf("prepare import: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	inserted := 0
+	for _, msg := range msgs {
+		received := msg.ReceivedAt
+		if received.IsZero() {
+			received = time.Now()
+		}
+		var sent any
+		if msg.SentAt != nil {
+			sent = msg.SentAt.UTC()
+		}
+		res, err := stmt.ExecContext(ctx,
+			msg.PushoverID,
+			msg.UMID,
+			msg.Title,
+			msg.Message,
+			msg.App,
+			msg.AID,
+			msg.Icon,
+			received.UTC(),
+			sent,
+			msg.Priority,
+			msg.URL,
+			boolToInt(msg.Acked),
+			boolToInt(msg.HTML),
+			nullIfEmpty(msg.RawJSON),
+			nullIfEmpty(msg.Device),
+			nullIfEmpty(msg.Receipt),
+			nullIfEmpty(msg.Thread),
+			msg.PushoverID,
+			sent,
+			msg.App,
+			msg.Title,
+			msg.Message,
+		)
+		if err != nil {
+			return inserted, fmt.Errorf("import message %d: %w", msg.PushoverID, err)
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			inserted += int(n)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit import: %w", err)
+	}
+	return inserted, nil
+}
@@ -0,0 +1,310 @@
+// ABOUTME: Maintenance helpers for the embedded SQLite store.
+// ABOUTME: Vacuum, integrity checks, per-table/index size statistics, and retention pruning.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const maintenanceKey = "maintenance"
+
+// MaintenanceState records when push daemon last maintained the database.
+type MaintenanceState struct {
+	OptimizedAt time.Time `json:"optimized_at,omitzero"`
+	VacuumedAt  time.Time `json:"vacuumed_at,omitzero"`
+	// FreedBytes is what the last vacuum gave back to the filesystem.
+	FreedBytes int64     `json:"freed_bytes"`
+	PrunedAt   time.Time `json:"pruned_at,omitzero"`
+	// PrunedMessages and PrunedSent count what the last prune deleted.
+	PrunedMessages int64 `json:"pruned_messages"`
+	PrunedSent     int64 `json:"pruned_sent"`
+}
+
+// LastRun returns when any maintenance last ran, or the zero time if none has.
+func (m MaintenanceState) LastRun() time.Time {
+	last := m.OptimizedAt
+	for _, at := range []time.Time{m.VacuumedAt, m.PrunedAt} {
+		if at.After(last) {
+			last = at
+		}
+	}
+	return last
+}
+
+// ObjectStats describes one table or index.
+type ObjectStats struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Table string `json:"table"`
+	Rows  int64  `json:"rows,omitempty"`
+	Bytes int64  `json:"bytes"`
+}
+
+// DatabaseStats summarizes storage usage.
+type DatabaseStats struct {
+	PageSize  int64         `json:"page_size"`
+	PageCount int64         `json:"page_count"`
+	FreePages int64         `json:"free_pages"`
+	Objects   []ObjectStats `json:"objects"`
+}
+
+// Vacuum rebuilds the database file to reclaim free pages and truncates the WAL.
+func (s *Store) Vacuum(ctx context.Context) error {
+	if s == nil || s.sql == nil {
+		return errors.New("database not initialized")
+	}
+	if _, err := s.write.ExecContext(ctx, `VACUUM;`); err != nil {
+		return fmt.Errorf("vacuum: %w", err)
+	}
+	if _, err := s.write.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE);`); err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	return nil
+}
+
+// IncrementalVacuum returns the database's free pages to the filesystem and
+// reports how many bytes that freed. A database created before incremental
+// vacuuming was enabled is rebuilt once with a full VACUUM to switch it over.
+func (s *Store) IncrementalVacuum(ctx context.Context) (int64, error) {
+	if s == nil || s.write == nil {
+		return 0, errors.New("database not initialized")
+	}
+	var mode, pageSize, freePages int64
+	for pragma, dest := range map[string]*int64{
+		"auto_vacuum":    &mode,
+		"page_size":      &pageSize,
+		"freelist_count": &freePages,
+	} {
+		if err := s.write.QueryRowContext(ctx, fmt.Sprintf(`PRAGMA %s;`, pragma)).Scan(dest); err != nil {
+			return 0, fmt.Errorf("read %s: %w", pragma, err)
+		}
+	}
+	// 2 is INCREMENTAL.
+	if mode != 2 {
+		if _, err := s.write.ExecContext(ctx, `PRAGMA auto_vacuum = INCREMENTAL;`); err != nil {
+			return 0, fmt.Errorf("enable incremental vacuum: %w", err)
+		}
+		if err := s.Vacuum(ctx); err != nil {
+			return 0, err
+		}
+		return freePages * pageSize, nil
+	}
+	if freePages == 0 {
+		return 0, nil
+	}
+	if _, err := s.write.ExecContext(ctx, `PRAGMA incremental_vacuum;`); err != nil {
+		return 0, fmt.Errorf("incremental vacuum: %w", err)
+	}
+	if _, err := s.write.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE);`); err != nil {
+		return 0, fmt.Errorf("checkpoint: %w", err)
+	}
+	var left int64
+	if err := s.write.QueryRowContext(ctx, `PRAGMA freelist_count;`).Scan(&left); err != nil {
+		return 0, fmt.Errorf("read freelist_count: %w", err)
+	}
+	return (freePages - left) * pageSize, nil
+}
+
+// Optimize runs PRAGMA optimize, refreshing the query planner's statistics
+// where they've gone stale.
+func (s *Store) Optimize(ctx context.Context) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	if _, err := s.write.ExecContext(ctx, `PRAGMA optimize;`); err != nil {
+		return fmt.Errorf("optimize: %w", err)
+	}
+	return nil
+}
+
+// PruneHistory deletes received messages and logged sends from before cutoff
+// and returns how many of each went.
+func (s *Store) PruneHistory(ctx context.Context, cutoff time.Time) (int64, int64, error) {
+	if s == nil || s.sql == nil {
+		return 0, 0, errors.New("database not initialized")
+	}
+	ids, err := s.MessageIDs(ctx, MessageFilter{Until: &cutoff})
+	if err != nil {
+		return 0, 0, err
+	}
+	messages, err := s.DeleteMessages(ctx, ids)
+	if err != nil {
+		return messages, 0, err
+	}
+
+	rows, err := s.sql.QueryContext(ctx, `SELECT id FROM sent WHERE sent_at < ?;`, cutoff.UTC())
+	if err != nil {
+		return messages, 0, fmt.Errorf("query old sends: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var sentIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return messages, 0, fmt.Errorf("scan sent id: %w", err)
+		}
+		sentIDs = append(sentIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return messages, 0, fmt.Errorf("iterate old sends: %w", err)
+	}
+	_ = rows.Close()
+
+	sent, err := s.inBatches(ctx, sentIDs, func(tx *sql.Tx, in string, args []any) (sql.Result, error) {
+		return tx.ExecContext(ctx, `DELETE FROM sent WHERE id IN (`+in+`);`, args...)
+	})
+	return messages, sent, err
+}
+
+// LastActivity returns when the latest message was received or notification
+// sent, or the zero time if history is empty.
+func (s *Store) LastActivity(ctx context.Context) (time.Time, error) {
+	if s == nil || s.sql == nil {
+		return time.Time{}, errors.New("database not initialized")
+	}
+	var last sql.NullString
+	err := s.sql.QueryRowContext(ctx, `SELECT MAX(at) FROM (
+            SELECT MAX(`+receivedAtExpr+`) AS at FROM messages
+            UNION ALL SELECT MAX(substr(sent_at, 1, 19)) FROM sent
+        );`).Scan(&last)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query last activity: %w", err)
+	}
+	if !last.Valid {
+		return time.Time{}, nil
+	}
+	at, err := time.Parse(time.DateTime, strings.Replace(last.String, "T", " ", 1))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse last activity %q: %w", last.String, err)
+	}
+	return at, nil
+}
+
+// MaintenanceStatus returns what push daemon's last maintenance did, or the
+// zero state if it never ran.
+func (s *Store) MaintenanceStatus(ctx context.Context) (MaintenanceState, error) {
+	if s == nil || s.sql == nil {
+		return MaintenanceState{}, errors.New("database not initialized")
+	}
+	var value string
+	err := s.sql.QueryRowContext(ctx, `SELECT value FROM state WHERE key = ?;`, maintenanceKey).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return MaintenanceState{}, nil
+	}
+	if err != nil {
+		return MaintenanceState{}, fmt.Errorf("query maintenance state: %w", err)
+	}
+	var state MaintenanceState
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		return MaintenanceState{}, fmt.Errorf("parse maintenance state: %w", err)
+	}
+	return state, nil
+}
+
+// SetMaintenanceStatus records what maintenance did.
+func (s *Store) SetMaintenanceStatus(ctx context.Context, state MaintenanceState) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	value, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encode maintenance state: %w", err)
+	}
+	_, err = s.write.ExecContext(ctx,
+		`INSERT INTO state (key, value, updated_at) VALUES (?, ?, ?)
+        ON CONFLICT(key) DO UPDATE SET value=excluded.value, updated_at=excluded.updated_at;`,
+		maintenanceKey, string(value), time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("set maintenance state: %w", err)
+	}
+	return nil
+}
+
+// IntegrityCheck runs PRAGMA integrity_check and returns the problems found,
+// or nil when the database is healthy.
+func (s *Store) IntegrityCheck(ctx context.Context) ([]string, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	rows, err := s.sql.QueryContext(ctx, `PRAGMA integrity_check;`)
+	if err != nil {
+		return nil, fmt.Errorf("integrity check: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("integrity check: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("integrity check: %w", err)
+	}
+	return problems, nil
+}
+
+// Stats reports page usage plus row counts and on-disk size for every table and index.
+func (s *Store) Stats(ctx context.Context) (DatabaseStats, error) {
+	if s == nil || s.sql == nil {
+		return DatabaseStats{}, errors.New("database not initialized")
+	}
+
+	var stats DatabaseStats
+	for pragma, dest := range map[string]*int64{
+		"page_size":      &stats.PageSize,
+		"page_count":     &stats.PageCount,
+		"freelist_count": &stats.FreePages,
+	} {
+		if err := s.sql.QueryRowContext(ctx, fmt.Sprintf(`PRAGMA %s;`, pragma)).Scan(dest); err != nil {
+			return DatabaseStats{}, fmt.Errorf("read %s: %w", pragma, err)
+		}
+	}
+
+	rows, err := s.sql.QueryContext(ctx,
+		`SELECT m.name, m.type, m.tbl_name, COALESCE(SUM(d.pgsize), 0)
+        FROM sqlite_schema m LEFT JOIN dbstat d ON d.name = m.name
+        WHERE m.type = 'index' OR (m.type = 'table' AND m.name NOT LIKE 'sqlite_%')
+        GROUP BY m.name ORDER BY m.tbl_name, m.type DESC, m.name;`)
+	if err != nil {
+		return DatabaseStats{}, fmt.Errorf("query schema: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var obj ObjectStats
+		if err := rows.Scan(&obj.Name, &obj.Type, &obj.Table, &obj.Bytes); err != nil {
+			return DatabaseStats{}, fmt.Errorf("scan schema: %w", err)
+		}
+		stats.Objects = append(stats.Objects, obj)
+	}
+	if err := rows.Err(); err != nil {
+		return DatabaseStats{}, fmt.Errorf("iterate schema: %w", err)
+	}
+	_ = rows.Close()
+
+	for i := range stats.Objects {
+		obj := &stats.Objects[i]
+		if obj.Type != "table" {
+			continue
+		}
+		// Names come from sqlite_schema, not user input.
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %q;`, obj.Name)
+		if err := s.sql.QueryRowContext(ctx, query).Scan(&obj.Rows); err != nil {
+			return DatabaseStats{}, fmt.Errorf("count %s: %w", obj.Name, err)
+		}
+	}
+	return stats, nil
+}
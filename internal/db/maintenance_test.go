@@ -0,0 +1,93 @@
+// ABOUTME: Tests for background maintenance of the store.
+// ABOUTME: Covers retention pruning, incremental vacuuming, and the recorded maintenance state.
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// maintenanceStore returns a file-backed store holding 200 large messages,
+// one a day back from now, and sends from 1 and 100 days ago.
+func maintenanceStore(t *testing.T, now time.Time) *Store {
+	t.Helper()
+	ctx := context.Background()
+	store, err := Open(filepath.Join(t.TempDir(), "push.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	if last, err := store.LastActivity(ctx); err != nil || !last.IsZero() {
+		t.Errorf("LastActivity() on an empty store = %v, %v; want zero", last, err)
+	}
+	body := strings.Repeat("x", 2000)
+	var msgs []MessageRecord
+	for i := range 200 {
+		msgs = append(msgs, MessageRecord{PushoverID: int64(i + 1), Message: body, ReceivedAt: now.Add(-time.Duration(i) * 24 * time.Hour)})
+	}
+	if _, err := store.PersistMessages(ctx, msgs); err != nil {
+		t.Fatal(err)
+	}
+	for _, ago := range []int{1, 100} {
+		if err := store.LogSent(ctx, SentRecord{Message: "hi", SentAt: now.AddDate(0, 0, -ago)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+func TestMaintenance(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	store := maintenanceStore(t, now)
+
+	if last, err := store.LastActivity(ctx); err != nil || !last.Equal(now) {
+		t.Errorf("LastActivity() = %v, %v; want %v", last, err, now)
+	}
+
+	messages, sent, err := store.PruneHistory(ctx, now.AddDate(0, 0, -30))
+	if err != nil || messages != 169 || sent != 1 {
+		t.Fatalf("PruneHistory() = %d, %d, %v; want 169 messages and 1 sent", messages, sent, err)
+	}
+	if left, err := store.MessageIDs(ctx, MessageFilter{}); err != nil || len(left) != 31 {
+		t.Errorf("%d messages left, %v; want 31", len(left), err)
+	}
+
+	freed, err := store.IncrementalVacuum(ctx)
+	if err != nil || freed <= 0 {
+		t.Errorf("IncrementalVacuum() = %d, %v; want bytes freed", freed, err)
+	}
+	stats, err := store.Stats(ctx)
+	if err != nil || stats.FreePages != 0 {
+		t.Errorf("%d free pages after vacuuming, %v; want none", stats.FreePages, err)
+	}
+	if err := store.Optimize(ctx); err != nil {
+		t.Errorf("Optimize() error: %v", err)
+	}
+}
+
+func TestMaintenanceStatus(t *testing.T) {
+	ctx := context.Background()
+	store, err := Open(Memory)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if state, err := store.MaintenanceStatus(ctx); err != nil || !state.LastRun().IsZero() {
+		t.Errorf("MaintenanceStatus() before any = %+v, %v; want zero", state, err)
+	}
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	want := MaintenanceState{OptimizedAt: now, VacuumedAt: now.Add(-time.Minute), FreedBytes: 4096, PrunedAt: now.Add(-2 * time.Minute), PrunedMessages: 169, PrunedSent: 1}
+	if err := store.SetMaintenanceStatus(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.MaintenanceStatus(ctx)
+	if err != nil || got != want || !got.LastRun().Equal(now) {
+		t.Errorf("MaintenanceStatus() = %+v, %v; want %+v", got, err, want)
+	}
+}
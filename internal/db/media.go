@@ -0,0 +1,93 @@
+// ABOUTME: Metadata for the content-addressed media cache.
+// ABOUTME: Maps source URLs to cached files and links icons to messages.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MediaRecord mirrors the media table.
+type MediaRecord struct {
+	Hash        string    `json:"hash"`
+	URL         string    `json:"url"`
+	Path        string    `json:"path"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+const mediaColumns = `hash, url, path, content_type, size, fetched_at`
+
+// SaveMedia records a cached file, replacing any previous entry for its URL.
+func (s *Store) SaveMedia(ctx context.Context, rec MediaRecord) error {
+	if s == nil || s.sql == nil {
+		return errors.New("database not initialized")
+	}
+	if rec.FetchedAt.IsZero() {
+		rec.FetchedAt = time.Now()
+	}
+
+	_, err := s.write.ExecContext(ctx,
+		`INSERT INTO media (hash, url, path, content_type, size, fetched_at) VALUES (?, ?, ?, ?, ?, ?)
+        ON CONFLICT(url) DO UPDATE SET
+            hash=excluded.hash,
+            path=excluded.path,
+            content_type=excluded.content_type,
+            size=excluded.size,
+            fetched_at=excluded.fetched_at;`,
+		rec.Hash,
+		rec.URL,
+		rec.Path,
+		rec.ContentType,
+		rec.Size,
+		rec.FetchedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("save media: %w", err)
+	}
+	return nil
+}
+
+// MediaByURL looks up a cached file by the URL it was downloaded from.
+func (s *Store) MediaByURL(ctx context.Context, url string) (MediaRecord, bool, error) {
+	return s.findMedia(ctx, `url = ?`, url)
+}
+
+// MediaByHash looks up a cached file by its SHA-256 content hash.
+func (s *Store) MediaByHash(ctx context.Context, hash string) (MediaRecord, bool, error) {
+	return s.findMedia(ctx, `hash = ?`, hash)
+}
+
+func (s *Store) findMedia(ctx context.Context, where string, arg any) (MediaRecord, bool, error) {
+	if s == nil || s.sql == nil {
+		return MediaRecord{}, false, errors.New("database not initialized")
+	}
+
+	var rec MediaRecord
+	err := s.sql.QueryRowContext(ctx,
+		fmt.Sprintf(`SELECT %s FROM media WHERE %s ORDER BY fetched_at DESC LIMIT 1;`, mediaColumns, where), arg).
+		Scan(&rec.Hash, &rec.URL, &rec.Path, &rec.ContentType, &rec.Size, &rec.FetchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return MediaRecord{}, false, nil
+	}
+	if err != nil {
+		return MediaRecord{}, false, fmt.Errorf("query media: %w", err)
+	}
+	return rec, true, nil
+}
+
+// SetMessageIcon links a persisted message to its cached icon.
+func (s *Store) SetMessageIcon(ctx context.Context, pushoverID int64, hash string) error {
+	if s == nil || s.sql == nil {
+		return errors.New("database not initialized")
+	}
+	if _, err := s.write.ExecContext(ctx,
+		`UPDATE messages SET icon_hash = ? WHERE pushover_id = ?;`, hash, pushoverID); err != nil {
+		return fmt.Errorf("set message icon: %w", err)
+	}
+	return nil
+}
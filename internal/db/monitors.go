@@ -0,0 +1,154 @@
+// ABOUTME: Persistence for URL monitors checked by the daemon.
+// ABOUTME: Stores each monitor's settings, current up/down state, and last result.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Monitor states. A monitor is unknown until its first confirmed result.
+const (
+	MonitorUnknown = "unknown"
+	MonitorUp      = "up"
+	MonitorDown    = "down"
+)
+
+// MonitorRecord mirrors the monitors table.
+type MonitorRecord struct {
+	Name    string        `json:"name"`
+	URL     string        `json:"url"`
+	Every   time.Duration `json:"every"`
+	Timeout time.Duration `json:"timeout"`
+	// Expect lists the accepted status codes, e.g. "200" or "2xx,301".
+	Expect string `json:"expect"`
+	// Confirm is how many checks in a row must disagree with State before
+	// it changes.
+	Confirm int    `json:"confirm"`
+	State   string `json:"state"`
+	// Streak counts the checks in a row that disagreed with State.
+	Streak      int       `json:"streak"`
+	LastChecked time.Time `json:"last_checked,omitzero"`
+	// LastResult describes the last check, e.g. "200 in 84ms".
+	LastResult string    `json:"last_result,omitempty"`
+	LastChange time.Time `json:"last_change,omitzero"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Due reports whether the monitor should be checked at now. slack lets a
+// check run a little early so ticks that land just short of the interval
+// don't skip a whole one.
+func (m MonitorRecord) Due(now time.Time, slack time.Duration) bool {
+	return m.LastChecked.IsZero() || !now.Add(slack).Before(m.LastChecked.Add(m.Every))
+}
+
+// SaveMonitor adds a monitor, or updates the settings of the one with the
+// same name and starts its state over.
+func (s *Store) SaveMonitor(ctx context.Context, m MonitorRecord) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	if m.Name == "" || m.URL == "" {
+		return errors.New("monitor name and URL are required")
+	}
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now()
+	}
+	_, err := s.write.ExecContext(ctx,
+		`INSERT INTO monitors (name, url, every_seconds, timeout_seconds, expect, confirm, state, streak, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, 0, ?)
+        ON CONFLICT(name) DO UPDATE SET
+            url=excluded.url,
+            every_seconds=excluded.every_seconds,
+            timeout_seconds=excluded.timeout_seconds,
+            expect=excluded.expect,
+            confirm=excluded.confirm,
+            state=excluded.state,
+            streak=0,
+            last_checked=NULL,
+            last_result=NULL,
+            last_change=NULL;`,
+		m.Name,
+		m.URL,
+		int64(m.Every/time.Second),
+		int64(m.Timeout/time.Second),
+		m.Expect,
+		m.Confirm,
+		MonitorUnknown,
+		m.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("save monitor: %w", err)
+	}
+	return nil
+}
+
+// ListMonitors returns every monitor ordered by name.
+func (s *Store) ListMonitors(ctx context.Context) ([]MonitorRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	rows, err := s.sql.QueryContext(ctx,
+		`SELECT name, url, every_seconds, timeout_seconds, expect, confirm, state, streak, last_checked, last_result, last_change, created_at
+        FROM monitors ORDER BY name ASC;`)
+	if err != nil {
+		return nil, fmt.Errorf("query monitors: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []MonitorRecord
+	for rows.Next() {
+		var rec MonitorRecord
+		var everySeconds, timeoutSeconds int64
+		var lastChecked, lastChange sql.NullTime
+		var lastResult sql.NullString
+		if err := rows.Scan(&rec.Name, &rec.URL, &everySeconds, &timeoutSeconds, &rec.Expect, &rec.Confirm, &rec.State, &rec.Streak,
+			&lastChecked, &lastResult, &lastChange, &rec.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan monitor: %w", err)
+		}
+		rec.Every = time.Duration(everySeconds) * time.Second
+		rec.Timeout = time.Duration(timeoutSeconds) * time.Second
+		rec.LastChecked = lastChecked.Time
+		rec.LastResult = lastResult.String
+		rec.LastChange = lastChange.Time
+		results = append(results, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate monitors: %w", err)
+	}
+	return results, nil
+}
+
+// UpdateMonitorState records the outcome of a check.
+func (s *Store) UpdateMonitorState(ctx context.Context, m MonitorRecord) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := s.write.ExecContext(ctx,
+		`UPDATE monitors SET state = ?, streak = ?, last_checked = ?, last_result = ?, last_change = ? WHERE name = ?;`,
+		m.State, m.Streak, nullTime(m.LastChecked), nullIfEmpty(m.LastResult), nullTime(m.LastChange), m.Name)
+	if err != nil {
+		return fmt.Errorf("update monitor: %w", err)
+	}
+	return nil
+}
+
+// DeleteMonitor removes a monitor, reporting whether it existed.
+func (s *Store) DeleteMonitor(ctx context.Context, name string) (bool, error) {
+	if s == nil || s.write == nil {
+		return false, errors.New("database not initialized")
+	}
+	res, err := s.write.ExecContext(ctx, `DELETE FROM monitors WHERE name = ?;`, name)
+	if err != nil {
+		return false, fmt.Errorf("delete monitor: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete monitor: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,104 @@
+// ABOUTME: Persistence for push mute, which quiets received messages from chatty apps.
+// ABOUTME: Muted apps' messages are still saved; watchers read the list on every poll.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MuteRecord mirrors the mutes table.
+type MuteRecord struct {
+	App string `json:"app"`
+	// Until is when the mute ends; zero means until it's removed.
+	Until     time.Time `json:"until,omitzero"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MuteApp mutes app until the given time, or indefinitely when until is zero,
+// replacing any mute it already has. Expired mutes are cleared on the way.
+func (s *Store) MuteApp(ctx context.Context, app string, until, now time.Time) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	if app == "" {
+		return errors.New("app name is required")
+	}
+	if _, err := s.write.ExecContext(ctx, `DELETE FROM mutes WHERE until <= ?;`, now.UTC()); err != nil {
+		return fmt.Errorf("clear expired mutes: %w", err)
+	}
+	_, err := s.write.ExecContext(ctx,
+		`INSERT INTO mutes (app, until, created_at) VALUES (?, ?, ?)
+        ON CONFLICT(app) DO UPDATE SET until=excluded.until, created_at=excluded.created_at;`,
+		app, nullTime(until), now.UTC())
+	if err != nil {
+		return fmt.Errorf("mute app: %w", err)
+	}
+	return nil
+}
+
+// ListMutes returns the mutes in effect at now ordered by app.
+func (s *Store) ListMutes(ctx context.Context, now time.Time) ([]MuteRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	rows, err := s.sql.QueryContext(ctx,
+		`SELECT app, until, created_at FROM mutes
+        WHERE until IS NULL OR until > ? ORDER BY app COLLATE NOCASE ASC;`, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("query mutes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []MuteRecord
+	for rows.Next() {
+		var rec MuteRecord
+		var until sql.NullTime
+		if err := rows.Scan(&rec.App, &until, &rec.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan mute: %w", err)
+		}
+		rec.Until = until.Time
+		results = append(results, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate mutes: %w", err)
+	}
+	return results, nil
+}
+
+// MutedApps returns the apps muted at now, keyed by lower-cased name.
+func (s *Store) MutedApps(ctx context.Context, now time.Time) (map[string]bool, error) {
+	mutes, err := s.ListMutes(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	muted := make(map[string]bool, len(mutes))
+	for _, m := range mutes {
+		muted[strings.ToLower(m.App)] = true
+	}
+	return muted, nil
+}
+
+// Unmute removes app's mute. It reports whether one was in effect at now.
+func (s *Store) Unmute(ctx context.Context, app string, now time.Time) (bool, error) {
+	if s == nil || s.write == nil {
+		return false, errors.New("database not initialized")
+	}
+	var until sql.NullTime
+	err := s.sql.QueryRowContext(ctx, `SELECT until FROM mutes WHERE app = ?;`, app).Scan(&until)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("query mute: %w", err)
+	}
+	if _, err := s.write.ExecContext(ctx, `DELETE FROM mutes WHERE app = ?;`, app); err != nil {
+		return false, fmt.Errorf("unmute app: %w", err)
+	}
+	return !until.Valid || until.Time.After(now), nil
+}
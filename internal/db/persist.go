@@ -0,0 +1,326 @@
+// ABOUTME: Persists received messages in chunks of multi-row upserts.
+// ABOUTME: Keeps large backfills after a long time offline fast and lets other writers in between chunks.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// persistBatch is how many messages PersistMessages stores per transaction.
+const persistBatch = 500
+
+// insertRows is how many messages go in one INSERT. Binding a statement's
+// parameters costs the driver time quadratic in their number, so past a
+// couple of dozen rows bigger statements get slower, not faster.
+const insertRows = 20
+
+const messageInsertColumns = 18
+
+const messageUpsert = `
+        ON CONFLICT(pushover_id) DO UPDATE SET
+            umid=excluded.umid,
+            title=excluded.title,
+            message=excluded.message,
+            app=excluded.app,
+            aid=excluded.aid,
+            icon=excluded.icon,
+            received_at=excluded.received_at,
+            sent_at=excluded.sent_at,
+            priority=excluded.priority,
+            url=excluded.url,
+            acked=excluded.acked,
+            html=excluded.html,
+            raw_json=COALESCE(excluded.raw_json, messages.raw_json),
+            device=COALESCE(excluded.device, messages.device),
+            receipt=COALESCE(excluded.receipt, messages.receipt),
+            thread=COALESCE(messages.thread, excluded.thread),
+            sent_id=COALESCE(messages.sent_id, excluded.sent_id);`
+
+// PersistMessages inserts the provided message records, updating any already
+// stored. A message without a thread joins the one matchThread finds for it,
+// and a copy of a notification sent from here is linked to it by linkSent.
+// Messages are stored persistBatch at a time, each batch in its own
+// transaction, so on error the count covers the batches already committed.
+func (s *Store) PersistMessages(ctx context.Context, msgs []MessageRecord) (int, error) {
+	if s == nil || s.sql == nil {
+		return 0, errors.New("database not initialized")
+	}
+
+	inserted := 0
+	for start := 0; start < len(msgs); start += persistBatch {
+		batch := msgs[start:min(start+persistBatch, len(msgs))]
+		if err := s.persistBatch(ctx, batch); err != nil {
+			return inserted, err
+		}
+		inserted += len(batch)
+	}
+	return inserted, nil
+}
+
+func (s *Store) persistBatch(ctx context.Context, msgs []MessageRecord) error {
+	tx, err := s.write.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	if err := persistRows(ctx, tx, msgs); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit messages: %w", err)
+	}
+	return nil
+}
+
+// persistRows resolves each message's thread and send and writes them in as
+// few INSERTs as it can. Rows wait in pending until a later message's lookup
+// could match one of them, which makes them visible to it first.
+func persistRows(ctx context.Context, tx *sql.Tx, msgs []MessageRecord) error {
+	now := time.Now()
+	received := make([]time.Time, len(msgs))
+	for i, msg := range msgs {
+		received[i] = msg.ReceivedAt
+		if received[i].IsZero() {
+			received[i] = now
+		}
+	}
+	cand, err := loadCandidates(ctx, tx, msgs, received)
+	if err != nil {
+		return err
+	}
+
+	rows := &pendingRows{
+		tx:       tx,
+		args:     make([]any, 0, len(msgs)*messageInsertColumns),
+		threaded: make(map[string]bool),
+		linked:   make(map[string]bool),
+	}
+	for i, msg := range msgs {
+		thread, err := rows.thread(ctx, cand, msg, received[i])
+		if err != nil {
+			return err
+		}
+		sentID, err := rows.sentID(ctx, cand, msg, received[i])
+		if err != nil {
+			return err
+		}
+		if err := rows.add(ctx, cand, msg, received[i], thread, sentID); err != nil {
+			return err
+		}
+	}
+	return rows.flush(ctx)
+}
+
+// pendingRows are messages waiting to be inserted together.
+type pendingRows struct {
+	tx    *sql.Tx
+	args  []any
+	count int
+	// Threads and devices with links among the pending rows.
+	threaded map[string]bool
+	linked   map[string]bool
+}
+
+func (p *pendingRows) flush(ctx context.Context) error {
+	if p.count == 0 {
+		return nil
+	}
+	values := strings.TrimSuffix(strings.Repeat("("+placeholders(messageInsertColumns)+"), ", p.count), ", ")
+	if _, err := p.tx.ExecContext(ctx, `INSERT INTO messages (
+            pushover_id, umid, title, message, app, aid, icon,
+            received_at, sent_at, priority, url, acked, html, raw_json, device, receipt, thread, sent_id
+        ) VALUES `+values+messageUpsert, p.args...); err != nil {
+		return fmt.Errorf("insert messages: %w", err)
+	}
+	p.args, p.count = p.args[:0], 0
+	clear(p.threaded)
+	clear(p.linked)
+	return nil
+}
+
+// thread returns the thread msg belongs to, flushing first when a pending
+// row could be the one it continues.
+func (p *pendingRows) thread(ctx context.Context, cand persistCandidates, msg MessageRecord, received time.Time) (string, error) {
+	if msg.Thread != "" || !cand.threaded(msg.App, msg.Title) {
+		return msg.Thread, nil
+	}
+	if p.threaded[threadKey(msg.App, msg.Title)] {
+		if err := p.flush(ctx); err != nil {
+			return "", err
+		}
+	}
+	return matchThread(ctx, p.tx, msg.App, msg.Title, received)
+}
+
+// sentID returns the logged send msg came from, flushing first when a
+// pending row from the same device might have claimed it.
+func (p *pendingRows) sentID(ctx context.Context, cand persistCandidates, msg MessageRecord, received time.Time) (int64, error) {
+	if msg.SentID != 0 || (msg.Receipt == "" && !cand.bodies[msg.Message]) {
+		return msg.SentID, nil
+	}
+	if p.linked[msg.Device] {
+		if err := p.flush(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return linkSent(ctx, p.tx, msg, received)
+}
+
+// add queues msg, inserting the pending rows once there are insertRows.
+func (p *pendingRows) add(ctx context.Context, cand persistCandidates, msg MessageRecord, received time.Time, thread string, sentID int64) error {
+	var sent any
+	if msg.SentAt != nil {
+		sent = msg.SentAt.UTC()
+	}
+	p.args = append(p.args,
+		msg.PushoverID,
+		msg.UMID,
+		msg.Title,
+		msg.Message,
+		msg.App,
+		msg.AID,
+		msg.Icon,
+		received.UTC(),
+		sent,
+		msg.Priority,
+		msg.URL,
+		boolToInt(msg.Acked),
+		boolToInt(msg.HTML),
+		nullIfEmpty(msg.RawJSON),
+		nullIfEmpty(msg.Device),
+		nullIfEmpty(msg.Receipt),
+		nullIfEmpty(thread),
+		nullIfZero(sentID),
+	)
+	p.count++
+	if thread != "" {
+		key := threadKey(msg.App, msg.Title)
+		p.threaded[key] = true
+		cand.messages[key] = true
+	}
+	if sentID != 0 {
+		p.linked[msg.Device] = true
+	}
+	if p.count == insertRows {
+		return p.flush(ctx)
+	}
+	return nil
+}
+
+// persistCandidates narrows which messages in a batch need the per-message
+// lookups: those titled like a threaded notification near the batch's time
+// span, and those with the body of a send in it. Titles are compared
+// lower-cased, which matches at least everything COLLATE NOCASE does.
+type persistCandidates struct {
+	sentTitles map[string]bool
+	messages   map[string]bool
+	bodies     map[string]bool
+}
+
+func (c persistCandidates) threaded(app, title string) bool {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return false
+	}
+	return c.sentTitles[strings.ToLower(title)] || c.messages[threadKey(app, title)]
+}
+
+func threadKey(app, title string) string {
+	return app + "\x00" + strings.ToLower(strings.TrimSpace(title))
+}
+
+func loadCandidates(ctx context.Context, tx *sql.Tx, msgs []MessageRecord, received []time.Time) (persistCandidates, error) {
+	cand := persistCandidates{
+		sentTitles: make(map[string]bool),
+		messages:   make(map[string]bool),
+		bodies:     make(map[string]bool),
+	}
+	first, last := received[0], received[0]
+	firstDated, lastDated := received[0], received[0]
+	for i, msg := range msgs {
+		first, last = minTime(first, received[i]), maxTime(last, received[i])
+		dated := received[i]
+		if msg.SentAt != nil {
+			dated = *msg.SentAt
+		}
+		firstDated, lastDated = minTime(firstDated, dated), maxTime(lastDated, dated)
+	}
+	from, to := first.Add(-threadWindow).UTC(), last.Add(threadSkew).UTC()
+
+	err := collect(ctx, tx, func(rows *sql.Rows) error {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return err
+		}
+		cand.sentTitles[strings.ToLower(title)] = true
+		return nil
+	}, `SELECT DISTINCT title FROM sent WHERE thread IS NOT NULL AND title IS NOT NULL AND sent_at BETWEEN ? AND ?;`, from, to)
+	if err != nil {
+		return cand, fmt.Errorf("load threaded sends: %w", err)
+	}
+	err = collect(ctx, tx, func(rows *sql.Rows) error {
+		var app, title string
+		if err := rows.Scan(&app, &title); err != nil {
+			return err
+		}
+		cand.messages[threadKey(app, title)] = true
+		return nil
+	}, `SELECT DISTINCT COALESCE(app, ''), title FROM messages WHERE thread IS NOT NULL AND title IS NOT NULL AND received_at BETWEEN ? AND ?;`, from, to)
+	if err != nil {
+		return cand, fmt.Errorf("load threaded messages: %w", err)
+	}
+	err = collect(ctx, tx, func(rows *sql.Rows) error {
+		var body string
+		if err := rows.Scan(&body); err != nil {
+			return err
+		}
+		cand.bodies[body] = true
+		return nil
+	}, `SELECT DISTINCT message FROM sent
+        WHERE sent_at BETWEEN ? AND ?
+            AND error IS NULL AND COALESCE(suppressed, 0) = 0
+            AND COALESCE(via, '') IN ('', 'pushover') AND recipient IS NULL;`,
+		firstDated.Add(-threadSkew).UTC(), lastDated.Add(threadSkew).UTC())
+	if err != nil {
+		return cand, fmt.Errorf("load sent bodies: %w", err)
+	}
+	return cand, nil
+}
+
+// collect runs query in tx and calls scan for each row.
+func collect(ctx context.Context, tx *sql.Tx, scan func(*sql.Rows) error, query string, args ...any) error {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
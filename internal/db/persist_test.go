@@ -0,0 +1,118 @@
+// ABOUTME: Tests and benchmarks for persisting fetched messages in chunks.
+// ABOUTME: Checks large backfills land intact, upsert in order, and thread within a single call.
+package db
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// backlog returns n messages like a long offline period leaves: a few apps
+// repeating the same titles every minute.
+func backlog(n int, start time.Time) []MessageRecord {
+	apps := []string{"cron", "ci", "monitor", "backup"}
+	msgs := make([]MessageRecord, n)
+	for i := range msgs {
+		at := start.Add(time.Duration(i) * time.Minute)
+		msgs[i] = MessageRecord{
+			PushoverID: int64(i + 1),
+			UMID:       fmt.Sprintf("u%d", i+1),
+			Title:      fmt.Sprintf("Job %d", i%25),
+			Message:    fmt.Sprintf("run %d finished", i),
+			App:        apps[i%len(apps)],
+			Device:     "phone",
+			ReceivedAt: at,
+			SentAt:     &at,
+			RawJSON:    fmt.Sprintf(`{"id":%d}`, i+1),
+		}
+	}
+	return msgs
+}
+
+func TestPersistMessagesChunks(t *testing.T) {
+	ctx := context.Background()
+	store, err := Open(Memory)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	msgs := backlog(1200, start)
+	// A repeat of an earlier ID in the same call updates it in place.
+	repeat := msgs[3]
+	repeat.Message = "run 3 finished (edited)"
+	msgs = append(msgs, repeat)
+
+	n, err := store.PersistMessages(ctx, msgs)
+	if err != nil {
+		t.Fatalf("PersistMessages: %v", err)
+	}
+	if n != len(msgs) {
+		t.Fatalf("persisted %d, want %d", n, len(msgs))
+	}
+	stored, err := store.FindMessages(ctx, MessageFilter{Limit: len(msgs)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != len(msgs)-1 {
+		t.Fatalf("stored %d messages, want %d", len(stored), len(msgs)-1)
+	}
+	got, found, err := store.GetMessage(ctx, 4)
+	if err != nil || !found {
+		t.Fatalf("GetMessage: %v found=%v", err, found)
+	}
+	if got.Message != repeat.Message || got.RawJSON != `{"id":4}` || got.Device != "phone" {
+		t.Fatalf("message 4 = %+v", got)
+	}
+}
+
+func TestPersistMessagesThreadsWithinCall(t *testing.T) {
+	ctx := context.Background()
+	store, err := Open(Memory)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	if _, err := store.PersistMessages(ctx, []MessageRecord{
+		{PushoverID: 1, Title: "Deploy", Message: "started", App: "ci", Thread: "deploy-42", ReceivedAt: now},
+		{PushoverID: 2, Title: "deploy", Message: "finished", App: "ci", ReceivedAt: now.Add(time.Minute)},
+		{PushoverID: 3, Title: "Deploy", Message: "other app", App: "cron", ReceivedAt: now.Add(time.Minute)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[int64]string{1: "deploy-42", 2: "deploy-42", 3: ""} {
+		got, _, err := store.GetMessage(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Thread != want {
+			t.Errorf("message %d thread = %q, want %q", id, got.Thread, want)
+		}
+	}
+}
+
+func BenchmarkPersistMessages(b *testing.B) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	msgs := backlog(10000, start)
+	for b.Loop() {
+		b.StopTimer()
+		store, err := Open(filepath.Join(b.TempDir(), "push.db"))
+		if err != nil {
+			b.Fatalf("open store: %v", err)
+		}
+		b.StartTimer()
+		if _, err := store.PersistMessages(ctx, msgs); err != nil {
+			b.Fatal(err)
+		}
+		b.StopTimer()
+		_ = store.Close()
+		b.StartTimer()
+	}
+}
@@ -0,0 +1,71 @@
+// ABOUTME: Cross-process send budget tracking in SQLite.
+// ABOUTME: Reserves send slots atomically so concurrent invocations share one limit.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ReserveSendSlot records a send attempt if fewer than limit sends happened
+// within window. When the budget is exhausted it returns false along with how
+// long until the oldest reservation expires. The check and insert run in one
+// immediate transaction so separate processes cannot overshoot the budget.
+func (s *Store) ReserveSendSlot(ctx context.Context, limit int, window time.Duration, now time.Time) (bool, time.Duration, error) {
+	if s == nil || s.sql == nil {
+		return false, 0, errors.New("database not initialized")
+	}
+	if limit <= 0 {
+		return true, 0, nil
+	}
+
+	conn, err := s.write.Conn(ctx)
+	if err != nil {
+		return false, 0, fmt.Errorf("reserve send slot: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE;`); err != nil {
+		return false, 0, fmt.Errorf("reserve send slot: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_, _ = conn.ExecContext(context.Background(), `ROLLBACK;`)
+		}
+	}()
+
+	cutoff := now.Add(-window).UTC()
+	if _, err := conn.ExecContext(ctx, `DELETE FROM send_slots WHERE reserved_at <= ?;`, cutoff); err != nil {
+		return false, 0, fmt.Errorf("prune send slots: %w", err)
+	}
+
+	var count int
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM send_slots;`).Scan(&count); err != nil {
+		return false, 0, fmt.Errorf("count send slots: %w", err)
+	}
+
+	if count >= limit {
+		var oldest time.Time
+		if err := conn.QueryRowContext(ctx,
+			`SELECT reserved_at FROM send_slots ORDER BY reserved_at ASC LIMIT 1 OFFSET ?;`, count-limit).Scan(&oldest); err != nil {
+			return false, 0, fmt.Errorf("query send slots: %w", err)
+		}
+		retry := oldest.Add(window).Sub(now)
+		if retry < time.Second {
+			retry = time.Second
+		}
+		return false, retry, nil
+	}
+
+	if _, err := conn.ExecContext(ctx, `INSERT INTO send_slots (reserved_at) VALUES (?);`, now.UTC()); err != nil {
+		return false, 0, fmt.Errorf("insert send slot: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, `COMMIT;`); err != nil {
+		return false, 0, fmt.Errorf("commit send slot: %w", err)
+	}
+	committed = true
+	return true, 0, nil
+}
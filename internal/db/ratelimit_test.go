@@ -0,0 +1,115 @@
+// ABOUTME: Tests for the cross-process send budget.
+// ABOUTME: Covers the limit, window expiry, and two stores sharing one file.
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReserveSendSlotLimit(t *testing.T) {
+	ctx := context.Background()
+	store, err := Open(Memory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		ok, _, err := store.ReserveSendSlot(ctx, 3, time.Minute, start.Add(time.Duration(i)*10*time.Second))
+		if err != nil || !ok {
+			t.Fatalf("reservation %d = %v, %v; want it granted", i+1, ok, err)
+		}
+	}
+	ok, retry, err := store.ReserveSendSlot(ctx, 3, time.Minute, start.Add(30*time.Second))
+	if err != nil || ok {
+		t.Fatalf("fourth reservation = %v, %v; want it refused", ok, err)
+	}
+	// The first slot, taken at start, frees up a minute later.
+	if retry != 30*time.Second {
+		t.Errorf("retry = %s, want 30s", retry)
+	}
+
+	if ok, _, err := store.ReserveSendSlot(ctx, 0, time.Minute, start.Add(30*time.Second)); err != nil || !ok {
+		t.Errorf("reservation with no limit = %v, %v; want it granted", ok, err)
+	}
+}
+
+func TestReserveSendSlotWindowExpiry(t *testing.T) {
+	ctx := context.Background()
+	store, err := Open(Memory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{start, start.Add(20 * time.Second)} {
+		if ok, _, err := store.ReserveSendSlot(ctx, 2, time.Minute, at); err != nil || !ok {
+			t.Fatalf("reservation at %s = %v, %v; want it granted", at, ok, err)
+		}
+	}
+	if ok, _, _ := store.ReserveSendSlot(ctx, 2, time.Minute, start.Add(59*time.Second)); ok {
+		t.Fatal("reservation inside the window was granted")
+	}
+	// Once the first slot is a full window old only it expires.
+	if ok, _, err := store.ReserveSendSlot(ctx, 2, time.Minute, start.Add(time.Minute)); err != nil || !ok {
+		t.Fatalf("reservation after the first slot expired = %v, %v; want it granted", ok, err)
+	}
+	ok, retry, err := store.ReserveSendSlot(ctx, 2, time.Minute, start.Add(time.Minute+time.Second))
+	if err != nil || ok {
+		t.Fatalf("reservation with the second slot still live = %v, %v; want it refused", ok, err)
+	}
+	if retry != 19*time.Second {
+		t.Errorf("retry = %s, want 19s", retry)
+	}
+}
+
+func TestReserveSendSlotAcrossStores(t *testing.T) {
+	// Two stores on one file stand in for two push processes.
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "push.db")
+	first, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = first.Close() }()
+	second, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = second.Close() }()
+
+	const limit = 10
+	now := time.Now()
+	granted := make(chan int, 2)
+	errs := make(chan error, 2)
+	for _, store := range []*Store{first, second} {
+		go func() {
+			n := 0
+			for range limit {
+				ok, _, err := store.ReserveSendSlot(ctx, limit, time.Minute, now)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if ok {
+					n++
+				}
+			}
+			granted <- n
+			errs <- nil
+		}()
+	}
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatalf("concurrent reservations failed: %v", err)
+		}
+	}
+	if total := <-granted + <-granted; total != limit {
+		t.Errorf("granted %d reservations across both stores, want exactly %d", total, limit)
+	}
+}
@@ -0,0 +1,61 @@
+// ABOUTME: Tests for opening a database copy read-only.
+// ABOUTME: Checks history reads work, writes are refused, and the file is left untouched.
+package db
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenReadOnly(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	orig := filepath.Join(dir, "push.db")
+	store, err := Open(orig)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	if _, err := store.PersistMessages(ctx, []MessageRecord{{PushoverID: 1, Message: "from the backup", ReceivedAt: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(orig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "copy.db")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ro, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly: %v", err)
+	}
+	defer func() { _ = ro.Close() }()
+	if !ro.ReadOnly() {
+		t.Error("ReadOnly() = false for a read-only store")
+	}
+	msgs, err := ro.QueryMessages(ctx, 10, nil, "")
+	if err != nil || len(msgs) != 1 || msgs[0].Message != "from the backup" {
+		t.Fatalf("QueryMessages() = %+v, %v; want the one stored message", msgs, err)
+	}
+
+	if _, err := ro.PersistMessages(ctx, []MessageRecord{{PushoverID: 2, Message: "new", ReceivedAt: time.Now()}}); err == nil {
+		t.Error("PersistMessages() on a read-only store succeeded, want an error")
+	}
+	if after, err := os.ReadFile(path); err != nil || !bytes.Equal(after, data) {
+		t.Errorf("read-only open changed the copy (%v)", err)
+	}
+
+	if _, err := OpenReadOnly(filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("OpenReadOnly() of a missing file succeeded, want an error")
+	}
+}
@@ -0,0 +1,80 @@
+// ABOUTME: Redaction of sensitive message bodies across history, sends, schedules, and reply links.
+// ABOUTME: Replaces bodies with a marker and scrubs the old text from the database file.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// RedactedMarker replaces the body of a redacted message.
+const RedactedMarker = "[redacted]"
+
+// RedactMessages replaces the bodies of the received messages with the given
+// row IDs with RedactedMarker and returns how many changed. The raw API copy,
+// which holds the body too, is dropped, and so is a URL matching pattern;
+// title, app, priority, and dates are kept.
+func (s *Store) RedactMessages(ctx context.Context, ids []int64, pattern string) (int64, error) {
+	if _, err := compilePattern(pattern); err != nil {
+		return 0, err
+	}
+	return s.redact(ctx, ids, func(tx *sql.Tx, in string, args []any) (sql.Result, error) {
+		return tx.ExecContext(ctx, `UPDATE messages SET
+                message = ?,
+                html = 0,
+                raw_json = NULL,
+                url = CASE WHEN COALESCE(url, '') REGEXP ? THEN '' ELSE url END
+            WHERE id IN (`+in+`) AND message <> ?;`,
+			append(append([]any{RedactedMarker, pattern}, args...), RedactedMarker)...)
+	})
+}
+
+// RedactSent replaces the bodies of the logged sends with the given IDs with
+// RedactedMarker and returns how many changed.
+func (s *Store) RedactSent(ctx context.Context, ids []int64) (int64, error) {
+	return s.redact(ctx, ids, func(tx *sql.Tx, in string, args []any) (sql.Result, error) {
+		return tx.ExecContext(ctx, `UPDATE sent SET message = ? WHERE id IN (`+in+`) AND message <> ?;`,
+			append(append([]any{RedactedMarker}, args...), RedactedMarker)...)
+	})
+}
+
+// RedactScheduled replaces the bodies of the scheduled sends with the given
+// IDs with RedactedMarker and returns how many changed. Pending sends are
+// left alone, since redacting one would change what goes out.
+func (s *Store) RedactScheduled(ctx context.Context, ids []int64) (int64, error) {
+	return s.redact(ctx, ids, func(tx *sql.Tx, in string, args []any) (sql.Result, error) {
+		return tx.ExecContext(ctx, `UPDATE scheduled SET message = ? WHERE id IN (`+in+`) AND status <> ? AND message <> ?;`,
+			append(append([]any{RedactedMarker}, args...), ScheduledPending, RedactedMarker)...)
+	})
+}
+
+// RedactResponses replaces the bodies of the reply links with the given IDs
+// with RedactedMarker and returns how many changed.
+func (s *Store) RedactResponses(ctx context.Context, ids []int64) (int64, error) {
+	return s.redact(ctx, ids, func(tx *sql.Tx, in string, args []any) (sql.Result, error) {
+		return tx.ExecContext(ctx, `UPDATE responses SET message = ? WHERE id IN (`+in+`) AND message <> ?;`,
+			append(append([]any{RedactedMarker}, args...), RedactedMarker)...)
+	})
+}
+
+// redact runs update in batches with freed space zeroed, then checkpoints the
+// WAL, so the old text doesn't linger in the database file.
+func (s *Store) redact(ctx context.Context, ids []int64, update func(tx *sql.Tx, in string, args []any) (sql.Result, error)) (int64, error) {
+	changed, err := s.inBatches(ctx, ids, func(tx *sql.Tx, in string, args []any) (sql.Result, error) {
+		if _, err := tx.ExecContext(ctx, `PRAGMA secure_delete = ON;`); err != nil {
+			return nil, err
+		}
+		return update(tx, in, args)
+	})
+	if err != nil {
+		return changed, err
+	}
+	if _, err := s.write.ExecContext(ctx, `PRAGMA secure_delete = OFF;`); err != nil {
+		return changed, fmt.Errorf("secure delete: %w", err)
+	}
+	if _, err := s.write.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE);`); err != nil {
+		return changed, fmt.Errorf("checkpoint: %w", err)
+	}
+	return changed, nil
+}
@@ -0,0 +1,213 @@
+// ABOUTME: Tests for redacting message bodies across every table that stores them.
+// ABOUTME: Checks no row or database page keeps the redacted text, and pending sends are left alone.
+package db
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const redactSecret = "hunter2"
+
+// tablesContaining returns the tables with a row holding text in any column.
+func tablesContaining(t *testing.T, store *Store, text string) []string {
+	t.Helper()
+	ctx := context.Background()
+	rows, err := store.sql.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%';`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		tables = append(tables, name)
+	}
+	_ = rows.Close()
+
+	var found []string
+	for _, table := range tables {
+		rows, err := store.sql.QueryContext(ctx, `SELECT * FROM `+table+`;`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cols, err := rows.Columns()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		hit := false
+		for rows.Next() {
+			if err := rows.Scan(ptrs...); err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range values {
+				if b, ok := v.([]byte); ok {
+					v = string(b)
+				}
+				if strings.Contains(fmt.Sprint(v), text) {
+					hit = true
+				}
+			}
+		}
+		_ = rows.Close()
+		if hit {
+			found = append(found, table)
+		}
+	}
+	return found
+}
+
+// seedSecrets stores body in every table that keeps message text: a received
+// message, a logged send, a sent and a pending scheduled send, and a reply
+// link. It returns the pending send's ID.
+func seedSecrets(t *testing.T, store *Store, body string) int64 {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+	if _, err := store.PersistMessages(ctx, []MessageRecord{{
+		PushoverID: 1, Title: "Creds", Message: body, App: "ci", ReceivedAt: now,
+		URL: "https://example.com/?p=" + redactSecret, RawJSON: `{"message":"` + body + `"}`,
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.LogSent(ctx, SentRecord{Message: body, Title: "Creds", SentAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	sentID, err := store.ScheduleSend(ctx, ScheduledRecord{Message: body, SendAt: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.FinishScheduled(ctx, sentID, ScheduledSent); err != nil {
+		t.Fatal(err)
+	}
+	pendingID, err := store.ScheduleSend(ctx, ScheduledRecord{Message: body, SendAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateResponse(ctx, ResponseRecord{Token: "tok", Message: body, CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	return pendingID
+}
+
+// idsOf returns the ID of each record.
+func idsOf[T any](records []T, id func(T) int64) []int64 {
+	ids := make([]int64, len(records))
+	for i, rec := range records {
+		ids[i] = id(rec)
+	}
+	return ids
+}
+
+// redactEverywhere redacts pattern the way push redact does, returning how
+// many rows changed.
+func redactEverywhere(t *testing.T, store *Store, pattern string) int64 {
+	t.Helper()
+	ctx := context.Background()
+	ids, err := store.MessageIDs(ctx, MessageFilter{BodyRegex: pattern})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent, err := store.ListSent(ctx, SentFilter{BodyRegex: pattern})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduled, err := store.MatchScheduled(ctx, pattern, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses, err := store.ListResponses(ctx, ResponseFilter{BodyRegex: pattern})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var total int64
+	for _, redact := range []func() (int64, error){
+		func() (int64, error) { return store.RedactMessages(ctx, ids, pattern) },
+		func() (int64, error) {
+			return store.RedactSent(ctx, idsOf(sent, func(r SentRecord) int64 { return r.ID }))
+		},
+		func() (int64, error) {
+			return store.RedactScheduled(ctx, idsOf(scheduled, func(r ScheduledRecord) int64 { return r.ID }))
+		},
+		func() (int64, error) {
+			return store.RedactResponses(ctx, idsOf(responses, func(r ResponseRecord) int64 { return r.ID }))
+		},
+	} {
+		n, err := redact()
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += n
+	}
+	return total
+}
+
+func TestRedactEveryTable(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "push.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	pendingID := seedSecrets(t, store, "the password is "+redactSecret)
+	pattern := "(?i)" + redactSecret
+
+	if got := redactEverywhere(t, store, pattern); got != 4 {
+		t.Errorf("first redaction changed %d rows, want 4", got)
+	}
+	// The pending send keeps its body until it's cancelled or sent.
+	if got := tablesContaining(t, store, redactSecret); len(got) != 1 || got[0] != "scheduled" {
+		t.Fatalf("tables still holding the secret = %v, want [scheduled]", got)
+	}
+	if status, err := store.ScheduledStatus(ctx, pendingID); err != nil || status != ScheduledPending {
+		t.Fatalf("pending send status = %q, %v", status, err)
+	}
+
+	if _, err := store.FinishScheduled(ctx, pendingID, ScheduledCancelled); err != nil {
+		t.Fatal(err)
+	}
+	if got := redactEverywhere(t, store, pattern); got != 1 {
+		t.Errorf("second redaction changed %d rows, want 1", got)
+	}
+	if got := tablesContaining(t, store, redactSecret); len(got) != 0 {
+		t.Fatalf("tables still holding the secret = %v", got)
+	}
+	if got := redactEverywhere(t, store, pattern); got != 0 {
+		t.Errorf("repeat redaction changed %d rows, want 0", got)
+	}
+
+	msg, _, err := store.GetMessage(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Message != RedactedMarker || msg.Title != "Creds" || msg.URL != "" || msg.RawJSON != "" {
+		t.Errorf("redacted message = %+v", msg)
+	}
+
+	// Nothing is left in the file for a disk reader to find either.
+	for _, name := range []string{path, path + "-wal"} {
+		data, err := os.ReadFile(name)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte(redactSecret)) {
+			t.Errorf("%s still contains the secret", filepath.Base(name))
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// ABOUTME: SQLite REGEXP operator backed by Go's regexp package.
+// ABOUTME: Registered once for every connection the driver opens.
+package db
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"modernc.org/sqlite"
+)
+
+// compiledPatterns caches patterns so REGEXP doesn't recompile per row.
+var compiledPatterns sync.Map
+
+func init() {
+	// SQLite rewrites "X REGEXP Y" as regexp(Y, X).
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		pattern, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("regexp: pattern must be text")
+		}
+		var subject string
+		switch v := args[1].(type) {
+		case nil:
+			return false, nil
+		case string:
+			subject = v
+		case []byte:
+			subject = string(v)
+		default:
+			subject = fmt.Sprint(v)
+		}
+
+		re, err := compilePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(subject), nil
+	})
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := compiledPatterns.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	compiledPatterns.Store(pattern, re)
+	return re, nil
+}
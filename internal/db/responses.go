@@ -0,0 +1,210 @@
+// ABOUTME: Persistence for reply links sent with notifications and their responses.
+// ABOUTME: push serve records opens, acknowledgements, and choices here.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Response states, in the order they can happen.
+const (
+	ResponseWaiting      = "waiting"
+	ResponseOpened       = "opened"
+	ResponseAcknowledged = "acknowledged"
+	ResponseAnswered     = "answered"
+)
+
+// ResponseRecord mirrors the responses table: one reply link and what the
+// recipient did with it.
+type ResponseRecord struct {
+	ID      int64  `json:"id"`
+	Token   string `json:"token"`
+	Message string `json:"message"`
+	Title   string `json:"title,omitempty"`
+	// Choices are the answers offered; empty for a plain acknowledgement.
+	Choices     []string  `json:"choices,omitempty"`
+	RequestID   string    `json:"request_id,omitempty"`
+	Status      string    `json:"status"`
+	Choice      string    `json:"choice,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	OpenedAt    time.Time `json:"opened_at,omitzero"`
+	RespondedAt time.Time `json:"responded_at,omitzero"`
+}
+
+// ResponseFilter narrows ListResponses. Zero values disable each filter.
+type ResponseFilter struct {
+	Limit  int
+	Since  *time.Time
+	Until  *time.Time
+	Status string
+	// BodyRegex matches the message body against a Go regular expression.
+	BodyRegex string
+}
+
+// Responded reports whether the link was acknowledged or answered.
+func (r ResponseRecord) Responded() bool {
+	return r.Status == ResponseAcknowledged || r.Status == ResponseAnswered
+}
+
+// CreateResponse records a reply link that was just sent.
+func (s *Store) CreateResponse(ctx context.Context, rec ResponseRecord) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now()
+	}
+	_, err := s.write.ExecContext(ctx,
+		`INSERT INTO responses (token, message, title, choices, request_id, status, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?);`,
+		rec.Token,
+		rec.Message,
+		nullIfEmpty(rec.Title),
+		nullIfEmpty(strings.Join(rec.Choices, "\n")),
+		nullIfEmpty(rec.RequestID),
+		ResponseWaiting,
+		rec.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("create response: %w", err)
+	}
+	return nil
+}
+
+// ResponseByToken returns the reply link with token.
+func (s *Store) ResponseByToken(ctx context.Context, token string) (ResponseRecord, bool, error) {
+	if s == nil || s.sql == nil {
+		return ResponseRecord{}, false, errors.New("database not initialized")
+	}
+	row := s.sql.QueryRowContext(ctx, fmt.Sprintf(`SELECT %s FROM responses WHERE token = ?;`, responseColumns), token)
+	rec, err := scanResponse(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ResponseRecord{}, false, nil
+	}
+	if err != nil {
+		return ResponseRecord{}, false, fmt.Errorf("query response: %w", err)
+	}
+	return rec, true, nil
+}
+
+// MarkResponseOpened records that the link was first opened at.
+func (s *Store) MarkResponseOpened(ctx context.Context, token string, at time.Time) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := s.write.ExecContext(ctx,
+		`UPDATE responses SET status = ?, opened_at = ? WHERE token = ? AND status = ?;`,
+		ResponseOpened, at.UTC(), token, ResponseWaiting)
+	if err != nil {
+		return fmt.Errorf("update response: %w", err)
+	}
+	return nil
+}
+
+// RecordResponse stores an acknowledgement (empty choice) or an answer. Only
+// the first response counts; it reports false when one was already recorded.
+func (s *Store) RecordResponse(ctx context.Context, token, choice string, at time.Time) (bool, error) {
+	if s == nil || s.write == nil {
+		return false, errors.New("database not initialized")
+	}
+	status := ResponseAcknowledged
+	if choice != "" {
+		status = ResponseAnswered
+	}
+	res, err := s.write.ExecContext(ctx,
+		`UPDATE responses SET status = ?, choice = ?, responded_at = ?, opened_at = COALESCE(opened_at, ?)
+        WHERE token = ? AND status IN (?, ?);`,
+		status, nullIfEmpty(choice), at.UTC(), at.UTC(), token, ResponseWaiting, ResponseOpened)
+	if err != nil {
+		return false, fmt.Errorf("record response: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("record response: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ListResponses returns reply links, newest first.
+func (s *Store) ListResponses(ctx context.Context, filter ResponseFilter) ([]ResponseRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	var where []string
+	var args []any
+	if filter.Since != nil {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if filter.Until != nil {
+		where = append(where, "created_at <= ?")
+		args = append(args, filter.Until.UTC())
+	}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.BodyRegex != "" {
+		if _, err := compilePattern(filter.BodyRegex); err != nil {
+			return nil, err
+		}
+		where = append(where, "message REGEXP ?")
+		args = append(args, filter.BodyRegex)
+	}
+	query := fmt.Sprintf(`SELECT %s FROM responses`, responseColumns)
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query responses: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var records []ResponseRecord
+	for rows.Next() {
+		rec, err := scanResponse(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan response: %w", err)
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query responses: %w", err)
+	}
+	return records, nil
+}
+
+// responseColumns lists the responses columns in the order scanResponse expects.
+const responseColumns = `id, token, message, title, choices, request_id, status, choice, created_at, opened_at, responded_at`
+
+func scanResponse(row rowScanner) (ResponseRecord, error) {
+	var rec ResponseRecord
+	var title, choices, requestID, choice sql.NullString
+	var openedAt, respondedAt sql.NullTime
+	if err := row.Scan(&rec.ID, &rec.Token, &rec.Message, &title, &choices, &requestID, &rec.Status, &choice,
+		&rec.CreatedAt, &openedAt, &respondedAt); err != nil {
+		return ResponseRecord{}, err
+	}
+	rec.Title = title.String
+	if choices.String != "" {
+		rec.Choices = strings.Split(choices.String, "\n")
+	}
+	rec.RequestID = requestID.String
+	rec.Choice = choice.String
+	rec.OpenedAt = openedAt.Time
+	rec.RespondedAt = respondedAt.Time
+	return rec, nil
+}
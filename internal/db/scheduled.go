@@ -0,0 +1,160 @@
+// ABOUTME: Persistence for delayed sends and timers waiting to go out.
+// ABOUTME: Lets another process list or cancel them, and the daemon fire timers.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Scheduled send states.
+const (
+	ScheduledPending   = "pending"
+	ScheduledSent      = "sent"
+	ScheduledCancelled = "cancelled"
+)
+
+// Kinds of scheduled send. A delayed send is sent by the push send process
+// counting it down; the daemon sends everything else.
+const (
+	ScheduledDelay    = ""
+	ScheduledTimer    = "timer"
+	ScheduledReminder = "reminder"
+)
+
+// ScheduledRecord mirrors the scheduled table.
+type ScheduledRecord struct {
+	ID        int64     `json:"id"`
+	Message   string    `json:"message"`
+	Title     string    `json:"title,omitempty"`
+	Priority  int       `json:"priority"`
+	Device    string    `json:"device,omitempty"`
+	Via       string    `json:"via,omitempty"`
+	SendAt    time.Time `json:"send_at"`
+	PID       int       `json:"pid"`
+	Status    string    `json:"status"`
+	Kind      string    `json:"kind,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Daemon reports whether push daemon sends this rather than a countdown.
+func (r ScheduledRecord) Daemon() bool {
+	return r.Kind != ScheduledDelay
+}
+
+// ScheduleSend records a pending delayed send and returns its ID.
+func (s *Store) ScheduleSend(ctx context.Context, rec ScheduledRecord) (int64, error) {
+	if s == nil || s.sql == nil {
+		return 0, errors.New("database not initialized")
+	}
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now()
+	}
+
+	res, err := s.write.ExecContext(ctx,
+		`INSERT INTO scheduled (message, title, priority, device, via, send_at, pid, status, kind, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		rec.Message,
+		rec.Title,
+		rec.Priority,
+		rec.Device,
+		rec.Via,
+		rec.SendAt.UTC(),
+		rec.PID,
+		ScheduledPending,
+		nullIfEmpty(rec.Kind),
+		rec.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("schedule send: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// PendingScheduled returns pending delayed sends and timers, soonest first.
+func (s *Store) PendingScheduled(ctx context.Context) ([]ScheduledRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+	return s.queryScheduled(ctx, `WHERE status = ?`, ScheduledPending)
+}
+
+// DueScheduled returns the pending sends the daemon should make at now,
+// soonest first.
+func (s *Store) DueScheduled(ctx context.Context, now time.Time) ([]ScheduledRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+	return s.queryScheduled(ctx, `WHERE status = ? AND COALESCE(kind, '') <> '' AND send_at <= ?`, ScheduledPending, now.UTC())
+}
+
+// MatchScheduled returns the scheduled sends in any state whose body matches
+// pattern, created at or before until when it's set, soonest first.
+func (s *Store) MatchScheduled(ctx context.Context, pattern string, until *time.Time) ([]ScheduledRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+	if _, err := compilePattern(pattern); err != nil {
+		return nil, err
+	}
+	if until == nil {
+		return s.queryScheduled(ctx, `WHERE message REGEXP ?`, pattern)
+	}
+	return s.queryScheduled(ctx, `WHERE message REGEXP ? AND created_at <= ?`, pattern, until.UTC())
+}
+
+func (s *Store) queryScheduled(ctx context.Context, where string, args ...any) ([]ScheduledRecord, error) {
+	rows, err := s.sql.QueryContext(ctx,
+		`SELECT id, message, COALESCE(title, ''), priority, COALESCE(device, ''), COALESCE(via, ''), send_at, COALESCE(pid, 0), status, COALESCE(kind, ''), created_at
+        FROM scheduled `+where+` ORDER BY send_at ASC, id ASC;`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query scheduled: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []ScheduledRecord
+	for rows.Next() {
+		var rec ScheduledRecord
+		if err := rows.Scan(&rec.ID, &rec.Message, &rec.Title, &rec.Priority, &rec.Device, &rec.Via,
+			&rec.SendAt, &rec.PID, &rec.Status, &rec.Kind, &rec.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan scheduled: %w", err)
+		}
+		results = append(results, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scheduled: %w", err)
+	}
+	return results, nil
+}
+
+// FinishScheduled moves a pending send to status, reporting false if it was
+// no longer pending (for example, cancelled from another terminal).
+func (s *Store) FinishScheduled(ctx context.Context, id int64, status string) (bool, error) {
+	if s == nil || s.sql == nil {
+		return false, errors.New("database not initialized")
+	}
+	res, err := s.write.ExecContext(ctx,
+		`UPDATE scheduled SET status = ? WHERE id = ? AND status = ?;`, status, id, ScheduledPending)
+	if err != nil {
+		return false, fmt.Errorf("update scheduled: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("update scheduled: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ScheduledStatus returns the current status of a delayed send.
+func (s *Store) ScheduledStatus(ctx context.Context, id int64) (string, error) {
+	if s == nil || s.sql == nil {
+		return "", errors.New("database not initialized")
+	}
+	var status string
+	if err := s.sql.QueryRowContext(ctx, `SELECT status FROM scheduled WHERE id = ?;`, id).Scan(&status); err != nil {
+		return "", fmt.Errorf("query scheduled: %w", err)
+	}
+	return status, nil
+}
@@ -0,0 +1,199 @@
+// ABOUTME: Queries over the sent log and emergency receipt tracking.
+// ABOUTME: Lists sends for history and records receipt acknowledgement or expiry.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Receipt states for emergency sends.
+const (
+	ReceiptPending      = "pending"
+	ReceiptAcknowledged = "acknowledged"
+	ReceiptExpired      = "expired"
+)
+
+// SentFilter narrows ListSent. Zero values disable each filter.
+type SentFilter struct {
+	Limit int
+	Since *time.Time
+	Until *time.Time
+	// ReceiptsOnly keeps emergency sends that returned a receipt.
+	ReceiptsOnly bool
+	// ReceiptStatus keeps sends whose receipt is in this state.
+	ReceiptStatus string
+	// Thread keeps sends in this thread.
+	Thread string
+	// App keeps sends made with this app's token.
+	App string
+	// BodyRegex matches the message body against a Go regular expression.
+	BodyRegex string
+}
+
+// sentColumns lists the sent columns in the order scanSent expects.
+const sentColumns = `id, message, title, device, priority, sent_at, request_id, content_hash,
+            suppressed, via, error, recipient, app, receipt, receipt_status,
+            receipt_acked_at, receipt_acked_by, receipt_expires_at, thread`
+
+// ListSent returns logged sends, newest first.
+func (s *Store) ListSent(ctx context.Context, filter SentFilter) ([]SentRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	var where []string
+	var args []any
+	if filter.Since != nil {
+		where = append(where, "sent_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if filter.Until != nil {
+		where = append(where, "sent_at < ?")
+		args = append(args, filter.Until.UTC())
+	}
+	if filter.ReceiptsOnly {
+		where = append(where, "receipt IS NOT NULL")
+	}
+	if filter.ReceiptStatus != "" {
+		where = append(where, "receipt_status = ?")
+		args = append(args, filter.ReceiptStatus)
+	}
+	if filter.Thread != "" {
+		where = append(where, "thread = ?")
+		args = append(args, filter.Thread)
+	}
+	if filter.App != "" {
+		where = append(where, "app = ?")
+		args = append(args, filter.App)
+	}
+	if filter.BodyRegex != "" {
+		if _, err := compilePattern(filter.BodyRegex); err != nil {
+			return nil, err
+		}
+		where = append(where, "message REGEXP ?")
+		args = append(args, filter.BodyRegex)
+	}
+	query := fmt.Sprintf(`SELECT %s FROM sent`, sentColumns)
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY sent_at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query sent: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var records []SentRecord
+	for rows.Next() {
+		rec, err := scanSent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan sent: %w", err)
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query sent: %w", err)
+	}
+	return records, nil
+}
+
+// SentByID returns the logged send with id.
+func (s *Store) SentByID(ctx context.Context, id int64) (SentRecord, bool, error) {
+	if s == nil || s.sql == nil {
+		return SentRecord{}, false, errors.New("database not initialized")
+	}
+
+	row := s.sql.QueryRowContext(ctx, fmt.Sprintf(`SELECT %s FROM sent WHERE id = ?;`, sentColumns), id)
+	rec, err := scanSent(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return SentRecord{}, false, nil
+	}
+	if err != nil {
+		return SentRecord{}, false, fmt.Errorf("query sent by id: %w", err)
+	}
+	return rec, true, nil
+}
+
+// SentByReceipt returns the send that returned receipt.
+func (s *Store) SentByReceipt(ctx context.Context, receipt string) (SentRecord, bool, error) {
+	if s == nil || s.sql == nil {
+		return SentRecord{}, false, errors.New("database not initialized")
+	}
+
+	row := s.sql.QueryRowContext(ctx,
+		fmt.Sprintf(`SELECT %s FROM sent WHERE receipt = ? ORDER BY id DESC LIMIT 1;`, sentColumns), receipt)
+	rec, err := scanSent(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return SentRecord{}, false, nil
+	}
+	if err != nil {
+		return SentRecord{}, false, fmt.Errorf("query sent by receipt: %w", err)
+	}
+	return rec, true, nil
+}
+
+// UpdateReceipt stores the receipt state carried by rec on the send that
+// returned rec.Receipt.
+func (s *Store) UpdateReceipt(ctx context.Context, rec SentRecord) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := s.write.ExecContext(ctx,
+		`UPDATE sent SET receipt_status = ?, receipt_acked_at = ?, receipt_acked_by = ?, receipt_expires_at = ? WHERE receipt = ?;`,
+		rec.ReceiptStatus,
+		nullTime(rec.ReceiptAckedAt),
+		nullIfEmpty(rec.ReceiptAckedBy),
+		nullTime(rec.ReceiptExpiresAt),
+		rec.Receipt,
+	)
+	if err != nil {
+		return fmt.Errorf("update receipt: %w", err)
+	}
+	return nil
+}
+
+func scanSent(row rowScanner) (SentRecord, error) {
+	var rec SentRecord
+	var title, device, requestID, hash, via, sendErr, recipient, app, receipt, status, ackedBy, thread sql.NullString
+	var suppressed sql.NullInt64
+	var ackedAt, expiresAt sql.NullTime
+	if err := row.Scan(&rec.ID, &rec.Message, &title, &device, &rec.Priority, &rec.SentAt, &requestID, &hash,
+		&suppressed, &via, &sendErr, &recipient, &app, &receipt, &status,
+		&ackedAt, &ackedBy, &expiresAt, &thread); err != nil {
+		return SentRecord{}, err
+	}
+	rec.Title = title.String
+	rec.Device = device.String
+	rec.RequestID = requestID.String
+	rec.ContentHash = hash.String
+	rec.Suppressed = suppressed.Int64 == 1
+	rec.Via = via.String
+	rec.Error = sendErr.String
+	rec.Recipient = recipient.String
+	rec.App = app.String
+	rec.Receipt = receipt.String
+	rec.ReceiptStatus = status.String
+	rec.ReceiptAckedAt = ackedAt.Time
+	rec.ReceiptAckedBy = ackedBy.String
+	rec.ReceiptExpiresAt = expiresAt.Time
+	rec.Thread = thread.String
+	return rec, nil
+}
+
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
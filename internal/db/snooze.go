@@ -0,0 +1,65 @@
+// ABOUTME: Snooze flag shared between push snooze and long-running watchers.
+// ABOUTME: Stored in the state table so running processes see it on their next poll.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const snoozeKey = "snooze_until"
+
+// SetSnooze suppresses hooks and forwarding until the given time.
+func (s *Store) SetSnooze(ctx context.Context, until time.Time) error {
+	if s == nil || s.sql == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := s.write.ExecContext(ctx,
+		`INSERT INTO state (key, value, updated_at) VALUES (?, ?, ?)
+        ON CONFLICT(key) DO UPDATE SET value=excluded.value, updated_at=excluded.updated_at;`,
+		snoozeKey, until.UTC().Format(time.RFC3339Nano), time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("set snooze: %w", err)
+	}
+	return nil
+}
+
+// SnoozedUntil returns when the current snooze ends, or the zero time when
+// nothing is snoozed at now. Expired snoozes are reported as not snoozed.
+func (s *Store) SnoozedUntil(ctx context.Context, now time.Time) (time.Time, error) {
+	if s == nil || s.sql == nil {
+		return time.Time{}, errors.New("database not initialized")
+	}
+	var value string
+	err := s.sql.QueryRowContext(ctx, `SELECT value FROM state WHERE key = ?;`, snoozeKey).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query snooze: %w", err)
+	}
+	until, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse snooze: %w", err)
+	}
+	if !until.After(now) {
+		return time.Time{}, nil
+	}
+	return until, nil
+}
+
+// ClearSnooze ends any snooze early. It reports whether one was active.
+func (s *Store) ClearSnooze(ctx context.Context, now time.Time) (bool, error) {
+	until, err := s.SnoozedUntil(ctx, now)
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.write.ExecContext(ctx, `DELETE FROM state WHERE key = ?;`, snoozeKey); err != nil {
+		return false, fmt.Errorf("clear snooze: %w", err)
+	}
+	return !until.IsZero(), nil
+}
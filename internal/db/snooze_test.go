@@ -0,0 +1,53 @@
+// ABOUTME: Tests for the snooze flag in the state table.
+// ABOUTME: Covers setting it, its expiry at now, and clearing it early.
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSnooze(t *testing.T) {
+	ctx := context.Background()
+	store, err := Open(Memory)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	if until, err := store.SnoozedUntil(ctx, now); err != nil || !until.IsZero() {
+		t.Fatalf("SnoozedUntil() before any snooze = %v, %v; want zero", until, err)
+	}
+
+	end := now.Add(time.Hour)
+	if err := store.SetSnooze(ctx, end); err != nil {
+		t.Fatalf("SetSnooze: %v", err)
+	}
+	if until, err := store.SnoozedUntil(ctx, now); err != nil || !until.Equal(end) {
+		t.Errorf("SnoozedUntil() = %v, %v; want %v", until, err, end)
+	}
+	// A snooze ending at now is over.
+	if until, err := store.SnoozedUntil(ctx, end); err != nil || !until.IsZero() {
+		t.Errorf("SnoozedUntil() at its end = %v, %v; want zero", until, err)
+	}
+
+	if active, err := store.ClearSnooze(ctx, now); err != nil || !active {
+		t.Errorf("ClearSnooze() = %v, %v; want an active snooze cleared", active, err)
+	}
+	if until, err := store.SnoozedUntil(ctx, now); err != nil || !until.IsZero() {
+		t.Errorf("SnoozedUntil() after clearing = %v, %v; want zero", until, err)
+	}
+	if active, err := store.ClearSnooze(ctx, now); err != nil || active {
+		t.Errorf("ClearSnooze() with nothing snoozed = %v, %v; want false", active, err)
+	}
+
+	// Clearing an expired snooze reports it wasn't active.
+	if err := store.SetSnooze(ctx, now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if active, err := store.ClearSnooze(ctx, now); err != nil || active {
+		t.Errorf("ClearSnooze() of an expired snooze = %v, %v; want false", active, err)
+	}
+}
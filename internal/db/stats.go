@@ -0,0 +1,187 @@
+// ABOUTME: Aggregate queries over persisted message history.
+// ABOUTME: Powers push stats and the get_stats MCP tool with per-day, per-app, and hourly counts.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// receivedAtExpr trims stored timestamps to a form SQLite date functions understand.
+const receivedAtExpr = "substr(received_at, 1, 19)"
+
+// CountBucket pairs an aggregation key with its message count.
+type CountBucket struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// CountByDay returns message counts grouped by local calendar day, oldest first.
+func (s *Store) CountByDay(ctx context.Context, since *time.Time) ([]CountBucket, error) {
+	query := fmt.Sprintf(`SELECT date(%s, 'localtime') AS bucket, COUNT(*)
+        FROM messages
+        WHERE %s
+        GROUP BY bucket
+        ORDER BY bucket ASC;`, receivedAtExpr, sinceClause(since))
+	return s.countBuckets(ctx, query, sinceArgs(since)...)
+}
+
+// CountByApp returns message counts grouped by sending application, busiest first.
+func (s *Store) CountByApp(ctx context.Context, since *time.Time) ([]CountBucket, error) {
+	query := fmt.Sprintf(`SELECT COALESCE(NULLIF(app, ''), '(unknown)') AS bucket, COUNT(*) AS total
+        FROM messages
+        WHERE %s
+        GROUP BY bucket
+        ORDER BY total DESC, bucket ASC;`, sinceClause(since))
+	return s.countBuckets(ctx, query, sinceArgs(since)...)
+}
+
+// CountByPriority returns message counts grouped by priority, highest priority first.
+func (s *Store) CountByPriority(ctx context.Context, since *time.Time) ([]CountBucket, error) {
+	query := fmt.Sprintf(`SELECT CAST(COALESCE(priority, 0) AS TEXT) AS bucket, COUNT(*)
+        FROM messages
+        WHERE %s
+        GROUP BY COALESCE(priority, 0)
+        ORDER BY COALESCE(priority, 0) DESC;`, sinceClause(since))
+	return s.countBuckets(ctx, query, sinceArgs(since)...)
+}
+
+// HourlyHistogram returns message counts for each local hour of the day (00-23).
+func (s *Store) HourlyHistogram(ctx context.Context, since *time.Time) ([]CountBucket, error) {
+	query := fmt.Sprintf(`SELECT strftime('%%H', %s, 'localtime') AS bucket, COUNT(*)
+        FROM messages
+        WHERE %s
+        GROUP BY bucket
+        ORDER BY bucket ASC;`, receivedAtExpr, sinceClause(since))
+	counts, err := s.countBuckets(ctx, query, sinceArgs(since)...)
+	if err != nil {
+		return nil, err
+	}
+
+	byHour := make(map[string]int, len(counts))
+	for _, bucket := range counts {
+		byHour[bucket.Key] = bucket.Count
+	}
+	hours := make([]CountBucket, 0, 24)
+	for hour := 0; hour < 24; hour++ {
+		key := fmt.Sprintf("%02d", hour)
+		hours = append(hours, CountBucket{Key: key, Count: byHour[key]})
+	}
+	return hours, nil
+}
+
+// SenderStats summarizes the messages one app sent.
+type SenderStats struct {
+	App   string `json:"app"`
+	Count int    `json:"count"`
+	// Urgent counts the app's messages at high or emergency priority.
+	Urgent int       `json:"urgent"`
+	LastAt time.Time `json:"last_at"`
+}
+
+// TopSenders returns the n apps that sent the most messages, busiest first.
+// Messages without an app are grouped as "(unknown)", as in CountByApp.
+func (s *Store) TopSenders(ctx context.Context, since *time.Time, n int) ([]SenderStats, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`SELECT COALESCE(NULLIF(app, ''), '(unknown)') AS sender, COUNT(*) AS total,
+            SUM(CASE WHEN COALESCE(priority, 0) >= 1 THEN 1 ELSE 0 END), MAX(%s)
+        FROM messages
+        WHERE %s
+        GROUP BY sender
+        ORDER BY total DESC, sender ASC
+        LIMIT ?;`, receivedAtExpr, sinceClause(since))
+	rows, err := s.sql.QueryContext(ctx, query, append(sinceArgs(since), n)...)
+	if err != nil {
+		return nil, fmt.Errorf("query top senders: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var senders []SenderStats
+	for rows.Next() {
+		var sender SenderStats
+		var last string
+		if err := rows.Scan(&sender.App, &sender.Count, &sender.Urgent, &last); err != nil {
+			return nil, fmt.Errorf("scan top senders: %w", err)
+		}
+		if sender.LastAt, err = time.Parse(time.DateTime, strings.Replace(last, "T", " ", 1)); err != nil {
+			return nil, fmt.Errorf("parse last message time %q: %w", last, err)
+		}
+		senders = append(senders, sender)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate top senders: %w", err)
+	}
+	return senders, nil
+}
+
+// AverageFetchDelay returns the mean time between Pushover accepting a message
+// and this client fetching it. Zero means no eligible rows.
+func (s *Store) AverageFetchDelay(ctx context.Context, since *time.Time) (time.Duration, error) {
+	if s == nil || s.sql == nil {
+		return 0, errors.New("database not initialized")
+	}
+
+	query := fmt.Sprintf(`SELECT AVG((julianday(%s) - julianday(substr(sent_at, 1, 19))) * 86400.0)
+        FROM messages
+        WHERE sent_at IS NOT NULL AND %s;`, receivedAtExpr, sinceClause(since))
+
+	var seconds sql.NullFloat64
+	if err := s.sql.QueryRowContext(ctx, query, sinceArgs(since)...).Scan(&seconds); err != nil {
+		return 0, fmt.Errorf("query fetch delay: %w", err)
+	}
+	if !seconds.Valid || seconds.Float64 < 0 {
+		return 0, nil
+	}
+	return time.Duration(seconds.Float64 * float64(time.Second)).Round(time.Second), nil
+}
+
+func (s *Store) countBuckets(ctx context.Context, query string, args ...interface{}) ([]CountBucket, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	rows, err := s.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query stats: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var buckets []CountBucket
+	for rows.Next() {
+		var key sql.NullString
+		var bucket CountBucket
+		if err := rows.Scan(&key, &bucket.Count); err != nil {
+			return nil, fmt.Errorf("scan stats: %w", err)
+		}
+		bucket.Key = key.String
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate stats: %w", err)
+	}
+	return buckets, nil
+}
+
+func sinceClause(since *time.Time) string {
+	if since == nil || since.IsZero() {
+		return "1=1"
+	}
+	return "received_at >= ?"
+}
+
+func sinceArgs(since *time.Time) []interface{} {
+	if since == nil || since.IsZero() {
+		return nil
+	}
+	return []interface{}{since.UTC()}
+}
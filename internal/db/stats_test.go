@@ -0,0 +1,152 @@
+// ABOUTME: Tests for the aggregate queries over message history.
+// ABOUTME: Covers per-app, per-priority, and hourly counts, top senders, and fetch delay.
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// statsStore returns a store holding six messages from the day before now,
+// from cron, ci, and an unnamed app, and one older cron message.
+func statsStore(t *testing.T, now time.Time) (*Store, int) {
+	t.Helper()
+	store, err := Open(Memory)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	var msgs []MessageRecord
+	add := func(app string, priority int, ago time.Duration) {
+		sent := now.Add(-ago)
+		msgs = append(msgs, MessageRecord{PushoverID: int64(len(msgs) + 1), App: app, Message: "m", Priority: priority, ReceivedAt: sent, SentAt: &sent})
+	}
+	add("cron", 0, time.Hour)
+	add("cron", 1, 2*time.Hour)
+	add("cron", 2, 3*time.Hour)
+	add("ci", 0, 30*time.Minute)
+	add("ci", -1, 90*time.Minute)
+	add("", 0, 4*time.Hour)
+	add("cron", 0, 48*time.Hour)
+	if _, err := store.PersistMessages(context.Background(), msgs); err != nil {
+		t.Fatal(err)
+	}
+	return store, len(msgs)
+}
+
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	store, total := statsStore(t, now)
+	since := now.Add(-24 * time.Hour)
+
+	apps, err := store.CountByApp(ctx, &since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantApps := []CountBucket{{"cron", 3}, {"ci", 2}, {"(unknown)", 1}}
+	if !equalBuckets(apps, wantApps) {
+		t.Errorf("CountByApp() = %v, want %v", apps, wantApps)
+	}
+
+	priorities, err := store.CountByPriority(ctx, &since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPriorities := []CountBucket{{"2", 1}, {"1", 1}, {"0", 3}, {"-1", 1}}
+	if !equalBuckets(priorities, wantPriorities) {
+		t.Errorf("CountByPriority() = %v, want %v", priorities, wantPriorities)
+	}
+
+	hours, err := store.HourlyHistogram(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counted := 0
+	for _, hour := range hours {
+		counted += hour.Count
+	}
+	if len(hours) != 24 || hours[0].Key != "00" || hours[23].Key != "23" || counted != total {
+		t.Errorf("HourlyHistogram() = %v, want 24 hours counting all %d messages", hours, total)
+	}
+}
+
+func TestTopSenders(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	store, _ := statsStore(t, now)
+	since := now.Add(-24 * time.Hour)
+
+	senders, err := store.TopSenders(ctx, &since, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SenderStats{
+		{App: "cron", Count: 3, Urgent: 2, LastAt: now.Add(-time.Hour)},
+		{App: "ci", Count: 2, Urgent: 0, LastAt: now.Add(-30 * time.Minute)},
+	}
+	if len(senders) != len(want) {
+		t.Fatalf("TopSenders() = %+v, want %+v", senders, want)
+	}
+	for i := range want {
+		if senders[i].App != want[i].App || senders[i].Count != want[i].Count || senders[i].Urgent != want[i].Urgent || !senders[i].LastAt.Equal(want[i].LastAt) {
+			t.Errorf("TopSenders()[%d] = %+v, want %+v", i, senders[i], want[i])
+		}
+	}
+	if all, err := store.TopSenders(ctx, nil, 10); err != nil || len(all) != 3 || all[0].Count != 4 {
+		t.Errorf("TopSenders(all time) = %+v, %v; want cron with 4 first of 3", all, err)
+	}
+	if none, err := store.TopSenders(ctx, nil, 0); err != nil || len(none) != 0 {
+		t.Errorf("TopSenders(0) = %+v, %v; want none", none, err)
+	}
+
+	var nilStore *Store
+	if _, err := nilStore.TopSenders(ctx, nil, 1); err == nil {
+		t.Error("TopSenders on a nil store succeeded")
+	}
+}
+
+func TestAverageFetchDelay(t *testing.T) {
+	ctx := context.Background()
+	store, err := Open(Memory)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if delay, err := store.AverageFetchDelay(ctx, nil); err != nil || delay != 0 {
+		t.Errorf("AverageFetchDelay() on an empty store = %s, %v; want 0", delay, err)
+	}
+
+	fetched := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	sent := func(ago time.Duration) *time.Time {
+		at := fetched.Add(-ago)
+		return &at
+	}
+	msgs := []MessageRecord{
+		{PushoverID: 1, Message: "m", ReceivedAt: fetched, SentAt: sent(10 * time.Second)},
+		{PushoverID: 2, Message: "m", ReceivedAt: fetched, SentAt: sent(30 * time.Second)},
+		// Without a sent time there is nothing to measure.
+		{PushoverID: 3, Message: "m", ReceivedAt: fetched},
+	}
+	if _, err := store.PersistMessages(ctx, msgs); err != nil {
+		t.Fatal(err)
+	}
+	if delay, err := store.AverageFetchDelay(ctx, nil); err != nil || delay != 20*time.Second {
+		t.Errorf("AverageFetchDelay() = %s, %v; want 20s", delay, err)
+	}
+}
+
+func equalBuckets(got, want []CountBucket) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+// ABOUTME: Persistence for the local system checks behind push sysmon.
+// ABOUTME: Stores each check's threshold and whether it is currently over it.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// System check states.
+const (
+	SysmonOK   = "ok"
+	SysmonHigh = "high"
+)
+
+// SysmonRecord mirrors the sysmon table.
+type SysmonRecord struct {
+	Name string `json:"name"`
+	// Metric is what is measured: "disk" or "load".
+	Metric string `json:"metric"`
+	// Path is the filesystem a disk check measures.
+	Path        string        `json:"path,omitempty"`
+	Threshold   float64       `json:"threshold"`
+	Every       time.Duration `json:"every"`
+	State       string        `json:"state"`
+	LastChecked time.Time     `json:"last_checked,omitzero"`
+	LastValue   float64       `json:"last_value"`
+	LastChange  time.Time     `json:"last_change,omitzero"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// Due reports whether the check should run at now; slack lets it run a
+// little early, as for monitors.
+func (r SysmonRecord) Due(now time.Time, slack time.Duration) bool {
+	return r.LastChecked.IsZero() || !now.Add(slack).Before(r.LastChecked.Add(r.Every))
+}
+
+// SaveSysmon adds a system check, or updates the one with the same name and
+// starts its state over.
+func (s *Store) SaveSysmon(ctx context.Context, r SysmonRecord) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	if r.Name == "" || r.Metric == "" {
+		return errors.New("system check name and metric are required")
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	_, err := s.write.ExecContext(ctx,
+		`INSERT INTO sysmon (name, metric, path, threshold, every_seconds, state, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(name) DO UPDATE SET
+            metric=excluded.metric,
+            path=excluded.path,
+            threshold=excluded.threshold,
+            every_seconds=excluded.every_seconds,
+            state=excluded.state,
+            last_checked=NULL,
+            last_value=NULL,
+            last_change=NULL;`,
+		r.Name, r.Metric, r.Path, r.Threshold, int64(r.Every/time.Second), SysmonOK, r.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("save system check: %w", err)
+	}
+	return nil
+}
+
+// ListSysmon returns every system check ordered by name.
+func (s *Store) ListSysmon(ctx context.Context) ([]SysmonRecord, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	rows, err := s.sql.QueryContext(ctx,
+		`SELECT name, metric, path, threshold, every_seconds, state, last_checked, last_value, last_change, created_at
+        FROM sysmon ORDER BY name ASC;`)
+	if err != nil {
+		return nil, fmt.Errorf("query system checks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []SysmonRecord
+	for rows.Next() {
+		var rec SysmonRecord
+		var everySeconds int64
+		var lastChecked, lastChange sql.NullTime
+		var lastValue sql.NullFloat64
+		if err := rows.Scan(&rec.Name, &rec.Metric, &rec.Path, &rec.Threshold, &everySeconds, &rec.State,
+			&lastChecked, &lastValue, &lastChange, &rec.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan system check: %w", err)
+		}
+		rec.Every = time.Duration(everySeconds) * time.Second
+		rec.LastChecked = lastChecked.Time
+		rec.LastValue = lastValue.Float64
+		rec.LastChange = lastChange.Time
+		results = append(results, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate system checks: %w", err)
+	}
+	return results, nil
+}
+
+// UpdateSysmonState records the outcome of a check.
+func (s *Store) UpdateSysmonState(ctx context.Context, r SysmonRecord) error {
+	if s == nil || s.write == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := s.write.ExecContext(ctx,
+		`UPDATE sysmon SET state = ?, last_checked = ?, last_value = ?, last_change = ? WHERE name = ?;`,
+		r.State, nullTime(r.LastChecked), r.LastValue, nullTime(r.LastChange), r.Name)
+	if err != nil {
+		return fmt.Errorf("update system check: %w", err)
+	}
+	return nil
+}
+
+// DeleteSysmon removes a system check, reporting whether it existed.
+func (s *Store) DeleteSysmon(ctx context.Context, name string) (bool, error) {
+	if s == nil || s.write == nil {
+		return false, errors.New("database not initialized")
+	}
+	res, err := s.write.ExecContext(ctx, `DELETE FROM sysmon WHERE name = ?;`, name)
+	if err != nil {
+		return false, fmt.Errorf("delete system check: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete system check: %w", err)
+	}
+	return affected > 0, nil
+}
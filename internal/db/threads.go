@@ -0,0 +1,105 @@
+// ABOUTME: Threads that group related sent and received notifications, such as one incident's.
+// ABOUTME: Matches incoming messages to a thread by title and app, and lists a thread's entries.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// threadWindow is how far back a received message looks for a notification
+// with the same title to take its thread from.
+const threadWindow = 24 * time.Hour
+
+// threadSkew allows for a received message stamped slightly before the send
+// it echoes was logged here.
+const threadSkew = 5 * time.Minute
+
+// Kinds of thread entries.
+const (
+	ThreadSent     = "sent"
+	ThreadReceived = "received"
+)
+
+// ThreadEntry is one sent or received notification in a thread.
+type ThreadEntry struct {
+	Kind string `json:"kind"`
+	// ID is the sent log ID for sent entries and the Pushover message ID for
+	// received ones.
+	ID       int64     `json:"id"`
+	At       time.Time `json:"at"`
+	Title    string    `json:"title,omitempty"`
+	Message  string    `json:"message"`
+	App      string    `json:"app,omitempty"`
+	Device   string    `json:"device,omitempty"`
+	Priority int       `json:"priority"`
+}
+
+// matchThread finds the thread for a received message that didn't say: the
+// thread of the latest notification in the last day with the same title,
+// either sent from here or received from the same app. An empty title
+// matches nothing.
+func matchThread(ctx context.Context, tx *sql.Tx, app, title string, received time.Time) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", nil
+	}
+	from, to := received.Add(-threadWindow).UTC(), received.Add(threadSkew).UTC()
+	var thread string
+	err := tx.QueryRowContext(ctx,
+		`SELECT thread FROM (
+            SELECT thread, sent_at AS at FROM sent
+            WHERE thread IS NOT NULL AND title = ? COLLATE NOCASE AND sent_at BETWEEN ? AND ?
+            UNION ALL
+            SELECT thread, received_at AS at FROM messages
+            WHERE thread IS NOT NULL AND app = ? AND title = ? COLLATE NOCASE AND received_at BETWEEN ? AND ?
+        ) ORDER BY at DESC LIMIT 1;`,
+		title, from, to, app, title, from, to).Scan(&thread)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("match thread: %w", err)
+	}
+	return thread, nil
+}
+
+// Thread returns the latest limit sent and received notifications in the
+// thread key, oldest first.
+func (s *Store) Thread(ctx context.Context, key string, limit int) ([]ThreadEntry, error) {
+	if key == "" {
+		return nil, errors.New("thread key is required")
+	}
+	received, err := s.FindMessages(ctx, MessageFilter{Thread: key, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	sent, err := s.ListSent(ctx, SentFilter{Thread: key, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]ThreadEntry, 0, len(received)+len(sent))
+	for _, m := range received {
+		entries = append(entries, ThreadEntry{
+			Kind: ThreadReceived, ID: m.PushoverID, At: m.ReceivedAt, Title: m.Title, Message: m.Message,
+			App: m.App, Device: m.Device, Priority: m.Priority,
+		})
+	}
+	for _, r := range sent {
+		entries = append(entries, ThreadEntry{
+			Kind: ThreadSent, ID: r.ID, At: r.SentAt, Title: r.Title, Message: r.Message,
+			App: r.App, Device: r.Device, Priority: r.Priority,
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.Before(entries[j].At) })
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
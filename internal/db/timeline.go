@@ -0,0 +1,148 @@
+// ABOUTME: Links received copies of notifications sent from here to their sends.
+// ABOUTME: Merges sent and received history into one timeline with each self-send shown once.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimelineEntry is one notification in the combined sent and received
+// history. A notification sent to your own user whose copy was received is a
+// single sent entry with the Delivered fields set.
+type TimelineEntry struct {
+	ThreadEntry
+	// DeliveredAt is when the received copy arrived, DeliveredID its Pushover
+	// message ID, and DeliveredTo the device that received it.
+	DeliveredAt time.Time `json:"delivered_at,omitzero"`
+	DeliveredID int64     `json:"delivered_id,omitempty"`
+	DeliveredTo string    `json:"delivered_to,omitempty"`
+}
+
+// linkSent finds the send a received message is a copy of and returns its
+// sent log ID, or zero. Pushover doesn't hand the send's request ID back to
+// the receiving device, so an emergency message is matched by its receipt and
+// any other by content: a successful Pushover send to your own user with the
+// same body and title (or none), to the receiving device or all devices,
+// logged within threadSkew of when Pushover dated the message. Each send is
+// linked to at most one copy per device, the oldest send first, so repeats of
+// the same text pair up in order.
+func linkSent(ctx context.Context, tx *sql.Tx, msg MessageRecord, received time.Time) (int64, error) {
+	var id int64
+	if msg.Receipt != "" {
+		err := tx.QueryRowContext(ctx, `SELECT id FROM sent WHERE receipt = ? ORDER BY id LIMIT 1;`, msg.Receipt).Scan(&id)
+		if err == nil {
+			return id, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("link sent: %w", err)
+		}
+	}
+
+	dated := received
+	if msg.SentAt != nil {
+		dated = *msg.SentAt
+	}
+	from, to := dated.Add(-threadSkew).UTC(), dated.Add(threadSkew).UTC()
+	err := tx.QueryRowContext(ctx,
+		`SELECT id FROM sent
+        WHERE message = ? AND (COALESCE(title, '') = '' OR title = ? COLLATE NOCASE)
+            AND sent_at BETWEEN ? AND ?
+            AND error IS NULL AND COALESCE(suppressed, 0) = 0
+            AND COALESCE(via, '') IN ('', 'pushover') AND recipient IS NULL
+            AND (? = '' OR COALESCE(device, '') = '' OR instr(',' || device || ',', ',' || ? || ',') > 0)
+            AND NOT EXISTS (
+                SELECT 1 FROM messages m
+                WHERE m.sent_id = sent.id AND COALESCE(m.device, '') = ? AND m.pushover_id <> ?
+            )
+        ORDER BY sent_at ASC, id ASC LIMIT 1;`,
+		msg.Message, msg.Title, from, to, msg.Device, msg.Device, msg.Device, msg.PushoverID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("link sent: %w", err)
+	}
+	return id, nil
+}
+
+// Timeline returns the latest limit sent and received notifications between
+// since and until (either may be nil), newest first. Received copies of sends
+// are folded into the send; archived messages are left out.
+func (s *Store) Timeline(ctx context.Context, since, until *time.Time, limit int) ([]TimelineEntry, error) {
+	if s == nil || s.sql == nil {
+		return nil, errors.New("database not initialized")
+	}
+	received, err := s.FindMessages(ctx, MessageFilter{Since: since, Until: until, Limit: limit, HideArchived: true, Unlinked: true})
+	if err != nil {
+		return nil, err
+	}
+	sent, err := s.ListSent(ctx, SentFilter{Since: since, Until: until, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	copies, err := s.deliveredCopies(ctx, sent)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]TimelineEntry, 0, len(received)+len(sent))
+	for _, m := range received {
+		entries = append(entries, TimelineEntry{ThreadEntry: ThreadEntry{
+			Kind: ThreadReceived, ID: m.PushoverID, At: m.ReceivedAt, Title: m.Title, Message: m.Message,
+			App: m.App, Device: m.Device, Priority: m.Priority,
+		}})
+	}
+	for _, r := range sent {
+		entry := TimelineEntry{ThreadEntry: ThreadEntry{
+			Kind: ThreadSent, ID: r.ID, At: r.SentAt, Title: r.Title, Message: r.Message,
+			App: r.App, Device: r.Device, Priority: r.Priority,
+		}}
+		if c, ok := copies[r.ID]; ok {
+			entry.DeliveredAt, entry.DeliveredID, entry.DeliveredTo = c.ReceivedAt, c.PushoverID, c.Device
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.After(entries[j].At) })
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// deliveredCopies maps the IDs of the given sends to the first received copy
+// of each, for those with one.
+func (s *Store) deliveredCopies(ctx context.Context, sent []SentRecord) (map[int64]MessageRecord, error) {
+	copies := make(map[int64]MessageRecord)
+	if len(sent) == 0 {
+		return copies, nil
+	}
+	args := make([]any, len(sent))
+	for i, r := range sent {
+		args[i] = r.ID
+	}
+	rows, err := s.sql.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM messages
+        WHERE sent_id IN (%s)
+        ORDER BY received_at DESC, id DESC;`, messageColumns, strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query delivered copies: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		rec, err := scanMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan delivered copy: %w", err)
+		}
+		// Newest first, so the earliest copy is the one kept.
+		copies[rec.SentID] = rec
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate delivered copies: %w", err)
+	}
+	return copies, nil
+}
@@ -0,0 +1,113 @@
+// ABOUTME: Tests for linking received copies to sends and the combined timeline.
+// ABOUTME: Covers matching by content, time, device, and receipt, and folding copies into sends.
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var timelineNow = time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+
+func timelineAt(ago time.Duration) *time.Time {
+	sent := timelineNow.Add(-ago)
+	return &sent
+}
+
+// timelineStore returns a store holding seven sends and nine received
+// messages, some of them copies of those sends.
+func timelineStore(t *testing.T) *Store {
+	t.Helper()
+	ctx := context.Background()
+	store, err := Open(Memory)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	now := timelineNow
+	for _, rec := range []SentRecord{
+		{Message: "deploy done", Title: "CI", SentAt: now.Add(-10 * time.Minute)},           // 1
+		{Message: "deploy done", Title: "CI", SentAt: now.Add(-5 * time.Minute)},            // 2
+		{Message: "to a friend", SentAt: now.Add(-4 * time.Minute), Recipient: "alice"},     // 3
+		{Message: "failed", SentAt: now.Add(-3 * time.Minute), Error: "invalid token"},      // 4
+		{Message: "wake up", Priority: 2, SentAt: now.Add(-2 * time.Minute), Receipt: "r1"}, // 5
+		{Message: "tablet only", SentAt: now.Add(-time.Minute), Device: "tablet"},           // 6
+		{Message: "via ntfy", SentAt: now.Add(-time.Minute), Via: "ntfy"},                   // 7
+	} {
+		if err := store.LogSent(ctx, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := store.PersistMessages(ctx, []MessageRecord{
+		{PushoverID: 101, Message: "deploy done", Title: "ci", Device: "phone", SentAt: timelineAt(10 * time.Minute), ReceivedAt: now},
+		{PushoverID: 102, Message: "deploy done", Title: "CI", Device: "phone", SentAt: timelineAt(5 * time.Minute), ReceivedAt: now},
+		{PushoverID: 103, Message: "deploy done", Title: "CI", Device: "laptop", SentAt: timelineAt(9 * time.Minute), ReceivedAt: now},
+		{PushoverID: 104, Message: "to a friend", Device: "phone", SentAt: timelineAt(4 * time.Minute), ReceivedAt: now},
+		{PushoverID: 105, Message: "failed", Device: "phone", SentAt: timelineAt(3 * time.Minute), ReceivedAt: now},
+		{PushoverID: 106, Message: "wake up, edited", Priority: 2, Receipt: "r1", Device: "phone", SentAt: timelineAt(2 * time.Minute), ReceivedAt: now},
+		{PushoverID: 107, Message: "tablet only", Device: "phone", SentAt: timelineAt(time.Minute), ReceivedAt: now},
+		{PushoverID: 108, Message: "via ntfy", Device: "phone", SentAt: timelineAt(time.Minute), ReceivedAt: now},
+		{PushoverID: 109, Message: "deploy done", Title: "CI", Device: "phone", SentAt: timelineAt(time.Hour), ReceivedAt: now},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestLinkSends(t *testing.T) {
+	ctx := context.Background()
+	store := timelineStore(t)
+
+	want := map[int64]int64{101: 1, 102: 2, 103: 1, 104: 0, 105: 0, 106: 5, 107: 0, 108: 0, 109: 0}
+	for id, sentID := range want {
+		rec, found, err := store.GetMessage(ctx, id)
+		if err != nil || !found {
+			t.Fatalf("GetMessage(%d) = %v, %v", id, found, err)
+		}
+		if rec.SentID != sentID {
+			t.Errorf("message %d linked to send %d, want %d", id, rec.SentID, sentID)
+		}
+	}
+	// Fetching a message again keeps its link.
+	if _, err := store.PersistMessages(ctx, []MessageRecord{{PushoverID: 101, Message: "deploy done", Title: "ci", Device: "phone", SentAt: timelineAt(10 * time.Minute), ReceivedAt: timelineNow}}); err != nil {
+		t.Fatal(err)
+	}
+	if rec, _, _ := store.GetMessage(ctx, 101); rec.SentID != 1 {
+		t.Errorf("refetched message linked to send %d, want 1", rec.SentID)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	ctx := context.Background()
+	store := timelineStore(t)
+
+	entries, err := store.Timeline(ctx, nil, nil, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]int{}
+	delivered := map[int64]int64{}
+	for _, e := range entries {
+		kinds[e.Kind]++
+		if e.Kind == ThreadSent && e.DeliveredID != 0 {
+			delivered[e.ID] = e.DeliveredID
+		}
+	}
+	if kinds[ThreadSent] != 7 || kinds[ThreadReceived] != 5 {
+		t.Errorf("timeline has %v, want 7 sent and 5 received", kinds)
+	}
+	if len(delivered) != 3 || delivered[1] == 0 || delivered[2] != 102 || delivered[5] != 106 {
+		t.Errorf("delivered sends = %v, want 1, 2 (as 102), and 5 (as 106)", delivered)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].At.After(entries[i-1].At) {
+			t.Fatalf("timeline out of order at %d: %v after %v", i, entries[i].At, entries[i-1].At)
+		}
+	}
+	if limited, err := store.Timeline(ctx, nil, nil, 3); err != nil || len(limited) != 3 {
+		t.Errorf("Timeline(limit 3) = %d entries, %v", len(limited), err)
+	}
+}
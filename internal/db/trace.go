@@ -0,0 +1,77 @@
+// ABOUTME: OpenTelemetry spans and busy retries around SQLite statements.
+// ABOUTME: Wraps the context-aware sql.DB calls used by Store methods.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/harper/push/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedDB is a sql.DB whose context-aware calls run inside a span and are
+// retried while the database is locked (see retryBusy). Calls without a
+// context, used only by migrations, pass straight through.
+type tracedDB struct {
+	*sql.DB
+}
+
+func (d *tracedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := startStatement(ctx, query)
+	var rows *sql.Rows
+	err := retryBusy(ctx, func() (err error) {
+		rows, err = d.DB.QueryContext(ctx, query, args...)
+		return err
+	})
+	telemetry.End(span, err)
+	return rows, err
+}
+
+func (d *tracedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := startStatement(ctx, query)
+	var row *sql.Row
+	_ = retryBusy(ctx, func() error {
+		row = d.DB.QueryRowContext(ctx, query, args...)
+		return row.Err()
+	})
+	telemetry.End(span, row.Err())
+	return row
+}
+
+func (d *tracedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := startStatement(ctx, query)
+	var result sql.Result
+	err := retryBusy(ctx, func() (err error) {
+		result, err = d.DB.ExecContext(ctx, query, args...)
+		return err
+	})
+	telemetry.End(span, err)
+	return result, err
+}
+
+func (d *tracedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	ctx, span := telemetry.Start(ctx, "sqlite BEGIN", attribute.String("db.system.name", "sqlite"))
+	var tx *sql.Tx
+	err := retryBusy(ctx, func() (err error) {
+		tx, err = d.DB.BeginTx(ctx, opts)
+		return err
+	})
+	telemetry.End(span, err)
+	return tx, err
+}
+
+// startStatement names the span after the statement's leading keyword, e.g.
+// "sqlite SELECT". Statements are parameterized, so the text holds no values.
+func startStatement(ctx context.Context, query string) (context.Context, trace.Span) {
+	query = strings.TrimSpace(query)
+	operation, _, _ := strings.Cut(query, " ")
+	operation = strings.ToUpper(operation)
+	return telemetry.Start(ctx, "sqlite "+operation,
+		attribute.String("db.system.name", "sqlite"),
+		attribute.String("db.operation.name", operation),
+		attribute.String("db.query.text", query),
+	)
+}
@@ -0,0 +1,251 @@
+// ABOUTME: Digest of received messages grouped by app and priority, for push digest.
+// ABOUTME: Renders it as Markdown or as one notification sized for Pushover.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/render"
+	"github.com/harper/push/pkg/pushover"
+)
+
+const (
+	// maxMessage is Pushover's message length limit, in characters.
+	maxMessage = 1024
+	// maxLatest caps the latest message quoted for each app.
+	maxLatest = 60
+	// noApp names messages that came without an app.
+	noApp = "(no app)"
+)
+
+// Digest summarizes the messages received from Since until Until.
+type Digest struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	Total int       `json:"total"`
+	Apps  []App     `json:"apps"`
+}
+
+// App is one app's part of a digest.
+type App struct {
+	App   string `json:"app"`
+	Count int    `json:"count"`
+	// Priorities counts the app's messages per priority, highest first.
+	Priorities []PriorityCount `json:"priorities"`
+	// Latest is the app's most recent message on one line.
+	Latest string `json:"latest,omitempty"`
+}
+
+// PriorityCount is how many of an app's messages had one priority.
+type PriorityCount struct {
+	Priority int `json:"priority"`
+	Count    int `json:"count"`
+}
+
+// highest is the app's highest message priority.
+func (a App) highest() int {
+	if len(a.Priorities) == 0 {
+		return 0
+	}
+	return a.Priorities[0].Priority
+}
+
+// Build assembles a digest from per-app priority counts and each app's latest
+// message. Apps are ordered by their highest priority, then by count.
+func Build(since, until time.Time, counts []db.AppPriorityCount, latest []db.AppDigest) Digest {
+	d := Digest{Since: since, Until: until}
+	byApp := map[string]*App{}
+	var order []string
+	for _, c := range counts {
+		app, ok := byApp[c.App]
+		if !ok {
+			app = &App{App: c.App}
+			byApp[c.App] = app
+			order = append(order, c.App)
+		}
+		app.Count += c.Count
+		app.Priorities = append(app.Priorities, PriorityCount{Priority: c.Priority, Count: c.Count})
+		d.Total += c.Count
+	}
+	for _, l := range latest {
+		if app, ok := byApp[l.App]; ok {
+			app.Latest = latestLine(l.Latest)
+		}
+	}
+
+	d.Apps = make([]App, 0, len(order))
+	for _, name := range order {
+		app := byApp[name]
+		sort.SliceStable(app.Priorities, func(i, j int) bool { return app.Priorities[i].Priority > app.Priorities[j].Priority })
+		d.Apps = append(d.Apps, *app)
+	}
+	sort.SliceStable(d.Apps, func(i, j int) bool {
+		a, b := d.Apps[i], d.Apps[j]
+		if a.highest() != b.highest() {
+			return a.highest() > b.highest()
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.App < b.App
+	})
+	return d
+}
+
+// Load builds the digest of the messages matching filter, which should set
+// Since and Until.
+func Load(ctx context.Context, store *db.Store, filter db.MessageFilter) (Digest, error) {
+	counts, err := store.CountByAppPriority(ctx, filter)
+	if err != nil {
+		return Digest{}, err
+	}
+	latest, err := store.DigestByApp(ctx, filter)
+	if err != nil {
+		return Digest{}, err
+	}
+	var since, until time.Time
+	if filter.Since != nil {
+		since = *filter.Since
+	}
+	if filter.Until != nil {
+		until = *filter.Until
+	}
+	return Build(since, until, counts, latest), nil
+}
+
+// Markdown renders the digest as a Markdown document.
+func (d Digest) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# Push digest\n\n")
+	fmt.Fprintf(&b, "%s, %s to %s.\n", d.headline(), d.Since.Local().Format("2006-01-02 15:04"), d.Until.Local().Format("2006-01-02 15:04"))
+	if len(d.Apps) > 0 {
+		b.WriteString("\n")
+	}
+	for _, app := range d.Apps {
+		fmt.Fprintf(&b, "- **%s**: %s", markdownEscape(app.name()), app.counted())
+		if app.Latest != "" {
+			fmt.Fprintf(&b, ". Latest: %s", markdownEscape(app.Latest))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Notification renders the digest as one notification, one line per app,
+// listing as many apps as fit in a Pushover message.
+func (d Digest) Notification() pushover.SendParams {
+	params := pushover.SendParams{Title: "Digest: " + d.headline()}
+	if len(d.Apps) == 0 {
+		params.Message = "No messages received since " + d.Since.Local().Format("2006-01-02 15:04") + "."
+		return params
+	}
+
+	var lines []string
+	used := 0
+	for i, app := range d.Apps {
+		line := app.name() + ": " + app.counted()
+		if app.Latest != "" {
+			line += " — " + app.Latest
+		}
+		// Keep room for the line saying how many apps didn't fit.
+		need := used + len([]rune(line))
+		if rest := len(d.Apps) - i - 1; rest > 0 {
+			need += 1 + len([]rune(moreApps(rest)))
+		}
+		if need > maxMessage {
+			lines = append(lines, moreApps(len(d.Apps)-i))
+			break
+		}
+		lines = append(lines, line)
+		used += len([]rune(line)) + 1
+	}
+	params.Message = strings.Join(lines, "\n")
+	return params
+}
+
+// headline counts the messages and apps, e.g. "42 messages from 3 apps".
+func (d Digest) headline() string {
+	switch {
+	case d.Total == 0:
+		return "no messages"
+	case len(d.Apps) == 1:
+		return plural(d.Total, "message") + " from " + d.Apps[0].name()
+	default:
+		return plural(d.Total, "message") + " from " + plural(len(d.Apps), "app")
+	}
+}
+
+func (a App) name() string {
+	if a.App == "" {
+		return noApp
+	}
+	return a.App
+}
+
+// counted is the app's message count with its priorities, e.g. "12 (2 high,
+// 10 normal)". Apps whose messages are all normal priority get the count alone.
+func (a App) counted() string {
+	if len(a.Priorities) == 1 && a.Priorities[0].Priority == 0 {
+		return fmt.Sprintf("%d", a.Count)
+	}
+	parts := make([]string, 0, len(a.Priorities))
+	for _, p := range a.Priorities {
+		parts = append(parts, fmt.Sprintf("%d %s", p.Count, priorityName(p.Priority)))
+	}
+	return fmt.Sprintf("%d (%s)", a.Count, strings.Join(parts, ", "))
+}
+
+// priorityName names a Pushover priority, e.g. "high" for 1.
+func priorityName(priority int) string {
+	switch priority {
+	case -2:
+		return "lowest"
+	case -1:
+		return "low"
+	case 0:
+		return "normal"
+	case 1:
+		return "high"
+	case 2:
+		return "emergency"
+	}
+	return fmt.Sprintf("priority %d", priority)
+}
+
+// latestLine puts a message's title and body on one short line.
+func latestLine(rec db.MessageRecord) string {
+	body := rec.Message
+	if rec.HTML {
+		body = render.HTML(body, false)
+	}
+	line := strings.Join(strings.Fields(body), " ")
+	if rec.Title != "" {
+		line = rec.Title + ": " + line
+	}
+	if runes := []rune(line); len(runes) > maxLatest {
+		line = string(runes[:maxLatest-1]) + "…"
+	}
+	return line
+}
+
+func moreApps(n int) string {
+	return fmt.Sprintf("…and %d more apps", n)
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `_`, `\_`, "`", "\\`", `[`, `\[`, `]`, `\]`)
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,96 @@
+// ABOUTME: Tests for digests of received messages.
+// ABOUTME: Checks grouping and ordering, Markdown output, and fitting the notification.
+package digest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/harper/push/internal/db"
+)
+
+func TestBuild(t *testing.T) {
+	since := time.Date(2026, 3, 1, 8, 0, 0, 0, time.Local)
+	d := Build(since, since.Add(24*time.Hour), []db.AppPriorityCount{
+		{App: "backups", Priority: 0, Count: 10},
+		{App: "backups", Priority: 1, Count: 2},
+		{App: "cron", Priority: -2, Count: 40},
+		{App: "nas", Priority: 0, Count: 3},
+		{App: "", Priority: 0, Count: 1},
+	}, []db.AppDigest{
+		{App: "backups", Latest: db.MessageRecord{Title: "Backup", Message: "finished:\n 3 files"}},
+		{App: "nas", Latest: db.MessageRecord{Message: strings.Repeat("x", 100)}},
+	})
+
+	if d.Total != 56 {
+		t.Errorf("Total = %d, want 56", d.Total)
+	}
+	var order []string
+	for _, app := range d.Apps {
+		order = append(order, app.App+"="+app.counted())
+	}
+	want := []string{"backups=12 (2 high, 10 normal)", "nas=3", "=1", "cron=40 (40 lowest)"}
+	if fmt.Sprint(order) != fmt.Sprint(want) {
+		t.Errorf("apps = %q, want %q", order, want)
+	}
+	if got := d.Apps[0].Latest; got != "Backup: finished: 3 files" {
+		t.Errorf("latest = %q", got)
+	}
+	if got := d.Apps[1].Latest; utf8.RuneCountInString(got) != maxLatest || !strings.HasSuffix(got, "…") {
+		t.Errorf("long latest = %q, want it cut to %d characters", got, maxLatest)
+	}
+
+	md := d.Markdown()
+	for _, line := range []string{
+		"# Push digest",
+		"56 messages from 4 apps, 2026-03-01 08:00 to 2026-03-02 08:00.",
+		"- **backups**: 12 (2 high, 10 normal). Latest: Backup: finished: 3 files",
+		"- **(no app)**: 1",
+		"- **cron**: 40 (40 lowest)",
+	} {
+		if !strings.Contains(md, line+"\n") {
+			t.Errorf("Markdown() missing %q:\n%s", line, md)
+		}
+	}
+
+	params := d.Notification()
+	if params.Title != "Digest: 56 messages from 4 apps" {
+		t.Errorf("title = %q", params.Title)
+	}
+	if first := strings.Split(params.Message, "\n")[0]; first != "backups: 12 (2 high, 10 normal) — Backup: finished: 3 files" {
+		t.Errorf("first line = %q", first)
+	}
+}
+
+func TestNotificationFits(t *testing.T) {
+	var counts []db.AppPriorityCount
+	var latest []db.AppDigest
+	for i := range 40 {
+		app := fmt.Sprintf("app-%02d", i)
+		counts = append(counts, db.AppPriorityCount{App: app, Count: 100 - i})
+		latest = append(latest, db.AppDigest{App: app, Latest: db.MessageRecord{Message: strings.Repeat("word ", 20)}})
+	}
+	params := Build(time.Now().Add(-time.Hour), time.Now(), counts, latest).Notification()
+	if n := utf8.RuneCountInString(params.Message); n > maxMessage {
+		t.Fatalf("message is %d characters, over %d", n, maxMessage)
+	}
+	lines := strings.Split(params.Message, "\n")
+	last := lines[len(lines)-1]
+	if want := fmt.Sprintf("…and %d more apps", 40-len(lines)+1); last != want {
+		t.Errorf("last line = %q, want %q", last, want)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	since := time.Date(2026, 3, 1, 8, 0, 0, 0, time.Local)
+	d := Build(since, since.Add(time.Hour), nil, nil)
+	if params := d.Notification(); params.Title != "Digest: no messages" || !strings.Contains(params.Message, "2026-03-01 08:00") {
+		t.Errorf("empty notification = %+v", params)
+	}
+	if md := d.Markdown(); !strings.HasSuffix(md, "no messages, 2026-03-01 08:00 to 2026-03-01 09:00.\n") {
+		t.Errorf("empty Markdown() = %q", md)
+	}
+}
@@ -0,0 +1,227 @@
+// ABOUTME: Reads RSS and Atom feeds and picks out the items push feed hasn't seen.
+// ABOUTME: Turns new items into notifications, one each or a summary for a burst.
+package feed
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/harper/push/pkg/pushover"
+)
+
+const (
+	// maxFeedSize bounds how much of a feed is read.
+	maxFeedSize = 8 << 20
+	// maxSeparate is the most new items sent as separate notifications; more
+	// than that in one poll are summarised in one.
+	maxSeparate = 3
+	// maxListed caps the items listed in a summary.
+	maxListed = 10
+)
+
+// Item is one entry of a feed.
+type Item struct {
+	// GUID identifies the item across polls: the RSS guid or Atom id, or the
+	// link or title when the feed has neither.
+	GUID  string
+	Title string
+	Link  string
+}
+
+// Feed is a parsed feed, with its items in document order (usually newest
+// first).
+type Feed struct {
+	Title string
+	Link  string
+	Items []Item
+}
+
+// Result is what a fetch returned. NotModified is set when the server
+// answered a conditional request with 304, in which case Feed is empty.
+type Result struct {
+	Feed         Feed
+	ETag         string
+	LastModified string
+	NotModified  bool
+}
+
+// xmlLink covers RSS's <link>url</link> and Atom's <link href="url"/>.
+type xmlLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Text string `xml:",chardata"`
+}
+
+type xmlItem struct {
+	GUID  string    `xml:"guid"`
+	ID    string    `xml:"id"`
+	About string    `xml:"about,attr"`
+	Title string    `xml:"title"`
+	Links []xmlLink `xml:"link"`
+}
+
+type xmlChannel struct {
+	Title string    `xml:"title"`
+	Links []xmlLink `xml:"link"`
+	Items []xmlItem `xml:"item"`
+}
+
+// xmlDocument decodes RSS 2.0 (<rss><channel><item>), RSS 1.0
+// (<rdf:RDF><channel/><item>), and Atom (<feed><entry>).
+type xmlDocument struct {
+	XMLName xml.Name
+	Title   string      `xml:"title"`
+	Links   []xmlLink   `xml:"link"`
+	Entries []xmlItem   `xml:"entry"`
+	Items   []xmlItem   `xml:"item"`
+	Channel *xmlChannel `xml:"channel"`
+}
+
+var tags = regexp.MustCompile(`<[^>]*>`)
+
+// Parse reads an RSS or Atom feed.
+func Parse(r io.Reader) (Feed, error) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	// Feeds in other charsets are read as they are; titles are mostly ASCII.
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	var doc xmlDocument
+	if err := dec.Decode(&doc); err != nil {
+		return Feed{}, fmt.Errorf("parse feed: %w", err)
+	}
+
+	var f Feed
+	var items []xmlItem
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss":
+		if doc.Channel == nil {
+			return Feed{}, fmt.Errorf("parse feed: rss without a channel")
+		}
+		f.Title, f.Link = doc.Channel.Title, pickLink(doc.Channel.Links)
+		items = doc.Channel.Items
+	case "rdf":
+		if doc.Channel != nil {
+			f.Title, f.Link = doc.Channel.Title, pickLink(doc.Channel.Links)
+		}
+		items = doc.Items
+	case "feed":
+		f.Title, f.Link = doc.Title, pickLink(doc.Links)
+		items = doc.Entries
+	default:
+		return Feed{}, fmt.Errorf("parse feed: <%s> is not an RSS or Atom feed", doc.XMLName.Local)
+	}
+
+	f.Title = clean(f.Title)
+	for _, x := range items {
+		item := Item{Title: clean(x.Title), Link: pickLink(x.Links)}
+		for _, id := range []string{x.GUID, x.ID, x.About, item.Link, item.Title} {
+			if id = strings.TrimSpace(id); id != "" {
+				item.GUID = id
+				break
+			}
+		}
+		if item.GUID == "" {
+			continue
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f, nil
+}
+
+// pickLink returns the alternate link: Atom's rel="alternate" (or no rel)
+// href, or RSS's element text.
+func pickLink(links []xmlLink) string {
+	for _, l := range links {
+		if l.Href != "" && (l.Rel == "" || l.Rel == "alternate") {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	for _, l := range links {
+		if text := strings.TrimSpace(l.Text); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// clean flattens a title that may carry HTML into one line of text.
+func clean(s string) string {
+	s = html.UnescapeString(tags.ReplaceAllString(s, ""))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Fetch requests the feed at url. etag and lastModified come from the
+// previous fetch and make the request conditional.
+func Fetch(ctx context.Context, client *http.Client, url, etag, lastModified string) (Result, error) {
+	resp, err := ConditionalGet(ctx, client, url, "push-feed",
+		"application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.5",
+		etag, lastModified, maxFeedSize)
+	if err != nil {
+		return Result{}, fmt.Errorf("fetch feed: %w", err)
+	}
+	if resp.NotModified {
+		return Result{ETag: resp.ETag, LastModified: resp.LastModified, NotModified: true}, nil
+	}
+	f, err := Parse(bytes.NewReader(resp.Body))
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Feed: f, ETag: resp.ETag, LastModified: resp.LastModified}, nil
+}
+
+// CompileFilter compiles a --filter expression; an empty one matches
+// everything and compiles to nil.
+func CompileFilter(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	return re, nil
+}
+
+// Notifications returns what to send for the new items of the feed named
+// name, oldest first: a notification each with the item's link, or one
+// summary when there are more than a few.
+func Notifications(name string, f Feed, items []Item) []pushover.SendParams {
+	// Feeds list their newest items first.
+	ordered := make([]Item, len(items))
+	for i, item := range items {
+		ordered[len(items)-1-i] = item
+	}
+
+	if len(ordered) <= maxSeparate {
+		out := make([]pushover.SendParams, 0, len(ordered))
+		for _, item := range ordered {
+			message := item.Title
+			if message == "" {
+				message = item.Link
+			}
+			out = append(out, pushover.SendParams{Title: name, Message: message, URL: item.Link})
+		}
+		return out
+	}
+
+	lines := make([]string, 0, maxListed+1)
+	for i, item := range ordered {
+		if i == maxListed {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(ordered)-maxListed))
+			break
+		}
+		lines = append(lines, "• "+item.Title)
+	}
+	return []pushover.SendParams{{
+		Title:   fmt.Sprintf("%s: %d new items", name, len(ordered)),
+		Message: strings.Join(lines, "\n"),
+		URL:     f.Link,
+	}}
+}
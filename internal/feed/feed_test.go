@@ -0,0 +1,151 @@
+// ABOUTME: Tests for parsing feeds and turning new items into notifications.
+// ABOUTME: Covers RSS 2.0, RSS 1.0, Atom, conditional fetches, and burst summaries.
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const rss = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+<channel>
+  <title>Example Blog</title>
+  <link>https://example.com/</link>
+  <atom:link href="https://example.com/feed.xml" rel="self"/>
+  <item>
+    <title>Second &amp;amp; newest</title>
+    <link>https://example.com/2</link>
+    <guid isPermaLink="false">post-2</guid>
+  </item>
+  <item>
+    <title>First</title>
+    <link>https://example.com/1</link>
+  </item>
+</channel>
+</rss>`
+
+const atom = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Release notes from push</title>
+  <link rel="alternate" type="text/html" href="https://github.com/harperreed/push/releases"/>
+  <link rel="self" href="https://github.com/harperreed/push/releases.atom"/>
+  <entry>
+    <id>tag:github.com,2008:Repository/1/v1.2.0</id>
+    <title type="html">&lt;b&gt;v1.2.0&lt;/b&gt;</title>
+    <link rel="alternate" type="text/html" href="https://github.com/harperreed/push/releases/tag/v1.2.0"/>
+  </entry>
+</feed>`
+
+const rdf = `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">
+  <channel rdf:about="https://example.org/"><title>Old School</title><link>https://example.org/</link></channel>
+  <item rdf:about="https://example.org/a"><title>A</title><link>https://example.org/a</link></item>
+</rdf:RDF>`
+
+func TestParse(t *testing.T) {
+	f, err := Parse(strings.NewReader(rss))
+	if err != nil {
+		t.Fatalf("Parse(rss): %v", err)
+	}
+	if f.Title != "Example Blog" || f.Link != "https://example.com/" || len(f.Items) != 2 {
+		t.Fatalf("rss = %+v", f)
+	}
+	if got := f.Items[0]; got.GUID != "post-2" || got.Title != "Second & newest" || got.Link != "https://example.com/2" {
+		t.Errorf("rss item 0 = %+v", got)
+	}
+	if got := f.Items[1]; got.GUID != "https://example.com/1" {
+		t.Errorf("rss item without a guid = %+v, want the link as GUID", got)
+	}
+
+	f, err = Parse(strings.NewReader(atom))
+	if err != nil {
+		t.Fatalf("Parse(atom): %v", err)
+	}
+	if f.Link != "https://github.com/harperreed/push/releases" || len(f.Items) != 1 {
+		t.Fatalf("atom = %+v", f)
+	}
+	if got := f.Items[0]; got.GUID != "tag:github.com,2008:Repository/1/v1.2.0" || got.Title != "v1.2.0" ||
+		got.Link != "https://github.com/harperreed/push/releases/tag/v1.2.0" {
+		t.Errorf("atom entry = %+v", got)
+	}
+
+	f, err = Parse(strings.NewReader(rdf))
+	if err != nil {
+		t.Fatalf("Parse(rdf): %v", err)
+	}
+	if f.Title != "Old School" || len(f.Items) != 1 || f.Items[0].GUID != "https://example.org/a" {
+		t.Errorf("rdf = %+v", f)
+	}
+
+	if _, err := Parse(strings.NewReader("<html><body>nope</body></html>")); err == nil {
+		t.Error("Parse(html) succeeded, want an error")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", `"abc"`)
+		_, _ = w.Write([]byte(rss))
+	}))
+	defer srv.Close()
+
+	res, err := Fetch(t.Context(), srv.Client(), srv.URL, "", "")
+	if err != nil || len(res.Feed.Items) != 2 || res.ETag != `"abc"` {
+		t.Fatalf("Fetch = %+v, %v", res, err)
+	}
+	res, err = Fetch(t.Context(), srv.Client(), srv.URL, `"abc"`, "")
+	if err != nil || !res.NotModified || res.ETag != `"abc"` {
+		t.Errorf("conditional Fetch = %+v, %v", res, err)
+	}
+	if _, err := Fetch(t.Context(), srv.Client(), srv.URL+"/missing", "", ""); err == nil {
+		t.Error("Fetch of a 404 succeeded, want an error")
+	}
+}
+
+func TestCompileFilter(t *testing.T) {
+	re, err := CompileFilter(`(?i)^v\d+\.\d+\.\d+$`)
+	if err != nil || !re.MatchString("V1.2.3") || re.MatchString("v1.2.3-rc1") {
+		t.Errorf("CompileFilter = %v, %v", re, err)
+	}
+	if re, err := CompileFilter(""); re != nil || err != nil {
+		t.Errorf("empty filter = %v, %v; want nil", re, err)
+	}
+	if _, err := CompileFilter("(unclosed"); err == nil {
+		t.Error("bad filter compiled, want an error")
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	f := Feed{Title: "Example Blog", Link: "https://example.com/"}
+	items := []Item{{GUID: "2", Title: "Newer", Link: "https://example.com/2"}, {GUID: "1", Title: "Older", Link: "https://example.com/1"}}
+
+	got := Notifications("blog", f, items)
+	if len(got) != 2 || got[0].Message != "Older" || got[0].URL != "https://example.com/1" || got[1].Title != "blog" {
+		t.Errorf("Notifications = %+v, want one per item, oldest first", got)
+	}
+
+	var burst []Item
+	for i := 12; i > 0; i-- {
+		burst = append(burst, Item{GUID: fmt.Sprint(i), Title: fmt.Sprintf("Post %d", i)})
+	}
+	got = Notifications("blog", f, burst)
+	if len(got) != 1 || got[0].Title != "blog: 12 new items" || got[0].URL != "https://example.com/" {
+		t.Fatalf("burst = %+v, want one summary", got)
+	}
+	lines := strings.Split(got[0].Message, "\n")
+	if len(lines) != 11 || lines[0] != "• Post 1" || lines[10] != "…and 2 more" {
+		t.Errorf("summary message = %q", got[0].Message)
+	}
+}
@@ -0,0 +1,55 @@
+// ABOUTME: Conditional HTTP GET shared by feed and calendar polling.
+// ABOUTME: Sends the last ETag and Last-Modified, handles 304, and caps the body size.
+package feed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Response is the outcome of a conditional GET. NotModified is set when the
+// server answered 304, in which case Body is nil and the validators are the
+// ones sent.
+type Response struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	NotModified  bool
+}
+
+// ConditionalGet requests url with the given User-Agent and Accept headers,
+// made conditional on etag and lastModified from the previous fetch. It
+// reads at most max bytes of the body and fails on a non-2xx status.
+func ConditionalGet(ctx context.Context, client *http.Client, url, userAgent, accept, etag, lastModified string, max int64) (Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Response{}, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", accept)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Response{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return Response{ETag: etag, LastModified: lastModified, NotModified: true}, nil
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return Response{}, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, max))
+	if err != nil {
+		return Response{}, fmt.Errorf("read body: %w", err)
+	}
+	return Response{Body: body, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}, nil
+}
@@ -0,0 +1,200 @@
+// ABOUTME: Matches filesystem events against push fswatch watches and batches them.
+// ABOUTME: Turns a burst of events for one watch into a single notification.
+package fswatch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/harper/push/internal/db"
+)
+
+// DefaultEvents is what a watch reacts to when no events are given.
+const DefaultEvents = "create"
+
+// maxListed caps the files listed in one notification.
+const maxListed = 10
+
+// eventOps maps event names to the fsnotify operations they cover.
+var eventOps = []struct {
+	name string
+	op   fsnotify.Op
+}{
+	{"create", fsnotify.Create},
+	{"modify", fsnotify.Write},
+	{"remove", fsnotify.Remove},
+	{"rename", fsnotify.Rename},
+}
+
+// ParseEvents parses a comma-separated list of event names and returns it in
+// canonical form, e.g. "modify, create" becomes "create,modify".
+func ParseEvents(value string) (string, error) {
+	op, err := parseOps(value)
+	if err != nil {
+		return "", err
+	}
+	var names []string
+	for _, e := range eventOps {
+		if op.Has(e.op) {
+			names = append(names, e.name)
+		}
+	}
+	return strings.Join(names, ","), nil
+}
+
+func parseOps(value string) (fsnotify.Op, error) {
+	var op fsnotify.Op
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		switch item {
+		case "write":
+			item = "modify"
+		case "delete":
+			item = "remove"
+		}
+		found := false
+		for _, e := range eventOps {
+			if e.name == item {
+				op |= e.op
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown event %q; use create, modify, remove, or rename", item)
+		}
+	}
+	return op, nil
+}
+
+// Watch is a saved watch resolved against the filesystem.
+type Watch struct {
+	Name string
+	// Dir is the directory handed to fsnotify.
+	Dir string
+	// File limits events to one entry of Dir when the watch names a file.
+	File     string
+	Pattern  string
+	Ops      fsnotify.Op
+	Priority int
+}
+
+// Resolve works out what to watch for rec. A directory is watched itself; a
+// file, or a path that doesn't exist yet such as a completion marker, is
+// watched through its parent directory, which must exist.
+func Resolve(rec db.FSWatchRecord) (Watch, error) {
+	ops, err := parseOps(rec.Events)
+	if err != nil {
+		return Watch{}, err
+	}
+	w := Watch{Name: rec.Name, Dir: filepath.Clean(rec.Path), Pattern: rec.Pattern, Ops: ops, Priority: rec.Priority}
+	if info, err := os.Stat(w.Dir); err == nil && info.IsDir() {
+		return w, nil
+	}
+	w.Dir, w.File = filepath.Split(w.Dir)
+	w.Dir = filepath.Clean(w.Dir)
+	if info, err := os.Stat(w.Dir); err != nil || !info.IsDir() {
+		return Watch{}, fmt.Errorf("neither %s nor its parent directory exists", rec.Path)
+	}
+	return w, nil
+}
+
+// Matches reports whether ev is one w reacts to.
+func (w Watch) Matches(ev fsnotify.Event) bool {
+	if ev.Op&w.Ops == 0 {
+		return false
+	}
+	dir, base := filepath.Split(filepath.Clean(ev.Name))
+	if filepath.Clean(dir) != w.Dir || (w.File != "" && base != w.File) {
+		return false
+	}
+	if w.Pattern == "" {
+		return true
+	}
+	ok, err := filepath.Match(w.Pattern, base)
+	return err == nil && ok
+}
+
+// Batch collects the events for one watch until they settle.
+type Batch struct {
+	files map[string]fsnotify.Op
+	order []string
+	// Last is when the most recent event arrived.
+	Last time.Time
+}
+
+// Add records ev as seen at now.
+func (b *Batch) Add(ev fsnotify.Event, ops fsnotify.Op, now time.Time) {
+	if b.files == nil {
+		b.files = map[string]fsnotify.Op{}
+	}
+	if _, seen := b.files[ev.Name]; !seen {
+		b.order = append(b.order, ev.Name)
+	}
+	b.files[ev.Name] |= ev.Op & ops
+	b.Last = now
+}
+
+// Len returns how many files the batch has seen.
+func (b *Batch) Len() int {
+	return len(b.order)
+}
+
+// LastFile returns the file the batch saw most recently, or "".
+func (b *Batch) LastFile() string {
+	if len(b.order) == 0 {
+		return ""
+	}
+	return b.order[len(b.order)-1]
+}
+
+// Notification returns the title and message describing the batch for w.
+func (b *Batch) Notification(w Watch) (string, string) {
+	if len(b.order) == 1 {
+		name := b.order[0]
+		return fmt.Sprintf("%s: %s %s", w.Name, filepath.Base(name), describe(b.files[name])), name
+	}
+
+	verb := describe(b.files[b.order[0]])
+	for _, name := range b.order {
+		if describe(b.files[name]) != verb {
+			verb = "changed"
+			break
+		}
+	}
+	lines := make([]string, 0, min(len(b.order), maxListed)+1)
+	for i, name := range b.order {
+		if i == maxListed {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(b.order)-maxListed))
+			break
+		}
+		line := "• " + filepath.Base(name)
+		if verb == "changed" {
+			line += " (" + describe(b.files[name]) + ")"
+		}
+		lines = append(lines, line)
+	}
+	return fmt.Sprintf("%s: %d files %s", w.Name, len(b.order), verb), strings.Join(lines, "\n")
+}
+
+// describe names what happened to a file. A file that was created and then
+// written is "created", and one that is gone by the end is "removed".
+func describe(op fsnotify.Op) string {
+	for _, e := range []struct {
+		op   fsnotify.Op
+		verb string
+	}{
+		{fsnotify.Remove, "removed"},
+		{fsnotify.Rename, "renamed"},
+		{fsnotify.Create, "created"},
+		{fsnotify.Write, "modified"},
+	} {
+		if op.Has(e.op) {
+			return e.verb
+		}
+	}
+	return "changed"
+}
@@ -0,0 +1,144 @@
+// ABOUTME: Tests for fswatch event parsing, matching, and batching.
+// ABOUTME: Uses temporary directories and synthetic fsnotify events.
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/harper/push/internal/db"
+)
+
+func TestParseEvents(t *testing.T) {
+	cases := map[string]string{
+		"create":               "create",
+		"modify, create":       "create,modify",
+		"WRITE,delete":         "modify,remove",
+		"rename,create,remove": "create,remove,rename",
+	}
+	for in, want := range cases {
+		got, err := ParseEvents(in)
+		if err != nil {
+			t.Fatalf("ParseEvents(%q): %v", in, err)
+		}
+		if got != want {
+			t.Errorf("ParseEvents(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for _, bad := range []string{"", "chmod", "create,"} {
+		if _, err := ParseEvents(bad); err == nil {
+			t.Errorf("ParseEvents(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "backup.done")
+
+	w, err := Resolve(db.FSWatchRecord{Name: "drop", Path: dir, Events: "create"})
+	if err != nil {
+		t.Fatalf("Resolve dir: %v", err)
+	}
+	if w.Dir != dir || w.File != "" {
+		t.Errorf("dir watch = %+v", w)
+	}
+
+	// A marker that doesn't exist yet is watched through its parent.
+	w, err = Resolve(db.FSWatchRecord{Name: "done", Path: file, Events: "create"})
+	if err != nil {
+		t.Fatalf("Resolve missing file: %v", err)
+	}
+	if w.Dir != dir || w.File != "backup.done" {
+		t.Errorf("file watch = %+v", w)
+	}
+
+	if _, err := Resolve(db.FSWatchRecord{Name: "gone", Path: filepath.Join(dir, "nope", "x"), Events: "create"}); err == nil {
+		t.Error("Resolve with missing parent succeeded, want error")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	dir := t.TempDir()
+	w := Watch{Name: "logs", Dir: dir, Pattern: "*.log", Ops: fsnotify.Create | fsnotify.Write}
+
+	cases := []struct {
+		ev   fsnotify.Event
+		want bool
+	}{
+		{fsnotify.Event{Name: filepath.Join(dir, "app.log"), Op: fsnotify.Create}, true},
+		{fsnotify.Event{Name: filepath.Join(dir, "app.log"), Op: fsnotify.Write}, true},
+		{fsnotify.Event{Name: filepath.Join(dir, "app.log"), Op: fsnotify.Remove}, false},
+		{fsnotify.Event{Name: filepath.Join(dir, "app.txt"), Op: fsnotify.Create}, false},
+		{fsnotify.Event{Name: filepath.Join(dir, "sub", "app.log"), Op: fsnotify.Create}, false},
+	}
+	for _, c := range cases {
+		if got := w.Matches(c.ev); got != c.want {
+			t.Errorf("Matches(%v) = %v, want %v", c.ev, got, c.want)
+		}
+	}
+
+	marker := Watch{Name: "done", Dir: dir, File: "DONE", Ops: fsnotify.Create}
+	if !marker.Matches(fsnotify.Event{Name: filepath.Join(dir, "DONE"), Op: fsnotify.Create}) {
+		t.Error("marker watch missed its file")
+	}
+	if marker.Matches(fsnotify.Event{Name: filepath.Join(dir, "OTHER"), Op: fsnotify.Create}) {
+		t.Error("marker watch matched another file")
+	}
+}
+
+func TestBatchNotification(t *testing.T) {
+	dir := t.TempDir()
+	w := Watch{Name: "drop", Dir: dir, Ops: fsnotify.Create | fsnotify.Write | fsnotify.Remove}
+	now := time.Now()
+
+	var one Batch
+	one.Add(fsnotify.Event{Name: filepath.Join(dir, "a.csv"), Op: fsnotify.Create}, w.Ops, now)
+	one.Add(fsnotify.Event{Name: filepath.Join(dir, "a.csv"), Op: fsnotify.Write}, w.Ops, now)
+	title, message := one.Notification(w)
+	if title != "drop: a.csv created" || message != filepath.Join(dir, "a.csv") {
+		t.Errorf("single file = %q / %q", title, message)
+	}
+
+	var many Batch
+	for i := range 12 {
+		many.Add(fsnotify.Event{Name: filepath.Join(dir, string(rune('a'+i))+".csv"), Op: fsnotify.Create}, w.Ops, now)
+	}
+	title, message = many.Notification(w)
+	if title != "drop: 12 files created" {
+		t.Errorf("title = %q", title)
+	}
+	if !strings.Contains(message, "• a.csv") || !strings.HasSuffix(message, "…and 2 more") {
+		t.Errorf("message = %q", message)
+	}
+	if many.LastFile() != filepath.Join(dir, "l.csv") {
+		t.Errorf("LastFile = %q", many.LastFile())
+	}
+
+	var mixed Batch
+	mixed.Add(fsnotify.Event{Name: filepath.Join(dir, "a.csv"), Op: fsnotify.Create}, w.Ops, now)
+	mixed.Add(fsnotify.Event{Name: filepath.Join(dir, "b.csv"), Op: fsnotify.Remove}, w.Ops, now)
+	title, message = mixed.Notification(w)
+	if title != "drop: 2 files changed" || message != "• a.csv (created)\n• b.csv (removed)" {
+		t.Errorf("mixed = %q / %q", title, message)
+	}
+}
+
+func TestResolveFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w, err := Resolve(db.FSWatchRecord{Name: "log", Path: file, Events: "modify"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if w.Dir != dir || w.File != "app.log" || w.Ops != fsnotify.Write {
+		t.Errorf("watch = %+v", w)
+	}
+}
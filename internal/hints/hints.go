@@ -0,0 +1,67 @@
+// ABOUTME: Friendly explanations and next steps for common failures.
+// ABOUTME: Shared by the CLI, which prints them after errors, and MCP tool errors.
+package hints
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+
+	"github.com/harper/push/pkg/pushover"
+)
+
+// For returns what went wrong and how to fix it for a recognised error, or ""
+// when there is nothing useful to add.
+func For(err error) string {
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return ""
+	case errors.Is(err, pushover.ErrInvalidToken):
+		return "Pushover rejected the application token. Check app_token (or the [apps] entry picked with --app) against https://pushover.net/apps, or run `push login` to set it again."
+	case errors.Is(err, pushover.ErrInvalidUser):
+		return "Pushover doesn't recognise the user key. Copy \"Your User Key\" from https://pushover.net into user_key, or run `push login`."
+	case errors.Is(err, pushover.ErrDeviceInvalid):
+		return "The device name or secret isn't valid. Check the name against your devices at https://pushover.net (or `push send --device <Tab>`); if this machine's registration was removed, run `push login --refresh`."
+	case errors.Is(err, pushover.ErrRateLimited):
+		return "This application has used its monthly message quota. Wait for the reset, or send through another application with --app."
+	case errors.Is(err, pushover.ErrMessageTooLong):
+		return "Pushover messages are limited to 1024 characters. Send with --split or --truncate, or set long_message_mode in the config."
+	case errors.Is(err, pushover.ErrTwoFactorRequired):
+		return "This account uses two-factor authentication and the code was missing or wrong. Run `push login` again with the current code, or retry a pending non-interactive login with `push login --continue --code <code>`."
+	}
+
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return "The API's TLS certificate isn't trusted. Behind an intercepting proxy, point ca_cert_path at your organisation's CA bundle."
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return "Couldn't reach the Pushover API. Check your connection, proxy_url or HTTPS_PROXY, and api_url or PUSH_API_URL."
+	}
+	return ""
+}
+
+// hinted is an error carrying its hint on a second line.
+type hinted struct {
+	err  error
+	hint string
+}
+
+func (h *hinted) Error() string {
+	return h.err.Error() + "\nHint: " + h.hint
+}
+
+func (h *hinted) Unwrap() error {
+	return h.err
+}
+
+// Wrap appends the hint for err to its message, for callers such as MCP
+// clients that only see the error text. Errors without a hint pass through.
+func Wrap(err error) error {
+	hint := For(err)
+	if hint == "" {
+		return err
+	}
+	return &hinted{err: err, hint: hint}
+}
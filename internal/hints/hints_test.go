@@ -0,0 +1,64 @@
+// ABOUTME: Tests for error hints.
+// ABOUTME: Checks classified API errors get hints and wrapping keeps errors.Is working.
+package hints
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/harper/push/pkg/pushover"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+)
+
+func TestFor(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	client := srv.Client()
+	client.AppToken = "wrong"
+	_, err := client.Send(ctx, pushover.SendParams{Message: "hi"})
+	if hint := For(err); !strings.Contains(hint, "pushover.net/apps") {
+		t.Errorf("For(invalid token) = %q, want a pointer to the apps page", hint)
+	}
+
+	client = srv.Client()
+	client.UserKey = "wrong"
+	_, err = client.Send(ctx, pushover.SendParams{Message: "hi"})
+	if hint := For(fmt.Errorf("send: %w", err)); !strings.Contains(hint, "user key") {
+		t.Errorf("For(wrapped invalid user) = %q, want a user key hint", hint)
+	}
+
+	refused := &url.Error{Op: "Post", URL: srv.URL(), Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+	if hint := For(fmt.Errorf("send: %w", refused)); !strings.Contains(hint, "Couldn't reach") {
+		t.Errorf("For(connection refused) = %q, want a connectivity hint", hint)
+	}
+	if hint := For(fmt.Errorf("send: %w", context.Canceled)); hint != "" {
+		t.Errorf("For(canceled) = %q, want none", hint)
+	}
+
+	if hint := For(errors.New("something else")); hint != "" {
+		t.Errorf("For(unrecognised) = %q, want none", hint)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	err := Wrap(fmt.Errorf("send: %w", pushover.ErrMessageTooLong))
+	if !errors.Is(err, pushover.ErrMessageTooLong) {
+		t.Error("Wrap() lost the underlying error")
+	}
+	if !strings.Contains(err.Error(), "\nHint: ") {
+		t.Errorf("Wrap() = %q, want a hint line", err)
+	}
+
+	plain := errors.New("plain")
+	if Wrap(plain) != plain {
+		t.Error("Wrap() changed an error without a hint")
+	}
+}
@@ -0,0 +1,62 @@
+// ABOUTME: Runs user commands for received messages.
+// ABOUTME: Passes the message to the command in PUSH_* environment variables.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/harper/push/pkg/pushover"
+)
+
+// Env returns the PUSH_* variables describing msg as received on device.
+func Env(device string, msg pushover.ReceivedMessage) []string {
+	return []string{
+		"PUSH_ID=" + strconv.FormatInt(msg.PushoverID, 10),
+		"PUSH_MESSAGE=" + msg.Message,
+		"PUSH_TITLE=" + msg.Title,
+		"PUSH_APP=" + msg.App,
+		"PUSH_PRIORITY=" + strconv.Itoa(msg.Priority),
+		"PUSH_URL=" + msg.URL,
+		"PUSH_DEVICE=" + device,
+		"PUSH_DATE=" + time.Unix(msg.Date, 0).Format(time.RFC3339),
+	}
+}
+
+// DefaultTimeout is how long a hook may run when the caller doesn't say.
+const DefaultTimeout = time.Minute
+
+// waitDelay bounds how long Run waits for the hook's output after stopping
+// it, in case something it started still holds stdout or stderr open.
+const waitDelay = 2 * time.Second
+
+// Run executes command through the shell (sh -c, or cmd /C on Windows) with
+// the current environment plus Env. The command's output goes to stdout and
+// stderr; a non-zero exit is returned as an error. A command still running
+// after timeout (DefaultTimeout if zero) is killed along with anything it
+// started, so a hung hook can't hold up the messages after it.
+func Run(ctx context.Context, command, device string, msg pushover.ReceivedMessage, timeout time.Duration, stdout, stderr io.Writer) error {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), Env(device, msg)...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = waitDelay
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("run hook for message %d: timed out after %s", msg.PushoverID, timeout)
+		}
+		return fmt.Errorf("run hook for message %d: %w", msg.PushoverID, err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// ABOUTME: Tests for message hooks.
+// ABOUTME: Runs a shell command, checks the PUSH_* variables it sees, and times it out.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/push/pkg/pushover"
+)
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	msg := pushover.ReceivedMessage{PushoverID: 7, Message: "door opened", Title: "Front door", App: "Home", Priority: 1, URL: "https://example.com"}
+
+	var stdout, stderr bytes.Buffer
+	command := `printf '%s|%s|%s|%s|%s|%s|%s' "$PUSH_ID" "$PUSH_MESSAGE" "$PUSH_TITLE" "$PUSH_APP" "$PUSH_PRIORITY" "$PUSH_URL" "$PUSH_DEVICE"`
+	if err := Run(context.Background(), command, "phone", msg, 0, &stdout, &stderr); err != nil {
+		t.Fatalf("Run() error: %v (stderr %q)", err, stderr.String())
+	}
+	want := "7|door opened|Front door|Home|1|https://example.com|phone"
+	if stdout.String() != want {
+		t.Errorf("hook saw %q, want %q", stdout.String(), want)
+	}
+
+	if err := Run(context.Background(), "exit 3", "phone", msg, 0, &stdout, &stderr); err == nil {
+		t.Error("Run() with failing command succeeded, want error")
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	msg := pushover.ReceivedMessage{PushoverID: 9}
+
+	// The sleep is a child of the shell holding stdout open; the hook must
+	// still stop at its deadline rather than wait for it.
+	var stdout, stderr bytes.Buffer
+	start := time.Now()
+	err := Run(context.Background(), "sleep 30; echo late", "phone", msg, 100*time.Millisecond, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Fatalf("Run() = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run() took %s to give up on a hung hook", elapsed)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("timed-out hook printed %q", stdout.String())
+	}
+}
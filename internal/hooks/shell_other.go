@@ -0,0 +1,19 @@
+// ABOUTME: Starts hooks through the platform shell where there are no process groups.
+// ABOUTME: On Windows that is cmd /C; stopping a hook kills the shell.
+//go:build !unix
+
+package hooks
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+)
+
+// shellCommand runs command with cmd /C on Windows and sh -c elsewhere.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,22 @@
+// ABOUTME: Starts hooks through sh in their own process group.
+// ABOUTME: Stopping a hook kills the whole group, not just the shell.
+//go:build unix
+
+package hooks
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// shellCommand runs command with sh -c. When ctx ends the hook's process
+// group is killed, so commands the shell started don't outlive it.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	return cmd
+}
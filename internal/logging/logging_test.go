@@ -0,0 +1,67 @@
+// ABOUTME: Tests for structured logging setup.
+// ABOUTME: Verifies level parsing, format selection, and level filtering.
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"":        slog.LevelInfo,
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warn":    slog.LevelWarn,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+	}
+	for input, want := range tests {
+		got, err := ParseLevel(input)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("ParseLevel(loud) succeeded, want error")
+	}
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, slog.LevelWarn, FormatJSON)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	logger.Info("hidden")
+	logger.Warn("unable to ack messages", "device", "laptop")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1: %q", len(lines), buf.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("line is not JSON: %v", err)
+	}
+	if record["level"] != "WARN" || record["msg"] != "unable to ack messages" || record["device"] != "laptop" {
+		t.Errorf("record = %v", record)
+	}
+
+	buf.Reset()
+	logger, err = New(&buf, slog.LevelInfo, FormatText)
+	if err != nil {
+		t.Fatalf("New(text) error: %v", err)
+	}
+	logger.Info("starting daemon", "jobs", 1)
+	if !strings.Contains(buf.String(), `msg="starting daemon" jobs=1`) {
+		t.Errorf("text output = %q", buf.String())
+	}
+
+	if _, err := New(&buf, slog.LevelInfo, "xml"); err == nil {
+		t.Error("New(xml) succeeded, want error")
+	}
+}
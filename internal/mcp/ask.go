@@ -0,0 +1,156 @@
+// ABOUTME: MCP tool that asks the user a question on their phone and waits.
+// ABOUTME: Each option is an emergency notification; acknowledging one answers.
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/harper/push/internal/messages"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const toolAskHuman = "ask_human"
+
+type AskHumanInput struct {
+	Question string   `json:"question"`
+	Options  []string `json:"options,omitempty"`
+	Timeout  string   `json:"timeout,omitempty"`
+	Title    string   `json:"title,omitempty"`
+	Device   string   `json:"device,omitempty"`
+	App      string   `json:"app,omitempty"`
+}
+
+type AskHumanOutput struct {
+	// Status is "answered" or "timeout".
+	Status     string    `json:"status"`
+	Answer     string    `json:"answer,omitempty"`
+	Index      *int      `json:"option_index,omitempty"`
+	AnsweredAt time.Time `json:"answered_at,omitzero"`
+	Device     string    `json:"answered_on_device,omitempty"`
+	Options    []string  `json:"options"`
+	Receipts   []string  `json:"receipts"`
+}
+
+func (s *Server) registerAskHumanTool() {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"question": map[string]any{
+				"type":        "string",
+				"description": "Question to put to the user",
+			},
+			"options": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"maxItems":    messages.MaxAskOptions,
+				"description": "Possible answers, each sent as its own notification. Defaults to [\"Yes\", \"No\"].",
+			},
+			"timeout": map[string]any{
+				"type":        "string",
+				"description": "How long to wait for an answer, from 30s to 3h (default 5m). Many MCP clients give up on tool calls sooner.",
+			},
+			"title": map[string]any{
+				"type":        "string",
+				"description": "Short title shown before each option (default \"Question\")",
+			},
+			"device": map[string]any{
+				"type":        "string",
+				"description": "Target device name. Defaults to config's default_device.",
+			},
+			"app": map[string]any{
+				"type":        "string",
+				"description": "[apps] entry to send with. Defaults to app_token.",
+			},
+		},
+		"required": []string{"question"},
+	}
+
+	addTool(s, &mcp.Tool{
+		Name:        toolAskHuman,
+		Description: "Ask the user a question on their phone and wait for the answer before proceeding. Each option arrives as an emergency notification that repeats until one is acknowledged; acknowledging it picks that option. Returns status \"timeout\" when nobody answers in time.",
+		InputSchema: schema,
+	}, s.handleAskHuman)
+}
+
+func (s *Server) handleAskHuman(ctx context.Context, req *mcp.CallToolRequest, input AskHumanInput) (*mcp.CallToolResult, AskHumanOutput, error) {
+	cfg := s.config()
+	if err := cfg.ValidateSend(); err != nil {
+		return nil, AskHumanOutput{}, err
+	}
+	client := s.newClient()
+	if input.App != "" {
+		appCfg, err := cfg.ForApp(input.App)
+		if err != nil {
+			return nil, AskHumanOutput{}, err
+		}
+		client.AppToken = appCfg.AppToken
+	}
+	title, err := cfg.ApplyTitleTemplate(input.Title)
+	if err != nil {
+		return nil, AskHumanOutput{}, err
+	}
+
+	question := messages.Question{
+		Text:      input.Question,
+		Title:     title,
+		Options:   input.Options,
+		Device:    input.Device,
+		App:       input.App,
+		PollEvery: s.askPoll,
+	}
+	if question.Device == "" {
+		question.Device = cfg.DefaultDevice
+	}
+	if input.Timeout != "" {
+		if question.Timeout, err = time.ParseDuration(input.Timeout); err != nil {
+			return nil, AskHumanOutput{}, fmt.Errorf("timeout must be a duration such as \"10m\"")
+		}
+	}
+	if err := question.Normalize(); err != nil {
+		return nil, AskHumanOutput{}, err
+	}
+	wait, err := cfg.RateLimitWaits()
+	if err != nil {
+		return nil, AskHumanOutput{}, err
+	}
+	// Each option is sent as its own message.
+	if err := s.limiter.tooMany(len(question.Options)); err != nil {
+		return nil, AskHumanOutput{}, fmt.Errorf("too many options: %w", err)
+	}
+	if scope, retry, ok := s.limiter.reserve(sessionOf(req), len(question.Options), time.Now()); !ok {
+		return nil, AskHumanOutput{}, fmt.Errorf("rate limited by the %s send limit; retry in %d seconds", scope, int(math.Ceil(retry.Seconds())))
+	}
+	beforeSend := func(ctx context.Context) error {
+		err := messages.AcquireSendSlot(ctx, s.store.Store, cfg.RateLimit, wait, nil)
+		var budget *messages.BudgetError
+		if errors.As(err, &budget) {
+			return fmt.Errorf("rate limited by the %s send limit; retry in %d seconds", limitScopeShared, int(math.Ceil(budget.RetryAfter.Seconds())))
+		}
+		return err
+	}
+
+	s.report(ctx, sessionOf(req), mcp.LevelInfo, "asking the user", "question", input.Question)
+	answer, err := messages.Ask(ctx, s.store.Store, client, question, beforeSend)
+	if err != nil {
+		return nil, AskHumanOutput{}, err
+	}
+
+	output := AskHumanOutput{Status: "timeout", Options: question.Options, Receipts: answer.Receipts}
+	if answer.Answered {
+		index := answer.Index
+		output.Status = "answered"
+		output.Answer = answer.Option
+		output.Index = &index
+		output.AnsweredAt = answer.AnsweredAt
+		output.Device = answer.Device
+	}
+	result, err := buildToolResult(output)
+	if err != nil {
+		return nil, output, err
+	}
+	return result, output, nil
+}
@@ -0,0 +1,59 @@
+// ABOUTME: Tests for the ask_human tool.
+// ABOUTME: Answers a question by acknowledging one option on the mock API.
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+)
+
+func TestAskHuman(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	dbPath := filepath.Join(t.TempDir(), "push.db")
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	server, err := NewServer(&config.Config{AppToken: pushovertest.AppToken, UserKey: pushovertest.UserKey, APIURL: srv.URL()}, "", store, dbPath)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	server.askPoll = 10 * time.Millisecond
+
+	if _, _, err := server.handleAskHuman(ctx, nil, AskHumanInput{Question: "Merge?", Timeout: "1s"}); err == nil {
+		t.Error("ask_human accepted a timeout under 30s")
+	}
+
+	go func() {
+		for range 200 {
+			sent, _ := store.ListSent(ctx, db.SentFilter{ReceiptsOnly: true})
+			if len(sent) == 2 {
+				for _, rec := range sent {
+					if rec.Title == "Question: Yes" {
+						srv.AcknowledgeSent(rec.Receipt, "phone")
+					}
+				}
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	_, out, err := server.handleAskHuman(ctx, nil, AskHumanInput{Question: "Merge the release branch?", Timeout: "1m"})
+	if err != nil {
+		t.Fatalf("ask_human: %v", err)
+	}
+	if out.Status != "answered" || out.Answer != "Yes" || out.Index == nil || *out.Index != 0 || out.Device != "phone" || len(out.Receipts) != 2 {
+		t.Errorf("ask_human = %+v, want Yes answered on phone", out)
+	}
+}
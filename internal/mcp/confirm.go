@@ -0,0 +1,75 @@
+// ABOUTME: Human confirmation gate for high-priority MCP sends.
+// ABOUTME: Uses MCP elicitation when available, otherwise requires a confirm: true retry.
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/harper/push/internal/messages"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// confirmHighPriority blocks sends at or above the configured priority until a
+// human approves them. Clients that support elicitation are asked directly;
+// others are refused and must retry with confirm: true after checking with
+// their user. The retry only counts in the session that saw the refusal, and
+// calls outside a client session are always refused.
+func (s *Server) confirmHighPriority(ctx context.Context, req *mcp.CallToolRequest, input SendNotificationInput, priority int) error {
+	threshold := s.config().ConfirmationPriority()
+	if priority < threshold {
+		return nil
+	}
+
+	state := s.sessionFor(sessionOf(req))
+	key := fmt.Sprintf("%d:%s", priority, messages.ContentHash(input.Message, input.Title))
+	now := time.Now()
+	if input.Confirm && state != nil && state.takeConfirmation(key, now) {
+		return nil
+	}
+
+	if !supportsElicitation(req) {
+		if state != nil {
+			state.requestConfirmation(key, now)
+		}
+		return fmt.Errorf("priority %d notifications require human confirmation: ask the user, then retry with confirm: true", priority)
+	}
+
+	body := input.Message
+	if input.Title != "" {
+		body = input.Title + "\n" + body
+	}
+	result, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+		Message: fmt.Sprintf("An assistant wants to send a priority %d notification:\n\n%s\n\nSend it?", priority, body),
+		RequestedSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"confirm": map[string]any{
+					"type":        "boolean",
+					"title":       "Send notification",
+					"description": "Confirm sending this high-priority notification.",
+				},
+			},
+			"required": []string{"confirm"},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("requesting confirmation: %w", err)
+	}
+	if result.Action != "accept" {
+		return fmt.Errorf("priority %d notification not sent: user chose %s", priority, result.Action)
+	}
+	if confirmed, _ := result.Content["confirm"].(bool); !confirmed {
+		return fmt.Errorf("priority %d notification not sent: user did not confirm", priority)
+	}
+	return nil
+}
+
+func supportsElicitation(req *mcp.CallToolRequest) bool {
+	if req == nil || req.Session == nil {
+		return false
+	}
+	params := req.Session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Elicitation != nil
+}
@@ -0,0 +1,173 @@
+// ABOUTME: Tests for the human confirmation gate on high-priority MCP sends.
+// ABOUTME: Covers the priority threshold, confirm: true retries, calls without a session, and elicitation.
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func newConfirmServer(t *testing.T, apiURL string, threshold *int) *Server {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "push.db")
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	server, err := NewServer(&config.Config{
+		AppToken: pushovertest.AppToken,
+		UserKey:  pushovertest.UserKey,
+		APIURL:   apiURL,
+		MCP:      config.MCPConfig{RequireConfirmationPriority: threshold},
+	}, "", store, dbPath)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	return server
+}
+
+// connectConfirmClient connects a client to server, answering elicitation
+// requests with elicit when it's non-nil.
+func connectConfirmClient(t *testing.T, server *Server, elicit func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error)) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.mcp.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, &mcp.ClientOptions{ElicitationHandler: elicit})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func callSend(t *testing.T, session *mcp.ClientSession, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "send_notification", Arguments: args})
+	if err != nil {
+		t.Fatalf("send_notification: %v", err)
+	}
+	return result
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	if len(result.Content) == 0 {
+		return ""
+	}
+	text, _ := result.Content[0].(*mcp.TextContent)
+	if text == nil {
+		return ""
+	}
+	return text.Text
+}
+
+func TestConfirmHighPriorityThreshold(t *testing.T) {
+	ctx := context.Background()
+	threshold := 1
+	server := newConfirmServer(t, "", &threshold)
+
+	if err := server.confirmHighPriority(ctx, nil, SendNotificationInput{Message: "fyi"}, 0); err != nil {
+		t.Errorf("priority 0 under threshold 1: %v", err)
+	}
+	if err := server.confirmHighPriority(ctx, nil, SendNotificationInput{Message: "urgent"}, 1); err == nil {
+		t.Error("priority 1 at threshold 1 was not refused")
+	}
+
+	disabled := 3
+	server = newConfirmServer(t, "", &disabled)
+	if err := server.confirmHighPriority(ctx, nil, SendNotificationInput{Message: "fire"}, 2); err != nil {
+		t.Errorf("priority 2 with confirmation disabled: %v", err)
+	}
+}
+
+func TestConfirmWithoutSession(t *testing.T) {
+	ctx := context.Background()
+	server := newConfirmServer(t, "", nil)
+
+	input := SendNotificationInput{Message: "datacenter on fire", Confirm: true}
+	for range 2 {
+		if err := server.confirmHighPriority(ctx, nil, input, 2); err == nil {
+			t.Fatal("confirm: true without a session was accepted")
+		}
+	}
+}
+
+func TestConfirmRetry(t *testing.T) {
+	api := pushovertest.NewServer()
+	defer api.Close()
+	server := newConfirmServer(t, api.URL(), nil)
+	session := connectConfirmClient(t, server, nil)
+
+	// confirm: true only counts as a retry of a refused send.
+	confirmed := map[string]any{"message": "datacenter on fire", "priority": 2, "confirm": true}
+	if result := callSend(t, session, confirmed); !result.IsError {
+		t.Fatal("confirm: true before any refusal was accepted")
+	}
+	result := callSend(t, session, map[string]any{"message": "datacenter on fire", "priority": 2})
+	if !result.IsError || !strings.Contains(resultText(result), "confirmation") {
+		t.Fatalf("unconfirmed emergency = %q, want confirmation error", resultText(result))
+	}
+	if result := callSend(t, session, map[string]any{"message": "something else", "priority": 2, "confirm": true}); !result.IsError {
+		t.Error("confirm: true for a different message was accepted")
+	}
+	if result := callSend(t, session, confirmed); result.IsError {
+		t.Fatalf("confirmed retry failed: %s", resultText(result))
+	}
+	if result := callSend(t, session, confirmed); !result.IsError {
+		t.Error("one confirmation covered two sends")
+	}
+	if sent := len(api.Sent()); sent != 1 {
+		t.Errorf("API received %d sends, want 1", sent)
+	}
+}
+
+func TestConfirmElicitation(t *testing.T) {
+	api := pushovertest.NewServer()
+	defer api.Close()
+	server := newConfirmServer(t, api.URL(), nil)
+
+	var answer *mcp.ElicitResult
+	asked := 0
+	session := connectConfirmClient(t, server, func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+		asked++
+		return answer, nil
+	})
+
+	// confirm: true doesn't skip asking a client that can elicit.
+	answer = &mcp.ElicitResult{Action: "decline"}
+	result := callSend(t, session, map[string]any{"message": "datacenter on fire", "priority": 2, "confirm": true})
+	if !result.IsError || !strings.Contains(resultText(result), "decline") {
+		t.Fatalf("declined emergency = %q, want decline error", resultText(result))
+	}
+
+	answer = &mcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": false}}
+	if result := callSend(t, session, map[string]any{"message": "datacenter on fire", "priority": 2}); !result.IsError {
+		t.Error("send went out with confirm: false")
+	}
+
+	answer = &mcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": true}}
+	if result := callSend(t, session, map[string]any{"message": "datacenter on fire", "priority": 2}); result.IsError {
+		t.Fatalf("accepted emergency failed: %s", resultText(result))
+	}
+	if asked != 3 {
+		t.Errorf("elicited %d times, want 3", asked)
+	}
+	if sent := len(api.Sent()); sent != 1 {
+		t.Errorf("API received %d sends, want 1", sent)
+	}
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Tests for the list_history tool.
+// ABOUTME: Runs against an in-memory store and checks empty results, archived and tagged messages, and body cuts.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestListHistory(t *testing.T) {
+	ctx := context.Background()
+	store, err := db.Open(db.Memory)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	server, err := NewServer(&config.Config{}, "", store, "")
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.mcp.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	list := func(args map[string]any) []int64 {
+		t.Helper()
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: toolListHistory, Arguments: args})
+		if err != nil || result.IsError {
+			t.Fatalf("list_history(%v) = %v, %v", args, result, err)
+		}
+		var out ListHistoryOutput
+		if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out); err != nil {
+			t.Fatal(err)
+		}
+		ids := []int64{}
+		for _, m := range out.Messages {
+			ids = append(ids, m.PushoverID)
+		}
+		return ids
+	}
+
+	if got := list(map[string]any{}); len(got) != 0 {
+		t.Errorf("empty history = %v, want none", got)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	if _, err := store.PersistMessages(ctx, []db.MessageRecord{
+		{PushoverID: 1, Message: "Backup done", ReceivedAt: now.Add(-time.Hour), SentAt: &now},
+		{PushoverID: 2, Message: "Disk full", ReceivedAt: now, SentAt: &now},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.SetArchived(ctx, 1, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := list(map[string]any{}); len(got) != 1 || got[0] != 2 {
+		t.Errorf("history = %v, want only [2] with the archived message left out", got)
+	}
+	if got := list(map[string]any{"archived": true}); len(got) != 2 {
+		t.Errorf("history with archived = %v, want both messages", got)
+	}
+
+	ids, err := store.MessageIDs(ctx, db.MessageFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.UpdateMessages(ctx, ids[:1], db.MessageUpdate{AddTag: "disk"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := list(map[string]any{"archived": true}); len(got) != 2 {
+		t.Errorf("history with a tagged message = %v, want both messages", got)
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: toolListHistory, Arguments: map[string]any{"max_body_chars": 4}})
+	if err != nil || result.IsError {
+		t.Fatalf("list_history max_body_chars = %v, %v", result, err)
+	}
+	var out ListHistoryOutput
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Messages) != 1 || out.Messages[0].Message != "Disk… [5 more characters trimmed]" || !out.Messages[0].BodyTruncated {
+		t.Errorf("list_history max_body_chars = %+v, want the body cut and flagged", out.Messages)
+	}
+}
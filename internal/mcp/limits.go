@@ -0,0 +1,169 @@
+// ABOUTME: Monthly Pushover quota exposed as the check_limits tool and push://limits.
+// ABOUTME: Reuses quota headers from recent sends and probes the API otherwise.
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/harper/push/pkg/pushover"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// limitsMaxAge is how long quota seen on a send response is reused before
+// check_limits asks Pushover again.
+const limitsMaxAge = time.Minute
+
+// lowQuotaFraction marks quota as low when at most this share remains.
+const lowQuotaFraction = 0.1
+
+// Sources for reported quota.
+const (
+	limitsFromSend  = "send"
+	limitsFromProbe = "probe"
+)
+
+// quotaCache remembers the last quota seen per app token.
+type quotaCache struct {
+	mu     sync.Mutex
+	byApp  map[string]quotaSnapshot
+	maxAge time.Duration
+}
+
+type quotaSnapshot struct {
+	limits pushover.AppLimits
+	source string
+	seen   time.Time
+}
+
+func newQuotaCache() *quotaCache {
+	return &quotaCache{byApp: make(map[string]quotaSnapshot), maxAge: limitsMaxAge}
+}
+
+func (q *quotaCache) record(token string, limits *pushover.AppLimits, source string, now time.Time) {
+	if limits == nil || token == "" {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.byApp[token] = quotaSnapshot{limits: *limits, source: source, seen: now}
+}
+
+func (q *quotaCache) fresh(token string, now time.Time) (quotaSnapshot, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	snap, ok := q.byApp[token]
+	return snap, ok && now.Sub(snap.seen) < q.maxAge
+}
+
+type CheckLimitsInput struct {
+	App     string `json:"app,omitempty"`
+	Refresh bool   `json:"refresh,omitempty"`
+}
+
+type CheckLimitsOutput struct {
+	App       string    `json:"app,omitempty"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Used      int       `json:"used"`
+	Reset     time.Time `json:"reset"`
+	Low       bool      `json:"low"`
+	Source    string    `json:"source"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+func (s *Server) registerCheckLimitsTool() {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"app": map[string]any{
+				"type":        "string",
+				"description": "[apps] entry whose quota to check. Defaults to the main app token.",
+			},
+			"refresh": map[string]any{
+				"type":        "boolean",
+				"description": "Ask Pushover even if a send reported the quota in the last minute.",
+			},
+		},
+	}
+
+	addTool(s, &mcp.Tool{
+		Name:        toolCheckLimits,
+		Description: "Report the Pushover application's monthly message quota: limit, remaining, and reset time. low is true at 10% remaining or less; batch or skip low-value notifications then.",
+		InputSchema: schema,
+	}, s.handleCheckLimits)
+}
+
+func (s *Server) handleCheckLimits(ctx context.Context, _ *mcp.CallToolRequest, input CheckLimitsInput) (*mcp.CallToolResult, CheckLimitsOutput, error) {
+	output, err := s.appLimits(ctx, input.App, input.Refresh)
+	if err != nil {
+		return nil, CheckLimitsOutput{}, err
+	}
+	result, err := buildToolResult(output)
+	if err != nil {
+		return nil, output, err
+	}
+	return result, output, nil
+}
+
+// appLimits returns the quota for app (or the main token), using the last
+// send's headers when they are fresh and probing Pushover otherwise.
+func (s *Server) appLimits(ctx context.Context, app string, refresh bool) (CheckLimitsOutput, error) {
+	client := s.newClient()
+	if app != "" {
+		appCfg, err := s.config().ForApp(app)
+		if err != nil {
+			return CheckLimitsOutput{}, err
+		}
+		client.AppToken = appCfg.AppToken
+	}
+
+	now := time.Now()
+	snap, ok := s.quota.fresh(client.AppToken, now)
+	if !ok || refresh {
+		limits, err := client.AppLimits(ctx)
+		if err != nil {
+			return CheckLimitsOutput{}, err
+		}
+		s.quota.record(client.AppToken, limits, limitsFromProbe, now)
+		snap = quotaSnapshot{limits: *limits, source: limitsFromProbe, seen: now}
+	}
+
+	limits := snap.limits
+	return CheckLimitsOutput{
+		App:       app,
+		Limit:     limits.Limit,
+		Remaining: limits.Remaining,
+		Used:      limits.Limit - limits.Remaining,
+		Reset:     limits.Reset,
+		Low:       float64(limits.Remaining) <= float64(limits.Limit)*lowQuotaFraction,
+		Source:    snap.source,
+		CheckedAt: snap.seen,
+	}, nil
+}
+
+func (s *Server) registerLimitsResource() {
+	res := &mcp.Resource{
+		URI:         "push://limits",
+		Name:        "Push Quota",
+		Description: "Monthly message quota for the main Pushover application.",
+		MIMEType:    "application/json",
+	}
+
+	s.mcp.AddResource(res, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		limits, err := s.appLimits(ctx, "", false)
+		if err != nil {
+			return nil, err
+		}
+		payload := ResourcePayload{
+			Metadata: ResourceMetadata{
+				Timestamp:   time.Now(),
+				ResourceURI: res.URI,
+				Count:       1,
+			},
+			Data: limits,
+		}
+		return buildResourceResult(req.Params.URI, payload)
+	})
+}
@@ -0,0 +1,61 @@
+// ABOUTME: Tests for the check_limits tool.
+// ABOUTME: Runs against the mock Pushover API to cover cached and probed quota.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCheckLimits(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	srv.MonthlyLimit = 10
+	ctx := context.Background()
+
+	session := connect(t, &config.Config{
+		AppToken: pushovertest.AppToken,
+		UserKey:  pushovertest.UserKey,
+		APIURL:   srv.URL(),
+	}, nil)
+
+	checkLimits := func(args map[string]any) CheckLimitsOutput {
+		t.Helper()
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: toolCheckLimits, Arguments: args})
+		if err != nil || result.IsError {
+			t.Fatalf("check_limits: %v %+v", err, result)
+		}
+		var out CheckLimitsOutput
+		data, _ := json.Marshal(result.StructuredContent)
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("decode output: %v", err)
+		}
+		return out
+	}
+
+	out := checkLimits(nil)
+	if out.Source != limitsFromProbe || out.Limit != 10 || out.Remaining != 10 || out.Low {
+		t.Errorf("before sending = %+v, want probe with 10 of 10 remaining", out)
+	}
+
+	for range 9 {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: toolSendNotification, Arguments: map[string]any{"message": "hi"}})
+		if err != nil || result.IsError {
+			t.Fatalf("send_notification: %v %+v", err, result)
+		}
+	}
+	out = checkLimits(nil)
+	if out.Source != limitsFromSend || out.Remaining != 1 || out.Used != 9 || !out.Low {
+		t.Errorf("after sending = %+v, want 1 remaining from send headers, low", out)
+	}
+
+	out = checkLimits(map[string]any{"refresh": true})
+	if out.Source != limitsFromProbe || out.Remaining != 1 {
+		t.Errorf("refresh = %+v, want probe with 1 remaining", out)
+	}
+}
@@ -0,0 +1,40 @@
+// ABOUTME: Reports server-side problems to the MCP client as log notifications.
+// ABOUTME: Mirrors each record to the server's slog logger for local logs.
+package mcp
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// loggerName identifies push in notifications/message sent to clients.
+const loggerName = "push"
+
+// report logs msg at level to the server log and, when the request came from
+// a client session, as a notifications/message the client can show. Clients
+// only receive records at or above the level they set with logging/setLevel.
+// Notice has no slog equivalent, so it is written locally as info.
+func (s *Server) report(ctx context.Context, session *mcp.ServerSession, level slog.Level, msg string, args ...any) {
+	local := level
+	if local > slog.LevelInfo && local < slog.LevelWarn {
+		local = slog.LevelInfo
+	}
+	s.log.Log(ctx, local, msg, args...)
+
+	if session == nil {
+		return
+	}
+	handler := mcp.NewLoggingHandler(session, &mcp.LoggingHandlerOptions{LoggerName: loggerName})
+	slog.New(handler).Log(ctx, level, msg, args...)
+}
+
+// sessionOf returns the client session behind req, or nil when the handler is
+// called directly.
+func sessionOf(req *mcp.CallToolRequest) *mcp.ServerSession {
+	if req == nil {
+		return nil
+	}
+	return req.Session
+}
@@ -0,0 +1,78 @@
+// ABOUTME: Tests for forwarding server warnings to MCP clients.
+// ABOUTME: Connects an in-memory client and checks the log notifications it gets.
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReportSendsLogNotifications(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var local bytes.Buffer
+	s := &Server{
+		mcp: mcp.NewServer(&mcp.Implementation{Name: "push", Version: "test"}, nil),
+		log: slog.New(slog.NewTextHandler(&local, nil)),
+	}
+
+	received := make(chan *mcp.LoggingMessageParams, 4)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			received <- req.Params
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	session, err := s.mcp.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	defer session.Close()
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer clientSession.Close()
+
+	// Nothing is forwarded until the client picks a level.
+	s.report(ctx, session, mcp.LevelWarning, "before set level")
+	if err := clientSession.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "notice"}); err != nil {
+		t.Fatalf("set level: %v", err)
+	}
+	s.report(ctx, session, slog.LevelInfo, "below client level")
+	s.report(ctx, session, mcp.LevelWarning, "failed to persist messages", "error", "disk full")
+
+	select {
+	case params := <-received:
+		if params.Level != "warning" || params.Logger != loggerName {
+			t.Errorf("params = %+v, want warning from %s", params, loggerName)
+		}
+		data, _ := params.Data.(map[string]any)
+		if data["msg"] != "failed to persist messages" || data["error"] != "disk full" {
+			t.Errorf("data = %v", params.Data)
+		}
+	case <-ctx.Done():
+		t.Fatal("no log notification received")
+	}
+	select {
+	case params := <-received:
+		t.Errorf("unexpected notification: %+v", params)
+	default:
+	}
+
+	// Notice is logged locally as info; every record reaches the server log.
+	s.report(ctx, nil, mcp.LevelNotice, "send budget reached, waiting")
+	for _, want := range []string{"before set level", "below client level", `level=INFO msg="send budget reached, waiting"`} {
+		if !strings.Contains(local.String(), want) {
+			t.Errorf("server log missing %q:\n%s", want, local.String())
+		}
+	}
+}
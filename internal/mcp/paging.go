@@ -0,0 +1,159 @@
+// ABOUTME: Pages and size limits for check_messages and list_history replies.
+// ABOUTME: Encodes cursors into a stored fetch and cuts message bodies to max_body_chars and a byte budget.
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// checkMessagesMaxBytes bounds the JSON of the messages in one check_messages
+// reply, well under what MCP clients accept for a tool result.
+const checkMessagesMaxBytes = 100_000
+
+// minTrimmedBody is the fewest characters fitMessages cuts a body to before
+// it drops whole messages instead.
+const minTrimmedBody = 500
+
+// encodeFetchCursor returns a cursor for the rest of a fetch that ended at
+// upTo, after the message with ID after.
+func encodeFetchCursor(after, upTo int64) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d:%d", after, upTo))
+}
+
+func decodeFetchCursor(cursor string) (after, upTo int64, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cursor: %w", err)
+	}
+	afterStr, upToStr, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return 0, 0, errors.New("invalid cursor")
+	}
+	if after, err = strconv.ParseInt(afterStr, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if upTo, err = strconv.ParseInt(upToStr, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return after, upTo, nil
+}
+
+// CheckedMessage is a message in a check_messages reply.
+type CheckedMessage struct {
+	pushover.ReceivedMessage
+	// BodyTruncated is set when Message was cut to max_body_chars or to fit
+	// the reply.
+	BodyTruncated bool `json:"body_truncated,omitempty"`
+}
+
+// HistoryMessage is a message in a list_history reply.
+type HistoryMessage struct {
+	db.MessageRecord
+	// BodyTruncated is set when Message was cut to max_body_chars.
+	BodyTruncated bool `json:"body_truncated,omitempty"`
+}
+
+// maxBodyChars resolves a call's max_body_chars against the server default;
+// zero means no limit.
+func maxBodyChars(input *int, fallback int) (int, error) {
+	if input == nil {
+		return fallback, nil
+	}
+	if *input < 0 {
+		return 0, fmt.Errorf("max_body_chars must not be negative")
+	}
+	return *input, nil
+}
+
+// historyMessages returns records with bodies cut to maxChars characters
+// when it's positive. The result is never nil, since the output schema wants
+// an array even when nothing matched.
+func historyMessages(records []db.MessageRecord, maxChars int) []HistoryMessage {
+	out := make([]HistoryMessage, len(records))
+	for i, rec := range records {
+		out[i].MessageRecord = rec
+		out[i].Message, out[i].BodyTruncated = cutBody(rec.Message, maxChars)
+	}
+	return out
+}
+
+// fitMessages cuts bodies to maxChars characters when it's positive and
+// keeps msgs within budget bytes of JSON. Bodies are cut further, to the
+// longest length that fits, and if even minTrimmedBody characters each is
+// too much, messages are dropped from the end, keeping at least one. It
+// returns the messages kept and how many of their bodies were cut.
+func fitMessages(msgs []pushover.ReceivedMessage, maxChars, budget int) ([]CheckedMessage, int) {
+	longest := 0
+	for _, msg := range msgs {
+		longest = max(longest, len([]rune(msg.Message)))
+	}
+	limit := longest
+	if maxChars > 0 {
+		limit = min(limit, maxChars)
+	}
+
+	fitted := cutBodies(msgs, limit)
+	if encodedSize(fitted) > budget {
+		// Find the longest body length that fits.
+		lo, hi := min(minTrimmedBody, limit), limit
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+			if encodedSize(cutBodies(msgs, mid)) <= budget {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+		fitted = cutBodies(msgs, lo)
+		for len(fitted) > 1 && encodedSize(fitted) > budget {
+			fitted = fitted[:len(fitted)-1]
+		}
+	}
+
+	cut := 0
+	for _, msg := range fitted {
+		if msg.BodyTruncated {
+			cut++
+		}
+	}
+	return fitted, cut
+}
+
+// cutBodies returns msgs with bodies over limit characters cut to limit.
+func cutBodies(msgs []pushover.ReceivedMessage, limit int) []CheckedMessage {
+	out := make([]CheckedMessage, len(msgs))
+	for i, msg := range msgs {
+		out[i].ReceivedMessage = msg
+		out[i].Message, out[i].BodyTruncated = cutBody(msg.Message, limit)
+	}
+	return out
+}
+
+// cutBody shortens body to limit characters, ending it with a note of how
+// many were cut, when it's longer and limit is positive.
+func cutBody(body string, limit int) (string, bool) {
+	if limit <= 0 {
+		return body, false
+	}
+	runes := []rune(body)
+	if len(runes) <= limit {
+		return body, false
+	}
+	return fmt.Sprintf("%s… [%d more characters trimmed]", string(runes[:limit]), len(runes)-limit), true
+}
+
+func encodedSize(msgs []CheckedMessage) int {
+	data, err := json.Marshal(msgs)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
@@ -0,0 +1,88 @@
+// ABOUTME: Tests for check_messages cursors, max_body_chars, and the reply size budget.
+// ABOUTME: Checks cursors round-trip, bodies are cut and flagged, and messages drop only when trimming isn't enough.
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+)
+
+func TestFetchCursor(t *testing.T) {
+	after, upTo, err := decodeFetchCursor(encodeFetchCursor(41, 99))
+	if err != nil || after != 41 || upTo != 99 {
+		t.Fatalf("decodeFetchCursor = %d, %d, %v; want 41, 99", after, upTo, err)
+	}
+	if _, _, err := decodeFetchCursor("not a cursor"); err == nil {
+		t.Error("decodeFetchCursor accepted garbage")
+	}
+}
+
+func TestMaxBodyChars(t *testing.T) {
+	zero, negative := 0, -1
+	if got, err := maxBodyChars(nil, 2000); got != 2000 || err != nil {
+		t.Errorf("maxBodyChars(nil, 2000) = %d, %v; want the server default", got, err)
+	}
+	if got, err := maxBodyChars(&zero, 2000); got != 0 || err != nil {
+		t.Errorf("maxBodyChars(0, 2000) = %d, %v; want no limit", got, err)
+	}
+	if _, err := maxBodyChars(&negative, 0); err == nil {
+		t.Error("maxBodyChars accepted a negative limit")
+	}
+
+	history := historyMessages([]db.MessageRecord{{Message: "<p>long html</p>"}, {Message: "ok"}}, 3)
+	if history[0].Message != "<p>… [13 more characters trimmed]" || !history[0].BodyTruncated || history[1].BodyTruncated {
+		t.Errorf("historyMessages = %+v", history)
+	}
+	if empty := historyMessages(nil, 3); empty == nil {
+		t.Error("historyMessages(nil) is nil, want an empty array")
+	}
+}
+
+func TestFitMessages(t *testing.T) {
+	small := []pushover.ReceivedMessage{{PushoverID: 1, Message: "short"}, {PushoverID: 2, Message: "also short"}}
+	if got, trimmed := fitMessages(small, 0, 10_000); len(got) != 2 || trimmed != 0 || got[0].Message != "short" {
+		t.Errorf("fitMessages(small) = %+v, %d trimmed", got, trimmed)
+	}
+
+	// max_body_chars cuts bodies even when the reply would fit.
+	got, trimmed := fitMessages(small, 5, 10_000)
+	if trimmed != 1 || got[0].BodyTruncated || got[1].Message != "also … [5 more characters trimmed]" {
+		t.Errorf("fitMessages(small, 5) = %+v, %d trimmed", got, trimmed)
+	}
+}
+
+func TestFitMessagesTrimsBodies(t *testing.T) {
+	huge := []pushover.ReceivedMessage{
+		{PushoverID: 1, Message: "brief"},
+		{PushoverID: 2, Message: strings.Repeat("<p>report</p>", 2000)},
+		{PushoverID: 3, Message: strings.Repeat("é", 8000)},
+	}
+	got, trimmed := fitMessages(huge, 0, 10_000)
+	if len(got) != 3 || trimmed != 2 || encodedSize(got) > 10_000 {
+		t.Fatalf("fitMessages(huge) kept %d, trimmed %d, %d bytes", len(got), trimmed, encodedSize(got))
+	}
+	if got[0].Message != "brief" || !strings.Contains(got[1].Message, "more characters trimmed]") {
+		t.Errorf("fitMessages(huge) bodies = %.40q, %.40q", got[0].Message, got[1].Message)
+	}
+	if huge[1].Message == got[1].Message {
+		t.Error("fitMessages changed its input")
+	}
+	if got[0].BodyTruncated || !got[1].BodyTruncated || !got[2].BodyTruncated {
+		t.Errorf("body_truncated = %v, %v, %v; want false, true, true", got[0].BodyTruncated, got[1].BodyTruncated, got[2].BodyTruncated)
+	}
+}
+
+// When even short bodies don't fit, whole messages go from the end.
+func TestFitMessagesDropsFromEnd(t *testing.T) {
+	many := make([]pushover.ReceivedMessage, 40)
+	for i := range many {
+		many[i] = pushover.ReceivedMessage{PushoverID: int64(i + 1), Message: strings.Repeat("x", 2000)}
+	}
+	got, _ := fitMessages(many, 0, 10_000)
+	if len(got) == 0 || len(got) == len(many) || encodedSize(got) > 10_000 || got[0].PushoverID != 1 {
+		t.Errorf("fitMessages(many) kept %d messages in %d bytes", len(got), encodedSize(got))
+	}
+}
@@ -0,0 +1,123 @@
+// ABOUTME: Per-session and server-wide send limits for the MCP send tool.
+// ABOUTME: Keeps a sliding one-minute window of sends in memory.
+package mcp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Rate limit scopes reported in rate_limited errors.
+const (
+	limitScopeSession = "session"
+	limitScopeGlobal  = "global"
+	limitScopeShared  = "shared"
+)
+
+// sendLimiter caps sends per minute for each client session and for the
+// server as a whole, so a looping agent can't drain the monthly quota.
+type sendLimiter struct {
+	mu         sync.Mutex
+	perSession int
+	global     int
+	window     time.Duration
+	sent       []time.Time
+	sessions   map[*mcp.ServerSession][]time.Time
+}
+
+func newSendLimiter(perSession, global int) *sendLimiter {
+	return &sendLimiter{
+		perSession: perSession,
+		global:     global,
+		window:     time.Minute,
+		sessions:   make(map[*mcp.ServerSession][]time.Time),
+	}
+}
+
+// tooMany returns an error when n sends exceed a limit outright, so no
+// amount of waiting would let reserve accept them.
+func (l *sendLimiter) tooMany(n int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, limit := range []struct {
+		scope string
+		max   int
+	}{{limitScopeSession, l.perSession}, {limitScopeGlobal, l.global}} {
+		if limit.max > 0 && n > limit.max {
+			return fmt.Errorf("needs %d sends but the %s limit is %d per minute", n, limit.scope, limit.max)
+		}
+	}
+	return nil
+}
+
+// reserve records n sends for session at now if both limits allow them. When
+// they don't, it returns the scope that refused and how long until enough of
+// the window has passed.
+func (l *sendLimiter) reserve(session *mcp.ServerSession, n int, now time.Time) (string, time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-l.window)
+	l.sent = prune(l.sent, cutoff)
+	for s, times := range l.sessions {
+		if times = prune(times, cutoff); len(times) == 0 {
+			delete(l.sessions, s)
+		} else {
+			l.sessions[s] = times
+		}
+	}
+
+	if retry, ok := l.allows(l.sessions[session], l.perSession, n, now); !ok {
+		return limitScopeSession, retry, false
+	}
+	if retry, ok := l.allows(l.sent, l.global, n, now); !ok {
+		return limitScopeGlobal, retry, false
+	}
+
+	for range n {
+		l.sent = append(l.sent, now)
+		l.sessions[session] = append(l.sessions[session], now)
+	}
+	return "", 0, true
+}
+
+// setLimits changes both limits; sends already in the window still count.
+func (l *sendLimiter) setLimits(perSession, global int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.perSession, l.global = perSession, global
+}
+
+// forget drops the window of a session that has ended.
+func (l *sendLimiter) forget(session *mcp.ServerSession) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.sessions, session)
+}
+
+// allows reports whether n more sends fit under limit given the sends in the
+// window. A limit of zero or less disables the check.
+func (l *sendLimiter) allows(times []time.Time, limit, n int, now time.Time) (time.Duration, bool) {
+	if limit <= 0 || len(times)+n <= limit {
+		return 0, true
+	}
+	// More than the limit never fits; callers rule that out with tooMany.
+	if n > limit {
+		return l.window, false
+	}
+	// The send that must expire before n more fit.
+	oldest := times[len(times)+n-limit-1]
+	return oldest.Add(l.window).Sub(now), false
+}
+
+// prune drops times at or before cutoff; times are in ascending order.
+func prune(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
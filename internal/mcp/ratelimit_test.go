@@ -0,0 +1,72 @@
+// ABOUTME: Tests for the MCP send limiter.
+// ABOUTME: Covers per-session and global windows, retry_after values, and oversized requests.
+package mcp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSendLimiter(t *testing.T) {
+	limiter := newSendLimiter(2, 3)
+	a, b := &mcp.ServerSession{}, &mcp.ServerSession{}
+	start := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	for i := range 2 {
+		if _, _, ok := limiter.reserve(a, 1, start.Add(time.Duration(i)*time.Second)); !ok {
+			t.Fatalf("send %d from session a refused", i+1)
+		}
+	}
+	scope, retry, ok := limiter.reserve(a, 1, start.Add(10*time.Second))
+	if ok || scope != limitScopeSession || retry != 50*time.Second {
+		t.Errorf("third send from a = %q, %v, %v; want session limit, 50s", scope, retry, ok)
+	}
+
+	if _, _, ok := limiter.reserve(b, 1, start.Add(10*time.Second)); !ok {
+		t.Fatal("first send from session b refused")
+	}
+	scope, retry, ok = limiter.reserve(b, 1, start.Add(20*time.Second))
+	if ok || scope != limitScopeGlobal || retry != 40*time.Second {
+		t.Errorf("second send from b = %q, %v, %v; want global limit, 40s", scope, retry, ok)
+	}
+
+	// After the window passes, session a may send again.
+	if _, _, ok := limiter.reserve(a, 1, start.Add(61*time.Second)); !ok {
+		t.Error("send after window refused")
+	}
+	// A split message needing more slots than the limit is never allowed.
+	if _, _, ok := limiter.reserve(b, 3, start.Add(5*time.Minute)); ok {
+		t.Error("oversized reserve accepted")
+	}
+}
+
+func TestSendLimiterTooMany(t *testing.T) {
+	limiter := newSendLimiter(2, 3)
+	if err := limiter.tooMany(2); err != nil {
+		t.Errorf("tooMany(2) = %v, want nil", err)
+	}
+	err := limiter.tooMany(3)
+	if err == nil || !strings.Contains(err.Error(), "needs 3 sends but the session limit is 2 per minute") {
+		t.Errorf("tooMany(3) = %v, want the session limit named", err)
+	}
+	limiter.setLimits(0, 3)
+	if err := limiter.tooMany(4); err == nil || !strings.Contains(err.Error(), "global limit is 3") {
+		t.Errorf("tooMany(4) = %v, want the global limit named", err)
+	}
+	if err := newSendLimiter(0, 0).tooMany(100); err != nil {
+		t.Errorf("tooMany with limits disabled = %v, want nil", err)
+	}
+}
+
+func TestSendLimiterDisabled(t *testing.T) {
+	limiter := newSendLimiter(0, 0)
+	now := time.Now()
+	for i := range 100 {
+		if _, _, ok := limiter.reserve(nil, 1, now); !ok {
+			t.Fatalf("send %d refused with limits disabled", i+1)
+		}
+	}
+}
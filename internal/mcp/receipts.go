@@ -0,0 +1,121 @@
+// ABOUTME: MCP tool reporting whether an emergency notification was acknowledged.
+// ABOUTME: Asks Pushover for the receipt's state and records it on the sent log.
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/messages"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const toolGetReceiptStatus = "get_receipt_status"
+
+type GetReceiptStatusInput struct {
+	Receipt string `json:"receipt"`
+	App     string `json:"app,omitempty"`
+}
+
+type GetReceiptStatusOutput struct {
+	Receipt        string    `json:"receipt"`
+	Status         string    `json:"status"`
+	AcknowledgedAt time.Time `json:"acknowledged_at,omitzero"`
+	AcknowledgedBy string    `json:"acknowledged_by_device,omitempty"`
+	ExpiresAt      time.Time `json:"expires_at,omitzero"`
+	// Message, Title, and SentAt come from the sent log when the send was
+	// made by push.
+	Message string    `json:"message,omitempty"`
+	Title   string    `json:"title,omitempty"`
+	SentAt  time.Time `json:"sent_at,omitzero"`
+}
+
+func (s *Server) registerGetReceiptStatusTool() {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"receipt": map[string]any{
+				"type":        "string",
+				"description": "Receipt returned by send_notification for an emergency (priority 2) message",
+			},
+			"app": map[string]any{
+				"type":        "string",
+				"description": "[apps] entry the message was sent with. Defaults to the one recorded for the send, or the main app token.",
+			},
+		},
+		"required": []string{"receipt"},
+	}
+
+	addTool(s, &mcp.Tool{
+		Name:        toolGetReceiptStatus,
+		Description: "Check whether an emergency notification was acknowledged, is still repeating, or expired unacknowledged. status is pending, acknowledged, or expired.",
+		InputSchema: schema,
+	}, s.handleGetReceiptStatus)
+}
+
+func (s *Server) handleGetReceiptStatus(ctx context.Context, _ *mcp.CallToolRequest, input GetReceiptStatusInput) (*mcp.CallToolResult, GetReceiptStatusOutput, error) {
+	receipt := strings.TrimSpace(input.Receipt)
+	if receipt == "" {
+		return nil, GetReceiptStatusOutput{}, fmt.Errorf("receipt is required")
+	}
+
+	storeCtx, cancel := s.store.op(ctx)
+	rec, tracked, err := s.store.SentByReceipt(storeCtx, receipt)
+	cancel()
+	if err != nil {
+		return nil, GetReceiptStatusOutput{}, err
+	}
+	if !tracked {
+		rec = db.SentRecord{Receipt: receipt, ReceiptStatus: db.ReceiptPending}
+	}
+	if input.App != "" {
+		rec.App = input.App
+	}
+
+	clientFor := func(app string) (*pushover.Client, error) {
+		client := s.newClient()
+		if app != "" {
+			appCfg, err := s.config().ForApp(app)
+			if err != nil {
+				return nil, err
+			}
+			client.AppToken = appCfg.AppToken
+		}
+		return client, nil
+	}
+	if tracked {
+		if _, err := messages.CheckReceipt(ctx, s.store.Store, clientFor, &rec); err != nil {
+			return nil, GetReceiptStatusOutput{}, err
+		}
+	} else {
+		client, err := clientFor(rec.App)
+		if err != nil {
+			return nil, GetReceiptStatusOutput{}, err
+		}
+		status, err := client.Receipt(ctx, receipt)
+		if err != nil {
+			return nil, GetReceiptStatusOutput{}, err
+		}
+		messages.ApplyReceipt(&rec, status)
+	}
+
+	output := GetReceiptStatusOutput{
+		Receipt:        receipt,
+		Status:         rec.ReceiptStatus,
+		AcknowledgedAt: rec.ReceiptAckedAt,
+		AcknowledgedBy: rec.ReceiptAckedBy,
+		ExpiresAt:      rec.ReceiptExpiresAt,
+		Message:        rec.Message,
+		Title:          rec.Title,
+		SentAt:         rec.SentAt,
+	}
+	result, err := buildToolResult(output)
+	if err != nil {
+		return nil, output, err
+	}
+	return result, output, nil
+}
@@ -0,0 +1,59 @@
+// ABOUTME: Tests for the get_receipt_status tool.
+// ABOUTME: Sends an emergency notification and follows its receipt to acknowledgement.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestGetReceiptStatus(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	noConfirmation := 3
+
+	session := connect(t, &config.Config{
+		AppToken: pushovertest.AppToken,
+		UserKey:  pushovertest.UserKey,
+		APIURL:   srv.URL(),
+		MCP:      config.MCPConfig{RequireConfirmationPriority: &noConfirmation},
+	}, nil)
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      toolSendNotification,
+		Arguments: map[string]any{"message": "server down", "priority": 2},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("send_notification = %v, %v", result, err)
+	}
+	var sent SendNotificationOutput
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &sent); err != nil || sent.Receipt == "" {
+		t.Fatalf("send output %v, %v; want a receipt", result.Content, err)
+	}
+
+	status := func() GetReceiptStatusOutput {
+		t.Helper()
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: toolGetReceiptStatus, Arguments: map[string]any{"receipt": sent.Receipt}})
+		if err != nil || result.IsError {
+			t.Fatalf("get_receipt_status = %v, %v", result, err)
+		}
+		var out GetReceiptStatusOutput
+		if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	if got := status(); got.Status != "pending" || got.Message != "server down" {
+		t.Errorf("status before ack = %+v, want pending with the sent message", got)
+	}
+	srv.AcknowledgeSent(sent.Receipt, "phone")
+	if got := status(); got.Status != "acknowledged" || got.AcknowledgedBy != "phone" {
+		t.Errorf("status after ack = %+v, want acknowledged on phone", got)
+	}
+}
@@ -0,0 +1,116 @@
+// ABOUTME: MCP tool that schedules a reminder from a plain sentence.
+// ABOUTME: Finds the time phrase in the text and leaves push daemon to send it.
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/remind"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const toolCreateReminder = "create_reminder"
+
+type CreateReminderInput struct {
+	Text     string `json:"text"`
+	When     string `json:"when,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+	Device   string `json:"device,omitempty"`
+}
+
+type CreateReminderOutput struct {
+	ID       int64     `json:"id"`
+	Message  string    `json:"message"`
+	RemindAt time.Time `json:"remind_at"`
+	// Described is the time in words, for confirming it with the user.
+	Described string `json:"described"`
+	// Phrase is the part of the text read as the time.
+	Phrase string `json:"phrase"`
+}
+
+func (s *Server) registerCreateReminderTool() {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"text": map[string]any{
+				"type":        "string",
+				"description": "What to be reminded of and when, e.g. \"call dentist tomorrow at 3pm\" or \"check the oven in 20 minutes\". With when set, all of it is the message.",
+			},
+			"when": map[string]any{
+				"type":        "string",
+				"description": "When to send, if not in text: a phrase like \"friday morning\" or a date like \"2026-11-02 15:00\" in the user's local time",
+			},
+			"priority": map[string]any{
+				"type":        "integer",
+				"minimum":     -2,
+				"maximum":     2,
+				"description": "Priority of the reminder (default 0)",
+			},
+			"device": map[string]any{
+				"type":        "string",
+				"description": "Target device name. Defaults to config's default_device.",
+			},
+		},
+		"required": []string{"text"},
+	}
+
+	addTool(s, &mcp.Tool{
+		Name:        toolCreateReminder,
+		Description: "Schedule a reminder notification for later. The time is read from the text (relative times, day names, clock times, and dates) and returned with the message so you can confirm both with the user. push daemon must be running to send it.",
+		InputSchema: schema,
+	}, s.handleCreateReminder)
+}
+
+func (s *Server) handleCreateReminder(ctx context.Context, _ *mcp.CallToolRequest, input CreateReminderInput) (*mcp.CallToolResult, CreateReminderOutput, error) {
+	if input.Priority < -2 || input.Priority > 2 {
+		return nil, CreateReminderOutput{}, errors.New("priority must be between -2 and 2")
+	}
+	now := time.Now()
+	var r remind.Reminder
+	if when := strings.TrimSpace(input.When); when != "" {
+		at, err := remind.ParseTime(when, now)
+		if err != nil {
+			return nil, CreateReminderOutput{}, err
+		}
+		r = remind.Reminder{Message: strings.TrimSpace(input.Text), At: at, Phrase: when}
+		if r.Message == "" {
+			return nil, CreateReminderOutput{}, errors.New("text cannot be empty")
+		}
+	} else {
+		var err error
+		if r, err = remind.Parse(input.Text, now); err != nil {
+			return nil, CreateReminderOutput{}, err
+		}
+	}
+
+	storeCtx, cancel := s.store.op(ctx)
+	defer cancel()
+	id, err := s.store.ScheduleSend(storeCtx, db.ScheduledRecord{
+		Title:    "Reminder",
+		Message:  r.Message,
+		Priority: input.Priority,
+		Device:   input.Device,
+		SendAt:   r.At,
+		Kind:     db.ScheduledReminder,
+	})
+	if err != nil {
+		return nil, CreateReminderOutput{}, err
+	}
+
+	output := CreateReminderOutput{
+		ID:        id,
+		Message:   r.Message,
+		RemindAt:  r.At,
+		Described: remind.Describe(r.At, now),
+		Phrase:    r.Phrase,
+	}
+	result, err := buildToolResult(output)
+	if err != nil {
+		return nil, output, err
+	}
+	return result, output, nil
+}
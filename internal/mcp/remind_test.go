@@ -0,0 +1,85 @@
+// ABOUTME: Tests for the create_reminder tool.
+// ABOUTME: Schedules reminders through the tool and checks what lands in the store.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCreateReminder(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "push.db")
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	server, err := NewServer(&config.Config{}, "", store, dbPath)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.mcp.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	create := func(args map[string]any) (CreateReminderOutput, *mcp.CallToolResult) {
+		t.Helper()
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: toolCreateReminder, Arguments: args})
+		if err != nil {
+			t.Fatalf("create_reminder(%v): %v", args, err)
+		}
+		var out CreateReminderOutput
+		if !result.IsError {
+			if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return out, result
+	}
+
+	start := time.Now()
+	out, result := create(map[string]any{"text": "check the oven in 20 minutes", "priority": 1})
+	if result.IsError {
+		t.Fatalf("create_reminder failed: %v", result.Content)
+	}
+	if out.Message != "check the oven" || out.Phrase != "in 20 minutes" {
+		t.Errorf("reminder = %+v", out)
+	}
+	if d := out.RemindAt.Sub(start); d < 20*time.Minute || d > 21*time.Minute {
+		t.Errorf("remind_at is %s away, want 20m", d)
+	}
+
+	out, result = create(map[string]any{"text": "Stand-up notes", "when": "tomorrow at 9am"})
+	if result.IsError || out.Message != "Stand-up notes" || out.RemindAt.Hour() != 9 {
+		t.Errorf("reminder with when = %+v, %v", out, result.Content)
+	}
+
+	if _, result := create(map[string]any{"text": "buy milk"}); !result.IsError {
+		t.Error("reminder without a time succeeded, want an error")
+	}
+
+	pending, err := store.PendingScheduled(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].Kind != db.ScheduledReminder || pending[0].Priority != 1 || pending[0].Title != "Reminder" {
+		t.Errorf("pending = %+v, want the two reminders", pending)
+	}
+}
@@ -1,13 +1,23 @@
 // ABOUTME: MCP resource definitions and providers.
-// ABOUTME: Exposes unread messages, history, and status as resources.
+// ABOUTME: Exposes unread messages, history, status, and lookup lists as resources.
 package mcp
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/araddon/dateparse"
+	"github.com/harper/push/internal/catalog"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -21,24 +31,30 @@ type ResourceMetadata struct {
 	Timestamp   time.Time `json:"timestamp"`
 	ResourceURI string    `json:"resource_uri"`
 	Count       int       `json:"count"`
+	NextCursor  string    `json:"next_cursor,omitempty"`
 }
 
 func (s *Server) registerResources() {
 	s.registerUnreadResource()
 	s.registerHistoryResource()
+	s.registerMessageResource()
+	s.registerMediaResource()
 	s.registerStatusResource()
+	s.registerLimitsResource()
+	s.registerCatalogResource("push://sounds", "Push Sounds", "Notification sounds accepted by send_notification's sound field, with descriptions.", db.CatalogSounds)
+	s.registerCatalogResource("push://devices", "Push Devices", "Device names accepted by send_notification's device field.", db.CatalogDevices)
 }
 
 func (s *Server) registerUnreadResource() {
 	res := &mcp.Resource{
 		URI:         "push://unread",
 		Name:        "Unread Messages",
-		Description: "Current unread messages fetched directly from Pushover (no persistence or acknowledgement).",
+		Description: "Current unread messages fetched directly from Pushover (no persistence or acknowledgement). Emergency messages awaiting acknowledgement have pending_ack set and a receipt.",
 		MIMEType:    "application/json",
 	}
 
 	s.mcp.AddResource(res, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		if err := s.cfg.ValidateReceive(); err != nil {
+		if err := s.config().ValidateReceive(); err != nil {
 			return nil, err
 		}
 		client := s.newClient()
@@ -46,53 +62,227 @@ func (s *Server) registerUnreadResource() {
 		if err != nil {
 			return nil, err
 		}
+		unread := make([]UnreadMessage, 0, len(result.Messages))
+		for _, msg := range result.Messages {
+			unread = append(unread, UnreadMessage{ReceivedMessage: msg, PendingAck: msg.NeedsAck()})
+		}
 		payload := ResourcePayload{
 			Metadata: ResourceMetadata{
 				Timestamp:   time.Now(),
 				ResourceURI: res.URI,
 				Count:       len(result.Messages),
 			},
-			Data: result.Messages,
+			Data: unread,
 		}
 		return buildResourceResult(req.Params.URI, payload)
 	})
 }
 
+// UnreadMessage is a push://unread entry. PendingAck marks emergency messages
+// still waiting for someone to run 'push ack' with their receipt.
+type UnreadMessage struct {
+	pushover.ReceivedMessage
+	PendingAck bool `json:"pending_ack,omitempty"`
+}
+
+const (
+	historyPageSize    = 20
+	historyMaxPageSize = 100
+)
+
 func (s *Server) registerHistoryResource() {
 	res := &mcp.Resource{
 		URI:         "push://history",
 		Name:        "Recent History",
-		Description: "Last 20 persisted messages from the local SQLite database.",
+		Description: "First page (20 rows) of persisted messages. Follow links.next or use the push://history{?cursor,limit,since,app} template to page further.",
 		MIMEType:    "application/json",
 	}
+	s.mcp.AddResource(res, s.readHistoryPage)
 
-	s.mcp.AddResource(res, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		records, err := s.store.QueryMessages(ctx, 20, nil, "")
+	tmpl := &mcp.ResourceTemplate{
+		URITemplate: "push://history{?cursor,limit,since,app}",
+		Name:        "History Pages",
+		Description: "Cursor-paginated persisted messages, newest first. limit defaults to 20 (max 100); since accepts natural language dates; app filters by sending application.",
+		MIMEType:    "application/json",
+	}
+	s.mcp.AddResourceTemplate(tmpl, s.readHistoryPage)
+}
+
+func (s *Server) readHistoryPage(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	filter, err := parseHistoryURI(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to learn whether another page exists.
+	pageSize := filter.Limit
+	filter.Limit = pageSize + 1
+	ctx, cancel := s.store.op(ctx)
+	defer cancel()
+	records, err := s.store.FindMessages(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := ResourcePayload{
+		Metadata: ResourceMetadata{
+			Timestamp:   time.Now(),
+			ResourceURI: req.Params.URI,
+		},
+		Links: map[string]string{"message": "push://message/{pushover_id}"},
+	}
+	if len(records) > pageSize {
+		records = records[:pageSize]
+		next := filter
+		next.Limit = pageSize
+		next.Cursor = db.NextCursor(records)
+		payload.Metadata.NextCursor = next.Cursor
+		payload.Links["next"] = historyURI(next, req.Params.URI)
+	}
+	payload.Metadata.Count = len(records)
+	payload.Data = records
+	return buildResourceResult(req.Params.URI, payload)
+}
+
+func parseHistoryURI(uri string) (db.MessageFilter, error) {
+	filter := db.MessageFilter{Limit: historyPageSize}
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return filter, fmt.Errorf("invalid history uri: %w", err)
+	}
+	query := parsed.Query()
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return filter, fmt.Errorf("limit must be a positive integer")
+		}
+		filter.Limit = min(limit, historyMaxPageSize)
+	}
+	if raw := query.Get("since"); raw != "" {
+		since, err := dateparse.ParseLocal(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid since value: %w", err)
+		}
+		filter.Since = &since
+	}
+	filter.App = query.Get("app")
+	filter.Cursor = query.Get("cursor")
+	return filter, nil
+}
+
+// historyURI rebuilds a history page URI, preserving the original since text.
+func historyURI(filter db.MessageFilter, original string) string {
+	query := url.Values{}
+	query.Set("cursor", filter.Cursor)
+	query.Set("limit", strconv.Itoa(filter.Limit))
+	if parsed, err := url.Parse(original); err == nil {
+		if since := parsed.Query().Get("since"); since != "" {
+			query.Set("since", since)
+		}
+	}
+	if filter.App != "" {
+		query.Set("app", filter.App)
+	}
+	return "push://history?" + query.Encode()
+}
+
+func (s *Server) registerMessageResource() {
+	tmpl := &mcp.ResourceTemplate{
+		URITemplate: "push://message/{pushover_id}",
+		Name:        "Message",
+		Description: "A single persisted message by Pushover ID, including its full (possibly HTML) body and supplementary URL.",
+		MIMEType:    "application/json",
+	}
+
+	s.mcp.AddResourceTemplate(tmpl, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		rawID := strings.TrimPrefix(req.Params.URI, "push://message/")
+		id, err := strconv.ParseInt(rawID, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, mcp.ResourceNotFoundError(req.Params.URI)
+		}
+
+		ctx, cancel := s.store.op(ctx)
+		defer cancel()
+		record, found, err := s.store.GetMessage(ctx, id)
 		if err != nil {
 			return nil, err
 		}
+		if !found {
+			return nil, mcp.ResourceNotFoundError(req.Params.URI)
+		}
+
 		payload := ResourcePayload{
 			Metadata: ResourceMetadata{
 				Timestamp:   time.Now(),
-				ResourceURI: res.URI,
-				Count:       len(records),
+				ResourceURI: req.Params.URI,
+				Count:       1,
+			},
+			Data: record,
+			Links: map[string]string{
+				"history": "push://history",
 			},
-			Data: records,
+		}
+		if record.IconHash != "" {
+			payload.Links["icon"] = "push://media/" + record.IconHash
 		}
 		return buildResourceResult(req.Params.URI, payload)
 	})
 }
 
+func (s *Server) registerMediaResource() {
+	tmpl := &mcp.ResourceTemplate{
+		URITemplate: "push://media/{hash}",
+		Name:        "Cached media",
+		Description: "Binary content of a cached icon, addressed by the SHA-256 hash recorded on messages as IconHash.",
+	}
+
+	s.mcp.AddResourceTemplate(tmpl, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		hash := strings.TrimPrefix(req.Params.URI, "push://media/")
+		ctx, cancel := s.store.op(ctx)
+		defer cancel()
+		record, found, err := s.store.MediaByHash(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, mcp.ResourceNotFoundError(req.Params.URI)
+		}
+
+		data, err := os.ReadFile(record.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, mcp.ResourceNotFoundError(req.Params.URI)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read cached media: %w", err)
+		}
+
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{
+				URI:      req.Params.URI,
+				MIMEType: record.ContentType,
+				Blob:     data,
+			}},
+		}, nil
+	})
+}
+
 func (s *Server) registerStatusResource() {
 	res := &mcp.Resource{
 		URI:         "push://status",
 		Name:        "Push Status",
-		Description: "Credential and database health summary for the Push CLI.",
+		Description: "Credential and database health summary for the Push CLI, including when push daemon last maintained the database.",
 		MIMEType:    "application/json",
 	}
 
 	s.mcp.AddResource(res, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		cfg := s.cfg
+		cfg := s.config()
+		ctx, cancel := s.store.op(ctx)
+		defer cancel()
+		maintenance, err := s.store.MaintenanceStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
 		status := map[string]interface{}{
 			"config": map[string]interface{}{
 				"path":              s.cfgPath,
@@ -103,8 +293,10 @@ func (s *Server) registerStatusResource() {
 				"default_priority":  cfg.DefaultPriority,
 			},
 			"database": map[string]interface{}{
-				"path": s.dbPath,
+				"path":        s.dbPath,
+				"maintenance": maintenance,
 			},
+			"sessions":  s.sessionCount(),
 			"timestamp": time.Now(),
 		}
 
@@ -124,6 +316,36 @@ func (s *Server) registerStatusResource() {
 	})
 }
 
+// registerCatalogResource exposes a cached sound or device list, refreshed
+// from Pushover when older than a day.
+func (s *Server) registerCatalogResource(uri, name, description, kind string) {
+	res := &mcp.Resource{
+		URI:         uri,
+		Name:        name,
+		Description: description,
+		MIMEType:    "application/json",
+	}
+
+	s.mcp.AddResource(res, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		entries, err := catalog.Load(ctx, s.store.Store, s.newClient(), kind)
+		if err != nil {
+			return nil, err
+		}
+		if entries == nil {
+			entries = []db.CatalogEntry{}
+		}
+		payload := ResourcePayload{
+			Metadata: ResourceMetadata{
+				Timestamp:   time.Now(),
+				ResourceURI: res.URI,
+				Count:       len(entries),
+			},
+			Data: entries,
+		}
+		return buildResourceResult(req.Params.URI, payload)
+	})
+}
+
 func buildResourceResult(uri string, payload ResourcePayload) (*mcp.ReadResourceResult, error) {
 	bytes, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
@@ -0,0 +1,56 @@
+// ABOUTME: Tests for MCP resources.
+// ABOUTME: Reads the sound and device lists against the mock Pushover API.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCatalogResources(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	session := connect(t, &config.Config{
+		AppToken: pushovertest.AppToken,
+		UserKey:  pushovertest.UserKey,
+		APIURL:   srv.URL(),
+	}, nil)
+
+	read := func(uri string) []db.CatalogEntry {
+		t.Helper()
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			t.Fatalf("read %s: %v", uri, err)
+		}
+		var payload struct {
+			Data []db.CatalogEntry `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(result.Contents[0].Text), &payload); err != nil {
+			t.Fatalf("decode %s: %v", uri, err)
+		}
+		return payload.Data
+	}
+
+	sounds := read("push://sounds")
+	if len(sounds) != len(pushovertest.Sounds) {
+		t.Errorf("push://sounds has %d entries, want %d", len(sounds), len(pushovertest.Sounds))
+	}
+	for _, sound := range sounds {
+		if pushovertest.Sounds[sound.Name] != sound.Label {
+			t.Errorf("sound %q label = %q, want %q", sound.Name, sound.Label, pushovertest.Sounds[sound.Name])
+		}
+	}
+
+	devices := read("push://devices")
+	if len(devices) != 2 || devices[0].Name != "laptop" || devices[1].Name != "phone" {
+		t.Errorf("push://devices = %+v, want laptop and phone", devices)
+	}
+}
@@ -0,0 +1,89 @@
+// ABOUTME: MCP tool listing reply links and what recipients did with them.
+// ABOUTME: Reads the responses push serve records as links are tapped.
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/harper/push/internal/db"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const toolListResponses = "list_responses"
+
+var responseStatuses = []string{db.ResponseWaiting, db.ResponseOpened, db.ResponseAcknowledged, db.ResponseAnswered}
+
+type ListResponsesInput struct {
+	Limit  int    `json:"limit,omitempty"`
+	Status string `json:"status,omitempty"`
+	Token  string `json:"token,omitempty"`
+}
+
+type ListResponsesOutput struct {
+	Responses []db.ResponseRecord `json:"responses"`
+}
+
+func (s *Server) registerListResponsesTool() {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "Maximum number of reply links to return (default 20)",
+				"minimum":     1,
+			},
+			"status": map[string]any{
+				"type":        "string",
+				"enum":        responseStatuses,
+				"description": "Only reply links in this state",
+			},
+			"token": map[string]any{
+				"type":        "string",
+				"description": "Return just the reply link with this token",
+			},
+		},
+	}
+
+	addTool(s, &mcp.Tool{
+		Name:        toolListResponses,
+		Description: "List notifications sent with a reply link (push send --reply or --choices), newest first, with what the recipient did: waiting, opened, acknowledged, or answered with a choice.",
+		InputSchema: schema,
+	}, s.handleListResponses)
+}
+
+func (s *Server) handleListResponses(ctx context.Context, _ *mcp.CallToolRequest, input ListResponsesInput) (*mcp.CallToolResult, ListResponsesOutput, error) {
+	ctx, cancel := s.store.op(ctx)
+	defer cancel()
+	output := ListResponsesOutput{Responses: []db.ResponseRecord{}}
+	if token := strings.TrimSpace(input.Token); token != "" {
+		rec, found, err := s.store.ResponseByToken(ctx, token)
+		if err != nil {
+			return nil, ListResponsesOutput{}, err
+		}
+		if found {
+			output.Responses = append(output.Responses, rec)
+		}
+	} else {
+		if input.Status != "" && !slices.Contains(responseStatuses, input.Status) {
+			return nil, ListResponsesOutput{}, fmt.Errorf("status must be one of %s", strings.Join(responseStatuses, ", "))
+		}
+		filter := db.ResponseFilter{Limit: input.Limit, Status: input.Status}
+		if filter.Limit <= 0 {
+			filter.Limit = 20
+		}
+		records, err := s.store.ListResponses(ctx, filter)
+		if err != nil {
+			return nil, ListResponsesOutput{}, err
+		}
+		output.Responses = append(output.Responses, records...)
+	}
+
+	result, err := buildToolResult(output)
+	if err != nil {
+		return nil, output, err
+	}
+	return result, output, nil
+}
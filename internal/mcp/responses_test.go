@@ -0,0 +1,81 @@
+// ABOUTME: Tests for the list_responses tool.
+// ABOUTME: Records reply links in the store and reads them back by status and token.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestListResponses(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "push.db")
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	start := time.Now().Add(-time.Minute)
+	for i, rec := range []db.ResponseRecord{
+		{Token: "ack", Message: "Backups finished"},
+		{Token: "pick", Message: "Deploy?", Choices: []string{"Ship it", "Wait"}},
+	} {
+		rec.CreatedAt = start.Add(time.Duration(i) * time.Second)
+		if err := store.CreateResponse(ctx, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.RecordResponse(ctx, "pick", "Wait", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := NewServer(&config.Config{}, "", store, dbPath)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.mcp.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	list := func(args map[string]any) []db.ResponseRecord {
+		t.Helper()
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: toolListResponses, Arguments: args})
+		if err != nil || result.IsError {
+			t.Fatalf("list_responses(%v) = %v, %v", args, result, err)
+		}
+		var out ListResponsesOutput
+		if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out.Responses
+	}
+
+	if got := list(nil); len(got) != 2 || got[0].Token != "pick" {
+		t.Errorf("all responses = %+v, want both, newest first", got)
+	}
+	if got := list(map[string]any{"status": "answered"}); len(got) != 1 || got[0].Choice != "Wait" {
+		t.Errorf("answered responses = %+v", got)
+	}
+	if got := list(map[string]any{"token": "ack"}); len(got) != 1 || got[0].Status != db.ResponseWaiting {
+		t.Errorf("response by token = %+v", got)
+	}
+	if got := list(map[string]any{"token": "missing"}); len(got) != 0 {
+		t.Errorf("unknown token = %+v, want none", got)
+	}
+}
@@ -4,24 +4,47 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/harper/push/internal/config"
 	"github.com/harper/push/internal/db"
-	"github.com/harper/push/internal/pushover"
+	"github.com/harper/push/internal/media"
+	"github.com/harper/push/pkg/pushover"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // Server wraps the MCP runtime and Push integrations.
 type Server struct {
 	mcp     *mcp.Server
-	cfg     *config.Config
+	cfg     atomic.Pointer[config.Config]
 	cfgPath string
-	store   *db.Store
+	store   *storeManager
 	dbPath  string
+	logger  pushover.Logger
+	log     *slog.Logger
+	http    *http.Client
+	media   *media.Cache
+	limiter *sendLimiter
+	quota   *quotaCache
+	tools   []string
+	// askPoll overrides how often ask_human checks for an answer, for tests.
+	askPoll time.Duration
+
+	sessionsMu sync.Mutex
+	sessions   map[*mcp.ServerSession]*sessionState
 }
 
-// NewServer sets up the MCP server with all tools and resources.
+// NewServer sets up the MCP server with all tools and resources. Warnings go
+// to slog.Default(), alongside the MCP SDK's own logs.
 func NewServer(cfg *config.Config, cfgPath string, store *db.Store, dbPath string) (*Server, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config is required")
@@ -30,33 +53,148 @@ func NewServer(cfg *config.Config, cfgPath string, store *db.Store, dbPath strin
 		return nil, fmt.Errorf("database store is required")
 	}
 
-	impl := &mcp.Implementation{Name: "push", Version: "1.0.0"}
-	srv := mcp.NewServer(impl, nil)
+	httpClient, err := pushover.NewHTTPClient(pushover.HTTPClientOptions{
+		ProxyURL:   cfg.ProxyURL,
+		CACertPath: cfg.CACertPath,
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	server := &Server{
-		mcp:     srv,
-		cfg:     cfg,
 		cfgPath: cfgPath,
-		store:   store,
+		store:   newStoreManager(store),
 		dbPath:  dbPath,
+		log:     slog.Default(),
+		http:    httpClient,
+		limiter: newSendLimiter(cfg.MCPSendLimits()),
+		quota:   newQuotaCache(),
+
+		sessions: make(map[*mcp.ServerSession]*sessionState),
 	}
+	server.cfg.Store(cfg)
 
-	server.registerTools()
+	impl := &mcp.Implementation{Name: "push", Version: "1.0.0"}
+	server.mcp = mcp.NewServer(impl, &mcp.ServerOptions{
+		Logger:             slog.Default(),
+		InitializedHandler: server.trackSession,
+	})
+	server.mcp.AddReceivingMiddleware(traceHandlers)
+	if !cfg.NoMediaCache && dbPath != "" {
+		server.media = media.New(filepath.Join(filepath.Dir(dbPath), "cache"), store, httpClient, cfg.EffectiveAPIURL())
+	}
+
+	if err := server.registerTools(); err != nil {
+		return nil, err
+	}
 	server.registerResources()
 
 	return server, nil
 }
 
+// config returns the configuration in effect for the current request.
+func (s *Server) config() *config.Config {
+	return s.cfg.Load()
+}
+
+// SetConfig swaps in a reloaded configuration. Credentials, defaults, and send
+// limits apply from the next tool call. The tool list, proxy settings, and
+// media cache were fixed at startup, so their running values are kept and the
+// names of any that changed are returned.
+func (s *Server) SetConfig(cfg *config.Config) []string {
+	current := s.config()
+	next := cfg.Clone()
+
+	var restart []string
+	if !slices.Equal(next.MCP.EnabledTools, current.MCP.EnabledTools) {
+		restart = append(restart, "mcp.enabled_tools")
+	}
+	if next.MCP.ReadOnly != current.MCP.ReadOnly {
+		restart = append(restart, "mcp.read_only")
+	}
+	if next.ProxyURL != current.ProxyURL || next.CACertPath != current.CACertPath {
+		restart = append(restart, "proxy_url/ca_cert_path")
+	}
+	if next.NoMediaCache != current.NoMediaCache {
+		restart = append(restart, "disable_media_cache")
+	}
+	if next.DatabasePath != current.DatabasePath {
+		restart = append(restart, "database_path")
+	}
+	next.MCP.EnabledTools = current.MCP.EnabledTools
+	next.MCP.ReadOnly = current.MCP.ReadOnly
+	next.ProxyURL, next.CACertPath = current.ProxyURL, current.CACertPath
+	next.NoMediaCache = current.NoMediaCache
+	next.DatabasePath = current.DatabasePath
+
+	s.limiter.setLimits(next.MCPSendLimits())
+	s.cfg.Store(next)
+	return restart
+}
+
+// SetMediaCache replaces the icon cache NewServer keeps next to the database,
+// for when the database lives outside the data directory. Nil turns icon
+// downloads off. Call it before serving.
+func (s *Server) SetMediaCache(cache *media.Cache) {
+	s.media = cache
+}
+
+// Tools returns the names of the tools the server exposes.
+func (s *Server) Tools() []string {
+	return s.tools
+}
+
 // Serve starts the MCP server over stdio.
 func (s *Server) Serve(ctx context.Context) error {
 	transport := &mcp.StdioTransport{}
 	return s.mcp.Run(ctx, transport)
 }
 
+// ServeHTTP serves any number of concurrent sessions over the streamable HTTP
+// transport at http://addr/mcp until ctx is cancelled. Sessions share the
+// database and the server-wide send limit.
+func (s *Server) ServeHTTP(ctx context.Context, addr string) error {
+	httpServer := &http.Server{Addr: addr, Handler: s.httpHandler(), ReadHeaderTimeout: 10 * time.Second}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", addr, err)
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve http: %w", err)
+	}
+	return nil
+}
+
+// httpHandler routes /mcp to the streamable HTTP transport.
+func (s *Server) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return s.mcp }, nil))
+	return mux
+}
+
+// SetRequestLogger enables debug logging on every Pushover client the server creates.
+func (s *Server) SetRequestLogger(logger pushover.Logger) {
+	s.logger = logger
+}
+
 func (s *Server) newClient() *pushover.Client {
-	cfg := s.cfg
+	cfg := s.config()
+	var client *pushover.Client
 	if cfg == nil {
-		return pushover.NewClient("", "", "", "")
+		client = pushover.NewClient("", "", "", "")
+	} else {
+		client = pushover.NewClient(cfg.AppToken, cfg.UserKey, cfg.DeviceID, cfg.DeviceSecret)
+		client.SetBaseURL(cfg.EffectiveAPIURL())
 	}
-	return pushover.NewClient(cfg.AppToken, cfg.UserKey, cfg.DeviceID, cfg.DeviceSecret)
+	client.SetHTTPClient(s.http)
+	client.SetLogger(s.logger)
+	return client
 }
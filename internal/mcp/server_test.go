@@ -0,0 +1,69 @@
+// ABOUTME: Tests for swapping in a reloaded config on a running server.
+// ABOUTME: Checks new credentials apply while startup-only settings are kept.
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+)
+
+func TestSetConfig(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	dbPath := filepath.Join(t.TempDir(), "push.db")
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	server, err := NewServer(&config.Config{AppToken: "wrong", UserKey: pushovertest.UserKey, APIURL: srv.URL()}, "", store, dbPath)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	send := func() error {
+		_, _, err := server.handleSendNotification(ctx, nil, SendNotificationInput{Message: "hello"})
+		return err
+	}
+	if err := send(); err == nil {
+		t.Fatal("send with the wrong app token succeeded")
+	}
+
+	limit := 2
+	restart := server.SetConfig(&config.Config{
+		AppToken:        pushovertest.AppToken,
+		UserKey:         pushovertest.UserKey,
+		APIURL:          srv.URL(),
+		DefaultPriority: 1,
+		MCP:             config.MCPConfig{ReadOnly: true, SendLimit: &limit},
+	})
+	if !slices.Equal(restart, []string{"mcp.read_only"}) {
+		t.Errorf("SetConfig() restart = %v, want [mcp.read_only]", restart)
+	}
+	if server.config().MCP.ReadOnly {
+		t.Error("read_only applied without a restart")
+	}
+
+	if err := send(); err != nil {
+		t.Fatalf("send after reload: %v", err)
+	}
+	sent := srv.Sent()
+	if len(sent) != 1 || sent[0].Priority != 1 {
+		t.Errorf("sent %+v, want one message at the reloaded default priority 1", sent)
+	}
+
+	// Both attempts count against the reloaded limit of two sends per minute.
+	result, _, err := server.handleSendNotification(ctx, nil, SendNotificationInput{Message: "again"})
+	if err != nil || result == nil || !result.IsError {
+		t.Errorf("third send = %v, %v; want a rate_limited result", result, err)
+	}
+}
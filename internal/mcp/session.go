@@ -0,0 +1,77 @@
+// ABOUTME: Per-session state for MCP clients sharing one server.
+// ABOUTME: Tracks pending send confirmations and cleans up when a session ends.
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// confirmationTTL is how long a confirm: true retry may follow the refused send.
+const confirmationTTL = 10 * time.Minute
+
+// sessionState holds what one client session has been told, so retries are
+// matched to the session that saw the original refusal.
+type sessionState struct {
+	mu sync.Mutex
+	// confirmations maps sends that were refused pending human confirmation
+	// to when they were refused.
+	confirmations map[string]time.Time
+}
+
+// requestConfirmation remembers that key was refused pending confirmation.
+func (st *sessionState) requestConfirmation(key string, now time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.confirmations[key] = now
+}
+
+// takeConfirmation reports whether key was refused within confirmationTTL and
+// forgets it, so each confirmation covers one send.
+func (st *sessionState) takeConfirmation(key string, now time.Time) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	requested, ok := st.confirmations[key]
+	delete(st.confirmations, key)
+	return ok && now.Sub(requested) < confirmationTTL
+}
+
+// sessionFor returns the state for ss, creating it on first use. It returns
+// nil when there is no session, such as when handlers are called directly.
+func (s *Server) sessionFor(ss *mcp.ServerSession) *sessionState {
+	if ss == nil {
+		return nil
+	}
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	st, ok := s.sessions[ss]
+	if !ok {
+		st = &sessionState{confirmations: make(map[string]time.Time)}
+		s.sessions[ss] = st
+	}
+	return st
+}
+
+// trackSession registers a newly initialized session and drops its state,
+// including its rate limit window, once the client disconnects.
+func (s *Server) trackSession(_ context.Context, req *mcp.InitializedRequest) {
+	ss := req.Session
+	s.sessionFor(ss)
+	go func() {
+		_ = ss.Wait()
+		s.sessionsMu.Lock()
+		delete(s.sessions, ss)
+		s.sessionsMu.Unlock()
+		s.limiter.forget(ss)
+	}()
+}
+
+// sessionCount returns how many clients are connected.
+func (s *Server) sessionCount() int {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	return len(s.sessions)
+}
@@ -0,0 +1,101 @@
+// ABOUTME: Tests for serving several MCP sessions from one server over HTTP.
+// ABOUTME: Checks shared limits and that confirmations stay with their session.
+package mcp
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHTTPSessions(t *testing.T) {
+	api := pushovertest.NewServer()
+	defer api.Close()
+	ctx := context.Background()
+
+	dbPath := filepath.Join(t.TempDir(), "push.db")
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	sessionLimit, globalLimit := 2, 3
+	server, err := NewServer(&config.Config{
+		AppToken: pushovertest.AppToken,
+		UserKey:  pushovertest.UserKey,
+		APIURL:   api.URL(),
+		MCP:      config.MCPConfig{SessionSendLimit: &sessionLimit, SendLimit: &globalLimit},
+	}, "", store, dbPath)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	httpServer := httptest.NewServer(server.httpHandler())
+	defer httpServer.Close()
+
+	connectHTTP := func() *mcp.ClientSession {
+		t.Helper()
+		client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil)
+		session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: httpServer.URL + "/mcp"}, nil)
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		return session
+	}
+	a, b := connectHTTP(), connectHTTP()
+	defer func() { _ = a.Close() }()
+	defer func() { _ = b.Close() }()
+
+	send := func(session *mcp.ClientSession, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: toolSendNotification, Arguments: args})
+		if err != nil {
+			t.Fatalf("send_notification: %v", err)
+		}
+		return result
+	}
+	text := func(result *mcp.CallToolResult) string {
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	// A confirm: true retry only counts in the session that was refused.
+	emergency := map[string]any{"message": "datacenter on fire", "priority": 2}
+	if result := send(a, emergency); !result.IsError || !strings.Contains(text(result), "confirmation") {
+		t.Fatalf("unconfirmed emergency = %s, want confirmation error", text(result))
+	}
+	confirmed := map[string]any{"message": "datacenter on fire", "priority": 2, "confirm": true}
+	if result := send(b, confirmed); !result.IsError {
+		t.Error("confirm from another session was accepted")
+	}
+	if result := send(a, confirmed); result.IsError {
+		t.Errorf("confirmed emergency failed: %s", text(result))
+	}
+
+	// Session a has one send left and the server two.
+	if result := send(a, map[string]any{"message": "two"}); result.IsError {
+		t.Fatalf("second send from a failed: %s", text(result))
+	}
+	if result := send(a, map[string]any{"message": "three"}); !strings.Contains(text(result), `"limit_scope": "session"`) {
+		t.Errorf("third send from a = %s, want session limit", text(result))
+	}
+	if result := send(b, map[string]any{"message": "one"}); result.IsError {
+		t.Fatalf("first send from b failed: %s", text(result))
+	}
+	if result := send(b, map[string]any{"message": "two"}); !strings.Contains(text(result), `"limit_scope": "global"`) {
+		t.Errorf("second send from b = %s, want global limit", text(result))
+	}
+
+	if got := server.sessionCount(); got != 2 {
+		t.Errorf("sessionCount() = %d, want 2", got)
+	}
+	if sent := len(api.Sent()); sent != 3 {
+		t.Errorf("API received %d sends, want 3", sent)
+	}
+}
@@ -0,0 +1,110 @@
+// ABOUTME: MCP tool reporting aggregate statistics over message history.
+// ABOUTME: Mirrors push stats: per-day, per-app, per-priority, and hourly counts and top senders.
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const toolGetStats = "get_stats"
+
+type GetStatsInput struct {
+	Since *string `json:"since,omitempty"`
+	Top   int     `json:"top,omitempty"`
+}
+
+type GetStatsOutput struct {
+	Since         time.Time        `json:"since"`
+	Total         int              `json:"total"`
+	PerDay        []db.CountBucket `json:"per_day"`
+	PerApp        []db.CountBucket `json:"per_app"`
+	PerPriority   []db.CountBucket `json:"per_priority"`
+	Hourly        []db.CountBucket `json:"hourly"`
+	TopSenders    []db.SenderStats `json:"top_senders"`
+	AvgFetchDelay string           `json:"avg_fetch_delay,omitempty"`
+}
+
+func (s *Server) registerGetStatsTool() {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"since": map[string]any{
+				"type":        "string",
+				"description": "Start of the window, natural language or ISO date (default: 30 days ago)",
+			},
+			"top": map[string]any{
+				"type":        "integer",
+				"minimum":     1,
+				"description": "Number of top senders to return (default 10)",
+			},
+		},
+	}
+
+	addTool(s, &mcp.Tool{
+		Name:        toolGetStats,
+		Description: "Summarize message history like 'push stats': counts per day, app, priority, and hour of day, the busiest senders with their urgent counts and latest message time, and the average delay between Pushover accepting a message and push fetching it.",
+		InputSchema: schema,
+	}, s.handleGetStats)
+}
+
+func (s *Server) handleGetStats(ctx context.Context, _ *mcp.CallToolRequest, input GetStatsInput) (*mcp.CallToolResult, GetStatsOutput, error) {
+	since := time.Now().AddDate(0, 0, -30)
+	sincePtr := &since
+	if err := parseDateInput("since", input.Since, &sincePtr); err != nil {
+		return nil, GetStatsOutput{}, err
+	}
+	top := input.Top
+	if top <= 0 {
+		top = 10
+	}
+
+	ctx, cancel := s.store.op(ctx)
+	defer cancel()
+	output := GetStatsOutput{Since: *sincePtr}
+	perDay, err := s.store.CountByDay(ctx, sincePtr)
+	if err != nil {
+		return nil, output, err
+	}
+	perApp, err := s.store.CountByApp(ctx, sincePtr)
+	if err != nil {
+		return nil, output, err
+	}
+	perPriority, err := s.store.CountByPriority(ctx, sincePtr)
+	if err != nil {
+		return nil, output, err
+	}
+	hourly, err := s.store.HourlyHistogram(ctx, sincePtr)
+	if err != nil {
+		return nil, output, err
+	}
+	senders, err := s.store.TopSenders(ctx, sincePtr, top)
+	if err != nil {
+		return nil, output, err
+	}
+	delay, err := s.store.AverageFetchDelay(ctx, sincePtr)
+	if err != nil {
+		return nil, output, err
+	}
+
+	output.PerDay = append([]db.CountBucket{}, perDay...)
+	output.PerApp = append([]db.CountBucket{}, perApp...)
+	output.PerPriority = append([]db.CountBucket{}, perPriority...)
+	output.Hourly = append([]db.CountBucket{}, hourly...)
+	output.TopSenders = append([]db.SenderStats{}, senders...)
+	for _, bucket := range perDay {
+		output.Total += bucket.Count
+	}
+	if delay > 0 {
+		output.AvgFetchDelay = delay.String()
+	}
+
+	result, err := buildToolResult(output)
+	if err != nil {
+		return nil, output, err
+	}
+	return result, output, nil
+}
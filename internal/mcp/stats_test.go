@@ -0,0 +1,98 @@
+// ABOUTME: Tests for the get_stats tool.
+// ABOUTME: Seeds history and checks the window, totals, and top senders it reports.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// statsSession seeds three messages from the last few hours and one from
+// forty days ago, and returns a client connected to a server over them.
+func statsSession(t *testing.T, now time.Time) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "push.db")
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	if _, err := store.PersistMessages(ctx, []db.MessageRecord{
+		{PushoverID: 1, Message: "Backup done", App: "cron", ReceivedAt: now.Add(-time.Hour)},
+		{PushoverID: 2, Message: "Backup failed", App: "cron", Priority: 1, ReceivedAt: now.Add(-2 * time.Hour)},
+		{PushoverID: 3, Message: "Build green", App: "ci", ReceivedAt: now.Add(-3 * time.Hour)},
+		{PushoverID: 4, Message: "Old news", App: "ci", ReceivedAt: now.AddDate(0, 0, -40)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := NewServer(&config.Config{}, "", store, dbPath)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.mcp.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func getStats(t *testing.T, session *mcp.ClientSession, args map[string]any) GetStatsOutput {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: toolGetStats, Arguments: args})
+	if err != nil || result.IsError {
+		t.Fatalf("get_stats(%v) = %v, %v", args, result, err)
+	}
+	var out GetStatsOutput
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestGetStats(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	session := statsSession(t, now)
+
+	out := getStats(t, session, map[string]any{})
+	if out.Total != 3 || len(out.Hourly) != 24 || len(out.PerApp) != 2 {
+		t.Errorf("default window = %+v, want 3 messages from 2 apps over 24 hours", out)
+	}
+	if len(out.TopSenders) != 2 || out.TopSenders[0].App != "cron" || out.TopSenders[0].Count != 2 || out.TopSenders[0].Urgent != 1 || !out.TopSenders[0].LastAt.Equal(now.Add(-time.Hour)) {
+		t.Errorf("top senders = %+v, want cron first with 2 messages, 1 urgent", out.TopSenders)
+	}
+
+	out = getStats(t, session, map[string]any{"since": now.AddDate(0, 0, -60).Format(time.RFC3339), "top": 1})
+	if out.Total != 4 || len(out.TopSenders) != 1 || out.TopSenders[0].App != "ci" {
+		t.Errorf("60 day window, top 1 = %+v, want 4 messages and ci alone", out)
+	}
+
+	out = getStats(t, session, map[string]any{"since": now.Add(time.Hour).Format(time.RFC3339)})
+	if out.Total != 0 || out.PerDay == nil || out.TopSenders == nil {
+		t.Errorf("empty window = %+v, want no messages and empty lists", out)
+	}
+}
+
+func TestGetStatsRejectsBadSince(t *testing.T) {
+	session := statsSession(t, time.Now())
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: toolGetStats, Arguments: map[string]any{"since": "not a date"}})
+	if err != nil || !result.IsError {
+		t.Errorf("bad since = %v, %v; want a tool error", result, err)
+	}
+}
@@ -0,0 +1,50 @@
+// ABOUTME: The store the MCP server opens at startup and shares between concurrent tool calls.
+// ABOUTME: Gives each database operation its own deadline and keeps records of finished work from being cancelled.
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+// storeTimeout bounds one database operation for a tool call or resource
+// read, so a database another process holds locked fails the call instead of
+// hanging it.
+const storeTimeout = 30 * time.Second
+
+// storeManager is the server's one store, open for its lifetime and shared by
+// every session, where the CLI opens and closes one per command. Writes queue
+// on the store's single write connection, and statements that find the
+// database locked, say by push daemon, are retried (see db.IsBusy); op and
+// record give each use its own context.
+type storeManager struct {
+	*db.Store
+	timeout time.Duration
+}
+
+func newStoreManager(store *db.Store) *storeManager {
+	return &storeManager{Store: store, timeout: storeTimeout}
+}
+
+// op returns the context for a database operation on behalf of ctx, which
+// ends with ctx or after the timeout.
+func (m *storeManager) op(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, m.timeout)
+}
+
+// record returns the context for saving the outcome of work already done,
+// such as a notification sent or messages fetched and acknowledged. It isn't
+// cancelled with ctx, so a client giving up on the call doesn't lose the
+// record, but it still times out.
+func (m *storeManager) record(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), m.timeout)
+}
+
+// logSent records a send (or suppressed send) that has already happened.
+func (s *Server) logSent(ctx context.Context, record db.SentRecord) error {
+	ctx, cancel := s.store.record(ctx)
+	defer cancel()
+	return s.store.LogSent(ctx, record)
+}
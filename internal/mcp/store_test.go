@@ -0,0 +1,63 @@
+// ABOUTME: Tests for the shared store's per-operation contexts.
+// ABOUTME: Checks operations get a deadline and records of finished work outlive a cancelled call.
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+)
+
+func TestStoreManagerContexts(t *testing.T) {
+	m := newStoreManager(nil)
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	op, cancelOp := m.op(parent)
+	defer cancelOp()
+	if _, ok := op.Deadline(); !ok {
+		t.Fatal("op context has no deadline")
+	}
+	rec, cancelRec := m.record(parent)
+	defer cancelRec()
+	if _, ok := rec.Deadline(); !ok {
+		t.Fatal("record context has no deadline")
+	}
+
+	cancelParent()
+	if op.Err() == nil {
+		t.Fatal("op context outlived its parent")
+	}
+	if rec.Err() != nil {
+		t.Fatalf("record context cancelled with its parent: %v", rec.Err())
+	}
+}
+
+func TestLogSentAfterCancel(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "push.db")
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	server, err := NewServer(&config.Config{}, "", store, dbPath)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := server.logSent(ctx, db.SentRecord{Message: "deploy done", SentAt: time.Now()}); err != nil {
+		t.Fatalf("logSent: %v", err)
+	}
+	sent, err := store.ListSent(context.Background(), db.SentFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("got %d sent records, want 1", len(sent))
+	}
+}
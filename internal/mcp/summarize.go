@@ -0,0 +1,196 @@
+// ABOUTME: summarize_unread tool: triages unread messages in one call.
+// ABOUTME: Asks the client's model for a summary via MCP sampling.
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/messages"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	summarizeDefaultMessages = 50
+	summarizeMaxTokens       = 500
+	summarizeSystemPrompt    = "You triage push notifications. Summarize them briefly: group related messages, call out anything urgent or needing action first, and skip noise. Plain text, no preamble."
+)
+
+type SummarizeUnreadInput struct {
+	MarkRead     bool   `json:"mark_read,omitempty"`
+	MaxMessages  *int   `json:"max_messages,omitempty"`
+	Instructions string `json:"instructions,omitempty"`
+}
+
+type SummarizeUnreadOutput struct {
+	Count      int    `json:"count"`
+	Summarized int    `json:"summarized"`
+	Summary    string `json:"summary"`
+	Model      string `json:"model,omitempty"`
+	Persisted  int    `json:"persisted"`
+	HighestID  int64  `json:"highest_id"`
+	AckedUpTo  int64  `json:"acked_up_to,omitempty"`
+	Warning    string `json:"warning,omitempty"`
+	AckWarning string `json:"ack_warning,omitempty"`
+}
+
+func (s *Server) registerSummarizeUnreadTool() {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"mark_read": map[string]any{
+				"type":        "boolean",
+				"description": "Delete the summarized messages from Pushover once the summary is ready. Defaults to false.",
+			},
+			"max_messages": map[string]any{
+				"type":        "integer",
+				"minimum":     1,
+				"description": "Summarize at most this many of the newest messages (default 50).",
+			},
+			"instructions": map[string]any{
+				"type":        "string",
+				"description": "Extra guidance for the summary, e.g. 'only mention failures'.",
+			},
+		},
+	}
+
+	addTool(s, &mcp.Tool{
+		Name:        toolSummarizeUnread,
+		Description: "Fetch unread Pushover messages, persist them, and summarize them using the client's model (MCP sampling). Optionally mark them read. Requires a client that supports sampling.",
+		InputSchema: schema,
+	}, s.handleSummarizeUnread)
+}
+
+func (s *Server) handleSummarizeUnread(ctx context.Context, req *mcp.CallToolRequest, input SummarizeUnreadInput) (*mcp.CallToolResult, SummarizeUnreadOutput, error) {
+	if err := s.config().ValidateReceive(); err != nil {
+		return nil, SummarizeUnreadOutput{}, err
+	}
+	if input.MarkRead && s.config().MCP.ReadOnly {
+		return nil, SummarizeUnreadOutput{}, fmt.Errorf("server is read-only; mark_read is disabled")
+	}
+	if !supportsSampling(req) {
+		return nil, SummarizeUnreadOutput{}, fmt.Errorf("client does not support sampling; use check_messages or the push://unread resource instead")
+	}
+
+	limit := summarizeDefaultMessages
+	if input.MaxMessages != nil && *input.MaxMessages > 0 {
+		limit = *input.MaxMessages
+	}
+
+	rules, err := s.config().LocalPriorities()
+	if err != nil {
+		return nil, SummarizeUnreadOutput{}, err
+	}
+	client := s.newClient()
+	result, err := client.FetchMessages(ctx)
+	if err != nil {
+		return nil, SummarizeUnreadOutput{}, err
+	}
+	messages.ApplyLocalPriorities(rules, result.Messages)
+
+	output := SummarizeUnreadOutput{Count: len(result.Messages), HighestID: determineAckID(result)}
+	recordCtx, cancel := s.store.record(ctx)
+	persisted, persistErr := messages.PersistReceived(recordCtx, s.store.Store, s.config().ReceivingDevice(), result.Messages)
+	cancel()
+	output.Persisted = persisted
+	if persistErr != nil {
+		s.report(ctx, sessionOf(req), mcp.LevelWarning, "failed to persist messages", "error", persistErr)
+		output.Warning = persistErr.Error()
+	}
+
+	if len(result.Messages) == 0 {
+		output.Summary = "No unread messages."
+	} else if err := summarize(ctx, req, result.Messages, limit, input.Instructions, &output); err != nil {
+		return nil, SummarizeUnreadOutput{}, err
+	}
+	if input.MarkRead {
+		s.markSummarizedRead(ctx, req, client, &output)
+	}
+
+	resultPayload, err := buildToolResult(output)
+	if err != nil {
+		return nil, output, err
+	}
+	return resultPayload, output, nil
+}
+
+// summarize asks the client's model to summarize the newest limit of msgs.
+func summarize(ctx context.Context, req *mcp.CallToolRequest, msgs []pushover.ReceivedMessage, limit int, instructions string, output *SummarizeUnreadOutput) error {
+	batch := msgs
+	if len(batch) > limit {
+		batch = batch[len(batch)-limit:]
+	}
+	sampled, err := req.Session.CreateMessage(ctx, &mcp.CreateMessageParams{
+		Messages: []*mcp.SamplingMessage{{
+			Role:    "user",
+			Content: &mcp.TextContent{Text: summaryPrompt(batch, len(msgs), instructions)},
+		}},
+		SystemPrompt: summarizeSystemPrompt,
+		MaxTokens:    summarizeMaxTokens,
+	})
+	if err != nil {
+		return fmt.Errorf("request summary: %w", err)
+	}
+	text, ok := sampled.Content.(*mcp.TextContent)
+	if !ok {
+		return fmt.Errorf("request summary: client returned %T, want text", sampled.Content)
+	}
+	output.Summarized = len(batch)
+	output.Summary = strings.TrimSpace(text.Text)
+	output.Model = sampled.Model
+	return nil
+}
+
+// markSummarizedRead acknowledges the fetched messages. Only messages that
+// made it into the summary are acknowledged, so a truncated batch never
+// deletes anything the summary left out.
+func (s *Server) markSummarizedRead(ctx context.Context, req *mcp.CallToolRequest, client *pushover.Client, output *SummarizeUnreadOutput) {
+	if output.Summarized < output.Count {
+		output.AckWarning = fmt.Sprintf("summarized %d of %d messages; none marked read (raise max_messages)", output.Summarized, output.Count)
+		return
+	}
+	if output.HighestID <= 0 {
+		return
+	}
+	if err := client.DeleteMessages(ctx, output.HighestID); err != nil {
+		s.report(ctx, sessionOf(req), mcp.LevelWarning, "unable to ack messages", "up_to", output.HighestID, "error", err)
+		output.AckWarning = err.Error()
+		return
+	}
+	output.AckedUpTo = output.HighestID
+}
+
+// summaryPrompt lists msgs oldest first, one per line, for the sampling request.
+func summaryPrompt(msgs []pushover.ReceivedMessage, total int, instructions string) string {
+	var b strings.Builder
+	if len(msgs) < total {
+		fmt.Fprintf(&b, "The newest %d of %d unread push notifications:\n\n", len(msgs), total)
+	} else {
+		fmt.Fprintf(&b, "%d unread push notifications:\n\n", total)
+	}
+	for _, msg := range msgs {
+		fmt.Fprintf(&b, "- [%s] %s", time.Unix(msg.Date, 0).Format(time.RFC3339), msg.App)
+		if msg.Priority != 0 {
+			fmt.Fprintf(&b, " (priority %d)", msg.Priority)
+		}
+		if msg.Title != "" {
+			fmt.Fprintf(&b, " %s:", msg.Title)
+		}
+		fmt.Fprintf(&b, " %s\n", strings.ReplaceAll(msg.Message, "\n", " "))
+	}
+	if instructions != "" {
+		fmt.Fprintf(&b, "\nInstructions: %s\n", instructions)
+	}
+	return b.String()
+}
+
+func supportsSampling(req *mcp.CallToolRequest) bool {
+	if req == nil || req.Session == nil {
+		return false
+	}
+	params := req.Session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Sampling != nil
+}
@@ -0,0 +1,72 @@
+// ABOUTME: Tests for the summarize_unread tool.
+// ABOUTME: Uses a sampling client stub and the mock Pushover API.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSummarizeUnread(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	cfg := &config.Config{
+		AppToken:     pushovertest.AppToken,
+		UserKey:      pushovertest.UserKey,
+		DeviceID:     pushovertest.DeviceID,
+		DeviceSecret: pushovertest.LoginSecret,
+		APIURL:       srv.URL(),
+	}
+
+	srv.Deliver(pushover.ReceivedMessage{App: "CI", Title: "Build", Message: "main failed", Priority: 1})
+	srv.Deliver(pushover.ReceivedMessage{App: "Backups", Message: "nightly ok"})
+
+	var prompt string
+	session := connect(t, cfg, &mcp.ClientOptions{
+		CreateMessageHandler: func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			prompt = req.Params.Messages[0].Content.(*mcp.TextContent).Text
+			return &mcp.CreateMessageResult{Model: "stub", Role: "assistant", Content: &mcp.TextContent{Text: "CI failed on main; backups fine."}}, nil
+		},
+	})
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: toolSummarizeUnread, Arguments: map[string]any{"mark_read": true, "instructions": "be terse"}})
+	if err != nil || result.IsError {
+		t.Fatalf("summarize_unread: %v %+v", err, result)
+	}
+	var out SummarizeUnreadOutput
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+
+	if out.Count != 2 || out.Summarized != 2 || out.Summary != "CI failed on main; backups fine." || out.Model != "stub" {
+		t.Errorf("output = %+v", out)
+	}
+	if out.AckedUpTo != out.HighestID || len(srv.Pending()) != 0 {
+		t.Errorf("acked up to %d of %d, %d still pending", out.AckedUpTo, out.HighestID, len(srv.Pending()))
+	}
+	for _, want := range []string{"CI (priority 1) Build: main failed", "Backups nightly ok", "Instructions: be terse"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestSummarizeUnreadWithoutSampling(t *testing.T) {
+	session := connect(t, &config.Config{AppToken: "app", UserKey: "user", DeviceID: "device", DeviceSecret: "secret"}, nil)
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: toolSummarizeUnread})
+	if err != nil {
+		t.Fatalf("summarize_unread: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "sampling") {
+		t.Errorf("result = %+v, want a sampling error", result)
+	}
+}
@@ -0,0 +1,72 @@
+// ABOUTME: MCP tool listing the notifications in a thread, such as one incident's.
+// ABOUTME: Merges sent and received notifications that share a thread key, oldest first.
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/harper/push/internal/db"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const toolListThread = "list_thread"
+
+type ListThreadInput struct {
+	Thread string `json:"thread"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+type ListThreadOutput struct {
+	Thread  string           `json:"thread"`
+	Entries []db.ThreadEntry `json:"entries"`
+}
+
+func (s *Server) registerListThreadTool() {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"thread": map[string]any{
+				"type":        "string",
+				"description": "Thread key given to send_notification or push send --thread",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "Maximum number of notifications to return, the latest ones (default 50)",
+				"minimum":     1,
+			},
+		},
+		"required": []string{"thread"},
+	}
+
+	addTool(s, &mcp.Tool{
+		Name:        toolListThread,
+		Description: "List the sent and received notifications in a thread, oldest first, to follow an incident from start to finish. Received messages without a thread join one when their title matches a threaded notification from the last day.",
+		InputSchema: schema,
+	}, s.handleListThread)
+}
+
+func (s *Server) handleListThread(ctx context.Context, _ *mcp.CallToolRequest, input ListThreadInput) (*mcp.CallToolResult, ListThreadOutput, error) {
+	key := strings.TrimSpace(input.Thread)
+	if key == "" {
+		return nil, ListThreadOutput{}, errors.New("thread is required")
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	storeCtx, cancel := s.store.op(ctx)
+	defer cancel()
+	entries, err := s.store.Thread(storeCtx, key, limit)
+	if err != nil {
+		return nil, ListThreadOutput{}, err
+	}
+	output := ListThreadOutput{Thread: key, Entries: append([]db.ThreadEntry{}, entries...)}
+
+	result, err := buildToolResult(output)
+	if err != nil {
+		return nil, output, err
+	}
+	return result, output, nil
+}
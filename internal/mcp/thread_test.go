@@ -0,0 +1,99 @@
+// ABOUTME: Tests for the list_thread tool.
+// ABOUTME: Threads a send and checks which received messages join it by title.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestListThread(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "push.db")
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now().Truncate(time.Second)
+	if err := store.LogSent(ctx, db.SentRecord{Message: "Primary is down", Title: "DB outage", SentAt: now.Add(-time.Hour), Thread: "inc-42"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.PersistMessages(ctx, []db.MessageRecord{
+		{PushoverID: 1, Message: "Old outage", Title: "DB outage", App: "monitor", ReceivedAt: now.Add(-48 * time.Hour)},
+		{PushoverID: 2, Message: "Failover started", Title: "db outage", App: "monitor", ReceivedAt: now.Add(-30 * time.Minute)},
+		{PushoverID: 3, Message: "Disk at 80%", Title: "Disk", App: "monitor", ReceivedAt: now.Add(-20 * time.Minute)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// A day on, the send is out of the window, so only message 2 from the
+	// same app can lend its thread.
+	if _, err := store.PersistMessages(ctx, []db.MessageRecord{
+		{PushoverID: 4, Message: "Recovered", Title: "DB outage", App: "monitor", ReceivedAt: now.Add(23*time.Hour + 15*time.Minute)},
+		{PushoverID: 5, Message: "Recovered", Title: "DB outage", App: "other", ReceivedAt: now.Add(23*time.Hour + 20*time.Minute)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := NewServer(&config.Config{}, "", store, dbPath)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.mcp.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	list := func(args map[string]any) []db.ThreadEntry {
+		t.Helper()
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: toolListThread, Arguments: args})
+		if err != nil || result.IsError {
+			t.Fatalf("list_thread(%v) = %v, %v", args, result, err)
+		}
+		var out ListThreadOutput
+		if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out.Entries
+	}
+
+	got := list(map[string]any{"thread": "inc-42"})
+	want := []struct {
+		kind string
+		id   int64
+	}{{db.ThreadSent, 1}, {db.ThreadReceived, 2}, {db.ThreadReceived, 4}}
+	if len(got) != len(want) {
+		t.Fatalf("thread = %+v, want %v", got, want)
+	}
+	for i, w := range want {
+		if got[i].Kind != w.kind || got[i].ID != w.id {
+			t.Errorf("entry %d = %s %d, want %s %d", i, got[i].Kind, got[i].ID, w.kind, w.id)
+		}
+	}
+
+	if got := list(map[string]any{"thread": "inc-42", "limit": 1}); len(got) != 1 || got[0].ID != 4 {
+		t.Errorf("limited thread = %+v, want only the latest", got)
+	}
+	if got := list(map[string]any{"thread": "missing"}); len(got) != 0 {
+		t.Errorf("unknown thread = %+v, want none", got)
+	}
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: toolListThread, Arguments: map[string]any{"thread": " "}})
+	if err != nil || !result.IsError {
+		t.Errorf("blank thread = %v, %v; want a tool error", result, err)
+	}
+}
@@ -5,22 +5,83 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/araddon/dateparse"
+	"github.com/harper/push/internal/config"
 	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/hints"
 	"github.com/harper/push/internal/messages"
-	"github.com/harper/push/internal/pushover"
+	"github.com/harper/push/internal/notify"
+	"github.com/harper/push/pkg/pushover"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func (s *Server) registerTools() {
+// Tool names, as accepted by mcp.enabled_tools.
+const (
+	toolSendNotification = "send_notification"
+	toolCheckMessages    = "check_messages"
+	toolListHistory      = "list_history"
+	toolMarkRead         = "mark_read"
+	toolDailyDigest      = "daily_digest"
+	toolCheckLimits      = "check_limits"
+	toolSummarizeUnread  = "summarize_unread"
+)
+
+// knownTools lists every tool the server can expose.
+var knownTools = []string{toolSendNotification, toolCheckMessages, toolListHistory, toolMarkRead, toolDailyDigest, toolCheckLimits, toolSummarizeUnread, toolGetReceiptStatus, toolAskHuman, toolListResponses, toolCreateReminder, toolListThread, toolGetStats}
+
+// writeTools send, schedule, or delete notifications and are hidden in read-only mode.
+var writeTools = []string{toolSendNotification, toolMarkRead, toolAskHuman, toolCreateReminder}
+
+func (s *Server) registerTools() error {
+	for _, name := range s.config().MCP.EnabledTools {
+		if !slices.Contains(knownTools, name) {
+			return fmt.Errorf("unknown tool %q in mcp.enabled_tools (known: %s)", name, strings.Join(knownTools, ", "))
+		}
+	}
+
 	s.registerSendNotificationTool()
 	s.registerCheckMessagesTool()
 	s.registerListHistoryTool()
 	s.registerMarkReadTool()
+	s.registerDailyDigestTool()
+	s.registerCheckLimitsTool()
+	s.registerSummarizeUnreadTool()
+	s.registerGetReceiptStatusTool()
+	s.registerAskHumanTool()
+	s.registerListResponsesTool()
+	s.registerCreateReminderTool()
+	s.registerListThreadTool()
+	s.registerGetStatsTool()
+	return nil
+}
+
+// toolEnabled reports whether name passes mcp.enabled_tools and read-only mode.
+func (s *Server) toolEnabled(name string) bool {
+	cfg := s.config()
+	if cfg.MCP.ReadOnly && slices.Contains(writeTools, name) {
+		return false
+	}
+	return len(cfg.MCP.EnabledTools) == 0 || slices.Contains(cfg.MCP.EnabledTools, name)
+}
+
+// addTool registers tool unless configuration disables it. Errors the handler
+// returns carry a remediation hint when one applies.
+func addTool[In, Out any](s *Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	if !s.toolEnabled(tool.Name) {
+		return
+	}
+	mcp.AddTool(s.mcp, tool, func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, Out, error) {
+		result, out, err := handler(ctx, req, in)
+		return result, out, hints.Wrap(err)
+	})
+	s.tools = append(s.tools, tool.Name)
 }
 
 func (s *Server) registerSendNotificationTool() {
@@ -47,18 +108,44 @@ func (s *Server) registerSendNotificationTool() {
 			},
 			"sound": map[string]any{
 				"type":        "string",
-				"description": "Notification sound",
+				"description": "Notification sound. Valid names are listed by the push://sounds resource.",
 			},
 			"device": map[string]any{
 				"type":        "string",
-				"description": "Target device name. Defaults to config's default_device.",
+				"description": "Target device name. Defaults to config's default_device; valid names are listed by the push://devices resource.",
+			},
+			"via": map[string]any{
+				"type":        "string",
+				"enum":        notify.Backends(),
+				"description": "Send backend. Defaults to config's default_via (pushover).",
+			},
+			"app": map[string]any{
+				"type":        "string",
+				"description": "Name of an [apps] entry whose token to send with, so the notification shows under that application. Defaults to app_token.",
+			},
+			"confirm": map[string]any{
+				"type":        "boolean",
+				"description": "Set only after a human explicitly approved a high-priority (emergency by default) send. Clients with elicitation support are asked directly instead.",
+			},
+			"ttl": map[string]any{
+				"type":        "string",
+				"description": "Expire the notification from devices after this Go duration (e.g. \"30m\"). Ignored for emergency priority.",
+			},
+			"long_message": map[string]any{
+				"type":        "string",
+				"enum":        []string{"error", "truncate", "split"},
+				"description": "What to do with messages over 1024 characters: fail (default unless configured), truncate with an ellipsis, or split into numbered parts.",
+			},
+			"thread": map[string]any{
+				"type":        "string",
+				"description": "Key grouping this notification with related ones, such as an incident ID; list_thread returns them together.",
 			},
 		},
 		"required": []string{"message"},
 	}
 
-	mcp.AddTool(s.mcp, &mcp.Tool{
-		Name:        "send_notification",
+	addTool(s, &mcp.Tool{
+		Name:        toolSendNotification,
 		Description: "Send a push notification through Pushover, mirroring the CLI 'send' command.",
 		InputSchema: schema,
 	}, s.handleSendNotification)
@@ -73,12 +160,30 @@ func (s *Server) registerCheckMessagesTool() {
 				"minimum":     1,
 				"description": "Maximum number of messages to return in the response. Defaults to 10.",
 			},
+			"ack": map[string]any{
+				"type":        "boolean",
+				"description": "Delete fetched messages from Pushover after persisting them. Defaults to config's mcp.auto_ack (true). Pass false to peek, then call mark_read with highest_id.",
+			},
+			"offset": map[string]any{
+				"type":        "integer",
+				"minimum":     0,
+				"description": "Skip this many fetched messages before the ones returned. They're still persisted and acknowledged.",
+			},
+			"cursor": map[string]any{
+				"type":        "string",
+				"description": "next_cursor from a truncated response: return the rest of that fetch from local history, without fetching or acknowledging again.",
+			},
+			"max_body_chars": map[string]any{
+				"type":        "integer",
+				"minimum":     0,
+				"description": "Cut each message body to this many characters, setting body_truncated. Defaults to config's mcp.max_body_chars; 0 means no limit.",
+			},
 		},
 	}
 
-	mcp.AddTool(s.mcp, &mcp.Tool{
-		Name:        "check_messages",
-		Description: "Poll the Pushover Open Client API, persist new messages, and return the newest ones.",
+	addTool(s, &mcp.Tool{
+		Name:        toolCheckMessages,
+		Description: "Poll the Pushover Open Client API, persist new messages, and return the newest ones. Set ack=false to leave them on the server for other clients. A response that doesn't hold every message is truncated; pass its next_cursor as cursor for the rest.",
 		InputSchema: schema,
 	}, s.handleCheckMessages)
 }
@@ -96,20 +201,79 @@ func (s *Server) registerListHistoryTool() {
 				"type":        "string",
 				"description": "Natural language or ISO date filter (e.g. 'yesterday', '2025-01-01').",
 			},
+			"until": map[string]any{
+				"type":        "string",
+				"description": "Only messages received before this natural language or ISO date.",
+			},
 			"search": map[string]any{
 				"type":        "string",
 				"description": "Full text search over message and title fields.",
 			},
+			"regex": map[string]any{
+				"type":        "string",
+				"description": "Go regular expression matched against message and title (e.g. 'error (5\\d\\d)').",
+			},
+			"app": map[string]any{
+				"type":        "string",
+				"description": "Only messages from this application name.",
+			},
+			"min_priority": map[string]any{
+				"type":        "integer",
+				"minimum":     -2,
+				"maximum":     2,
+				"description": "Only messages at or above this priority.",
+			},
+			"device": map[string]any{
+				"type":        "string",
+				"description": "Only messages received on this device.",
+			},
+			"has_url": map[string]any{
+				"type":        "boolean",
+				"description": "Only messages with a supplementary URL.",
+			},
+			"archived": map[string]any{
+				"type":        "boolean",
+				"description": "Include messages archived with push archive, which are otherwise left out.",
+			},
+			"max_body_chars": map[string]any{
+				"type":        "integer",
+				"minimum":     0,
+				"description": "Cut each message body to this many characters, setting body_truncated. Defaults to config's mcp.max_body_chars; 0 means no limit.",
+			},
 		},
 	}
 
-	mcp.AddTool(s.mcp, &mcp.Tool{
-		Name:        "list_history",
+	addTool(s, &mcp.Tool{
+		Name:        toolListHistory,
 		Description: "Query persisted message history from the local SQLite database.",
 		InputSchema: schema,
 	}, s.handleListHistory)
 }
 
+func (s *Server) registerDailyDigestTool() {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"since": map[string]any{
+				"type":        "string",
+				"description": "Start of the digest window as a natural language or ISO date. Defaults to 24 hours ago.",
+			},
+			"min_priority": map[string]any{
+				"type":        "integer",
+				"minimum":     -2,
+				"maximum":     2,
+				"description": "Only count messages at or above this priority.",
+			},
+		},
+	}
+
+	addTool(s, &mcp.Tool{
+		Name:        toolDailyDigest,
+		Description: "Summarize recent message history per app: message count and latest message, busiest apps first.",
+		InputSchema: schema,
+	}, s.handleDailyDigest)
+}
+
 func (s *Server) registerMarkReadTool() {
 	schema := map[string]any{
 		"type": "object",
@@ -123,212 +287,610 @@ func (s *Server) registerMarkReadTool() {
 		"required": []string{"message_id"},
 	}
 
-	mcp.AddTool(s.mcp, &mcp.Tool{
-		Name:        "mark_read",
+	addTool(s, &mcp.Tool{
+		Name:        toolMarkRead,
 		Description: "Delete unread messages from Pushover up to (and including) the provided ID.",
 		InputSchema: schema,
 	}, s.handleMarkRead)
 }
 
 type SendNotificationInput struct {
-	Message  string `json:"message"`
-	Title    string `json:"title,omitempty"`
-	Priority *int   `json:"priority,omitempty"`
-	URL      string `json:"url,omitempty"`
-	Sound    string `json:"sound,omitempty"`
-	Device   string `json:"device,omitempty"`
+	Message     string `json:"message"`
+	Title       string `json:"title,omitempty"`
+	Priority    *int   `json:"priority,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Sound       string `json:"sound,omitempty"`
+	Device      string `json:"device,omitempty"`
+	Via         string `json:"via,omitempty"`
+	App         string `json:"app,omitempty"`
+	Confirm     bool   `json:"confirm,omitempty"`
+	LongMessage string `json:"long_message,omitempty"`
+	TTL         string `json:"ttl,omitempty"`
+	Thread      string `json:"thread,omitempty"`
 }
 
 type SendNotificationOutput struct {
-	Message   string `json:"message"`
-	Title     string `json:"title,omitempty"`
-	Device    string `json:"device,omitempty"`
-	Priority  int    `json:"priority"`
-	RequestID string `json:"request_id"`
-	Receipt   string `json:"receipt,omitempty"`
-	Logged    bool   `json:"logged"`
-	Warning   string `json:"warning,omitempty"`
-}
-
-func (s *Server) handleSendNotification(ctx context.Context, _ *mcp.CallToolRequest, input SendNotificationInput) (*mcp.CallToolResult, SendNotificationOutput, error) {
-	if err := s.cfg.ValidateSend(); err != nil {
+	Message    string   `json:"message"`
+	Title      string   `json:"title,omitempty"`
+	Device     string   `json:"device,omitempty"`
+	Priority   int      `json:"priority"`
+	Via        string   `json:"via"`
+	RequestID  string   `json:"request_id"`
+	RequestIDs []string `json:"request_ids,omitempty"`
+	Parts      int      `json:"parts,omitempty"`
+	Receipt    string   `json:"receipt,omitempty"`
+	Logged     bool     `json:"logged"`
+	Suppressed bool     `json:"suppressed,omitempty"`
+	Repeats    int      `json:"repeats,omitempty"`
+	Warning    string   `json:"warning,omitempty"`
+	// Error is "rate_limited" when a send limit refused the call; retry after
+	// RetryAfter seconds. LimitScope says which limit: session, global, or shared.
+	Error      string `json:"error,omitempty"`
+	RetryAfter int    `json:"retry_after,omitempty"`
+	LimitScope string `json:"limit_scope,omitempty"`
+}
+
+func (s *Server) handleSendNotification(ctx context.Context, req *mcp.CallToolRequest, input SendNotificationInput) (*mcp.CallToolResult, SendNotificationOutput, error) {
+	plan, err := s.planSend(ctx, req, input)
+	if err != nil {
+		return nil, SendNotificationOutput{}, err
+	}
+
+	if plan.window > 0 {
+		suppressed, err := s.suppressDuplicate(ctx, req, plan)
+		if err != nil {
+			return nil, SendNotificationOutput{}, err
+		}
+		if suppressed {
+			return toolResult(plan.output)
+		}
+	}
+
+	parts, err := messages.FitMessage(plan.params.Message, plan.longMessages)
+	if err != nil {
+		return nil, SendNotificationOutput{}, fmt.Errorf("%w; shorten it or set long_message to split or truncate", err)
+	}
+	wait, err := plan.cfg.RateLimitWaits()
+	if err != nil {
 		return nil, SendNotificationOutput{}, err
 	}
+	if err := s.limiter.tooMany(len(parts)); err != nil {
+		return nil, SendNotificationOutput{}, fmt.Errorf("message splits into too many parts: %w; shorten it or set long_message to truncate", err)
+	}
+	if scope, retry, ok := s.limiter.reserve(sessionOf(req), len(parts), time.Now()); !ok {
+		return s.rateLimited(ctx, req, plan.output, scope, retry)
+	}
+	return s.sendParts(ctx, req, plan, parts, wait)
+}
+
+// sendPlan is a validated send_notification call, ready to go out.
+type sendPlan struct {
+	cfg          *config.Config
+	client       *pushover.Client
+	notifier     notify.Notifier
+	params       pushover.SendParams
+	window       time.Duration
+	longMessages string
+	output       SendNotificationOutput
+	record       db.SentRecord
+}
+
+// planSend checks a send_notification call against the config, including
+// human confirmation for high priorities, and resolves its defaults.
+func (s *Server) planSend(ctx context.Context, req *mcp.CallToolRequest, input SendNotificationInput) (*sendPlan, error) {
+	cfg := s.config()
+	client := s.newClient()
+	if input.App != "" {
+		appCfg, err := cfg.ForApp(input.App)
+		if err != nil {
+			return nil, err
+		}
+		client.AppToken = appCfg.AppToken
+	}
+	notifier, err := notify.New(cfg, input.Via, client)
+	if err != nil {
+		return nil, err
+	}
 	if strings.TrimSpace(input.Message) == "" {
-		return nil, SendNotificationOutput{}, fmt.Errorf("message is required")
+		return nil, fmt.Errorf("message is required")
 	}
 
-	priority := s.cfg.DefaultPriority
+	title, err := cfg.ApplyTitleTemplate(input.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	priority := cfg.DefaultPriority
 	if input.Priority != nil {
 		priority = *input.Priority
 	}
 	if priority < -2 || priority > 2 {
-		return nil, SendNotificationOutput{}, fmt.Errorf("priority must be between -2 and 2")
+		return nil, fmt.Errorf("priority must be between -2 and 2")
+	}
+	if err := s.confirmHighPriority(ctx, req, input, priority); err != nil {
+		return nil, err
 	}
 
 	device := input.Device
 	if device == "" {
-		device = s.cfg.DefaultDevice
+		device = cfg.DefaultDevice
 	}
 
-	params := pushover.SendParams{
+	plan := &sendPlan{cfg: cfg, client: client, notifier: notifier}
+	if plan.window, err = cfg.DedupeWindowDuration(); err != nil {
+		return nil, err
+	}
+	if plan.longMessages, err = cfg.LongMessagePolicy(); err != nil {
+		return nil, err
+	}
+	if input.LongMessage != "" {
+		if plan.longMessages, err = config.ParseLongMessageMode(input.LongMessage); err != nil {
+			return nil, fmt.Errorf("long_message must be error, truncate, or split")
+		}
+	}
+
+	plan.params = pushover.SendParams{
 		Message:  input.Message,
-		Title:    input.Title,
+		Title:    title,
 		Device:   device,
 		Priority: priority,
 		URL:      input.URL,
 		Sound:    input.Sound,
 	}
+	if input.TTL != "" {
+		if plan.params.TTL, err = time.ParseDuration(input.TTL); err != nil || plan.params.TTL <= 0 {
+			return nil, fmt.Errorf("ttl must be a positive duration such as \"30m\"")
+		}
+	}
 
-	client := s.newClient()
-	resp, err := client.Send(ctx, params)
+	plan.output = SendNotificationOutput{
+		Message:  input.Message,
+		Title:    title,
+		Device:   device,
+		Priority: priority,
+		Via:      notify.Resolve(cfg, input.Via),
+	}
+	plan.record = db.SentRecord{
+		Message:     input.Message,
+		Title:       title,
+		Device:      device,
+		Priority:    priority,
+		SentAt:      time.Now(),
+		ContentHash: messages.ContentHash(input.Message, title),
+		Via:         notify.Resolve(cfg, input.Via),
+		App:         input.App,
+		Thread:      strings.TrimSpace(input.Thread),
+	}
+	return plan, nil
+}
+
+// suppressDuplicate reports whether plan repeats a notification sent within
+// the dedupe window, logging it as suppressed if so. Otherwise it notes how
+// many times it has repeated in the message.
+func (s *Server) suppressDuplicate(ctx context.Context, req *mcp.CallToolRequest, plan *sendPlan) (bool, error) {
+	storeCtx, cancel := s.store.op(ctx)
+	dup, err := messages.CheckDuplicate(storeCtx, s.store.Store, plan.record.ContentHash, plan.window, plan.record.SentAt)
+	cancel()
 	if err != nil {
-		return nil, SendNotificationOutput{}, err
+		return false, err
 	}
-
-	output := SendNotificationOutput{
-		Message:   input.Message,
-		Title:     input.Title,
-		Device:    device,
-		Priority:  priority,
-		RequestID: resp.Request,
-		Receipt:   resp.Receipt,
+	if !dup.Duplicate {
+		plan.output.Repeats = dup.Repeats
+		plan.params.Message = messages.AnnotateRepeats(plan.params.Message, dup.Repeats)
+		return false, nil
 	}
 
-	record := db.SentRecord{
-		Message:   input.Message,
-		Title:     input.Title,
-		Device:    device,
-		Priority:  priority,
-		SentAt:    time.Now(),
-		RequestID: resp.Request,
-	}
-	if err := s.store.LogSent(ctx, record); err != nil {
-		output.Warning = fmt.Sprintf("failed to log history: %v", err)
-	} else {
-		output.Logged = true
+	plan.record.Suppressed = true
+	plan.output.Suppressed = true
+	plan.output.Warning = fmt.Sprintf("identical notification sent %s ago; suppressed", time.Since(dup.LastSent).Round(time.Second))
+	s.report(ctx, sessionOf(req), mcp.LevelNotice, "duplicate notification suppressed", "last_sent", dup.LastSent, "window", plan.window)
+	if err := s.logSent(ctx, plan.record); err == nil {
+		plan.output.Logged = true
 	}
+	return true, nil
+}
 
-	result, err := buildToolResult(output)
-	if err != nil {
-		return nil, output, err
+// sendParts sends each part of the message in turn, taking a slot from the
+// shared send budget for each, and logs every part that went out.
+func (s *Server) sendParts(ctx context.Context, req *mcp.CallToolRequest, plan *sendPlan, parts []string, wait bool) (*mcp.CallToolResult, SendNotificationOutput, error) {
+	cfg, output, record := plan.cfg, plan.output, plan.record
+	output.Logged = true
+	for i, part := range parts {
+		if err := messages.AcquireSendSlot(ctx, s.store.Store, cfg.RateLimit, wait, func(retry time.Duration) {
+			s.report(ctx, sessionOf(req), mcp.LevelNotice, "send budget reached, waiting", "limit_per_minute", cfg.RateLimit, "retry_in", retry.Round(time.Second))
+		}); err != nil {
+			var budget *messages.BudgetError
+			if errors.As(err, &budget) {
+				// Earlier parts went out and stay logged.
+				output.Logged = output.Logged && i > 0
+				return s.rateLimited(ctx, req, output, limitScopeShared, budget.RetryAfter)
+			}
+			return nil, SendNotificationOutput{}, err
+		}
+
+		params := plan.params
+		params.Message = part
+		resp, err := plan.notifier.Send(ctx, params)
+		if err != nil {
+			return nil, SendNotificationOutput{}, err
+		}
+
+		output.RequestID = resp.Request
+		output.Receipt = resp.Receipt
+		s.quota.record(plan.client.AppToken, resp.Limits, limitsFromSend, time.Now())
+		record.Message = part
+		record.RequestID = resp.Request
+		record.Receipt = resp.Receipt
+		if resp.Receipt != "" {
+			record.ReceiptExpiresAt = time.Now().Add(pushover.DefaultEmergencyExpire)
+		}
+		if err := s.logSent(ctx, record); err != nil {
+			s.report(ctx, sessionOf(req), mcp.LevelWarning, "failed to log sent message", "request_id", resp.Request, "error", err)
+			output.Warning = fmt.Sprintf("failed to log history: %v", err)
+			output.Logged = false
+		}
+		if len(parts) > 1 {
+			output.RequestIDs = append(output.RequestIDs, resp.Request)
+		}
 	}
-	return result, output, nil
+	if len(parts) > 1 {
+		output.Parts = len(parts)
+		output.RequestID = output.RequestIDs[0]
+	}
+	return toolResult(output)
 }
 
 type CheckMessagesInput struct {
-	Limit *int `json:"limit,omitempty"`
+	Limit        *int   `json:"limit,omitempty"`
+	Ack          *bool  `json:"ack,omitempty"`
+	Offset       int    `json:"offset,omitempty"`
+	Cursor       string `json:"cursor,omitempty"`
+	MaxBodyChars *int   `json:"max_body_chars,omitempty"`
 }
 
 type CheckMessagesOutput struct {
-	Count      int                        `json:"count"`
-	Returned   int                        `json:"returned"`
-	Limit      int                        `json:"limit"`
-	Persisted  int                        `json:"persisted"`
-	AckedUpTo  int64                      `json:"acked_up_to,omitempty"`
-	Messages   []pushover.ReceivedMessage `json:"messages"`
-	Warning    string                     `json:"warning,omitempty"`
-	AckWarning string                     `json:"ack_warning,omitempty"`
-}
-
-func (s *Server) handleCheckMessages(ctx context.Context, _ *mcp.CallToolRequest, input CheckMessagesInput) (*mcp.CallToolResult, CheckMessagesOutput, error) {
-	if err := s.cfg.ValidateReceive(); err != nil {
+	Count      int              `json:"count"`
+	Returned   int              `json:"returned"`
+	Limit      int              `json:"limit"`
+	Persisted  int              `json:"persisted"`
+	HighestID  int64            `json:"highest_id,omitempty"`
+	AckedUpTo  int64            `json:"acked_up_to,omitempty"`
+	Offset     int              `json:"offset,omitempty"`
+	Messages   []CheckedMessage `json:"messages"`
+	Truncated  bool             `json:"truncated"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+	Trimmed    int              `json:"trimmed,omitempty"`
+	Warning    string           `json:"warning,omitempty"`
+	AckWarning string           `json:"ack_warning,omitempty"`
+}
+
+// page sets msgs as the messages returned, with bodies cut to maxChars and
+// fitted to checkMessagesMaxBytes, out of remaining left in the fetch from the
+// first of them on. When some weren't returned, the output is marked
+// truncated with a cursor for the rest.
+func (o *CheckMessagesOutput) page(msgs []pushover.ReceivedMessage, remaining, maxChars int) {
+	o.Messages, o.Trimmed = fitMessages(msgs, maxChars, checkMessagesMaxBytes)
+	o.Returned = len(o.Messages)
+	if remaining > o.Returned && o.Returned > 0 {
+		o.Truncated = true
+		o.NextCursor = encodeFetchCursor(o.Messages[o.Returned-1].PushoverID, o.HighestID)
+	}
+}
+
+func (s *Server) handleCheckMessages(ctx context.Context, req *mcp.CallToolRequest, input CheckMessagesInput) (*mcp.CallToolResult, CheckMessagesOutput, error) {
+	cfg := s.config()
+	limit, maxChars, err := checkMessagesLimits(cfg, input)
+	if err != nil {
 		return nil, CheckMessagesOutput{}, err
 	}
+	if input.Cursor != "" {
+		return s.checkMessagesPage(ctx, cfg.ReceivingDevice(), input.Cursor, limit, maxChars)
+	}
+
+	client := s.newClient()
+	fetched, err := s.fetchChecked(ctx, req, cfg, client, input.Offset, limit)
+	if err != nil {
+		return nil, CheckMessagesOutput{}, err
+	}
+
+	ack := cfg.MCPAutoAck() && !cfg.MCP.ReadOnly
+	if input.Ack != nil {
+		ack = *input.Ack
+	}
+	output := CheckMessagesOutput{
+		Count:     fetched.count,
+		Limit:     limit,
+		Persisted: fetched.persisted,
+		HighestID: fetched.highestID,
+		Offset:    input.Offset,
+		Warning:   fetched.warning,
+	}
+	if ack && output.HighestID > 0 {
+		if err := client.DeleteMessages(ctx, output.HighestID); err != nil {
+			s.report(ctx, sessionOf(req), mcp.LevelWarning, "unable to ack messages", "up_to", output.HighestID, "error", err)
+			output.AckWarning = err.Error()
+		} else {
+			output.AckedUpTo = output.HighestID
+		}
+	}
+	output.page(fetched.outgoing, max(fetched.count-input.Offset, 0), maxChars)
+
+	resultPayload, err := buildToolResult(output)
+	if err != nil {
+		return nil, output, err
+	}
+	return resultPayload, output, nil
+}
 
-	limit := 10
+// checkMessagesLimits checks check_messages' arguments, returning how many
+// messages to reply with and how much of each body to keep.
+func checkMessagesLimits(cfg *config.Config, input CheckMessagesInput) (limit, maxChars int, err error) {
+	if err := cfg.ValidateReceive(); err != nil {
+		return 0, 0, err
+	}
+	if cfg.MCP.ReadOnly && input.Ack != nil && *input.Ack {
+		return 0, 0, fmt.Errorf("server is read-only; ack is disabled")
+	}
+	limit = 10
 	if input.Limit != nil && *input.Limit > 0 {
 		limit = *input.Limit
 	}
+	if input.Offset < 0 {
+		return 0, 0, fmt.Errorf("offset must not be negative")
+	}
+	if maxChars, err = maxBodyChars(input.MaxBodyChars, cfg.MCPMaxBodyChars()); err != nil {
+		return 0, 0, err
+	}
+	if input.Cursor != "" && input.Offset > 0 {
+		return 0, 0, fmt.Errorf("use either offset or cursor, not both")
+	}
+	return limit, maxChars, nil
+}
 
-	client := s.newClient()
-	result, err := client.FetchMessages(ctx)
+// checkedFetch is what one check_messages fetch found.
+type checkedFetch struct {
+	// outgoing are the first limit messages after offset, for the reply.
+	outgoing  []pushover.ReceivedMessage
+	count     int
+	persisted int
+	highestID int64
+	warning   string
+}
+
+// fetchChecked fetches the device's messages, storing them a batch at a time
+// as they're decoded and keeping only the ones the reply needs.
+func (s *Server) fetchChecked(ctx context.Context, req *mcp.CallToolRequest, cfg *config.Config, client *pushover.Client, offset, limit int) (checkedFetch, error) {
+	rules, err := cfg.LocalPriorities()
 	if err != nil {
-		return nil, CheckMessagesOutput{}, err
+		return checkedFetch{}, err
+	}
+	stream, err := client.FetchMessagesStream(ctx)
+	if err != nil {
+		return checkedFetch{}, err
 	}
+	defer func() { _ = stream.Close() }()
 
-	persisted, persistErr := messages.PersistReceived(ctx, s.store, result.Messages)
+	fetched := checkedFetch{outgoing: []pushover.ReceivedMessage{}}
+	var highest int64
+	for batch, err := range stream.Batches(messages.StreamBatch) {
+		if err != nil {
+			return checkedFetch{}, err
+		}
+		messages.ApplyLocalPriorities(rules, batch)
+		n, warning := s.storeChecked(ctx, req, cfg.ReceivingDevice(), batch)
+		fetched.persisted += n
+		if fetched.warning == "" {
+			fetched.warning = warning
+		}
+		for _, msg := range batch {
+			highest = max(highest, msg.PushoverID)
+			if fetched.count >= offset && len(fetched.outgoing) < limit {
+				fetched.outgoing = append(fetched.outgoing, msg)
+			}
+			fetched.count++
+		}
+	}
+	fetched.highestID = stream.LastMessageID
+	if fetched.highestID == 0 {
+		fetched.highestID = highest
+	}
+	return fetched, nil
+}
+
+// storeChecked saves a fetched batch and caches its icons, returning how
+// many rows were written and a warning for the first thing that failed.
+func (s *Server) storeChecked(ctx context.Context, req *mcp.CallToolRequest, device string, batch []pushover.ReceivedMessage) (int, string) {
+	// Fetched (and maybe acknowledged) messages are saved even if the
+	// client has given up on the call, since Pushover may not return
+	// them again.
+	recordCtx, cancel := s.store.record(ctx)
+	persisted, persistErr := messages.PersistReceived(recordCtx, s.store.Store, device, batch)
+	cancel()
 	warning := ""
 	if persistErr != nil {
+		s.report(ctx, sessionOf(req), mcp.LevelWarning, "failed to persist messages", "error", persistErr)
 		warning = persistErr.Error()
 	}
-
-	ackedID := determineAckID(result)
-	ackWarning := ""
-	if ackedID > 0 {
-		if err := client.DeleteMessages(ctx, ackedID); err != nil {
-			ackWarning = err.Error()
+	if s.media != nil {
+		if err := s.media.CacheIcons(ctx, batch); err != nil {
+			s.report(ctx, sessionOf(req), mcp.LevelWarning, "unable to cache icons", "error", err)
+			if warning == "" {
+				warning = fmt.Sprintf("unable to cache icons: %v", err)
+			}
 		}
 	}
+	return persisted, warning
+}
 
-	outgoing := result.Messages
-	if len(outgoing) > limit {
-		outgoing = outgoing[:limit]
+// checkMessagesPage continues a check_messages reply from its next_cursor,
+// reading the rest of that fetch back from history without fetching or
+// acknowledging anything. Count is how many of the fetch remain from the
+// cursor on.
+func (s *Server) checkMessagesPage(ctx context.Context, device, cursor string, limit, maxChars int) (*mcp.CallToolResult, CheckMessagesOutput, error) {
+	after, upTo, err := decodeFetchCursor(cursor)
+	if err != nil {
+		return nil, CheckMessagesOutput{}, err
+	}
+	storeCtx, cancel := s.store.op(ctx)
+	defer cancel()
+	records, total, err := s.store.MessagesAfter(storeCtx, device, after, upTo, limit)
+	if err != nil {
+		return nil, CheckMessagesOutput{}, err
 	}
 
-	output := CheckMessagesOutput{
-		Count:      len(result.Messages),
-		Returned:   len(outgoing),
-		Limit:      limit,
-		Persisted:  persisted,
-		AckedUpTo:  ackedID,
-		Messages:   outgoing,
-		Warning:    warning,
-		AckWarning: ackWarning,
+	outgoing := make([]pushover.ReceivedMessage, 0, len(records))
+	for _, rec := range records {
+		outgoing = append(outgoing, messages.ReceivedFromRecord(rec))
 	}
+	output := CheckMessagesOutput{Count: total, Limit: limit, HighestID: upTo}
+	output.page(outgoing, total, maxChars)
 
-	resultPayload, err := buildToolResult(output)
+	result, err := buildToolResult(output)
 	if err != nil {
 		return nil, output, err
 	}
-	return resultPayload, output, nil
+	return result, output, nil
 }
 
 type ListHistoryInput struct {
-	Limit  *int    `json:"limit,omitempty"`
-	Since  *string `json:"since,omitempty"`
-	Search *string `json:"search,omitempty"`
+	Limit        *int    `json:"limit,omitempty"`
+	Since        *string `json:"since,omitempty"`
+	Until        *string `json:"until,omitempty"`
+	Search       *string `json:"search,omitempty"`
+	Regex        *string `json:"regex,omitempty"`
+	App          *string `json:"app,omitempty"`
+	MinPriority  *int    `json:"min_priority,omitempty"`
+	Device       *string `json:"device,omitempty"`
+	HasURL       bool    `json:"has_url,omitempty"`
+	Archived     bool    `json:"archived,omitempty"`
+	MaxBodyChars *int    `json:"max_body_chars,omitempty"`
 }
 
 type ListHistoryOutput struct {
-	Count    int                `json:"count"`
-	Limit    int                `json:"limit"`
-	Since    *time.Time         `json:"since,omitempty"`
-	Search   string             `json:"search,omitempty"`
-	Messages []db.MessageRecord `json:"messages"`
+	Count       int              `json:"count"`
+	Limit       int              `json:"limit"`
+	Since       *time.Time       `json:"since,omitempty"`
+	Until       *time.Time       `json:"until,omitempty"`
+	Search      string           `json:"search,omitempty"`
+	Regex       string           `json:"regex,omitempty"`
+	App         string           `json:"app,omitempty"`
+	MinPriority *int             `json:"min_priority,omitempty"`
+	Device      string           `json:"device,omitempty"`
+	HasURL      bool             `json:"has_url,omitempty"`
+	Archived    bool             `json:"archived,omitempty"`
+	Messages    []HistoryMessage `json:"messages"`
 }
 
 func (s *Server) handleListHistory(ctx context.Context, _ *mcp.CallToolRequest, input ListHistoryInput) (*mcp.CallToolResult, ListHistoryOutput, error) {
-	limit := 20
+	filter := db.MessageFilter{
+		Limit:       20,
+		Search:      derefString(input.Search),
+		Regex:       derefString(input.Regex),
+		App:         derefString(input.App),
+		Device:      derefString(input.Device),
+		MinPriority: input.MinPriority,
+		HasURL:      input.HasURL,
+		// Archived messages were triaged already; agents see them only on request.
+		HideArchived: !input.Archived,
+	}
 	if input.Limit != nil && *input.Limit > 0 {
-		limit = *input.Limit
+		filter.Limit = *input.Limit
 	}
 
-	var sinceTime *time.Time
-	if input.Since != nil && *input.Since != "" {
-		parsed, err := dateparse.ParseLocal(*input.Since)
-		if err != nil {
-			return nil, ListHistoryOutput{}, fmt.Errorf("invalid since value: %w", err)
-		}
-		sinceTime = &parsed
+	if err := parseDateInput("since", input.Since, &filter.Since); err != nil {
+		return nil, ListHistoryOutput{}, err
 	}
-
-	searchVal := ""
-	if input.Search != nil {
-		searchVal = *input.Search
+	if err := parseDateInput("until", input.Until, &filter.Until); err != nil {
+		return nil, ListHistoryOutput{}, err
+	}
+	if filter.MinPriority != nil && (*filter.MinPriority < -2 || *filter.MinPriority > 2) {
+		return nil, ListHistoryOutput{}, fmt.Errorf("min_priority must be between -2 and 2")
+	}
+	maxChars, err := maxBodyChars(input.MaxBodyChars, s.config().MCPMaxBodyChars())
+	if err != nil {
+		return nil, ListHistoryOutput{}, err
 	}
 
-	records, err := s.store.QueryMessages(ctx, limit, sinceTime, searchVal)
+	storeCtx, cancel := s.store.op(ctx)
+	defer cancel()
+	records, err := s.store.FindMessages(storeCtx, filter)
 	if err != nil {
 		return nil, ListHistoryOutput{}, err
 	}
 
 	output := ListHistoryOutput{
-		Count:    len(records),
-		Limit:    limit,
-		Since:    sinceTime,
-		Search:   searchVal,
-		Messages: records,
+		Count:       len(records),
+		Limit:       filter.Limit,
+		Since:       filter.Since,
+		Until:       filter.Until,
+		Search:      filter.Search,
+		Regex:       filter.Regex,
+		App:         filter.App,
+		MinPriority: filter.MinPriority,
+		Device:      filter.Device,
+		HasURL:      filter.HasURL,
+		Archived:    input.Archived,
+		Messages:    historyMessages(records, maxChars),
+	}
+
+	result, err := buildToolResult(output)
+	if err != nil {
+		return nil, output, err
+	}
+	return result, output, nil
+}
+
+// parseDateInput sets dest when value holds a parseable date.
+func parseDateInput(name string, value *string, dest **time.Time) error {
+	if value == nil || *value == "" {
+		return nil
+	}
+	parsed, err := dateparse.ParseLocal(*value)
+	if err != nil {
+		return fmt.Errorf("invalid %s value: %w", name, err)
+	}
+	*dest = &parsed
+	return nil
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+type DailyDigestInput struct {
+	Since       *string `json:"since,omitempty"`
+	MinPriority *int    `json:"min_priority,omitempty"`
+}
+
+type DailyDigestOutput struct {
+	Since time.Time      `json:"since"`
+	Total int            `json:"total"`
+	Apps  []db.AppDigest `json:"apps"`
+}
+
+func (s *Server) handleDailyDigest(ctx context.Context, _ *mcp.CallToolRequest, input DailyDigestInput) (*mcp.CallToolResult, DailyDigestOutput, error) {
+	since := time.Now().Add(-24 * time.Hour)
+	filter := db.MessageFilter{Since: &since, MinPriority: input.MinPriority}
+	if filter.MinPriority != nil && (*filter.MinPriority < -2 || *filter.MinPriority > 2) {
+		return nil, DailyDigestOutput{}, fmt.Errorf("min_priority must be between -2 and 2")
+	}
+	if err := parseDateInput("since", input.Since, &filter.Since); err != nil {
+		return nil, DailyDigestOutput{}, err
+	}
+
+	storeCtx, cancel := s.store.op(ctx)
+	defer cancel()
+	digests, err := s.store.DigestByApp(storeCtx, filter)
+	if err != nil {
+		return nil, DailyDigestOutput{}, err
+	}
+
+	output := DailyDigestOutput{Since: *filter.Since, Apps: digests}
+	for _, digest := range digests {
+		output.Total += digest.Count
 	}
 
 	result, err := buildToolResult(output)
@@ -348,7 +910,7 @@ type MarkReadOutput struct {
 }
 
 func (s *Server) handleMarkRead(ctx context.Context, _ *mcp.CallToolRequest, input MarkReadInput) (*mcp.CallToolResult, MarkReadOutput, error) {
-	if err := s.cfg.ValidateReceive(); err != nil {
+	if err := s.config().ValidateReceive(); err != nil {
 		return nil, MarkReadOutput{}, err
 	}
 	if input.MessageID <= 0 {
@@ -384,6 +946,22 @@ func determineAckID(result *pushover.FetchResult) int64 {
 	return highest
 }
 
+// rateLimited reports a refused send as an error result whose structured
+// output carries error "rate_limited" and retry_after in whole seconds.
+func (s *Server) rateLimited(ctx context.Context, req *mcp.CallToolRequest, output SendNotificationOutput, scope string, retry time.Duration) (*mcp.CallToolResult, SendNotificationOutput, error) {
+	output.Error = "rate_limited"
+	output.LimitScope = scope
+	output.RetryAfter = int(math.Ceil(retry.Seconds()))
+	s.report(ctx, sessionOf(req), mcp.LevelWarning, "send rate limited", "scope", scope, "retry_after", retry.Round(time.Second))
+
+	result, err := buildToolResult(output)
+	if err != nil {
+		return nil, output, err
+	}
+	result.IsError = true
+	return result, output, nil
+}
+
 func buildToolResult(payload any) (*mcp.CallToolResult, error) {
 	data, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
@@ -393,3 +971,12 @@ func buildToolResult(payload any) (*mcp.CallToolResult, error) {
 		Content: []mcp.Content{&mcp.TextContent{Text: string(data)}},
 	}, nil
 }
+
+// toolResult returns output the way a tool handler does.
+func toolResult[T any](output T) (*mcp.CallToolResult, T, error) {
+	result, err := buildToolResult(output)
+	if err != nil {
+		return nil, output, err
+	}
+	return result, output, nil
+}
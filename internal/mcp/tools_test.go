@@ -0,0 +1,252 @@
+// ABOUTME: Tests for MCP tool registration and sends.
+// ABOUTME: Checks enabled_tools filtering, read-only mode, title templates, error hints, and large fetches.
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connect starts a server for cfg and returns a client session talking to it
+// over in-memory transports.
+func connect(t *testing.T, cfg *config.Config, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+
+	dbPath := filepath.Join(t.TempDir(), "push.db")
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	server, err := NewServer(cfg, "", store, dbPath)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	session, err := server.mcp.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, opts)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	t.Cleanup(func() { _ = clientSession.Close() })
+	return clientSession
+}
+
+func listTools(t *testing.T, cfg *config.Config) []string {
+	t.Helper()
+	result, err := connect(t, cfg, nil).ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestToolFiltering(t *testing.T) {
+	tests := []struct {
+		name string
+		mcp  config.MCPConfig
+		want []string
+	}{
+		{"all", config.MCPConfig{}, []string{"ask_human", "check_limits", "check_messages", "create_reminder", "daily_digest", "get_receipt_status", "get_stats", "list_history", "list_responses", "list_thread", "mark_read", "send_notification", "summarize_unread"}},
+		{"read only", config.MCPConfig{ReadOnly: true}, []string{"check_limits", "check_messages", "daily_digest", "get_receipt_status", "get_stats", "list_history", "list_responses", "list_thread", "summarize_unread"}},
+		{"enabled", config.MCPConfig{EnabledTools: []string{"list_history", "send_notification"}}, []string{"list_history", "send_notification"}},
+		{"enabled and read only", config.MCPConfig{EnabledTools: []string{"list_history", "send_notification"}, ReadOnly: true}, []string{"list_history"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := listTools(t, &config.Config{MCP: tt.mcp})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("tools = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownEnabledTool(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "push.db")
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	cfg := &config.Config{MCP: config.MCPConfig{EnabledTools: []string{"delete_everything"}}}
+	if _, err := NewServer(cfg, "", store, dbPath); err == nil {
+		t.Error("NewServer succeeded with unknown tool, want error")
+	}
+}
+
+func TestSendNotificationTitleTemplate(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skipf("no hostname: %v", err)
+	}
+
+	session := connect(t, &config.Config{
+		AppToken:      pushovertest.AppToken,
+		UserKey:       pushovertest.UserKey,
+		APIURL:        srv.URL(),
+		TitleTemplate: "[{{.Hostname}}] {{.Title}}",
+	}, nil)
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      toolSendNotification,
+		Arguments: map[string]any{"message": "disk full", "title": "Alert"},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("send_notification = %v, %v", result, err)
+	}
+
+	sent := srv.Sent()
+	if want := "[" + host + "] Alert"; len(sent) != 1 || sent[0].Title != want {
+		t.Errorf("sent %+v, want title %q", sent, want)
+	}
+}
+
+func TestToolErrorHints(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+
+	session := connect(t, &config.Config{AppToken: "wrong", UserKey: pushovertest.UserKey, APIURL: srv.URL()}, nil)
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      toolSendNotification,
+		Arguments: map[string]any{"message": "hi"},
+	})
+	if err != nil || !result.IsError {
+		t.Fatalf("send_notification = %v, %v; want an error result", result, err)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "Hint: ") || !strings.Contains(text, "pushover.net/apps") {
+		t.Errorf("error result %q lacks a remediation hint", text)
+	}
+}
+
+// mockServer returns a Server fetching from srv and persisting to an
+// in-memory store.
+func mockServer(t *testing.T, srv *pushovertest.Server) *Server {
+	t.Helper()
+	store, err := db.Open(db.Memory)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	server, err := NewServer(&config.Config{
+		AppToken: pushovertest.AppToken, UserKey: pushovertest.UserKey,
+		DeviceID: pushovertest.DeviceID, DeviceSecret: pushovertest.LoginSecret,
+		APIURL: srv.URL(),
+	}, "", store, db.Memory)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	return server
+}
+
+// pageRest follows out's cursor to the end, checking each page stays under
+// the byte budget and no message comes back twice. It returns the number of
+// pages it read.
+func pageRest(t *testing.T, server *Server, out CheckMessagesOutput, seen map[int64]bool) int {
+	t.Helper()
+	pages, limit := 0, 1000
+	for out.NextCursor != "" {
+		if !out.Truncated {
+			t.Fatal("next_cursor on a response not marked truncated")
+		}
+		var err error
+		if _, out, err = server.handleCheckMessages(context.Background(), nil, CheckMessagesInput{Limit: &limit, Cursor: out.NextCursor}); err != nil {
+			t.Fatalf("check_messages cursor: %v", err)
+		}
+		if size := encodedSize(out.Messages); size > checkMessagesMaxBytes {
+			t.Fatalf("page of %d bytes, over the %d budget", size, checkMessagesMaxBytes)
+		}
+		for _, msg := range out.Messages {
+			if seen[msg.PushoverID] {
+				t.Fatalf("message %d returned twice", msg.PushoverID)
+			}
+			seen[msg.PushoverID] = true
+		}
+		pages++
+	}
+	return pages
+}
+
+func TestCheckMessagesBacklog(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	total := 1234
+	for i := range total {
+		srv.Deliver(pushover.ReceivedMessage{Message: fmt.Sprintf("message %d", i), App: "cron"})
+	}
+	server := mockServer(t, srv)
+
+	limit, ack := 5, true
+	_, out, err := server.handleCheckMessages(context.Background(), nil, CheckMessagesInput{Limit: &limit, Ack: &ack})
+	if err != nil {
+		t.Fatalf("check_messages: %v", err)
+	}
+	if out.Count != total || out.Persisted != total || out.Returned != limit || len(out.Messages) != limit {
+		t.Errorf("check_messages = count %d, persisted %d, returned %d, want %d, %d, %d", out.Count, out.Persisted, out.Returned, total, total, limit)
+	}
+	if out.Messages[0].Message != "message 0" || out.AckedUpTo == 0 || out.AckedUpTo != out.HighestID {
+		t.Errorf("check_messages = first %q, acked %d of %d", out.Messages[0].Message, out.AckedUpTo, out.HighestID)
+	}
+	if pending := srv.Pending(); len(pending) != 0 {
+		t.Errorf("%d messages left on the server, want none", len(pending))
+	}
+
+	// The rest of the fetch pages out of history until no cursor is left.
+	seen := map[int64]bool{}
+	for _, msg := range out.Messages {
+		seen[msg.PushoverID] = true
+	}
+	if pages := pageRest(t, server, out, seen); len(seen) != total || pages < 2 {
+		t.Errorf("paged through %d messages in %d pages, want %d in several", len(seen), pages, total)
+	}
+}
+
+// Offset skips into a fetch left on the server.
+func TestCheckMessagesOffset(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	server := mockServer(t, srv)
+
+	srv.Deliver(pushover.ReceivedMessage{Message: "one"})
+	srv.Deliver(pushover.ReceivedMessage{Message: "two"})
+	srv.Deliver(pushover.ReceivedMessage{Message: "three"})
+	limit, ack := 1, false
+	_, out, err := server.handleCheckMessages(ctx, nil, CheckMessagesInput{Limit: &limit, Ack: &ack, Offset: 1})
+	if err != nil {
+		t.Fatalf("check_messages offset: %v", err)
+	}
+	if out.Count != 3 || len(out.Messages) != 1 || out.Messages[0].Message != "two" || !out.Truncated {
+		t.Errorf("check_messages offset 1 = %+v, want two of three, truncated", out)
+	}
+	if _, _, err := server.handleCheckMessages(ctx, nil, CheckMessagesInput{Offset: 1, Cursor: out.NextCursor}); err == nil {
+		t.Error("check_messages accepted both offset and cursor")
+	}
+}
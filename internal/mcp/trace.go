@@ -0,0 +1,40 @@
+// ABOUTME: OpenTelemetry spans around MCP tool calls and resource reads.
+// ABOUTME: Installed as receiving middleware so every handler is covered.
+package mcp
+
+import (
+	"context"
+	"errors"
+
+	"github.com/harper/push/internal/telemetry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// traceHandlers opens a span per tools/call and resources/read request, named
+// after the tool or resource, so API and database spans nest beneath it.
+func traceHandlers(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		var target string
+		switch params := req.GetParams().(type) {
+		case *mcp.CallToolParamsRaw:
+			target = params.Name
+		case *mcp.ReadResourceParams:
+			target = params.URI
+		default:
+			return next(ctx, method, req)
+		}
+
+		ctx, span := telemetry.Start(ctx, method+" "+target,
+			attribute.String("mcp.method.name", method),
+			attribute.String("mcp.target", target),
+		)
+		result, err := next(ctx, method, req)
+		spanErr := err
+		if toolResult, ok := result.(*mcp.CallToolResult); ok && err == nil && toolResult.IsError {
+			spanErr = errors.New("tool returned an error result")
+		}
+		telemetry.End(span, spanErr)
+		return result, err
+	}
+}
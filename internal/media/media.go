@@ -0,0 +1,182 @@
+// ABOUTME: Content-addressed cache for message icons and other remote media.
+// ABOUTME: Downloads files once, stores them by SHA-256, and records them in the DB.
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// MaxSize caps how much of a single remote file is cached.
+const MaxSize = 5 << 20
+
+// Cache stores downloaded files under dir/<first two hash chars>/<hash><ext>.
+type Cache struct {
+	dir     string
+	store   *db.Store
+	http    *http.Client
+	iconURL string
+}
+
+// New returns a cache rooted at dir. apiURL is the Pushover API base, used to
+// locate icons; an empty value means pushover.DefaultBaseURL.
+func New(dir string, store *db.Store, httpClient *http.Client, apiURL string) *Cache {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if apiURL == "" {
+		apiURL = pushover.DefaultBaseURL
+	}
+	base := strings.TrimSuffix(strings.TrimRight(apiURL, "/"), "/1")
+	return &Cache{dir: dir, store: store, http: httpClient, iconURL: base + "/icons/"}
+}
+
+// Dir returns the cache root.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// IconURL returns where Pushover serves the named application icon.
+func (c *Cache) IconURL(icon string) string {
+	return c.iconURL + icon + ".png"
+}
+
+// Fetch returns the cached copy of url, downloading it on first use.
+func (c *Cache) Fetch(ctx context.Context, url string) (db.MediaRecord, error) {
+	if rec, found, err := c.store.MediaByURL(ctx, url); err != nil {
+		return db.MediaRecord{}, err
+	} else if found {
+		if _, statErr := os.Stat(rec.Path); statErr == nil {
+			return rec, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return db.MediaRecord{}, fmt.Errorf("build media request: %w", err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return db.MediaRecord{}, fmt.Errorf("download %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return db.MediaRecord{}, fmt.Errorf("download %s: status %d", url, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSize+1))
+	if err != nil {
+		return db.MediaRecord{}, fmt.Errorf("download %s: %w", url, err)
+	}
+	if len(data) > MaxSize {
+		return db.MediaRecord{}, fmt.Errorf("download %s: larger than %d bytes", url, MaxSize)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	path, err := c.write(hash, extensionFor(contentType, url), data)
+	if err != nil {
+		return db.MediaRecord{}, err
+	}
+
+	rec := db.MediaRecord{
+		Hash:        hash,
+		URL:         url,
+		Path:        path,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		FetchedAt:   time.Now(),
+	}
+	if err := c.store.SaveMedia(ctx, rec); err != nil {
+		return db.MediaRecord{}, err
+	}
+	return rec, nil
+}
+
+// write stores data atomically; identical content lands on the same path.
+func (c *Cache) write(hash, ext string, data []byte) (string, error) {
+	dir := filepath.Join(c.dir, hash[:2])
+	path := filepath.Join(dir, hash+ext)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create media cache: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("write media cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("write media cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("write media cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("write media cache: %w", err)
+	}
+	return path, nil
+}
+
+// CacheIcons downloads the icons of the given messages and links them to the
+// persisted rows. Every icon is attempted; failures are joined.
+func (c *Cache) CacheIcons(ctx context.Context, msgs []pushover.ReceivedMessage) error {
+	var errs []error
+	for _, msg := range msgs {
+		if msg.Icon == "" {
+			continue
+		}
+		rec, err := c.Fetch(ctx, c.IconURL(msg.Icon))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("icon %q: %w", msg.Icon, err))
+			continue
+		}
+		if err := c.store.SetMessageIcon(ctx, msg.PushoverID, rec.Hash); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func extensionFor(contentType, url string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "image/png":
+			return ".png"
+		case "image/jpeg":
+			return ".jpg"
+		case "image/gif":
+			return ".gif"
+		}
+		if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+			return exts[0]
+		}
+	}
+	if ext := filepath.Ext(strings.SplitN(url, "?", 2)[0]); len(ext) <= 5 {
+		return ext
+	}
+	return ""
+}
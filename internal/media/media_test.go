@@ -0,0 +1,152 @@
+// ABOUTME: Tests for the content-addressed media cache.
+// ABOUTME: Covers downloading once, shared content, size limits, and icon linking.
+package media
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/messages"
+	"github.com/harper/push/pkg/pushover"
+)
+
+var png = []byte("\x89PNG\r\n\x1a\nnot really a png")
+
+// newCache returns a cache backed by a fresh store and a server that serves
+// png at every path except /missing and /huge. It counts requests.
+func newCache(t *testing.T) (*Cache, *db.Store, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/huge":
+			_, _ = w.Write(bytes.Repeat([]byte{0}, MaxSize+1))
+		default:
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(png)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	store, err := db.Open(db.Memory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return New(t.TempDir(), store, srv.Client(), srv.URL+"/1/"), store, &hits
+}
+
+func TestIconURL(t *testing.T) {
+	c := New(t.TempDir(), nil, nil, "")
+	if got := c.IconURL("abc"); got != "https://api.pushover.net/icons/abc.png" {
+		t.Errorf("IconURL() = %q", got)
+	}
+}
+
+func TestFetchDownloadsOnce(t *testing.T) {
+	ctx := context.Background()
+	c, _, hits := newCache(t)
+	url := c.IconURL("app")
+
+	rec, err := c.Fetch(ctx, url)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if want := filepath.Join(c.Dir(), rec.Hash[:2], rec.Hash+".png"); rec.Path != want {
+		t.Errorf("path = %s, want %s", rec.Path, want)
+	}
+	if data, err := os.ReadFile(rec.Path); err != nil || !bytes.Equal(data, png) {
+		t.Errorf("cached file = %q, %v; want the download", data, err)
+	}
+	if rec.ContentType != "image/png" || rec.Size != int64(len(png)) {
+		t.Errorf("record = %+v", rec)
+	}
+
+	if again, err := c.Fetch(ctx, url); err != nil || again.Hash != rec.Hash || hits.Load() != 1 {
+		t.Errorf("second Fetch = %+v, %v after %d requests; want the cached copy", again, err, hits.Load())
+	}
+
+	// A cached file that went missing is downloaded again.
+	if err := os.Remove(rec.Path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Fetch(ctx, url); err != nil || hits.Load() != 2 {
+		t.Errorf("Fetch after removal = %v after %d requests; want a second download", err, hits.Load())
+	}
+	if _, err := os.Stat(rec.Path); err != nil {
+		t.Errorf("file not restored: %v", err)
+	}
+}
+
+func TestFetchSharesIdenticalContent(t *testing.T) {
+	ctx := context.Background()
+	c, _, _ := newCache(t)
+
+	first, err := c.Fetch(ctx, c.IconURL("one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.Fetch(ctx, c.IconURL("two"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Path != second.Path {
+		t.Errorf("identical icons cached at %s and %s, want one file", first.Path, second.Path)
+	}
+}
+
+func TestFetchFailures(t *testing.T) {
+	ctx := context.Background()
+	c, _, _ := newCache(t)
+	base := strings.TrimSuffix(c.IconURL("x"), "/icons/x.png")
+
+	if _, err := c.Fetch(ctx, base+"/missing"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Fetch(missing) = %v, want the status", err)
+	}
+	if _, err := c.Fetch(ctx, base+"/huge"); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("Fetch(huge) = %v, want a size error", err)
+	}
+	entries, _ := os.ReadDir(c.Dir())
+	if len(entries) != 0 {
+		t.Errorf("failed downloads left %d entries in the cache", len(entries))
+	}
+}
+
+func TestCacheIcons(t *testing.T) {
+	ctx := context.Background()
+	c, store, _ := newCache(t)
+	msgs := []pushover.ReceivedMessage{
+		{PushoverID: 1, Message: "with icon", Icon: "app", Date: time.Now().Unix()},
+		{PushoverID: 2, Message: "without icon", Date: time.Now().Unix()},
+	}
+	if _, err := store.PersistMessages(ctx, messages.RecordsFromReceived(msgs)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.CacheIcons(ctx, msgs); err != nil {
+		t.Fatalf("CacheIcons: %v", err)
+	}
+	withIcon, _, err := store.GetMessage(ctx, 1)
+	if err != nil || withIcon.IconHash == "" {
+		t.Errorf("message with an icon = %+v, %v; want its icon hash", withIcon, err)
+	}
+	if rec, found, err := store.MediaByHash(ctx, withIcon.IconHash); err != nil || !found || rec.URL != c.IconURL("app") {
+		t.Errorf("MediaByHash() = %+v, %v, %v", rec, found, err)
+	}
+	without, _, err := store.GetMessage(ctx, 2)
+	if err != nil || without.IconHash != "" {
+		t.Errorf("message without an icon = %+v, %v; want no hash", without, err)
+	}
+}
@@ -0,0 +1,207 @@
+// ABOUTME: Questions answered from the phone by acknowledging a notification.
+// ABOUTME: Sends one emergency notification per option and waits for an acknowledgement.
+package messages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// Limits on questions. Pushover expires emergency retries after three hours
+// at most, and more options than fit on a lock screen are hard to pick from.
+const (
+	MaxAskOptions     = 5
+	MinAskTimeout     = 30 * time.Second
+	MaxAskTimeout     = 3 * time.Hour
+	DefaultAskTimeout = 5 * time.Minute
+)
+
+// defaultAskPoll is how often receipts are checked while waiting; Pushover
+// asks clients not to poll a receipt more than once every five seconds.
+const defaultAskPoll = 5 * time.Second
+
+// Question is something to ask the user on their phone.
+type Question struct {
+	Text  string
+	Title string
+	// Options are the possible answers, "Yes" and "No" when empty.
+	Options []string
+	Device  string
+	// App is the [apps] entry client sends with, recorded on the sent log.
+	App     string
+	Timeout time.Duration
+	// PollEvery is how often receipts are checked; zero means every five
+	// seconds.
+	PollEvery time.Duration
+}
+
+// Answer is how a question was answered. Answered is false when nobody
+// picked an option before the timeout.
+type Answer struct {
+	Answered   bool
+	Option     string
+	Index      int
+	AnsweredAt time.Time
+	Device     string
+	Receipts   []string
+}
+
+// Normalize validates q and fills in its defaults. Ask calls it too; callers
+// use it to see the final options before sending.
+func (q *Question) Normalize() error {
+	q.Text = strings.TrimSpace(q.Text)
+	if q.Text == "" {
+		return errors.New("question is required")
+	}
+	var options []string
+	for _, option := range q.Options {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	if len(options) == 0 {
+		options = []string{"Yes", "No"}
+	}
+	if len(options) > MaxAskOptions {
+		return fmt.Errorf("at most %d options are allowed", MaxAskOptions)
+	}
+	q.Options = options
+	if q.Timeout == 0 {
+		q.Timeout = DefaultAskTimeout
+	}
+	if q.Timeout < MinAskTimeout || q.Timeout > MaxAskTimeout {
+		return fmt.Errorf("timeout must be between %s and %s", MinAskTimeout, MaxAskTimeout)
+	}
+	if q.PollEvery <= 0 {
+		q.PollEvery = defaultAskPoll
+	}
+	return nil
+}
+
+// Ask sends q as one emergency notification per option, so acknowledging a
+// notification picks its option, then waits for the first acknowledgement.
+// The other notifications are cancelled once an option is picked or the
+// timeout passes. beforeSend, when set, runs ahead of every notification so
+// callers can apply their send budgets.
+func Ask(ctx context.Context, store *db.Store, client *pushover.Client, q Question, beforeSend func(context.Context) error) (Answer, error) {
+	if err := q.Normalize(); err != nil {
+		return Answer{}, err
+	}
+
+	title := q.Title
+	if title == "" {
+		title = "Question"
+	}
+	records := make([]db.SentRecord, len(q.Options))
+	var answer Answer
+	// Send the last option first so the notifications list in option order,
+	// newest on top.
+	for i := len(q.Options) - 1; i >= 0; i-- {
+		if beforeSend != nil {
+			if err := beforeSend(ctx); err != nil {
+				cancelReceipts(ctx, store, client, records, -1)
+				return Answer{}, err
+			}
+		}
+		params := pushover.SendParams{
+			Title:    fmt.Sprintf("%s: %s", title, q.Options[i]),
+			Message:  fmt.Sprintf("%s\n\nAcknowledge to answer %q.", q.Text, q.Options[i]),
+			Device:   q.Device,
+			Priority: 2,
+			Expire:   q.Timeout,
+		}
+		resp, err := client.Send(ctx, params)
+		if err != nil {
+			cancelReceipts(ctx, store, client, records, -1)
+			return Answer{}, fmt.Errorf("send option %q: %w", q.Options[i], err)
+		}
+		now := time.Now()
+		records[i] = db.SentRecord{
+			Message:          params.Message,
+			Title:            params.Title,
+			Device:           params.Device,
+			Priority:         params.Priority,
+			SentAt:           now,
+			RequestID:        resp.Request,
+			ContentHash:      ContentHash(params.Message, params.Title),
+			Via:              "pushover",
+			App:              q.App,
+			Receipt:          resp.Receipt,
+			ReceiptExpiresAt: now.Add(q.Timeout),
+		}
+		if err := store.LogSent(ctx, records[i]); err != nil {
+			cancelReceipts(ctx, store, client, records, -1)
+			return Answer{}, err
+		}
+	}
+	for _, rec := range records {
+		answer.Receipts = append(answer.Receipts, rec.Receipt)
+	}
+
+	clientFor := func(string) (*pushover.Client, error) { return client, nil }
+	deadline := time.NewTimer(q.Timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(q.PollEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			cancelReceipts(ctx, store, client, records, -1)
+			return Answer{}, ctx.Err()
+		case <-deadline.C:
+			cancelReceipts(ctx, store, client, records, -1)
+			return answer, nil
+		case <-ticker.C:
+		}
+
+		expired := 0
+		for i := range records {
+			if _, err := CheckReceipt(ctx, store, clientFor, &records[i]); err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				return Answer{}, err
+			}
+			switch records[i].ReceiptStatus {
+			case db.ReceiptAcknowledged:
+				cancelReceipts(ctx, store, client, records, i)
+				answer.Answered = true
+				answer.Option = q.Options[i]
+				answer.Index = i
+				answer.AnsweredAt = records[i].ReceiptAckedAt
+				answer.Device = records[i].ReceiptAckedBy
+				return answer, nil
+			case db.ReceiptExpired:
+				expired++
+			}
+		}
+		if expired == len(records) {
+			return answer, nil
+		}
+	}
+}
+
+// cancelReceipts stops the retries of every pending notification in records
+// except the one at keep and records them as expired. It is best effort:
+// anything left repeating stops at the question's timeout anyway.
+func cancelReceipts(ctx context.Context, store *db.Store, client *pushover.Client, records []db.SentRecord, keep int) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	for i := range records {
+		rec := &records[i]
+		if i == keep || rec.Receipt == "" || rec.ReceiptStatus == db.ReceiptAcknowledged || rec.ReceiptStatus == db.ReceiptExpired {
+			continue
+		}
+		if err := client.CancelReceipt(ctx, rec.Receipt); err != nil {
+			continue
+		}
+		rec.ReceiptStatus = db.ReceiptExpired
+		_ = store.UpdateReceipt(ctx, *rec)
+	}
+}
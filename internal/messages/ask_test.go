@@ -0,0 +1,115 @@
+// ABOUTME: Tests for asking the user a question through emergency notifications.
+// ABOUTME: Acknowledges or expires options on the mock API and checks the answer.
+package messages
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+)
+
+// sentOptions waits until n options of a question have been logged.
+func sentOptions(t *testing.T, store *db.Store, n int) []db.SentRecord {
+	t.Helper()
+	for range 200 {
+		sent, err := store.ListSent(context.Background(), db.SentFilter{ReceiptsOnly: true})
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		if len(sent) == n {
+			return sent
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("question never sent %d options", n)
+	return nil
+}
+
+func TestAsk(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "push.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	go func() {
+		for _, rec := range sentOptions(t, store, 3) {
+			if strings.HasPrefix(rec.Title, "Deploy: Wait") {
+				srv.AcknowledgeSent(rec.Receipt, "phone")
+			}
+		}
+	}()
+	answer, err := Ask(ctx, store, srv.Client(), Question{
+		Text:      "Deploy to production?",
+		Title:     "Deploy",
+		Options:   []string{"Ship it", " Wait ", "Abort"},
+		PollEvery: 10 * time.Millisecond,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Ask() error: %v", err)
+	}
+	if !answer.Answered || answer.Option != "Wait" || answer.Index != 1 || answer.Device != "phone" || len(answer.Receipts) != 3 {
+		t.Errorf("Ask() = %+v, want Wait answered on phone", answer)
+	}
+
+	sent := srv.Sent()
+	if len(sent) != 3 || sent[0].Title != "Deploy: Abort" || sent[0].Priority != 2 || sent[0].Expire != DefaultAskTimeout {
+		t.Errorf("sent %+v, want three emergency options, last option first", sent)
+	}
+	logged, err := store.ListSent(ctx, db.SentFilter{ReceiptsOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range logged {
+		want := db.ReceiptExpired
+		if rec.Receipt == answer.Receipts[1] {
+			want = db.ReceiptAcknowledged
+		}
+		if rec.ReceiptStatus != want {
+			t.Errorf("%s: receipt status %q, want %q (others cancelled)", rec.Title, rec.ReceiptStatus, want)
+		}
+	}
+}
+
+func TestAskUnanswered(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "push.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	go func() {
+		for _, rec := range sentOptions(t, store, 2) {
+			srv.ExpireSent(rec.Receipt)
+		}
+	}()
+	answer, err := Ask(context.Background(), store, srv.Client(), Question{Text: "Still there?", PollEvery: 10 * time.Millisecond}, nil)
+	if err != nil {
+		t.Fatalf("Ask() error: %v", err)
+	}
+	if answer.Answered || len(answer.Receipts) != 2 {
+		t.Errorf("Ask() = %+v, want unanswered Yes/No question", answer)
+	}
+
+	for _, q := range []Question{{}, {Text: "x", Options: make([]string, MaxAskOptions+1)}, {Text: "x", Timeout: time.Second}} {
+		for i := range q.Options {
+			q.Options[i] = "option"
+		}
+		if err := q.Normalize(); err == nil {
+			t.Errorf("Normalize(%+v) succeeded, want error", q)
+		}
+	}
+}
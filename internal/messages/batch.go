@@ -0,0 +1,197 @@
+// ABOUTME: Parsing of bulk send files in CSV or JSON Lines form.
+// ABOUTME: Each row is one notification; rows are validated before anything is sent.
+package messages
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Batch file formats.
+const (
+	BatchCSV   = "csv"
+	BatchJSONL = "jsonl"
+)
+
+// BatchRow is one notification from a batch file. Empty fields fall back to
+// the command's flags; Priority is nil when the row doesn't set one.
+type BatchRow struct {
+	Line     int    `json:"-"`
+	Message  string `json:"message"`
+	Title    string `json:"title,omitempty"`
+	Priority *int   `json:"priority,omitempty"`
+	Device   string `json:"device,omitempty"`
+	URL      string `json:"url,omitempty"`
+	URLTitle string `json:"url_title,omitempty"`
+	Sound    string `json:"sound,omitempty"`
+}
+
+// BatchFormatFor picks the format from a file name: .jsonl and .ndjson are
+// JSON Lines, .csv is CSV, and anything else is detected from the content.
+func BatchFormatFor(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".jsonl"), strings.HasSuffix(lower, ".ndjson"):
+		return BatchJSONL
+	case strings.HasSuffix(lower, ".csv"):
+		return BatchCSV
+	}
+	return ""
+}
+
+// ParseBatch reads every row from r. An empty format is detected from the
+// first non-blank character: '{' means JSON Lines, anything else CSV.
+func ParseBatch(r io.Reader, format string) ([]BatchRow, error) {
+	br := bufio.NewReader(r)
+	if format == "" {
+		format = sniffBatchFormat(br)
+	}
+
+	var rows []BatchRow
+	var err error
+	switch format {
+	case BatchCSV:
+		rows, err = parseBatchCSV(br)
+	case BatchJSONL:
+		rows, err = parseBatchJSONL(br)
+	default:
+		return nil, fmt.Errorf("unknown batch format %q (want csv or jsonl)", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("batch file has no rows")
+	}
+	for _, row := range rows {
+		if err := row.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.Line, err)
+		}
+	}
+	return rows, nil
+}
+
+func sniffBatchFormat(br *bufio.Reader) string {
+	peek, _ := br.Peek(512)
+	peek = bytes.TrimLeft(bytes.TrimPrefix(peek, utf8BOM), " \t\r\n")
+	if len(peek) > 0 && peek[0] == '{' {
+		return BatchJSONL
+	}
+	return BatchCSV
+}
+
+// utf8BOM prefixes files saved by some spreadsheet tools.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+func (row BatchRow) validate() error {
+	if strings.TrimSpace(row.Message) == "" {
+		return errors.New("message cannot be empty")
+	}
+	if row.Priority != nil && (*row.Priority < -2 || *row.Priority > 2) {
+		return fmt.Errorf("priority %d is not between -2 and 2", *row.Priority)
+	}
+	return nil
+}
+
+// batchColumns maps CSV header names to row fields.
+var batchColumns = map[string]func(*BatchRow, string) error{
+	"message":   func(r *BatchRow, v string) error { r.Message = v; return nil },
+	"title":     func(r *BatchRow, v string) error { r.Title = v; return nil },
+	"device":    func(r *BatchRow, v string) error { r.Device = v; return nil },
+	"url":       func(r *BatchRow, v string) error { r.URL = v; return nil },
+	"url_title": func(r *BatchRow, v string) error { r.URLTitle = v; return nil },
+	"sound":     func(r *BatchRow, v string) error { r.Sound = v; return nil },
+	"priority": func(r *BatchRow, v string) error {
+		if v = strings.TrimSpace(v); v == "" {
+			return nil
+		}
+		p, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("priority %q is not a number", v)
+		}
+		r.Priority = &p
+		return nil
+	},
+}
+
+// parseBatchCSV reads a CSV file whose header row names the columns.
+func parseBatchCSV(r io.Reader) ([]BatchRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read batch header: %w", err)
+	}
+
+	setters := make([]func(*BatchRow, string) error, len(header))
+	hasMessage := false
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, string(utf8BOM))))
+		set, ok := batchColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown batch column %q (want message, title, priority, device, url, url_title, sound)", name)
+		}
+		setters[i] = set
+		hasMessage = hasMessage || name == "message"
+	}
+	if !hasMessage {
+		return nil, errors.New("batch header has no message column")
+	}
+
+	var rows []BatchRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read batch row: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		row := BatchRow{Line: line}
+		for i, value := range record {
+			if err := setters[i](&row, value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// parseBatchJSONL reads one JSON object per line, skipping blank lines.
+func parseBatchJSONL(r io.Reader) ([]BatchRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []BatchRow
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if line == 1 {
+			text = bytes.TrimPrefix(text, utf8BOM)
+		}
+		if len(text) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(text))
+		dec.DisallowUnknownFields()
+		row := BatchRow{Line: line}
+		if err := dec.Decode(&row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read batch file: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,69 @@
+// ABOUTME: Tests for bulk send file parsing.
+// ABOUTME: Covers CSV and JSON Lines rows, format detection, and row validation.
+package messages
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBatchCSV(t *testing.T) {
+	input := "\xef\xbb\xbfMessage, title, priority,device\n" +
+		"disk full,ops,1,server\n" +
+		"\"multi\nline\",,,\n"
+	rows, err := ParseBatch(strings.NewReader(input), BatchCSV)
+	if err != nil {
+		t.Fatalf("ParseBatch() error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("ParseBatch() returned %d rows, want 2", len(rows))
+	}
+	first := rows[0]
+	if first.Line != 2 || first.Message != "disk full" || first.Title != "ops" || first.Device != "server" || first.Priority == nil || *first.Priority != 1 {
+		t.Errorf("first row = %+v", first)
+	}
+	if second := rows[1]; second.Line != 3 || second.Message != "multi\nline" || second.Priority != nil {
+		t.Errorf("second row = %+v, want a multi-line message without a priority", second)
+	}
+}
+
+func TestParseBatchJSONL(t *testing.T) {
+	input := "\n  {\"message\": \"one\", \"priority\": -1, \"sound\": \"pushover\"}\n\n{\"message\": \"two\", \"url\": \"https://example.com\"}\n"
+	rows, err := ParseBatch(strings.NewReader(input), "")
+	if err != nil {
+		t.Fatalf("ParseBatch() error: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Line != 2 || *rows[0].Priority != -1 || rows[0].Sound != "pushover" || rows[1].Line != 4 || rows[1].URL != "https://example.com" {
+		t.Errorf("ParseBatch() = %+v", rows)
+	}
+}
+
+func TestParseBatchErrors(t *testing.T) {
+	cases := []struct {
+		name, format, input, want string
+	}{
+		{"unknown column", BatchCSV, "message,colour\nhi,red\n", "unknown batch column"},
+		{"no message column", BatchCSV, "title\nhi\n", "no message column"},
+		{"empty message", BatchCSV, "message,title\nok,\n,lonely title\n", "line 3: message cannot be empty"},
+		{"priority out of range", BatchJSONL, "{\"message\":\"hi\",\"priority\":3}\n", "line 1: priority 3"},
+		{"priority not a number", BatchCSV, "message,priority\nhi,high\n", "line 2: priority \"high\""},
+		{"unknown field", BatchJSONL, "{\"message\":\"hi\",\"colour\":\"red\"}\n", "line 1"},
+		{"no rows", BatchCSV, "message\n", "no rows"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseBatch(strings.NewReader(tc.input), tc.format)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("ParseBatch() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestBatchFormatFor(t *testing.T) {
+	for name, want := range map[string]string{"rows.CSV": BatchCSV, "rows.jsonl": BatchJSONL, "rows.ndjson": BatchJSONL, "-": ""} {
+		if got := BatchFormatFor(name); got != want {
+			t.Errorf("BatchFormatFor(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
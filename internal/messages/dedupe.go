@@ -0,0 +1,66 @@
+// ABOUTME: Duplicate detection for outgoing notifications.
+// ABOUTME: Suppresses repeats within a window and annotates the next delivery.
+package messages
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+// DedupeResult describes how a send relates to recent identical sends.
+type DedupeResult struct {
+	Duplicate bool
+	LastSent  time.Time
+	Repeats   int
+}
+
+// ContentHash returns a stable fingerprint for a message/title pair.
+func ContentHash(message, title string) string {
+	sum := sha256.Sum256([]byte(title + "\x00" + message))
+	return hex.EncodeToString(sum[:])
+}
+
+// RecipientContentHash is ContentHash for a send to a named recipient, so the
+// same notification going to several people isn't a duplicate of itself.
+func RecipientContentHash(message, title, recipient string) string {
+	if recipient == "" {
+		return ContentHash(message, title)
+	}
+	sum := sha256.Sum256([]byte(recipient + "\x00" + title + "\x00" + message))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckDuplicate reports whether an identical notification was delivered within
+// window and, if not, how many duplicates were suppressed since the last delivery.
+func CheckDuplicate(ctx context.Context, store *db.Store, hash string, window time.Duration, now time.Time) (DedupeResult, error) {
+	last, found, err := store.LastDeliveredByHash(ctx, hash)
+	if err != nil || !found {
+		return DedupeResult{}, err
+	}
+
+	result := DedupeResult{LastSent: last.SentAt}
+	if now.Sub(last.SentAt) < window {
+		result.Duplicate = true
+		return result, nil
+	}
+
+	result.Repeats, err = store.CountSuppressedSince(ctx, hash, last.SentAt)
+	return result, err
+}
+
+// AnnotateRepeats appends a coalescing note for suppressed duplicates.
+func AnnotateRepeats(message string, repeats int) string {
+	if repeats <= 0 {
+		return message
+	}
+	suffix := "times"
+	if repeats == 1 {
+		suffix = "time"
+	}
+	return fmt.Sprintf("%s\n(repeated %d more %s since last notification)", message, repeats, suffix)
+}
@@ -0,0 +1,83 @@
+// ABOUTME: Tests for duplicate detection on outgoing notifications.
+// ABOUTME: Covers the window, counting suppressed repeats, and the repeat note.
+package messages
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+func TestCheckDuplicate(t *testing.T) {
+	ctx := context.Background()
+	store, err := db.Open(filepath.Join(t.TempDir(), "push.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	hash := ContentHash("disk full", "db1")
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	log := func(at time.Time, suppressed bool, sendErr string) {
+		t.Helper()
+		rec := db.SentRecord{Message: "disk full", Title: "db1", SentAt: at, ContentHash: hash, Suppressed: suppressed, Error: sendErr}
+		if err := store.LogSent(ctx, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if dup, err := CheckDuplicate(ctx, store, hash, time.Minute, start); err != nil || dup.Duplicate || dup.Repeats != 0 {
+		t.Fatalf("first send = %+v, %v; want it delivered", dup, err)
+	}
+	// A failed send doesn't count as delivered.
+	log(start, false, "boom")
+	if dup, err := CheckDuplicate(ctx, store, hash, time.Minute, start.Add(time.Second)); err != nil || dup.Duplicate {
+		t.Fatalf("send after a failure = %+v, %v; want it delivered", dup, err)
+	}
+
+	log(start.Add(time.Second), false, "")
+	dup, err := CheckDuplicate(ctx, store, hash, time.Minute, start.Add(30*time.Second))
+	if err != nil || !dup.Duplicate || !dup.LastSent.Equal(start.Add(time.Second)) {
+		t.Fatalf("repeat inside the window = %+v, %v; want a duplicate of the earlier send", dup, err)
+	}
+	log(start.Add(30*time.Second), true, "")
+	log(start.Add(40*time.Second), true, "")
+
+	dup, err = CheckDuplicate(ctx, store, hash, time.Minute, start.Add(2*time.Minute))
+	if err != nil || dup.Duplicate || dup.Repeats != 2 {
+		t.Errorf("send after the window = %+v, %v; want it delivered noting 2 repeats", dup, err)
+	}
+
+	other := RecipientContentHash("disk full", "db1", "alice")
+	if dup, err := CheckDuplicate(ctx, store, other, time.Minute, start.Add(30*time.Second)); err != nil || dup.Duplicate {
+		t.Errorf("same message to a named recipient = %+v, %v; want it delivered", dup, err)
+	}
+}
+
+func TestContentHashes(t *testing.T) {
+	if ContentHash("a", "b") == ContentHash("b", "a") {
+		t.Error("swapping title and message gave the same hash")
+	}
+	if RecipientContentHash("m", "t", "") != ContentHash("m", "t") {
+		t.Error("a send to the default user hashed differently from ContentHash")
+	}
+	if RecipientContentHash("m", "t", "alice") == RecipientContentHash("m", "t", "bob") {
+		t.Error("sends to different recipients gave the same hash")
+	}
+}
+
+func TestAnnotateRepeats(t *testing.T) {
+	cases := map[int]string{
+		0: "build failed",
+		1: "build failed\n(repeated 1 more time since last notification)",
+		3: "build failed\n(repeated 3 more times since last notification)",
+	}
+	for repeats, want := range cases {
+		if got := AnnotateRepeats("build failed", repeats); got != want {
+			t.Errorf("AnnotateRepeats(%d) = %q, want %q", repeats, got, want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+// ABOUTME: Parsing of message exports for push import, as Pushover JSON or CSV.
+// ABOUTME: Turns each exported message into a record dated when it was sent.
+package messages
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// Import file formats.
+const (
+	ImportPushoverJSON = "pushover-json"
+	ImportCSV          = "csv"
+)
+
+// importedIDBase starts the Pushover IDs given to imported messages that
+// don't have one, far above any ID Pushover hands out.
+const importedIDBase = 1 << 62
+
+// ParseImport reads the messages exported in r.
+//
+// Pushover JSON is the Open Client API's messages.json response, or a bare
+// array of its message objects. CSV has a header row naming the columns
+// date and message (required), id, app, title, priority, url, acked, and
+// html; other columns are ignored.
+func ParseImport(r io.Reader, format string) ([]db.MessageRecord, error) {
+	var records []db.MessageRecord
+	var err error
+	switch format {
+	case ImportPushoverJSON:
+		records, err = parseImportJSON(r)
+	case ImportCSV:
+		records, err = parseImportCSV(r)
+	default:
+		return nil, fmt.Errorf("unknown import format %q (want %s or %s)", format, ImportPushoverJSON, ImportCSV)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("no messages to import")
+	}
+	return records, nil
+}
+
+func parseImportJSON(r io.Reader) ([]db.MessageRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read export: %w", err)
+	}
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, utf8BOM))
+	var raws []json.RawMessage
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &raws)
+	} else {
+		var payload struct {
+			Messages []json.RawMessage `json:"messages"`
+		}
+		err = json.Unmarshal(data, &payload)
+		raws = payload.Messages
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse export: %w", err)
+	}
+
+	records := make([]db.MessageRecord, 0, len(raws))
+	for i, raw := range raws {
+		var msg pushover.ReceivedMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			return nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+		if strings.TrimSpace(msg.Message) == "" {
+			return nil, fmt.Errorf("message %d: message cannot be empty", i+1)
+		}
+		if msg.Date <= 0 {
+			return nil, fmt.Errorf("message %d: no date", i+1)
+		}
+		msg.Raw = raw
+		rec := RecordsFromReceived([]pushover.ReceivedMessage{msg})[0]
+		rec.ReceivedAt = *rec.SentAt
+		if rec.PushoverID <= 0 {
+			rec.PushoverID = importedID(rec)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// importColumns maps CSV header names to record fields.
+var importColumns = map[string]func(*db.MessageRecord, string) error{
+	"message": func(r *db.MessageRecord, v string) error { r.Message = v; return nil },
+	"title":   func(r *db.MessageRecord, v string) error { r.Title = v; return nil },
+	"app":     func(r *db.MessageRecord, v string) error { r.App = v; return nil },
+	"url":     func(r *db.MessageRecord, v string) error { r.URL = v; return nil },
+	"id": func(r *db.MessageRecord, v string) error {
+		if v = strings.TrimSpace(v); v == "" {
+			return nil
+		}
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			return fmt.Errorf("id %q is not a positive number", v)
+		}
+		r.PushoverID = id
+		return nil
+	},
+	"date": func(r *db.MessageRecord, v string) error {
+		sent, err := parseImportDate(strings.TrimSpace(v))
+		if err != nil {
+			return err
+		}
+		r.SentAt, r.ReceivedAt = &sent, sent
+		return nil
+	},
+	"priority": func(r *db.MessageRecord, v string) error {
+		if v = strings.TrimSpace(v); v == "" {
+			return nil
+		}
+		p, err := strconv.Atoi(v)
+		if err != nil || p < -2 || p > 2 {
+			return fmt.Errorf("priority %q is not between -2 and 2", v)
+		}
+		r.Priority = p
+		return nil
+	},
+	"acked": func(r *db.MessageRecord, v string) error { return parseImportBool(&r.Acked, "acked", v) },
+	"html":  func(r *db.MessageRecord, v string) error { return parseImportBool(&r.HTML, "html", v) },
+}
+
+func parseImportCSV(r io.Reader) ([]db.MessageRecord, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read export header: %w", err)
+	}
+
+	setters := make([]func(*db.MessageRecord, string) error, len(header))
+	var hasMessage, hasDate bool
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, string(utf8BOM))))
+		setters[i] = importColumns[name]
+		hasMessage = hasMessage || name == "message"
+		hasDate = hasDate || name == "date"
+	}
+	if !hasMessage || !hasDate {
+		return nil, errors.New("export header needs date and message columns")
+	}
+
+	var records []db.MessageRecord
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read export row: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		var rec db.MessageRecord
+		for i, value := range row {
+			if setters[i] == nil {
+				continue
+			}
+			if err := setters[i](&rec, value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		if strings.TrimSpace(rec.Message) == "" {
+			return nil, fmt.Errorf("line %d: message cannot be empty", line)
+		}
+		if rec.PushoverID == 0 {
+			rec.PushoverID = importedID(rec)
+		}
+		records = append(records, rec)
+	}
+}
+
+// parseImportDate reads Unix seconds or any date dateparse understands, in
+// local time unless it names a zone.
+func parseImportDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("date cannot be empty")
+	}
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil && len(value) >= 9 {
+		return time.Unix(secs, 0), nil
+	}
+	t, err := dateparse.ParseLocal(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date %q: %w", value, err)
+	}
+	return t, nil
+}
+
+func parseImportBool(dst *bool, column, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("%s %q is not true or false", column, value)
+	}
+	*dst = b
+	return nil
+}
+
+// importedID derives an ID from a message's sent time, app, title, and body,
+// so importing the same export twice gives its messages the same IDs.
+func importedID(rec db.MessageRecord) int64 {
+	var sent int64
+	if rec.SentAt != nil {
+		sent = rec.SentAt.Unix()
+	}
+	sum := sha256.Sum256([]byte(strconv.FormatInt(sent, 10) + "\x00" + rec.App + "\x00" + rec.Title + "\x00" + rec.Message))
+	return importedIDBase | int64(binary.BigEndian.Uint64(sum[:8])>>2)
+}
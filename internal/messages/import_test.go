@@ -0,0 +1,92 @@
+// ABOUTME: Tests for parsing message exports for push import.
+// ABOUTME: Covers Pushover JSON in both shapes, CSV columns, dates, and derived IDs.
+package messages
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseImportJSON(t *testing.T) {
+	message := `{"id": 42, "umid": 7, "title": "Backup", "message": "done", "app": "cron", "date": 1700000000, "priority": 1, "acked": 1}`
+	for name, input := range map[string]string{
+		"api response": `{"status": 1, "messages": [` + message + `]}`,
+		"array":        "\xef\xbb\xbf [" + message + "]",
+	} {
+		records, err := ParseImport(strings.NewReader(input), ImportPushoverJSON)
+		if err != nil {
+			t.Fatalf("%s: ParseImport() error: %v", name, err)
+		}
+		rec := records[0]
+		if len(records) != 1 || rec.PushoverID != 42 || rec.UMID != "7" || rec.Title != "Backup" || rec.App != "cron" || rec.Priority != 1 || !rec.Acked {
+			t.Errorf("%s: ParseImport() = %+v", name, records)
+		}
+		if want := time.Unix(1700000000, 0); !rec.ReceivedAt.Equal(want) || rec.SentAt == nil || !rec.SentAt.Equal(want) {
+			t.Errorf("%s: dated %v / %v, want both %v", name, rec.ReceivedAt, rec.SentAt, want)
+		}
+		if rec.RawJSON != message {
+			t.Errorf("%s: RawJSON = %s", name, rec.RawJSON)
+		}
+	}
+
+	for name, input := range map[string]string{
+		"no date":    `[{"id": 1, "message": "x"}]`,
+		"no message": `[{"id": 1, "date": 1700000000}]`,
+		"empty":      `{"messages": []}`,
+		"not json":   `id,message`,
+	} {
+		if _, err := ParseImport(strings.NewReader(input), ImportPushoverJSON); err == nil {
+			t.Errorf("%s: ParseImport() succeeded, want an error", name)
+		}
+	}
+}
+
+func TestParseImportCSV(t *testing.T) {
+	input := "Date,App,Title,Message,Priority,Notes,html\n" +
+		"1700000000,cron,Backup,done,1,ignored,true\n" +
+		"2023-11-14T22:13:20Z,,,\"multi\nline\",,,\n"
+	records, err := ParseImport(strings.NewReader(input), ImportCSV)
+	if err != nil {
+		t.Fatalf("ParseImport() error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("ParseImport() returned %d records, want 2", len(records))
+	}
+	first, second := records[0], records[1]
+	if first.App != "cron" || first.Title != "Backup" || first.Message != "done" || first.Priority != 1 || !first.HTML {
+		t.Errorf("first record = %+v", first)
+	}
+	if !first.SentAt.Equal(*second.SentAt) {
+		t.Errorf("dates %v and %v, want the same instant", first.SentAt, second.SentAt)
+	}
+	if first.PushoverID < importedIDBase || second.PushoverID < importedIDBase || first.PushoverID == second.PushoverID {
+		t.Errorf("derived IDs %d and %d, want distinct IDs from %d up", first.PushoverID, second.PushoverID, int64(importedIDBase))
+	}
+	again, err := ParseImport(strings.NewReader(input), ImportCSV)
+	if err != nil || again[0].PushoverID != first.PushoverID {
+		t.Errorf("reimported ID = %d, want %d", again[0].PushoverID, first.PushoverID)
+	}
+
+	withID, err := ParseImport(strings.NewReader("id,date,message\n99,1700000000,hi\n"), ImportCSV)
+	if err != nil || withID[0].PushoverID != 99 {
+		t.Errorf("ParseImport() with id = %+v, %v", withID, err)
+	}
+
+	for name, input := range map[string]string{
+		"no date column": "message\nhi\n",
+		"empty date":     "date,message\n,hi\n",
+		"bad date":       "date,message\nsometime,hi\n",
+		"empty message":  "date,message\n1700000000,\n",
+		"bad priority":   "date,message,priority\n1700000000,hi,5\n",
+		"bad id":         "id,date,message\n-3,1700000000,hi\n",
+		"header only":    "date,message\n",
+	} {
+		if _, err := ParseImport(strings.NewReader(input), ImportCSV); err == nil {
+			t.Errorf("%s: ParseImport() succeeded, want an error", name)
+		}
+	}
+	if _, err := ParseImport(strings.NewReader(input), "xml"); err == nil {
+		t.Error("ParseImport() with an unknown format succeeded")
+	}
+}
@@ -0,0 +1,73 @@
+// ABOUTME: Length policy for outgoing messages over Pushover's 1024 character limit.
+// ABOUTME: Rejects, truncates, or splits long bodies into numbered parts.
+package messages
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/harper/push/internal/config"
+)
+
+// MaxMessageLength is Pushover's limit on message bodies, in characters.
+const MaxMessageLength = 1024
+
+// FitMessage applies a long message policy and returns the bodies to send.
+// Messages within the limit come back unchanged as a single part.
+func FitMessage(message, policy string) ([]string, error) {
+	length := len([]rune(message))
+	if length <= MaxMessageLength {
+		return []string{message}, nil
+	}
+
+	switch policy {
+	case config.LongMessageTruncate:
+		return []string{string([]rune(message)[:MaxMessageLength-1]) + "…"}, nil
+	case config.LongMessageSplit:
+		return splitMessage(message), nil
+	default:
+		return nil, fmt.Errorf("message is %d characters, over the %d character limit", length, MaxMessageLength)
+	}
+}
+
+// splitMessage breaks message into parts prefixed "(i/n) " that each fit the
+// limit, preferring to break at whitespace.
+func splitMessage(message string) []string {
+	runes := []rune(message)
+	var chunks [][]rune
+	for total := 1; ; {
+		prefix := len([]rune(fmt.Sprintf("(%d/%d) ", total, total)))
+		chunks = chunkRunes(runes, MaxMessageLength-prefix)
+		if len(chunks) <= total {
+			break
+		}
+		total = len(chunks)
+	}
+
+	parts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		parts[i] = fmt.Sprintf("(%d/%d) %s", i+1, len(chunks), string(chunk))
+	}
+	return parts
+}
+
+func chunkRunes(runes []rune, size int) [][]rune {
+	var chunks [][]rune
+	for len(runes) > 0 {
+		if len(runes) <= size {
+			chunks = append(chunks, runes)
+			break
+		}
+		cut := size
+		for i := size; i > size/2; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+		chunks = append(chunks, []rune(strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace)))
+		runes = []rune(strings.TrimLeftFunc(string(runes[cut:]), unicode.IsSpace))
+	}
+	return chunks
+}
@@ -0,0 +1,52 @@
+// ABOUTME: Tests for the long message policy.
+// ABOUTME: Covers rejecting, truncating, and splitting bodies over the limit.
+package messages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/harper/push/internal/config"
+)
+
+func TestFitMessage(t *testing.T) {
+	short := "hello"
+	if parts, err := FitMessage(short, config.LongMessageError); err != nil || len(parts) != 1 || parts[0] != short {
+		t.Fatalf("FitMessage(short) = %v, %v; want unchanged", parts, err)
+	}
+
+	long := strings.TrimSpace(strings.Repeat("word ", 500))
+	if _, err := FitMessage(long, config.LongMessageError); err == nil {
+		t.Error("FitMessage(long, error) succeeded, want error")
+	}
+
+	parts, err := FitMessage(long, config.LongMessageTruncate)
+	if err != nil || len(parts) != 1 {
+		t.Fatalf("FitMessage(long, truncate) = %v, %v", parts, err)
+	}
+	if n := len([]rune(parts[0])); n != MaxMessageLength || !strings.HasSuffix(parts[0], "…") {
+		t.Errorf("truncated part has %d characters, want %d ending in an ellipsis", n, MaxMessageLength)
+	}
+
+	parts, err = FitMessage(long, config.LongMessageSplit)
+	if err != nil {
+		t.Fatalf("FitMessage(long, split) error: %v", err)
+	}
+	if len(parts) != 3 {
+		t.Fatalf("FitMessage(long, split) returned %d parts, want 3", len(parts))
+	}
+	var joined []string
+	for i, part := range parts {
+		if len([]rune(part)) > MaxMessageLength {
+			t.Errorf("part %d has %d characters", i+1, len([]rune(part)))
+		}
+		prefix := "(" + string(rune('1'+i)) + "/3) "
+		if !strings.HasPrefix(part, prefix) || strings.HasSuffix(part, "wor") {
+			t.Errorf("part %d = %q..., want prefix %q and a whitespace break", i+1, part[:10], prefix)
+		}
+		joined = append(joined, strings.TrimPrefix(part, prefix))
+	}
+	if got := strings.Join(joined, " "); got != long {
+		t.Error("split parts do not reassemble into the original message")
+	}
+}
@@ -0,0 +1,24 @@
+// ABOUTME: URL detection for outgoing message bodies.
+// ABOUTME: Finds the first web link so it can fill the supplementary URL field.
+package messages
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// ExtractURL returns the first http(s) URL in text, without trailing punctuation.
+func ExtractURL(text string) (string, bool) {
+	match := urlPattern.FindString(text)
+	match = strings.TrimRight(match, ".,;:!?)]}'")
+	if match == "" {
+		return "", false
+	}
+	if parsed, err := url.Parse(match); err != nil || parsed.Host == "" {
+		return "", false
+	}
+	return match, true
+}
@@ -4,11 +4,13 @@ package messages
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"time"
 
+	"github.com/harper/push/internal/config"
 	"github.com/harper/push/internal/db"
-	"github.com/harper/push/internal/pushover"
+	"github.com/harper/push/pkg/pushover"
 )
 
 // RecordsFromReceived converts API messages into database records.
@@ -33,6 +35,8 @@ func RecordsFromReceived(msgs []pushover.ReceivedMessage) []db.MessageRecord {
 			URL:        msg.URL,
 			Acked:      msg.Acked != 0,
 			HTML:       msg.HTML != 0,
+			Receipt:    msg.Receipt,
+			RawJSON:    string(msg.Raw),
 		}
 		if msg.Date > 0 {
 			sent := time.Unix(msg.Date, 0)
@@ -43,11 +47,62 @@ func RecordsFromReceived(msgs []pushover.ReceivedMessage) []db.MessageRecord {
 	return records
 }
 
-// PersistReceived converts and saves received messages, returning inserted count.
-func PersistReceived(ctx context.Context, store *db.Store, msgs []pushover.ReceivedMessage) (int, error) {
+// ReceivedFromRecord turns a stored message back into the API form, with the
+// stored title, body, and (local) priority. Fields only the API payload has,
+// such as the sound, come from RawJSON when it's kept.
+func ReceivedFromRecord(rec db.MessageRecord) pushover.ReceivedMessage {
+	var msg pushover.ReceivedMessage
+	if rec.RawJSON != "" && json.Unmarshal([]byte(rec.RawJSON), &msg) == nil {
+		msg.Raw = json.RawMessage(rec.RawJSON)
+	}
+	msg.PushoverID = rec.PushoverID
+	if msg.UMIDStr == "" {
+		msg.UMIDStr = rec.UMID
+	}
+	msg.Title = rec.Title
+	msg.Message = rec.Message
+	msg.App = rec.App
+	msg.AID = rec.AID
+	msg.Icon = rec.Icon
+	if rec.SentAt != nil {
+		msg.Date = rec.SentAt.Unix()
+	}
+	msg.Priority = rec.Priority
+	msg.URL = rec.URL
+	msg.Acked = boolToInt(rec.Acked)
+	msg.HTML = boolToInt(rec.HTML)
+	msg.Receipt = rec.Receipt
+	return msg
+}
+
+func boolToInt(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// ApplyLocalPriorities replaces each message's priority with its local one
+// under rules, so it's stored, shown, and passed to hooks at that priority.
+func ApplyLocalPriorities(rules config.LocalPriorities, msgs []pushover.ReceivedMessage) {
+	for i := range msgs {
+		msgs[i].Priority = rules.For(msgs[i].App, msgs[i].Title, msgs[i].Priority)
+	}
+}
+
+// StreamBatch is how many messages a streaming fetch decodes before storing
+// and handling them, bounding memory however large the backlog.
+const StreamBatch = 500
+
+// PersistReceived converts and saves messages received on device, returning
+// inserted count. device may be empty when unknown.
+func PersistReceived(ctx context.Context, store *db.Store, device string, msgs []pushover.ReceivedMessage) (int, error) {
 	if len(msgs) == 0 {
 		return 0, nil
 	}
 	records := RecordsFromReceived(msgs)
+	for i := range records {
+		records[i].Device = device
+	}
 	return store.PersistMessages(ctx, records)
 }
@@ -0,0 +1,63 @@
+// ABOUTME: Shared per-minute send budget enforced across processes.
+// ABOUTME: Either fails fast or waits for a free slot before sending.
+package messages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+// ErrSendBudgetExceeded reports that the configured sends-per-minute budget is used up.
+var ErrSendBudgetExceeded = errors.New("send budget exceeded")
+
+// BudgetError carries the limit and how long until a slot frees up.
+type BudgetError struct {
+	Limit      int
+	RetryAfter time.Duration
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("send budget of %d per minute exhausted; retry in %s", e.Limit, e.RetryAfter.Round(time.Second))
+}
+
+// Unwrap lets errors.Is match ErrSendBudgetExceeded.
+func (e *BudgetError) Unwrap() error {
+	return ErrSendBudgetExceeded
+}
+
+// AcquireSendSlot reserves one send against the shared per-minute budget. With
+// wait set it sleeps until a slot frees up (calling onWait before each sleep);
+// otherwise it returns a *BudgetError immediately. A limit of zero disables it.
+func AcquireSendSlot(ctx context.Context, store *db.Store, perMinute int, wait bool, onWait func(time.Duration)) error {
+	if perMinute <= 0 {
+		return nil
+	}
+
+	for {
+		ok, retry, err := store.ReserveSendSlot(ctx, perMinute, time.Minute, time.Now())
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if !wait {
+			return &BudgetError{Limit: perMinute, RetryAfter: retry}
+		}
+		if onWait != nil {
+			onWait(retry)
+		}
+
+		timer := time.NewTimer(retry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// ABOUTME: Tests for acquiring send slots from the shared budget.
+// ABOUTME: Covers failing fast, waiting until cancelled, and a disabled budget.
+package messages
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+func TestAcquireSendSlot(t *testing.T) {
+	ctx := context.Background()
+	store, err := db.Open(filepath.Join(t.TempDir(), "push.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	for i := range 2 {
+		if err := AcquireSendSlot(ctx, store, 2, false, nil); err != nil {
+			t.Fatalf("send %d: %v", i+1, err)
+		}
+	}
+
+	err = AcquireSendSlot(ctx, store, 2, false, nil)
+	var budget *BudgetError
+	if !errors.Is(err, ErrSendBudgetExceeded) || !errors.As(err, &budget) {
+		t.Fatalf("third send = %v, want a BudgetError", err)
+	}
+	if budget.Limit != 2 || budget.RetryAfter <= 0 || budget.RetryAfter > time.Minute {
+		t.Errorf("BudgetError = %+v, want limit 2 and a retry within a minute", budget)
+	}
+
+	if err := AcquireSendSlot(ctx, store, 0, false, nil); err != nil {
+		t.Errorf("send with the budget disabled: %v", err)
+	}
+}
+
+func TestAcquireSendSlotWaits(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "push.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	if err := AcquireSendSlot(context.Background(), store, 1, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The only slot is taken for a minute, so a waiting send sleeps until
+	// it is cancelled.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var waits []time.Duration
+	err = AcquireSendSlot(ctx, store, 1, true, func(d time.Duration) { waits = append(waits, d) })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("waiting send = %v, want the context deadline", err)
+	}
+	if len(waits) != 1 || waits[0] <= 0 {
+		t.Errorf("onWait calls = %v, want one with a positive wait", waits)
+	}
+}
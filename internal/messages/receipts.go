@@ -0,0 +1,85 @@
+// ABOUTME: Tracking of emergency sends through their receipts.
+// ABOUTME: Polls pending receipts and records acknowledgement or expiry on the sent log.
+package messages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// ApplyReceipt copies status onto rec and reports whether its receipt state
+// changed.
+func ApplyReceipt(rec *db.SentRecord, status *pushover.ReceiptStatus) bool {
+	before := *rec
+	rec.ReceiptExpiresAt = status.ExpiresAt
+	switch {
+	case status.Acknowledged:
+		rec.ReceiptStatus = db.ReceiptAcknowledged
+		rec.ReceiptAckedAt = status.AcknowledgedAt
+		rec.ReceiptAckedBy = status.AcknowledgedByDevice
+	case status.Expired:
+		rec.ReceiptStatus = db.ReceiptExpired
+	default:
+		rec.ReceiptStatus = db.ReceiptPending
+	}
+	return rec.ReceiptStatus != before.ReceiptStatus ||
+		!rec.ReceiptAckedAt.Equal(before.ReceiptAckedAt) ||
+		rec.ReceiptAckedBy != before.ReceiptAckedBy ||
+		!rec.ReceiptExpiresAt.Equal(before.ReceiptExpiresAt)
+}
+
+// ReceiptClient returns the client for sends made with the named [apps]
+// entry, or app_token when app is empty; receipts can only be read with
+// the token that sent them.
+type ReceiptClient func(app string) (*pushover.Client, error)
+
+// SyncReceipts checks every pending receipt and stores what changed,
+// returning the sends that were acknowledged or expired. Receipts Pushover no
+// longer knows about are marked expired. A failed check is reported after the
+// rest are done.
+func SyncReceipts(ctx context.Context, store *db.Store, clientFor ReceiptClient) ([]db.SentRecord, error) {
+	pending, err := store.ListSent(ctx, db.SentFilter{ReceiptStatus: db.ReceiptPending})
+	if err != nil {
+		return nil, err
+	}
+
+	var settled []db.SentRecord
+	var errs []error
+	for _, rec := range pending {
+		changed, err := CheckReceipt(ctx, store, clientFor, &rec)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if changed && rec.ReceiptStatus != db.ReceiptPending {
+			settled = append(settled, rec)
+		}
+	}
+	return settled, errors.Join(errs...)
+}
+
+// CheckReceipt fetches the current state of rec's receipt, storing and
+// reporting any change.
+func CheckReceipt(ctx context.Context, store *db.Store, clientFor ReceiptClient, rec *db.SentRecord) (bool, error) {
+	client, err := clientFor(rec.App)
+	if err != nil {
+		return false, err
+	}
+	status, err := client.Receipt(ctx, rec.Receipt)
+	var apiErr *pushover.APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		status, err = &pushover.ReceiptStatus{Expired: true, ExpiresAt: rec.ReceiptExpiresAt}, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("check receipt %s: %w", rec.Receipt, err)
+	}
+	if !ApplyReceipt(rec, status) {
+		return false, nil
+	}
+	return true, store.UpdateReceipt(ctx, *rec)
+}
@@ -0,0 +1,70 @@
+// ABOUTME: Tests for emergency receipt tracking.
+// ABOUTME: Syncs pending receipts against the mock API and checks the sent log.
+package messages
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/pushover"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+)
+
+func TestSyncReceipts(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "push.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	client := srv.Client()
+	var receipts []string
+	for _, message := range []string{"acked", "expired", "waiting"} {
+		resp, err := client.Send(ctx, pushover.SendParams{Message: message, Priority: 2})
+		if err != nil {
+			t.Fatalf("send %s: %v", message, err)
+		}
+		receipts = append(receipts, resp.Receipt)
+		if err := store.LogSent(ctx, db.SentRecord{Message: message, Priority: 2, Receipt: resp.Receipt}); err != nil {
+			t.Fatalf("log %s: %v", message, err)
+		}
+	}
+	if err := store.LogSent(ctx, db.SentRecord{Message: "forgotten", Priority: 2, Receipt: "unknown-receipt"}); err != nil {
+		t.Fatal(err)
+	}
+	srv.AcknowledgeSent(receipts[0], "phone")
+	srv.ExpireSent(receipts[1])
+
+	clientFor := func(string) (*pushover.Client, error) { return client, nil }
+	settled, err := SyncReceipts(ctx, store, clientFor)
+	if err != nil {
+		t.Fatalf("SyncReceipts() error: %v", err)
+	}
+	if len(settled) != 3 {
+		t.Fatalf("SyncReceipts() settled %d sends, want 3: %+v", len(settled), settled)
+	}
+
+	want := map[string]string{"acked": db.ReceiptAcknowledged, "expired": db.ReceiptExpired, "waiting": db.ReceiptPending, "forgotten": db.ReceiptExpired}
+	sent, err := store.ListSent(ctx, db.SentFilter{ReceiptsOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range sent {
+		if rec.ReceiptStatus != want[rec.Message] {
+			t.Errorf("%s: receipt status %q, want %q", rec.Message, rec.ReceiptStatus, want[rec.Message])
+		}
+		if rec.Message == "acked" && (rec.ReceiptAckedBy != "phone" || rec.ReceiptAckedAt.IsZero()) {
+			t.Errorf("acked send = %+v, want acknowledgement details", rec)
+		}
+	}
+
+	if settled, err := SyncReceipts(ctx, store, clientFor); err != nil || len(settled) != 0 {
+		t.Errorf("second SyncReceipts() = %v, %v; want nothing new", settled, err)
+	}
+}
@@ -0,0 +1,136 @@
+// ABOUTME: HTTP uptime checks and the up/down state machine behind push monitor.
+// ABOUTME: A state changes only after enough checks in a row agree, so flapping stays quiet.
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+// maxBodyRead bounds how much of a response is read before closing it.
+const maxBodyRead = 64 << 10
+
+// Expect is the set of acceptable status codes.
+type Expect struct {
+	codes   []int
+	classes []int
+}
+
+// ParseExpect parses a comma-separated list of status codes and classes,
+// e.g. "200", "2xx", or "200,301,302".
+func ParseExpect(value string) (Expect, error) {
+	var e Expect
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if len(item) == 3 && strings.HasSuffix(item, "xx") && item[0] >= '1' && item[0] <= '5' {
+			e.classes = append(e.classes, int(item[0]-'0'))
+			continue
+		}
+		code, err := strconv.Atoi(item)
+		if err != nil || code < 100 || code > 599 {
+			return Expect{}, fmt.Errorf("expected status %q must be a code like 200 or a class like 2xx", item)
+		}
+		e.codes = append(e.codes, code)
+	}
+	return e, nil
+}
+
+// Matches reports whether status is acceptable.
+func (e Expect) Matches(status int) bool {
+	for _, code := range e.codes {
+		if code == status {
+			return true
+		}
+	}
+	for _, class := range e.classes {
+		if status/100 == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Up      bool
+	Status  int
+	Latency time.Duration
+	Err     error
+}
+
+// String describes the result for listings and notifications.
+func (r Result) String() string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+	return fmt.Sprintf("%d in %s", r.Status, r.Latency.Round(time.Millisecond))
+}
+
+// Check requests m's URL with client and compares the status with m.Expect.
+func Check(ctx context.Context, client *http.Client, m db.MonitorRecord) Result {
+	expect, err := ParseExpect(m.Expect)
+	if err != nil {
+		return Result{Err: err}
+	}
+	if m.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.URL, nil)
+	if err != nil {
+		return Result{Err: fmt.Errorf("build request: %w", err)}
+	}
+	req.Header.Set("User-Agent", "push-monitor")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return Result{Latency: time.Since(start), Err: fmt.Errorf("no response within %s", m.Timeout)}
+		}
+		return Result{Latency: time.Since(start), Err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodyRead))
+
+	result := Result{Status: resp.StatusCode, Latency: time.Since(start), Up: expect.Matches(resp.StatusCode)}
+	if !result.Up {
+		result.Err = fmt.Errorf("status %d, expected %s", resp.StatusCode, m.Expect)
+	}
+	return result
+}
+
+// Apply records result on m as checked at now and reports whether the
+// monitor changed state. An unknown monitor takes the first result as its
+// state; after that, m.Confirm results in a row must disagree before it
+// flips, and disagreeing results are forgotten as soon as one agrees.
+func Apply(m *db.MonitorRecord, result Result, now time.Time) bool {
+	m.LastChecked = now
+	m.LastResult = result.String()
+	observed := db.MonitorDown
+	if result.Up {
+		observed = db.MonitorUp
+	}
+
+	if m.State == observed {
+		m.Streak = 0
+		return false
+	}
+	m.Streak++
+	if m.State != db.MonitorUnknown && m.Streak < max(m.Confirm, 1) {
+		return false
+	}
+	m.State = observed
+	m.Streak = 0
+	m.LastChange = now
+	return true
+}
@@ -0,0 +1,94 @@
+// ABOUTME: Tests for URL checks and the monitor state machine.
+// ABOUTME: Covers expected status parsing, timeouts, and flap suppression.
+package monitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+func TestParseExpect(t *testing.T) {
+	expect, err := ParseExpect("200, 3xx")
+	if err != nil {
+		t.Fatalf("ParseExpect() error: %v", err)
+	}
+	for status, want := range map[int]bool{200: true, 301: true, 399: true, 204: false, 500: false} {
+		if got := expect.Matches(status); got != want {
+			t.Errorf("Matches(%d) = %v, want %v", status, got, want)
+		}
+	}
+	for _, bad := range []string{"", "ok", "99", "600", "6xx", "2x"} {
+		if _, err := ParseExpect(bad); err == nil {
+			t.Errorf("ParseExpect(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/broken":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path, expect string
+		timeout      time.Duration
+		up           bool
+		describe     string
+	}{
+		{"/", "200", time.Second, true, "200 in"},
+		{"/broken", "2xx", time.Second, false, "status 503, expected 2xx"},
+		{"/broken", "200,503", time.Second, true, "503 in"},
+		{"/slow", "200", 50 * time.Millisecond, false, "no response within 50ms"},
+	}
+	for _, tt := range tests {
+		result := Check(t.Context(), srv.Client(), db.MonitorRecord{URL: srv.URL + tt.path, Expect: tt.expect, Timeout: tt.timeout})
+		if result.Up != tt.up || !strings.Contains(result.String(), tt.describe) {
+			t.Errorf("Check(%s, %s) = %v %q, want up=%v containing %q", tt.path, tt.expect, result.Up, result, tt.up, tt.describe)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	up := Result{Up: true, Status: 200}
+	down := Result{Status: 500}
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	m := db.MonitorRecord{State: db.MonitorUnknown, Confirm: 2}
+
+	steps := []struct {
+		result  Result
+		changed bool
+		state   string
+	}{
+		{up, true, db.MonitorUp},     // the first result settles an unknown monitor
+		{down, false, db.MonitorUp},  // one failure is not enough
+		{up, false, db.MonitorUp},    // and is forgotten after a success
+		{down, false, db.MonitorUp},  //
+		{down, true, db.MonitorDown}, // two in a row flip it
+		{down, false, db.MonitorDown},
+		{up, false, db.MonitorDown},
+		{up, true, db.MonitorUp},
+	}
+	for i, step := range steps {
+		now := start.Add(time.Duration(i) * time.Minute)
+		if changed := Apply(&m, step.result, now); changed != step.changed || m.State != step.state {
+			t.Fatalf("step %d: changed=%v state=%s, want changed=%v state=%s", i, changed, m.State, step.changed, step.state)
+		}
+		if m.LastChecked != now {
+			t.Errorf("step %d: LastChecked = %s, want %s", i, m.LastChecked, now)
+		}
+	}
+	if want := start.Add(7 * time.Minute); m.LastChange != want {
+		t.Errorf("LastChange = %s, want %s", m.LastChange, want)
+	}
+}
@@ -0,0 +1,89 @@
+// ABOUTME: Gotify notification backend.
+// ABOUTME: Posts messages to a self-hosted Gotify server's message API.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/pkg/pushover"
+)
+
+type gotifyNotifier struct {
+	cfg  config.GotifyConfig
+	http *http.Client
+}
+
+func newGotify(cfg config.GotifyConfig, client *http.Client) (*gotifyNotifier, error) {
+	if strings.TrimSpace(cfg.Server) == "" {
+		return nil, errors.New("gotify: server is not configured ([gotify] server)")
+	}
+	if strings.TrimSpace(cfg.Token) == "" {
+		return nil, errors.New("gotify: app token is not configured ([gotify] token)")
+	}
+	return &gotifyNotifier{cfg: cfg, http: client}, nil
+}
+
+// gotifyPriority maps Pushover's -2..2 scale onto Gotify's 0..10 scale.
+func gotifyPriority(priority int) int {
+	return []int{0, 2, 5, 8, 10}[min(max(priority+2, 0), 4)]
+}
+
+func (g *gotifyNotifier) Send(ctx context.Context, params pushover.SendParams) (*pushover.SendResponse, error) {
+	if strings.TrimSpace(params.Message) == "" {
+		return nil, fmt.Errorf("message cannot be empty")
+	}
+
+	body := map[string]any{
+		"message":  params.Message,
+		"priority": gotifyPriority(params.Priority),
+	}
+	if params.Title != "" {
+		body["title"] = params.Title
+	}
+	if params.URL != "" {
+		body["extras"] = map[string]any{
+			"client::notification": map[string]any{"click": map[string]string{"url": params.URL}},
+		}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("gotify: encode message: %w", err)
+	}
+
+	endpoint := strings.TrimRight(g.cfg.Server, "/") + "/message?token=" + url.QueryEscape(g.cfg.Token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gotify: %w", err)
+	}
+	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
+
+	if err := checkStatus(resp, "gotify"); err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		ID int64 `json:"id"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&payload)
+	out := &pushover.SendResponse{Status: 1}
+	if payload.ID > 0 {
+		out.Request = strconv.FormatInt(payload.ID, 10)
+	}
+	return out, nil
+}
@@ -0,0 +1,82 @@
+// ABOUTME: Notification backend abstraction for outgoing messages.
+// ABOUTME: Selects Pushover, ntfy, Gotify, or a webhook from configuration.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// Backend names accepted by --via and default_via.
+const (
+	Pushover = "pushover"
+	Ntfy     = "ntfy"
+	Gotify   = "gotify"
+	Webhook  = "webhook"
+)
+
+// Notifier delivers a notification through a single backend.
+// *pushover.Client satisfies it directly.
+type Notifier interface {
+	Send(ctx context.Context, params pushover.SendParams) (*pushover.SendResponse, error)
+}
+
+// Backends lists every supported backend name.
+func Backends() []string {
+	return []string{Pushover, Ntfy, Gotify, Webhook}
+}
+
+// Resolve returns the backend name to use, falling back to config and then Pushover.
+func Resolve(cfg *config.Config, via string) string {
+	via = strings.ToLower(strings.TrimSpace(via))
+	if via == "" && cfg != nil {
+		via = strings.ToLower(strings.TrimSpace(cfg.DefaultVia))
+	}
+	if via == "" {
+		return Pushover
+	}
+	return via
+}
+
+// New builds the notifier for the named backend. The Pushover client is used
+// as-is for the pushover backend so callers keep its retry and limiter settings.
+func New(cfg *config.Config, via string, client *pushover.Client) (Notifier, error) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	httpClient, err := pushover.NewHTTPClient(pushover.HTTPClientOptions{
+		ProxyURL:   cfg.ProxyURL,
+		CACertPath: cfg.CACertPath,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch Resolve(cfg, via) {
+	case Pushover:
+		if err := cfg.ValidateSend(); err != nil {
+			return nil, err
+		}
+		return client, nil
+	case Ntfy:
+		return newNtfy(cfg.Ntfy, httpClient)
+	case Gotify:
+		return newGotify(cfg.Gotify, httpClient)
+	case Webhook:
+		return newWebhook(cfg.Webhook, httpClient)
+	default:
+		return nil, fmt.Errorf("unknown backend %q (expected one of %s)", via, strings.Join(Backends(), ", "))
+	}
+}
+
+func checkStatus(resp *http.Response, backend string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return fmt.Errorf("%s: unexpected status %s", backend, resp.Status)
+}
@@ -0,0 +1,195 @@
+// ABOUTME: Tests for the notification backends.
+// ABOUTME: Covers backend selection and what ntfy, Gotify, and webhooks receive.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// captured is one request a fake backend received.
+type captured struct {
+	path   string
+	query  string
+	header http.Header
+	body   string
+}
+
+// fakeBackend answers every request with status and body, recording it.
+func fakeBackend(t *testing.T, status int, body string) (*httptest.Server, *captured) {
+	t.Helper()
+	got := &captured{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		*got = captured{path: r.URL.Path, query: r.URL.RawQuery, header: r.Header.Clone(), body: string(data)}
+		w.Header().Set("X-Request-Id", "hook-1")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, got
+}
+
+func TestResolve(t *testing.T) {
+	cases := []struct {
+		via, defaultVia, want string
+	}{
+		{"", "", Pushover},
+		{"", "Gotify", Gotify},
+		{" NTFY ", "gotify", Ntfy},
+		{"webhook", "", Webhook},
+	}
+	for _, tc := range cases {
+		if got := Resolve(&config.Config{DefaultVia: tc.defaultVia}, tc.via); got != tc.want {
+			t.Errorf("Resolve(default %q, via %q) = %q, want %q", tc.defaultVia, tc.via, got, tc.want)
+		}
+	}
+	if got := Resolve(nil, ""); got != Pushover {
+		t.Errorf("Resolve(nil config) = %q, want pushover", got)
+	}
+}
+
+func TestNewNeedsConfiguration(t *testing.T) {
+	cases := map[string]string{
+		Pushover: "app token is missing",
+		Ntfy:     "[ntfy] topic",
+		Gotify:   "[gotify] server",
+		Webhook:  "[webhook] url",
+		"pigeon": "unknown backend",
+	}
+	for via, want := range cases {
+		if _, err := New(&config.Config{}, via, nil); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("New(%q) = %v, want an error mentioning %q", via, err, want)
+		}
+	}
+
+	client := pushover.NewClient("token", "user", "", "")
+	n, err := New(&config.Config{AppToken: "token", UserKey: "user"}, "", client)
+	if err != nil || n != Notifier(client) {
+		t.Errorf("New(pushover) = %v, %v; want the Pushover client itself", n, err)
+	}
+}
+
+func TestNtfySend(t *testing.T) {
+	srv, got := fakeBackend(t, http.StatusOK, `{"id":"abc123"}`)
+	n, err := New(&config.Config{Ntfy: config.NtfyConfig{Server: srv.URL + "/", Topic: "alerts", Token: "tk"}}, Ntfy, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := n.Send(context.Background(), pushover.SendParams{Message: "disk full", Title: "db1", Priority: 2, URL: "https://example.com", HTML: true})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if resp.Request != "abc123" {
+		t.Errorf("request = %q, want the ntfy message id", resp.Request)
+	}
+	if got.path != "/alerts" || got.body != "disk full" {
+		t.Errorf("posted %q to %s, want the message to /alerts", got.body, got.path)
+	}
+	want := map[string]string{"Title": "db1", "Priority": "5", "Click": "https://example.com", "Markdown": "yes", "Authorization": "Bearer tk"}
+	for key, value := range want {
+		if got.header.Get(key) != value {
+			t.Errorf("header %s = %q, want %q", key, got.header.Get(key), value)
+		}
+	}
+}
+
+func TestGotifySend(t *testing.T) {
+	srv, got := fakeBackend(t, http.StatusOK, `{"id":42}`)
+	n, err := New(&config.Config{Gotify: config.GotifyConfig{Server: srv.URL, Token: "a&b"}}, Gotify, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := n.Send(context.Background(), pushover.SendParams{Message: "deployed", Title: "ci", Priority: -1, URL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if resp.Request != "42" {
+		t.Errorf("request = %q, want 42", resp.Request)
+	}
+	if got.path != "/message" || got.query != "token=a%26b" {
+		t.Errorf("posted to %s?%s, want /message with the escaped token", got.path, got.query)
+	}
+	var body struct {
+		Message  string         `json:"message"`
+		Title    string         `json:"title"`
+		Priority int            `json:"priority"`
+		Extras   map[string]any `json:"extras"`
+	}
+	if err := json.Unmarshal([]byte(got.body), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Message != "deployed" || body.Title != "ci" || body.Priority != 2 || body.Extras["client::notification"] == nil {
+		t.Errorf("body = %s, want the message, title, priority 2, and a click URL", got.body)
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	srv, got := fakeBackend(t, http.StatusAccepted, "")
+	n, err := New(&config.Config{Webhook: config.WebhookConfig{URL: srv.URL + "/hook", Headers: map[string]string{"X-Secret": "s3"}}}, Webhook, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := n.Send(context.Background(), pushover.SendParams{Message: "hi", Sound: "siren", Device: "phone"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if resp.Request != "hook-1" {
+		t.Errorf("request = %q, want the X-Request-Id header", resp.Request)
+	}
+	if got.path != "/hook" || got.header.Get("X-Secret") != "s3" || got.header.Get("Content-Type") != "application/json" {
+		t.Errorf("posted to %s with headers %v, want /hook with the configured header", got.path, got.header)
+	}
+	var body webhookPayload
+	if err := json.Unmarshal([]byte(got.body), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body != (webhookPayload{Message: "hi", Sound: "siren", Device: "phone"}) {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+func TestSendFailures(t *testing.T) {
+	srv, _ := fakeBackend(t, http.StatusForbidden, "")
+	cfg := &config.Config{
+		Ntfy:    config.NtfyConfig{Server: srv.URL, Topic: "t"},
+		Gotify:  config.GotifyConfig{Server: srv.URL, Token: "t"},
+		Webhook: config.WebhookConfig{URL: srv.URL},
+	}
+	for _, via := range []string{Ntfy, Gotify, Webhook} {
+		n, err := New(cfg, via, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := n.Send(context.Background(), pushover.SendParams{Message: "x"}); err == nil || !strings.Contains(err.Error(), "403") {
+			t.Errorf("%s Send with a 403 = %v, want the status in the error", via, err)
+		}
+		if _, err := n.Send(context.Background(), pushover.SendParams{Message: "  "}); err == nil {
+			t.Errorf("%s Send with an empty message succeeded", via)
+		}
+	}
+}
+
+func TestPriorityScales(t *testing.T) {
+	for priority, want := range map[int]int{-3: 1, -2: 1, -1: 2, 0: 3, 1: 4, 2: 5, 3: 5} {
+		if got := ntfyPriority(priority); got != want {
+			t.Errorf("ntfyPriority(%d) = %d, want %d", priority, got, want)
+		}
+	}
+	for priority, want := range map[int]int{-3: 0, -2: 0, -1: 2, 0: 5, 1: 8, 2: 10, 3: 10} {
+		if got := gotifyPriority(priority); got != want {
+			t.Errorf("gotifyPriority(%d) = %d, want %d", priority, got, want)
+		}
+	}
+}
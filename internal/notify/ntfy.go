@@ -0,0 +1,80 @@
+// ABOUTME: ntfy.sh notification backend.
+// ABOUTME: Publishes messages to a topic using ntfy's header-based HTTP API.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/pkg/pushover"
+)
+
+const defaultNtfyServer = "https://ntfy.sh"
+
+type ntfyNotifier struct {
+	cfg  config.NtfyConfig
+	http *http.Client
+}
+
+func newNtfy(cfg config.NtfyConfig, client *http.Client) (*ntfyNotifier, error) {
+	if strings.TrimSpace(cfg.Topic) == "" {
+		return nil, errors.New("ntfy: topic is not configured ([ntfy] topic)")
+	}
+	if cfg.Server == "" {
+		cfg.Server = defaultNtfyServer
+	}
+	return &ntfyNotifier{cfg: cfg, http: client}, nil
+}
+
+// ntfyPriority maps Pushover's -2..2 scale onto ntfy's 1..5 scale.
+func ntfyPriority(priority int) int {
+	return min(max(priority+3, 1), 5)
+}
+
+func (n *ntfyNotifier) Send(ctx context.Context, params pushover.SendParams) (*pushover.SendResponse, error) {
+	if strings.TrimSpace(params.Message) == "" {
+		return nil, fmt.Errorf("message cannot be empty")
+	}
+
+	endpoint := strings.TrimRight(n.cfg.Server, "/") + "/" + n.cfg.Topic
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(params.Message))
+	if err != nil {
+		return nil, err
+	}
+	if params.Title != "" {
+		req.Header.Set("Title", params.Title)
+	}
+	req.Header.Set("Priority", strconv.Itoa(ntfyPriority(params.Priority)))
+	if params.URL != "" {
+		req.Header.Set("Click", params.URL)
+	}
+	if params.HTML {
+		req.Header.Set("Markdown", "yes")
+	}
+	if n.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.cfg.Token)
+	}
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ntfy: %w", err)
+	}
+	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
+
+	if err := checkStatus(resp, "ntfy"); err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		ID string `json:"id"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&payload)
+	return &pushover.SendResponse{Status: 1, Request: payload.ID}, nil
+}
@@ -0,0 +1,79 @@
+// ABOUTME: Generic webhook notification backend.
+// ABOUTME: POSTs the notification as JSON to a configured URL.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/pkg/pushover"
+)
+
+type webhookNotifier struct {
+	cfg  config.WebhookConfig
+	http *http.Client
+}
+
+// webhookPayload is the JSON body delivered to webhook receivers.
+type webhookPayload struct {
+	Message  string `json:"message"`
+	Title    string `json:"title,omitempty"`
+	Priority int    `json:"priority"`
+	URL      string `json:"url,omitempty"`
+	URLTitle string `json:"url_title,omitempty"`
+	Sound    string `json:"sound,omitempty"`
+	Device   string `json:"device,omitempty"`
+}
+
+func newWebhook(cfg config.WebhookConfig, client *http.Client) (*webhookNotifier, error) {
+	if strings.TrimSpace(cfg.URL) == "" {
+		return nil, errors.New("webhook: url is not configured ([webhook] url)")
+	}
+	return &webhookNotifier{cfg: cfg, http: client}, nil
+}
+
+func (w *webhookNotifier) Send(ctx context.Context, params pushover.SendParams) (*pushover.SendResponse, error) {
+	if strings.TrimSpace(params.Message) == "" {
+		return nil, fmt.Errorf("message cannot be empty")
+	}
+
+	data, err := json.Marshal(webhookPayload{
+		Message:  params.Message,
+		Title:    params.Title,
+		Priority: params.Priority,
+		URL:      params.URL,
+		URLTitle: params.URLTitle,
+		Sound:    params.Sound,
+		Device:   params.Device,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("webhook: encode message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
+
+	if err := checkStatus(resp, "webhook"); err != nil {
+		return nil, err
+	}
+	return &pushover.SendResponse{Status: 1, Request: resp.Header.Get("X-Request-Id")}, nil
+}
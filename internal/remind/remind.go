@@ -0,0 +1,585 @@
+// ABOUTME: Splits "call dentist tomorrow at 3pm" into what to remind about and when.
+// ABOUTME: Understands relative offsets, day words, weekdays, clock times, and dates.
+package remind
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+// defaultHour is when a reminder for a day without a time goes off.
+const defaultHour = 9
+
+// partHours are the times named parts of the day stand for.
+var partHours = map[string]int{
+	"morning":   9,
+	"afternoon": 15,
+	"evening":   18,
+	"night":     20,
+	"tonight":   20,
+}
+
+// weekdays leaves out "sun", "sat", and "wed", which are everyday words.
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var units = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+var (
+	clockPattern   = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm|a\.m\.|p\.m\.)?$`)
+	ordinalPattern = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)?$`)
+	offsetPattern  = regexp.MustCompile(`^(\d+)([a-z]+)$`)
+)
+
+// ErrNoTime means no time phrase was found in the text.
+var ErrNoTime = errors.New(`no time found; say when, e.g. "in 20 minutes", "tomorrow at 3pm", or "friday morning"`)
+
+// Reminder is a parsed reminder.
+type Reminder struct {
+	// Message is the text with the time phrase taken out.
+	Message string
+	At      time.Time
+	// Phrase is the part of the text read as the time.
+	Phrase string
+}
+
+// Parse finds the time phrase in text, relative to now, and returns what is
+// left as the message. The longest phrase that parses wins; ties go to one at
+// the end of the text, then at the start.
+func Parse(text string, now time.Time) (Reminder, error) {
+	words := strings.Fields(text)
+	n := len(words)
+	for length := n; length > 0; length-- {
+		for _, start := range spanStarts(n, length) {
+			at, ok := parsePhrase(words[start:start+length], now)
+			if !ok {
+				continue
+			}
+			rest := append(append([]string{}, words[:start]...), words[start+length:]...)
+			message := cleanMessage(rest)
+			if message == "" {
+				return Reminder{}, errors.New("nothing to remind about; add what the reminder is for")
+			}
+			if !at.After(now) {
+				return Reminder{}, fmt.Errorf("%q is in the past", strings.Join(words[start:start+length], " "))
+			}
+			return Reminder{Message: message, At: at, Phrase: strings.Join(words[start:start+length], " ")}, nil
+		}
+	}
+	return Reminder{}, ErrNoTime
+}
+
+// ParseTime reads all of phrase as a time, relative to now, falling back to
+// absolute dates such as "2026-11-02 15:00".
+func ParseTime(phrase string, now time.Time) (time.Time, error) {
+	at, ok := parsePhrase(strings.Fields(phrase), now)
+	if !ok {
+		parsed, err := dateparse.ParseIn(strings.TrimSpace(phrase), now.Location())
+		if err != nil {
+			return time.Time{}, fmt.Errorf("can't read %q as a time", phrase)
+		}
+		at = parsed
+	}
+	if !at.After(now) {
+		return time.Time{}, fmt.Errorf("%q is in the past", phrase)
+	}
+	return at, nil
+}
+
+// spanStarts orders the start positions of spans of length in n words: the
+// span ending the text, the one starting it, then the rest left to right.
+func spanStarts(n, length int) []int {
+	starts := []int{n - length}
+	if n-length != 0 {
+		starts = append(starts, 0)
+	}
+	for start := 1; start < n-length; start++ {
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+// cleanMessage joins the words left over, dropping "remind me to" and
+// connecting words stranded next to where the time phrase was.
+func cleanMessage(words []string) string {
+	message := strings.Join(words, " ")
+	lower := strings.ToLower(message)
+	for _, prefix := range []string{"remind me to ", "remind me ", "to "} {
+		if strings.HasPrefix(lower, prefix) {
+			message, lower = message[len(prefix):], lower[len(prefix):]
+		}
+	}
+	for {
+		trimmed := strings.TrimRight(message, " ,.;:-")
+		fields := strings.Fields(trimmed)
+		if len(fields) == 0 {
+			return ""
+		}
+		switch strings.ToLower(fields[len(fields)-1]) {
+		case "at", "on", "by", "in", "for", "to":
+			message = strings.Join(fields[:len(fields)-1], " ")
+			continue
+		}
+		return trimmed
+	}
+}
+
+// phrase collects what a time phrase says before it is resolved.
+type phrase struct {
+	day     time.Time
+	hasDay  bool
+	hour    int
+	minute  int
+	hasTime bool
+	// ambiguous marks an hour given without am or pm.
+	ambiguous bool
+	part      string
+	offset    time.Duration
+}
+
+// phraseScan walks the tokens of a time phrase, collecting what they say.
+type phraseScan struct {
+	phrase
+	tokens     []string
+	i          int
+	now, today time.Time
+}
+
+// peek returns the token n after the current one, or "" past the end.
+func (sc *phraseScan) peek(n int) string {
+	if sc.i+n < len(sc.tokens) {
+		return sc.tokens[sc.i+n]
+	}
+	return ""
+}
+
+func (sc *phraseScan) setDay(day time.Time) bool {
+	if sc.hasDay {
+		return false
+	}
+	sc.day, sc.hasDay = day, true
+	return true
+}
+
+func (sc *phraseScan) setPart(part string) bool {
+	if sc.part != "" {
+		return false
+	}
+	sc.part = part
+	return true
+}
+
+// A phraseParser reads the words at the scan's position that it knows. It
+// returns how many it used, zero when the current word isn't one of its
+// own, and false when they can't be part of a time phrase or contradict an
+// earlier part of it.
+type phraseParser func(sc *phraseScan) (used int, ok bool)
+
+// phraseParsers are tried in order on each word; the first that uses it wins.
+var phraseParsers = []phraseParser{
+	parsePartAfterIn,
+	parseOffsetAfterIn,
+	parseFiller,
+	parseNamedDay,
+	parseNextPeriod,
+	parseThisOrNext,
+	parseWeekday,
+	parsePartOfDay,
+	parseNamedTime,
+	parseMonthName,
+	parseNumericDate,
+	parseClockTime,
+}
+
+// parsePhrase reads words as a time phrase, failing on any word it doesn't
+// understand.
+func parsePhrase(words []string, now time.Time) (time.Time, bool) {
+	if len(words) == 0 {
+		return time.Time{}, false
+	}
+	sc := &phraseScan{
+		tokens: make([]string, len(words)),
+		now:    now,
+		today:  time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+	}
+	for i, w := range words {
+		sc.tokens[i] = strings.Trim(strings.ToLower(w), ",.!?;")
+	}
+
+	for sc.i < len(sc.tokens) {
+		used := 0
+		for _, parse := range phraseParsers {
+			n, ok := parse(sc)
+			if !ok {
+				return time.Time{}, false
+			}
+			if n > 0 {
+				used = n
+				break
+			}
+		}
+		if used == 0 {
+			return time.Time{}, false
+		}
+		sc.i += used
+	}
+	return sc.resolve(now, sc.today)
+}
+
+// parsePartAfterIn reads "in the morning" and the like.
+func parsePartAfterIn(sc *phraseScan) (int, bool) {
+	part := sc.peek(2)
+	if sc.peek(0) != "in" || sc.peek(1) != "the" || partHours[part] == 0 || part == "tonight" {
+		return 0, true
+	}
+	return 3, sc.setPart(part)
+}
+
+// parseOffsetAfterIn reads "in 2 hours" and the like.
+func parseOffsetAfterIn(sc *phraseScan) (int, bool) {
+	if sc.peek(0) != "in" {
+		return 0, true
+	}
+	offset, used := parseOffset(sc.tokens[sc.i+1:])
+	if used == 0 || sc.offset != 0 {
+		return 0, false
+	}
+	sc.offset = offset
+	return used + 1, true
+}
+
+// parseFiller skips "at" and "on" before a time or day.
+func parseFiller(sc *phraseScan) (int, bool) {
+	if tok := sc.peek(0); (tok == "at" || tok == "on") && sc.peek(1) != "" {
+		return 1, true
+	}
+	return 0, true
+}
+
+// parseNamedDay reads today, tonight, and tomorrow.
+func parseNamedDay(sc *phraseScan) (int, bool) {
+	switch tok := sc.peek(0); tok {
+	case "today":
+		return 1, sc.setDay(sc.today)
+	case "tonight":
+		return 1, sc.setDay(sc.today) && sc.setPart(tok)
+	case "tomorrow", "tmrw", "tmr":
+		return 1, sc.setDay(sc.today.AddDate(0, 0, 1))
+	}
+	return 0, true
+}
+
+// parseNextPeriod reads "next week" and "next month". "This week" is too
+// vague to pick a time from.
+func parseNextPeriod(sc *phraseScan) (int, bool) {
+	tok, next := sc.peek(0), sc.peek(1)
+	switch {
+	case tok == "this" && next == "week":
+		return 0, false
+	case tok == "next" && next == "week":
+		return 2, sc.setDay(sc.today.AddDate(0, 0, 7))
+	case tok == "next" && next == "month":
+		return 2, sc.setDay(sc.today.AddDate(0, 1, 0))
+	}
+	return 0, true
+}
+
+// parseThisOrNext skips "this" or "next" before a weekday or part of the
+// day, as in "this evening" or "next friday"; the word after says the rest.
+func parseThisOrNext(sc *phraseScan) (int, bool) {
+	tok, next := sc.peek(0), sc.peek(1)
+	if (tok != "this" && tok != "next") || next == "" {
+		return 0, true
+	}
+	if _, ok := weekdays[next]; !ok && partHours[next] == 0 {
+		return 0, false
+	}
+	return 1, true
+}
+
+// parseWeekday reads a weekday as the next one after today.
+func parseWeekday(sc *phraseScan) (int, bool) {
+	weekday, ok := weekdays[sc.peek(0)]
+	if !ok {
+		return 0, true
+	}
+	days := (int(weekday) - int(sc.today.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return 1, sc.setDay(sc.today.AddDate(0, 0, days))
+}
+
+// parsePartOfDay reads morning, evening, and the like.
+func parsePartOfDay(sc *phraseScan) (int, bool) {
+	tok := sc.peek(0)
+	if partHours[tok] == 0 {
+		return 0, true
+	}
+	return 1, sc.setPart(tok)
+}
+
+// parseNamedTime reads noon, midday, and midnight.
+func parseNamedTime(sc *phraseScan) (int, bool) {
+	tok := sc.peek(0)
+	if tok != "noon" && tok != "midday" && tok != "midnight" {
+		return 0, true
+	}
+	if sc.hasTime {
+		return 0, false
+	}
+	sc.hasTime = true
+	if tok != "midnight" {
+		sc.hour = 12
+	} else if !sc.hasDay {
+		// The coming midnight is the start of tomorrow.
+		sc.setDay(sc.today.AddDate(0, 0, 1))
+	}
+	return 1, true
+}
+
+// parseMonthName reads a date like "march 3" or "3 march".
+func parseMonthName(sc *phraseScan) (int, bool) {
+	if months[sc.peek(0)] == 0 {
+		return 0, true
+	}
+	day, used, ok := parseMonthDay(sc.tokens[sc.i:], sc.today)
+	if !ok {
+		return 0, false
+	}
+	return used, sc.setDay(day)
+}
+
+// parseNumericDate reads a date like 2025-03-14 or 3/14.
+func parseNumericDate(sc *phraseScan) (int, bool) {
+	tok := sc.peek(0)
+	if !strings.ContainsAny(tok, "-/") || !strings.ContainsAny(tok, "0123456789") {
+		return 0, true
+	}
+	loc := sc.now.Location()
+	parsed, err := dateparse.ParseIn(tok, loc)
+	if err != nil {
+		return 0, false
+	}
+	return 1, sc.setDay(time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, loc))
+}
+
+// parseClockTime reads a time of day like 3pm, 15:30, or "3 pm". A bare
+// number is only a time right after "at".
+func parseClockTime(sc *phraseScan) (int, bool) {
+	clock, used := sc.peek(0), 1
+	if next := sc.peek(1); next == "am" || next == "pm" || next == "a.m." || next == "p.m." {
+		clock += next
+		used++
+	}
+	hour, minute, ambiguous, ok := parseClock(clock)
+	afterAt := sc.i > 0 && sc.tokens[sc.i-1] == "at"
+	if !ok || sc.hasTime || (ambiguous && !strings.Contains(clock, ":") && !afterAt) {
+		return 0, false
+	}
+	sc.hour, sc.minute, sc.ambiguous, sc.hasTime = hour, minute, ambiguous, true
+	return used, true
+}
+
+// resolve turns the phrase into a point in time.
+func (p phrase) resolve(now, today time.Time) (time.Time, bool) {
+	if p.offset != 0 {
+		if p.hasDay || p.hasTime || p.part != "" {
+			return time.Time{}, false
+		}
+		return now.Add(p.offset), true
+	}
+	if !p.hasDay && !p.hasTime && p.part == "" {
+		return time.Time{}, false
+	}
+
+	hour, minute := p.clock()
+	day := today
+	if p.hasDay {
+		day = p.day
+	}
+	at := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location())
+	if p.hasDay {
+		return at, true
+	}
+	// Without a day, take the next time the clock shows it: later today,
+	// this evening for an hour without am or pm, or else tomorrow.
+	if !at.After(now) && p.ambiguous && hour < 12 && p.part == "" {
+		at = at.Add(12 * time.Hour)
+	}
+	if !at.After(now) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at, true
+}
+
+// clock picks the hour and minute the phrase names, reading an hour without
+// am or pm as the afternoon when the part of the day or an early hour says so.
+func (p phrase) clock() (hour, minute int) {
+	switch {
+	case p.hasTime:
+		hour, minute = p.hour, p.minute
+		if p.ambiguous && hour < 12 {
+			evening := p.part == "afternoon" || p.part == "evening" || p.part == "night" || p.part == "tonight"
+			if evening || (p.hasDay && hour < 7) {
+				hour += 12
+			}
+		}
+		return hour, minute
+	case p.part != "":
+		return partHours[p.part], 0
+	}
+	return defaultHour, 0
+}
+
+// parseOffset reads the duration after "in", returning how many words it used.
+func parseOffset(tokens []string) (time.Duration, int) {
+	if len(tokens) == 0 {
+		return 0, 0
+	}
+	if d, err := time.ParseDuration(tokens[0]); err == nil && d > 0 {
+		return d, 1
+	}
+	if m := offsetPattern.FindStringSubmatch(tokens[0]); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if unit, ok := units[m[2]]; ok && n > 0 {
+			return time.Duration(n) * unit, 1
+		}
+	}
+	if len(tokens) >= 3 && tokens[0] == "half" && (tokens[1] == "an" || tokens[1] == "a") {
+		if unit, ok := units[tokens[2]]; ok {
+			return unit / 2, 3
+		}
+	}
+	if len(tokens) < 2 {
+		return 0, 0
+	}
+	unit, ok := units[tokens[1]]
+	if !ok {
+		return 0, 0
+	}
+	if tokens[0] == "a" || tokens[0] == "an" {
+		return unit, 2
+	}
+	n, err := strconv.Atoi(tokens[0])
+	if err != nil || n <= 0 {
+		return 0, 0
+	}
+	return time.Duration(n) * unit, 2
+}
+
+// parseMonthDay reads "nov 2", "november 2nd", or "nov 2 2027", returning
+// the day and how many words it used. A date without a year that has passed
+// means next year's.
+func parseMonthDay(tokens []string, today time.Time) (time.Time, int, bool) {
+	if len(tokens) < 2 {
+		return time.Time{}, 0, false
+	}
+	m := ordinalPattern.FindStringSubmatch(tokens[1])
+	if m == nil {
+		return time.Time{}, 0, false
+	}
+	dom, _ := strconv.Atoi(m[1])
+	month := months[tokens[0]]
+	year, used := today.Year(), 2
+	if len(tokens) > 2 && len(tokens[2]) == 4 {
+		if y, err := strconv.Atoi(tokens[2]); err == nil {
+			year, used = y, 3
+		}
+	}
+	day := time.Date(year, month, dom, 0, 0, 0, 0, today.Location())
+	if day.Month() != month {
+		return time.Time{}, 0, false
+	}
+	if used == 2 && day.Before(today) {
+		day = day.AddDate(1, 0, 0)
+	}
+	return day, used, true
+}
+
+// parseClock reads "3pm", "3:30 pm", "15:00", or "3". ambiguous is set for
+// an hour of 12 or less without am or pm.
+func parseClock(tok string) (hour, minute int, ambiguous, ok bool) {
+	m := clockPattern.FindStringSubmatch(tok)
+	if m == nil {
+		return 0, 0, false, false
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false, false
+	}
+	switch strings.ReplaceAll(m[3], ".", "") {
+	case "am":
+		if hour == 0 || hour > 12 {
+			return 0, 0, false, false
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour == 0 || hour > 12 {
+			return 0, 0, false, false
+		}
+		if hour < 12 {
+			hour += 12
+		}
+	default:
+		ambiguous = hour <= 12 && hour > 0
+	}
+	return hour, minute, ambiguous, true
+}
+
+// Describe says when at is in words relative to now, e.g. "tomorrow at
+// 15:00" or "Mon 19 Oct at 09:00", for confirming a parsed reminder.
+func Describe(at, now time.Time) string {
+	at = at.In(now.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := at.Format("Mon 2 Jan")
+	switch time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, now.Location()) {
+	case today:
+		day = "today"
+	case today.AddDate(0, 0, 1):
+		day = "tomorrow"
+	}
+	if at.Year() != now.Year() {
+		day += at.Format(" 2006")
+	}
+	return fmt.Sprintf("%s at %s", day, at.Format("15:04"))
+}
@@ -0,0 +1,103 @@
+// ABOUTME: Tests for pulling the time phrase out of reminder text.
+// ABOUTME: Runs from a fixed Wednesday morning so relative phrases are predictable.
+package remind
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// now is Wednesday 14 October 2026, 10:00.
+var now = time.Date(2026, time.October, 14, 10, 0, 0, 0, time.UTC)
+
+func at(month time.Month, day, hour, minute int) time.Time {
+	return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		text    string
+		message string
+		at      time.Time
+	}{
+		{"call dentist tomorrow at 3pm", "call dentist", at(time.October, 15, 15, 0)},
+		{"tomorrow at 3pm call dentist", "call dentist", at(time.October, 15, 15, 0)},
+		{"remind me to take the bins out tonight", "take the bins out", at(time.October, 14, 20, 0)},
+		{"check the oven in 20 minutes", "check the oven", at(time.October, 14, 10, 20)},
+		{"put the cake in the oven in 1h30m", "put the cake in the oven", at(time.October, 14, 11, 30)},
+		{"stretch in half an hour", "stretch", at(time.October, 14, 10, 30)},
+		{"water plants in 2 days", "water plants", at(time.October, 16, 10, 0)},
+		{"standup friday morning", "standup", at(time.October, 16, 9, 0)},
+		{"pay rent on monday", "pay rent", at(time.October, 19, 9, 0)},
+		// Today is Wednesday, so "wednesday" is next week's.
+		{"team lunch wednesday at noon", "team lunch", at(time.October, 21, 12, 0)},
+		{"call mom at 4:30", "call mom", at(time.October, 14, 16, 30)},
+		{"deploy at 11", "deploy", at(time.October, 14, 11, 0)},
+		{"stand up at 9", "stand up", at(time.October, 14, 21, 0)},
+		{"lock up at 17:45", "lock up", at(time.October, 14, 17, 45)},
+		{"renew passport nov 2nd", "renew passport", at(time.November, 2, 9, 0)},
+		{"book flights on 2026-12-01 at 8am", "book flights", at(time.December, 1, 8, 0)},
+		{"email Bob about the monday meeting tomorrow at 3pm", "email Bob about the monday meeting", at(time.October, 15, 15, 0)},
+		{"review PR this afternoon", "review PR", at(time.October, 14, 15, 0)},
+		{"dinner tomorrow at 6", "dinner", at(time.October, 15, 18, 0)},
+		{"run tomorrow at 7", "run", at(time.October, 15, 7, 0)},
+		{"backup check at midnight", "backup check", at(time.October, 15, 0, 0)},
+	}
+	for _, c := range cases {
+		got, err := Parse(c.text, now)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", c.text, err)
+			continue
+		}
+		if got.Message != c.message || !got.At.Equal(c.at) {
+			t.Errorf("Parse(%q) = %q at %s, want %q at %s", c.text, got.Message, got.At, c.message, c.at)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse("buy 3 eggs", now); !errors.Is(err, ErrNoTime) {
+		t.Errorf("Parse without a time = %v, want ErrNoTime", err)
+	}
+	if _, err := Parse("buy sun cream", now); !errors.Is(err, ErrNoTime) {
+		t.Errorf("Parse(sun) = %v, want ErrNoTime", err)
+	}
+	if _, err := Parse("tomorrow at 3pm", now); err == nil {
+		t.Error("Parse with only a time succeeded, want an error")
+	}
+	if _, err := Parse("file taxes on 2020-04-15", now); err == nil {
+		t.Error("Parse with a past date succeeded, want an error")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	for phrase, want := range map[string]time.Time{
+		"tomorrow at 3pm":  at(time.October, 15, 15, 0),
+		"in 90m":           at(time.October, 14, 11, 30),
+		"2026-10-20 08:15": at(time.October, 20, 8, 15),
+	} {
+		got, err := ParseTime(phrase, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseTime(%q) = %s, %v; want %s", phrase, got, err, want)
+		}
+	}
+	for _, bad := range []string{"whenever", "yesterday at noon", "2020-01-01"} {
+		if _, err := ParseTime(bad, now); err == nil {
+			t.Errorf("ParseTime(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	for want, when := range map[string]time.Time{
+		"today at 16:30":          at(time.October, 14, 16, 30),
+		"tomorrow at 09:00":       at(time.October, 15, 9, 0),
+		"Mon 19 Oct at 09:00":     at(time.October, 19, 9, 0),
+		"Fri 1 Jan 2027 at 00:00": time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if got := Describe(when, now); got != want {
+			t.Errorf("Describe(%s) = %q, want %q", when, got, want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// ABOUTME: Renders Pushover's HTML message subset for terminal display.
+// ABOUTME: Maps bold, italic, underline, colour, links, and lists to ANSI or plain text.
+package render
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	tagPattern  = regexp.MustCompile(`(?s)<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*)>`)
+	attrPattern = regexp.MustCompile(`(?i)([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// ANSI SGR sequences for the supported styles.
+const (
+	bold         = "\x1b[1m"
+	boldOff      = "\x1b[22m"
+	italic       = "\x1b[3m"
+	italicOff    = "\x1b[23m"
+	underline    = "\x1b[4m"
+	underlineOff = "\x1b[24m"
+	colorOff     = "\x1b[39m"
+)
+
+var namedColors = map[string]string{
+	"black":   "#000000",
+	"red":     "#ff0000",
+	"green":   "#008000",
+	"yellow":  "#ffff00",
+	"blue":    "#0000ff",
+	"magenta": "#ff00ff",
+	"cyan":    "#00ffff",
+	"white":   "#ffffff",
+	"gray":    "#808080",
+	"grey":    "#808080",
+	"orange":  "#ffa500",
+	"purple":  "#800080",
+}
+
+// list tracks an open <ul> or <ol> so items can be bulleted or numbered.
+type list struct {
+	ordered bool
+	next    int
+}
+
+// htmlRenderer accumulates the terminal text for one message body.
+type htmlRenderer struct {
+	out   strings.Builder
+	ansi  bool
+	lists []*list
+	links []string
+}
+
+// HTML converts a message body to terminal text. With ansi set, styles become
+// escape sequences; otherwise only the structure (links, lists, breaks) is kept.
+// Unknown tags are dropped and entities decoded.
+func HTML(body string, ansi bool) string {
+	r := &htmlRenderer{ansi: ansi}
+	last := 0
+	for _, loc := range tagPattern.FindAllStringSubmatchIndex(body, -1) {
+		r.out.WriteString(html.UnescapeString(body[last:loc[0]]))
+		last = loc[1]
+
+		closing := loc[3] > loc[2]
+		name := strings.ToLower(body[loc[4]:loc[5]])
+		r.tag(name, closing, parseAttrs(body[loc[6]:loc[7]]))
+	}
+	r.out.WriteString(html.UnescapeString(body[last:]))
+
+	if ansi {
+		// Reset anything an unbalanced message left open.
+		r.out.WriteString(boldOff + italicOff + underlineOff + colorOff)
+	}
+	return strings.TrimRight(r.out.String(), "\n")
+}
+
+func (r *htmlRenderer) tag(name string, closing bool, attrs map[string]string) {
+	switch name {
+	case "b", "strong":
+		r.style(pick(closing, boldOff, bold))
+	case "i", "em":
+		r.style(pick(closing, italicOff, italic))
+	case "u":
+		r.style(pick(closing, underlineOff, underline))
+	case "font":
+		if closing {
+			r.style(colorOff)
+		} else if seq, ok := colorSequence(attrs["color"]); ok {
+			r.style(seq)
+		}
+	case "a":
+		r.link(closing, attrs["href"])
+	case "br":
+		r.out.WriteString("\n")
+	case "p", "div":
+		r.newline()
+	case "ul", "ol":
+		r.newline()
+		if !closing {
+			r.lists = append(r.lists, &list{ordered: name == "ol", next: 1})
+		} else if len(r.lists) > 0 {
+			r.lists = r.lists[:len(r.lists)-1]
+		}
+	case "li":
+		if !closing {
+			r.item()
+		}
+	}
+}
+
+func (r *htmlRenderer) style(seq string) {
+	if r.ansi {
+		r.out.WriteString(seq)
+	}
+}
+
+func (r *htmlRenderer) newline() {
+	if r.out.Len() > 0 && !strings.HasSuffix(r.out.String(), "\n") {
+		r.out.WriteString("\n")
+	}
+}
+
+// link underlines the link text and follows it with the href, unless the
+// text already ends with it.
+func (r *htmlRenderer) link(closing bool, href string) {
+	if !closing {
+		r.links = append(r.links, href)
+		r.style(underline)
+		return
+	}
+	if len(r.links) == 0 {
+		r.style(underlineOff)
+		return
+	}
+	href = r.links[len(r.links)-1]
+	r.links = r.links[:len(r.links)-1]
+	showHref := href != "" && !strings.HasSuffix(r.out.String(), href)
+	r.style(underlineOff)
+	if showHref {
+		fmt.Fprintf(&r.out, " <%s>", href)
+	}
+}
+
+// item starts a list item, numbered in an <ol> and bulleted otherwise.
+func (r *htmlRenderer) item() {
+	r.newline()
+	indent := strings.Repeat("  ", max(len(r.lists), 1))
+	if len(r.lists) > 0 && r.lists[len(r.lists)-1].ordered {
+		current := r.lists[len(r.lists)-1]
+		fmt.Fprintf(&r.out, "%s%d. ", indent, current.next)
+		current.next++
+		return
+	}
+	r.out.WriteString(indent + "• ")
+}
+
+func pick(closing bool, onClose, onOpen string) string {
+	if closing {
+		return onClose
+	}
+	return onOpen
+}
+
+func parseAttrs(raw string) map[string]string {
+	attrs := make(map[string]string)
+	for _, match := range attrPattern.FindAllStringSubmatch(raw, -1) {
+		attrs[strings.ToLower(match[1])] = html.UnescapeString(match[2] + match[3] + match[4])
+	}
+	return attrs
+}
+
+// colorSequence returns a 24-bit foreground sequence for a hex or named colour.
+func colorSequence(color string) (string, bool) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if named, ok := namedColors[color]; ok {
+		color = named
+	}
+	color = strings.TrimPrefix(color, "#")
+	if len(color) == 3 {
+		color = string([]byte{color[0], color[0], color[1], color[1], color[2], color[2]})
+	}
+	if len(color) != 6 {
+		return "", false
+	}
+	rgb, err := strconv.ParseUint(color, 16, 32)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", rgb>>16, (rgb>>8)&0xff, rgb&0xff), true
+}
@@ -0,0 +1,46 @@
+// ABOUTME: Tests for terminal rendering of HTML message bodies.
+// ABOUTME: Covers plain-text structure and ANSI styling.
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLPlain(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"styles stripped", "second <b>bold</b> and <i>it</i>", "second bold and it"},
+		{"entities", "fish &amp; chips &lt;3", "fish & chips <3"},
+		{"link", `see <a href="https://example.com">docs</a>`, "see docs <https://example.com>"},
+		{"bare link", `<a href="https://example.com">https://example.com</a>`, "https://example.com"},
+		{"list", "todo:<ul><li>one</li><li>two</li></ul>", "todo:\n  • one\n  • two"},
+		{"ordered", "<ol><li>a<li>b</ol>done", "  1. a\n  2. b\ndone"},
+		{"breaks", "a<br>b<br/>c", "a\nb\nc"},
+		{"unknown tags", "<span>x</span>", "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTML(tt.in, false); got != tt.want {
+				t.Errorf("HTML(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLANSI(t *testing.T) {
+	got := HTML(`<b>hi</b> <font color="#ff0000">red</font>`, true)
+	for _, want := range []string{"\x1b[1mhi\x1b[22m", "\x1b[38;2;255;0;0mred\x1b[39m"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML() = %q, want it to contain %q", got, want)
+		}
+	}
+
+	link := `<a href="https://example.com">https://example.com</a>`
+	if got := HTML(link, true); strings.Count(got, "https://example.com") != 1 {
+		t.Errorf("HTML(%q) = %q, want the URL shown once", link, got)
+	}
+}
@@ -0,0 +1,107 @@
+// ABOUTME: Colour themes for message listings.
+// ABOUTME: Maps priorities and secondary fields to ANSI styles, or to nothing.
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Theme names accepted by the theme config option.
+const (
+	ThemeAuto  = "auto"
+	ThemeDark  = "dark"
+	ThemeLight = "light"
+	ThemeNone  = "none"
+)
+
+// Theme holds the ANSI sequences used when listing messages. The zero Theme
+// prints everything unstyled.
+type Theme struct {
+	// Dim styles timestamps and field labels.
+	Dim string
+	// Priority styles a message body by its priority, indexed by priority+2.
+	Priority [5]string
+	// Match styles search matches within a message.
+	Match string
+}
+
+const reset = "\x1b[0m"
+
+var (
+	darkTheme = Theme{
+		Dim:      "\x1b[2m",
+		Priority: [5]string{"\x1b[2m", "\x1b[37m", "", "\x1b[33m", "\x1b[1;31m"},
+		Match:    "\x1b[1;30;43m",
+	}
+	lightTheme = Theme{
+		Dim:      "\x1b[90m",
+		Priority: [5]string{"\x1b[90m", "\x1b[2m", "", "\x1b[38;5;130m", "\x1b[1;31m"},
+		Match:    "\x1b[1;103m",
+	}
+)
+
+// ThemeNames lists the valid theme option values.
+func ThemeNames() []string {
+	return []string{ThemeAuto, ThemeDark, ThemeLight, ThemeNone}
+}
+
+// ThemeByName returns the named theme. An empty name or "auto" means dark,
+// which also reads well on most light terminals.
+func ThemeByName(name string) (Theme, error) {
+	switch strings.ToLower(name) {
+	case "", ThemeAuto, ThemeDark:
+		return darkTheme, nil
+	case ThemeLight:
+		return lightTheme, nil
+	case ThemeNone:
+		return Theme{}, nil
+	default:
+		return Theme{}, fmt.Errorf("theme must be one of %s", strings.Join(ThemeNames(), ", "))
+	}
+}
+
+// Dimmed styles secondary text such as timestamps.
+func (t Theme) Dimmed(text string) string {
+	return paint(t.Dim, text)
+}
+
+// ForPriority styles text according to a message priority.
+func (t Theme) ForPriority(priority int, text string) string {
+	if priority < -2 || priority > 2 {
+		return text
+	}
+	return paint(t.Priority[priority+2], text)
+}
+
+// Highlighted styles text like ForPriority, with the matches of re in the
+// match style instead. A nil re highlights nothing.
+func (t Theme) Highlighted(priority int, text string, re *regexp.Regexp) string {
+	base := ""
+	if priority >= -2 && priority <= 2 {
+		base = t.Priority[priority+2]
+	}
+	if re == nil || t.Match == "" {
+		return paint(base, text)
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		b.WriteString(paint(base, text[last:loc[0]]))
+		b.WriteString(paint(t.Match, text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	b.WriteString(paint(base, text[last:]))
+	return b.String()
+}
+
+func paint(seq, text string) string {
+	if seq == "" || text == "" {
+		return text
+	}
+	return seq + text + reset
+}
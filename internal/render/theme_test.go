@@ -0,0 +1,35 @@
+// ABOUTME: Tests for listing colour themes.
+// ABOUTME: Covers highlighting search matches within priority styles.
+package render
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestHighlighted(t *testing.T) {
+	theme := Theme{Priority: [5]string{"", "", "", "<hi>", ""}, Match: "<m>"}
+	re := regexp.MustCompile(`(?i)disk`)
+	tests := []struct {
+		name     string
+		theme    Theme
+		priority int
+		text     string
+		re       *regexp.Regexp
+		want     string
+	}{
+		{"normal priority", theme, 0, "Disk full on disk0", re, "<m>Disk" + reset + " full on " + "<m>disk" + reset + "0"},
+		{"priority style kept around matches", theme, 1, "low disk space", re, "<hi>low " + reset + "<m>disk" + reset + "<hi> space" + reset},
+		{"no match", theme, 1, "all good", re, "<hi>all good" + reset},
+		{"no pattern", theme, 0, "disk", nil, "disk"},
+		{"no match style", Theme{}, 0, "disk", re, "disk"},
+		{"empty matches skipped", theme, 0, "ab", regexp.MustCompile(`x*`), "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.theme.Highlighted(tt.priority, tt.text, tt.re); got != tt.want {
+				t.Errorf("Highlighted(%d, %q) = %q, want %q", tt.priority, tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// ABOUTME: Replication target that keeps replicas in a local or mounted directory.
+// ABOUTME: Writes each replica to a temporary file and renames it into place.
+package replicate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type fileTarget struct {
+	dir string
+}
+
+func (t fileTarget) Put(ctx context.Context, name string, body io.ReadSeeker, size int64) error {
+	if err := os.MkdirAll(t.dir, 0o700); err != nil {
+		return fmt.Errorf("create replica dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(t.dir, "."+name+"-*")
+	if err != nil {
+		return fmt.Errorf("write replica: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := io.Copy(tmp, body); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write replica: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write replica: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(t.dir, name)); err != nil {
+		return fmt.Errorf("write replica: %w", err)
+	}
+	return nil
+}
+
+func (t fileTarget) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(t.dir)
+	if err != nil {
+		return nil, fmt.Errorf("list replicas: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (t fileTarget) Delete(ctx context.Context, name string) error {
+	if err := os.Remove(filepath.Join(t.dir, name)); err != nil {
+		return fmt.Errorf("delete replica: %w", err)
+	}
+	return nil
+}
+
+func (t fileTarget) String() string {
+	return t.dir
+}
@@ -0,0 +1,291 @@
+// ABOUTME: Copies the history database to a directory or S3 bucket for push daemon.
+// ABOUTME: Uploads a snapshot archive when the database has changed and prunes old replicas.
+package replicate
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/config"
+	"github.com/harper/push/internal/db"
+)
+
+const (
+	// ArchiveDB and ArchiveConfig name the entries of a backup archive, so a
+	// replica can be restored with push restore.
+	ArchiveDB     = "push.db"
+	ArchiveConfig = "config.toml"
+
+	replicaPrefix = "push-"
+	replicaSuffix = ".tar.gz"
+	replicaStamp  = "20060102T150405Z"
+)
+
+// Target is where replicas are kept.
+type Target interface {
+	// Put stores a replica under name, replacing any with that name.
+	Put(ctx context.Context, name string, body io.ReadSeeker, size int64) error
+	// List returns the names of the stored replicas, in any order.
+	List(ctx context.Context) ([]string, error)
+	Delete(ctx context.Context, name string) error
+	String() string
+}
+
+// New returns the target for a parsed [replicate] section.
+func New(r config.Replication) (Target, error) {
+	switch r.Kind {
+	case config.ReplicateFile:
+		return fileTarget{dir: r.Dir}, nil
+	case config.ReplicateS3:
+		return newS3Target(r)
+	}
+	return nil, fmt.Errorf("unknown replication target %q", r.Kind)
+}
+
+// Options configure a replication run.
+type Options struct {
+	// Retain is how many replicas to keep; zero keeps them all.
+	Retain int
+	// StatusPath is where the status of the last run is kept.
+	StatusPath string
+	// Config is a config snapshot, without secrets, stored with each replica.
+	Config []byte
+}
+
+// Status is how replication went, as kept at Options.StatusPath.
+type Status struct {
+	Target string `json:"target"`
+	// LastRun is when the database was last checked for changes.
+	LastRun time.Time `json:"last_run,omitzero"`
+	// LastReplicated is when a replica was last uploaded.
+	LastReplicated time.Time `json:"last_replicated,omitzero"`
+	LastReplica    string    `json:"last_replica,omitempty"`
+	LastSize       int64     `json:"last_size,omitempty"`
+	// LastHash is the SHA-256 of the last uploaded database, so an unchanged
+	// one isn't uploaded again.
+	LastHash  string `json:"last_hash,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+	// Replicas is how many replicas the target held after the last upload.
+	Replicas int `json:"replicas"`
+}
+
+// Uploaded reports whether the last run uploaded a replica.
+func (s Status) Uploaded() bool {
+	return !s.LastRun.IsZero() && s.LastRun.Equal(s.LastReplicated)
+}
+
+// LoadStatus reads the status kept at path. A missing file is an empty status.
+func LoadStatus(path string) (Status, error) {
+	var status Status
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return status, nil
+	}
+	if err != nil {
+		return status, fmt.Errorf("read replication status: %w", err)
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return status, fmt.Errorf("parse replication status %s: %w", path, err)
+	}
+	return status, nil
+}
+
+func saveStatus(path string, status Status) error {
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode replication status: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write replication status: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write replication status: %w", err)
+	}
+	return nil
+}
+
+// Run snapshots the database and, if it changed since the last replica,
+// uploads it to target and deletes replicas beyond opts.Retain. The outcome,
+// including a failure, is saved to opts.StatusPath.
+func Run(ctx context.Context, store *db.Store, target Target, opts Options, now time.Time) (Status, error) {
+	status, err := LoadStatus(opts.StatusPath)
+	if err != nil {
+		return status, err
+	}
+	// A new target needs a replica of its own, changed or not.
+	if status.Target != target.String() {
+		status = Status{Target: target.String()}
+	}
+	status.LastRun = now.UTC()
+
+	runErr := replicate(ctx, store, target, opts, &status)
+	status.LastError = ""
+	if runErr != nil {
+		status.LastError = runErr.Error()
+	}
+	if opts.StatusPath != "" {
+		if err := saveStatus(opts.StatusPath, status); err != nil && runErr == nil {
+			runErr = err
+		}
+	}
+	return status, runErr
+}
+
+func replicate(ctx context.Context, store *db.Store, target Target, opts Options, status *Status) error {
+	tmpDir, err := os.MkdirTemp("", "push-replicate-*")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	snapshot := filepath.Join(tmpDir, ArchiveDB)
+	if err := store.BackupTo(ctx, snapshot); err != nil {
+		return err
+	}
+	hash, err := fileHash(snapshot)
+	if err != nil {
+		return err
+	}
+	if hash == status.LastHash {
+		return nil
+	}
+
+	archive := filepath.Join(tmpDir, "replica"+replicaSuffix)
+	out, err := os.OpenFile(archive, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("create replica: %w", err)
+	}
+	defer func() { _ = out.Close() }()
+	if err := WriteArchive(out, snapshot, opts.Config, status.LastRun); err != nil {
+		return err
+	}
+	size, err := out.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("read replica: %w", err)
+	}
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("read replica: %w", err)
+	}
+
+	name := replicaName(status.LastRun)
+	if err := target.Put(ctx, name, out, size); err != nil {
+		return err
+	}
+	status.LastReplicated, status.LastReplica, status.LastSize, status.LastHash = status.LastRun, name, size, hash
+
+	kept, err := prune(ctx, target, opts.Retain)
+	if err != nil {
+		return err
+	}
+	status.Replicas = kept
+	return nil
+}
+
+// prune deletes the oldest replicas beyond retain and returns how many are left.
+func prune(ctx context.Context, target Target, retain int) (int, error) {
+	names, err := target.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var replicas []string
+	for _, name := range names {
+		if isReplica(name) {
+			replicas = append(replicas, name)
+		}
+	}
+	// The timestamp in the name sorts oldest first.
+	sort.Strings(replicas)
+	if retain <= 0 || len(replicas) <= retain {
+		return len(replicas), nil
+	}
+	for _, name := range replicas[:len(replicas)-retain] {
+		if err := target.Delete(ctx, name); err != nil {
+			return 0, err
+		}
+	}
+	return retain, nil
+}
+
+func replicaName(t time.Time) string {
+	return replicaPrefix + t.UTC().Format(replicaStamp) + replicaSuffix
+}
+
+// isReplica reports whether name was written by replicaName, so that other
+// files at the target are never pruned.
+func isReplica(name string) bool {
+	stamp, ok := strings.CutPrefix(name, replicaPrefix)
+	if !ok {
+		return false
+	}
+	if stamp, ok = strings.CutSuffix(stamp, replicaSuffix); !ok {
+		return false
+	}
+	_, err := time.Parse(replicaStamp, stamp)
+	return err == nil
+}
+
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("read snapshot: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("read snapshot: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteArchive writes a gzipped tar holding the database at dbPath and, if
+// given, a config snapshot: the format of push backup.
+func WriteArchive(w io.Writer, dbPath string, configSnapshot []byte, now time.Time) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	dbFile, err := os.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("read database copy: %w", err)
+	}
+	defer func() { _ = dbFile.Close() }()
+	info, err := dbFile.Stat()
+	if err != nil {
+		return fmt.Errorf("read database copy: %w", err)
+	}
+
+	if err := tw.WriteHeader(&tar.Header{Name: ArchiveDB, Mode: 0o600, Size: info.Size(), ModTime: now}); err != nil {
+		return fmt.Errorf("write backup: %w", err)
+	}
+	if _, err := io.Copy(tw, dbFile); err != nil {
+		return fmt.Errorf("write backup: %w", err)
+	}
+	if configSnapshot != nil {
+		if err := tw.WriteHeader(&tar.Header{Name: ArchiveConfig, Mode: 0o600, Size: int64(len(configSnapshot)), ModTime: now}); err != nil {
+			return fmt.Errorf("write backup: %w", err)
+		}
+		if _, err := tw.Write(configSnapshot); err != nil {
+			return fmt.Errorf("write backup: %w", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("write backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("write backup: %w", err)
+	}
+	return nil
+}
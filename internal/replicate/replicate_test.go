@@ -0,0 +1,166 @@
+// ABOUTME: Tests for replicating the database to a directory.
+// ABOUTME: Covers skipping unchanged databases, retention, status, and the archive format.
+package replicate
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+// replicaTarget returns an open store in a temporary directory and a file
+// target for a replicas directory beside it.
+func replicaTarget(t *testing.T) (*db.Store, fileTarget, string) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := db.Open(filepath.Join(dir, "push.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	replicas := filepath.Join(dir, "replicas")
+	if err := os.MkdirAll(replicas, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return store, fileTarget{dir: replicas}, dir
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	store, target, dir := replicaTarget(t)
+	opts := Options{Retain: 2, StatusPath: filepath.Join(dir, "replicate.json")}
+
+	start := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	status, err := Run(ctx, store, target, opts, start)
+	if err != nil {
+		t.Fatalf("first Run: %v", err)
+	}
+	if !status.Uploaded() || status.LastReplica != "push-20260304T090000Z.tar.gz" || status.Replicas != 1 {
+		t.Errorf("first Run status = %+v, want push-20260304T090000Z.tar.gz uploaded", status)
+	}
+
+	status, err = Run(ctx, store, target, opts, start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("unchanged Run: %v", err)
+	}
+	if status.Uploaded() || status.LastReplica != "push-20260304T090000Z.tar.gz" {
+		t.Errorf("unchanged database uploaded again: %+v", status)
+	}
+}
+
+func TestRunRetention(t *testing.T) {
+	ctx := context.Background()
+	store, target, dir := replicaTarget(t)
+	// Files that aren't replicas are never pruned.
+	if err := os.WriteFile(filepath.Join(target.dir, "notes.txt"), []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Retain: 2, StatusPath: filepath.Join(dir, "replicate.json"), Config: []byte("[send]\n")}
+
+	start := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		sentAt := start.Add(time.Duration(i) * time.Hour)
+		if _, err := store.PersistMessages(ctx, []db.MessageRecord{{PushoverID: int64(i + 1), Message: "changed", ReceivedAt: sentAt, SentAt: &sentAt}}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Run(ctx, store, target, opts, start.Add(time.Duration(i+2)*time.Minute)); err != nil {
+			t.Fatalf("Run after change %d: %v", i, err)
+		}
+	}
+
+	names, err := target.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(names)
+	want := []string{"notes.txt", "push-20260304T090300Z.tar.gz", "push-20260304T090400Z.tar.gz"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("replicas = %v, want %v", names, want)
+	}
+
+	status, err := LoadStatus(opts.StatusPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Target != target.dir || status.LastReplica != want[2] || status.Replicas != 2 || status.LastError != "" {
+		t.Errorf("saved status = %+v", status)
+	}
+
+	entries := archiveEntries(t, filepath.Join(target.dir, want[2]))
+	if len(entries) != 2 || entries[0] != ArchiveDB || entries[1] != ArchiveConfig {
+		t.Errorf("replica holds %v, want [%s %s]", entries, ArchiveDB, ArchiveConfig)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := db.Open(filepath.Join(dir, "push.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// A file where the replica directory should be.
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.WriteFile(blocked, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	statusPath := filepath.Join(dir, "replicate.json")
+	if _, err := Run(ctx, store, fileTarget{dir: blocked}, Options{StatusPath: statusPath}, time.Now()); err == nil {
+		t.Fatal("Run into a file succeeded, want an error")
+	}
+	status, err := LoadStatus(statusPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.LastError == "" || status.LastHash != "" {
+		t.Errorf("status after failure = %+v, want the error kept and no hash", status)
+	}
+}
+
+func TestIsReplica(t *testing.T) {
+	for name, want := range map[string]bool{
+		"push-20260304T090000Z.tar.gz":          true,
+		"push-latest.tar.gz":                    false,
+		"push-20260304T090000Z.tar":             false,
+		".push-20260304T090000Z.tar.gz-123":     false,
+		"backup-20260304T090000Z.tar.gz":        false,
+		"push-20260304T090000Z.tar.gz.download": false,
+	} {
+		if got := isReplica(name); got != want {
+			t.Errorf("isReplica(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func archiveEntries(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, hdr.Name)
+	}
+	return names
+}
@@ -0,0 +1,328 @@
+// ABOUTME: Replication target that keeps replicas in an S3 or S3-compatible bucket.
+// ABOUTME: Signs requests with AWS Signature Version 4 using credentials from the environment.
+package replicate
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/config"
+)
+
+const (
+	defaultS3Region = "us-east-1"
+	// emptyHash is the SHA-256 of an empty body.
+	emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	// maxS3Error bounds how much of an error response is read.
+	maxS3Error = 64 << 10
+)
+
+// credentials sign S3 requests.
+type credentials struct {
+	accessKey    string
+	secretKey    string
+	sessionToken string
+}
+
+type s3Target struct {
+	client *http.Client
+	// base is the bucket's URL: virtual-hosted on AWS, path-style on a
+	// custom endpoint.
+	base   *url.URL
+	bucket string
+	prefix string
+	region string
+	creds  credentials
+}
+
+func newS3Target(r config.Replication) (*s3Target, error) {
+	creds := credentials{
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.accessKey == "" || creds.secretKey == "" {
+		return nil, errors.New("replicating to S3 needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY set")
+	}
+	region := r.Region
+	for _, env := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region == "" {
+			region = os.Getenv(env)
+		}
+	}
+	if region == "" {
+		region = defaultS3Region
+	}
+
+	base := "https://" + r.Bucket + ".s3." + region + ".amazonaws.com"
+	if r.Endpoint != "" {
+		base = r.Endpoint + "/" + url.PathEscape(r.Bucket)
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("parse S3 endpoint: %w", err)
+	}
+	return &s3Target{
+		client: &http.Client{Timeout: 10 * time.Minute},
+		base:   u,
+		bucket: r.Bucket,
+		prefix: r.Prefix,
+		region: region,
+		creds:  creds,
+	}, nil
+}
+
+func (t *s3Target) key(name string) string {
+	if t.prefix == "" {
+		return name
+	}
+	return t.prefix + "/" + name
+}
+
+func (t *s3Target) objectURL(name string) *url.URL {
+	u := *t.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + t.key(name)
+	return &u
+}
+
+func (t *s3Target) Put(ctx context.Context, name string, body io.ReadSeeker, size int64) error {
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return fmt.Errorf("read replica: %w", err)
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("read replica: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, t.objectURL(name).String(), io.NopCloser(body))
+	if err != nil {
+		return fmt.Errorf("build S3 request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/gzip")
+	resp, err := t.do(req, hex.EncodeToString(h.Sum(nil)))
+	if err != nil {
+		return fmt.Errorf("upload %s: %w", t.key(name), err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+func (t *s3Target) Delete(ctx context.Context, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.objectURL(name).String(), nil)
+	if err != nil {
+		return fmt.Errorf("build S3 request: %w", err)
+	}
+	resp, err := t.do(req, emptyHash)
+	if err != nil {
+		return fmt.Errorf("delete %s: %w", t.key(name), err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// listResult is a ListObjectsV2 response.
+type listResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (t *s3Target) List(ctx context.Context) ([]string, error) {
+	prefix := ""
+	if t.prefix != "" {
+		prefix = t.prefix + "/"
+	}
+	var names []string
+	token := ""
+	for {
+		u := *t.base
+		if u.Path == "" {
+			u.Path = "/"
+		}
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u.RawQuery = query.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("build S3 request: %w", err)
+		}
+		resp, err := t.do(req, emptyHash)
+		if err != nil {
+			return nil, fmt.Errorf("list replicas: %w", err)
+		}
+		var result listResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("list replicas: %w", err)
+		}
+		for _, obj := range result.Contents {
+			// Replicas sit directly under the prefix.
+			if name := strings.TrimPrefix(obj.Key, prefix); !strings.Contains(name, "/") {
+				names = append(names, name)
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return names, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (t *s3Target) String() string {
+	if t.prefix == "" {
+		return "s3://" + t.bucket
+	}
+	return "s3://" + t.bucket + "/" + t.prefix
+}
+
+// s3Error is the body S3 sends with a failed request.
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// do signs and sends req, turning a non-2xx response into an error.
+func (t *s3Target) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	sign(req, t.creds, t.region, payloadHash, time.Now())
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var e s3Error
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxS3Error))
+	if xml.Unmarshal(body, &e) == nil && e.Code != "" {
+		return nil, fmt.Errorf("S3 answered %s: %s: %s", resp.Status, e.Code, e.Message)
+	}
+	return nil, fmt.Errorf("S3 answered %s", resp.Status)
+}
+
+// sign adds AWS Signature Version 4 headers to req. Every header already set
+// on req, apart from User-Agent, is signed along with the host.
+func sign(req *http.Request, creds credentials, region, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "authorization" || lower == "user-agent" {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[lower] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		canonicalQuery(req.URL),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+creds.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalPath is the URL path with each segment URI-encoded once.
+func canonicalPath(u *url.URL) string {
+	path := u.Path
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery is the query string sorted by name with names and values
+// URI-encoded.
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var pairs []string
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name)+"="+uriEncode(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but the characters SigV4 leaves alone.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
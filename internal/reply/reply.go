@@ -0,0 +1,186 @@
+// ABOUTME: Reply links that let a notification's recipient answer from the phone.
+// ABOUTME: Builds per-message callback URLs and serves the page they open.
+package reply
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+// MaxChoices caps the answers offered on one reply page.
+const MaxChoices = 8
+
+// NewToken returns an unguessable identifier for one reply link.
+func NewToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate reply token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Link returns the reply URL for token under base, push serve's public URL.
+func Link(base, token string) string {
+	return strings.TrimRight(base, "/") + "/r/" + token
+}
+
+// ParseChoices splits a comma-separated list of answers, dropping blanks.
+func ParseChoices(list string) ([]string, error) {
+	var choices []string
+	for _, choice := range strings.Split(list, ",") {
+		choice = strings.TrimSpace(choice)
+		if choice == "" || slices.Contains(choices, choice) {
+			continue
+		}
+		if strings.ContainsAny(choice, "\r\n") {
+			return nil, fmt.Errorf("choice %q spans lines", choice)
+		}
+		choices = append(choices, choice)
+	}
+	if len(choices) == 0 {
+		return nil, errors.New("no choices given")
+	}
+	if len(choices) > MaxChoices {
+		return nil, fmt.Errorf("at most %d choices are allowed", MaxChoices)
+	}
+	return choices, nil
+}
+
+// Handler serves reply pages from store: GET /r/{token} shows the message
+// and marks it opened, POST /r/{token} records the acknowledgement or choice.
+func Handler(store *db.Store, log *slog.Logger) http.Handler {
+	h := &handler{store: store, log: log}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /r/{token}", h.show)
+	mux.HandleFunc("POST /r/{token}", h.respond)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	return mux
+}
+
+type handler struct {
+	store *db.Store
+	log   *slog.Logger
+}
+
+// pageData is what the reply page renders.
+type pageData struct {
+	Rec db.ResponseRecord
+	// Recorded is set right after this request recorded a response.
+	Recorded bool
+}
+
+func (h *handler) show(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	if rec.Status == db.ResponseWaiting {
+		now := time.Now()
+		if err := h.store.MarkResponseOpened(r.Context(), rec.Token, now); err != nil {
+			h.fail(w, "mark opened", err)
+			return
+		}
+		rec.Status = db.ResponseOpened
+		rec.OpenedAt = now
+		h.log.Info("reply link opened", "token", rec.Token, "title", rec.Title)
+	}
+	h.render(w, http.StatusOK, pageData{Rec: rec})
+}
+
+func (h *handler) respond(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	choice := strings.TrimSpace(r.PostFormValue("choice"))
+	switch {
+	case len(rec.Choices) == 0 && choice != "":
+		http.Error(w, "this message takes no choice", http.StatusBadRequest)
+		return
+	case len(rec.Choices) > 0 && !slices.Contains(rec.Choices, choice):
+		http.Error(w, "unknown choice", http.StatusBadRequest)
+		return
+	}
+
+	recorded, err := h.store.RecordResponse(r.Context(), rec.Token, choice, time.Now())
+	if err != nil {
+		h.fail(w, "record response", err)
+		return
+	}
+	if recorded {
+		h.log.Info("reply recorded", "token", rec.Token, "title", rec.Title, "choice", choice)
+	}
+	if rec, ok = h.lookup(w, r); !ok {
+		return
+	}
+	h.render(w, http.StatusOK, pageData{Rec: rec, Recorded: recorded})
+}
+
+// lookup loads the link named in the request path, answering 404 itself
+// when there is none.
+func (h *handler) lookup(w http.ResponseWriter, r *http.Request) (db.ResponseRecord, bool) {
+	rec, found, err := h.store.ResponseByToken(r.Context(), r.PathValue("token"))
+	if err != nil {
+		h.fail(w, "look up reply link", err)
+		return db.ResponseRecord{}, false
+	}
+	if !found {
+		http.NotFound(w, r)
+		return db.ResponseRecord{}, false
+	}
+	return rec, true
+}
+
+func (h *handler) fail(w http.ResponseWriter, action string, err error) {
+	h.log.Error("reply page failed", "action", action, "error", err)
+	http.Error(w, "something went wrong", http.StatusInternalServerError)
+}
+
+func (h *handler) render(w http.ResponseWriter, status int, data pageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := page.Execute(w, data); err != nil {
+		h.log.Warn("render reply page", "error", err)
+	}
+}
+
+var page = template.Must(template.New("reply").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{with .Rec.Title}}{{.}}{{else}}Reply{{end}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem auto; max-width: 32rem; padding: 0 1rem; }
+p.message { white-space: pre-wrap; }
+button { display: block; width: 100%; margin: .5rem 0; padding: .9rem; font-size: 1.1rem; }
+.done { color: #2a7a2a; }
+</style>
+</head>
+<body>
+{{with .Rec.Title}}<h1>{{.}}</h1>{{end}}
+<p class="message">{{.Rec.Message}}</p>
+{{if .Rec.Responded}}
+<p class="done">{{if .Recorded}}Thanks, recorded{{else}}Already answered{{end}}: {{with .Rec.Choice}}{{.}}{{else}}acknowledged{{end}}.</p>
+{{else}}
+<form method="post">
+{{range .Rec.Choices}}<button name="choice" value="{{.}}">{{.}}</button>
+{{else}}<button>Acknowledge</button>
+{{end}}</form>
+{{end}}
+</body>
+</html>
+`))
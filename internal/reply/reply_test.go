@@ -0,0 +1,129 @@
+// ABOUTME: Tests for reply links and the page push serve shows for them.
+// ABOUTME: Opens, acknowledges, and answers links through the HTTP handler.
+package reply
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/harper/push/internal/db"
+)
+
+func openStore(t *testing.T) *db.Store {
+	t.Helper()
+	store, err := db.Open(filepath.Join(t.TempDir(), "push.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestParseChoices(t *testing.T) {
+	choices, err := ParseChoices(" Ship it, Wait,,Wait ,Abort")
+	if err != nil {
+		t.Fatalf("ParseChoices() error: %v", err)
+	}
+	if want := []string{"Ship it", "Wait", "Abort"}; !slices.Equal(choices, want) {
+		t.Errorf("ParseChoices() = %q, want %q", choices, want)
+	}
+	for _, list := range []string{" , ", "a,b,c,d,e,f,g,h,i", "one\ntwo,three"} {
+		if _, err := ParseChoices(list); err == nil {
+			t.Errorf("ParseChoices(%q) succeeded, want an error", list)
+		}
+	}
+}
+
+func TestLink(t *testing.T) {
+	if got := Link("https://push.example.com/", "abc"); got != "https://push.example.com/r/abc" {
+		t.Errorf("Link() = %q", got)
+	}
+	token, err := NewToken()
+	if err != nil || len(token) != 32 {
+		t.Errorf("NewToken() = %q, %v", token, err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+	store := openStore(t)
+	for _, rec := range []db.ResponseRecord{
+		{Token: "ack", Message: "Backups finished", Title: "Backups"},
+		{Token: "pick", Message: "Deploy to production?", Choices: []string{"Ship it", "Wait"}},
+	} {
+		if err := store.CreateResponse(ctx, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := httptest.NewServer(Handler(store, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	defer srv.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	post := func(path string, form url.Values) (int, string) {
+		t.Helper()
+		resp, err := http.PostForm(srv.URL+path, form)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	status := func(token string) db.ResponseRecord {
+		t.Helper()
+		rec, ok, err := store.ResponseByToken(ctx, token)
+		if err != nil || !ok {
+			t.Fatalf("ResponseByToken(%q) = %v, %v", token, ok, err)
+		}
+		return rec
+	}
+
+	if code, _ := get("/r/missing"); code != http.StatusNotFound {
+		t.Errorf("unknown token status = %d, want 404", code)
+	}
+
+	code, body := get("/r/ack")
+	if code != http.StatusOK || !strings.Contains(body, "Backups finished") || !strings.Contains(body, "Acknowledge") {
+		t.Errorf("GET /r/ack = %d:\n%s", code, body)
+	}
+	if rec := status("ack"); rec.Status != db.ResponseOpened || rec.OpenedAt.IsZero() {
+		t.Errorf("after opening: %+v", rec)
+	}
+	if code, body = post("/r/ack", nil); code != http.StatusOK || !strings.Contains(body, "Thanks, recorded") {
+		t.Errorf("POST /r/ack = %d:\n%s", code, body)
+	}
+	if rec := status("ack"); rec.Status != db.ResponseAcknowledged || rec.RespondedAt.IsZero() {
+		t.Errorf("after acknowledging: %+v", rec)
+	}
+
+	if code, _ = post("/r/pick", url.Values{"choice": {"Maybe"}}); code != http.StatusBadRequest {
+		t.Errorf("unknown choice status = %d, want 400", code)
+	}
+	if code, _ = post("/r/pick", url.Values{"choice": {"Wait"}}); code != http.StatusOK {
+		t.Errorf("POST /r/pick = %d", code)
+	}
+	if code, body = post("/r/pick", url.Values{"choice": {"Ship it"}}); code != http.StatusOK || !strings.Contains(body, "Already answered: Wait") {
+		t.Errorf("second answer = %d:\n%s", code, body)
+	}
+	rec := status("pick")
+	if rec.Status != db.ResponseAnswered || rec.Choice != "Wait" || rec.OpenedAt.IsZero() {
+		t.Errorf("after answering: %+v", rec)
+	}
+}
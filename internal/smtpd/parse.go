@@ -0,0 +1,167 @@
+// ABOUTME: Extracts the subject and readable text from a received mail.
+// ABOUTME: Decodes MIME parts and transfer encodings, preferring text/plain.
+package smtpd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// maxPartDepth bounds how deeply nested multipart mail is searched.
+const maxPartDepth = 5
+
+// Mail is the part of a message that becomes a notification.
+type Mail struct {
+	From    string
+	Subject string
+	// Body is the text/plain part, or text/html reduced to text when there
+	// is none.
+	Body string
+}
+
+// Parse reads the subject, sender, and text body of a raw message.
+func Parse(data []byte) (Mail, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return Mail{}, fmt.Errorf("parse mail: %w", err)
+	}
+	decoder := mime.WordDecoder{CharsetReader: passCharset}
+	m := Mail{From: msg.Header.Get("From")}
+	if m.Subject, err = decoder.DecodeHeader(msg.Header.Get("Subject")); err != nil {
+		m.Subject = msg.Header.Get("Subject")
+	}
+	if addr, err := mail.ParseAddress(m.From); err == nil {
+		m.From = addr.Address
+	}
+
+	body, isHTML, err := textPart(msg.Header, msg.Body, 0)
+	if err != nil {
+		return Mail{}, err
+	}
+	if isHTML {
+		body = htmlText(body)
+	}
+	m.Subject = strings.TrimSpace(m.Subject)
+	m.Body = strings.TrimSpace(strings.ReplaceAll(body, "\r\n", "\n"))
+	return m, nil
+}
+
+// header is what textPart needs from message and part headers.
+type header interface {
+	Get(key string) string
+}
+
+// textPart returns the best text in a part: its text/plain content, else its
+// text/html content, searching nested multipart parts.
+func textPart(h header, body io.Reader, depth int) (string, bool, error) {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxPartDepth {
+			return "", false, nil
+		}
+		return multipartText(multipart.NewReader(body, params["boundary"]), depth)
+	}
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", false, nil
+	}
+
+	// multipart.Part decodes quoted-printable itself and drops the header.
+	switch strings.ToLower(h.Get("Content-Transfer-Encoding")) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, &newlineSkipper{r: body})
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", false, fmt.Errorf("read mail body: %w", err)
+	}
+	return string(data), mediaType == "text/html", nil
+}
+
+// multipartText returns the first plain text part in reader, else the first
+// HTML one, skipping attachments.
+func multipartText(reader *multipart.Reader, depth int) (string, bool, error) {
+	var htmlBody string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", false, fmt.Errorf("read mail part: %w", err)
+		}
+		if disposition, _, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition")); disposition == "attachment" {
+			continue
+		}
+		text, isHTML, err := textPart(part.Header, part, depth+1)
+		if err != nil {
+			return "", false, err
+		}
+		if text == "" {
+			continue
+		}
+		if !isHTML {
+			return text, false, nil
+		}
+		if htmlBody == "" {
+			htmlBody = text
+		}
+	}
+	return htmlBody, htmlBody != "", nil
+}
+
+// newlineSkipper drops line breaks from base64 bodies.
+type newlineSkipper struct {
+	r io.Reader
+}
+
+func (n *newlineSkipper) Read(p []byte) (int, error) {
+	for {
+		count, err := n.r.Read(p)
+		kept := 0
+		for _, b := range p[:count] {
+			if b != '\r' && b != '\n' {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+// passCharset reads headers in charsets other than UTF-8 as they are; most
+// appliance mail is ASCII.
+func passCharset(_ string, input io.Reader) (io.Reader, error) {
+	return input, nil
+}
+
+var (
+	htmlBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</tr>|</h[1-6]>|</li>`)
+	htmlDrop   = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+	htmlTags   = regexp.MustCompile(`<[^>]*>`)
+	blankLines = regexp.MustCompile(`\n[ \t]*\n([ \t]*\n)+`)
+)
+
+// htmlText reduces an HTML body to readable text.
+func htmlText(body string) string {
+	body = htmlDrop.ReplaceAllString(body, "")
+	body = htmlBreaks.ReplaceAllString(body, "\n")
+	body = htmlTags.ReplaceAllString(body, "")
+	body = html.UnescapeString(body)
+	return blankLines.ReplaceAllString(body, "\n\n")
+}
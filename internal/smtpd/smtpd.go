@@ -0,0 +1,312 @@
+// ABOUTME: Minimal SMTP receiver for turning mail from appliances into notifications.
+// ABOUTME: Speaks just enough SMTP to accept plain messages and hands each one to a callback.
+package smtpd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBytes caps the size of one accepted mail.
+const DefaultMaxBytes = 1 << 20
+
+// commandTimeout is how long a client may take to send one command or the
+// whole of a mail's data.
+const commandTimeout = 5 * time.Minute
+
+// Envelope is one mail as received: the SMTP sender and recipients and the
+// raw message data.
+type Envelope struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// DeliverFunc handles a received mail. An error is reported to the sender as
+// a temporary failure, so it will retry, unless it is a *PermanentError.
+type DeliverFunc func(ctx context.Context, env Envelope) error
+
+// PermanentError is a delivery failure that retrying can't fix, such as
+// mail that doesn't parse. The sender is told to give up.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// Server accepts mail and passes each message to Deliver.
+type Server struct {
+	// Hostname is announced in the greeting.
+	Hostname string
+	// MaxBytes rejects larger mail; zero means DefaultMaxBytes.
+	MaxBytes int64
+	// Allowed lists the senders accepted, as full addresses or "@domain";
+	// empty accepts anyone.
+	Allowed []string
+	Deliver DeliverFunc
+	Log     *slog.Logger
+}
+
+// ListenAndServe accepts mail on addr until ctx is cancelled.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", addr, err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve accepts mail on ln until ctx is cancelled, then waits for open
+// sessions to finish.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("accept: %w", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { _ = conn.Close() }()
+			s.session(ctx, conn)
+		}()
+	}
+}
+
+// Allows reports whether mail from addr is accepted.
+func (s *Server) Allows(addr string) bool {
+	if len(s.Allowed) == 0 {
+		return true
+	}
+	addr = strings.ToLower(addr)
+	_, domain, ok := strings.Cut(addr, "@")
+	for _, allowed := range s.Allowed {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == addr || (ok && strings.HasPrefix(allowed, "@") && allowed[1:] == domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) maxBytes() int64 {
+	if s.MaxBytes <= 0 {
+		return DefaultMaxBytes
+	}
+	return s.MaxBytes
+}
+
+// session runs one SMTP conversation.
+func (s *Server) session(ctx context.Context, conn net.Conn) {
+	c := &smtpConn{
+		server:   s,
+		conn:     conn,
+		text:     textproto.NewConn(conn),
+		remote:   conn.RemoteAddr().String(),
+		hostname: s.Hostname,
+	}
+	if c.hostname == "" {
+		c.hostname = "push"
+	}
+	if !c.reply(220, c.hostname+" ESMTP push") {
+		return
+	}
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(commandTimeout))
+		line, err := c.text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			c.env = nil
+			c.reply(250, c.hostname)
+		case "EHLO":
+			c.env = nil
+			_ = c.text.PrintfLine("250-%s", c.hostname)
+			_ = c.text.PrintfLine("250-SIZE %d", s.maxBytes())
+			_ = c.text.PrintfLine("250-8BITMIME")
+			c.reply(250, "PIPELINING")
+		case "MAIL":
+			c.mail(arg)
+		case "RCPT":
+			c.rcpt(arg)
+		case "DATA":
+			if !c.data(ctx) {
+				return
+			}
+		case "RSET":
+			c.env = nil
+			c.reply(250, "OK")
+		case "NOOP":
+			c.reply(250, "OK")
+		case "VRFY":
+			c.reply(252, "Cannot verify users")
+		case "QUIT":
+			c.reply(221, "Bye")
+			return
+		default:
+			c.reply(502, "Command not implemented")
+		}
+	}
+}
+
+// smtpConn is the state of one SMTP conversation.
+type smtpConn struct {
+	server   *Server
+	conn     net.Conn
+	text     *textproto.Conn
+	remote   string
+	hostname string
+	// env is the mail being built, from MAIL until DATA or a reset.
+	env *Envelope
+}
+
+func (c *smtpConn) reply(code int, msg string) bool {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(commandTimeout))
+	return c.text.PrintfLine("%d %s", code, msg) == nil
+}
+
+func (c *smtpConn) mail(arg string) {
+	from, params, ok := pathArg(arg, "FROM:")
+	switch {
+	case !ok:
+		c.reply(501, "Syntax: MAIL FROM:<address>")
+	case c.env != nil:
+		c.reply(503, "Sender already given")
+	case paramSize(params) > c.server.maxBytes():
+		c.reply(552, fmt.Sprintf("Message exceeds the %d byte limit", c.server.maxBytes()))
+	case !c.server.Allows(from):
+		c.server.Log.Warn("rejected mail from a sender not in the allow list", "from", from, "remote", c.remote)
+		c.reply(550, "Sender not allowed")
+	default:
+		c.env = &Envelope{From: from}
+		c.reply(250, "OK")
+	}
+}
+
+func (c *smtpConn) rcpt(arg string) {
+	to, _, ok := pathArg(arg, "TO:")
+	switch {
+	case !ok:
+		c.reply(501, "Syntax: RCPT TO:<address>")
+	case c.env == nil:
+		c.reply(503, "Need MAIL first")
+	default:
+		c.env.To = append(c.env.To, to)
+		c.reply(250, "OK")
+	}
+}
+
+// data reads the mail and delivers it. It returns false when the connection
+// can't go on.
+func (c *smtpConn) data(ctx context.Context) bool {
+	if c.env == nil || len(c.env.To) == 0 {
+		c.reply(503, "Need MAIL and RCPT first")
+		return true
+	}
+	if !c.reply(354, "End data with <CR><LF>.<CR><LF>") {
+		return false
+	}
+	_ = c.conn.SetReadDeadline(time.Now().Add(commandTimeout))
+	data, err := readData(c.text.DotReader(), c.server.maxBytes())
+	env := c.env
+	c.env = nil
+	switch {
+	case errors.Is(err, errTooLarge):
+		c.reply(552, fmt.Sprintf("Message exceeds the %d byte limit", c.server.maxBytes()))
+	case err != nil:
+		return false
+	default:
+		env.Data = data
+		c.deliver(ctx, *env)
+	}
+	return true
+}
+
+// deliver hands env to Deliver and replies with the outcome.
+func (c *smtpConn) deliver(ctx context.Context, env Envelope) {
+	err := c.server.Deliver(ctx, env)
+	if err == nil {
+		c.reply(250, "OK: queued as notification")
+		return
+	}
+	c.server.Log.Error("mail not delivered", "from", env.From, "remote", c.remote, "error", err)
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		c.reply(554, "Not delivered and will not be accepted on retry")
+		return
+	}
+	c.reply(451, "Not delivered, try again later")
+}
+
+var errTooLarge = errors.New("message too large")
+
+// readData reads a mail's dot-encoded data, consuming all of it even when it
+// is over max so the session can carry on.
+func readData(r io.Reader, max int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			return nil, err
+		}
+		return nil, errTooLarge
+	}
+	return data, nil
+}
+
+// pathArg parses "FROM:<addr> PARAMS" style arguments. The null sender <>
+// is returned as an empty address.
+func pathArg(arg, prefix string) (string, string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	rest := strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(rest, "<") {
+		addr, params, _ := strings.Cut(rest, " ")
+		return addr, params, addr != ""
+	}
+	end := strings.Index(rest, ">")
+	if end < 0 {
+		return "", "", false
+	}
+	return rest[1:end], strings.TrimSpace(rest[end+1:]), true
+}
+
+// paramSize returns the SIZE= value among MAIL FROM parameters, or zero.
+func paramSize(params string) int64 {
+	for _, param := range strings.Fields(params) {
+		key, value, _ := strings.Cut(param, "=")
+		if strings.EqualFold(key, "SIZE") {
+			size, _ := strconv.ParseInt(value, 10, 64)
+			return size
+		}
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+// ABOUTME: Tests for the SMTP receiver and mail parsing.
+// ABOUTME: Sends mail with net/smtp and checks allow lists, size limits, and MIME bodies.
+package smtpd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// startServer runs s on a loopback port and returns its address and the
+// mail it delivered.
+func startServer(t *testing.T, s *Server) (string, func() []Envelope) {
+	t.Helper()
+	var mu sync.Mutex
+	var delivered []Envelope
+	if s.Deliver == nil {
+		s.Deliver = func(_ context.Context, env Envelope) error {
+			mu.Lock()
+			defer mu.Unlock()
+			delivered = append(delivered, env)
+			return nil
+		}
+	}
+	s.Log = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, ln) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve() error: %v", err)
+		}
+	})
+	return ln.Addr().String(), func() []Envelope {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]Envelope(nil), delivered...)
+	}
+}
+
+func TestServer(t *testing.T) {
+	addr, delivered := startServer(t, &Server{Allowed: []string{"nas@home.lan", "@printers.lan"}, MaxBytes: 512})
+
+	body := "Subject: Disk failing\r\n\r\nDrive 2 reports SMART errors.\r\n"
+	if err := smtp.SendMail(addr, nil, "NAS@home.lan", []string{"me@example.com"}, []byte(body)); err != nil {
+		t.Fatalf("SendMail() from an allowed address: %v", err)
+	}
+	if err := smtp.SendMail(addr, nil, "laser@printers.lan", []string{"me@example.com"}, []byte("Subject: Toner\r\n\r\nLow\r\n")); err != nil {
+		t.Fatalf("SendMail() from an allowed domain: %v", err)
+	}
+	err := smtp.SendMail(addr, nil, "spam@example.com", []string{"me@example.com"}, []byte(body))
+	if err == nil || !strings.Contains(err.Error(), "550") {
+		t.Errorf("SendMail() from a stranger = %v, want 550", err)
+	}
+	err = smtp.SendMail(addr, nil, "nas@home.lan", []string{"me@example.com"}, []byte("Subject: big\r\n\r\n"+strings.Repeat("x", 600)))
+	if err == nil || !strings.Contains(err.Error(), "552") {
+		t.Errorf("SendMail() over the size limit = %v, want 552", err)
+	}
+
+	got := delivered()
+	if len(got) != 2 {
+		t.Fatalf("delivered %d mails, want 2", len(got))
+	}
+	if got[0].From != "NAS@home.lan" || len(got[0].To) != 1 || !strings.Contains(string(got[0].Data), "SMART errors") {
+		t.Errorf("first mail = %+v", got[0])
+	}
+}
+
+func TestServerDeliverFailure(t *testing.T) {
+	addr, _ := startServer(t, &Server{Deliver: func(context.Context, Envelope) error {
+		return errors.New("pushover down")
+	}})
+	err := smtp.SendMail(addr, nil, "nas@home.lan", []string{"me@example.com"}, []byte("Subject: hi\r\n\r\nbody\r\n"))
+	if err == nil || !strings.Contains(err.Error(), "451") {
+		t.Errorf("SendMail() = %v, want a temporary 451 failure", err)
+	}
+}
+
+func TestServerMalformedMail(t *testing.T) {
+	addr, _ := startServer(t, &Server{Deliver: func(_ context.Context, env Envelope) error {
+		if _, err := Parse(env.Data); err != nil {
+			return &PermanentError{Err: err}
+		}
+		return nil
+	}})
+	err := smtp.SendMail(addr, nil, "nas@home.lan", []string{"me@example.com"}, []byte("not a header line\r\n\r\nbody\r\n"))
+	if err == nil || !strings.Contains(err.Error(), "554") {
+		t.Errorf("SendMail() of malformed mail = %v, want a permanent 554 failure", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name, raw, subject, body string
+	}{
+		{
+			name:    "plain",
+			raw:     "From: NAS <nas@home.lan>\r\nSubject: Backup done\r\n\r\nAll 3 volumes backed up.\r\n",
+			subject: "Backup done",
+			body:    "All 3 volumes backed up.",
+		},
+		{
+			name:    "encoded subject and quoted-printable body",
+			raw:     "Subject: =?UTF-8?Q?Temp=C3=A9rature_high?=\r\nContent-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\nRack 2 is at 41=C2=B0C and ri=\r\nsing.\r\n",
+			subject: "Température high",
+			body:    "Rack 2 is at 41°C and rising.",
+		},
+		{
+			name: "multipart prefers text/plain and skips attachments",
+			raw: "Subject: Report\r\nContent-Type: multipart/mixed; boundary=outer\r\n\r\n" +
+				"--outer\r\nContent-Type: multipart/alternative; boundary=inner\r\n\r\n" +
+				"--inner\r\nContent-Type: text/html\r\n\r\n<p>HTML version</p>\r\n" +
+				"--inner\r\nContent-Type: text/plain\r\nContent-Transfer-Encoding: base64\r\n\r\nUGxhaW4g\r\ndmVyc2lvbg==\r\n" +
+				"--inner--\r\n" +
+				"--outer\r\nContent-Type: text/plain\r\nContent-Disposition: attachment; filename=log.txt\r\n\r\nattached log\r\n" +
+				"--outer--\r\n",
+			subject: "Report",
+			body:    "Plain version",
+		},
+		{
+			name:    "html only",
+			raw:     "Subject: Alert\r\nContent-Type: text/html\r\n\r\n<html><style>p{}</style><p>Door &amp; window</p><p>opened</p></html>\r\n",
+			subject: "Alert",
+			body:    "Door & window\nopened",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mail, err := Parse([]byte(tt.raw))
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+			if mail.Subject != tt.subject || mail.Body != tt.body {
+				t.Errorf("Parse() = %q / %q, want %q / %q", mail.Subject, mail.Body, tt.subject, tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+// ABOUTME: Disk usage stub for systems without statfs.
+// ABOUTME: Reports that disk checks are unsupported rather than guessing.
+//go:build !(linux || darwin || freebsd)
+
+package sysmon
+
+import "fmt"
+
+// DiskUsage is not available on this system.
+func DiskUsage(path string) (float64, error) {
+	return 0, fmt.Errorf("read disk usage of %s: %w", path, ErrUnsupported)
+}
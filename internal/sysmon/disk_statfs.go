@@ -0,0 +1,25 @@
+// ABOUTME: Disk usage via statfs on Linux, macOS, and FreeBSD.
+// ABOUTME: Matches df by leaving space reserved for root out of the total.
+//go:build linux || darwin || freebsd
+
+package sysmon
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// DiskUsage returns the percentage of the filesystem holding path that is in
+// use, as df reports it.
+func DiskUsage(path string) (float64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, fmt.Errorf("read disk usage of %s: %w", path, err)
+	}
+	used := float64(st.Blocks - st.Bfree)
+	avail := float64(st.Bavail)
+	if used+avail == 0 {
+		return 0, nil
+	}
+	return used / (used + avail) * 100, nil
+}
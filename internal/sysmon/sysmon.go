@@ -0,0 +1,120 @@
+// ABOUTME: Reads local system metrics and decides when push sysmon should alert.
+// ABOUTME: Checks trip at their threshold and clear only once comfortably below it.
+package sysmon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+// Metrics a check can measure.
+const (
+	// Disk is the percentage of a filesystem in use.
+	Disk = "disk"
+	// Load is the five-minute load average.
+	Load = "load"
+)
+
+// clearRatio is the hysteresis: a check over its threshold clears only when
+// the value drops below this fraction of it, so a disk hovering at the limit
+// doesn't alert on every check.
+const clearRatio = 0.95
+
+// ErrUnsupported means the metric can't be read on this system.
+var ErrUnsupported = errors.New("not supported on this system")
+
+// ParsePercent parses a disk threshold such as "90%" or "90".
+func ParsePercent(value string) (float64, error) {
+	pct, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "%")), 64)
+	if err != nil || pct <= 0 || pct >= 100 {
+		return 0, fmt.Errorf("disk threshold must be a percentage between 0 and 100, got %q", value)
+	}
+	return pct, nil
+}
+
+// Name returns the name a check on metric and path is saved under.
+func Name(metric, path string) string {
+	if metric == Disk {
+		return Disk + ":" + path
+	}
+	return metric
+}
+
+// Read measures r's metric.
+func Read(ctx context.Context, r db.SysmonRecord) (float64, error) {
+	switch r.Metric {
+	case Disk:
+		return DiskUsage(r.Path)
+	case Load:
+		return LoadAverage(ctx)
+	}
+	return 0, fmt.Errorf("unknown metric %q", r.Metric)
+}
+
+// LoadAverage returns the five-minute load average, from /proc/loadavg on
+// Linux and sysctl elsewhere.
+func LoadAverage(ctx context.Context) (float64, error) {
+	if data, err := os.ReadFile("/proc/loadavg"); err == nil {
+		return parseLoad(string(data))
+	}
+	if _, err := exec.LookPath("sysctl"); err != nil {
+		return 0, fmt.Errorf("read load average: %w", ErrUnsupported)
+	}
+	out, err := exec.CommandContext(ctx, "sysctl", "-n", "vm.loadavg").Output()
+	if err != nil {
+		return 0, fmt.Errorf("read load average: %w", err)
+	}
+	// sysctl prints "{ 1.23 1.45 1.67 }".
+	return parseLoad(strings.Trim(strings.TrimSpace(string(out)), "{ }"))
+}
+
+// parseLoad returns the second of the load averages in s.
+func parseLoad(s string) (float64, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("unexpected load average %q", s)
+	}
+	load, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected load average %q", s)
+	}
+	return load, nil
+}
+
+// Apply records value on r as read at now and reports whether the check
+// changed state: it goes high at its threshold and back to ok once the value
+// falls below clearRatio of it.
+func Apply(r *db.SysmonRecord, value float64, now time.Time) bool {
+	r.LastChecked = now
+	r.LastValue = value
+
+	next := r.State
+	switch {
+	case value >= r.Threshold:
+		next = db.SysmonHigh
+	case value < r.Threshold*clearRatio:
+		next = db.SysmonOK
+	}
+	if next == r.State {
+		return false
+	}
+	r.State = next
+	r.LastChange = now
+	return true
+}
+
+// Format renders a metric's value for people.
+func Format(metric string, value float64) string {
+	if metric == Disk {
+		return fmt.Sprintf("%.0f%%", value)
+	}
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
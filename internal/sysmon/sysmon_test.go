@@ -0,0 +1,90 @@
+// ABOUTME: Tests for system metric parsing and threshold hysteresis.
+// ABOUTME: Reads real disk usage and load where the platform allows it.
+package sysmon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/db"
+)
+
+func TestParsePercent(t *testing.T) {
+	for in, want := range map[string]float64{"90%": 90, "85.5": 85.5, " 70 % ": 70} {
+		got, err := ParsePercent(in)
+		if err != nil || got != want {
+			t.Errorf("ParsePercent(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "full", "0%", "100%", "-5"} {
+		if _, err := ParsePercent(bad); err == nil {
+			t.Errorf("ParsePercent(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestParseLoad(t *testing.T) {
+	got, err := parseLoad("0.52 1.25 0.98 2/512 12345\n")
+	if err != nil || got != 1.25 {
+		t.Errorf("parseLoad(proc) = %v, %v", got, err)
+	}
+	got, err = parseLoad("1.10 2.20 3.30")
+	if err != nil || got != 2.2 {
+		t.Errorf("parseLoad(sysctl) = %v, %v", got, err)
+	}
+	if _, err := parseLoad("1.10"); err == nil {
+		t.Error("parseLoad with one field succeeded")
+	}
+}
+
+func TestApplyHysteresis(t *testing.T) {
+	r := db.SysmonRecord{Metric: Disk, Threshold: 90, State: db.SysmonOK}
+	now := time.Now()
+
+	steps := []struct {
+		value   float64
+		changed bool
+		state   string
+	}{
+		{80, false, db.SysmonOK},
+		{90, true, db.SysmonHigh},
+		{92, false, db.SysmonHigh},
+		// Below the threshold but inside the margin: still high.
+		{88, false, db.SysmonHigh},
+		{85, true, db.SysmonOK},
+		{89, false, db.SysmonOK},
+	}
+	for i, step := range steps {
+		at := now.Add(time.Duration(i) * time.Minute)
+		if changed := Apply(&r, step.value, at); changed != step.changed || r.State != step.state {
+			t.Fatalf("step %d (%v): changed=%v state=%s, want %v %s", i, step.value, changed, r.State, step.changed, step.state)
+		}
+		if step.changed && !r.LastChange.Equal(at) {
+			t.Errorf("step %d: LastChange = %v, want %v", i, r.LastChange, at)
+		}
+	}
+	if r.LastValue != 89 {
+		t.Errorf("LastValue = %v, want 89", r.LastValue)
+	}
+}
+
+func TestRead(t *testing.T) {
+	pct, err := DiskUsage(t.TempDir())
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip("disk usage not supported here")
+	}
+	if err != nil || pct < 0 || pct > 100 {
+		t.Errorf("DiskUsage = %v, %v", pct, err)
+	}
+	if _, err := DiskUsage("/does/not/exist"); err == nil {
+		t.Error("DiskUsage of a missing path succeeded")
+	}
+	if load, err := LoadAverage(context.Background()); err == nil && load < 0 {
+		t.Errorf("LoadAverage = %v", load)
+	}
+	if _, err := Read(context.Background(), db.SysmonRecord{Metric: "memory"}); err == nil {
+		t.Error("Read of an unknown metric succeeded")
+	}
+}
@@ -0,0 +1,69 @@
+// ABOUTME: Public wrapper around the push message history database.
+// ABOUTME: Lets other Go programs persist and query messages like the CLI does.
+
+// Package history stores received and sent Pushover messages in the same SQLite
+// database format used by the push CLI, so programs embedding the pushover
+// client can share history with it.
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/internal/messages"
+	"github.com/harper/push/pkg/pushover"
+)
+
+// Message is a persisted received message.
+type Message = db.MessageRecord
+
+// Sent is a persisted outgoing notification.
+type Sent = db.SentRecord
+
+// QueryOptions filters history queries. Zero values mean "no filter"; a zero
+// Limit returns the 20 most recent messages. Since matches messages recorded
+// at or after it, not when they were sent.
+type QueryOptions struct {
+	Limit  int
+	Since  *time.Time
+	Search string
+}
+
+// Store is a handle to a history database.
+type Store struct {
+	db *db.Store
+}
+
+// Open creates (if necessary) and opens the history database at path.
+func Open(path string) (*Store, error) {
+	store, err := db.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: store}, nil
+}
+
+// Close releases the database handle.
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Record persists messages returned by pushover.Client.FetchMessages, updating
+// rows that were already stored. It returns the number of rows written.
+func (s *Store) Record(ctx context.Context, msgs []pushover.ReceivedMessage) (int, error) {
+	return messages.PersistReceived(ctx, s.db, "", msgs)
+}
+
+// Query returns stored messages, newest first.
+func (s *Store) Query(ctx context.Context, opts QueryOptions) ([]Message, error) {
+	return s.db.QueryMessages(ctx, opts.Limit, opts.Since, opts.Search)
+}
+
+// LogSent records an outgoing notification.
+func (s *Store) LogSent(ctx context.Context, rec Sent) error {
+	return s.db.LogSent(ctx, rec)
+}
@@ -0,0 +1,89 @@
+// ABOUTME: Tests for the public history wrapper.
+// ABOUTME: Covers recording, querying, and sharing the database with the CLI's store.
+package history_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/push/internal/db"
+	"github.com/harper/push/pkg/history"
+	"github.com/harper/push/pkg/pushover"
+)
+
+func TestRecordAndQuery(t *testing.T) {
+	ctx := context.Background()
+	store, err := history.Open(filepath.Join(t.TempDir(), "push.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now().Truncate(time.Second)
+	msgs := []pushover.ReceivedMessage{
+		{PushoverID: 1, Message: "backup done", App: "cron", Date: now.Add(-2 * time.Hour).Unix()},
+		{PushoverID: 2, Message: "disk full", App: "cron", Date: now.Add(-time.Hour).Unix()},
+		{PushoverID: 3, Message: "deploy done", App: "ci", Date: now.Unix()},
+	}
+	if n, err := store.Record(ctx, msgs); err != nil || n != 3 {
+		t.Fatalf("Record() = %d, %v; want 3", n, err)
+	}
+	// Recording the same messages again updates them rather than adding rows.
+	if _, err := store.Record(ctx, msgs); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := store.Query(ctx, history.QueryOptions{})
+	if err != nil || len(all) != 3 || all[0].PushoverID != 3 {
+		t.Fatalf("Query() = %d messages, %v; want 3, newest first", len(all), err)
+	}
+	if got, err := store.Query(ctx, history.QueryOptions{Limit: 1}); err != nil || len(got) != 1 {
+		t.Errorf("Query(limit 1) = %d messages, %v", len(got), err)
+	}
+	if got, err := store.Query(ctx, history.QueryOptions{Search: "done"}); err != nil || len(got) != 2 {
+		t.Errorf("Query(search) = %d messages, %v; want 2", len(got), err)
+	}
+	// Since counts from when messages were recorded, not sent.
+	since := now.Add(-90 * time.Minute)
+	if got, err := store.Query(ctx, history.QueryOptions{Since: &since}); err != nil || len(got) != 3 {
+		t.Errorf("Query(since before recording) = %d messages, %v; want 3", len(got), err)
+	}
+	later := time.Now().Add(time.Minute)
+	if got, err := store.Query(ctx, history.QueryOptions{Since: &later}); err != nil || len(got) != 0 {
+		t.Errorf("Query(since after recording) = %d messages, %v; want none", len(got), err)
+	}
+}
+
+func TestSharedWithCLI(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "push.db")
+	store, err := history.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sentAt := time.Now().Truncate(time.Second)
+	if err := store.LogSent(ctx, history.Sent{Message: "hello", Title: "embed", SentAt: sentAt}); err != nil {
+		t.Fatalf("LogSent: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The push CLI reads the same file.
+	cli, err := db.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = cli.Close() }()
+	sent, err := cli.ListSent(ctx, db.SentFilter{})
+	if err != nil || len(sent) != 1 || sent[0].Message != "hello" || sent[0].Title != "embed" {
+		t.Errorf("ListSent() = %+v, %v; want the logged send", sent, err)
+	}
+
+	var closed *history.Store
+	if err := closed.Close(); err != nil {
+		t.Errorf("Close on a nil store = %v", err)
+	}
+}
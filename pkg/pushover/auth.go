@@ -48,7 +48,7 @@ func (c *Client) Login(ctx context.Context, email, password, twoFactorCode strin
 	encoded := values.Encode()
 
 	resp, err := c.do(ctx, func() (*http.Request, error) { //nolint:bodyclose // body closed by decodeJSON/decodeAPIError
-		req, err := http.NewRequest(http.MethodPost, apiBaseURL+"/users/login.json", strings.NewReader(encoded))
+		req, err := http.NewRequest(http.MethodPost, c.baseURL()+"/users/login.json", strings.NewReader(encoded))
 		if err != nil {
 			return nil, err
 		}
@@ -91,7 +91,7 @@ func (c *Client) RegisterDevice(ctx context.Context, secret, name string) (*Devi
 	encoded := values.Encode()
 
 	resp, err := c.do(ctx, func() (*http.Request, error) { //nolint:bodyclose // body closed by decodeJSON/decodeAPIError
-		req, err := http.NewRequest(http.MethodPost, apiBaseURL+"/devices.json", strings.NewReader(encoded))
+		req, err := http.NewRequest(http.MethodPost, c.baseURL()+"/devices.json", strings.NewReader(encoded))
 		if err != nil {
 			return nil, err
 		}
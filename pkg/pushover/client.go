@@ -0,0 +1,331 @@
+// ABOUTME: HTTP client wrapper for Pushover API communication.
+// ABOUTME: Handles request building, retries, and rate limiting.
+package pushover
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies this package's spans.
+const tracerName = "github.com/harper/push/pkg/pushover"
+
+// DefaultBaseURL is the production Pushover API endpoint.
+const DefaultBaseURL = "https://api.pushover.net/1"
+
+const (
+	retryDelay             = 5 * time.Second
+	maxConcurrentRequests  = 2
+	defaultRequestAttempts = 2
+)
+
+// Client wraps HTTP access to the Pushover API.
+type Client struct {
+	AppToken     string
+	UserKey      string
+	DeviceID     string
+	DeviceSecret string
+
+	httpClient *http.Client
+	limiter    chan struct{}
+	userAgent  string
+	apiURL     string
+	logger     Logger
+}
+
+// NewClient returns a configured client with sane defaults.
+func NewClient(appToken, userKey, deviceID, deviceSecret string) *Client {
+	return &Client{
+		AppToken:     appToken,
+		UserKey:      userKey,
+		DeviceID:     deviceID,
+		DeviceSecret: deviceSecret,
+		httpClient:   &http.Client{Timeout: 15 * time.Second},
+		limiter:      make(chan struct{}, maxConcurrentRequests),
+		userAgent:    fmt.Sprintf("push-cli/1.0 (%s)", runtime.GOOS),
+	}
+}
+
+// SetBaseURL points the client at an alternative API endpoint, such as a
+// pushovertest server. An empty value restores DefaultBaseURL.
+func (c *Client) SetBaseURL(baseURL string) {
+	c.apiURL = strings.TrimRight(baseURL, "/")
+}
+
+func (c *Client) baseURL() string {
+	if c.apiURL == "" {
+		return DefaultBaseURL
+	}
+	return c.apiURL
+}
+
+// SetHTTPClient overrides the default HTTP client.
+func (c *Client) SetHTTPClient(client *http.Client) {
+	if client != nil {
+		c.httpClient = client
+	}
+}
+
+type requestBuilder func() (*http.Request, error)
+
+// do sends the request from build, retrying transport failures, inside a
+// client span on the global OpenTelemetry tracer provider.
+func (c *Client) do(ctx context.Context, build requestBuilder, retries int) (*http.Response, error) { //nolint:unparam // retries kept for flexibility
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "pushover.request", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	resp, err := c.doRetry(ctx, build, retries)
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case resp.StatusCode >= 400:
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	if resp != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	}
+	return resp, err
+}
+
+func (c *Client) doRetry(ctx context.Context, build requestBuilder, retries int) (*http.Response, error) {
+	attempts := retries
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		req, err := build()
+		if err != nil {
+			return nil, err
+		}
+		req = req.WithContext(ctx)
+		req.Header.Set("User-Agent", c.userAgent)
+		if span := trace.SpanFromContext(ctx); attempt == 1 {
+			// Only the path: query strings can carry the device secret.
+			span.SetName("pushover " + req.Method + " " + strings.TrimPrefix(req.URL.Path, "/1"))
+			span.SetAttributes(
+				attribute.String("http.request.method", req.Method),
+				attribute.String("server.address", req.URL.Hostname()),
+				attribute.String("url.path", req.URL.Path),
+			)
+		} else {
+			span.SetAttributes(attribute.Int("http.request.resend_count", attempt-1))
+		}
+
+		started := time.Now()
+		resp, err := c.doOnce(req)
+		if c.logger != nil {
+			c.logExchange(req, attempt, started, resp, err)
+		}
+		if err == nil {
+			return resp, nil
+		}
+
+		lastErr = err
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if attempt < attempts {
+			if err := waitRetry(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, errors.New("pushover: request failed")
+}
+
+func (c *Client) doOnce(req *http.Request) (*http.Response, error) {
+	limiter := c.limiter
+	if limiter != nil {
+		select {
+		case limiter <- struct{}{}:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		defer func() { <-limiter }()
+	}
+
+	client := c.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return client.Do(req)
+}
+
+func waitRetry(ctx context.Context) error {
+	timer := time.NewTimer(retryDelay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Sentinel errors classifying API failures. *APIError unwraps to one of these
+// when the failure class is recognised, so callers can use errors.Is.
+var (
+	ErrInvalidUser    = errors.New("pushover: invalid user key")
+	ErrInvalidToken   = errors.New("pushover: invalid application token")
+	ErrRateLimited    = errors.New("pushover: rate limited")
+	ErrMessageTooLong = errors.New("pushover: message too long")
+	ErrDeviceInvalid  = errors.New("pushover: invalid device")
+)
+
+// ErrTwoFactorRequired is returned by Login when the account needs a 2FA code.
+var ErrTwoFactorRequired = errors.New("pushover: two-factor authentication required")
+
+// APIError captures error responses from the Pushover API.
+type APIError struct {
+	Status    int
+	RequestID string
+	Messages  []string
+	// Kind is the matching sentinel error, or nil when the failure is unclassified.
+	Kind error
+	// RateLimitReset is when the application's quota resets, if Pushover reported it.
+	RateLimitReset time.Time
+}
+
+func (e *APIError) Error() string {
+	if e == nil {
+		return "pushover API error"
+	}
+
+	var msg string
+	if len(e.Messages) == 0 {
+		msg = fmt.Sprintf("pushover API error (status=%d, request=%s)", e.Status, e.RequestID)
+	} else {
+		msg = fmt.Sprintf("pushover API error: %s", strings.Join(e.Messages, "; "))
+	}
+	if !e.RateLimitReset.IsZero() {
+		msg += fmt.Sprintf(" (limit resets %s)", e.RateLimitReset.Local().Format(time.RFC1123))
+	}
+	return msg
+}
+
+// Unwrap exposes the classified sentinel error for errors.Is.
+func (e *APIError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Kind
+}
+
+func decodeAPIError(resp *http.Response) error {
+	if resp == nil {
+		return errors.New("pushover API error: nil response")
+	}
+	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return ErrTwoFactorRequired
+	}
+
+	var payload struct {
+		Status  int      `json:"status"`
+		Request string   `json:"request"`
+		Errors  []string `json:"errors"`
+		User    string   `json:"user"`
+		Token   string   `json:"token"`
+		Device  string   `json:"device"`
+		Secret  string   `json:"secret"`
+		Message string   `json:"message"`
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if len(body) > 0 {
+		_ = json.Unmarshal(body, &payload)
+	}
+
+	if len(payload.Errors) == 0 && len(body) > 0 {
+		payload.Errors = []string{strings.TrimSpace(string(body))}
+	}
+
+	if payload.Status == 0 {
+		payload.Status = resp.StatusCode
+	}
+
+	apiErr := &APIError{Status: payload.Status, RequestID: payload.Request, Messages: payload.Errors}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		apiErr.Kind = ErrRateLimited
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-Limit-App-Reset"), 10, 64); err == nil && reset > 0 {
+			apiErr.RateLimitReset = time.Unix(reset, 0)
+		}
+	case payload.Token == "invalid" || containsError(payload.Errors, "application token is invalid"):
+		apiErr.Kind = ErrInvalidToken
+	case payload.User == "invalid" || containsError(payload.Errors, "user identifier is not a valid", "user key is invalid"):
+		apiErr.Kind = ErrInvalidUser
+	case payload.Device == "invalid" || payload.Secret == "invalid" || containsError(payload.Errors, "device name is not valid", "secret is invalid"):
+		apiErr.Kind = ErrDeviceInvalid
+	case containsError(payload.Errors, "cannot be longer than", "too long"):
+		apiErr.Kind = ErrMessageTooLong
+	}
+
+	return apiErr
+}
+
+func containsError(messages []string, fragments ...string) bool {
+	for _, msg := range messages {
+		lower := strings.ToLower(msg)
+		for _, fragment := range fragments {
+			if strings.Contains(lower, fragment) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeJSON(resp *http.Response, target interface{}) error {
+	defer func() { _ = resp.Body.Close() }()
+	dec := json.NewDecoder(resp.Body)
+	return dec.Decode(target)
+}
+
+func (c *Client) ensureSendCredentials() error {
+	if strings.TrimSpace(c.AppToken) == "" {
+		return errors.New("pushover: app token not configured")
+	}
+	if strings.TrimSpace(c.UserKey) == "" {
+		return errors.New("pushover: user key not configured")
+	}
+	return nil
+}
+
+func (c *Client) ensureReceiveCredentials() error {
+	if err := c.ensureSendCredentials(); err != nil {
+		return err
+	}
+	if strings.TrimSpace(c.DeviceID) == "" || strings.TrimSpace(c.DeviceSecret) == "" {
+		return errors.New("pushover: device credentials missing")
+	}
+	return nil
+}
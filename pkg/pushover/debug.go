@@ -0,0 +1,180 @@
+// ABOUTME: Request/response debug logging for the Pushover client.
+// ABOUTME: Records method, URL, status, latency, and redacted bodies per call.
+package pushover
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestLog describes one HTTP exchange with the Pushover API. Credentials
+// are redacted from URL, RequestBody, and ResponseBody before logging.
+type RequestLog struct {
+	Method       string
+	URL          string
+	Attempt      int
+	Status       int
+	RequestID    string
+	Latency      time.Duration
+	RequestBody  string
+	ResponseBody string
+	Err          error
+}
+
+// Logger receives a RequestLog for every attempt the client makes.
+type Logger interface {
+	LogRequest(entry RequestLog)
+}
+
+// SetLogger enables debug logging of every request. Nil disables it.
+func (c *Client) SetLogger(logger Logger) {
+	c.logger = logger
+}
+
+// WriterLogger formats RequestLog entries as text lines on an io.Writer.
+type WriterLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterLogger returns a Logger writing to w (typically stderr or a file).
+func NewWriterLogger(w io.Writer) *WriterLogger {
+	return &WriterLogger{w: w}
+}
+
+// LogRequest writes a summary line followed by indented bodies.
+func (l *WriterLogger) LogRequest(entry RequestLog) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	status := fmt.Sprintf("%d", entry.Status)
+	if entry.Err != nil {
+		status = "error: " + entry.Err.Error()
+	}
+	_, _ = fmt.Fprintf(l.w, "[pushover] %s %s %s attempt=%d status=%s request=%s latency=%s\n",
+		time.Now().Format(time.RFC3339), entry.Method, entry.URL, entry.Attempt, status, entry.RequestID, entry.Latency.Round(time.Millisecond))
+	if entry.RequestBody != "" {
+		_, _ = fmt.Fprintf(l.w, "  > %s\n", entry.RequestBody)
+	}
+	if entry.ResponseBody != "" {
+		_, _ = fmt.Fprintf(l.w, "  < %s\n", entry.ResponseBody)
+	}
+}
+
+// SlogLogger records RequestLog entries as debug-level structured log records.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger that writes each exchange to logger.
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	return &SlogLogger{logger: logger}
+}
+
+// LogRequest emits one "pushover request" record with the exchange details.
+func (l *SlogLogger) LogRequest(entry RequestLog) {
+	attrs := []slog.Attr{
+		slog.String("method", entry.Method),
+		slog.String("url", entry.URL),
+		slog.Int("attempt", entry.Attempt),
+		slog.Int("status", entry.Status),
+		slog.String("request_id", entry.RequestID),
+		slog.Duration("latency", entry.Latency),
+	}
+	if entry.RequestBody != "" {
+		attrs = append(attrs, slog.String("request_body", entry.RequestBody))
+	}
+	if entry.ResponseBody != "" {
+		attrs = append(attrs, slog.String("response_body", entry.ResponseBody))
+	}
+	if entry.Err != nil {
+		attrs = append(attrs, slog.Any("error", entry.Err))
+	}
+	l.logger.LogAttrs(context.Background(), slog.LevelDebug, "pushover request", attrs...)
+}
+
+const redacted = "REDACTED"
+
+var sensitiveFields = map[string]bool{
+	"token":    true,
+	"user":     true,
+	"secret":   true,
+	"password": true,
+	"twofa":    true,
+}
+
+var sensitiveJSON = regexp.MustCompile(`"(secret|token|password)"\s*:\s*"[^"]*"`)
+
+// redactValues masks credential fields in a form-encoded string.
+func redactValues(encoded string) string {
+	values, err := url.ParseQuery(encoded)
+	if err != nil {
+		return encoded
+	}
+	for key := range values {
+		if sensitiveFields[key] {
+			values.Set(key, redacted)
+		}
+	}
+	return values.Encode()
+}
+
+func redactURL(raw *url.URL) string {
+	if raw == nil {
+		return ""
+	}
+	copied := *raw
+	copied.RawQuery = redactValues(copied.RawQuery)
+	return copied.String()
+}
+
+func redactJSON(body string) string {
+	return sensitiveJSON.ReplaceAllString(body, `"$1":"`+redacted+`"`)
+}
+
+// logExchange reports an attempt to the logger, buffering the response body
+// so callers can still decode it.
+func (c *Client) logExchange(req *http.Request, attempt int, started time.Time, resp *http.Response, err error) {
+	entry := RequestLog{
+		Method:  req.Method,
+		URL:     redactURL(req.URL),
+		Attempt: attempt,
+		Latency: time.Since(started),
+		Err:     err,
+	}
+
+	if req.GetBody != nil {
+		if body, bodyErr := req.GetBody(); bodyErr == nil {
+			data, _ := io.ReadAll(body)
+			_ = body.Close()
+			entry.RequestBody = redactValues(string(data))
+		}
+	}
+
+	if resp != nil {
+		entry.Status = resp.StatusCode
+		data, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+
+		entry.ResponseBody = redactJSON(strings.TrimSpace(string(data)))
+		var payload struct {
+			Request string `json:"request"`
+		}
+		if json.Unmarshal(data, &payload) == nil {
+			entry.RequestID = payload.Request
+		}
+	}
+
+	c.logger.LogRequest(entry)
+}
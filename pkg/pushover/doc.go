@@ -0,0 +1,22 @@
+// ABOUTME: Package documentation for the public Pushover client library.
+// ABOUTME: Describes the send and Open Client APIs exposed to other Go programs.
+
+// Package pushover is a Go client for the Pushover Message API and Open Client API.
+//
+// Sending only needs an application token and user key:
+//
+//	client := pushover.NewClient(appToken, userKey, "", "")
+//	resp, err := client.Send(ctx, pushover.SendParams{Message: "deploy finished"})
+//
+// resp.Limits carries the application's remaining monthly quota when Pushover
+// reports it; AppLimits fetches the quota without sending.
+//
+// Receiving requires a device registered through Login and RegisterDevice; the
+// resulting device ID and secret are passed to NewClient, after which
+// FetchMessages and DeleteMessages poll and acknowledge the device's queue.
+//
+// Every network call takes a context, retries transient transport failures, and
+// returns *APIError (or ErrTwoFactorRequired) when Pushover rejects a request.
+// Requests are traced with the global OpenTelemetry tracer provider, which is a
+// no-op unless the embedding program installs one.
+package pushover
@@ -0,0 +1,70 @@
+// ABOUTME: Monthly message quota for a Pushover application.
+// ABOUTME: Reads it from send response headers or the app limits endpoint.
+package pushover
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AppLimits reports an application's monthly message quota.
+type AppLimits struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// AppLimits fetches the application's quota without sending a message.
+func (c *Client) AppLimits(ctx context.Context) (*AppLimits, error) {
+	if c.AppToken == "" {
+		return nil, fmt.Errorf("app token is required")
+	}
+
+	params := url.Values{}
+	params.Set("token", c.AppToken)
+
+	resp, err := c.do(ctx, func() (*http.Request, error) { //nolint:bodyclose // body closed by decodeJSON/decodeAPIError
+		return http.NewRequest(http.MethodGet, c.baseURL()+"/apps/limits.json?"+params.Encode(), nil)
+	}, defaultRequestAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, decodeAPIError(resp)
+	}
+
+	var payload struct {
+		Status    int   `json:"status"`
+		Limit     int   `json:"limit"`
+		Remaining int   `json:"remaining"`
+		Reset     int64 `json:"reset"`
+	}
+	if err := decodeJSON(resp, &payload); err != nil {
+		return nil, fmt.Errorf("decode limits response: %w", err)
+	}
+
+	return &AppLimits{Limit: payload.Limit, Remaining: payload.Remaining, Reset: time.Unix(payload.Reset, 0)}, nil
+}
+
+// limitsFromHeader reads the X-Limit-App-* headers Pushover adds to send
+// responses. It returns nil when they are missing.
+func limitsFromHeader(header http.Header) *AppLimits {
+	limit, err := strconv.Atoi(header.Get("X-Limit-App-Limit"))
+	if err != nil {
+		return nil
+	}
+	remaining, err := strconv.Atoi(header.Get("X-Limit-App-Remaining"))
+	if err != nil {
+		return nil
+	}
+	limits := &AppLimits{Limit: limit, Remaining: remaining}
+	if reset, err := strconv.ParseInt(header.Get("X-Limit-App-Reset"), 10, 64); err == nil && reset > 0 {
+		limits.Reset = time.Unix(reset, 0)
+	}
+	return limits
+}
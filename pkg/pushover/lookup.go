@@ -0,0 +1,87 @@
+// ABOUTME: Lookup operations for sounds and user devices.
+// ABOUTME: Lets callers offer valid sound and device names before sending.
+package pushover
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// UserValidation mirrors the response to a user validation request.
+type UserValidation struct {
+	Status   int      `json:"status"`
+	Request  string   `json:"request"`
+	Devices  []string `json:"devices"`
+	Licenses []string `json:"licenses"`
+}
+
+// Sounds returns the sounds available to the application, keyed by the name
+// accepted by SendParams.Sound with a human readable description as value.
+func (c *Client) Sounds(ctx context.Context) (map[string]string, error) {
+	if c.AppToken == "" {
+		return nil, fmt.Errorf("app token is required")
+	}
+
+	params := url.Values{}
+	params.Set("token", c.AppToken)
+
+	resp, err := c.do(ctx, func() (*http.Request, error) { //nolint:bodyclose // body closed by decodeJSON/decodeAPIError
+		return http.NewRequest(http.MethodGet, c.baseURL()+"/sounds.json?"+params.Encode(), nil)
+	}, defaultRequestAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, decodeAPIError(resp)
+	}
+
+	var payload struct {
+		Status int               `json:"status"`
+		Sounds map[string]string `json:"sounds"`
+	}
+	if err := decodeJSON(resp, &payload); err != nil {
+		return nil, fmt.Errorf("decode sounds response: %w", err)
+	}
+
+	return payload.Sounds, nil
+}
+
+// ValidateUser checks the user key and returns the names of the user's
+// active devices.
+func (c *Client) ValidateUser(ctx context.Context) (*UserValidation, error) {
+	if err := c.ensureSendCredentials(); err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("token", c.AppToken)
+	values.Set("user", c.UserKey)
+	encoded := values.Encode()
+
+	resp, err := c.do(ctx, func() (*http.Request, error) { //nolint:bodyclose // body closed by decodeJSON/decodeAPIError
+		req, err := http.NewRequest(http.MethodPost, c.baseURL()+"/users/validate.json", strings.NewReader(encoded))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	}, defaultRequestAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, decodeAPIError(resp)
+	}
+
+	var validation UserValidation
+	if err := decodeJSON(resp, &validation); err != nil {
+		return nil, fmt.Errorf("decode validate response: %w", err)
+	}
+
+	return &validation, nil
+}
@@ -0,0 +1,131 @@
+// ABOUTME: Tests for the Pushover API client.
+// ABOUTME: Covers error classification of API failure payloads.
+package pushover
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPlaceholder(t *testing.T) {
+	// Placeholder to satisfy Go 1.23 coverage requirements
+}
+
+func TestDecodeAPIErrorClassification(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		header  http.Header
+		body    string
+		want    error
+		resetAt int64
+	}{
+		{
+			name:   "invalid token",
+			status: http.StatusBadRequest,
+			body:   `{"token":"invalid","errors":["application token is invalid"],"status":0,"request":"r1"}`,
+			want:   ErrInvalidToken,
+		},
+		{
+			name:   "invalid user",
+			status: http.StatusBadRequest,
+			body:   `{"user":"invalid","errors":["user identifier is not a valid user, group, or subscribed user key"],"status":0}`,
+			want:   ErrInvalidUser,
+		},
+		{
+			name:   "invalid device",
+			status: http.StatusBadRequest,
+			body:   `{"device":"invalid","errors":["device name is not valid for user"],"status":0}`,
+			want:   ErrDeviceInvalid,
+		},
+		{
+			name:   "message too long",
+			status: http.StatusBadRequest,
+			body:   `{"message":"invalid","errors":["message cannot be longer than 1024 characters"],"status":0}`,
+			want:   ErrMessageTooLong,
+		},
+		{
+			name:    "rate limited",
+			status:  http.StatusTooManyRequests,
+			header:  http.Header{"X-Limit-App-Reset": []string{"1767225600"}},
+			body:    `{"errors":["application is over its monthly limit"],"status":0}`,
+			want:    ErrRateLimited,
+			resetAt: 1767225600,
+		},
+		{
+			name:   "two factor",
+			status: http.StatusPreconditionFailed,
+			body:   `{"errors":["two-factor authentication required"],"status":0}`,
+			want:   ErrTwoFactorRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			err := decodeAPIError(resp)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("decodeAPIError() = %v, want errors.Is %v", err, tt.want)
+			}
+
+			var apiErr *APIError
+			if tt.resetAt > 0 {
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("decodeAPIError() = %T, want *APIError", err)
+				}
+				if got := apiErr.RateLimitReset.Unix(); got != tt.resetAt {
+					t.Errorf("RateLimitReset = %d, want %d", got, tt.resetAt)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeAPIErrorUnclassified(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusInternalServerError,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("upstream exploded")),
+	}
+
+	err := decodeAPIError(resp)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("decodeAPIError() = %T, want *APIError", err)
+	}
+	if apiErr.Kind != nil {
+		t.Errorf("Kind = %v, want nil", apiErr.Kind)
+	}
+	if apiErr.Status != http.StatusInternalServerError {
+		t.Errorf("Status = %d, want %d", apiErr.Status, http.StatusInternalServerError)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	form := redactValues("message=hi&token=abc&user=def&secret=ghi&password=pw")
+	for _, leaked := range []string{"abc", "def", "ghi", "pw"} {
+		if strings.Contains(form, leaked) {
+			t.Errorf("redactValues() leaked %q in %q", leaked, form)
+		}
+	}
+	if !strings.Contains(form, "message=hi") {
+		t.Errorf("redactValues() dropped non-sensitive field: %q", form)
+	}
+
+	body := redactJSON(`{"status":1,"secret":"s3cr3t","request":"r"}`)
+	if strings.Contains(body, "s3cr3t") {
+		t.Errorf("redactJSON() leaked secret: %q", body)
+	}
+}
@@ -0,0 +1,571 @@
+// ABOUTME: In-process mock of the Pushover API built on httptest.
+// ABOUTME: Implements send, login, device registration, lookups, and message polling.
+
+// Package pushovertest provides a fake Pushover API server for offline tests.
+//
+//	srv := pushovertest.NewServer()
+//	defer srv.Close()
+//	client := srv.Client()
+//	_, _ = client.Send(ctx, pushover.SendParams{Message: "hi"})
+//	sent := srv.Sent()
+//
+// The server enforces credentials and the 1024 character message limit, and
+// returns errors in Pushover's JSON shape so client error handling is exercised.
+package pushovertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/harper/push/pkg/pushover"
+)
+
+// Default credentials accepted by a new Server.
+const (
+	AppToken      = "test-app-token"
+	UserKey       = "test-user-key"
+	Email         = "user@example.com"
+	Password      = "password"
+	LoginSecret   = "test-login-secret"
+	DeviceID      = "test-device-id"
+	MaxMessageLen = 1024
+)
+
+// Server is a fake Pushover API. Exported credential fields may be changed
+// before the first request to exercise failure paths.
+type Server struct {
+	AppToken string
+	UserKey  string
+	Email    string
+	Password string
+	// TwoFactorCode, when set, makes login require this code.
+	TwoFactorCode string
+	// Devices are the device names reported by user validation.
+	Devices []string
+	// MonthlyLimit is the app quota reported in X-Limit-App-* headers and
+	// by the limits endpoint; each accepted send uses one message.
+	MonthlyLimit int
+	// Recipients are other user or group keys accepted by sends.
+	Recipients []string
+	// Loopback queues every accepted send for the device as well, like an
+	// account whose own device receives what it sends.
+	Loopback bool
+
+	srv *httptest.Server
+
+	requests atomic.Int64
+
+	mu       sync.Mutex
+	sent     []pushover.SendParams
+	pending  []pushover.ReceivedMessage
+	receipts map[string]*pushover.ReceiptStatus
+	nextID   int64
+}
+
+// NewServer starts a mock server with the default credentials.
+func NewServer() *Server {
+	s := &Server{
+		AppToken:     AppToken,
+		UserKey:      UserKey,
+		Email:        Email,
+		Password:     Password,
+		Devices:      []string{"phone", "laptop"},
+		MonthlyLimit: 10000,
+		receipts:     map[string]*pushover.ReceiptStatus{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/1/messages.json", s.handleMessages)
+	mux.HandleFunc("/1/users/login.json", s.handleLogin)
+	mux.HandleFunc("/1/devices.json", s.handleRegister)
+	mux.HandleFunc("/1/devices/", s.handleUpdateHighest)
+	mux.HandleFunc("/1/sounds.json", s.handleSounds)
+	mux.HandleFunc("/1/users/validate.json", s.handleValidate)
+	mux.HandleFunc("/1/receipts/", s.handleAcknowledge)
+	mux.HandleFunc("GET /1/receipts/{file}", s.handleReceipt)
+	mux.HandleFunc("POST /1/receipts/{receipt}/cancel.json", s.handleCancelReceipt)
+	mux.HandleFunc("/1/apps/limits.json", s.handleLimits)
+	mux.HandleFunc("/icons/", handleIcon)
+	s.srv = httptest.NewServer(mux)
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// URL returns the API base URL to pass to pushover.Client.SetBaseURL.
+func (s *Server) URL() string {
+	return s.srv.URL + "/1"
+}
+
+// Client returns a pushover.Client wired to this server with send and receive
+// credentials already configured.
+func (s *Server) Client() *pushover.Client {
+	client := pushover.NewClient(s.AppToken, s.UserKey, DeviceID, LoginSecret)
+	client.SetBaseURL(s.URL())
+	client.SetHTTPClient(s.srv.Client())
+	return client
+}
+
+// Sent returns copies of every accepted send, oldest first.
+func (s *Server) Sent() []pushover.SendParams {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]pushover.SendParams(nil), s.sent...)
+}
+
+// Pending returns messages queued for the device and not yet acknowledged.
+func (s *Server) Pending() []pushover.ReceivedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]pushover.ReceivedMessage(nil), s.pending...)
+}
+
+// Deliver queues a message for the device, assigning an ID and date when unset.
+func (s *Server) Deliver(msg pushover.ReceivedMessage) pushover.ReceivedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deliverLocked(msg)
+}
+
+func (s *Server) deliverLocked(msg pushover.ReceivedMessage) pushover.ReceivedMessage {
+	s.nextID++
+	if msg.PushoverID == 0 {
+		msg.PushoverID = s.nextID
+	} else if msg.PushoverID > s.nextID {
+		s.nextID = msg.PushoverID
+	}
+	if msg.Date == 0 {
+		msg.Date = time.Now().Unix()
+	}
+	s.pending = append(s.pending, msg)
+	return msg
+}
+
+func (s *Server) requestID() string {
+	return fmt.Sprintf("req-%d", s.requests.Add(1))
+}
+
+func (s *Server) handleMessages(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.handleSend(w, r)
+	case http.MethodGet:
+		s.handleFetch(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleSend(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.PostForm.Get("token") != s.AppToken {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"token": "invalid"}, "application token is invalid")
+		return
+	}
+	user := r.PostForm.Get("user")
+	if user != s.UserKey && !slices.Contains(s.Recipients, user) {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"user": "invalid"}, "user identifier is not a valid user, group, or subscribed user key")
+		return
+	}
+	message := r.PostForm.Get("message")
+	if message == "" {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"message": "cannot be blank"}, "message cannot be blank")
+		return
+	}
+	if len([]rune(message)) > MaxMessageLen {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"message": "invalid"}, fmt.Sprintf("message cannot be longer than %d characters", MaxMessageLen))
+		return
+	}
+
+	params := pushover.SendParams{
+		Message:   message,
+		Title:     r.PostForm.Get("title"),
+		Device:    r.PostForm.Get("device"),
+		URL:       r.PostForm.Get("url"),
+		URLTitle:  r.PostForm.Get("url_title"),
+		Sound:     r.PostForm.Get("sound"),
+		HTML:      r.PostForm.Get("html") == "1",
+		Monospace: r.PostForm.Get("monospace") == "1",
+	}
+	if user != s.UserKey {
+		params.User = user
+	}
+	params.Priority, _ = strconv.Atoi(r.PostForm.Get("priority"))
+	if ts, err := strconv.ParseInt(r.PostForm.Get("timestamp"), 10, 64); err == nil {
+		params.Timestamp = time.Unix(ts, 0)
+	}
+	if ttl, err := strconv.Atoi(r.PostForm.Get("ttl")); err == nil {
+		params.TTL = time.Duration(ttl) * time.Second
+	}
+	if params.Priority == 2 {
+		retry, _ := strconv.Atoi(r.PostForm.Get("retry"))
+		expire, _ := strconv.Atoi(r.PostForm.Get("expire"))
+		if retry < 30 || expire <= 0 {
+			s.writeError(w, http.StatusBadRequest, map[string]string{"retry": "invalid"}, "retry and expire are required for emergency priority")
+			return
+		}
+		params.Retry = time.Duration(retry) * time.Second
+		params.Expire = time.Duration(expire) * time.Second
+	}
+	s.sent = append(s.sent, params)
+	if s.Loopback {
+		s.deliverLocked(pushover.ReceivedMessage{Message: params.Message, Title: params.Title, Priority: params.Priority, URL: params.URL, App: "push"})
+	}
+
+	resp := map[string]any{"status": 1, "request": s.requestID()}
+	if params.Priority == 2 {
+		receipt := fmt.Sprintf("receipt-%d", len(s.sent))
+		now := time.Now()
+		s.receipts[receipt] = &pushover.ReceiptStatus{LastDeliveredAt: now, ExpiresAt: now.Add(params.Expire)}
+		resp["receipt"] = receipt
+	}
+	w.Header().Set("X-Limit-App-Limit", strconv.Itoa(s.MonthlyLimit))
+	w.Header().Set("X-Limit-App-Remaining", strconv.Itoa(s.remaining()))
+	w.Header().Set("X-Limit-App-Reset", strconv.FormatInt(quotaReset(time.Now()).Unix(), 10))
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleLimits(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Query().Get("token") != s.AppToken {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"token": "invalid"}, "application token is invalid")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":    1,
+		"request":   s.requestID(),
+		"limit":     s.MonthlyLimit,
+		"remaining": s.remaining(),
+		"reset":     quotaReset(time.Now()).Unix(),
+	})
+}
+
+// remaining is the quota left after the sends so far. Callers hold s.mu.
+func (s *Server) remaining() int {
+	return max(s.MonthlyLimit-len(s.sent), 0)
+}
+
+// quotaReset returns the start of the next month, when Pushover resets quotas.
+func quotaReset(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+}
+
+func (s *Server) handleFetch(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	if query.Get("secret") != LoginSecret || query.Get("device_id") != DeviceID {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"secret": "invalid"}, "secret is invalid")
+		return
+	}
+
+	var last int64
+	for _, msg := range s.pending {
+		last = max(last, msg.PushoverID)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":   1,
+		"request":  s.requestID(),
+		"last":     last,
+		"messages": append([]pushover.ReceivedMessage{}, s.pending...),
+	})
+}
+
+func (s *Server) handleUpdateHighest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/update_highest_message.json") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deviceID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/1/devices/"), "/update_highest_message.json")
+	if r.PostForm.Get("secret") != LoginSecret || deviceID != DeviceID {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"secret": "invalid"}, "secret is invalid")
+		return
+	}
+	upTo, err := strconv.ParseInt(r.PostForm.Get("message"), 10, 64)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"message": "invalid"}, "message is invalid")
+		return
+	}
+
+	remaining := s.pending[:0]
+	for _, msg := range s.pending {
+		if msg.PushoverID > upTo {
+			remaining = append(remaining, msg)
+		}
+	}
+	s.pending = remaining
+	writeJSON(w, http.StatusOK, map[string]any{"status": 1, "request": s.requestID()})
+}
+
+func (s *Server) handleAcknowledge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/acknowledge.json") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.PostForm.Get("secret") != LoginSecret {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"secret": "invalid"}, "secret is invalid")
+		return
+	}
+	receipt := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/1/receipts/"), "/acknowledge.json")
+	found := false
+	for i := range s.pending {
+		if s.pending[i].Receipt == receipt {
+			s.pending[i].Acked = 1
+			found = true
+		}
+	}
+	if !found {
+		s.writeError(w, http.StatusNotFound, map[string]string{"receipt": "not found"}, "receipt not found; may be invalid or expired")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": 1, "request": s.requestID()})
+}
+
+// AcknowledgeSent marks the emergency message that returned receipt as
+// acknowledged on device, as if its recipient had tapped it.
+func (s *Server) AcknowledgeSent(receipt, device string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status, ok := s.receipts[receipt]
+	if !ok {
+		return false
+	}
+	status.Acknowledged = true
+	status.AcknowledgedAt = time.Now()
+	status.AcknowledgedBy = s.UserKey
+	status.AcknowledgedByDevice = device
+	return true
+}
+
+// ExpireSent makes the emergency message that returned receipt expire now.
+func (s *Server) ExpireSent(receipt string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status, ok := s.receipts[receipt]
+	if ok {
+		status.ExpiresAt = time.Now().Add(-time.Second)
+	}
+	return ok
+}
+
+func (s *Server) handleReceipt(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Query().Get("token") != s.AppToken {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"token": "invalid"}, "application token is invalid")
+		return
+	}
+	status, ok := s.receipts[strings.TrimSuffix(r.PathValue("file"), ".json")]
+	if !ok {
+		s.writeError(w, http.StatusNotFound, map[string]string{"receipt": "not found"}, "receipt not found; may be invalid or expired")
+		return
+	}
+	expired := time.Now().After(status.ExpiresAt)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":                 1,
+		"request":                s.requestID(),
+		"acknowledged":           boolInt(status.Acknowledged),
+		"acknowledged_at":        unix(status.AcknowledgedAt),
+		"acknowledged_by":        status.AcknowledgedBy,
+		"acknowledged_by_device": status.AcknowledgedByDevice,
+		"last_delivered_at":      unix(status.LastDeliveredAt),
+		"expired":                boolInt(expired),
+		"expires_at":             unix(status.ExpiresAt),
+		"called_back":            0,
+		"called_back_at":         0,
+	})
+}
+
+func (s *Server) handleCancelReceipt(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.PostForm.Get("token") != s.AppToken {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"token": "invalid"}, "application token is invalid")
+		return
+	}
+	status, ok := s.receipts[r.PathValue("receipt")]
+	if !ok {
+		s.writeError(w, http.StatusNotFound, map[string]string{"receipt": "not found"}, "receipt not found; may be invalid or expired")
+		return
+	}
+	if !status.Acknowledged && time.Now().Before(status.ExpiresAt) {
+		status.ExpiresAt = time.Now()
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": 1, "request": s.requestID()})
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.PostForm.Get("email") != s.Email || r.PostForm.Get("password") != s.Password {
+		s.writeError(w, http.StatusBadRequest, nil, "invalid email and/or password")
+		return
+	}
+	if s.TwoFactorCode != "" && r.PostForm.Get("twofa") != s.TwoFactorCode {
+		s.writeError(w, http.StatusPreconditionFailed, nil, "two-factor authentication code required")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":  1,
+		"request": s.requestID(),
+		"id":      s.UserKey,
+		"secret":  LoginSecret,
+	})
+}
+
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.PostForm.Get("secret") != LoginSecret {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"secret": "invalid"}, "secret is invalid")
+		return
+	}
+	if r.PostForm.Get("name") == "" {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"name": "invalid"}, "name is invalid")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":  1,
+		"request": s.requestID(),
+		"id":      DeviceID,
+	})
+}
+
+// Sounds is the sound list served by the mock, a subset of Pushover's.
+var Sounds = map[string]string{
+	"pushover": "Pushover (default)",
+	"bike":     "Bike",
+	"cosmic":   "Cosmic",
+	"none":     "None (silent)",
+}
+
+func (s *Server) handleSounds(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Query().Get("token") != s.AppToken {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"token": "invalid"}, "application token is invalid")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": 1, "request": s.requestID(), "sounds": Sounds})
+}
+
+func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.PostForm.Get("token") != s.AppToken {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"token": "invalid"}, "application token is invalid")
+		return
+	}
+	if r.PostForm.Get("user") != s.UserKey {
+		s.writeError(w, http.StatusBadRequest, map[string]string{"user": "invalid"}, "user key is invalid")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":   1,
+		"request":  s.requestID(),
+		"devices":  append([]string{}, s.Devices...),
+		"licenses": []string{"Android", "iOS", "Desktop"},
+	})
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, fields map[string]string, message string) {
+	body := map[string]any{"status": 0, "request": s.requestID(), "errors": []string{message}}
+	for key, value := range fields {
+		body[key] = value
+	}
+	writeJSON(w, status, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// iconPNG is a 1x1 transparent PNG served for every icon name.
+var iconPNG = []byte{
+	0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0x00, 0x00, 0x0d,
+	0x49, 0x48, 0x44, 0x52, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01,
+	0x08, 0x06, 0x00, 0x00, 0x00, 0x1f, 0x15, 0xc4, 0x89, 0x00, 0x00, 0x00,
+	0x0d, 0x49, 0x44, 0x41, 0x54, 0x78, 0x9c, 0x63, 0x00, 0x01, 0x00, 0x00,
+	0x05, 0x00, 0x01, 0x0d, 0x0a, 0x2d, 0xb4, 0x00, 0x00, 0x00, 0x00, 0x49,
+	0x45, 0x4e, 0x44, 0xae, 0x42, 0x60, 0x82,
+}
+
+func handleIcon(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, ".png") {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(iconPNG)
+}
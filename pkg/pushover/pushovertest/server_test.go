@@ -0,0 +1,312 @@
+// ABOUTME: Integration tests running the Pushover client against the mock server.
+// ABOUTME: Covers send, receive, acknowledge, login, and lookup round trips.
+package pushovertest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/push/pkg/pushover"
+	"github.com/harper/push/pkg/pushover/pushovertest"
+)
+
+func TestSendRoundTrip(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+
+	resp, err := srv.Client().Send(context.Background(), pushover.SendParams{Message: "hello", Title: "greeting", Priority: 2, TTL: 1500 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if resp.Request == "" || resp.Receipt == "" {
+		t.Errorf("Send() = %+v, want request and receipt", resp)
+	}
+
+	sent := srv.Sent()
+	if len(sent) != 1 || sent[0].Message != "hello" || sent[0].Title != "greeting" {
+		t.Errorf("Sent() = %+v, want one hello/greeting message", sent)
+	}
+	if len(sent) == 1 && sent[0].TTL != 2*time.Second {
+		t.Errorf("Sent() TTL = %v, want 2s (rounded up)", sent[0].TTL)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	client := srv.Client()
+	client.AppToken = "wrong"
+	if _, err := client.Send(ctx, pushover.SendParams{Message: "x"}); !errors.Is(err, pushover.ErrInvalidToken) {
+		t.Errorf("Send() with bad token = %v, want ErrInvalidToken", err)
+	}
+
+	long := strings.Repeat("a", pushovertest.MaxMessageLen+1)
+	if _, err := srv.Client().Send(ctx, pushover.SendParams{Message: long}); !errors.Is(err, pushover.ErrMessageTooLong) {
+		t.Errorf("Send() with long message = %v, want ErrMessageTooLong", err)
+	}
+}
+
+func TestSendToOtherUser(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	srv.Recipients = []string{"friend-key"}
+	ctx := context.Background()
+
+	if _, err := srv.Client().Send(ctx, pushover.SendParams{Message: "hi", User: "friend-key"}); err != nil {
+		t.Fatalf("Send() to recipient error: %v", err)
+	}
+	if _, err := srv.Client().Send(ctx, pushover.SendParams{Message: "hi", User: "stranger-key"}); !errors.Is(err, pushover.ErrInvalidUser) {
+		t.Errorf("Send() to unknown user = %v, want ErrInvalidUser", err)
+	}
+	if sent := srv.Sent(); len(sent) != 1 || sent[0].User != "friend-key" {
+		t.Errorf("Sent() = %+v, want one message for friend-key", sent)
+	}
+}
+
+func TestReceiptStatus(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	client := srv.Client()
+
+	resp, err := client.Send(ctx, pushover.SendParams{Message: "wake up", Priority: 2, Expire: 10 * time.Minute})
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if sent := srv.Sent(); sent[0].Retry != pushover.DefaultEmergencyRetry || sent[0].Expire != 10*time.Minute {
+		t.Errorf("sent retry/expire = %v/%v, want the default retry and 10m", sent[0].Retry, sent[0].Expire)
+	}
+
+	status, err := client.Receipt(ctx, resp.Receipt)
+	if err != nil {
+		t.Fatalf("Receipt() error: %v", err)
+	}
+	if status.Acknowledged || status.Expired || status.ExpiresAt.IsZero() {
+		t.Errorf("Receipt() = %+v, want pending", status)
+	}
+
+	srv.AcknowledgeSent(resp.Receipt, "phone")
+	status, err = client.Receipt(ctx, resp.Receipt)
+	if err != nil || !status.Acknowledged || status.AcknowledgedByDevice != "phone" || status.AcknowledgedAt.IsZero() {
+		t.Errorf("Receipt() after ack = %+v, %v", status, err)
+	}
+
+	other, err := client.Send(ctx, pushover.SendParams{Message: "never mind", Priority: 2})
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if err := client.CancelReceipt(ctx, other.Receipt); err != nil {
+		t.Fatalf("CancelReceipt() error: %v", err)
+	}
+	if status, err := client.Receipt(ctx, other.Receipt); err != nil || !status.Expired || status.Acknowledged {
+		t.Errorf("Receipt() after cancel = %+v, %v; want expired", status, err)
+	}
+
+	if _, err := client.Receipt(ctx, "unknown"); err == nil {
+		t.Error("Receipt(unknown) succeeded")
+	}
+}
+
+func TestFetchAndDelete(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	srv.Deliver(pushover.ReceivedMessage{Message: "first", App: "ci"})
+	second := srv.Deliver(pushover.ReceivedMessage{Message: "second"})
+
+	client := srv.Client()
+	result, err := client.FetchMessages(ctx)
+	if err != nil {
+		t.Fatalf("FetchMessages() error: %v", err)
+	}
+	if len(result.Messages) != 2 || result.LastMessageID != second.PushoverID {
+		t.Fatalf("FetchMessages() = %+v, want 2 messages ending at %d", result, second.PushoverID)
+	}
+	if raw := string(result.Messages[0].Raw); !strings.Contains(raw, `"app":"ci"`) {
+		t.Errorf("Raw = %s, want the original API object", raw)
+	}
+
+	if err := client.DeleteMessages(ctx, result.Messages[0].PushoverID); err != nil {
+		t.Fatalf("DeleteMessages() error: %v", err)
+	}
+	if pending := srv.Pending(); len(pending) != 1 || pending[0].Message != "second" {
+		t.Errorf("Pending() = %+v, want only second", pending)
+	}
+}
+
+func TestFetchMessagesStream(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	var last pushover.ReceivedMessage
+	for i := range 7 {
+		last = srv.Deliver(pushover.ReceivedMessage{Message: fmt.Sprintf("message %d", i)})
+	}
+
+	stream, err := srv.Client().FetchMessagesStream(ctx)
+	if err != nil {
+		t.Fatalf("FetchMessagesStream() error: %v", err)
+	}
+	defer func() { _ = stream.Close() }()
+	var sizes []int
+	for batch, err := range stream.Batches(3) {
+		if err != nil {
+			t.Fatalf("Batches() error: %v", err)
+		}
+		sizes = append(sizes, len(batch))
+	}
+	if !slices.Equal(sizes, []int{3, 3, 1}) {
+		t.Errorf("batch sizes = %v, want [3 3 1]", sizes)
+	}
+	// The mock sends last and request after the messages.
+	if stream.LastMessageID != last.PushoverID || stream.RequestID == "" {
+		t.Errorf("LastMessageID, RequestID = %d, %q, want %d and a request ID", stream.LastMessageID, stream.RequestID, last.PushoverID)
+	}
+	for _, err := range stream.Messages() {
+		if err == nil {
+			t.Error("second read of the stream succeeded")
+		}
+	}
+
+	stream, err = srv.Client().FetchMessagesStream(ctx)
+	if err != nil {
+		t.Fatalf("FetchMessagesStream() error: %v", err)
+	}
+	for msg, err := range stream.Messages() {
+		if err != nil || msg.Message != "message 0" {
+			t.Errorf("first message = %q, %v", msg.Message, err)
+		}
+		break
+	}
+	if err := stream.Close(); err != nil {
+		t.Errorf("Close() after stopping early: %v", err)
+	}
+}
+
+func TestLoopback(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	client := srv.Client()
+
+	if _, err := client.Send(ctx, pushover.SendParams{Message: "not looped"}); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	srv.Loopback = true
+	if _, err := client.Send(ctx, pushover.SendParams{Message: "echo", Title: "Self", Priority: -1}); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+
+	pending := srv.Pending()
+	if len(pending) != 1 || pending[0].Message != "echo" || pending[0].Title != "Self" || pending[0].Priority != -1 {
+		t.Errorf("Pending() = %+v, want only the looped-back send", pending)
+	}
+}
+
+func TestLoginWithTwoFactor(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	srv.TwoFactorCode = "123456"
+	ctx := context.Background()
+
+	client := srv.Client()
+	if _, err := client.Login(ctx, pushovertest.Email, pushovertest.Password, ""); !errors.Is(err, pushover.ErrTwoFactorRequired) {
+		t.Fatalf("Login() without code = %v, want ErrTwoFactorRequired", err)
+	}
+
+	login, err := client.Login(ctx, pushovertest.Email, pushovertest.Password, "123456")
+	if err != nil {
+		t.Fatalf("Login() error: %v", err)
+	}
+	device, err := client.RegisterDevice(ctx, login.Secret, "test")
+	if err != nil {
+		t.Fatalf("RegisterDevice() error: %v", err)
+	}
+	if device.ID != pushovertest.DeviceID {
+		t.Errorf("RegisterDevice() ID = %q, want %q", device.ID, pushovertest.DeviceID)
+	}
+}
+
+func TestLookups(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	sounds, err := srv.Client().Sounds(ctx)
+	if err != nil {
+		t.Fatalf("Sounds() error: %v", err)
+	}
+	if sounds["cosmic"] != "Cosmic" {
+		t.Errorf("Sounds() = %v, want cosmic entry", sounds)
+	}
+
+	validation, err := srv.Client().ValidateUser(ctx)
+	if err != nil {
+		t.Fatalf("ValidateUser() error: %v", err)
+	}
+	if strings.Join(validation.Devices, ",") != "phone,laptop" {
+		t.Errorf("ValidateUser() devices = %v, want phone,laptop", validation.Devices)
+	}
+
+	client := srv.Client()
+	client.UserKey = "wrong"
+	if _, err := client.ValidateUser(ctx); err == nil {
+		t.Error("ValidateUser() with bad user key succeeded, want error")
+	}
+}
+
+func TestAcknowledgeReceipt(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	srv.Deliver(pushover.ReceivedMessage{Message: "server down", Priority: 2, Receipt: "r-1"})
+	if err := srv.Client().AcknowledgeReceipt(ctx, "r-1"); err != nil {
+		t.Fatalf("AcknowledgeReceipt() error: %v", err)
+	}
+	if pending := srv.Pending(); len(pending) != 1 || pending[0].Acked != 1 {
+		t.Errorf("Pending() = %+v, want the message acked", pending)
+	}
+
+	if err := srv.Client().AcknowledgeReceipt(ctx, "unknown"); err == nil {
+		t.Error("AcknowledgeReceipt() with unknown receipt succeeded, want error")
+	}
+}
+
+func TestAppLimits(t *testing.T) {
+	srv := pushovertest.NewServer()
+	defer srv.Close()
+	srv.MonthlyLimit = 5
+	ctx := context.Background()
+	client := srv.Client()
+
+	resp, err := client.Send(ctx, pushover.SendParams{Message: "hello"})
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if resp.Limits == nil || resp.Limits.Limit != 5 || resp.Limits.Remaining != 4 || resp.Limits.Reset.IsZero() {
+		t.Errorf("Send() limits = %+v, want 4 of 5 remaining with a reset time", resp.Limits)
+	}
+
+	limits, err := client.AppLimits(ctx)
+	if err != nil {
+		t.Fatalf("AppLimits() error: %v", err)
+	}
+	if limits.Limit != 5 || limits.Remaining != 4 || !limits.Reset.Equal(resp.Limits.Reset) {
+		t.Errorf("AppLimits() = %+v, want %+v", limits, resp.Limits)
+	}
+
+	client.AppToken = "wrong"
+	if _, err := client.AppLimits(ctx); !errors.Is(err, pushover.ErrInvalidToken) {
+		t.Errorf("AppLimits() with bad token error = %v, want ErrInvalidToken", err)
+	}
+}
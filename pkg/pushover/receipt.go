@@ -0,0 +1,118 @@
+// ABOUTME: Receipt status for emergency-priority messages.
+// ABOUTME: Reports whether a sent emergency message was acknowledged or expired.
+package pushover
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ReceiptStatus is the delivery state of an emergency-priority message.
+// Times are zero when the event hasn't happened.
+type ReceiptStatus struct {
+	Acknowledged         bool      `json:"acknowledged"`
+	AcknowledgedAt       time.Time `json:"acknowledged_at,omitzero"`
+	AcknowledgedBy       string    `json:"acknowledged_by,omitempty"`
+	AcknowledgedByDevice string    `json:"acknowledged_by_device,omitempty"`
+	LastDeliveredAt      time.Time `json:"last_delivered_at,omitzero"`
+	Expired              bool      `json:"expired"`
+	ExpiresAt            time.Time `json:"expires_at,omitzero"`
+}
+
+// Receipt fetches the status of the emergency message that returned receipt.
+func (c *Client) Receipt(ctx context.Context, receipt string) (*ReceiptStatus, error) {
+	if c.AppToken == "" {
+		return nil, fmt.Errorf("app token is required")
+	}
+	if strings.TrimSpace(receipt) == "" {
+		return nil, fmt.Errorf("receipt is required")
+	}
+
+	params := url.Values{}
+	params.Set("token", c.AppToken)
+	endpoint := fmt.Sprintf("%s/receipts/%s.json?%s", c.baseURL(), url.PathEscape(receipt), params.Encode())
+
+	resp, err := c.do(ctx, func() (*http.Request, error) { //nolint:bodyclose // body closed by decodeJSON/decodeAPIError
+		return http.NewRequest(http.MethodGet, endpoint, nil)
+	}, defaultRequestAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, decodeAPIError(resp)
+	}
+
+	var payload struct {
+		Status               int    `json:"status"`
+		Acknowledged         int    `json:"acknowledged"`
+		AcknowledgedAt       int64  `json:"acknowledged_at"`
+		AcknowledgedBy       string `json:"acknowledged_by"`
+		AcknowledgedByDevice string `json:"acknowledged_by_device"`
+		LastDeliveredAt      int64  `json:"last_delivered_at"`
+		Expired              int    `json:"expired"`
+		ExpiresAt            int64  `json:"expires_at"`
+	}
+	if err := decodeJSON(resp, &payload); err != nil {
+		return nil, fmt.Errorf("decode receipt response: %w", err)
+	}
+
+	return &ReceiptStatus{
+		Acknowledged:         payload.Acknowledged == 1,
+		AcknowledgedAt:       unixTime(payload.AcknowledgedAt),
+		AcknowledgedBy:       payload.AcknowledgedBy,
+		AcknowledgedByDevice: payload.AcknowledgedByDevice,
+		LastDeliveredAt:      unixTime(payload.LastDeliveredAt),
+		Expired:              payload.Expired == 1,
+		ExpiresAt:            unixTime(payload.ExpiresAt),
+	}, nil
+}
+
+// CancelReceipt stops the retries of the emergency message that returned
+// receipt before anyone acknowledges it.
+func (c *Client) CancelReceipt(ctx context.Context, receipt string) error {
+	if c.AppToken == "" {
+		return fmt.Errorf("app token is required")
+	}
+	if strings.TrimSpace(receipt) == "" {
+		return fmt.Errorf("receipt is required")
+	}
+
+	values := url.Values{}
+	values.Set("token", c.AppToken)
+	encoded := values.Encode()
+
+	endpoint := fmt.Sprintf("%s/receipts/%s/cancel.json", c.baseURL(), url.PathEscape(receipt))
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(encoded))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	}, defaultRequestAttempts)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 400 {
+		return decodeAPIError(resp)
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return nil
+}
+
+// unixTime converts API timestamps, where 0 means unset.
+func unixTime(sec int64) time.Time {
+	if sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
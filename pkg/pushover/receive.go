@@ -4,6 +4,7 @@ package pushover
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -31,6 +32,15 @@ type ReceivedMessage struct {
 	Acked      int    `json:"acked"`
 	HTML       int    `json:"html"`
 	Receipt    string `json:"receipt"`
+	// Raw is the message object as returned by the API, including fields
+	// this package doesn't know about yet.
+	Raw json.RawMessage `json:"-"`
+}
+
+// NeedsAck reports whether the message is an emergency message that nobody
+// has acknowledged yet.
+func (m ReceivedMessage) NeedsAck() bool {
+	return m.Receipt != "" && m.Acked == 0
 }
 
 // FetchResult bundles a set of received messages and cursor metadata.
@@ -42,58 +52,73 @@ type FetchResult struct {
 
 // FetchMessages retrieves unread messages via the Open Client API.
 func (c *Client) FetchMessages(ctx context.Context) (*FetchResult, error) {
-	if err := c.ensureReceiveCredentials(); err != nil {
+	stream, err := c.FetchMessagesStream(ctx)
+	if err != nil {
 		return nil, err
 	}
+	defer func() { _ = stream.Close() }()
 
-	params := url.Values{}
-	params.Set("secret", c.DeviceSecret)
-	params.Set("device_id", c.DeviceID)
+	msgs := []ReceivedMessage{}
+	for msg, err := range stream.Messages() {
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return &FetchResult{Messages: msgs, LastMessageID: stream.LastMessageID, RequestID: stream.RequestID}, nil
+}
 
-	resp, err := c.do(ctx, func() (*http.Request, error) { //nolint:bodyclose // body closed by decodeJSON/decodeAPIError
-		req, err := http.NewRequest(http.MethodGet, apiBaseURL+"/messages.json?"+params.Encode(), nil)
+// DeleteMessages acknowledges messages up to the supplied ID.
+func (c *Client) DeleteMessages(ctx context.Context, upToID int64) error {
+	if err := c.ensureReceiveCredentials(); err != nil {
+		return err
+	}
+	if upToID <= 0 {
+		return fmt.Errorf("message id must be positive")
+	}
+
+	values := url.Values{}
+	values.Set("secret", c.DeviceSecret)
+	values.Set("message", strconv.FormatInt(upToID, 10))
+	encoded := values.Encode()
+
+	endpoint := fmt.Sprintf("%s/devices/%s/update_highest_message.json", c.baseURL(), url.PathEscape(c.DeviceID))
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(encoded))
 		if err != nil {
 			return nil, err
 		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		return req, nil
 	}, defaultRequestAttempts)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, decodeAPIError(resp)
-	}
-
-	var payload struct {
-		Status   int               `json:"status"`
-		Request  string            `json:"request"`
-		Last     int64             `json:"last"`
-		Messages []ReceivedMessage `json:"messages"`
-	}
-
-	if err := decodeJSON(resp, &payload); err != nil {
-		return nil, fmt.Errorf("decode fetch response: %w", err)
+		return decodeAPIError(resp)
 	}
 
-	return &FetchResult{Messages: payload.Messages, LastMessageID: payload.Last, RequestID: payload.Request}, nil
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return nil
 }
 
-// DeleteMessages acknowledges messages up to the supplied ID.
-func (c *Client) DeleteMessages(ctx context.Context, upToID int64) error {
+// AcknowledgeReceipt acknowledges an emergency-priority message by its
+// receipt, stopping its retries on every device.
+func (c *Client) AcknowledgeReceipt(ctx context.Context, receipt string) error {
 	if err := c.ensureReceiveCredentials(); err != nil {
 		return err
 	}
-	if upToID <= 0 {
-		return fmt.Errorf("message id must be positive")
+	if strings.TrimSpace(receipt) == "" {
+		return fmt.Errorf("receipt is required")
 	}
 
 	values := url.Values{}
 	values.Set("secret", c.DeviceSecret)
-	values.Set("message", strconv.FormatInt(upToID, 10))
 	encoded := values.Encode()
 
-	endpoint := fmt.Sprintf("%s/devices/%s/update_highest_message.json", apiBaseURL, url.PathEscape(c.DeviceID))
+	endpoint := fmt.Sprintf("%s/receipts/%s/acknowledge.json", c.baseURL(), url.PathEscape(receipt))
 	resp, err := c.do(ctx, func() (*http.Request, error) {
 		req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(encoded))
 		if err != nil {
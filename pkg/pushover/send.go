@@ -24,14 +24,33 @@ type SendParams struct {
 	Timestamp time.Time
 	HTML      bool
 	Monospace bool
+	// TTL makes the message expire from devices after this long. Rounded up
+	// to whole seconds; ignored by Pushover for emergency priority.
+	TTL time.Duration
+	// User sends to this user or group key instead of the client's UserKey.
+	User string
+	// Retry and Expire control how often and for how long an emergency
+	// (priority 2) message repeats until acknowledged. Zero uses
+	// DefaultEmergencyRetry and DefaultEmergencyExpire.
+	Retry  time.Duration
+	Expire time.Duration
 }
 
+// Defaults for emergency-priority messages, which Pushover requires to set
+// retry and expire.
+const (
+	DefaultEmergencyRetry  = time.Minute
+	DefaultEmergencyExpire = time.Hour
+)
+
 // SendResponse mirrors the API response to a send request.
 type SendResponse struct {
 	Status  int      `json:"status"`
 	Request string   `json:"request"`
 	Receipt string   `json:"receipt"`
 	Errors  []string `json:"errors"`
+	// Limits is the app's quota after this send, when Pushover reported it.
+	Limits *AppLimits `json:"-"`
 }
 
 // Send dispatches a push notification via the Message API.
@@ -46,6 +65,9 @@ func (c *Client) Send(ctx context.Context, params SendParams) (*SendResponse, er
 	values := url.Values{}
 	values.Set("token", c.AppToken)
 	values.Set("user", c.UserKey)
+	if params.User != "" {
+		values.Set("user", params.User)
+	}
 	values.Set("message", params.Message)
 
 	if params.Title != "" {
@@ -57,6 +79,17 @@ func (c *Client) Send(ctx context.Context, params SendParams) (*SendResponse, er
 	if params.Priority != 0 {
 		values.Set("priority", strconv.Itoa(params.Priority))
 	}
+	if params.Priority == 2 {
+		retry, expire := params.Retry, params.Expire
+		if retry <= 0 {
+			retry = DefaultEmergencyRetry
+		}
+		if expire <= 0 {
+			expire = DefaultEmergencyExpire
+		}
+		values.Set("retry", strconv.Itoa(int(retry/time.Second)))
+		values.Set("expire", strconv.Itoa(int(expire/time.Second)))
+	}
 	if params.URL != "" {
 		values.Set("url", params.URL)
 	}
@@ -69,6 +102,9 @@ func (c *Client) Send(ctx context.Context, params SendParams) (*SendResponse, er
 	if !params.Timestamp.IsZero() {
 		values.Set("timestamp", strconv.FormatInt(params.Timestamp.Unix(), 10))
 	}
+	if params.TTL > 0 {
+		values.Set("ttl", strconv.FormatInt(int64((params.TTL+time.Second-1)/time.Second), 10))
+	}
 	if params.HTML {
 		values.Set("html", "1")
 	}
@@ -79,7 +115,7 @@ func (c *Client) Send(ctx context.Context, params SendParams) (*SendResponse, er
 	encoded := values.Encode()
 
 	resp, err := c.do(ctx, func() (*http.Request, error) { //nolint:bodyclose // body closed by decodeJSON/decodeAPIError
-		req, err := http.NewRequest(http.MethodPost, apiBaseURL+"/messages.json", strings.NewReader(encoded))
+		req, err := http.NewRequest(http.MethodPost, c.baseURL()+"/messages.json", strings.NewReader(encoded))
 		if err != nil {
 			return nil, err
 		}
@@ -98,6 +134,7 @@ func (c *Client) Send(ctx context.Context, params SendParams) (*SendResponse, er
 	if err := decodeJSON(resp, &payload); err != nil {
 		return nil, fmt.Errorf("decode send response: %w", err)
 	}
+	payload.Limits = limitsFromHeader(resp.Header)
 
 	return &payload, nil
 }
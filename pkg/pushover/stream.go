@@ -0,0 +1,177 @@
+// ABOUTME: Streaming fetch for the Open Client API.
+// ABOUTME: Decodes unread messages one at a time as the response arrives, so large backlogs use bounded memory.
+package pushover
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// MessageStream is a fetch of unread messages decoded as the response is read.
+// Range over Messages or Batches once, then Close it.
+type MessageStream struct {
+	// LastMessageID and RequestID are as in FetchResult. The API may send
+	// them after the messages, so they're set once the stream is read to the
+	// end.
+	LastMessageID int64
+	RequestID     string
+
+	body io.ReadCloser
+	dec  *json.Decoder
+	used bool
+}
+
+// FetchMessagesStream retrieves unread messages via the Open Client API like
+// FetchMessages, returning them as a stream instead of a slice.
+func (c *Client) FetchMessagesStream(ctx context.Context) (*MessageStream, error) {
+	if err := c.ensureReceiveCredentials(); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("secret", c.DeviceSecret)
+	params.Set("device_id", c.DeviceID)
+
+	resp, err := c.do(ctx, func() (*http.Request, error) { //nolint:bodyclose // body closed by MessageStream.Close/decodeAPIError
+		req, err := http.NewRequest(http.MethodGet, c.baseURL()+"/messages.json?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		return req, nil
+	}, defaultRequestAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, decodeAPIError(resp)
+	}
+	return &MessageStream{body: resp.Body, dec: json.NewDecoder(resp.Body)}, nil
+}
+
+// Close releases the response. It's safe to call more than once.
+func (s *MessageStream) Close() error {
+	return s.body.Close()
+}
+
+// Messages yields each message as it's decoded, then reads the rest of the
+// response for LastMessageID and RequestID. A decoding or network error is
+// yielded once and ends the stream.
+func (s *MessageStream) Messages() iter.Seq2[ReceivedMessage, error] {
+	return func(yield func(ReceivedMessage, error) bool) {
+		if s.used {
+			yield(ReceivedMessage{}, errors.New("pushover: message stream already read"))
+			return
+		}
+		s.used = true
+		if err := s.decode(yield); err != nil {
+			yield(ReceivedMessage{}, fmt.Errorf("decode fetch response: %w", err))
+		}
+	}
+}
+
+// Batches yields the messages n at a time; the last batch may be shorter.
+// Each batch is a new slice the caller may keep.
+func (s *MessageStream) Batches(n int) iter.Seq2[[]ReceivedMessage, error] {
+	n = max(n, 1)
+	return func(yield func([]ReceivedMessage, error) bool) {
+		batch := make([]ReceivedMessage, 0, n)
+		for msg, err := range s.Messages() {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			batch = append(batch, msg)
+			if len(batch) == n {
+				if !yield(batch, nil) {
+					return
+				}
+				batch = make([]ReceivedMessage, 0, n)
+			}
+		}
+		if len(batch) > 0 {
+			yield(batch, nil)
+		}
+	}
+}
+
+// errStopped ends decoding when the consumer stops ranging.
+var errStopped = errors.New("stopped")
+
+// decode walks the top-level response object, passing each element of its
+// messages array to yield and keeping last and request.
+func (s *MessageStream) decode(yield func(ReceivedMessage, error) bool) error {
+	if err := s.expect(json.Delim('{')); err != nil {
+		return err
+	}
+	for s.dec.More() {
+		tok, err := s.dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		switch key {
+		case "messages":
+			err = s.decodeMessages(yield)
+			if errors.Is(err, errStopped) {
+				return nil
+			}
+		case "last":
+			err = s.dec.Decode(&s.LastMessageID)
+		case "request":
+			err = s.dec.Decode(&s.RequestID)
+		default:
+			var skip json.RawMessage
+			err = s.dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return s.expect(json.Delim('}'))
+}
+
+func (s *MessageStream) decodeMessages(yield func(ReceivedMessage, error) bool) error {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("messages: unexpected %v", tok)
+	}
+	for s.dec.More() {
+		var raw json.RawMessage
+		if err := s.dec.Decode(&raw); err != nil {
+			return err
+		}
+		var msg ReceivedMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			return err
+		}
+		msg.Raw = raw
+		if !yield(msg, nil) {
+			return errStopped
+		}
+	}
+	return s.expect(json.Delim(']'))
+}
+
+func (s *MessageStream) expect(want json.Delim) error {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("expected %v, got %v", want, tok)
+	}
+	return nil
+}
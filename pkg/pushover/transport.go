@@ -0,0 +1,64 @@
+// ABOUTME: HTTP transport construction with proxy and custom CA support.
+// ABOUTME: Lets the client work behind corporate proxies and TLS interception.
+package pushover
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// HTTPClientOptions customise how the client reaches the API.
+type HTTPClientOptions struct {
+	// ProxyURL forces a proxy. When empty, HTTPS_PROXY/HTTP_PROXY/NO_PROXY apply.
+	ProxyURL string
+	// CACertPath adds PEM certificates to the system trust store.
+	CACertPath string
+	// Timeout bounds each request; zero uses 15 seconds.
+	Timeout time.Duration
+}
+
+// NewHTTPClient builds an *http.Client honouring the proxy and CA options.
+// Pass it to Client.SetHTTPClient.
+func NewHTTPClient(opts HTTPClientOptions) (*http.Client, error) {
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("pushover: unexpected default transport")
+	}
+	transport := base.Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if opts.ProxyURL != "" {
+		proxy, err := url.Parse(opts.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("pushover: invalid proxy url %q", opts.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if opts.CACertPath != "" {
+		pem, err := os.ReadFile(opts.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("pushover: reading CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("pushover: no certificates found in %s", opts.CACertPath)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}